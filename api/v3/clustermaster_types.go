@@ -33,6 +33,14 @@ const (
 	// ClusterMasterPausedAnnotation is the annotation that pauses the reconciliation (triggers
 	// an immediate requeue)
 	ClusterMasterPausedAnnotation = "clustermaster.enterprise.splunk.com/paused"
+
+	// ClusterMasterAdoptedAnnotation is the annotation set by the operator once a ClusterManager of the same name
+	// has adopted the resources of the cluster master, which are then left alone when it is deleted
+	ClusterMasterAdoptedAnnotation = "clustermaster.enterprise.splunk.com/adopted"
+
+	// ClusterMasterBundlePushAnnotation is the annotation that triggers a push of the cluster bundle to
+	// the indexer peers whenever its value changes
+	ClusterMasterBundlePushAnnotation = "clustermaster.enterprise.splunk.com/bundle-push"
 )
 
 // ClusterMasterSpec defines the desired state of ClusterMaster
@@ -68,6 +76,9 @@ type ClusterMasterStatus struct {
 
 	// Telemetry App installation flag
 	TelAppInstalled bool `json:"telAppInstalled"`
+
+	// last disruptive actions taken by the operator, oldest first
+	AuditTrail []enterpriseApi.AuditRecord `json:"auditTrail,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
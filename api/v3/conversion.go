@@ -0,0 +1,439 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+const (
+	// ConversionDataAnnotation is the annotation keeping the fields of a v4 object which v3 cannot represent,
+	// so that they are not lost when the object is written back in v3
+	ConversionDataAnnotation = "enterprise.splunk.com/conversion-data"
+)
+
+// hecConversionData holds the v4 fields of the Standalone and IndexerCluster kinds missing in v3
+// +kubebuilder:object:generate=false
+type hecConversionData struct {
+	Hec             enterpriseApi.HecSpec               `json:"hec,omitempty"`
+	HecStatus       enterpriseApi.HecStatus             `json:"hecStatus,omitempty"`
+	VolumeExpansion enterpriseApi.VolumeExpansionStatus `json:"volumeExpansion,omitempty"`
+	AuditTrail      []enterpriseApi.AuditRecord         `json:"auditTrail,omitempty"`
+
+	// health of an IndexerCluster
+	ReplicationFactorMet bool `json:"replicationFactorMet,omitempty"`
+	SearchFactorMet      bool `json:"searchFactorMet,omitempty"`
+}
+
+// searchHeadClusterConversionData holds the v4 fields of the SearchHeadCluster kind missing in v3
+// +kubebuilder:object:generate=false
+type searchHeadClusterConversionData struct {
+	KVStoreBackup   enterpriseApi.KVStoreBackupSpec              `json:"kvStoreBackup,omitempty"`
+	KVStore         enterpriseApi.SearchHeadClusterKVStoreStatus `json:"kvStore,omitempty"`
+	VolumeExpansion enterpriseApi.VolumeExpansionStatus          `json:"volumeExpansion,omitempty"`
+	AuditTrail      []enterpriseApi.AuditRecord                  `json:"auditTrail,omitempty"`
+	Members         map[string]searchHeadClusterMemberKVStore    `json:"members,omitempty"`
+}
+
+// monitoringConsoleConversionData holds the v4 fields of the MonitoringConsole kind missing in v3
+// +kubebuilder:object:generate=false
+type monitoringConsoleConversionData struct {
+	PeerSelector          *metav1.LabelSelector                         `json:"peerSelector,omitempty"`
+	PeerNamespaceSelector *metav1.LabelSelector                         `json:"peerNamespaceSelector,omitempty"`
+	ExternalPeers         []enterpriseApi.MonitoringConsoleExternalPeer `json:"externalPeers,omitempty"`
+	Peers                 []enterpriseApi.MonitoringConsolePeerStatus   `json:"peers,omitempty"`
+	AuditTrail            []enterpriseApi.AuditRecord                   `json:"auditTrail,omitempty"`
+}
+
+// searchHeadClusterMemberKVStore holds the KV store status of a search head cluster member
+// +kubebuilder:object:generate=false
+type searchHeadClusterMemberKVStore struct {
+	Status            string `json:"status,omitempty"`
+	ReplicationStatus string `json:"replicationStatus,omitempty"`
+}
+
+// setConversionData keeps the v4 fields missing in v3 in the annotations of a v3 object, unless they are all empty
+func setConversionData(obj metav1.Object, data interface{}) error {
+	annotations := obj.GetAnnotations()
+	if reflect.ValueOf(data).Elem().IsZero() {
+		if _, ok := annotations[ConversionDataAnnotation]; ok {
+			delete(annotations, ConversionDataAnnotation)
+			obj.SetAnnotations(annotations)
+		}
+		return nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[ConversionDataAnnotation] = string(raw)
+	obj.SetAnnotations(annotations)
+	return nil
+}
+
+// getConversionData restores the v4 fields kept in the annotations of an object, and removes the annotation
+func getConversionData(obj metav1.Object, data interface{}) error {
+	annotations := obj.GetAnnotations()
+	raw, ok := annotations[ConversionDataAnnotation]
+	if !ok {
+		return nil
+	}
+	delete(annotations, ConversionDataAnnotation)
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+	obj.SetAnnotations(annotations)
+
+	if err := json.Unmarshal([]byte(raw), data); err != nil {
+		return fmt.Errorf("invalid %s annotation: %w", ConversionDataAnnotation, err)
+	}
+	return nil
+}
+
+// ConvertTo converts a v3 Standalone to the v4 hub version
+func (src *Standalone) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*enterpriseApi.Standalone)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	data := &hecConversionData{}
+	if err := getConversionData(&dst.ObjectMeta, data); err != nil {
+		return err
+	}
+
+	src.Spec.CommonSplunkSpec.DeepCopyInto(&dst.Spec.CommonSplunkSpec)
+	dst.Spec.Replicas = src.Spec.Replicas
+	src.Spec.SmartStore.DeepCopyInto(&dst.Spec.SmartStore)
+	src.Spec.AppFrameworkConfig.DeepCopyInto(&dst.Spec.AppFrameworkConfig)
+	dst.Spec.Hec = data.Hec
+
+	dst.Status.Phase = src.Status.Phase
+	dst.Status.Replicas = src.Status.Replicas
+	dst.Status.ReadyReplicas = src.Status.ReadyReplicas
+	dst.Status.Selector = src.Status.Selector
+	src.Status.SmartStore.DeepCopyInto(&dst.Status.SmartStore)
+	dst.Status.ResourceRevMap = copyStringMap(src.Status.ResourceRevMap)
+	src.Status.AppContext.DeepCopyInto(&dst.Status.AppContext)
+	dst.Status.TelAppInstalled = src.Status.TelAppInstalled
+	dst.Status.Hec = data.HecStatus
+	dst.Status.VolumeExpansion = data.VolumeExpansion
+	dst.Status.AuditTrail = data.AuditTrail
+	return nil
+}
+
+// ConvertFrom converts the v4 hub version of a Standalone to v3
+func (dst *Standalone) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*enterpriseApi.Standalone)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	src.Spec.CommonSplunkSpec.DeepCopyInto(&dst.Spec.CommonSplunkSpec)
+	dst.Spec.Replicas = src.Spec.Replicas
+	src.Spec.SmartStore.DeepCopyInto(&dst.Spec.SmartStore)
+	src.Spec.AppFrameworkConfig.DeepCopyInto(&dst.Spec.AppFrameworkConfig)
+
+	dst.Status.Phase = src.Status.Phase
+	dst.Status.Replicas = src.Status.Replicas
+	dst.Status.ReadyReplicas = src.Status.ReadyReplicas
+	dst.Status.Selector = src.Status.Selector
+	src.Status.SmartStore.DeepCopyInto(&dst.Status.SmartStore)
+	dst.Status.ResourceRevMap = copyStringMap(src.Status.ResourceRevMap)
+	src.Status.AppContext.DeepCopyInto(&dst.Status.AppContext)
+	dst.Status.TelAppInstalled = src.Status.TelAppInstalled
+
+	return setConversionData(&dst.ObjectMeta, &hecConversionData{
+		Hec:             *src.Spec.Hec.DeepCopy(),
+		HecStatus:       *src.Status.Hec.DeepCopy(),
+		VolumeExpansion: *src.Status.VolumeExpansion.DeepCopy(),
+		AuditTrail:      copyAuditTrail(src.Status.AuditTrail),
+	})
+}
+
+// ConvertTo converts a v3 IndexerCluster to the v4 hub version
+func (src *IndexerCluster) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*enterpriseApi.IndexerCluster)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	data := &hecConversionData{}
+	if err := getConversionData(&dst.ObjectMeta, data); err != nil {
+		return err
+	}
+
+	src.Spec.CommonSplunkSpec.DeepCopyInto(&dst.Spec.CommonSplunkSpec)
+	dst.Spec.Replicas = src.Spec.Replicas
+	dst.Spec.Hec = data.Hec
+
+	dst.Status.Phase = src.Status.Phase
+	dst.Status.ClusterMasterPhase = src.Status.ClusterMasterPhase
+	dst.Status.ClusterManagerPhase = src.Status.ClusterManagerPhase
+	dst.Status.Replicas = src.Status.Replicas
+	dst.Status.ReadyReplicas = src.Status.ReadyReplicas
+	dst.Status.Selector = src.Status.Selector
+	dst.Status.Initialized = src.Status.Initialized
+	dst.Status.IndexingReady = src.Status.IndexingReady
+	dst.Status.ServiceReady = src.Status.ServiceReady
+	dst.Status.IndexerSecretChanged = append([]bool(nil), src.Status.IndexerSecretChanged...)
+	dst.Status.NamespaceSecretResourceVersion = src.Status.NamespaceSecretResourceVersion
+	dst.Status.IdxcPasswordChangedSecrets = copyBoolMap(src.Status.IdxcPasswordChangedSecrets)
+	dst.Status.MaintenanceMode = src.Status.MaintenanceMode
+	dst.Status.Peers = nil
+	for _, peer := range src.Status.Peers {
+		dst.Status.Peers = append(dst.Status.Peers, enterpriseApi.IndexerClusterMemberStatus(peer))
+	}
+	dst.Status.ReplicationFactorMet = data.ReplicationFactorMet
+	dst.Status.SearchFactorMet = data.SearchFactorMet
+	dst.Status.Hec = data.HecStatus
+	dst.Status.VolumeExpansion = data.VolumeExpansion
+	dst.Status.AuditTrail = data.AuditTrail
+	return nil
+}
+
+// ConvertFrom converts the v4 hub version of an IndexerCluster to v3
+func (dst *IndexerCluster) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*enterpriseApi.IndexerCluster)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	src.Spec.CommonSplunkSpec.DeepCopyInto(&dst.Spec.CommonSplunkSpec)
+	dst.Spec.Replicas = src.Spec.Replicas
+
+	dst.Status.Phase = src.Status.Phase
+	dst.Status.ClusterMasterPhase = src.Status.ClusterMasterPhase
+	dst.Status.ClusterManagerPhase = src.Status.ClusterManagerPhase
+	dst.Status.Replicas = src.Status.Replicas
+	dst.Status.ReadyReplicas = src.Status.ReadyReplicas
+	dst.Status.Selector = src.Status.Selector
+	dst.Status.Initialized = src.Status.Initialized
+	dst.Status.IndexingReady = src.Status.IndexingReady
+	dst.Status.ServiceReady = src.Status.ServiceReady
+	dst.Status.IndexerSecretChanged = append([]bool(nil), src.Status.IndexerSecretChanged...)
+	dst.Status.NamespaceSecretResourceVersion = src.Status.NamespaceSecretResourceVersion
+	dst.Status.IdxcPasswordChangedSecrets = copyBoolMap(src.Status.IdxcPasswordChangedSecrets)
+	dst.Status.MaintenanceMode = src.Status.MaintenanceMode
+	dst.Status.Peers = nil
+	for _, peer := range src.Status.Peers {
+		dst.Status.Peers = append(dst.Status.Peers, IndexerClusterMemberStatus(peer))
+	}
+
+	return setConversionData(&dst.ObjectMeta, &hecConversionData{
+		Hec:                  *src.Spec.Hec.DeepCopy(),
+		HecStatus:            *src.Status.Hec.DeepCopy(),
+		VolumeExpansion:      *src.Status.VolumeExpansion.DeepCopy(),
+		AuditTrail:           copyAuditTrail(src.Status.AuditTrail),
+		ReplicationFactorMet: src.Status.ReplicationFactorMet,
+		SearchFactorMet:      src.Status.SearchFactorMet,
+	})
+}
+
+// ConvertTo converts a v3 SearchHeadCluster to the v4 hub version
+func (src *SearchHeadCluster) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*enterpriseApi.SearchHeadCluster)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	data := &searchHeadClusterConversionData{}
+	if err := getConversionData(&dst.ObjectMeta, data); err != nil {
+		return err
+	}
+
+	src.Spec.CommonSplunkSpec.DeepCopyInto(&dst.Spec.CommonSplunkSpec)
+	dst.Spec.Replicas = src.Spec.Replicas
+	src.Spec.AppFrameworkConfig.DeepCopyInto(&dst.Spec.AppFrameworkConfig)
+	dst.Spec.KVStoreBackup = data.KVStoreBackup
+
+	dst.Status.Phase = src.Status.Phase
+	dst.Status.DeployerPhase = src.Status.DeployerPhase
+	dst.Status.Replicas = src.Status.Replicas
+	dst.Status.ReadyReplicas = src.Status.ReadyReplicas
+	dst.Status.Selector = src.Status.Selector
+	dst.Status.Captain = src.Status.Captain
+	dst.Status.CaptainReady = src.Status.CaptainReady
+	dst.Status.Initialized = src.Status.Initialized
+	dst.Status.MinPeersJoined = src.Status.MinPeersJoined
+	dst.Status.MaintenanceMode = src.Status.MaintenanceMode
+	dst.Status.ShcSecretChanged = append([]bool(nil), src.Status.ShcSecretChanged...)
+	dst.Status.AdminSecretChanged = append([]bool(nil), src.Status.AdminSecretChanged...)
+	dst.Status.AdminPasswordChangedSecrets = copyBoolMap(src.Status.AdminPasswordChangedSecrets)
+	dst.Status.NamespaceSecretResourceVersion = src.Status.NamespaceSecretResourceVersion
+	dst.Status.Members = nil
+	for _, member := range src.Status.Members {
+		kvStore := data.Members[member.Name]
+		dst.Status.Members = append(dst.Status.Members, enterpriseApi.SearchHeadClusterMemberStatus{
+			Name:                        member.Name,
+			Status:                      member.Status,
+			Adhoc:                       member.Adhoc,
+			Registered:                  member.Registered,
+			ActiveHistoricalSearchCount: member.ActiveHistoricalSearchCount,
+			ActiveRealtimeSearchCount:   member.ActiveRealtimeSearchCount,
+			KVStoreStatus:               kvStore.Status,
+			KVStoreReplicationStatus:    kvStore.ReplicationStatus,
+		})
+	}
+	dst.Status.KVStore = data.KVStore
+	dst.Status.VolumeExpansion = data.VolumeExpansion
+	dst.Status.AuditTrail = data.AuditTrail
+	src.Status.AppContext.DeepCopyInto(&dst.Status.AppContext)
+	dst.Status.TelAppInstalled = src.Status.TelAppInstalled
+	return nil
+}
+
+// ConvertFrom converts the v4 hub version of a SearchHeadCluster to v3
+func (dst *SearchHeadCluster) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*enterpriseApi.SearchHeadCluster)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	src.Spec.CommonSplunkSpec.DeepCopyInto(&dst.Spec.CommonSplunkSpec)
+	dst.Spec.Replicas = src.Spec.Replicas
+	src.Spec.AppFrameworkConfig.DeepCopyInto(&dst.Spec.AppFrameworkConfig)
+
+	data := &searchHeadClusterConversionData{
+		KVStoreBackup:   *src.Spec.KVStoreBackup.DeepCopy(),
+		KVStore:         *src.Status.KVStore.DeepCopy(),
+		VolumeExpansion: *src.Status.VolumeExpansion.DeepCopy(),
+		AuditTrail:      copyAuditTrail(src.Status.AuditTrail),
+	}
+
+	dst.Status.Phase = src.Status.Phase
+	dst.Status.DeployerPhase = src.Status.DeployerPhase
+	dst.Status.Replicas = src.Status.Replicas
+	dst.Status.ReadyReplicas = src.Status.ReadyReplicas
+	dst.Status.Selector = src.Status.Selector
+	dst.Status.Captain = src.Status.Captain
+	dst.Status.CaptainReady = src.Status.CaptainReady
+	dst.Status.Initialized = src.Status.Initialized
+	dst.Status.MinPeersJoined = src.Status.MinPeersJoined
+	dst.Status.MaintenanceMode = src.Status.MaintenanceMode
+	dst.Status.ShcSecretChanged = append([]bool(nil), src.Status.ShcSecretChanged...)
+	dst.Status.AdminSecretChanged = append([]bool(nil), src.Status.AdminSecretChanged...)
+	dst.Status.AdminPasswordChangedSecrets = copyBoolMap(src.Status.AdminPasswordChangedSecrets)
+	dst.Status.NamespaceSecretResourceVersion = src.Status.NamespaceSecretResourceVersion
+	dst.Status.Members = nil
+	for _, member := range src.Status.Members {
+		dst.Status.Members = append(dst.Status.Members, SearchHeadClusterMemberStatus{
+			Name:                        member.Name,
+			Status:                      member.Status,
+			Adhoc:                       member.Adhoc,
+			Registered:                  member.Registered,
+			ActiveHistoricalSearchCount: member.ActiveHistoricalSearchCount,
+			ActiveRealtimeSearchCount:   member.ActiveRealtimeSearchCount,
+		})
+		if member.KVStoreStatus != "" || member.KVStoreReplicationStatus != "" {
+			if data.Members == nil {
+				data.Members = map[string]searchHeadClusterMemberKVStore{}
+			}
+			data.Members[member.Name] = searchHeadClusterMemberKVStore{
+				Status:            member.KVStoreStatus,
+				ReplicationStatus: member.KVStoreReplicationStatus,
+			}
+		}
+	}
+	src.Status.AppContext.DeepCopyInto(&dst.Status.AppContext)
+	dst.Status.TelAppInstalled = src.Status.TelAppInstalled
+
+	return setConversionData(&dst.ObjectMeta, data)
+}
+
+// ConvertTo converts a v3 MonitoringConsole to the v4 hub version
+func (src *MonitoringConsole) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*enterpriseApi.MonitoringConsole)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	data := &monitoringConsoleConversionData{}
+	if err := getConversionData(&dst.ObjectMeta, data); err != nil {
+		return err
+	}
+
+	src.Spec.CommonSplunkSpec.DeepCopyInto(&dst.Spec.CommonSplunkSpec)
+	src.Spec.AppFrameworkConfig.DeepCopyInto(&dst.Spec.AppFrameworkConfig)
+	dst.Spec.PeerSelector = data.PeerSelector
+	dst.Spec.PeerNamespaceSelector = data.PeerNamespaceSelector
+	dst.Spec.ExternalPeers = data.ExternalPeers
+
+	dst.Status.Phase = src.Status.Phase
+	dst.Status.Selector = src.Status.Selector
+	src.Status.BundlePushTracker.DeepCopyInto(&dst.Status.BundlePushTracker)
+	dst.Status.ResourceRevMap = copyStringMap(src.Status.ResourceRevMap)
+	src.Status.AppContext.DeepCopyInto(&dst.Status.AppContext)
+	dst.Status.Peers = data.Peers
+	dst.Status.AuditTrail = data.AuditTrail
+	return nil
+}
+
+// ConvertFrom converts the v4 hub version of a MonitoringConsole to v3
+func (dst *MonitoringConsole) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*enterpriseApi.MonitoringConsole)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	src.Spec.CommonSplunkSpec.DeepCopyInto(&dst.Spec.CommonSplunkSpec)
+	src.Spec.AppFrameworkConfig.DeepCopyInto(&dst.Spec.AppFrameworkConfig)
+
+	dst.Status.Phase = src.Status.Phase
+	dst.Status.Selector = src.Status.Selector
+	src.Status.BundlePushTracker.DeepCopyInto(&dst.Status.BundlePushTracker)
+	dst.Status.ResourceRevMap = copyStringMap(src.Status.ResourceRevMap)
+	src.Status.AppContext.DeepCopyInto(&dst.Status.AppContext)
+
+	return setConversionData(&dst.ObjectMeta, &monitoringConsoleConversionData{
+		PeerSelector:          src.Spec.PeerSelector.DeepCopy(),
+		PeerNamespaceSelector: src.Spec.PeerNamespaceSelector.DeepCopy(),
+		ExternalPeers:         append([]enterpriseApi.MonitoringConsoleExternalPeer(nil), src.Spec.ExternalPeers...),
+		Peers:                 append([]enterpriseApi.MonitoringConsolePeerStatus(nil), src.Status.Peers...),
+		AuditTrail:            copyAuditTrail(src.Status.AuditTrail),
+	})
+}
+
+// copyStringMap returns a copy of a map of strings, keeping nil maps nil
+func copyStringMap(in map[string]string) map[string]string {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]string, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
+// copyBoolMap returns a copy of a map of booleans, keeping nil maps nil
+func copyBoolMap(in map[string]bool) map[string]bool {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]bool, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
+// copyAuditTrail returns a copy of an audit trail, keeping nil trails nil
+func copyAuditTrail(in []enterpriseApi.AuditRecord) []enterpriseApi.AuditRecord {
+	if in == nil {
+		return nil
+	}
+	out := make([]enterpriseApi.AuditRecord, len(in))
+	for i := range in {
+		in[i].DeepCopyInto(&out[i])
+	}
+	return out
+}
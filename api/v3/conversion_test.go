@@ -0,0 +1,198 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v3
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStandaloneConversion(t *testing.T) {
+	hub := enterpriseApi.Standalone{
+		ObjectMeta: metav1.ObjectMeta{Name: "s1", Namespace: "test", Annotations: map[string]string{"a": "b"}},
+		Spec: enterpriseApi.StandaloneSpec{
+			Replicas: 2,
+			Hec: enterpriseApi.HecSpec{
+				Enabled: true,
+				Tokens:  []enterpriseApi.HecTokenSpec{{Name: "logs", DefaultIndex: "main"}},
+			},
+		},
+		Status: enterpriseApi.StandaloneStatus{
+			Phase:           enterpriseApi.PhaseReady,
+			Replicas:        2,
+			ResourceRevMap:  map[string]string{"secret": "1"},
+			Hec:             enterpriseApi.HecStatus{ServiceName: "splunk-s1-standalone-hec"},
+			VolumeExpansion: enterpriseApi.VolumeExpansionStatus{Phase: enterpriseApi.VolumeExpansionResizing},
+			AuditTrail: []enterpriseApi.AuditRecord{{
+				Time:    metav1.Date(2022, 10, 1, 12, 0, 0, 0, time.Local),
+				Action:  "PodDeleted",
+				Target:  "splunk-s1-standalone-0",
+				Outcome: enterpriseApi.AuditSucceeded,
+			}},
+		},
+	}
+	hub.Spec.Image = "splunk/splunk:latest"
+
+	spoke := Standalone{}
+	if err := spoke.ConvertFrom(hub.DeepCopy()); err != nil {
+		t.Fatalf("ConvertFrom() returned error: %v", err)
+	}
+	if spoke.Spec.Replicas != 2 || spoke.Spec.Image != hub.Spec.Image || spoke.Status.Phase != enterpriseApi.PhaseReady {
+		t.Errorf("ConvertFrom() did not convert the fields of v3: %+v", spoke)
+	}
+	if _, ok := spoke.GetAnnotations()[ConversionDataAnnotation]; !ok {
+		t.Errorf("ConvertFrom() did not keep the fields missing in v3")
+	}
+
+	// a v3 client changes the number of replicas
+	spoke.Spec.Replicas = 3
+	hub.Spec.Replicas = 3
+
+	got := enterpriseApi.Standalone{}
+	if err := spoke.ConvertTo(&got); err != nil {
+		t.Fatalf("ConvertTo() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, hub) {
+		t.Errorf("ConvertTo() = %+v; want %+v", got, hub)
+	}
+
+	// the annotation is only set when v4 fields are used
+	spoke = Standalone{}
+	if err := spoke.ConvertFrom(&enterpriseApi.Standalone{ObjectMeta: metav1.ObjectMeta{Name: "s1"}}); err != nil {
+		t.Fatalf("ConvertFrom() returned error: %v", err)
+	}
+	if spoke.GetAnnotations() != nil {
+		t.Errorf("ConvertFrom() annotations = %v; want none", spoke.GetAnnotations())
+	}
+
+	spoke.SetAnnotations(map[string]string{ConversionDataAnnotation: "not json"})
+	if err := spoke.ConvertTo(&enterpriseApi.Standalone{}); err == nil {
+		t.Errorf("ConvertTo() should have returned an error for an invalid annotation")
+	}
+}
+
+func TestIndexerClusterConversion(t *testing.T) {
+	hub := enterpriseApi.IndexerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "idxc", Namespace: "test"},
+		Spec: enterpriseApi.IndexerClusterSpec{
+			Replicas: 3,
+			Hec:      enterpriseApi.HecSpec{Enabled: true},
+		},
+		Status: enterpriseApi.IndexerClusterStatus{
+			Phase:                      enterpriseApi.PhaseReady,
+			ClusterManagerPhase:        enterpriseApi.PhaseReady,
+			IndexerSecretChanged:       []bool{true},
+			IdxcPasswordChangedSecrets: map[string]bool{"s": true},
+			ReplicationFactorMet:       true,
+			Peers:                      []enterpriseApi.IndexerClusterMemberStatus{{Name: "idx-0", BucketCount: 10}},
+			Hec:                        enterpriseApi.HecStatus{ServiceName: "splunk-idxc-indexer-hec"},
+		},
+	}
+	hub.Spec.ClusterManagerRef.Name = "cm"
+
+	spoke := IndexerCluster{}
+	if err := spoke.ConvertFrom(hub.DeepCopy()); err != nil {
+		t.Fatalf("ConvertFrom() returned error: %v", err)
+	}
+	if len(spoke.Status.Peers) != 1 || spoke.Status.Peers[0].BucketCount != 10 || spoke.Spec.ClusterManagerRef.Name != "cm" {
+		t.Errorf("ConvertFrom() did not convert the fields of v3: %+v", spoke)
+	}
+
+	got := enterpriseApi.IndexerCluster{}
+	if err := spoke.ConvertTo(&got); err != nil {
+		t.Fatalf("ConvertTo() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, hub) {
+		t.Errorf("ConvertTo() = %+v; want %+v", got, hub)
+	}
+}
+
+func TestSearchHeadClusterConversion(t *testing.T) {
+	now := metav1.NewTime(time.Unix(1700000000, 0))
+	hub := enterpriseApi.SearchHeadCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "shc", Namespace: "test"},
+		Spec: enterpriseApi.SearchHeadClusterSpec{
+			Replicas:      3,
+			KVStoreBackup: enterpriseApi.KVStoreBackupSpec{Period: &metav1.Duration{Duration: time.Hour}, MaxBackups: 2},
+		},
+		Status: enterpriseApi.SearchHeadClusterStatus{
+			Phase:   enterpriseApi.PhaseReady,
+			Captain: "sh-0",
+			Members: []enterpriseApi.SearchHeadClusterMemberStatus{
+				{Name: "sh-0", Status: "Up", KVStoreStatus: "ready", KVStoreReplicationStatus: "KV store captain"},
+				{Name: "sh-1", Status: "Up"},
+			},
+			KVStore: enterpriseApi.SearchHeadClusterKVStoreStatus{LastBackupArchive: "backup.tgz", LastBackupTime: &now},
+		},
+	}
+
+	spoke := SearchHeadCluster{}
+	if err := spoke.ConvertFrom(hub.DeepCopy()); err != nil {
+		t.Fatalf("ConvertFrom() returned error: %v", err)
+	}
+	if len(spoke.Status.Members) != 2 || spoke.Status.Captain != "sh-0" {
+		t.Errorf("ConvertFrom() did not convert the fields of v3: %+v", spoke)
+	}
+
+	got := enterpriseApi.SearchHeadCluster{}
+	if err := spoke.ConvertTo(&got); err != nil {
+		t.Fatalf("ConvertTo() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, hub) {
+		t.Errorf("ConvertTo() = %+v; want %+v", got, hub)
+	}
+}
+
+func TestMonitoringConsoleConversion(t *testing.T) {
+	hub := enterpriseApi.MonitoringConsole{
+		ObjectMeta: metav1.ObjectMeta{Name: "mc", Namespace: "test"},
+		Spec: enterpriseApi.MonitoringConsoleSpec{
+			PeerSelector:          &metav1.LabelSelector{MatchLabels: map[string]string{"team": "a"}},
+			PeerNamespaceSelector: &metav1.LabelSelector{},
+			ExternalPeers:         []enterpriseApi.MonitoringConsoleExternalPeer{{Host: "idx.example.com", SecretRef: "idx-creds"}},
+		},
+		Status: enterpriseApi.MonitoringConsoleStatus{
+			Phase:          enterpriseApi.PhaseReady,
+			ResourceRevMap: map[string]string{"mc-configmap": "1"},
+			Peers:          []enterpriseApi.MonitoringConsolePeerStatus{{Name: "idx.example.com:8089", Source: "external", Status: "Up"}},
+		},
+	}
+	hub.Spec.ClusterManagerRef.Name = "cm"
+
+	spoke := MonitoringConsole{}
+	if err := spoke.ConvertFrom(hub.DeepCopy()); err != nil {
+		t.Fatalf("ConvertFrom() returned error: %v", err)
+	}
+	if spoke.Status.Phase != enterpriseApi.PhaseReady || spoke.Spec.ClusterManagerRef.Name != "cm" {
+		t.Errorf("ConvertFrom() did not convert the fields of v3: %+v", spoke)
+	}
+	if _, ok := spoke.GetAnnotations()[ConversionDataAnnotation]; !ok {
+		t.Errorf("ConvertFrom() did not keep the fields missing in v3")
+	}
+
+	got := enterpriseApi.MonitoringConsole{}
+	if err := spoke.ConvertTo(&got); err != nil {
+		t.Fatalf("ConvertTo() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got, hub) {
+		t.Errorf("ConvertTo() = %+v; want %+v", got, hub)
+	}
+}
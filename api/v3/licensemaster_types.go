@@ -53,6 +53,9 @@ type LicenseMasterStatus struct {
 
 	// Telemetry App installation flag
 	TelAppInstalled bool `json:"telAppInstalled"`
+
+	// last disruptive actions taken by the operator, oldest first
+	AuditTrail []enterpriseApi.AuditRecord `json:"auditTrail,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -22,6 +22,7 @@ limitations under the License.
 package v3
 
 import (
+	"github.com/splunk/splunk-operator/api/v4"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -115,6 +116,13 @@ func (in *ClusterMasterStatus) DeepCopyInto(out *ClusterMasterStatus) {
 		}
 	}
 	in.AppContext.DeepCopyInto(&out.AppContext)
+	if in.AuditTrail != nil {
+		in, out := &in.AuditTrail, &out.AuditTrail
+		*out = make([]v4.AuditRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterMasterStatus.
@@ -329,6 +337,13 @@ func (in *LicenseMasterSpec) DeepCopy() *LicenseMasterSpec {
 func (in *LicenseMasterStatus) DeepCopyInto(out *LicenseMasterStatus) {
 	*out = *in
 	in.AppContext.DeepCopyInto(&out.AppContext)
+	if in.AuditTrail != nil {
+		in, out := &in.AuditTrail, &out.AuditTrail
+		*out = make([]v4.AuditRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseMasterStatus.
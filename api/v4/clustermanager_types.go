@@ -32,6 +32,14 @@ const (
 	// ClusterManagerPausedAnnotation is the annotation that pauses the reconciliation (triggers
 	// an immediate requeue)
 	ClusterManagerPausedAnnotation = "clustermanager.enterprise.splunk.com/paused"
+
+	// ClusterManagerAdoptClusterMasterAnnotation is the annotation that makes a new ClusterManager adopt
+	// the statefulset, volumes and other resources of the ClusterMaster of the same name, without recreating its pod
+	ClusterManagerAdoptClusterMasterAnnotation = "clustermanager.enterprise.splunk.com/adopt-cluster-master"
+
+	// ClusterManagerBundlePushAnnotation is the annotation that triggers a push of the cluster bundle to
+	// the indexer peers whenever its value changes
+	ClusterManagerBundlePushAnnotation = "clustermanager.enterprise.splunk.com/bundle-push"
 )
 
 // ClusterManagerSpec defines the desired state of ClusterManager
@@ -67,6 +75,15 @@ type ClusterManagerStatus struct {
 
 	// Telemetry App installation flag
 	TelAppInstalled bool `json:"telAppInstalled"`
+
+	// Online expansion of the etc and var volumes
+	VolumeExpansion VolumeExpansionStatus `json:"volumeExpansion,omitempty"`
+
+	// The resources of the ClusterMaster of the same name have been adopted, and keep their names
+	ClusterMasterAdopted bool `json:"clusterMasterAdopted,omitempty"`
+
+	// last disruptive actions taken by the operator, oldest first
+	AuditTrail []AuditRecord `json:"auditTrail,omitempty"`
 }
 
 // BundlePushInfo Indicates if bundle push required
@@ -74,6 +91,9 @@ type BundlePushInfo struct {
 	NeedToPushMasterApps  bool  `json:"needToPushMasterApps"` // NeedToPushMasterApps is an exception needed for dual support
 	NeedToPushManagerApps bool  `json:"needToPushManagerApps"`
 	LastCheckInterval     int64 `json:"lastCheckInterval"`
+
+	// Value of the bundle push annotation of the last requested bundle push
+	LastBundlePushRequest string `json:"lastBundlePushRequest,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -17,6 +17,7 @@ package v4
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -115,7 +116,7 @@ type Spec struct {
 }
 
 // Phase is used to represent the current phase of a custom resource
-// +kubebuilder:validation:Enum=Pending;Ready;Updating;ScalingUp;ScalingDown;Terminating;Error
+// +kubebuilder:validation:Enum=Pending;Ready;Updating;ScalingUp;ScalingDown;Terminating;Error;PendingMaintenanceWindow
 type Phase string
 
 const (
@@ -139,6 +140,9 @@ const (
 
 	// PhaseError means an error occured with custom resource management
 	PhaseError Phase = "Error"
+
+	// PhasePendingMaintenanceWindow means disruptive operations of a custom resource wait for its next maintenance window
+	PhasePendingMaintenanceWindow Phase = "PendingMaintenanceWindow"
 )
 
 // Probe defines set of configurable values for Startup, Readiness, and Liveness probes
@@ -169,9 +173,15 @@ type CommonSplunkSpec struct {
 	// Storage configuration for /opt/splunk/var volume
 	VarVolumeStorageConfig StorageClassSpec `json:"varVolumeStorageConfig"`
 
-	// List of one or more Kubernetes volumes. These will be mounted in all pod containers as as /mnt/<name>
+	// List of one or more Kubernetes volumes. These will be mounted in all pod containers as as /mnt/<name>, unless a mount path is set in volumeMounts
 	Volumes []corev1.Volume `json:"volumes"`
 
+	// Mount paths of the Kubernetes volumes, overriding the default /mnt/<name> of the volume with the same name
+	VolumeMounts []VolumeMountSpec `json:"volumeMounts,omitempty"`
+
+	// Additional persistent volume claim templates, ex. separate volumes for hot and cold buckets on different storage classes
+	VolumeClaimTemplates []VolumeClaimTemplateSpec `json:"volumeClaimTemplates,omitempty"`
+
 	// Inline map of default.yml overrides used to initialize the environment
 	Defaults string `json:"defaults"`
 
@@ -238,6 +248,233 @@ type CommonSplunkSpec struct {
 	// Sets imagePullSecrets if image is being pulled from a private registry.
 	// See https://kubernetes.io/docs/tasks/configure-pod-container/pull-image-private-registry/
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// What happens to the persistent volume claims of the pods on scale down and on deletion of the custom resource
+	PVCRetentionPolicy PVCRetentionPolicy `json:"pvcRetentionPolicy,omitempty"`
+
+	// Additional containers run next to the Splunk instance container in the pods, ex. log shippers or Vault agents.
+	// Changes of these containers restart the pods
+	ExtraContainers []corev1.Container `json:"extraContainers,omitempty"`
+
+	// Additional init containers run before the Splunk instance container starts, ex. to fetch configuration.
+	// Changes of these containers restart the pods
+	ExtraInitContainers []corev1.Container `json:"extraInitContainers,omitempty"`
+
+	// Annotations added to the pods. Changes of the annotations restart the pods
+	PodAnnotations map[string]string `json:"podAnnotations,omitempty"`
+
+	// Labels added to the pods, the labels set by the operator can not be overridden. Changes of the labels restart the pods
+	PodLabels map[string]string `json:"podLabels,omitempty"`
+
+	// Recurring windows in which disruptive operations, like pod recycles, scale downs, bundle pushes and app installs,
+	// are allowed. Defaults to the operator-wide maintenance windows, disruptive operations are always allowed if none
+	// are set
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// Runs the health agent as a sidecar of the Splunk pods to serve their liveness, readiness and startup probes over
+	// HTTPS instead of the probe scripts. Enabling or disabling the agent restarts the pods
+	HealthAgent *HealthAgentSpec `json:"healthAgent,omitempty"`
+
+	// Separate services for each type of traffic of the Splunk instances, in addition to the headless and regular
+	// services, and the Gateway API routes exposing them
+	Services SplunkServicesSpec `json:"services,omitempty"`
+
+	// Certificates of the management, web, S2S and HEC ports, read from Secrets or issued by cert-manager
+	TLS SplunkTLSSpec `json:"tls,omitempty"`
+}
+
+// HealthAgentSpec defines the health agent sidecar of the Splunk pods
+type HealthAgentSpec struct {
+	// Image of the health agent (overrides RELATED_IMAGE_SPLUNK_HEALTH_AGENT environment variable)
+	Image string `json:"image,omitempty"`
+
+	// Resource requirements of the health agent container
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// SplunkServicesSpec defines the services managed for each type of traffic. A service is only created for the
+// traffic types set here and served by the instances, e.g. search heads have no s2s service
+type SplunkServicesSpec struct {
+	// Service for Splunk Web on port 8000. On search head clusters, it keeps the sessions of a client on the same member
+	Web *SplunkRoutedServiceSpec `json:"web,omitempty"`
+
+	// Service for the management port 8089
+	Mgmt *SplunkServiceSpec `json:"mgmt,omitempty"`
+
+	// Service for the forwarders on port 9997
+	S2S *SplunkServiceSpec `json:"s2s,omitempty"`
+
+	// Service for the HTTP Event Collector on port 8088. It customizes the HEC service created with hec.enabled
+	Hec *SplunkRoutedServiceSpec `json:"hec,omitempty"`
+}
+
+// SplunkServiceSpec defines a service for one type of traffic
+type SplunkServiceSpec struct {
+	// Type of the service: ClusterIP, NodePort or LoadBalancer. Defaults to ClusterIP
+	// +kubebuilder:validation:Enum=ClusterIP;NodePort;LoadBalancer
+	Type corev1.ServiceType `json:"type,omitempty"`
+
+	// Annotations added to the service, e.g. to configure cloud load balancers
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Routing of external traffic for NodePort and LoadBalancer services: Cluster or Local. Defaults to Cluster
+	// +kubebuilder:validation:Enum=Cluster;Local
+	ExternalTrafficPolicy corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+
+	// Client IP ranges allowed to reach LoadBalancer services
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+}
+
+// SplunkRoutedServiceSpec defines a service for one type of HTTP traffic, which may be exposed through a Gateway
+type SplunkRoutedServiceSpec struct {
+	SplunkServiceSpec `json:",inline"`
+
+	// Gateway API route exposing the service
+	Route *GatewayRouteSpec `json:"route,omitempty"`
+}
+
+// GatewayRouteKind is the kind of Gateway API route exposing a service
+// +kubebuilder:validation:Enum=HTTPRoute;TLSRoute
+type GatewayRouteKind string
+
+const (
+	// GatewayHTTPRoute terminates TLS at the Gateway and routes HTTP requests to the service
+	GatewayHTTPRoute GatewayRouteKind = "HTTPRoute"
+
+	// GatewayTLSRoute passes TLS connections through the Gateway to the service
+	GatewayTLSRoute GatewayRouteKind = "TLSRoute"
+)
+
+// GatewayRouteSpec defines a Gateway API route exposing a service
+type GatewayRouteSpec struct {
+	// Kind of route to create: HTTPRoute or TLSRoute. Defaults to HTTPRoute
+	Kind GatewayRouteKind `json:"kind,omitempty"`
+
+	// Gateway the route attaches to
+	GatewayRef HecGatewayRef `json:"gatewayRef"`
+
+	// Host names the route answers to. Required by TLSRoute to select the route from the SNI
+	Hostnames []string `json:"hostnames,omitempty"`
+
+	// Additional annotations added to the route
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// SplunkTLSSpec defines the certificates of the ports of the Splunk instances. Ports without a certificate keep the
+// self signed certificate generated by Splunk, e.g. search heads have no s2s certificate
+type SplunkTLSSpec struct {
+	// Certificate of the splunkd management port, which the operator verifies when calling the REST API
+	Mgmt *CertificateSpec `json:"mgmt,omitempty"`
+
+	// Certificate of Splunk Web
+	Web *CertificateSpec `json:"web,omitempty"`
+
+	// Certificate of the port receiving data from forwarders
+	S2S *CertificateSpec `json:"s2s,omitempty"`
+
+	// Certificate of the HTTP Event Collector
+	Hec *CertificateSpec `json:"hec,omitempty"`
+}
+
+// CertificateSpec defines where the certificate of a port comes from: either secretName or issuerRef must be set
+type CertificateSpec struct {
+	// Name of a Secret of type kubernetes.io/tls holding tls.crt, tls.key and optionally ca.crt
+	SecretName string `json:"secretName,omitempty"`
+
+	// cert-manager Issuer of the certificate, which the operator requests through a Certificate
+	IssuerRef *CertificateIssuerRef `json:"issuerRef,omitempty"`
+
+	// Additional DNS names of the certificate requested from the issuer, the names of the services are always included
+	DNSNames []string `json:"dnsNames,omitempty"`
+
+	// Duration of the certificate requested from the issuer, defaults to the one of cert-manager
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// How long before expiry the certificate requested from the issuer is renewed, defaults to the one of cert-manager
+	RenewBefore *metav1.Duration `json:"renewBefore,omitempty"`
+}
+
+// CertificateIssuerRef references a cert-manager Issuer or ClusterIssuer
+type CertificateIssuerRef struct {
+	// Name of the issuer
+	Name string `json:"name"`
+
+	// Kind of the issuer: Issuer or ClusterIssuer. Defaults to Issuer
+	// +kubebuilder:validation:Enum=Issuer;ClusterIssuer
+	Kind string `json:"kind,omitempty"`
+}
+
+// PVCRetentionPolicyType defines what happens to a persistent volume claim that is no longer used
+// +kubebuilder:validation:Enum=Retain;Delete;Snapshot
+type PVCRetentionPolicyType string
+
+const (
+	// PVCRetentionPolicyRetain keeps the persistent volume claim
+	PVCRetentionPolicyRetain PVCRetentionPolicyType = "Retain"
+
+	// PVCRetentionPolicyDelete deletes the persistent volume claim
+	PVCRetentionPolicyDelete PVCRetentionPolicyType = "Delete"
+
+	// PVCRetentionPolicySnapshot takes a CSI volume snapshot of the persistent volume claim before deleting it
+	PVCRetentionPolicySnapshot PVCRetentionPolicyType = "Snapshot"
+)
+
+const (
+	// PVCRetentionWhenScaledAnnotation is set on the StatefulSets to the whenScaled PVC retention policy
+	PVCRetentionWhenScaledAnnotation = "enterprise.splunk.com/pvc-retention-when-scaled"
+
+	// VolumeSnapshotClassAnnotation is set on the StatefulSets to the VolumeSnapshotClass used by the Snapshot PVC retention policy
+	VolumeSnapshotClassAnnotation = "enterprise.splunk.com/volume-snapshot-class"
+
+	// PlanAnnotation makes the operator report the changes a reconcile of a custom resource would make in a
+	// ConfigMap, instead of making them
+	PlanAnnotation = "enterprise.splunk.com/plan"
+
+	// MaintenanceWindowsAnnotation is set on the StatefulSets to the JSON of the maintenance windows in effect
+	MaintenanceWindowsAnnotation = "enterprise.splunk.com/maintenance-windows"
+
+	// MaintenanceInProgressAnnotation is set on the StatefulSets to the pod whose disruptive operation started within a
+	// maintenance window, so that it is completed after the window closed
+	MaintenanceInProgressAnnotation = "enterprise.splunk.com/maintenance-in-progress"
+
+	// MaintenanceOverrideAnnotation allows the disruptive operations of a custom resource outside of its maintenance windows
+	MaintenanceOverrideAnnotation = "enterprise.splunk.com/maintenance-override"
+
+	// DisruptionPriorityLabel sets the priority of a custom resource for the operator-wide disruption budget, as an
+	// integer. Custom resources with a higher priority recycle or remove their pods first, the default is 0. The label
+	// is copied to the StatefulSets as an annotation
+	DisruptionPriorityLabel = "enterprise.splunk.com/disruption-priority"
+
+	// RestartPodsAnnotation requests the restart of some pods of a custom resource, as the JSON of a map of the
+	// pod names to the RFC 3339 time of the request. Pods created before the time of their request are recycled
+	// like for a change of revision, the annotation is copied to the StatefulSets
+	RestartPodsAnnotation = "enterprise.splunk.com/restart-pods"
+)
+
+// MaintenanceWindow is a recurring period of time in which disruptive operations are allowed
+type MaintenanceWindow struct {
+	// Cron expression of the start of the window, with minute, hour, day of month, month and day of week fields.
+	// ex. "0 2 * * SAT" starts the window every Saturday at 02:00
+	Schedule string `json:"schedule"`
+
+	// Length of the window, ex. "4h" or "90m"
+	Duration string `json:"duration"`
+
+	// IANA time zone of the schedule, ex. "Europe/Berlin", defaults to UTC
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// PVCRetentionPolicy defines what happens to the persistent volume claims of the pods
+type PVCRetentionPolicy struct {
+	// Policy applied to the persistent volume claims of a pod removed by a scale down, defaults to Delete
+	WhenScaled PVCRetentionPolicyType `json:"whenScaled,omitempty"`
+
+	// Policy applied to the persistent volume claims when the custom resource is deleted. Defaults to Delete when the
+	// enterprise.splunk.com/delete-pvc finalizer is set, Retain otherwise. Snapshot requires the finalizer
+	WhenDeleted PVCRetentionPolicyType `json:"whenDeleted,omitempty"`
+
+	// Name of the VolumeSnapshotClass used by the Snapshot policy, defaults to the default class of the CSI driver
+	VolumeSnapshotClassName string `json:"volumeSnapshotClassName,omitempty"`
 }
 
 // StorageClassSpec defines storage class configuration
@@ -254,6 +491,37 @@ type StorageClassSpec struct {
 	EphemeralStorage bool `json:"ephemeralStorage"`
 }
 
+// VolumeMountSpec defines where a Kubernetes volume is mounted in the pod containers
+type VolumeMountSpec struct {
+	// Name of the volume
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Absolute path the volume is mounted at
+	// +kubebuilder:validation:Required
+	MountPath string `json:"mountPath"`
+
+	// Mount the volume read-only
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// VolumeClaimTemplateSpec defines an additional persistent volume claim template
+type VolumeClaimTemplateSpec struct {
+	// Name of the volume, the persistent volume claims are named <name>-<statefulset name>-<ordinal>
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Absolute path the volume is mounted at in the splunk container, defaults to /mnt/<name>
+	MountPath string `json:"mountPath,omitempty"`
+
+	// Name of StorageClass to use for the persistent volume claims, defaults to the default StorageClass
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// Storage capacity to request for the persistent volume claims
+	// +kubebuilder:validation:Required
+	StorageCapacity string `json:"storageCapacity"`
+}
+
 // SmartStoreSpec defines Splunk indexes and remote storage volume configuration
 type SmartStoreSpec struct {
 	// List of remote storage volumes
@@ -358,6 +626,175 @@ type IndexAndCacheManagerCommonSpec struct {
 	HotlistBloomFilterRecencyHours uint `json:"hotlistBloomFilterRecencyHours,omitempty"`
 }
 
+// HecSpec defines the HTTP Event Collector configuration managed by the operator
+type HecSpec struct {
+	// Enables the operator managed HEC tokens, service and ingress route
+	Enabled bool `json:"enabled,omitempty"`
+
+	// List of named HEC tokens. A Secret holding the token value is created for each entry
+	Tokens []HecTokenSpec `json:"tokens,omitempty"`
+
+	// Configuration for exposing HEC outside of the cluster
+	Ingress HecIngressSpec `json:"ingress,omitempty"`
+}
+
+// HecTokenSpec defines a named HEC token and the indexes it may write to
+type HecTokenSpec struct {
+	// Name of the token, used as the HEC input stanza name. Must be a valid DNS label
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Index events are written to when the request does not specify one
+	DefaultIndex string `json:"defaultIndex,omitempty"`
+
+	// Indexes the token is allowed to write to
+	AllowedIndexes []string `json:"allowedIndexes,omitempty"`
+
+	// Default sourcetype for events received with this token
+	SourceType string `json:"sourceType,omitempty"`
+
+	// Disables the token without removing it
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// HecIngressType is the kind of route used to expose HEC
+// +kubebuilder:validation:Enum=None;Ingress;Gateway
+type HecIngressType string
+
+const (
+	// HecIngressNone only exposes HEC through the in-cluster service
+	HecIngressNone HecIngressType = "None"
+
+	// HecIngressIngress exposes HEC using a networking.k8s.io/v1 Ingress
+	HecIngressIngress HecIngressType = "Ingress"
+
+	// HecIngressGateway exposes HEC using a Gateway API HTTPRoute
+	HecIngressGateway HecIngressType = "Gateway"
+)
+
+// HecIngressSpec defines how HEC is exposed outside of the cluster
+type HecIngressSpec struct {
+	// Type of route to create. Supported values: None, Ingress, Gateway
+	Type HecIngressType `json:"type,omitempty"`
+
+	// Host name the route answers to
+	Host string `json:"host,omitempty"`
+
+	// Name of the IngressClass used by the Ingress
+	IngressClassName string `json:"ingressClassName,omitempty"`
+
+	// Name of a kubernetes.io/tls Secret used to terminate TLS on the Ingress. For Gateway routes,
+	// TLS is terminated by the referenced Gateway listener
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// Additional annotations added to the Ingress or HTTPRoute
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Gateway the HTTPRoute attaches to
+	GatewayRef HecGatewayRef `json:"gatewayRef,omitempty"`
+}
+
+// HecGatewayRef identifies a Gateway API Gateway and optionally one of its listeners
+type HecGatewayRef struct {
+	// Name of the Gateway
+	Name string `json:"name,omitempty"`
+
+	// Namespace of the Gateway, defaults to the namespace of the custom resource
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the Gateway listener
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// HecTokenStatus defines the observed state of a HEC token
+type HecTokenStatus struct {
+	// Name of the token
+	Name string `json:"name"`
+
+	// Name of the Secret holding the token value
+	SecretName string `json:"secretName"`
+}
+
+// HecStatus defines the observed state of the operator managed HEC configuration
+type HecStatus struct {
+	// Name of the service load balancing HEC traffic across ready pods
+	ServiceName string `json:"serviceName,omitempty"`
+
+	// Status of the managed tokens
+	Tokens []HecTokenStatus `json:"tokens,omitempty"`
+}
+
+// VolumeExpansionPhase is used to represent the current phase of an online persistent volume expansion
+type VolumeExpansionPhase string
+
+const (
+	// VolumeExpansionExpanding means the storage requests of the persistent volume claims are being raised
+	VolumeExpansionExpanding VolumeExpansionPhase = "Expanding"
+
+	// VolumeExpansionResizing means the operator waits for the file systems of a pod to be resized
+	VolumeExpansionResizing VolumeExpansionPhase = "Resizing"
+
+	// VolumeExpansionRecreating means the StatefulSet is recreated with the new volume claim templates, once all volumes were resized
+	// or when volume claim templates were added or removed
+	VolumeExpansionRecreating VolumeExpansionPhase = "Recreating"
+
+	// VolumeExpansionCompleted means the last volume expansion has finished
+	VolumeExpansionCompleted VolumeExpansionPhase = "Completed"
+
+	// VolumeExpansionUnsupported means the storage class of the volumes does not allow volume expansion
+	VolumeExpansionUnsupported VolumeExpansionPhase = "Unsupported"
+)
+
+// VolumeExpansionStatus defines the observed state of an online expansion of the etc and var volumes
+type VolumeExpansionStatus struct {
+	// current phase of the volume expansion
+	Phase VolumeExpansionPhase `json:"phase,omitempty"`
+
+	// storage capacity requested for each expanded volume claim template
+	Capacity map[string]string `json:"capacity,omitempty"`
+
+	// number of pods whose volumes have been resized
+	ResizedPods int32 `json:"resizedPods,omitempty"`
+
+	// details about the current phase
+	Message string `json:"message,omitempty"`
+}
+
+// AuditOutcome is the outcome of a disruptive action of the operator
+type AuditOutcome string
+
+const (
+	// AuditSucceeded means the action was taken
+	AuditSucceeded AuditOutcome = "Succeeded"
+
+	// AuditFailed means the action was attempted and failed
+	AuditFailed AuditOutcome = "Failed"
+)
+
+// AuditRecord is a disruptive action taken by the operator for a custom resource, e.g. the deletion of a pod
+type AuditRecord struct {
+	// time of the action
+	Time metav1.Time `json:"time"`
+
+	// action taken: PodDeleted, PodRemoved, PeerDecommissioned, BundlePushed or SecretChanged
+	Action string `json:"action"`
+
+	// object the action was taken on, e.g. the name of a pod
+	Target string `json:"target"`
+
+	// why the action was taken
+	Reason string `json:"reason,omitempty"`
+
+	// generation of the spec of the custom resource when the action was taken
+	Generation int64 `json:"generation,omitempty"`
+
+	// outcome of the action
+	Outcome AuditOutcome `json:"outcome"`
+
+	// error of a failed action
+	Error string `json:"error,omitempty"`
+}
+
 // AppSourceDefaultSpec defines config common for defaults and App Sources
 type AppSourceDefaultSpec struct {
 	// Remote Storage Volume name
@@ -0,0 +1,47 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v4
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// v4 is the hub version of the kinds served in both v3 and v4: the other versions are converted from and to it
+
+// Hub marks Standalone as a conversion hub
+func (*Standalone) Hub() {}
+
+// Hub marks IndexerCluster as a conversion hub
+func (*IndexerCluster) Hub() {}
+
+// Hub marks SearchHeadCluster as a conversion hub
+func (*SearchHeadCluster) Hub() {}
+
+// Hub marks MonitoringConsole as a conversion hub
+func (*MonitoringConsole) Hub() {}
+
+// SetupConversionWebhookWithManager registers the conversion webhook of the kinds served in both v3 and v4,
+// which the scheme of the manager must know in both versions
+func SetupConversionWebhookWithManager(mgr ctrl.Manager) error {
+	for _, obj := range []runtime.Object{&Standalone{}, &IndexerCluster{}, &SearchHeadCluster{}, &MonitoringConsole{}} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(obj).Complete(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v4
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// default all fields to being optional
+// +kubebuilder:validation:Optional
+
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+// Add custom validation using kubebuilder tags: https://book-v1.book.kubebuilder.io/beyond_basics/generating_crd.html
+// see also https://book.kubebuilder.io/reference/markers/crd.html
+
+const (
+	// DeploymentServerPausedAnnotation is the annotation that pauses the reconciliation (triggers
+	// an immediate requeue)
+	DeploymentServerPausedAnnotation = "deploymentserver.enterprise.splunk.com/paused"
+)
+
+// AppStateOnClient is the state a deployed app is set to on the deployment clients
+// +kubebuilder:validation:Enum=enabled;disabled;noop
+type AppStateOnClient string
+
+const (
+	// AppStateOnClientEnabled enables the app on the deployment clients
+	AppStateOnClientEnabled AppStateOnClient = "enabled"
+
+	// AppStateOnClientDisabled disables the app on the deployment clients
+	AppStateOnClientDisabled AppStateOnClient = "disabled"
+
+	// AppStateOnClientNoop leaves the app state on the deployment clients untouched
+	AppStateOnClientNoop AppStateOnClient = "noop"
+)
+
+// ServerClassAppSpec defines an app from etc/deployment-apps assigned to a server class
+type ServerClassAppSpec struct {
+	// Name of the app directory in etc/deployment-apps
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Restart splunkd on the deployment clients after the app is installed
+	RestartSplunkd bool `json:"restartSplunkd,omitempty"`
+
+	// State of the app on the deployment clients, defaults to enabled
+	StateOnClient AppStateOnClient `json:"stateOnClient,omitempty"`
+}
+
+// ServerClassSpec defines a server class rendered into serverclass.conf
+type ServerClassSpec struct {
+	// Name of the server class
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Deployment clients matching any of these host names, IP addresses or DNS names (wildcards allowed) are part of the server class
+	Whitelist []string `json:"whitelist,omitempty"`
+
+	// Deployment clients matching any of these filters are excluded from the server class
+	Blacklist []string `json:"blacklist,omitempty"`
+
+	// Only deployment clients of these machine types (ex. linux-x86_64) are part of the server class
+	MachineTypesFilter []string `json:"machineTypesFilter,omitempty"`
+
+	// Apps deployed to the members of the server class
+	Apps []ServerClassAppSpec `json:"apps,omitempty"`
+}
+
+// DeploymentServerSpec defines the desired state of a Splunk Enterprise deployment server.
+type DeploymentServerSpec struct {
+	CommonSplunkSpec `json:",inline"`
+
+	// Splunk enterprise App repository. Specifies remote App location and scope for Splunk App management.
+	// Cluster scoped apps are synced into etc/deployment-apps, local scoped apps are installed on the deployment server itself
+	AppFrameworkConfig AppFrameworkSpec `json:"appRepo,omitempty"`
+
+	// Server classes rendered into serverclass.conf of the deployment server
+	ServerClasses []ServerClassSpec `json:"serverClasses,omitempty"`
+}
+
+// DeploymentServerStatus defines the observed state of a Splunk Enterprise deployment server.
+type DeploymentServerStatus struct {
+	// current phase of the deployment server
+	Phase Phase `json:"phase"`
+
+	// App Framework Context
+	AppContext AppDeploymentContext `json:"appContext"`
+
+	// Telemetry App installation flag
+	TelAppInstalled bool `json:"telAppInstalled"`
+
+	// number of deployment clients that phoned home to the deployment server
+	ClientCount int32 `json:"clientCount"`
+
+	// last disruptive actions taken by the operator, oldest first
+	AuditTrail []AuditRecord `json:"auditTrail,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeploymentServer is the Schema for a Splunk Enterprise deployment server.
+// +k8s:openapi-gen=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=deploymentservers,scope=Namespaced,shortName=ds
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Status of deployment server"
+// +kubebuilder:printcolumn:name="Clients",type="integer",JSONPath=".status.clientCount",description="Number of deployment clients"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Age of deployment server"
+// +kubebuilder:storageversion
+type DeploymentServer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeploymentServerSpec   `json:"spec,omitempty"`
+	Status DeploymentServerStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DeploymentServerList contains a list of DeploymentServer
+type DeploymentServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeploymentServer `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DeploymentServer{}, &DeploymentServerList{})
+}
+
+// NewEvent creates a new event associated with the object and ready
+// to be published to the kubernetes API.
+func (ds *DeploymentServer) NewEvent(eventType, reason, message string) corev1.Event {
+	t := metav1.Now()
+	return corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: reason + "-",
+			Namespace:    ds.ObjectMeta.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:       "DeploymentServer",
+			Namespace:  ds.Namespace,
+			Name:       ds.Name,
+			UID:        ds.UID,
+			APIVersion: GroupVersion.String(),
+		},
+		Reason:  reason,
+		Message: message,
+		Source: corev1.EventSource{
+			Component: "splunk-deploymentserver-controller",
+		},
+		FirstTimestamp:      t,
+		LastTimestamp:       t,
+		Count:               1,
+		Type:                eventType,
+		ReportingController: "enterprise.splunk.com/deploymentserver-controller",
+	}
+}
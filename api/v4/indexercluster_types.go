@@ -32,6 +32,10 @@ const (
 	// IndexerClusterPausedAnnotation is the annotation that pauses the reconciliation (triggers
 	// an immediate requeue)
 	IndexerClusterPausedAnnotation = "indexercluster.enterprise.splunk.com/paused"
+
+	// IndexerClusterMaintenanceModeAnnotation is the annotation that keeps the indexer cluster in maintenance
+	// mode while it is set, maintenance mode is turned off once it is removed
+	IndexerClusterMaintenanceModeAnnotation = "indexercluster.enterprise.splunk.com/maintenance-mode"
 )
 
 // IndexerClusterSpec defines the desired state of a Splunk Enterprise indexer cluster
@@ -40,6 +44,9 @@ type IndexerClusterSpec struct {
 
 	// Number of search head pods; a search head cluster will be created if > 1
 	Replicas int32 `json:"replicas"`
+
+	// HTTP Event Collector tokens, service and ingress configuration
+	Hec HecSpec `json:"hec,omitempty"`
 }
 
 // IndexerClusterMemberStatus is used to track the status of each indexer cluster peer.
@@ -106,8 +113,26 @@ type IndexerClusterStatus struct {
 	// Indicates if the cluster is in maintenance mode.
 	MaintenanceMode bool `json:"maintenance_mode"`
 
+	// Maintenance mode was turned on for the maintenance mode annotation
+	MaintenanceModeRequested bool `json:"maintenanceModeRequested,omitempty"`
+
+	// Indicates if the replication factor (and site replication factor) of the cluster is met.
+	ReplicationFactorMet bool `json:"replication_factor_met,omitempty"`
+
+	// Indicates if the search factor (and site search factor) of the cluster is met.
+	SearchFactorMet bool `json:"search_factor_met,omitempty"`
+
 	// status of each indexer cluster peer
 	Peers []IndexerClusterMemberStatus `json:"peers"`
+
+	// HTTP Event Collector status
+	Hec HecStatus `json:"hec,omitempty"`
+
+	// Online expansion of the etc and var volumes
+	VolumeExpansion VolumeExpansionStatus `json:"volumeExpansion,omitempty"`
+
+	// last disruptive actions taken by the operator, oldest first
+	AuditTrail []AuditRecord `json:"auditTrail,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
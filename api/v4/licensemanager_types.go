@@ -18,6 +18,7 @@ package v4
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -34,12 +35,114 @@ const (
 	LicenseManagerPausedAnnotation = "licensemanager.enterprise.splunk.com/paused"
 )
 
+// LicenseSecretSpec references a Splunk Enterprise license file stored in a Kubernetes secret
+type LicenseSecretSpec struct {
+	// Name of the secret holding the license file
+	// +kubebuilder:validation:Required
+	SecretName string `json:"secretName"`
+
+	// Key of the license file within the secret
+	// +kubebuilder:validation:Required
+	Key string `json:"key"`
+}
+
+// LicensePoolPeerRef references a custom resource whose instances are assigned to a license pool
+type LicensePoolPeerRef struct {
+	// Kind of the custom resource
+	// +kubebuilder:validation:Enum=IndexerCluster;Standalone
+	// +kubebuilder:validation:Required
+	Kind string `json:"kind"`
+
+	// Name of the custom resource
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// LicensePoolSpec defines a license pool carved out of a license stack
+type LicensePoolSpec struct {
+	// Name of the license pool
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Description of the license pool
+	Description string `json:"description,omitempty"`
+
+	// License stack the pool draws from, defaults to enterprise
+	StackID string `json:"stackId,omitempty"`
+
+	// Daily indexing quota of the pool (ex. 10G), defaults to the full quota of the stack
+	Quota *resource.Quantity `json:"quota,omitempty"`
+
+	// Custom resources whose instances are assigned to the pool
+	Peers []LicensePoolPeerRef `json:"peers,omitempty"`
+}
+
 // LicenseManagerSpec defines the desired state of a Splunk Enterprise license manager.
 type LicenseManagerSpec struct {
 	CommonSplunkSpec `json:",inline"`
 
 	// Splunk enterprise App repository. Specifies remote App location and scope for Splunk App management
 	AppFrameworkConfig AppFrameworkSpec `json:"appRepo,omitempty"`
+
+	// License files installed on the license manager, in addition to any listed in licenseUrl
+	Licenses []LicenseSecretSpec `json:"licenses,omitempty"`
+
+	// License pools managed by the operator
+	Pools []LicensePoolSpec `json:"pools,omitempty"`
+}
+
+// LicenseInfo defines the observed state of a license installed on the license manager
+type LicenseInfo struct {
+	// label of the license
+	Label string `json:"label"`
+
+	// license stack the license belongs to
+	StackID string `json:"stackId"`
+
+	// type of the license
+	Type string `json:"type"`
+
+	// status of the license (ex. VALID, EXPIRED)
+	Status string `json:"status"`
+
+	// daily indexing quota of the license in bytes
+	Quota int64 `json:"quota"`
+
+	// expiration time of the license in epoch seconds
+	ExpirationTime int64 `json:"expirationTime"`
+}
+
+// LicenseStackInfo defines the observed state of a license stack
+type LicenseStackInfo struct {
+	// name of the license stack
+	Name string `json:"name"`
+
+	// daily indexing quota of the stack in bytes
+	Quota int64 `json:"quota"`
+
+	// bytes indexed today against the stack
+	UsedBytes int64 `json:"usedBytes"`
+
+	// number of license violation messages raised for the stack
+	Violations int32 `json:"violations"`
+}
+
+// LicensePoolInfo defines the observed state of a license pool managed by the operator
+type LicensePoolInfo struct {
+	// name of the license pool
+	Name string `json:"name"`
+
+	// license stack the pool draws from
+	StackID string `json:"stackId"`
+
+	// daily indexing quota of the pool in bytes
+	Quota int64 `json:"quota"`
+
+	// bytes indexed today against the pool
+	UsedBytes int64 `json:"usedBytes"`
+
+	// number of license peers assigned to the pool
+	Peers int32 `json:"peers"`
 }
 
 // LicenseManagerStatus defines the observed state of a Splunk Enterprise license manager.
@@ -52,6 +155,18 @@ type LicenseManagerStatus struct {
 
 	// Telemetry App installation flag
 	TelAppInstalled bool `json:"telAppInstalled"`
+
+	// licenses installed on the license manager
+	Licenses []LicenseInfo `json:"licenses,omitempty"`
+
+	// license stacks with their daily usage
+	Stacks []LicenseStackInfo `json:"stacks,omitempty"`
+
+	// license pools managed by the operator with their daily usage
+	Pools []LicensePoolInfo `json:"pools,omitempty"`
+
+	// last disruptive actions taken by the operator, oldest first
+	AuditTrail []AuditRecord `json:"auditTrail,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
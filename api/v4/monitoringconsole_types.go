@@ -34,12 +34,47 @@ const (
 	MonitoringConsolePausedAnnotation = "monitoringconsole.enterprise.splunk.com/paused"
 )
 
+// MonitoringConsoleExternalPeer defines a Splunk Enterprise instance not managed by the operator added as a distributed peer
+type MonitoringConsoleExternalPeer struct {
+	// Management address of the peer as host or host:port, the port defaults to 8089
+	// +kubebuilder:validation:Required
+	Host string `json:"host"`
+
+	// Name of a secret in the namespace of the monitoring console holding the credentials of the peer
+	// under the "password" key and the optional "username" key, the username defaults to admin
+	// +kubebuilder:validation:Required
+	SecretRef string `json:"secretRef"`
+}
+
 // MonitoringConsoleSpec defines the desired state of MonitoringConsole
 type MonitoringConsoleSpec struct {
 	CommonSplunkSpec `json:",inline"`
 
 	// Splunk Enterprise App repository. Specifies remote App location and scope for Splunk App management
 	AppFrameworkConfig AppFrameworkSpec `json:"appRepo,omitempty"`
+
+	// Splunk Enterprise custom resources matching the selector are added as distributed peers,
+	// in addition to the ones referencing the monitoring console with monitoringConsoleRef
+	PeerSelector *metav1.LabelSelector `json:"peerSelector,omitempty"`
+
+	// Namespaces searched for custom resources matching peerSelector. Only the namespace of the
+	// monitoring console is searched when not set, an empty selector matches all namespaces
+	PeerNamespaceSelector *metav1.LabelSelector `json:"peerNamespaceSelector,omitempty"`
+
+	// Splunk Enterprise instances not managed by the operator added as distributed peers
+	ExternalPeers []MonitoringConsoleExternalPeer `json:"externalPeers,omitempty"`
+}
+
+// MonitoringConsolePeerStatus defines the observed state of a distributed peer added by the operator
+type MonitoringConsolePeerStatus struct {
+	// management address of the peer, host:port
+	Name string `json:"name"`
+
+	// origin of the peer, <kind>/<namespace>/<name> of the selected custom resource or external
+	Source string `json:"source"`
+
+	// status of the peer reported by the monitoring console
+	Status string `json:"status,omitempty"`
 }
 
 // MonitoringConsoleStatus defines the observed state of MonitoringConsole
@@ -58,6 +93,12 @@ type MonitoringConsoleStatus struct {
 
 	// App Framework status
 	AppContext AppDeploymentContext `json:"appContext,omitempty"`
+
+	// Distributed peers added from peerSelector and externalPeers
+	Peers []MonitoringConsolePeerStatus `json:"peers,omitempty"`
+
+	// last disruptive actions taken by the operator, oldest first
+	AuditTrail []AuditRecord `json:"auditTrail,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -33,8 +33,26 @@ const (
 	// SearchHeadClusterPausedAnnotation is the annotation that pauses the reconciliation (triggers
 	// an immediate requeue)
 	SearchHeadClusterPausedAnnotation = "searchheadcluster.enterprise.splunk.com/paused"
+
+	// SearchHeadClusterKVStoreBackupAnnotation is the annotation that triggers an on-demand KV store
+	// backup whenever its value changes
+	SearchHeadClusterKVStoreBackupAnnotation = "searchheadcluster.enterprise.splunk.com/kvstore-backup"
+
+	// SearchHeadClusterKVStoreRestoreAnnotation is the annotation that restores the KV store from the
+	// backup archive named by its value whenever its value changes
+	SearchHeadClusterKVStoreRestoreAnnotation = "searchheadcluster.enterprise.splunk.com/kvstore-restore"
 )
 
+// KVStoreBackupSpec defines the KV store backups taken by the operator
+type KVStoreBackupSpec struct {
+	// Interval between scheduled KV store backups (ex. 24h); scheduled backups are disabled if not set
+	Period *metav1.Duration `json:"period,omitempty"`
+
+	// Number of backup archives taken by the operator to keep, older archives are removed. Defaults to 7
+	// +kubebuilder:validation:Minimum=1
+	MaxBackups int32 `json:"maxBackups,omitempty"`
+}
+
 // SearchHeadClusterSpec defines the desired state of a Splunk Enterprise search head cluster
 type SearchHeadClusterSpec struct {
 	CommonSplunkSpec `json:",inline"`
@@ -44,6 +62,9 @@ type SearchHeadClusterSpec struct {
 
 	// Splunk Enterprise App repository. Specifies remote App location and scope for Splunk App management
 	AppFrameworkConfig AppFrameworkSpec `json:"appRepo,omitempty"`
+
+	// KV store backups taken by the operator
+	KVStoreBackup KVStoreBackupSpec `json:"kvStoreBackup,omitempty"`
 }
 
 // SearchHeadClusterMemberStatus is used to track the status of each search head cluster member
@@ -65,6 +86,42 @@ type SearchHeadClusterMemberStatus struct {
 
 	// Number of currently running realtime searches.
 	ActiveRealtimeSearchCount int `json:"active_realtime_search_count"`
+
+	// Status of the KV store of this member (ex. ready, starting, failed).
+	KVStoreStatus string `json:"kvstore_status"`
+
+	// KV store replication status of this member (ex. KV store captain, Non-captain KV store member, Recovering).
+	KVStoreReplicationStatus string `json:"kvstore_replication_status"`
+
+	// Time the KV store of this member was first seen failed or recovering.
+	KVStoreStaleTime *metav1.Time `json:"kvstore_stale_time,omitempty"`
+
+	// Time the operator last resynced the KV store of this member.
+	KVStoreResyncTime *metav1.Time `json:"kvstore_resync_time,omitempty"`
+}
+
+// SearchHeadClusterKVStoreStatus is used to track the KV store backups and restores run by the operator
+type SearchHeadClusterKVStoreStatus struct {
+	// name of the last backup archive taken by the operator
+	LastBackupArchive string `json:"lastBackupArchive,omitempty"`
+
+	// time of the last backup taken by the operator
+	LastBackupTime *metav1.Time `json:"lastBackupTime,omitempty"`
+
+	// search head pod keeping the backup archives on its var volume; the archives are not copied off the pod
+	BackupPod string `json:"backupPod,omitempty"`
+
+	// directory of the backup archives on the backup pod
+	BackupPath string `json:"backupPath,omitempty"`
+
+	// value of the backup annotation handled last
+	LastBackupRequest string `json:"lastBackupRequest,omitempty"`
+
+	// name of the backup archive restored last
+	LastRestoreArchive string `json:"lastRestoreArchive,omitempty"`
+
+	// time of the last restore run by the operator
+	LastRestoreTime *metav1.Time `json:"lastRestoreTime,omitempty"`
 }
 
 // SearchHeadClusterStatus defines the observed state of a Splunk Enterprise search head cluster
@@ -114,11 +171,20 @@ type SearchHeadClusterStatus struct {
 	// status of each search head cluster member
 	Members []SearchHeadClusterMemberStatus `json:"members"`
 
+	// KV store backups and restores run by the operator
+	KVStore SearchHeadClusterKVStoreStatus `json:"kvStore,omitempty"`
+
+	// Online expansion of the etc and var volumes
+	VolumeExpansion VolumeExpansionStatus `json:"volumeExpansion,omitempty"`
+
 	// App Framework Context
 	AppContext AppDeploymentContext `json:"appContext"`
 
 	// Telemetry App installation flag
 	TelAppInstalled bool `json:"telAppInstalled"`
+
+	// last disruptive actions taken by the operator, oldest first
+	AuditTrail []AuditRecord `json:"auditTrail,omitempty"`
 }
 
 // SearchHeadCluster is the Schema for a Splunk Enterprise search head cluster
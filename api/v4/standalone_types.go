@@ -46,6 +46,9 @@ type StandaloneSpec struct {
 
 	// Splunk Enterprise App repository. Specifies remote App location and scope for Splunk App management
 	AppFrameworkConfig AppFrameworkSpec `json:"appRepo,omitempty"`
+
+	// HTTP Event Collector tokens, service and ingress configuration
+	Hec HecSpec `json:"hec,omitempty"`
 }
 
 // StandaloneStatus defines the observed state of a Splunk Enterprise standalone instances.
@@ -73,6 +76,15 @@ type StandaloneStatus struct {
 
 	// Telemetry App installation flag
 	TelAppInstalled bool `json:"telAppInstalled"`
+
+	// HTTP Event Collector status
+	Hec HecStatus `json:"hec,omitempty"`
+
+	// Online expansion of the etc and var volumes
+	VolumeExpansion VolumeExpansionStatus `json:"volumeExpansion,omitempty"`
+
+	// last disruptive actions taken by the operator, oldest first
+	AuditTrail []AuditRecord `json:"auditTrail,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -23,7 +23,8 @@ package v4
 
 import (
 	"k8s.io/api/core/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -151,6 +152,22 @@ func (in *AppSrcDeployInfo) DeepCopy() *AppSrcDeployInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditRecord) DeepCopyInto(out *AuditRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditRecord.
+func (in *AuditRecord) DeepCopy() *AuditRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BundlePushInfo) DeepCopyInto(out *BundlePushInfo) {
 	*out = *in
@@ -197,6 +214,56 @@ func (in *CacheManagerSpec) DeepCopy() *CacheManagerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateIssuerRef) DeepCopyInto(out *CertificateIssuerRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateIssuerRef.
+func (in *CertificateIssuerRef) DeepCopy() *CertificateIssuerRef {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateIssuerRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateSpec) DeepCopyInto(out *CertificateSpec) {
+	*out = *in
+	if in.IssuerRef != nil {
+		in, out := &in.IssuerRef, &out.IssuerRef
+		*out = new(CertificateIssuerRef)
+		**out = **in
+	}
+	if in.DNSNames != nil {
+		in, out := &in.DNSNames, &out.DNSNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RenewBefore != nil {
+		in, out := &in.RenewBefore, &out.RenewBefore
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateSpec.
+func (in *CertificateSpec) DeepCopy() *CertificateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterManager) DeepCopyInto(out *ClusterManager) {
 	*out = *in
@@ -287,6 +354,14 @@ func (in *ClusterManagerStatus) DeepCopyInto(out *ClusterManagerStatus) {
 		}
 	}
 	in.AppContext.DeepCopyInto(&out.AppContext)
+	in.VolumeExpansion.DeepCopyInto(&out.VolumeExpansion)
+	if in.AuditTrail != nil {
+		in, out := &in.AuditTrail, &out.AuditTrail
+		*out = make([]AuditRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterManagerStatus.
@@ -312,6 +387,16 @@ func (in *CommonSplunkSpec) DeepCopyInto(out *CommonSplunkSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]VolumeMountSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.VolumeClaimTemplates != nil {
+		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
+		*out = make([]VolumeClaimTemplateSpec, len(*in))
+		copy(*out, *in)
+	}
 	out.LicenseMasterRef = in.LicenseMasterRef
 	out.LicenseManagerRef = in.LicenseManagerRef
 	out.ClusterMasterRef = in.ClusterMasterRef
@@ -344,6 +429,47 @@ func (in *CommonSplunkSpec) DeepCopyInto(out *CommonSplunkSpec) {
 		*out = make([]v1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	out.PVCRetentionPolicy = in.PVCRetentionPolicy
+	if in.ExtraContainers != nil {
+		in, out := &in.ExtraContainers, &out.ExtraContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraInitContainers != nil {
+		in, out := &in.ExtraInitContainers, &out.ExtraInitContainers
+		*out = make([]v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodAnnotations != nil {
+		in, out := &in.PodAnnotations, &out.PodAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.PodLabels != nil {
+		in, out := &in.PodLabels, &out.PodLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.HealthAgent != nil {
+		in, out := &in.HealthAgent, &out.HealthAgent
+		*out = new(HealthAgentSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Services.DeepCopyInto(&out.Services)
+	in.TLS.DeepCopyInto(&out.TLS)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonSplunkSpec.
@@ -356,6 +482,112 @@ func (in *CommonSplunkSpec) DeepCopy() *CommonSplunkSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentServer) DeepCopyInto(out *DeploymentServer) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentServer.
+func (in *DeploymentServer) DeepCopy() *DeploymentServer {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentServer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeploymentServer) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentServerList) DeepCopyInto(out *DeploymentServerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeploymentServer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentServerList.
+func (in *DeploymentServerList) DeepCopy() *DeploymentServerList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentServerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeploymentServerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentServerSpec) DeepCopyInto(out *DeploymentServerSpec) {
+	*out = *in
+	in.CommonSplunkSpec.DeepCopyInto(&out.CommonSplunkSpec)
+	in.AppFrameworkConfig.DeepCopyInto(&out.AppFrameworkConfig)
+	if in.ServerClasses != nil {
+		in, out := &in.ServerClasses, &out.ServerClasses
+		*out = make([]ServerClassSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentServerSpec.
+func (in *DeploymentServerSpec) DeepCopy() *DeploymentServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentServerStatus) DeepCopyInto(out *DeploymentServerStatus) {
+	*out = *in
+	in.AppContext.DeepCopyInto(&out.AppContext)
+	if in.AuditTrail != nil {
+		in, out := &in.AuditTrail, &out.AuditTrail
+		*out = make([]AuditRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentServerStatus.
+func (in *DeploymentServerStatus) DeepCopy() *DeploymentServerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentServerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EsDefaults) DeepCopyInto(out *EsDefaults) {
 	*out = *in
@@ -371,6 +603,166 @@ func (in *EsDefaults) DeepCopy() *EsDefaults {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayRouteSpec) DeepCopyInto(out *GatewayRouteSpec) {
+	*out = *in
+	out.GatewayRef = in.GatewayRef
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRouteSpec.
+func (in *GatewayRouteSpec) DeepCopy() *GatewayRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthAgentSpec) DeepCopyInto(out *HealthAgentSpec) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthAgentSpec.
+func (in *HealthAgentSpec) DeepCopy() *HealthAgentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthAgentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HecGatewayRef) DeepCopyInto(out *HecGatewayRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HecGatewayRef.
+func (in *HecGatewayRef) DeepCopy() *HecGatewayRef {
+	if in == nil {
+		return nil
+	}
+	out := new(HecGatewayRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HecIngressSpec) DeepCopyInto(out *HecIngressSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	out.GatewayRef = in.GatewayRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HecIngressSpec.
+func (in *HecIngressSpec) DeepCopy() *HecIngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HecIngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HecSpec) DeepCopyInto(out *HecSpec) {
+	*out = *in
+	if in.Tokens != nil {
+		in, out := &in.Tokens, &out.Tokens
+		*out = make([]HecTokenSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Ingress.DeepCopyInto(&out.Ingress)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HecSpec.
+func (in *HecSpec) DeepCopy() *HecSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HecSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HecStatus) DeepCopyInto(out *HecStatus) {
+	*out = *in
+	if in.Tokens != nil {
+		in, out := &in.Tokens, &out.Tokens
+		*out = make([]HecTokenStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HecStatus.
+func (in *HecStatus) DeepCopy() *HecStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HecStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HecTokenSpec) DeepCopyInto(out *HecTokenSpec) {
+	*out = *in
+	if in.AllowedIndexes != nil {
+		in, out := &in.AllowedIndexes, &out.AllowedIndexes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HecTokenSpec.
+func (in *HecTokenSpec) DeepCopy() *HecTokenSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HecTokenSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HecTokenStatus) DeepCopyInto(out *HecTokenStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HecTokenStatus.
+func (in *HecTokenStatus) DeepCopy() *HecTokenStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HecTokenStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IndexAndCacheManagerCommonSpec) DeepCopyInto(out *IndexAndCacheManagerCommonSpec) {
 	*out = *in
@@ -512,6 +904,7 @@ func (in *IndexerClusterMemberStatus) DeepCopy() *IndexerClusterMemberStatus {
 func (in *IndexerClusterSpec) DeepCopyInto(out *IndexerClusterSpec) {
 	*out = *in
 	in.CommonSplunkSpec.DeepCopyInto(&out.CommonSplunkSpec)
+	in.Hec.DeepCopyInto(&out.Hec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexerClusterSpec.
@@ -544,6 +937,15 @@ func (in *IndexerClusterStatus) DeepCopyInto(out *IndexerClusterStatus) {
 		*out = make([]IndexerClusterMemberStatus, len(*in))
 		copy(*out, *in)
 	}
+	in.Hec.DeepCopyInto(&out.Hec)
+	in.VolumeExpansion.DeepCopyInto(&out.VolumeExpansion)
+	if in.AuditTrail != nil {
+		in, out := &in.AuditTrail, &out.AuditTrail
+		*out = make([]AuditRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexerClusterStatus.
@@ -551,99 +953,268 @@ func (in *IndexerClusterStatus) DeepCopy() *IndexerClusterStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(IndexerClusterStatus)
+	out := new(IndexerClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVStoreBackupSpec) DeepCopyInto(out *KVStoreBackupSpec) {
+	*out = *in
+	if in.Period != nil {
+		in, out := &in.Period, &out.Period
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVStoreBackupSpec.
+func (in *KVStoreBackupSpec) DeepCopy() *KVStoreBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KVStoreBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicenseInfo) DeepCopyInto(out *LicenseInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseInfo.
+func (in *LicenseInfo) DeepCopy() *LicenseInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(LicenseInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicenseManager) DeepCopyInto(out *LicenseManager) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseManager.
+func (in *LicenseManager) DeepCopy() *LicenseManager {
+	if in == nil {
+		return nil
+	}
+	out := new(LicenseManager)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LicenseManager) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicenseManagerList) DeepCopyInto(out *LicenseManagerList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LicenseManager, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseManagerList.
+func (in *LicenseManagerList) DeepCopy() *LicenseManagerList {
+	if in == nil {
+		return nil
+	}
+	out := new(LicenseManagerList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LicenseManagerList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicenseManagerSpec) DeepCopyInto(out *LicenseManagerSpec) {
+	*out = *in
+	in.CommonSplunkSpec.DeepCopyInto(&out.CommonSplunkSpec)
+	in.AppFrameworkConfig.DeepCopyInto(&out.AppFrameworkConfig)
+	if in.Licenses != nil {
+		in, out := &in.Licenses, &out.Licenses
+		*out = make([]LicenseSecretSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]LicensePoolSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseManagerSpec.
+func (in *LicenseManagerSpec) DeepCopy() *LicenseManagerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LicenseManagerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicenseManagerStatus) DeepCopyInto(out *LicenseManagerStatus) {
+	*out = *in
+	in.AppContext.DeepCopyInto(&out.AppContext)
+	if in.Licenses != nil {
+		in, out := &in.Licenses, &out.Licenses
+		*out = make([]LicenseInfo, len(*in))
+		copy(*out, *in)
+	}
+	if in.Stacks != nil {
+		in, out := &in.Stacks, &out.Stacks
+		*out = make([]LicenseStackInfo, len(*in))
+		copy(*out, *in)
+	}
+	if in.Pools != nil {
+		in, out := &in.Pools, &out.Pools
+		*out = make([]LicensePoolInfo, len(*in))
+		copy(*out, *in)
+	}
+	if in.AuditTrail != nil {
+		in, out := &in.AuditTrail, &out.AuditTrail
+		*out = make([]AuditRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseManagerStatus.
+func (in *LicenseManagerStatus) DeepCopy() *LicenseManagerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LicenseManagerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicensePoolInfo) DeepCopyInto(out *LicensePoolInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicensePoolInfo.
+func (in *LicensePoolInfo) DeepCopy() *LicensePoolInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(LicensePoolInfo)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LicenseManager) DeepCopyInto(out *LicenseManager) {
+func (in *LicensePoolPeerRef) DeepCopyInto(out *LicensePoolPeerRef) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseManager.
-func (in *LicenseManager) DeepCopy() *LicenseManager {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicensePoolPeerRef.
+func (in *LicensePoolPeerRef) DeepCopy() *LicensePoolPeerRef {
 	if in == nil {
 		return nil
 	}
-	out := new(LicenseManager)
+	out := new(LicensePoolPeerRef)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LicenseManager) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LicenseManagerList) DeepCopyInto(out *LicenseManagerList) {
+func (in *LicensePoolSpec) DeepCopyInto(out *LicensePoolSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]LicenseManager, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]LicensePoolPeerRef, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseManagerList.
-func (in *LicenseManagerList) DeepCopy() *LicenseManagerList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicensePoolSpec.
+func (in *LicensePoolSpec) DeepCopy() *LicensePoolSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LicenseManagerList)
+	out := new(LicensePoolSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LicenseManagerList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LicenseSecretSpec) DeepCopyInto(out *LicenseSecretSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseSecretSpec.
+func (in *LicenseSecretSpec) DeepCopy() *LicenseSecretSpec {
+	if in == nil {
+		return nil
 	}
-	return nil
+	out := new(LicenseSecretSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LicenseManagerSpec) DeepCopyInto(out *LicenseManagerSpec) {
+func (in *LicenseStackInfo) DeepCopyInto(out *LicenseStackInfo) {
 	*out = *in
-	in.CommonSplunkSpec.DeepCopyInto(&out.CommonSplunkSpec)
-	in.AppFrameworkConfig.DeepCopyInto(&out.AppFrameworkConfig)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseManagerSpec.
-func (in *LicenseManagerSpec) DeepCopy() *LicenseManagerSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseStackInfo.
+func (in *LicenseStackInfo) DeepCopy() *LicenseStackInfo {
 	if in == nil {
 		return nil
 	}
-	out := new(LicenseManagerSpec)
+	out := new(LicenseStackInfo)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LicenseManagerStatus) DeepCopyInto(out *LicenseManagerStatus) {
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
 	*out = *in
-	in.AppContext.DeepCopyInto(&out.AppContext)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LicenseManagerStatus.
-func (in *LicenseManagerStatus) DeepCopy() *LicenseManagerStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
 	if in == nil {
 		return nil
 	}
-	out := new(LicenseManagerStatus)
+	out := new(MaintenanceWindow)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -675,6 +1246,21 @@ func (in *MonitoringConsole) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringConsoleExternalPeer) DeepCopyInto(out *MonitoringConsoleExternalPeer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConsoleExternalPeer.
+func (in *MonitoringConsoleExternalPeer) DeepCopy() *MonitoringConsoleExternalPeer {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringConsoleExternalPeer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MonitoringConsoleList) DeepCopyInto(out *MonitoringConsoleList) {
 	*out = *in
@@ -707,11 +1293,41 @@ func (in *MonitoringConsoleList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringConsolePeerStatus) DeepCopyInto(out *MonitoringConsolePeerStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConsolePeerStatus.
+func (in *MonitoringConsolePeerStatus) DeepCopy() *MonitoringConsolePeerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringConsolePeerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MonitoringConsoleSpec) DeepCopyInto(out *MonitoringConsoleSpec) {
 	*out = *in
 	in.CommonSplunkSpec.DeepCopyInto(&out.CommonSplunkSpec)
 	in.AppFrameworkConfig.DeepCopyInto(&out.AppFrameworkConfig)
+	if in.PeerSelector != nil {
+		in, out := &in.PeerSelector, &out.PeerSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PeerNamespaceSelector != nil {
+		in, out := &in.PeerNamespaceSelector, &out.PeerNamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalPeers != nil {
+		in, out := &in.ExternalPeers, &out.ExternalPeers
+		*out = make([]MonitoringConsoleExternalPeer, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConsoleSpec.
@@ -736,6 +1352,18 @@ func (in *MonitoringConsoleStatus) DeepCopyInto(out *MonitoringConsoleStatus) {
 		}
 	}
 	in.AppContext.DeepCopyInto(&out.AppContext)
+	if in.Peers != nil {
+		in, out := &in.Peers, &out.Peers
+		*out = make([]MonitoringConsolePeerStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.AuditTrail != nil {
+		in, out := &in.AuditTrail, &out.AuditTrail
+		*out = make([]AuditRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringConsoleStatus.
@@ -748,6 +1376,21 @@ func (in *MonitoringConsoleStatus) DeepCopy() *MonitoringConsoleStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCRetentionPolicy) DeepCopyInto(out *PVCRetentionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCRetentionPolicy.
+func (in *PVCRetentionPolicy) DeepCopy() *PVCRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PhaseInfo) DeepCopyInto(out *PhaseInfo) {
 	*out = *in
@@ -821,6 +1464,29 @@ func (in *SearchHeadCluster) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SearchHeadClusterKVStoreStatus) DeepCopyInto(out *SearchHeadClusterKVStoreStatus) {
+	*out = *in
+	if in.LastBackupTime != nil {
+		in, out := &in.LastBackupTime, &out.LastBackupTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastRestoreTime != nil {
+		in, out := &in.LastRestoreTime, &out.LastRestoreTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SearchHeadClusterKVStoreStatus.
+func (in *SearchHeadClusterKVStoreStatus) DeepCopy() *SearchHeadClusterKVStoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SearchHeadClusterKVStoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SearchHeadClusterList) DeepCopyInto(out *SearchHeadClusterList) {
 	*out = *in
@@ -856,6 +1522,14 @@ func (in *SearchHeadClusterList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SearchHeadClusterMemberStatus) DeepCopyInto(out *SearchHeadClusterMemberStatus) {
 	*out = *in
+	if in.KVStoreStaleTime != nil {
+		in, out := &in.KVStoreStaleTime, &out.KVStoreStaleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.KVStoreResyncTime != nil {
+		in, out := &in.KVStoreResyncTime, &out.KVStoreResyncTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SearchHeadClusterMemberStatus.
@@ -873,6 +1547,7 @@ func (in *SearchHeadClusterSpec) DeepCopyInto(out *SearchHeadClusterSpec) {
 	*out = *in
 	in.CommonSplunkSpec.DeepCopyInto(&out.CommonSplunkSpec)
 	in.AppFrameworkConfig.DeepCopyInto(&out.AppFrameworkConfig)
+	in.KVStoreBackup.DeepCopyInto(&out.KVStoreBackup)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SearchHeadClusterSpec.
@@ -908,9 +1583,20 @@ func (in *SearchHeadClusterStatus) DeepCopyInto(out *SearchHeadClusterStatus) {
 	if in.Members != nil {
 		in, out := &in.Members, &out.Members
 		*out = make([]SearchHeadClusterMemberStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
+	in.KVStore.DeepCopyInto(&out.KVStore)
+	in.VolumeExpansion.DeepCopyInto(&out.VolumeExpansion)
 	in.AppContext.DeepCopyInto(&out.AppContext)
+	if in.AuditTrail != nil {
+		in, out := &in.AuditTrail, &out.AuditTrail
+		*out = make([]AuditRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SearchHeadClusterStatus.
@@ -923,6 +1609,56 @@ func (in *SearchHeadClusterStatus) DeepCopy() *SearchHeadClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerClassAppSpec) DeepCopyInto(out *ServerClassAppSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerClassAppSpec.
+func (in *ServerClassAppSpec) DeepCopy() *ServerClassAppSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerClassAppSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerClassSpec) DeepCopyInto(out *ServerClassSpec) {
+	*out = *in
+	if in.Whitelist != nil {
+		in, out := &in.Whitelist, &out.Whitelist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Blacklist != nil {
+		in, out := &in.Blacklist, &out.Blacklist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MachineTypesFilter != nil {
+		in, out := &in.MachineTypesFilter, &out.MachineTypesFilter
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Apps != nil {
+		in, out := &in.Apps, &out.Apps
+		*out = make([]ServerClassAppSpec, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerClassSpec.
+func (in *ServerClassSpec) DeepCopy() *ServerClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SmartStoreSpec) DeepCopyInto(out *SmartStoreSpec) {
 	*out = *in
@@ -982,6 +1718,124 @@ func (in *Spec) DeepCopy() *Spec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SplunkRoutedServiceSpec) DeepCopyInto(out *SplunkRoutedServiceSpec) {
+	*out = *in
+	in.SplunkServiceSpec.DeepCopyInto(&out.SplunkServiceSpec)
+	if in.Route != nil {
+		in, out := &in.Route, &out.Route
+		*out = new(GatewayRouteSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplunkRoutedServiceSpec.
+func (in *SplunkRoutedServiceSpec) DeepCopy() *SplunkRoutedServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SplunkRoutedServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SplunkServiceSpec) DeepCopyInto(out *SplunkServiceSpec) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplunkServiceSpec.
+func (in *SplunkServiceSpec) DeepCopy() *SplunkServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SplunkServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SplunkServicesSpec) DeepCopyInto(out *SplunkServicesSpec) {
+	*out = *in
+	if in.Web != nil {
+		in, out := &in.Web, &out.Web
+		*out = new(SplunkRoutedServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mgmt != nil {
+		in, out := &in.Mgmt, &out.Mgmt
+		*out = new(SplunkServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.S2S != nil {
+		in, out := &in.S2S, &out.S2S
+		*out = new(SplunkServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hec != nil {
+		in, out := &in.Hec, &out.Hec
+		*out = new(SplunkRoutedServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplunkServicesSpec.
+func (in *SplunkServicesSpec) DeepCopy() *SplunkServicesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SplunkServicesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SplunkTLSSpec) DeepCopyInto(out *SplunkTLSSpec) {
+	*out = *in
+	if in.Mgmt != nil {
+		in, out := &in.Mgmt, &out.Mgmt
+		*out = new(CertificateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Web != nil {
+		in, out := &in.Web, &out.Web
+		*out = new(CertificateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.S2S != nil {
+		in, out := &in.S2S, &out.S2S
+		*out = new(CertificateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hec != nil {
+		in, out := &in.Hec, &out.Hec
+		*out = new(CertificateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplunkTLSSpec.
+func (in *SplunkTLSSpec) DeepCopy() *SplunkTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SplunkTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Standalone) DeepCopyInto(out *Standalone) {
 	*out = *in
@@ -1047,6 +1901,7 @@ func (in *StandaloneSpec) DeepCopyInto(out *StandaloneSpec) {
 	in.CommonSplunkSpec.DeepCopyInto(&out.CommonSplunkSpec)
 	in.SmartStore.DeepCopyInto(&out.SmartStore)
 	in.AppFrameworkConfig.DeepCopyInto(&out.AppFrameworkConfig)
+	in.Hec.DeepCopyInto(&out.Hec)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandaloneSpec.
@@ -1071,6 +1926,15 @@ func (in *StandaloneStatus) DeepCopyInto(out *StandaloneStatus) {
 		}
 	}
 	in.AppContext.DeepCopyInto(&out.AppContext)
+	in.Hec.DeepCopyInto(&out.Hec)
+	in.VolumeExpansion.DeepCopyInto(&out.VolumeExpansion)
+	if in.AuditTrail != nil {
+		in, out := &in.AuditTrail, &out.AuditTrail
+		*out = make([]AuditRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandaloneStatus.
@@ -1114,6 +1978,58 @@ func (in *VolumeAndTypeSpec) DeepCopy() *VolumeAndTypeSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeClaimTemplateSpec) DeepCopyInto(out *VolumeClaimTemplateSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeClaimTemplateSpec.
+func (in *VolumeClaimTemplateSpec) DeepCopy() *VolumeClaimTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeClaimTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeExpansionStatus) DeepCopyInto(out *VolumeExpansionStatus) {
+	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeExpansionStatus.
+func (in *VolumeExpansionStatus) DeepCopy() *VolumeExpansionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeExpansionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeMountSpec) DeepCopyInto(out *VolumeMountSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeMountSpec.
+func (in *VolumeMountSpec) DeepCopy() *VolumeMountSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeMountSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeSpec) DeepCopyInto(out *VolumeSpec) {
 	*out = *in
@@ -0,0 +1,87 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// The health agent runs as a sidecar of the Splunk pods and serves their liveness, readiness and startup probes
+package main
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/splunk/splunk-operator/pkg/splunk/health"
+)
+
+var setupLog = ctrl.Log.WithName("health-agent")
+
+func main() {
+	var bindAddress string
+	var checks string
+	config := health.Config{}
+
+	flag.StringVar(&bindAddress, "bind-address", fmt.Sprintf(":%d", health.DefaultPort), "The address the probes bind to.")
+	flag.StringVar(&config.SplunkdURL, "splunkd-url", "https://localhost:8089", "The URL of the splunkd management port.")
+	flag.StringVar(&config.Username, "username", "admin", "The splunkd admin user.")
+	flag.StringVar(&config.PasswordFile, "password-file", "/mnt/splunk-secrets/password", "The file with the password of the splunkd admin user.")
+	flag.StringVar(&checks, "checks", "", fmt.Sprintf("Comma separated role specific checks of the readiness probe: %s, %s or %s.",
+		health.CheckKVStore, health.CheckSHCMember, health.CheckIndexerPeer))
+	flag.StringVar(&config.ProcDir, "proc-dir", "/proc", "The proc file system used to find the splunkd process.")
+	flag.DurationVar(&config.Timeout, "timeout", 30*time.Second, "The timeout of the requests to splunkd.")
+
+	opts := zap.Options{
+		TimeEncoder: zapcore.RFC3339NanoTimeEncoder,
+	}
+	opts.BindFlags(flag.CommandLine)
+	flag.Parse()
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	for _, check := range strings.Split(checks, ",") {
+		if check = strings.TrimSpace(check); check != "" {
+			config.Checks = append(config.Checks, check)
+		}
+	}
+	config.Disabled = os.Getenv("NO_HEALTHCHECK") != ""
+
+	hostname, _ := os.Hostname()
+	certificate, err := health.GenerateCertificate("localhost", hostname)
+	if err != nil {
+		setupLog.Error(err, "unable to generate the certificate")
+		os.Exit(1)
+	}
+
+	server := &http.Server{
+		Addr:              bindAddress,
+		Handler:           health.NewAgent(config).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{certificate},
+			MinVersion:   tls.VersionTLS12,
+		},
+	}
+	setupLog.Info("starting health agent", "address", bindAddress, "checks", config.Checks)
+	if err := server.ListenAndServeTLS("", ""); err != nil {
+		setupLog.Error(err, "problem running health agent")
+		os.Exit(1)
+	}
+}
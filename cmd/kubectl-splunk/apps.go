@@ -0,0 +1,197 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	"github.com/splunk/splunk-operator/pkg/splunk/enterprise"
+)
+
+// manualUpdateStatusRegex matches the status of the manual app update of a kind in the manual app update ConfigMap
+var manualUpdateStatusRegex = regexp.MustCompile(`status: \w+`)
+
+// runAppRecheck turns the manual app update of a kind on
+func runAppRecheck(ctx context.Context, args []string) error {
+	var cluster clusterOptions
+	fs := flag.NewFlagSet("app-recheck", flag.ExitOnError)
+	cluster.bindFlags(fs)
+	positional, err := parseCommand(fs, "app-recheck <kind>", 1, args)
+	if err != nil {
+		return err
+	}
+
+	c, _, namespace, err := cluster.clients()
+	if err != nil {
+		return err
+	}
+	return requestAppRecheck(ctx, os.Stdout, c, namespace, positional[0])
+}
+
+// requestAppRecheck sets the status of a kind to on in the manual app update ConfigMap of a namespace, so that the
+// custom resources of the kind whose app repo polling is disabled check their remote storage once
+func requestAppRecheck(ctx context.Context, w io.Writer, c client.Client, namespace, kindName string) error {
+	kind, err := lookupKind(kindName)
+	if err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{}
+	name := enterprise.GetSplunkManualAppUpdateConfigMapName(namespace)
+	if err = c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, configMap); err != nil {
+		return fmt.Errorf("unable to get the manual app update ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	data, ok := configMap.Data[kind.gvk.Kind]
+	if !ok {
+		return fmt.Errorf("no %s of namespace %s uses the App Framework", kind.gvk.Kind, namespace)
+	}
+
+	patch := client.MergeFrom(configMap.DeepCopy())
+	configMap.Data[kind.gvk.Kind] = manualUpdateStatusRegex.ReplaceAllString(data, "status: on")
+	if err = c.Patch(ctx, configMap, patch); err != nil {
+		return fmt.Errorf("unable to update the manual app update ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	fmt.Fprintf(w, "app recheck requested for the %s resources with appsRepoPollIntervalSeconds 0\n", kind.gvk.Kind)
+	return nil
+}
+
+// runAppStatus shows the deployment status of the apps of a custom resource
+func runAppStatus(ctx context.Context, args []string) error {
+	var cluster clusterOptions
+	fs := flag.NewFlagSet("app-status", flag.ExitOnError)
+	cluster.bindFlags(fs)
+	positional, err := parseCommand(fs, "app-status <kind> <name>", 2, args)
+	if err != nil {
+		return err
+	}
+
+	c, _, namespace, err := cluster.clients()
+	if err != nil {
+		return err
+	}
+	return showAppStatus(ctx, os.Stdout, c, namespace, positional[0], positional[1])
+}
+
+// showAppStatus writes the deployment status of the apps of a custom resource
+func showAppStatus(ctx context.Context, w io.Writer, c client.Client, namespace, kindName, name string) error {
+	kind, err := lookupKind(kindName)
+	if err != nil {
+		return err
+	}
+	cr, err := getResource(ctx, c, kind, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	var appContext enterpriseApi.AppDeploymentContext
+	raw, ok, _ := unstructured.NestedMap(cr.Object, "status", "appContext")
+	if !ok {
+		return fmt.Errorf("%s %s does not use the App Framework", kind.gvk.Kind, name)
+	}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &appContext); err != nil {
+		return fmt.Errorf("invalid App Framework status of %s %s: %w", kind.gvk.Kind, name, err)
+	}
+
+	if appContext.LastAppInfoCheckTime > 0 {
+		fmt.Fprintf(w, "Last check of the remote storage: %s\n", time.Unix(appContext.LastAppInfoCheckTime, 0).UTC().Format(time.RFC3339))
+	}
+	if appContext.AppsRepoStatusPollInterval > 0 {
+		fmt.Fprintf(w, "Poll interval: %ds\n", appContext.AppsRepoStatusPollInterval)
+	} else {
+		fmt.Fprintf(w, "Poll interval: manual, run \"kubectl splunk app-recheck %s\" to check the remote storage\n", kind.gvk.Kind)
+	}
+	fmt.Fprintf(w, "Deployment in progress: %t\n\n", appContext.IsDeploymentInProgress)
+
+	sources := make([]string, 0, len(appContext.AppsSrcDeployStatus))
+	for source := range appContext.AppsSrcDeployStatus {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOURCE\tAPP\tREPO\tDEPLOY\tPHASE\tFAILURES")
+	for _, source := range sources {
+		for _, app := range appContext.AppsSrcDeployStatus[source].AppDeploymentInfoList {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\n", source, app.AppName, repoStateName(app.RepoState),
+				deployStatusName(app.DeployStatus), phaseName(app.PhaseInfo), app.PhaseInfo.FailCount)
+		}
+	}
+	return tw.Flush()
+}
+
+// repoStateName returns the name of the state of an app on the remote storage
+func repoStateName(state enterpriseApi.AppRepoState) string {
+	switch state {
+	case enterpriseApi.RepoStateActive:
+		return "Active"
+	case enterpriseApi.RepoStateDeleted:
+		return "Deleted"
+	case enterpriseApi.RepoStatePassive:
+		return "Passive"
+	default:
+		return "Unknown"
+	}
+}
+
+// deployStatusName returns the name of the deployment status of an app
+func deployStatusName(status enterpriseApi.AppDeploymentStatus) string {
+	switch status {
+	case enterpriseApi.DeployStatusPending:
+		return "Pending"
+	case enterpriseApi.DeployStatusInProgress:
+		return "InProgress"
+	case enterpriseApi.DeployStatusComplete:
+		return "Complete"
+	case enterpriseApi.DeployStatusError:
+		return "Error"
+	default:
+		return "Unknown"
+	}
+}
+
+// phaseName returns the name of the phase of the deployment of an app and of its status, whose last two digits
+// are 01 when pending, 02 in progress, 03 complete and 98 or 99 failed
+func phaseName(info enterpriseApi.PhaseInfo) string {
+	if info.Phase == "" {
+		return "-"
+	}
+	switch info.Status % 100 {
+	case 1:
+		return string(info.Phase) + " Pending"
+	case 2:
+		return string(info.Phase) + " InProgress"
+	case 3:
+		return string(info.Phase) + " Complete"
+	default:
+		return string(info.Phase) + " Error"
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	"github.com/splunk/splunk-operator/pkg/splunk/enterprise"
+)
+
+// runMaintenance turns the maintenance mode of an indexer cluster on or off
+func runMaintenance(ctx context.Context, args []string) error {
+	var cluster clusterOptions
+	fs := flag.NewFlagSet("maintenance", flag.ExitOnError)
+	cluster.bindFlags(fs)
+	positional, err := parseCommand(fs, "maintenance on|off <indexercluster>", 2, args)
+	if err != nil {
+		return err
+	}
+	if positional[0] != "on" && positional[0] != "off" {
+		return fmt.Errorf("invalid maintenance mode %q, must be on or off", positional[0])
+	}
+
+	c, _, namespace, err := cluster.clients()
+	if err != nil {
+		return err
+	}
+	return setMaintenanceMode(ctx, os.Stdout, c, namespace, positional[1], positional[0] == "on")
+}
+
+// setMaintenanceMode sets or removes the maintenance mode annotation of an indexer cluster
+func setMaintenanceMode(ctx context.Context, w io.Writer, c client.Client, namespace, name string, enable bool) error {
+	cr := &enterpriseApi.IndexerCluster{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, cr); err != nil {
+		return fmt.Errorf("unable to get IndexerCluster %s/%s: %w", namespace, name, err)
+	}
+
+	var value interface{}
+	state := "off"
+	if enable {
+		value = ""
+		state = "on"
+	}
+	if err := patchAnnotations(ctx, c, cr, map[string]interface{}{enterpriseApi.IndexerClusterMaintenanceModeAnnotation: value}); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "maintenance mode of IndexerCluster %s requested %s\n", name, state)
+	return nil
+}
+
+// runBundlePush pushes the cluster bundle of a cluster manager
+func runBundlePush(ctx context.Context, args []string) error {
+	var cluster clusterOptions
+	fs := flag.NewFlagSet("bundle-push", flag.ExitOnError)
+	cluster.bindFlags(fs)
+	positional, err := parseCommand(fs, "bundle-push <clustermanager>", 1, args)
+	if err != nil {
+		return err
+	}
+
+	c, _, namespace, err := cluster.clients()
+	if err != nil {
+		return err
+	}
+	return requestBundlePush(ctx, os.Stdout, c, namespace, positional[0], time.Now())
+}
+
+// requestBundlePush sets the bundle push annotation of a ClusterManager, or of a ClusterMaster, to the time of the request
+func requestBundlePush(ctx context.Context, w io.Writer, c client.Client, namespace, name string, now time.Time) error {
+	namespacedName := types.NamespacedName{Namespace: namespace, Name: name}
+	var cr client.Object = &enterpriseApi.ClusterManager{}
+	annotation := enterpriseApi.ClusterManagerBundlePushAnnotation
+	err := c.Get(ctx, namespacedName, cr)
+	if k8serrors.IsNotFound(err) {
+		cr = &enterpriseApiV3.ClusterMaster{}
+		annotation = enterpriseApiV3.ClusterMasterBundlePushAnnotation
+		err = c.Get(ctx, namespacedName, cr)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to get cluster manager %s/%s: %w", namespace, name, err)
+	}
+
+	request := now.UTC().Format(time.RFC3339)
+	if err = patchAnnotations(ctx, c, cr, map[string]interface{}{annotation: request}); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "bundle push of %s requested at %s, pending the maintenance windows of %s\n", name, request, name)
+	return nil
+}
+
+// runDecommission decommissions the last indexer peer of an indexer cluster
+func runDecommission(ctx context.Context, args []string) error {
+	var cluster clusterOptions
+	fs := flag.NewFlagSet("decommission", flag.ExitOnError)
+	cluster.bindFlags(fs)
+	positional, err := parseCommand(fs, "decommission <indexercluster> <pod>", 2, args)
+	if err != nil {
+		return err
+	}
+
+	c, _, namespace, err := cluster.clients()
+	if err != nil {
+		return err
+	}
+	return decommissionPeer(ctx, os.Stdout, c, namespace, positional[0], positional[1])
+}
+
+// decommissionPeer scales an indexer cluster down by one peer, which the operator decommissions before removing it.
+// Pods are removed from the highest ordinal, so only the last peer can be decommissioned
+func decommissionPeer(ctx context.Context, w io.Writer, c client.Client, namespace, name, podName string) error {
+	cr := &enterpriseApi.IndexerCluster{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, cr); err != nil {
+		return fmt.Errorf("unable to get IndexerCluster %s/%s: %w", namespace, name, err)
+	}
+	if cr.Spec.Replicas <= 1 {
+		return fmt.Errorf("unable to decommission %s, the last peer of IndexerCluster %s", podName, name)
+	}
+	last := enterprise.GetSplunkStatefulsetPodName(enterprise.SplunkIndexer, name, cr.Spec.Replicas-1)
+	if podName != last {
+		return fmt.Errorf("unable to decommission %s, peers are decommissioned from the highest ordinal, %s first", podName, last)
+	}
+
+	patch := client.MergeFrom(cr.DeepCopy())
+	cr.Spec.Replicas--
+	if err := c.Patch(ctx, cr, patch); err != nil {
+		return fmt.Errorf("unable to scale IndexerCluster %s/%s down: %w", namespace, name, err)
+	}
+	fmt.Fprintf(w, "IndexerCluster %s scaled down to %d replicas, %s is decommissioned within its maintenance windows\n", name, cr.Spec.Replicas, podName)
+	return nil
+}
@@ -0,0 +1,229 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"strings"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+)
+
+func TestParseCommand(t *testing.T) {
+	var cluster clusterOptions
+	fs := flag.NewFlagSet("pause", flag.ContinueOnError)
+	cluster.bindFlags(fs)
+	args, err := parseCommand(fs, "pause <kind> <name>", 2, []string{"standalone", "-n", "splunk", "s1"})
+	if err != nil || strings.Join(args, " ") != "standalone s1" || cluster.namespace != "splunk" {
+		t.Errorf("parseCommand() = %v, %v with namespace %q", args, err, cluster.namespace)
+	}
+
+	if _, err = parseCommand(fs, "pause <kind> <name>", 2, []string{"standalone"}); err == nil {
+		t.Errorf("parseCommand() returned no error for a missing argument")
+	}
+}
+
+func TestSetPaused(t *testing.T) {
+	ctx := context.TODO()
+	cr := &enterpriseApi.Standalone{ObjectMeta: metav1.ObjectMeta{Name: "s1", Namespace: "test"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr).Build()
+
+	var out bytes.Buffer
+	if err := setPaused(ctx, &out, c, "test", "stdaln", "s1", true); err != nil {
+		t.Fatalf("setPaused() returned error: %v", err)
+	}
+	_ = c.Get(ctx, client.ObjectKeyFromObject(cr), cr)
+	if _, ok := cr.GetAnnotations()[enterpriseApi.StandalonePausedAnnotation]; !ok {
+		t.Errorf("setPaused() did not pause Standalone s1")
+	}
+
+	if err := setPaused(ctx, &out, c, "test", "Standalone", "s1", false); err != nil {
+		t.Fatalf("setPaused() returned error: %v", err)
+	}
+	_ = c.Get(ctx, client.ObjectKeyFromObject(cr), cr)
+	if _, ok := cr.GetAnnotations()[enterpriseApi.StandalonePausedAnnotation]; ok {
+		t.Errorf("setPaused() did not resume Standalone s1")
+	}
+
+	if err := setPaused(ctx, &out, c, "test", "forwarder", "s1", true); err == nil {
+		t.Errorf("setPaused() returned no error for an unknown kind")
+	}
+}
+
+func TestClusterCommands(t *testing.T) {
+	ctx := context.TODO()
+	idxc := &enterpriseApi.IndexerCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "idxc", Namespace: "test"},
+		Spec:       enterpriseApi.IndexerClusterSpec{Replicas: 3},
+	}
+	cm := &enterpriseApiV3.ClusterMaster{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "test"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(idxc, cm).Build()
+	var out bytes.Buffer
+
+	if err := setMaintenanceMode(ctx, &out, c, "test", "idxc", true); err != nil {
+		t.Fatalf("setMaintenanceMode() returned error: %v", err)
+	}
+	_ = c.Get(ctx, client.ObjectKeyFromObject(idxc), idxc)
+	if _, ok := idxc.GetAnnotations()[enterpriseApi.IndexerClusterMaintenanceModeAnnotation]; !ok {
+		t.Errorf("setMaintenanceMode() did not request the maintenance mode")
+	}
+
+	// the bundle push of a ClusterMaster is requested when there is no ClusterManager of the name
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	if err := requestBundlePush(ctx, &out, c, "test", "cm", now); err != nil {
+		t.Fatalf("requestBundlePush() returned error: %v", err)
+	}
+	_ = c.Get(ctx, client.ObjectKeyFromObject(cm), cm)
+	if got := cm.GetAnnotations()[enterpriseApiV3.ClusterMasterBundlePushAnnotation]; got != "2022-10-01T12:00:00Z" {
+		t.Errorf("requestBundlePush() annotation = %q; want 2022-10-01T12:00:00Z", got)
+	}
+
+	// peers are decommissioned from the highest ordinal
+	if err := decommissionPeer(ctx, &out, c, "test", "idxc", "splunk-idxc-indexer-1"); err == nil {
+		t.Errorf("decommissionPeer() returned no error for a peer which is not the last one")
+	}
+	if err := decommissionPeer(ctx, &out, c, "test", "idxc", "splunk-idxc-indexer-2"); err != nil {
+		t.Fatalf("decommissionPeer() returned error: %v", err)
+	}
+	_ = c.Get(ctx, client.ObjectKeyFromObject(idxc), idxc)
+	if idxc.Spec.Replicas != 2 {
+		t.Errorf("decommissionPeer() replicas = %d; want 2", idxc.Spec.Replicas)
+	}
+}
+
+func TestRequestRestart(t *testing.T) {
+	ctx := context.TODO()
+	isController := true
+	cr := &enterpriseApi.Standalone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "s1",
+			Namespace:   "test",
+			UID:         "cr-uid",
+			Annotations: map[string]string{enterpriseApi.RestartPodsAnnotation: `{"splunk-s1-standalone-0":"2022-09-01T00:00:00Z"}`},
+		},
+	}
+	statefulSet := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+		Name:            "splunk-s1-standalone",
+		Namespace:       "test",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "Standalone", Name: "s1", UID: "cr-uid", Controller: &isController}},
+	}}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:            "splunk-s1-standalone-1",
+		Namespace:       "test",
+		OwnerReferences: []metav1.OwnerReference{{Kind: "StatefulSet", Name: "splunk-s1-standalone", Controller: &isController}},
+	}}
+	otherPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "splunk-s2-standalone-0", Namespace: "test"}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cr, statefulSet, pod, otherPod).Build()
+	var out bytes.Buffer
+
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
+	if err := requestRestart(ctx, &out, c, "test", "standalone", "s1", "splunk-s2-standalone-0", now); err == nil {
+		t.Errorf("requestRestart() returned no error for a pod of another custom resource")
+	}
+	if err := requestRestart(ctx, &out, c, "test", "standalone", "s1", "splunk-s1-standalone-1", now); err != nil {
+		t.Fatalf("requestRestart() returned error: %v", err)
+	}
+
+	_ = c.Get(ctx, client.ObjectKeyFromObject(cr), cr)
+	var requests map[string]string
+	_ = json.Unmarshal([]byte(cr.GetAnnotations()[enterpriseApi.RestartPodsAnnotation]), &requests)
+	if requests["splunk-s1-standalone-1"] != "2022-10-01T12:00:00Z" || requests["splunk-s1-standalone-0"] != "2022-09-01T00:00:00Z" {
+		t.Errorf("requestRestart() restart requests = %v", requests)
+	}
+}
+
+func TestAppCommands(t *testing.T) {
+	ctx := context.TODO()
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "splunk-test-manual-app-update", Namespace: "test"},
+		Data:       map[string]string{"Standalone": "status: off\nrefCount: 2"},
+	}
+	cr := &enterpriseApi.Standalone{ObjectMeta: metav1.ObjectMeta{Name: "s1", Namespace: "test"}}
+	cr.Status.AppContext.AppsSrcDeployStatus = map[string]enterpriseApi.AppSrcDeployInfo{
+		"appSrc1": {AppDeploymentInfoList: []enterpriseApi.AppDeploymentInfo{{
+			AppName:      "app1.tgz",
+			RepoState:    enterpriseApi.RepoStateActive,
+			DeployStatus: enterpriseApi.DeployStatusInProgress,
+			PhaseInfo:    enterpriseApi.PhaseInfo{Phase: enterpriseApi.PhaseInstall, Status: enterpriseApi.AppPkgInstallError, FailCount: 2},
+		}}},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(configMap, cr).Build()
+	var out bytes.Buffer
+
+	if err := requestAppRecheck(ctx, &out, c, "test", "standalone"); err != nil {
+		t.Fatalf("requestAppRecheck() returned error: %v", err)
+	}
+	_ = c.Get(ctx, types.NamespacedName{Namespace: "test", Name: configMap.Name}, configMap)
+	if got := configMap.Data["Standalone"]; got != "status: on\nrefCount: 2" {
+		t.Errorf("requestAppRecheck() data = %q", got)
+	}
+	if err := requestAppRecheck(ctx, &out, c, "test", "shc"); err == nil {
+		t.Errorf("requestAppRecheck() returned no error for a kind without App Framework")
+	}
+
+	out.Reset()
+	if err := showAppStatus(ctx, &out, c, "test", "standalone", "s1"); err != nil {
+		t.Fatalf("showAppStatus() returned error: %v", err)
+	}
+	if !strings.Contains(out.String(), "appSrc1  app1.tgz  Active  InProgress  install Error  2") {
+		t.Errorf("showAppStatus() output:\n%s", out.String())
+	}
+}
+
+func TestShowTopology(t *testing.T) {
+	ctx := context.TODO()
+	cm := &enterpriseApi.ClusterManager{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "test"}}
+	cm.Status.Phase = enterpriseApi.PhaseReady
+	idxc := &enterpriseApi.IndexerCluster{ObjectMeta: metav1.ObjectMeta{
+		Name:        "idxc",
+		Namespace:   "test",
+		Annotations: map[string]string{enterpriseApi.IndexerClusterPausedAnnotation: ""},
+	}}
+	idxc.Spec.ClusterManagerRef.Name = "cm"
+	idxc.Status.Phase = enterpriseApi.PhaseReady
+	idxc.Status.Replicas = 1
+	idxc.Status.ReadyReplicas = 1
+	idxc.Status.MaintenanceMode = true
+	idxc.Status.Peers = []enterpriseApi.IndexerClusterMemberStatus{{Name: "splunk-idxc-indexer-0", Status: "Up", BucketCount: 12, Searchable: true}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(cm, idxc).Build()
+
+	var out bytes.Buffer
+	if err := showTopology(ctx, &out, c, "test"); err != nil {
+		t.Fatalf("showTopology() returned error: %v", err)
+	}
+	want := `IndexerCluster/idxc  Ready, 1/1 ready, maintenance mode, paused
+  clusterManagerRef: cm
+  peer splunk-idxc-indexer-0  Up  buckets=12  searchable=true
+ClusterManager/cm  Ready
+`
+	if out.String() != want {
+		t.Errorf("showTopology() output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// kubectl-splunk is a kubectl plugin operating the Splunk Enterprise deployments of the operator, run as
+// "kubectl splunk <command>" once on the PATH
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+)
+
+var scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(enterpriseApi.AddToScheme(scheme))
+	utilruntime.Must(enterpriseApiV3.AddToScheme(scheme))
+}
+
+// command is a command of the plugin
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, args []string) error
+}
+
+var commands = []command{
+	{"pause", "Pause the reconciliation of a custom resource", runPause},
+	{"resume", "Resume the reconciliation of a paused custom resource", runResume},
+	{"maintenance", "Turn the maintenance mode of an indexer cluster on or off", runMaintenance},
+	{"bundle-push", "Push the cluster bundle of a cluster manager to the indexer peers", runBundlePush},
+	{"app-recheck", "Check the remote storage for app changes now, for the custom resources of a kind", runAppRecheck},
+	{"app-status", "Show the deployment status of the apps of a custom resource", runAppStatus},
+	{"restart", "Restart a pod of a custom resource, within its maintenance windows", runRestart},
+	{"decommission", "Decommission the last indexer peer of an indexer cluster", runDecommission},
+	{"topology", "Show the custom resources of a namespace, their references and members", runTopology},
+	{"support-bundle", "Collect the diagnostics of the Splunk Enterprise deployments of some namespaces in an archive", runSupportBundle},
+}
+
+// clusterOptions are the flags selecting the cluster and namespace of every command
+type clusterOptions struct {
+	kubeconfig string
+	namespace  string
+}
+
+// bindFlags adds the cluster flags to the flags of a command
+func (o *clusterOptions) bindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file, the kubectl default when empty.")
+	fs.StringVar(&o.namespace, "namespace", "", "Namespace of the custom resources, the one of the kubeconfig context when empty.")
+	fs.StringVar(&o.namespace, "n", "", "Shorthand for --namespace.")
+}
+
+// clients returns the clients of the cluster of the kubeconfig, and the namespace of the command
+func (o *clusterOptions) clients() (client.Client, kubernetes.Interface, string, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = o.kubeconfig
+	loader := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{})
+	config, err := loader.ClientConfig()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	namespace := o.namespace
+	if namespace == "" {
+		if namespace, _, err = loader.Namespace(); err != nil {
+			return nil, nil, "", err
+		}
+	}
+
+	// the pod execs of the operator packages load the kubeconfig of the flag of controller-runtime
+	if f := flag.CommandLine.Lookup("kubeconfig"); f != nil && o.kubeconfig != "" {
+		_ = f.Value.Set(o.kubeconfig)
+	}
+
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, nil, "", err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return c, clientset, namespace, nil
+}
+
+// parseCommand parses the flags of a command, which may come before or after its arguments, and returns
+// its arguments. The usage of the command is returned as an error when it has not nargs arguments
+func parseCommand(fs *flag.FlagSet, usage string, nargs int, args []string) ([]string, error) {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kubectl splunk %s [flags]\n\nFlags:\n", usage)
+		fs.PrintDefaults()
+	}
+
+	var positional []string
+	for {
+		_ = fs.Parse(args)
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(positional) != nargs {
+		return nil, fmt.Errorf("usage: kubectl splunk %s", usage)
+	}
+	return positional, nil
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: kubectl splunk <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", cmd.name, cmd.usage)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun \"kubectl splunk <command> -h\" for the flags of a command.\n")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			if err := cmd.run(ctrl.SetupSignalHandler(), os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	usage()
+	os.Exit(2)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// runPause pauses the reconciliation of a custom resource
+func runPause(ctx context.Context, args []string) error {
+	return runSetPaused(ctx, "pause", args, true)
+}
+
+// runResume resumes the reconciliation of a custom resource
+func runResume(ctx context.Context, args []string) error {
+	return runSetPaused(ctx, "resume", args, false)
+}
+
+func runSetPaused(ctx context.Context, name string, args []string, paused bool) error {
+	var cluster clusterOptions
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	cluster.bindFlags(fs)
+	positional, err := parseCommand(fs, name+" <kind> <name>", 2, args)
+	if err != nil {
+		return err
+	}
+
+	c, _, namespace, err := cluster.clients()
+	if err != nil {
+		return err
+	}
+	return setPaused(ctx, os.Stdout, c, namespace, positional[0], positional[1], paused)
+}
+
+// setPaused sets or removes the paused annotation of a custom resource
+func setPaused(ctx context.Context, w io.Writer, c client.Client, namespace, kindName, name string, paused bool) error {
+	kind, err := lookupKind(kindName)
+	if err != nil {
+		return err
+	}
+	cr, err := getResource(ctx, c, kind, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	var value interface{}
+	state := "resumed"
+	if paused {
+		value = ""
+		state = "paused"
+	}
+	if err = patchAnnotations(ctx, c, cr, map[string]interface{}{kind.pausedAnnotation: value}); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "%s %s %s\n", kind.gvk.Kind, name, state)
+	return nil
+}
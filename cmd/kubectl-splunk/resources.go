@@ -0,0 +1,93 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+)
+
+// resourceKind is a kind of custom resource of the operator
+type resourceKind struct {
+	gvk schema.GroupVersionKind
+
+	// names of the kind accepted on the command line besides the kind, the plural and short names of the CRD
+	aliases []string
+
+	// annotation pausing the reconciliation of the custom resources of the kind
+	pausedAnnotation string
+}
+
+// resourceKinds are the kinds of custom resources of the operator
+var resourceKinds = []resourceKind{
+	{enterpriseApi.GroupVersion.WithKind("Standalone"), []string{"standalones", "stdaln"}, enterpriseApi.StandalonePausedAnnotation},
+	{enterpriseApi.GroupVersion.WithKind("IndexerCluster"), []string{"indexerclusters", "idc", "idxc"}, enterpriseApi.IndexerClusterPausedAnnotation},
+	{enterpriseApi.GroupVersion.WithKind("ClusterManager"), []string{"clustermanagers", "cmanager-idxc"}, enterpriseApi.ClusterManagerPausedAnnotation},
+	{enterpriseApi.GroupVersion.WithKind("SearchHeadCluster"), []string{"searchheadclusters", "shc"}, enterpriseApi.SearchHeadClusterPausedAnnotation},
+	{enterpriseApi.GroupVersion.WithKind("LicenseManager"), []string{"licensemanagers", "lmanager"}, enterpriseApi.LicenseManagerPausedAnnotation},
+	{enterpriseApi.GroupVersion.WithKind("MonitoringConsole"), []string{"monitoringconsoles", "mc"}, enterpriseApi.MonitoringConsolePausedAnnotation},
+	{enterpriseApi.GroupVersion.WithKind("DeploymentServer"), []string{"deploymentservers", "ds"}, enterpriseApi.DeploymentServerPausedAnnotation},
+	{enterpriseApiV3.GroupVersion.WithKind("ClusterMaster"), []string{"clustermasters", "cm-idxc"}, enterpriseApiV3.ClusterMasterPausedAnnotation},
+	{enterpriseApiV3.GroupVersion.WithKind("LicenseMaster"), []string{"licensemasters", "lm"}, enterpriseApiV3.LicenseMasterPausedAnnotation},
+}
+
+// lookupKind returns the kind of custom resource of a name given on the command line
+func lookupKind(name string) (resourceKind, error) {
+	for _, kind := range resourceKinds {
+		if strings.EqualFold(name, kind.gvk.Kind) {
+			return kind, nil
+		}
+		for _, alias := range kind.aliases {
+			if strings.EqualFold(name, alias) {
+				return kind, nil
+			}
+		}
+	}
+	return resourceKind{}, fmt.Errorf("unknown kind %q", name)
+}
+
+// getResource returns a custom resource
+func getResource(ctx context.Context, c client.Client, kind resourceKind, namespace, name string) (*unstructured.Unstructured, error) {
+	cr := &unstructured.Unstructured{}
+	cr.SetGroupVersionKind(kind.gvk)
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, cr); err != nil {
+		return nil, fmt.Errorf("unable to get %s %s/%s: %w", kind.gvk.Kind, namespace, name, err)
+	}
+	return cr, nil
+}
+
+// patchAnnotations sets annotations of a custom resource, the annotations with a nil value are removed
+func patchAnnotations(ctx context.Context, c client.Client, cr client.Object, annotations map[string]interface{}) error {
+	patch, err := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"annotations": annotations}})
+	if err != nil {
+		return err
+	}
+	if err = c.Patch(ctx, cr, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return fmt.Errorf("unable to annotate %s %s/%s: %w", cr.GetObjectKind().GroupVersionKind().Kind, cr.GetNamespace(), cr.GetName(), err)
+	}
+	return nil
+}
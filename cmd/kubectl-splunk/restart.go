@@ -0,0 +1,100 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+)
+
+// runRestart restarts a pod of a custom resource
+func runRestart(ctx context.Context, args []string) error {
+	var cluster clusterOptions
+	fs := flag.NewFlagSet("restart", flag.ExitOnError)
+	cluster.bindFlags(fs)
+	positional, err := parseCommand(fs, "restart <kind> <name> <pod>", 3, args)
+	if err != nil {
+		return err
+	}
+
+	c, _, namespace, err := cluster.clients()
+	if err != nil {
+		return err
+	}
+	return requestRestart(ctx, os.Stdout, c, namespace, positional[0], positional[1], positional[2], time.Now())
+}
+
+// requestRestart adds a pod of a custom resource to its restart pods annotation. The operator recycles the pod like
+// for an update, within the maintenance windows and the disruption budget of the custom resource
+func requestRestart(ctx context.Context, w io.Writer, c client.Client, namespace, kindName, name, podName string, now time.Time) error {
+	kind, err := lookupKind(kindName)
+	if err != nil {
+		return err
+	}
+	cr, err := getResource(ctx, c, kind, namespace, name)
+	if err != nil {
+		return err
+	}
+
+	// only the pods of the StatefulSets managed by the custom resource are recycled by its reconciles
+	pod := &corev1.Pod{}
+	if err = c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: podName}, pod); err != nil {
+		return fmt.Errorf("unable to get pod %s/%s: %w", namespace, podName, err)
+	}
+	statefulSet := &appsv1.StatefulSet{}
+	owner := metav1.GetControllerOf(pod)
+	if owner == nil || owner.Kind != "StatefulSet" {
+		return fmt.Errorf("pod %s does not belong to %s %s", podName, kind.gvk.Kind, name)
+	}
+	if err = c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: owner.Name}, statefulSet); err != nil {
+		return fmt.Errorf("unable to get StatefulSet %s/%s: %w", namespace, owner.Name, err)
+	}
+	if owner = metav1.GetControllerOf(statefulSet); owner == nil || owner.UID != cr.GetUID() {
+		return fmt.Errorf("pod %s does not belong to %s %s", podName, kind.gvk.Kind, name)
+	}
+
+	// the requests of other pods are kept, invalid ones are dropped
+	requests := map[string]string{}
+	if value, ok := cr.GetAnnotations()[enterpriseApi.RestartPodsAnnotation]; ok {
+		if err = json.Unmarshal([]byte(value), &requests); err != nil {
+			requests = map[string]string{}
+		}
+	}
+	requests[podName] = now.UTC().Format(time.RFC3339)
+	value, err := json.Marshal(requests)
+	if err != nil {
+		return err
+	}
+	if err = patchAnnotations(ctx, c, cr, map[string]interface{}{enterpriseApi.RestartPodsAnnotation: string(value)}); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "restart of %s requested, pending the maintenance windows of %s %s\n", podName, kind.gvk.Kind, name)
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/splunk/splunk-operator/pkg/splunk/support"
+)
+
+// runSupportBundle collects a support bundle
+func runSupportBundle(ctx context.Context, args []string) error {
+	var cluster clusterOptions
+	var namespaces, diagPods, output string
+	opts := support.Options{}
+
+	fs := flag.NewFlagSet("support-bundle", flag.ExitOnError)
+	cluster.bindFlags(fs)
+	fs.StringVar(&namespaces, "namespaces", "", "Comma separated namespaces collected in addition to --namespace.")
+	fs.StringVar(&opts.OperatorNamespace, "operator-namespace", "splunk-operator", "Namespace of the operator whose logs are collected, none when empty.")
+	fs.StringVar(&diagPods, "diag-pods", "", "Comma separated names of the pods splunk diag is run on.")
+	fs.Int64Var(&opts.LogTailLines, "tail", 10000, "Number of lines of the logs of each container, all the lines when 0.")
+	fs.StringVar(&output, "output", "", "Path of the archive, splunk-support-bundle-<time>.tar.gz when empty.")
+	_ = fs.Parse(args)
+
+	c, clientset, namespace, err := cluster.clients()
+	if err != nil {
+		return err
+	}
+	opts.Namespaces = append([]string{namespace}, splitList(namespaces)...)
+	opts.DiagPods = splitList(diagPods)
+
+	root := "splunk-support-bundle-" + time.Now().UTC().Format("20060102-150405")
+	if output == "" {
+		output = root + ".tar.gz"
+	}
+	file, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err = support.NewCollector(c, clientset, opts).Collect(ctx, file, root); err != nil {
+		return fmt.Errorf("unable to write %s: %w", output, err)
+	}
+	fmt.Printf("support bundle written to %s\n", output)
+	return file.Close()
+}
+
+// splitList returns the non empty items of a comma separated list
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
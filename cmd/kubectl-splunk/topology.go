@@ -0,0 +1,107 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// referenceFields are the fields of the specs referencing other custom resources
+var referenceFields = []string{"clusterManagerRef", "clusterMasterRef", "licenseManagerRef", "licenseMasterRef", "monitoringConsoleRef"}
+
+// runTopology shows the custom resources of a namespace
+func runTopology(ctx context.Context, args []string) error {
+	var cluster clusterOptions
+	fs := flag.NewFlagSet("topology", flag.ExitOnError)
+	cluster.bindFlags(fs)
+	if _, err := parseCommand(fs, "topology", 0, args); err != nil {
+		return err
+	}
+
+	c, _, namespace, err := cluster.clients()
+	if err != nil {
+		return err
+	}
+	return showTopology(ctx, os.Stdout, c, namespace)
+}
+
+// showTopology writes the custom resources of a namespace with their phase, their references to other custom
+// resources, and the peers and members of the clusters
+func showTopology(ctx context.Context, w io.Writer, c client.Client, namespace string) error {
+	found := false
+	for _, kind := range resourceKinds {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(kind.gvk.GroupVersion().WithKind(kind.gvk.Kind + "List"))
+		if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return fmt.Errorf("unable to list the %s resources of namespace %s: %w", kind.gvk.Kind, namespace, err)
+		}
+
+		for _, cr := range list.Items {
+			found = true
+			fmt.Fprintf(w, "%s/%s  %s\n", kind.gvk.Kind, cr.GetName(), resourceState(&cr, kind))
+			for _, field := range referenceFields {
+				if ref, _, _ := unstructured.NestedString(cr.Object, "spec", field, "name"); ref != "" {
+					fmt.Fprintf(w, "  %s: %s\n", field, ref)
+				}
+			}
+
+			peers, _, _ := unstructured.NestedSlice(cr.Object, "status", "peers")
+			for _, peer := range peers {
+				fields, _ := peer.(map[string]interface{})
+				fmt.Fprintf(w, "  peer %v  %v  buckets=%v  searchable=%v\n", fields["name"], fields["status"], fields["bucket_count"], fields["is_searchable"])
+			}
+			members, _, _ := unstructured.NestedSlice(cr.Object, "status", "members")
+			for _, member := range members {
+				fields, _ := member.(map[string]interface{})
+				fmt.Fprintf(w, "  member %v  %v  registered=%v\n", fields["name"], fields["status"], fields["is_registered"])
+			}
+		}
+	}
+	if !found {
+		fmt.Fprintf(w, "no Splunk Enterprise custom resources in namespace %s\n", namespace)
+	}
+	return nil
+}
+
+// resourceState returns the phase of a custom resource, with its number of replicas and whether it is paused or in
+// maintenance mode
+func resourceState(cr *unstructured.Unstructured, kind resourceKind) string {
+	phase, _, _ := unstructured.NestedString(cr.Object, "status", "phase")
+	if phase == "" {
+		phase = "Pending"
+	}
+	state := []string{phase}
+	if replicas, ok, _ := unstructured.NestedInt64(cr.Object, "status", "replicas"); ok {
+		ready, _, _ := unstructured.NestedInt64(cr.Object, "status", "readyReplicas")
+		state = append(state, fmt.Sprintf("%d/%d ready", ready, replicas))
+	}
+	if maintenance, _, _ := unstructured.NestedBool(cr.Object, "status", "maintenance_mode"); maintenance {
+		state = append(state, "maintenance mode")
+	}
+	if _, paused := cr.GetAnnotations()[kind.pausedAnnotation]; paused {
+		state = append(state, "paused")
+	}
+	return strings.Join(state, ", ")
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 	common "github.com/splunk/splunk-operator/controllers/common"
 	enterprise "github.com/splunk/splunk-operator/pkg/splunk/enterprise"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -58,7 +59,10 @@ type ClusterManagerReconciler struct {
 //+kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -73,6 +77,8 @@ func (r *ClusterManagerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	// your logic here
 	reconcileCounters.With(getPrometheusLabels(req, "ClusterManager")).Inc()
 	defer recordInstrumentionData(time.Now(), req, "controller", "ClusterManager")
+	ctx, span := tracing.StartReconcile(ctx, "ClusterManager", req.Namespace, req.Name)
+	defer span.End()
 
 	reqLogger := log.FromContext(ctx)
 	reqLogger = reqLogger.WithValues("clustermanager", req.NamespacedName)
@@ -86,6 +92,7 @@ func (r *ClusterManagerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			// reconcile request.  Owned objects are automatically
 			// garbage collected. For additional cleanup logic use
 			// finalizers.  Return and don't requeue
+			deleteHealthMetrics(req, "ClusterManager")
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -100,9 +107,16 @@ func (r *ClusterManagerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 	}
 
+	// If a plan is requested, report the changes of the reconcile instead of making them
+	if enterprise.IsPlanRequested(instance) {
+		return enterprise.ApplyPlan(ctx, r.Client, instance)
+	}
+
 	reqLogger.Info("start", "CR version", instance.GetResourceVersion())
 
 	result, err := ApplyClusterManager(ctx, r.Client, instance)
+	tracing.RecordError(span, err)
+	updateHealthMetrics(req, instance)
 	if result.Requeue && result.RequeueAfter != 0 {
 		reqLogger.Info("Requeued", "period(seconds)", int(result.RequeueAfter/time.Second))
 	}
@@ -139,6 +153,8 @@ func (r *ClusterManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				IsController: false,
 				OwnerType:    &enterpriseApi.ClusterManager{},
 			}).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			common.EnqueueRequestsForTLSSecret(mgr.GetClient(), &enterpriseApi.ClusterManagerList{})).
 		Watches(&source.Kind{Type: &corev1.Pod{}},
 			&handler.EnqueueRequestForOwner{
 				IsController: false,
@@ -150,7 +166,7 @@ func (r *ClusterManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				OwnerType:    &enterpriseApi.ClusterManager{},
 			}).
 		WithOptions(controller.Options{
-			MaxConcurrentReconciles: enterpriseApi.TotalWorker,
+			MaxConcurrentReconciles: common.GetMaxConcurrentReconciles("ClusterManager"),
 		}).
 		Complete(r)
 }
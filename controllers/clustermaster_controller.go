@@ -25,6 +25,7 @@ import (
 	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
 	common "github.com/splunk/splunk-operator/controllers/common"
 	enterprise "github.com/splunk/splunk-operator/pkg/splunk/enterprise"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -59,7 +60,10 @@ type ClusterMasterReconciler struct {
 //+kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -74,6 +78,8 @@ func (r *ClusterMasterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	// your logic here
 	reconcileCounters.With(getPrometheusLabels(req, "ClusterMaster")).Inc()
 	defer recordInstrumentionData(time.Now(), req, "controller", "ClusterMaster")
+	ctx, span := tracing.StartReconcile(ctx, "ClusterMaster", req.Namespace, req.Name)
+	defer span.End()
 
 	reqLogger := log.FromContext(ctx)
 	reqLogger = reqLogger.WithValues("clustermaster", req.NamespacedName)
@@ -87,6 +93,7 @@ func (r *ClusterMasterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			// reconcile request.  Owned objects are automatically
 			// garbage collected. For additional cleanup logic use
 			// finalizers.  Return and don't requeue
+			deleteHealthMetrics(req, "ClusterMaster")
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -101,9 +108,16 @@ func (r *ClusterMasterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 	}
 
+	// If a plan is requested, report the changes of the reconcile instead of making them
+	if enterprise.IsPlanRequested(instance) {
+		return enterprise.ApplyPlan(ctx, r.Client, instance)
+	}
+
 	reqLogger.Info("start", "CR version", instance.GetResourceVersion())
 
 	result, err := ApplyClusterMaster(ctx, r.Client, instance)
+	tracing.RecordError(span, err)
+	updateHealthMetrics(req, instance)
 	if result.Requeue && result.RequeueAfter != 0 {
 		reqLogger.Info("Requeued", "period(seconds)", int(result.RequeueAfter/time.Second))
 	}
@@ -140,6 +154,8 @@ func (r *ClusterMasterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				IsController: false,
 				OwnerType:    &enterpriseApiV3.ClusterMaster{},
 			}).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			common.EnqueueRequestsForTLSSecret(mgr.GetClient(), &enterpriseApiV3.ClusterMasterList{})).
 		Watches(&source.Kind{Type: &corev1.Pod{}},
 			&handler.EnqueueRequestForOwner{
 				IsController: false,
@@ -151,7 +167,7 @@ func (r *ClusterMasterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				OwnerType:    &enterpriseApiV3.ClusterMaster{},
 			}).
 		WithOptions(controller.Options{
-			MaxConcurrentReconciles: enterpriseApi.TotalWorker,
+			MaxConcurrentReconciles: common.GetMaxConcurrentReconciles("ClusterMaster"),
 		}).
 		Complete(r)
 }
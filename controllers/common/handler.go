@@ -0,0 +1,40 @@
+package common
+
+import (
+	"context"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	"github.com/splunk/splunk-operator/pkg/splunk/enterprise"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// EnqueueRequestsForTLSSecret reconciles the custom resources of a list type which read certificates from a Secret,
+// so that their pods are recycled when the certificates are renewed
+func EnqueueRequestsForTLSSecret(c client.Client, list client.ObjectList) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		crs := list.DeepCopyObject().(client.ObjectList)
+		err := c.List(context.Background(), crs, client.InNamespace(obj.GetNamespace()))
+		if err != nil {
+			return nil
+		}
+		items, err := meta.ExtractList(crs)
+		if err != nil {
+			return nil
+		}
+
+		requests := []reconcile.Request{}
+		for _, item := range items {
+			cr, ok := item.(splcommon.MetaObject)
+			if ok && enterprise.IsTLSSourceSecret(cr, obj.GetName()) {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()},
+				})
+			}
+		}
+		return requests
+	})
+}
@@ -0,0 +1,16 @@
+package common
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// IsKindServed checks whether the API server serves a kind, such as the Gateway API routes whose CRDs are optional.
+// The controllers only watch the optional kinds which are served when they start
+func IsKindServed(mapper meta.RESTMapper, gvk schema.GroupVersionKind) (bool, error) {
+	_, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,30 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/splunk/splunk-operator/pkg/splunk/enterprise"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestIsKindServed(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	served, err := IsKindServed(mapper, enterprise.HecHTTPRouteGVK)
+	if err != nil || served {
+		t.Errorf("IsKindServed() without the Gateway API = %t, %v; want false, nil", served, err)
+	}
+
+	mapper.Add(enterprise.HecHTTPRouteGVK, meta.RESTScopeNamespace)
+	served, err = IsKindServed(mapper, enterprise.HecHTTPRouteGVK)
+	if err != nil || !served {
+		t.Errorf("IsKindServed() with the Gateway API = %t, %v; want true, nil", served, err)
+	}
+
+	// other versions of a served kind are not served
+	v1alpha2 := schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Kind: "HTTPRoute"}
+	served, err = IsKindServed(mapper, v1alpha2)
+	if err != nil || served {
+		t.Errorf("IsKindServed(%s) = %t, %v; want false, nil", v1alpha2, served, err)
+	}
+}
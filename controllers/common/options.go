@@ -0,0 +1,44 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+)
+
+// MaxConcurrentReconciles is the max number of concurrent reconciles of the controllers of each kind
+var MaxConcurrentReconciles = enterpriseApi.TotalWorker
+
+// MaxConcurrentReconcilesPerKind overrides MaxConcurrentReconciles for the controllers of some kinds
+var MaxConcurrentReconcilesPerKind = map[string]int{}
+
+// GetMaxConcurrentReconciles returns the max number of concurrent reconciles of the controller of a kind
+func GetMaxConcurrentReconciles(kind string) int {
+	if n, ok := MaxConcurrentReconcilesPerKind[kind]; ok {
+		return n
+	}
+	return MaxConcurrentReconciles
+}
+
+// ParseMaxConcurrentReconcilesPerKind parses a comma separated list of kind=count, ex. "IndexerCluster=2,Standalone=4"
+func ParseMaxConcurrentReconcilesPerKind(value string) (map[string]int, error) {
+	result := map[string]int{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid max concurrent reconciles %q, expected kind=count", item)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid max concurrent reconciles %q, expected a positive count", item)
+		}
+		result[strings.TrimSpace(parts[0])] = n
+	}
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package common
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMaxConcurrentReconcilesPerKind(t *testing.T) {
+	got, err := ParseMaxConcurrentReconcilesPerKind("IndexerCluster=2, Standalone=4,")
+	want := map[string]int{"IndexerCluster": 2, "Standalone": 4}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseMaxConcurrentReconcilesPerKind() = %v, %v; want %v", got, err, want)
+	}
+
+	got, err = ParseMaxConcurrentReconcilesPerKind("")
+	if err != nil || len(got) != 0 {
+		t.Errorf("ParseMaxConcurrentReconcilesPerKind(\"\") = %v, %v; want none", got, err)
+	}
+
+	for _, value := range []string{"IndexerCluster", "=2", "IndexerCluster=two", "IndexerCluster=0"} {
+		if _, err = ParseMaxConcurrentReconcilesPerKind(value); err == nil {
+			t.Errorf("ParseMaxConcurrentReconcilesPerKind(%q) should have returned an error", value)
+		}
+	}
+}
+
+func TestGetMaxConcurrentReconciles(t *testing.T) {
+	MaxConcurrentReconcilesPerKind = map[string]int{"IndexerCluster": 2}
+	defer func() { MaxConcurrentReconcilesPerKind = map[string]int{} }()
+
+	if got := GetMaxConcurrentReconciles("IndexerCluster"); got != 2 {
+		t.Errorf("GetMaxConcurrentReconciles(IndexerCluster) = %d; want 2", got)
+	}
+	if got := GetMaxConcurrentReconciles("Standalone"); got != MaxConcurrentReconciles {
+		t.Errorf("GetMaxConcurrentReconciles(Standalone) = %d; want %d", got, MaxConcurrentReconciles)
+	}
+}
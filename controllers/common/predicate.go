@@ -207,6 +207,7 @@ func CrdChangedPredicate() predicate.Predicate {
 			}
 			if !stringInSlice(newObj.Name, []string{"clustermasters.enterprise.splunk.com",
 				"indexerclusters.enterprise.splunk.com",
+				"deploymentservers.enterprise.splunk.com",
 				"licensemasters.enterprise.splunk.com",
 				"licensemanagers.enterprise.splunk.com",
 				"monitoringconsoles.enterprise.splunk.com",
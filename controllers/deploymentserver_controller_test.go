@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"time"
+
+	"github.com/splunk/splunk-operator/controllers/testutils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("DeploymentServer Controller", func() {
+
+	BeforeEach(func() {
+		time.Sleep(2 * time.Second)
+	})
+
+	AfterEach(func() {
+
+	})
+
+	Context("DeploymentServer Management", func() {
+
+		It("Get DeploymentServer custom resource should failed", func() {
+			namespace := "ns-splunk-ds-1"
+			ApplyDeploymentServer = func(ctx context.Context, client client.Client, instance *enterpriseApi.DeploymentServer) (reconcile.Result, error) {
+				return reconcile.Result{}, nil
+			}
+			nsSpecs := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			Expect(k8sClient.Create(context.Background(), nsSpecs)).Should(Succeed())
+			// check when resource not found
+			_, err := GetDeploymentServer("test", nsSpecs.Name)
+			Expect(err.Error()).Should(Equal("deploymentservers.enterprise.splunk.com \"test\" not found"))
+			Expect(k8sClient.Delete(context.Background(), nsSpecs)).Should(Succeed())
+		})
+
+		It("Create DeploymentServer custom resource with annotations should pause", func() {
+			namespace := "ns-splunk-ds-2"
+			ApplyDeploymentServer = func(ctx context.Context, client client.Client, instance *enterpriseApi.DeploymentServer) (reconcile.Result, error) {
+				return reconcile.Result{}, nil
+			}
+			nsSpecs := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			Expect(k8sClient.Create(context.Background(), nsSpecs)).Should(Succeed())
+			annotations := make(map[string]string)
+			annotations[enterpriseApi.DeploymentServerPausedAnnotation] = ""
+			CreateDeploymentServer("test", nsSpecs.Name, annotations, enterpriseApi.PhaseReady)
+			ssSpec, _ := GetDeploymentServer("test", nsSpecs.Name)
+			annotations = map[string]string{}
+			ssSpec.Annotations = annotations
+			ssSpec.Status.Phase = "Ready"
+			UpdateDeploymentServer(ssSpec, enterpriseApi.PhaseReady)
+			DeleteDeploymentServer("test", nsSpecs.Name)
+			Expect(k8sClient.Delete(context.Background(), nsSpecs)).Should(Succeed())
+		})
+
+		It("Create DeploymentServer custom resource should succeeded", func() {
+			namespace := "ns-splunk-ds-3"
+			ApplyDeploymentServer = func(ctx context.Context, client client.Client, instance *enterpriseApi.DeploymentServer) (reconcile.Result, error) {
+				return reconcile.Result{}, nil
+			}
+			nsSpecs := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			Expect(k8sClient.Create(context.Background(), nsSpecs)).Should(Succeed())
+			annotations := make(map[string]string)
+			CreateDeploymentServer("test", nsSpecs.Name, annotations, enterpriseApi.PhaseReady)
+			DeleteDeploymentServer("test", nsSpecs.Name)
+			Expect(k8sClient.Delete(context.Background(), nsSpecs)).Should(Succeed())
+		})
+
+		It("Cover Unused methods", func() {
+			namespace := "ns-splunk-ds-4"
+			ApplyDeploymentServer = func(ctx context.Context, client client.Client, instance *enterpriseApi.DeploymentServer) (reconcile.Result, error) {
+				return reconcile.Result{}, nil
+			}
+			nsSpecs := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+			Expect(k8sClient.Create(context.Background(), nsSpecs)).Should(Succeed())
+			ctx := context.TODO()
+			builder := fake.NewClientBuilder()
+			c := builder.Build()
+			instance := DeploymentServerReconciler{
+				Client: c,
+				Scheme: scheme.Scheme,
+			}
+			request := reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "test",
+					Namespace: namespace,
+				},
+			}
+			// econcile for the first time err is resource not found
+			_, err := instance.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			// create resource first adn then reconcile for the first time
+			ssSpec := testutils.NewDeploymentServer("test", namespace, "image")
+			Expect(c.Create(ctx, ssSpec)).Should(Succeed())
+			// reconcile with updated annotations for pause
+			annotations := make(map[string]string)
+			annotations[enterpriseApi.DeploymentServerPausedAnnotation] = ""
+			ssSpec.Annotations = annotations
+			Expect(c.Update(ctx, ssSpec)).Should(Succeed())
+			_, err = instance.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+			// reconcile after removing annotations for pause
+			annotations = map[string]string{}
+			ssSpec.Annotations = annotations
+			Expect(c.Update(ctx, ssSpec)).Should(Succeed())
+			_, err = instance.Reconcile(ctx, request)
+			// reconcile after adding delete timestamp
+			Expect(err).ToNot(HaveOccurred())
+			ssSpec.DeletionTimestamp = &metav1.Time{}
+			_, err = instance.Reconcile(ctx, request)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+	})
+})
+
+func GetDeploymentServer(name string, namespace string) (*enterpriseApi.DeploymentServer, error) {
+	key := types.NamespacedName{
+		Name:      name,
+		Namespace: namespace,
+	}
+	By("Expecting DeploymentServer custom resource to be created successfully")
+	ss := &enterpriseApi.DeploymentServer{}
+	err := k8sClient.Get(context.Background(), key, ss)
+	if err != nil {
+		return nil, err
+	}
+	return ss, err
+}
+
+func CreateDeploymentServer(name string, namespace string, annotations map[string]string, status enterpriseApi.Phase) *enterpriseApi.DeploymentServer {
+	key := types.NamespacedName{
+		Name:      name,
+		Namespace: namespace,
+	}
+	ssSpec := testutils.NewDeploymentServer(name, namespace, "image")
+	Expect(k8sClient.Create(context.Background(), ssSpec)).Should(Succeed())
+	time.Sleep(2 * time.Second)
+
+	By("Expecting DeploymentServer custom resource to be created successfully")
+	ss := &enterpriseApi.DeploymentServer{}
+	Eventually(func() bool {
+		_ = k8sClient.Get(context.Background(), key, ss)
+		if status != "" {
+			fmt.Printf("status is set to %v", status)
+			ss.Status.Phase = status
+			Expect(k8sClient.Status().Update(context.Background(), ss)).Should(Succeed())
+			time.Sleep(2 * time.Second)
+		}
+		return true
+	}, timeout, interval).Should(BeTrue())
+
+	return ss
+}
+
+func UpdateDeploymentServer(instance *enterpriseApi.DeploymentServer, status enterpriseApi.Phase) *enterpriseApi.DeploymentServer {
+	key := types.NamespacedName{
+		Name:      instance.Name,
+		Namespace: instance.Namespace,
+	}
+
+	ssSpec := testutils.NewDeploymentServer(instance.Name, instance.Namespace, "image")
+	ssSpec.ResourceVersion = instance.ResourceVersion
+	Expect(k8sClient.Update(context.Background(), ssSpec)).Should(Succeed())
+	time.Sleep(2 * time.Second)
+
+	By("Expecting DeploymentServer custom resource to be created successfully")
+	ss := &enterpriseApi.DeploymentServer{}
+	Eventually(func() bool {
+		_ = k8sClient.Get(context.Background(), key, ss)
+		if status != "" {
+			fmt.Printf("status is set to %v", status)
+			ss.Status.Phase = status
+			Expect(k8sClient.Status().Update(context.Background(), ss)).Should(Succeed())
+			time.Sleep(2 * time.Second)
+		}
+		return true
+	}, timeout, interval).Should(BeTrue())
+
+	return ss
+}
+
+func DeleteDeploymentServer(name string, namespace string) {
+	key := types.NamespacedName{
+		Name:      name,
+		Namespace: namespace,
+	}
+
+	By("Expecting DeploymentServer Deleted successfully")
+	Eventually(func() error {
+		ssys := &enterpriseApi.DeploymentServer{}
+		_ = k8sClient.Get(context.Background(), key, ssys)
+		err := k8sClient.Delete(context.Background(), ssys)
+		return err
+	}, timeout, interval).Should(Succeed())
+}
@@ -0,0 +1,289 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	labelStatus     = "status"
+	labelPeer       = "peer"
+	labelMember     = "member"
+	labelSearchType = "search_type"
+	labelStage      = "stage"
+)
+
+var indexerClusterPeers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_indexer_cluster_peers",
+	Help: "The number of peers of an indexer cluster by status (Up, Down, Decommissioning, ...)",
+}, []string{labelNamespace, labelName, labelStatus})
+
+var indexerClusterSearchablePeers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_indexer_cluster_searchable_peers",
+	Help: "The number of searchable peers of an indexer cluster",
+}, []string{labelNamespace, labelName})
+
+var indexerClusterPeerBuckets = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_indexer_cluster_peer_buckets",
+	Help: "The number of buckets of a peer of an indexer cluster, across all indexes",
+}, []string{labelNamespace, labelName, labelPeer})
+
+var indexerClusterReplicationFactorMet = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_indexer_cluster_replication_factor_met",
+	Help: "Whether the replication factor of an indexer cluster is met (1) or not (0)",
+}, []string{labelNamespace, labelName})
+
+var indexerClusterSearchFactorMet = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_indexer_cluster_search_factor_met",
+	Help: "Whether the search factor of an indexer cluster is met (1) or not (0)",
+}, []string{labelNamespace, labelName})
+
+var indexerClusterMaintenanceMode = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_indexer_cluster_maintenance_mode",
+	Help: "Whether an indexer cluster is in maintenance mode (1) or not (0)",
+}, []string{labelNamespace, labelName})
+
+var searchHeadClusterMembers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_search_head_cluster_members",
+	Help: "The number of members of a search head cluster",
+}, []string{labelNamespace, labelName})
+
+var searchHeadClusterRegisteredMembers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_search_head_cluster_registered_members",
+	Help: "The number of members of a search head cluster registered with the captain",
+}, []string{labelNamespace, labelName})
+
+var searchHeadClusterActiveSearches = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_search_head_cluster_active_searches",
+	Help: "The number of searches running on a member of a search head cluster, by type (historical or realtime)",
+}, []string{labelNamespace, labelName, labelMember, labelSearchType})
+
+var appDeployments = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_app_deployments",
+	Help: "The number of apps of the App Framework by deployment status (pending, in_progress, complete, error)",
+}, []string{labelNamespace, labelName, labelKind, labelStatus})
+
+var bundlePushStage = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_bundle_push_stage",
+	Help: "The current stage (1) of the bundle push of the App Framework (uninitialized, pending, in_progress, complete)",
+}, []string{labelNamespace, labelName, labelKind, labelStage})
+
+var bundlePushRequired = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_bundle_push_required",
+	Help: "Whether the manager apps of a cluster manager are waiting to be pushed to the peers (1) or not (0)",
+}, []string{labelNamespace, labelName, labelKind})
+
+// appDeployStatusLabels are the values of the status label of the app deployments
+var appDeployStatusLabels = map[enterpriseApi.AppDeploymentStatus]string{
+	enterpriseApi.DeployStatusPending:    "pending",
+	enterpriseApi.DeployStatusInProgress: "in_progress",
+	enterpriseApi.DeployStatusComplete:   "complete",
+	enterpriseApi.DeployStatusError:      "error",
+}
+
+// bundlePushStageLabels are the values of the stage label of the bundle pushes
+var bundlePushStageLabels = map[enterpriseApi.BundlePushStageType]string{
+	enterpriseApi.BundlePushUninitialized: "uninitialized",
+	enterpriseApi.BundlePushPending:       "pending",
+	enterpriseApi.BundlePushInProgress:    "in_progress",
+	enterpriseApi.BundlePushComplete:      "complete",
+}
+
+// healthMetricKey identifies the CR of the health series
+type healthMetricKey struct {
+	kind string
+	types.NamespacedName
+}
+
+// healthMetricSeries is a series exported for the health of a CR
+type healthMetricSeries struct {
+	gauge  *prometheus.GaugeVec
+	labels prometheus.Labels
+}
+
+// healthMetricLabels tracks the series exported for each CR, so that the series of removed peers, members and CRs
+// can be deleted
+var healthMetricLabels = struct {
+	sync.Mutex
+	series map[healthMetricKey][]healthMetricSeries
+}{series: make(map[healthMetricKey][]healthMetricSeries)}
+
+// healthMetrics collects the series of a CR
+type healthMetrics struct {
+	request reconcile.Request
+	series  []healthMetricSeries
+}
+
+// set sets a series of the CR, whose namespace and name labels are added to the other labels
+func (m *healthMetrics) set(gauge *prometheus.GaugeVec, labels prometheus.Labels, value float64) {
+	if labels == nil {
+		labels = prometheus.Labels{}
+	}
+	labels[labelNamespace] = m.request.Namespace
+	labels[labelName] = m.request.Name
+	gauge.With(labels).Set(value)
+	m.series = append(m.series, healthMetricSeries{gauge: gauge, labels: labels})
+}
+
+// setBool sets a series of the CR to 1 when a flag is true, and to 0 otherwise
+func (m *healthMetrics) setBool(gauge *prometheus.GaugeVec, labels prometheus.Labels, flag bool) {
+	value := 0.0
+	if flag {
+		value = 1
+	}
+	m.set(gauge, labels, value)
+}
+
+// setAppContext sets the series of the App Framework of the CR
+func (m *healthMetrics) setAppContext(kind string, appContext *enterpriseApi.AppDeploymentContext, bundlePush bool) {
+	counts := map[string]int{}
+	for _, label := range appDeployStatusLabels {
+		counts[label] = 0
+	}
+	for _, appSrc := range appContext.AppsSrcDeployStatus {
+		for _, app := range appSrc.AppDeploymentInfoList {
+			if label, ok := appDeployStatusLabels[app.DeployStatus]; ok {
+				counts[label]++
+			}
+		}
+	}
+	for label, count := range counts {
+		m.set(appDeployments, prometheus.Labels{labelKind: kind, labelStatus: label}, float64(count))
+	}
+
+	if bundlePush {
+		for stage, label := range bundlePushStageLabels {
+			m.setBool(bundlePushStage, prometheus.Labels{labelKind: kind, labelStage: label}, appContext.BundlePushStatus.BundlePushStage == stage)
+		}
+	}
+}
+
+// updateHealthMetrics exports the health of the Splunk instances of a CR recorded in its status
+func updateHealthMetrics(request reconcile.Request, cr client.Object) {
+	m := &healthMetrics{request: request}
+	var kind string
+	switch cr := cr.(type) {
+	case *enterpriseApi.IndexerCluster:
+		kind = "IndexerCluster"
+		peers := map[string]int{}
+		searchable := 0
+		for _, peer := range cr.Status.Peers {
+			if peer.Status != "" {
+				peers[peer.Status]++
+			}
+			if peer.Searchable {
+				searchable++
+			}
+			m.set(indexerClusterPeerBuckets, prometheus.Labels{labelPeer: peer.Name}, float64(peer.BucketCount))
+		}
+		for status, count := range peers {
+			m.set(indexerClusterPeers, prometheus.Labels{labelStatus: status}, float64(count))
+		}
+		m.set(indexerClusterSearchablePeers, nil, float64(searchable))
+		m.setBool(indexerClusterReplicationFactorMet, nil, cr.Status.ReplicationFactorMet)
+		m.setBool(indexerClusterSearchFactorMet, nil, cr.Status.SearchFactorMet)
+		m.setBool(indexerClusterMaintenanceMode, nil, cr.Status.MaintenanceMode)
+	case *enterpriseApi.SearchHeadCluster:
+		kind = "SearchHeadCluster"
+		registered := 0
+		for _, member := range cr.Status.Members {
+			if member.Registered {
+				registered++
+			}
+			m.set(searchHeadClusterActiveSearches, prometheus.Labels{labelMember: member.Name, labelSearchType: "historical"}, float64(member.ActiveHistoricalSearchCount))
+			m.set(searchHeadClusterActiveSearches, prometheus.Labels{labelMember: member.Name, labelSearchType: "realtime"}, float64(member.ActiveRealtimeSearchCount))
+		}
+		m.set(searchHeadClusterMembers, nil, float64(len(cr.Status.Members)))
+		m.set(searchHeadClusterRegisteredMembers, nil, float64(registered))
+		m.setAppContext(kind, &cr.Status.AppContext, true)
+	case *enterpriseApi.ClusterManager:
+		kind = "ClusterManager"
+		m.setAppContext(kind, &cr.Status.AppContext, true)
+		m.setBool(bundlePushRequired, prometheus.Labels{labelKind: kind}, cr.Status.BundlePushTracker.NeedToPushManagerApps || cr.Status.BundlePushTracker.NeedToPushMasterApps)
+	case *enterpriseApiV3.ClusterMaster:
+		kind = "ClusterMaster"
+		m.setAppContext(kind, &cr.Status.AppContext, true)
+		m.setBool(bundlePushRequired, prometheus.Labels{labelKind: kind}, cr.Status.BundlePushTracker.NeedToPushManagerApps || cr.Status.BundlePushTracker.NeedToPushMasterApps)
+	case *enterpriseApi.Standalone:
+		kind = "Standalone"
+		m.setAppContext(kind, &cr.Status.AppContext, false)
+	case *enterpriseApi.LicenseManager:
+		kind = "LicenseManager"
+		m.setAppContext(kind, &cr.Status.AppContext, false)
+	case *enterpriseApiV3.LicenseMaster:
+		kind = "LicenseMaster"
+		m.setAppContext(kind, &cr.Status.AppContext, false)
+	case *enterpriseApi.MonitoringConsole:
+		kind = "MonitoringConsole"
+		m.setAppContext(kind, &cr.Status.AppContext, false)
+	case *enterpriseApi.DeploymentServer:
+		kind = "DeploymentServer"
+		m.setAppContext(kind, &cr.Status.AppContext, false)
+	}
+
+	key := healthMetricKey{kind: kind, NamespacedName: request.NamespacedName}
+	healthMetricLabels.Lock()
+	defer healthMetricLabels.Unlock()
+	deleteHealthMetricsLocked(key, m.series)
+	healthMetricLabels.series[key] = m.series
+}
+
+// deleteHealthMetrics removes the health series of a CR
+func deleteHealthMetrics(request reconcile.Request, kind string) {
+	key := healthMetricKey{kind: kind, NamespacedName: request.NamespacedName}
+	healthMetricLabels.Lock()
+	defer healthMetricLabels.Unlock()
+	deleteHealthMetricsLocked(key, nil)
+	delete(healthMetricLabels.series, key)
+}
+
+// deleteHealthMetricsLocked removes the series exported for a CR which are not kept
+func deleteHealthMetricsLocked(key healthMetricKey, kept []healthMetricSeries) {
+	for _, series := range healthMetricLabels.series[key] {
+		found := false
+		for _, k := range kept {
+			if k.gauge == series.gauge && labelsEqual(k.labels, series.labels) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			series.gauge.Delete(series.labels)
+		}
+	}
+}
+
+// labelsEqual returns true when two label sets are equal
+func labelsEqual(a, b prometheus.Labels) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,104 @@
+/*
+Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestHealthMetrics(t *testing.T) {
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "metrics", Name: "idxc"}}
+	cr := &enterpriseApi.IndexerCluster{}
+	cr.Status.ReplicationFactorMet = true
+	cr.Status.Peers = []enterpriseApi.IndexerClusterMemberStatus{
+		{Name: "splunk-idxc-indexer-0", Status: "Up", BucketCount: 10, Searchable: true},
+		{Name: "splunk-idxc-indexer-1", Status: "Up", BucketCount: 20},
+		{Name: "splunk-idxc-indexer-2", Status: "Down"},
+	}
+	updateHealthMetrics(request, cr)
+
+	gauge := func(vec *prometheus.GaugeVec, labels prometheus.Labels) float64 {
+		labels[labelNamespace], labels[labelName] = request.Namespace, request.Name
+		return testutil.ToFloat64(vec.With(labels))
+	}
+	for _, check := range []struct {
+		vec    *prometheus.GaugeVec
+		labels prometheus.Labels
+		want   float64
+	}{
+		{indexerClusterPeers, prometheus.Labels{labelStatus: "Up"}, 2},
+		{indexerClusterPeers, prometheus.Labels{labelStatus: "Down"}, 1},
+		{indexerClusterSearchablePeers, prometheus.Labels{}, 1},
+		{indexerClusterPeerBuckets, prometheus.Labels{labelPeer: "splunk-idxc-indexer-1"}, 20},
+		{indexerClusterReplicationFactorMet, prometheus.Labels{}, 1},
+		{indexerClusterSearchFactorMet, prometheus.Labels{}, 0},
+	} {
+		if got := gauge(check.vec, check.labels); got != check.want {
+			t.Errorf("gauge %v = %v; want %v", check.labels, got, check.want)
+		}
+	}
+
+	// the series of the peers removed by a scale down are deleted
+	cr.Status.Peers = cr.Status.Peers[:1]
+	updateHealthMetrics(request, cr)
+	if got := testutil.CollectAndCount(indexerClusterPeerBuckets); got != 1 {
+		t.Errorf("%d peer bucket series; want 1", got)
+	}
+	if got := testutil.CollectAndCount(indexerClusterPeers); got != 1 {
+		t.Errorf("%d peer series; want 1", got)
+	}
+
+	// the app deployments and bundle push of a search head cluster
+	shcRequest := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "metrics", Name: "shc"}}
+	shc := &enterpriseApi.SearchHeadCluster{}
+	shc.Status.Members = []enterpriseApi.SearchHeadClusterMemberStatus{{Name: "sh-0", Registered: true, ActiveHistoricalSearchCount: 3}, {Name: "sh-1"}}
+	shc.Status.AppContext.AppsSrcDeployStatus = map[string]enterpriseApi.AppSrcDeployInfo{
+		"apps": {AppDeploymentInfoList: []enterpriseApi.AppDeploymentInfo{
+			{AppName: "a.tgz", DeployStatus: enterpriseApi.DeployStatusComplete},
+			{AppName: "b.tgz", DeployStatus: enterpriseApi.DeployStatusError},
+		}},
+	}
+	shc.Status.AppContext.BundlePushStatus.BundlePushStage = enterpriseApi.BundlePushInProgress
+	updateHealthMetrics(shcRequest, shc)
+	labels := prometheus.Labels{labelNamespace: "metrics", labelName: "shc", labelKind: "SearchHeadCluster", labelStatus: "error"}
+	if got := testutil.ToFloat64(appDeployments.With(labels)); got != 1 {
+		t.Errorf("app deployments in error = %v; want 1", got)
+	}
+	labels = prometheus.Labels{labelNamespace: "metrics", labelName: "shc", labelKind: "SearchHeadCluster", labelStage: "in_progress"}
+	if got := testutil.ToFloat64(bundlePushStage.With(labels)); got != 1 {
+		t.Errorf("bundle push stage in_progress = %v; want 1", got)
+	}
+	labels = prometheus.Labels{labelNamespace: "metrics", labelName: "shc"}
+	if got := testutil.ToFloat64(searchHeadClusterRegisteredMembers.With(labels)); got != 1 {
+		t.Errorf("registered members = %v; want 1", got)
+	}
+
+	// the series of deleted CRs are deleted
+	deleteHealthMetrics(request, "IndexerCluster")
+	deleteHealthMetrics(shcRequest, "SearchHeadCluster")
+	for _, vec := range []*prometheus.GaugeVec{indexerClusterPeers, indexerClusterPeerBuckets, appDeployments, bundlePushStage, searchHeadClusterActiveSearches} {
+		if got := testutil.CollectAndCount(vec); got != 0 {
+			t.Errorf("%d series left after the CRs are deleted; want 0", got)
+		}
+	}
+}
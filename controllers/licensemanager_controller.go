@@ -24,6 +24,7 @@ import (
 	"github.com/pkg/errors"
 	common "github.com/splunk/splunk-operator/controllers/common"
 	enterprise "github.com/splunk/splunk-operator/pkg/splunk/enterprise"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -58,7 +59,10 @@ type LicenseManagerReconciler struct {
 //+kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=cert-manager.io,resources=certificates,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=snapshot.storage.k8s.io,resources=volumesnapshots,verbs=get;list;watch;create
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -72,6 +76,8 @@ type LicenseManagerReconciler struct {
 func (r *LicenseManagerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	reconcileCounters.With(getPrometheusLabels(req, "LicenseManager")).Inc()
 	defer recordInstrumentionData(time.Now(), req, "controller", "LicenseManager")
+	ctx, span := tracing.StartReconcile(ctx, "LicenseManager", req.Namespace, req.Name)
+	defer span.End()
 
 	reqLogger := log.FromContext(ctx)
 	reqLogger = reqLogger.WithValues("licensemanager", req.NamespacedName)
@@ -85,6 +91,8 @@ func (r *LicenseManagerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 			// reconcile request.  Owned objects are automatically
 			// garbage collected. For additional cleanup logic use
 			// finalizers.  Return and don't requeue
+			deleteLicenseManagerMetrics(req)
+			deleteHealthMetrics(req, "LicenseManager")
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -99,9 +107,17 @@ func (r *LicenseManagerReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		}
 	}
 
+	// If a plan is requested, report the changes of the reconcile instead of making them
+	if enterprise.IsPlanRequested(instance) {
+		return enterprise.ApplyPlan(ctx, r.Client, instance)
+	}
+
 	reqLogger.Info("start", "CR version", instance.GetResourceVersion())
 
 	result, err := ApplyLicenseManager(ctx, r.Client, instance)
+	tracing.RecordError(span, err)
+	updateHealthMetrics(req, instance)
+	updateLicenseManagerMetrics(req, &instance.Status)
 	if result.Requeue && result.RequeueAfter != 0 {
 		reqLogger.Info("Requeued", "period(seconds)", int(result.RequeueAfter/time.Second))
 	}
@@ -138,6 +154,8 @@ func (r *LicenseManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				IsController: false,
 				OwnerType:    &enterpriseApi.LicenseManager{},
 			}).
+		Watches(&source.Kind{Type: &corev1.Secret{}},
+			common.EnqueueRequestsForTLSSecret(mgr.GetClient(), &enterpriseApi.LicenseManagerList{})).
 		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
 			&handler.EnqueueRequestForOwner{
 				IsController: false,
@@ -149,7 +167,7 @@ func (r *LicenseManagerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 				OwnerType:    &enterpriseApi.LicenseManager{},
 			}).
 		WithOptions(controller.Options{
-			MaxConcurrentReconciles: enterpriseApi.TotalWorker,
+			MaxConcurrentReconciles: common.GetMaxConcurrentReconciles("LicenseManager"),
 		}).
 		Complete(r)
 }
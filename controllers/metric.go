@@ -1,7 +1,11 @@
 package controllers
 
 import (
+	"sync"
+
 	"github.com/prometheus/client_golang/prometheus"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -14,6 +18,8 @@ const (
 	labelMethodName      = "api"
 	labelModuleName      = "module"
 	labelResourceVersion = "resource_version"
+	labelStack           = "stack"
+	labelPool            = "pool"
 )
 
 var reconcileCounters = prometheus.NewCounterVec(prometheus.CounterOpts{
@@ -36,6 +42,98 @@ var apiTotalTimeMetricEvents = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 	Help: "The time it takes to complete each call in standalone (in milliseconds)",
 }, []string{labelNamespace, labelName, labelKind, labelModuleName, labelMethodName})
 
+var licenseStackQuotaBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_license_stack_quota_bytes",
+	Help: "The daily indexing quota of a license stack of a license manager (in bytes)",
+}, []string{labelNamespace, labelName, labelStack})
+
+var licenseStackUsedBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_license_stack_used_bytes",
+	Help: "The bytes indexed today against a license stack of a license manager",
+}, []string{labelNamespace, labelName, labelStack})
+
+var licenseStackViolations = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_license_stack_violations",
+	Help: "The number of license violation messages raised for a license stack of a license manager",
+}, []string{labelNamespace, labelName, labelStack})
+
+var licensePoolQuotaBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_license_pool_quota_bytes",
+	Help: "The daily indexing quota of a license pool managed by a license manager (in bytes)",
+}, []string{labelNamespace, labelName, labelStack, labelPool})
+
+var licensePoolUsedBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "splunk_operator_license_pool_used_bytes",
+	Help: "The bytes indexed today against a license pool managed by a license manager",
+}, []string{labelNamespace, labelName, labelStack, labelPool})
+
+// licenseMetricLabels tracks the label sets exported for each license manager, so that
+// the series of removed stacks, pools and license managers can be deleted
+var licenseMetricLabels = struct {
+	sync.Mutex
+	stacks map[types.NamespacedName][]prometheus.Labels
+	pools  map[types.NamespacedName][]prometheus.Labels
+}{
+	stacks: make(map[types.NamespacedName][]prometheus.Labels),
+	pools:  make(map[types.NamespacedName][]prometheus.Labels),
+}
+
+// updateLicenseManagerMetrics exports the license usage recorded in the status of a license manager
+func updateLicenseManagerMetrics(request reconcile.Request, status *enterpriseApi.LicenseManagerStatus) {
+	licenseMetricLabels.Lock()
+	defer licenseMetricLabels.Unlock()
+
+	deleteLicenseManagerMetricsLocked(request.NamespacedName)
+
+	var stacks, pools []prometheus.Labels
+	for _, stack := range status.Stacks {
+		labels := prometheus.Labels{
+			labelNamespace: request.Namespace,
+			labelName:      request.Name,
+			labelStack:     stack.Name,
+		}
+		licenseStackQuotaBytes.With(labels).Set(float64(stack.Quota))
+		licenseStackUsedBytes.With(labels).Set(float64(stack.UsedBytes))
+		licenseStackViolations.With(labels).Set(float64(stack.Violations))
+		stacks = append(stacks, labels)
+	}
+	for _, pool := range status.Pools {
+		labels := prometheus.Labels{
+			labelNamespace: request.Namespace,
+			labelName:      request.Name,
+			labelStack:     pool.StackID,
+			labelPool:      pool.Name,
+		}
+		licensePoolQuotaBytes.With(labels).Set(float64(pool.Quota))
+		licensePoolUsedBytes.With(labels).Set(float64(pool.UsedBytes))
+		pools = append(pools, labels)
+	}
+	licenseMetricLabels.stacks[request.NamespacedName] = stacks
+	licenseMetricLabels.pools[request.NamespacedName] = pools
+}
+
+// deleteLicenseManagerMetrics removes the license usage series of a license manager
+func deleteLicenseManagerMetrics(request reconcile.Request) {
+	licenseMetricLabels.Lock()
+	defer licenseMetricLabels.Unlock()
+
+	deleteLicenseManagerMetricsLocked(request.NamespacedName)
+}
+
+func deleteLicenseManagerMetricsLocked(namespacedName types.NamespacedName) {
+	for _, labels := range licenseMetricLabels.stacks[namespacedName] {
+		licenseStackQuotaBytes.Delete(labels)
+		licenseStackUsedBytes.Delete(labels)
+		licenseStackViolations.Delete(labels)
+	}
+	for _, labels := range licenseMetricLabels.pools[namespacedName] {
+		licensePoolQuotaBytes.Delete(labels)
+		licensePoolUsedBytes.Delete(labels)
+	}
+	delete(licenseMetricLabels.stacks, namespacedName)
+	delete(licenseMetricLabels.pools, namespacedName)
+}
+
 func getPrometheusLabels(request reconcile.Request, kind string) prometheus.Labels {
 	return prometheus.Labels{
 		labelNamespace: request.Namespace,
@@ -50,5 +148,22 @@ func init() {
 		reconcileErrorCounter,
 		actionFailureCounters,
 		apiTotalTimeMetricEvents,
+		licenseStackQuotaBytes,
+		licenseStackUsedBytes,
+		licenseStackViolations,
+		licensePoolQuotaBytes,
+		licensePoolUsedBytes,
+		indexerClusterPeers,
+		indexerClusterSearchablePeers,
+		indexerClusterPeerBuckets,
+		indexerClusterReplicationFactorMet,
+		indexerClusterSearchFactorMet,
+		indexerClusterMaintenanceMode,
+		searchHeadClusterMembers,
+		searchHeadClusterRegisteredMembers,
+		searchHeadClusterActiveSearches,
+		appDeployments,
+		bundlePushStage,
+		bundlePushRequired,
 	)
 }
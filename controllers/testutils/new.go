@@ -215,6 +215,37 @@ func NewLicenseManager(name, ns, image string) *enterpriseApi.LicenseManager {
 	return ad
 }
 
+// NewDeploymentServer returns new deployment server instance with its config hash
+func NewDeploymentServer(name, ns, image string) *enterpriseApi.DeploymentServer {
+
+	c := &enterpriseApi.Spec{
+		ImagePullPolicy: string(pullPolicy),
+	}
+
+	cs := &enterpriseApi.CommonSplunkSpec{
+		Mock:    true,
+		Spec:    *c,
+		Volumes: []corev1.Volume{},
+	}
+
+	ad := &enterpriseApi.DeploymentServer{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "enterprise.splunk.com/v4",
+			Kind:       "DeploymentServer",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  ns,
+			Finalizers: []string{"enterprise.splunk.com/delete-pvc"},
+		},
+	}
+
+	ad.Spec = enterpriseApi.DeploymentServerSpec{
+		CommonSplunkSpec: *cs,
+	}
+	return ad
+}
+
 // NewLicenseMaster returns new serach head cluster instance with its config hash
 func NewLicenseMaster(name, ns, image string) *enterpriseApiV3.LicenseMaster {
 
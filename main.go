@@ -17,8 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -36,12 +39,19 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
 	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
 	"github.com/splunk/splunk-operator/controllers"
+	common "github.com/splunk/splunk-operator/controllers/common"
 	debug "github.com/splunk/splunk-operator/controllers/debug"
 	"github.com/splunk/splunk-operator/pkg/config"
+	"github.com/splunk/splunk-operator/pkg/splunk/audit"
+	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	enterprise "github.com/splunk/splunk-operator/pkg/splunk/enterprise"
+	"github.com/splunk/splunk-operator/pkg/splunk/hec"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	//+kubebuilder:scaffold:imports
 	//extapi "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
@@ -66,6 +76,12 @@ func main() {
 	var pprofActive bool
 	var logEncoder string
 	var logLevel int
+	var maxConcurrentReconcilesPerKind string
+	var enableConversionWebhook bool
+	var tracingOptions tracing.Options
+	var auditOptions audit.Options
+	var hecOptions hec.Options
+	var hecRef string
 
 	flag.StringVar(&logEncoder, "logEncoder", "json", "log encoding ('json' or 'console')")
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
@@ -75,6 +91,67 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&pprofActive, "pprof", true, "Enable pprof endpoint")
 	flag.IntVar(&logLevel, "loglevel", int(zapcore.InfoLevel), "set log level")
+	flag.BoolVar(&enterprise.PlanOnly, "plan-only", false,
+		"Report the changes the reconciles would make in a plan ConfigMap per custom resource instead of making them.")
+	flag.StringVar(&enterprise.MaintenanceWindowsConfigMap, "maintenance-windows-configmap", "",
+		"namespace/name of the ConfigMap with the maintenance windows of the custom resources that do not set their own.")
+	flag.IntVar(&common.MaxConcurrentReconciles, "max-concurrent-reconciles", common.MaxConcurrentReconciles,
+		"Max number of concurrent reconciles of the custom resources of each kind.")
+	flag.StringVar(&maxConcurrentReconcilesPerKind, "max-concurrent-reconciles-per-kind", "",
+		"Max number of concurrent reconciles overridden for some kinds, ex. IndexerCluster=2,Standalone=4.")
+	flag.IntVar(&splctrl.DisruptionBudget, "disruption-budget", 0,
+		"Max number of custom resources recycling or removing pods at the same time, 0 for no limit.")
+	flag.BoolVar(&splctrl.DisruptionBudgetPerNamespace, "disruption-budget-per-namespace", false,
+		"Apply the disruption budget to each namespace instead of the whole cluster.")
+	flag.DurationVar(&enterprise.EventDedupWindow, "event-dedup-window", enterprise.EventDedupWindow,
+		"Time during which the events identical to one published on a custom resource are dropped.")
+	flag.BoolVar(&enableConversionWebhook, "enable-conversion-webhook", false,
+		"Serve the webhook converting the custom resources between v3 and v4, which requires the serving certificate of the webhook.")
+
+	flag.StringVar(&tracingOptions.Endpoint, "tracing-otlp-endpoint", "",
+		"host:port of the OTLP gRPC receiver the traces of the reconciles are exported to, tracing is disabled when empty.")
+	flag.BoolVar(&tracingOptions.Insecure, "tracing-otlp-insecure", false,
+		"Export the traces to the OTLP receiver without TLS.")
+	flag.Float64Var(&tracingOptions.SampleRatio, "tracing-sample-ratio", 1,
+		"Ratio of the reconciles traced, between 0 and 1.")
+
+	flag.StringVar(&auditOptions.ConfigMap, "audit-configmap", "",
+		"namespace/name of a ConfigMap keeping the last disruptive actions of the operator.")
+	flag.IntVar(&auditOptions.ConfigMapRecords, "audit-configmap-records", audit.DefaultConfigMapRecords,
+		"Number of disruptive actions kept in the audit ConfigMap.")
+	flag.StringVar(&auditOptions.WebhookURL, "audit-webhook-url", "",
+		"URL the disruptive actions of the operator are posted to as JSON.")
+	flag.StringVar(&auditOptions.HECURL, "audit-hec-url", "",
+		"URL of a Splunk HTTP Event Collector the disruptive actions of the operator are sent to.")
+	flag.StringVar(&auditOptions.HECTokenFile, "audit-hec-token-file", "",
+		"File with the token of the audit HTTP Event Collector.")
+	flag.StringVar(&auditOptions.HECIndex, "audit-hec-index", "",
+		"Index of the disruptive actions sent to the HTTP Event Collector, the default index of the token when empty.")
+	flag.BoolVar(&auditOptions.InsecureSkipVerify, "audit-insecure-skip-verify", false,
+		"Do not verify the certificates of the audit webhook and HTTP Event Collector.")
+
+	flag.StringVar(&hecOptions.URL, "operator-hec-url", "",
+		"URL of an external Splunk HTTP Event Collector the logs and metrics of the operator are forwarded to.")
+	flag.StringVar(&hecOptions.TokenFile, "operator-hec-token-file", "",
+		"File with the token of the external HTTP Event Collector.")
+	flag.StringVar(&hecRef, "operator-hec-ref", "",
+		"Standalone or IndexerCluster whose HTTP Event Collector the logs and metrics of the operator are forwarded to, as Kind/namespace/name[/token].")
+	flag.StringVar(&hecOptions.Index, "operator-hec-index", "",
+		"Index of the logs of the operator, the default index of the token when empty.")
+	flag.StringVar(&hecOptions.MetricsIndex, "operator-hec-metrics-index", "",
+		"Metrics index the metrics of the operator are exported to, the metrics are not exported when empty.")
+	flag.DurationVar(&hecOptions.MetricsInterval, "operator-hec-metrics-interval", hec.DefaultMetricsInterval,
+		"Time between two exports of the metrics of the operator.")
+	flag.IntVar(&hecOptions.BatchSize, "operator-hec-batch-size", hec.DefaultBatchSize,
+		"Number of events sent per request to the HTTP Event Collector.")
+	flag.DurationVar(&hecOptions.FlushInterval, "operator-hec-flush-interval", hec.DefaultFlushInterval,
+		"Time the events wait for a batch to fill up before they are sent.")
+	flag.IntVar(&hecOptions.MaxBufferedEvents, "operator-hec-max-buffered-events", hec.DefaultMaxBufferedEvents,
+		"Number of events kept while the HTTP Event Collector is unavailable, the oldest ones are dropped.")
+	flag.StringVar(&hecOptions.CAFile, "operator-hec-ca-file", "",
+		"PEM file with the certificate authorities the certificate of the HTTP Event Collector is verified with.")
+	flag.BoolVar(&hecOptions.InsecureSkipVerify, "operator-hec-insecure-skip-verify", false,
+		"Do not verify the certificate of the HTTP Event Collector.")
 
 	opts := zap.Options{
 		Development: true,
@@ -83,8 +160,37 @@ func main() {
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
-	// Logging setup
+	// Logging setup, the logs are also sent to the HTTP Event Collector of the operator if any
+	hecForwarder, hecTarget, hecErr := newHecForwarder(hecOptions, hecRef)
+	if hecForwarder != nil {
+		var level zapcore.LevelEnabler = zapcore.InfoLevel
+		if opts.Level != nil {
+			level = opts.Level
+		}
+		opts.ZapOpts = append(opts.ZapOpts, hecForwarder.TeeLogs(level))
+	}
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	if hecErr != nil {
+		setupLog.Error(hecErr, "unable to set up the operator HTTP Event Collector")
+		os.Exit(1)
+	}
+
+	perKind, err := common.ParseMaxConcurrentReconcilesPerKind(maxConcurrentReconcilesPerKind)
+	if err != nil {
+		setupLog.Error(err, "invalid max-concurrent-reconciles-per-kind")
+		os.Exit(1)
+	}
+	common.MaxConcurrentReconcilesPerKind = perKind
+	if common.MaxConcurrentReconciles < 1 {
+		setupLog.Error(nil, "max-concurrent-reconciles must be positive")
+		os.Exit(1)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracingOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to set up tracing")
+		os.Exit(1)
+	}
 
 	options := ctrl.Options{
 		Scheme:                 scheme,
@@ -101,6 +207,33 @@ func main() {
 		os.Exit(1)
 	}
 
+	enterprise.EventRecorder = mgr.GetEventRecorderFor("splunk-operator")
+
+	if err = audit.Setup(auditOptions, mgr.GetClient()); err != nil {
+		setupLog.Error(err, "unable to set up the audit sinks")
+		os.Exit(1)
+	}
+	if err = mgr.Add(audit.Dispatcher{}); err != nil {
+		setupLog.Error(err, "unable to add the audit dispatcher")
+		os.Exit(1)
+	}
+
+	if hecForwarder != nil {
+		if hecTarget != nil {
+			hecTarget.Reader = mgr.GetAPIReader()
+		}
+		if err = mgr.Add(hecForwarder); err != nil {
+			setupLog.Error(err, "unable to add the HTTP Event Collector forwarder")
+			os.Exit(1)
+		}
+		if hecOptions.MetricsIndex != "" {
+			if err = mgr.Add(&hec.MetricsExporter{Forwarder: hecForwarder, Gatherer: metrics.Registry}); err != nil {
+				setupLog.Error(err, "unable to add the metrics exporter")
+				os.Exit(1)
+			}
+		}
+	}
+
 	if err = (&controllers.ClusterMasterReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -115,6 +248,13 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterManager")
 		os.Exit(1)
 	}
+	if err = (&controllers.DeploymentServerReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "DeploymentServer")
+		os.Exit(1)
+	}
 	if err = (&controllers.IndexerClusterReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
@@ -157,6 +297,12 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "Standalone")
 		os.Exit(1)
 	}
+	if enableConversionWebhook {
+		if err = enterpriseApi.SetupConversionWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "conversion")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -174,12 +320,50 @@ func main() {
 	}
 
 	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	err = mgr.Start(ctrl.SetupSignalHandler())
+
+	// export the spans left before exiting
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(ctx); err != nil {
+		setupLog.Error(err, "unable to flush the traces")
+	}
+	if hecForwarder != nil {
+		if err := hecForwarder.Flush(ctx); err != nil {
+			setupLog.Error(err, "unable to flush the events of the HTTP Event Collector")
+		}
+	}
+	cancel()
+
+	if err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
 }
 
+// newHecForwarder returns the forwarder of the logs and metrics of the operator to the HTTP Event Collector of a
+// custom resource reference, or to an external one, and the target of the reference. There is no forwarder when
+// neither is set.
+func newHecForwarder(opts hec.Options, ref string) (*hec.Forwarder, *enterprise.HecTarget, error) {
+	switch {
+	case ref != "" && opts.URL != "":
+		return nil, nil, errors.New("operator-hec-ref and operator-hec-url are mutually exclusive")
+	case ref != "":
+		target, err := enterprise.ParseHecTarget(ref)
+		if err != nil {
+			return nil, nil, err
+		}
+		forwarder, err := hec.NewForwarder(target, opts)
+		return forwarder, target, err
+	case opts.URL != "":
+		if opts.TokenFile == "" {
+			return nil, nil, errors.New("operator-hec-token-file is required with operator-hec-url")
+		}
+		forwarder, err := hec.NewForwarder(hec.FileTarget{URL: opts.URL, TokenFile: opts.TokenFile}, opts)
+		return forwarder, nil, err
+	}
+	return nil, nil, nil
+}
+
 // Note that these endpoints meant to be sensitive and shouldn't be exposed publicly.
 func customSetupEndpoints(pprofActive bool, mgr manager.Manager) error {
 	if pprofActive {
@@ -34,7 +34,7 @@ const (
 // GetWatchNamespaces returns the Namespaces the operator should be watching for changes.
 func GetWatchNamespaces() []string {
 	ns, found := os.LookupEnv(WatchNamespaceEnvVar)
-	if !found {
+	if !found || ns == "" {
 		return nil
 	}
 
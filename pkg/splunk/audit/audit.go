@@ -0,0 +1,171 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Actions recorded in the audit trail
+const (
+	// PodDeleted is the deletion of a pod recycled for an update or a restart
+	PodDeleted = "PodDeleted"
+
+	// PodRemoved is the removal of a pod by a scale down of its StatefulSet
+	PodRemoved = "PodRemoved"
+
+	// PeerDecommissioned is the decommission of an indexer peer
+	PeerDecommissioned = "PeerDecommissioned"
+
+	// BundlePushed is a push of the cluster bundle of a cluster manager or of a search head cluster deployer
+	BundlePushed = "BundlePushed"
+
+	// SecretChanged is a change of the idxc secret, shc secret or admin password of a pod
+	SecretChanged = "SecretChanged"
+)
+
+const (
+	// MaxStatusRecords is the number of records kept in the status of a custom resource
+	MaxStatusRecords = 20
+
+	// queueSize is the number of records waiting to be sent to the sinks
+	queueSize = 1000
+
+	// sendTimeout is the time a sink is given to accept a record
+	sendTimeout = 10 * time.Second
+)
+
+// Record is an audit record with the custom resource the action was taken for, as sent to the sinks
+type Record struct {
+	Kind                      string `json:"kind"`
+	Namespace                 string `json:"namespace"`
+	Name                      string `json:"name"`
+	enterpriseApi.AuditRecord `json:",inline"`
+}
+
+// Sink receives the audit records
+type Sink interface {
+	// Send appends a record to the sink
+	Send(ctx context.Context, record Record) error
+}
+
+// trail is the audit trail of a custom resource, carried by the context of its reconcile
+type trail struct {
+	mutex   sync.Mutex
+	cr      splcommon.MetaObject
+	history *[]enterpriseApi.AuditRecord
+}
+
+type trailKey struct{}
+
+// NewContext returns a context recording the disruptive actions of a reconcile in the audit trail of a custom
+// resource status
+func NewContext(ctx context.Context, cr splcommon.MetaObject, history *[]enterpriseApi.AuditRecord) context.Context {
+	return context.WithValue(ctx, trailKey{}, &trail{cr: cr, history: history})
+}
+
+// sinks receive the records queued by Log, no records are queued when there are none
+var sinks []Sink
+
+// queue holds the records until they are sent to the sinks
+var queue = make(chan Record, queueSize)
+
+// Log records a disruptive action on a target for a reason, failed if err is not nil, in the audit trail of the
+// custom resource of the context and in the sinks
+func Log(ctx context.Context, action, target, reason string, err error) {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("audit").WithValues("action", action, "target", target, "reason", reason)
+
+	t, ok := ctx.Value(trailKey{}).(*trail)
+	if !ok {
+		scopedLog.Info("no audit trail for the action")
+		return
+	}
+
+	record := Record{
+		Kind:      t.cr.GetObjectKind().GroupVersionKind().Kind,
+		Namespace: t.cr.GetNamespace(),
+		Name:      t.cr.GetName(),
+		AuditRecord: enterpriseApi.AuditRecord{
+			Time:       metav1.Now(),
+			Action:     action,
+			Target:     target,
+			Reason:     reason,
+			Generation: t.cr.GetGeneration(),
+			Outcome:    enterpriseApi.AuditSucceeded,
+		},
+	}
+	if err != nil {
+		record.Outcome = enterpriseApi.AuditFailed
+		record.Error = err.Error()
+	}
+	scopedLog.Info("audit", "outcome", record.Outcome, "generation", record.Generation)
+
+	// the App Framework pipeline records its bundle pushes from its own goroutines
+	t.mutex.Lock()
+	history := append(*t.history, record.AuditRecord)
+	if len(history) > MaxStatusRecords {
+		history = history[len(history)-MaxStatusRecords:]
+	}
+	*t.history = history
+	t.mutex.Unlock()
+
+	if len(sinks) == 0 {
+		return
+	}
+	select {
+	case queue <- record:
+	default:
+		scopedLog.Error(nil, "audit queue full, dropping the record for the sinks")
+	}
+}
+
+// Dispatcher sends the queued records to the sinks, it is run by the manager of the operator
+type Dispatcher struct{}
+
+// Start sends the queued records to the sinks until the context is done
+func (Dispatcher) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case record := <-queue:
+			send(ctx, record)
+		}
+	}
+}
+
+// send sends a record to all the sinks, logging the ones failing
+func send(ctx context.Context, record Record) {
+	scopedLog := log.FromContext(ctx).WithName("audit")
+	for _, sink := range sinks {
+		sinkCtx, cancel := context.WithTimeout(ctx, sendTimeout)
+		err := sink.Send(sinkCtx, record)
+		cancel()
+		if err != nil {
+			scopedLog.Error(err, "unable to send the audit record", "sink", fmt.Sprintf("%T", sink), "kind", record.Kind,
+				"namespace", record.Namespace, "name", record.Name, "action", record.Action, "target", record.Target)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// recordingSink keeps the records sent to it
+type recordingSink struct {
+	records []Record
+	err     error
+}
+
+func (s *recordingSink) Send(ctx context.Context, record Record) error {
+	s.records = append(s.records, record)
+	return s.err
+}
+
+func TestLog(t *testing.T) {
+	cr := &enterpriseApi.Standalone{
+		TypeMeta:   metav1.TypeMeta{Kind: "Standalone"},
+		ObjectMeta: metav1.ObjectMeta{Name: "s1", Namespace: "test", Generation: 3},
+	}
+	sink := &recordingSink{}
+	sinks = []Sink{sink}
+	defer func() { sinks = nil }()
+
+	// actions without an audit trail are only logged
+	Log(context.TODO(), PodDeleted, "splunk-s1-standalone-0", "restart requested", nil)
+	if len(queue) != 0 {
+		t.Errorf("Log() queued a record without an audit trail")
+	}
+
+	ctx := NewContext(context.TODO(), cr, &cr.Status.AuditTrail)
+	Log(ctx, PodDeleted, "splunk-s1-standalone-0", "restart requested", nil)
+	Log(ctx, PodDeleted, "splunk-s1-standalone-1", "restart requested", errors.New("conflict"))
+	if len(cr.Status.AuditTrail) != 2 {
+		t.Fatalf("Log() audit trail = %v; want 2 records", cr.Status.AuditTrail)
+	}
+	got := cr.Status.AuditTrail[1]
+	if got.Action != PodDeleted || got.Target != "splunk-s1-standalone-1" || got.Generation != 3 ||
+		got.Outcome != enterpriseApi.AuditFailed || got.Error != "conflict" {
+		t.Errorf("Log() record = %+v", got)
+	}
+
+	// the records are sent to the sinks by the dispatcher
+	for len(queue) > 0 {
+		send(context.TODO(), <-queue)
+	}
+	if len(sink.records) != 2 || sink.records[0].Kind != "Standalone" || sink.records[0].Name != "s1" || sink.records[0].Outcome != enterpriseApi.AuditSucceeded {
+		t.Errorf("send() records = %+v", sink.records)
+	}
+
+	// the status keeps the last records
+	for i := 0; i < MaxStatusRecords; i++ {
+		Log(ctx, SecretChanged, fmt.Sprintf("pod-%d", i), "", nil)
+	}
+	for len(queue) > 0 {
+		<-queue
+	}
+	if len(cr.Status.AuditTrail) != MaxStatusRecords || cr.Status.AuditTrail[0].Target != "pod-0" {
+		t.Errorf("Log() kept %d records, first %q", len(cr.Status.AuditTrail), cr.Status.AuditTrail[0].Target)
+	}
+}
+
+func TestConfigMapSink(t *testing.T) {
+	ctx := context.TODO()
+	c := fake.NewClientBuilder().Build()
+	sink := &ConfigMapSink{Client: c, Namespace: "splunk-operator", Name: "audit", MaxRecords: 2}
+
+	for _, target := range []string{"pod-0", "pod-1", "pod-2"} {
+		if err := sink.Send(ctx, Record{Name: "s1", AuditRecord: enterpriseApi.AuditRecord{Action: PodDeleted, Target: target}}); err != nil {
+			t.Fatalf("Send() returned error: %v", err)
+		}
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "splunk-operator", Name: "audit"}, configMap); err != nil {
+		t.Fatalf("Send() did not create the ConfigMap: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(configMap.Data[ConfigMapKey], "\n"), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], `"target":"pod-1"`) || !strings.Contains(lines[1], `"target":"pod-2"`) {
+		t.Errorf("Send() records = %q", configMap.Data[ConfigMapKey])
+	}
+}
+
+func TestHTTPSinks(t *testing.T) {
+	var authorization, path string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		path = r.URL.Path
+		body, _ = io.ReadAll(r.Body)
+		if strings.Contains(string(body), "fail") {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+	record := Record{Name: "idxc", AuditRecord: enterpriseApi.AuditRecord{Action: PeerDecommissioned, Target: "splunk-idxc-indexer-2"}}
+
+	webhook := &WebhookSink{URL: server.URL + "/audit", Client: server.Client()}
+	if err := webhook.Send(context.TODO(), record); err != nil {
+		t.Fatalf("WebhookSink.Send() returned error: %v", err)
+	}
+	var got Record
+	if err := json.Unmarshal(body, &got); err != nil || path != "/audit" || got.Target != record.Target {
+		t.Errorf("WebhookSink.Send() posted %s to %s", body, path)
+	}
+
+	hec := &HECSink{URL: server.URL, Token: "token", Index: "operator", Client: server.Client()}
+	if err := hec.Send(context.TODO(), record); err != nil {
+		t.Fatalf("HECSink.Send() returned error: %v", err)
+	}
+	var event hecEvent
+	if err := json.Unmarshal(body, &event); err != nil || path != "/services/collector/event" || authorization != "Splunk token" ||
+		event.Index != "operator" || event.SourceType != hecSourceType {
+		t.Errorf("HECSink.Send() posted %s to %s with %q", body, path, authorization)
+	}
+
+	record.Target = "fail"
+	if err := webhook.Send(context.TODO(), record); err == nil {
+		t.Errorf("WebhookSink.Send() returned no error for a failed response")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	defer func() { sinks = nil }()
+
+	if err := Setup(Options{ConfigMap: "audit"}, nil); err == nil {
+		t.Errorf("Setup() returned no error for a ConfigMap without namespace")
+	}
+	if err := Setup(Options{HECURL: "https://hec:8088", HECTokenFile: filepath.Join(t.TempDir(), "missing")}, nil); err == nil {
+		t.Errorf("Setup() returned no error for a missing HEC token file")
+	}
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	_ = os.WriteFile(tokenFile, []byte("token\n"), 0600)
+	err := Setup(Options{ConfigMap: "splunk-operator/audit", WebhookURL: "https://webhook", HECURL: "https://hec:8088", HECTokenFile: tokenFile}, nil)
+	if err != nil {
+		t.Fatalf("Setup() returned error: %v", err)
+	}
+	if len(sinks) != 3 || sinks[0].(*ConfigMapSink).MaxRecords != DefaultConfigMapRecords || sinks[2].(*HECSink).Token != "token" {
+		t.Errorf("Setup() sinks = %+v", sinks)
+	}
+}
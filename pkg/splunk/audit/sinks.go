@@ -0,0 +1,216 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// ConfigMapKey is the key of the records in the ConfigMap of a ConfigMapSink, one JSON record per line
+	ConfigMapKey = "audit.jsonl"
+
+	// DefaultConfigMapRecords is the number of records kept by a ConfigMapSink by default
+	DefaultConfigMapRecords = 1000
+
+	// hecSourceType is the sourcetype of the records sent to a HEC endpoint
+	hecSourceType = "splunk:operator:audit"
+)
+
+// Options configures the sinks the audit records are sent to, in addition to the status of the custom resources
+type Options struct {
+	// ConfigMap is the namespace/name of a ConfigMap keeping the last records
+	ConfigMap string
+
+	// ConfigMapRecords is the number of records kept in the ConfigMap
+	ConfigMapRecords int
+
+	// WebhookURL is the URL the records are posted to as JSON
+	WebhookURL string
+
+	// HECURL is the URL of a Splunk HTTP Event Collector, ex. https://splunk-hec.example.com:8088
+	HECURL string
+
+	// HECTokenFile is the file with the HEC token
+	HECTokenFile string
+
+	// HECIndex is the index of the records, the default index of the token when empty
+	HECIndex string
+
+	// InsecureSkipVerify disables the verification of the certificates of the webhook and HEC endpoints
+	InsecureSkipVerify bool
+}
+
+// Setup configures the sinks of the options, which the Dispatcher sends the records to
+func Setup(opts Options, c splcommon.ControllerClient) error {
+	sinks = nil
+	httpClient := &http.Client{
+		Timeout:   sendTimeout,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}},
+	}
+
+	if opts.ConfigMap != "" {
+		parts := strings.SplitN(opts.ConfigMap, "/", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid audit ConfigMap %q, expected namespace/name", opts.ConfigMap)
+		}
+		records := opts.ConfigMapRecords
+		if records <= 0 {
+			records = DefaultConfigMapRecords
+		}
+		sinks = append(sinks, &ConfigMapSink{Client: c, Namespace: parts[0], Name: parts[1], MaxRecords: records})
+	}
+	if opts.WebhookURL != "" {
+		sinks = append(sinks, &WebhookSink{URL: opts.WebhookURL, Client: httpClient})
+	}
+	if opts.HECURL != "" {
+		token, err := os.ReadFile(opts.HECTokenFile)
+		if err != nil {
+			return fmt.Errorf("unable to read the audit HEC token: %w", err)
+		}
+		sinks = append(sinks, &HECSink{URL: opts.HECURL, Token: strings.TrimSpace(string(token)), Index: opts.HECIndex, Client: httpClient})
+	}
+	return nil
+}
+
+// ConfigMapSink keeps the last records in a ConfigMap, as a ring buffer of JSON lines
+type ConfigMapSink struct {
+	Client     splcommon.ControllerClient
+	Namespace  string
+	Name       string
+	MaxRecords int
+}
+
+// Send appends a record to the ConfigMap, creating it if needed, and drops its oldest records over MaxRecords
+func (s *ConfigMapSink) Send(ctx context.Context, record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := s.Client.Get(ctx, types.NamespacedName{Namespace: s.Namespace, Name: s.Name}, configMap)
+		if k8serrors.IsNotFound(err) {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: s.Namespace, Name: s.Name},
+				Data:       map[string]string{ConfigMapKey: string(line) + "\n"},
+			}
+			return s.Client.Create(ctx, configMap)
+		} else if err != nil {
+			return err
+		}
+
+		var lines []string
+		if data := strings.TrimSuffix(configMap.Data[ConfigMapKey], "\n"); data != "" {
+			lines = strings.Split(data, "\n")
+		}
+		lines = append(lines, string(line))
+		if len(lines) > s.MaxRecords {
+			lines = lines[len(lines)-s.MaxRecords:]
+		}
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[ConfigMapKey] = strings.Join(lines, "\n") + "\n"
+		return s.Client.Update(ctx, configMap)
+	})
+}
+
+// WebhookSink posts the records as JSON to a URL
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// Send posts a record to the webhook
+func (s *WebhookSink) Send(ctx context.Context, record Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.Client, s.URL, "", body)
+}
+
+// HECSink sends the records as events to a Splunk HTTP Event Collector
+type HECSink struct {
+	URL    string
+	Token  string
+	Index  string
+	Client *http.Client
+}
+
+// hecEvent is the payload of an event sent to a HEC endpoint
+type hecEvent struct {
+	Time       float64     `json:"time"`
+	Source     string      `json:"source"`
+	SourceType string      `json:"sourcetype"`
+	Index      string      `json:"index,omitempty"`
+	Event      interface{} `json:"event"`
+}
+
+// Send sends a record to the event endpoint of the collector
+func (s *HECSink) Send(ctx context.Context, record Record) error {
+	body, err := json.Marshal(hecEvent{
+		Time:       float64(record.Time.UnixNano()) / float64(time.Second),
+		Source:     "splunk-operator",
+		SourceType: hecSourceType,
+		Index:      s.Index,
+		Event:      record,
+	})
+	if err != nil {
+		return err
+	}
+	return post(ctx, s.Client, strings.TrimSuffix(s.URL, "/")+"/services/collector/event", "Splunk "+s.Token, body)
+}
+
+// post posts a JSON body to a URL, failing unless the response is a success
+func post(ctx context.Context, client *http.Client, url, authorization string, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		request.Header.Set("Authorization", authorization)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", url, response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
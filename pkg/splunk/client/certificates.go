@@ -0,0 +1,99 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// certificateAuthority is the CA registered for a domain, with the PEM encoded certificates it was parsed from
+type certificateAuthority struct {
+	caCert []byte
+	pool   *x509.CertPool
+}
+
+// certificateAuthorities holds the CAs the management certificates of the Splunk instances are verified against,
+// by the domain of their services. A nil pool verifies against the root CAs of the host
+var certificateAuthorities = struct {
+	sync.RWMutex
+	domains map[string]certificateAuthority
+}{domains: make(map[string]certificateAuthority)}
+
+// RegisterCertificateAuthority makes the clients of a domain and of its sub domains (e.g. the pods of a headless service)
+// verify the management certificate against the PEM encoded CA certificates, or against the root CAs of the host when caCert is empty.
+// Registering the same CA again keeps the transports of the domain and their connections
+func RegisterCertificateAuthority(domain string, caCert []byte) error {
+	certificateAuthorities.RLock()
+	current, ok := certificateAuthorities.domains[domain]
+	certificateAuthorities.RUnlock()
+	if ok && bytes.Equal(current.caCert, caCert) {
+		return nil
+	}
+
+	var pool *x509.CertPool
+	if len(caCert) > 0 {
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return fmt.Errorf("no valid CA certificate for %s", domain)
+		}
+	}
+
+	certificateAuthorities.Lock()
+	certificateAuthorities.domains[domain] = certificateAuthority{caCert: append([]byte(nil), caCert...), pool: pool}
+	certificateAuthorities.Unlock()
+	resetTransports(domain)
+	return nil
+}
+
+// UnregisterCertificateAuthority makes the clients of a domain accept the self signed certificates of Splunk again
+func UnregisterCertificateAuthority(domain string) {
+	certificateAuthorities.Lock()
+	_, ok := certificateAuthorities.domains[domain]
+	delete(certificateAuthorities.domains, domain)
+	certificateAuthorities.Unlock()
+	if ok {
+		resetTransports(domain)
+	}
+}
+
+// isInDomain returns true if a host is a domain or one of its sub domains
+func isInDomain(host string, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// getTLSConfig returns the TLS configuration used by the clients of a management URI
+func getTLSConfig(managementURI string) *tls.Config {
+	u, err := url.Parse(managementURI)
+	if err == nil {
+		host := u.Hostname()
+
+		certificateAuthorities.RLock()
+		defer certificateAuthorities.RUnlock()
+		for domain, ca := range certificateAuthorities.domains {
+			if isInDomain(host, domain) {
+				return &tls.Config{RootCAs: ca.pool}
+			}
+		}
+	}
+
+	return &tls.Config{InsecureSkipVerify: true} // don't verify the self signed certs of splunk
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetTLSConfig(t *testing.T) {
+	domain := "splunk-s1-indexer-headless.test.svc.cluster.local"
+	if err := RegisterCertificateAuthority(domain, []byte("not a certificate")); err == nil {
+		t.Errorf("RegisterCertificateAuthority() should have returned an error for an invalid CA")
+	}
+
+	if err := RegisterCertificateAuthority(domain, nil); err != nil {
+		t.Errorf("RegisterCertificateAuthority() returned error: %v", err)
+	}
+	defer UnregisterCertificateAuthority(domain)
+
+	for uri, insecure := range map[string]bool{
+		"https://" + domain + ":8089":                                   false,
+		"https://splunk-s1-indexer-0." + domain + ":8089":               false,
+		"https://splunk-s1-indexer-service.test.svc.cluster.local:8089": true,
+		"https://localhost:8089":                                        true,
+	} {
+		if got := getTLSConfig(uri); got.InsecureSkipVerify != insecure || got.RootCAs != nil {
+			t.Errorf("getTLSConfig(%s) InsecureSkipVerify = %t; want %t", uri, got.InsecureSkipVerify, insecure)
+		}
+	}
+
+	UnregisterCertificateAuthority(domain)
+	if got := getTLSConfig("https://" + domain + ":8089"); !got.InsecureSkipVerify {
+		t.Errorf("getTLSConfig() should skip the verification once the CA is unregistered")
+	}
+}
+
+func TestNewSplunkClientVerifiesCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// the test server listens on 127.0.0.1, which its certificate is issued for
+	domain := "127.0.0.1"
+	defer UnregisterCertificateAuthority(domain)
+
+	// without CA, the certificate is not verified
+	if err := NewSplunkClient(server.URL, "admin", "p@ssw0rd").Get(context.TODO(), "/services/server/info", nil); err != nil {
+		t.Errorf("Get() returned error: %v", err)
+	}
+
+	// the certificate is verified against the CA of the server
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := RegisterCertificateAuthority(domain, ca); err != nil {
+		t.Fatalf("RegisterCertificateAuthority() returned error: %v", err)
+	}
+	if err := NewSplunkClient(server.URL, "admin", "p@ssw0rd").Get(context.TODO(), "/services/server/info", nil); err != nil {
+		t.Errorf("Get() returned error: %v", err)
+	}
+
+	// a certificate of another CA is rejected
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "other CA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() returned error: %v", err)
+	}
+	ca = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	if err := RegisterCertificateAuthority(domain, ca); err != nil {
+		t.Fatalf("RegisterCertificateAuthority() returned error: %v", err)
+	}
+	if err := NewSplunkClient(server.URL, "admin", "p@ssw0rd").Get(context.TODO(), "/services/server/info", nil); err == nil {
+		t.Errorf("Get() should have failed to verify the certificate")
+	}
+}
@@ -16,25 +16,50 @@
 package client
 
 import (
-	"crypto/tls"
+	"context"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// RequestTimeout bounds the time taken by each attempt of a Splunk REST API request
+var RequestTimeout = 5 * time.Second
+
 // SplunkHTTPClient defines the interface used by SplunkClient.
 // It is used to mock alternative implementations used for testing.
 type SplunkHTTPClient interface {
 	Do(*http.Request) (*http.Response, error)
 }
 
+// RetryPolicy defines how the idempotent requests of a SplunkClient are retried when Splunk cannot be reached
+// or is temporarily unavailable. The zero value sends each request only once.
+type RetryPolicy struct {
+	// maximum number of attempts of a request, including the first one
+	MaxAttempts int
+
+	// delay before the first retry, doubled on each subsequent retry
+	BaseDelay time.Duration
+
+	// upper bound of the delay between two attempts
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy is the RetryPolicy of the clients returned by NewSplunkClient
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: 250 * time.Millisecond, MaxDelay: 2 * time.Second}
+
 // SplunkClient is a simple object used to send HTTP REST API requests
 type SplunkClient struct {
 	// https endpoint for management interface (e.g. "https://server:8089")
@@ -48,31 +73,57 @@ type SplunkClient struct {
 
 	// HTTP client used to process requests
 	Client SplunkHTTPClient
+
+	// policy used to retry the idempotent requests
+	RetryPolicy RetryPolicy
 }
 
 // NewSplunkClient returns a new SplunkClient object initialized with a username and password.
+// The management certificate is verified when a CA is registered for the host, see RegisterCertificateAuthority.
+// The clients of the pods of a same CR share their transport, so that their connections are reused across reconciles.
 func NewSplunkClient(managementURI, username, password string) *SplunkClient {
 	return &SplunkClient{
 		ManagementURI: managementURI,
 		Username:      username,
 		Password:      password,
 		Client: &http.Client{
-			Timeout: 5 * time.Second,
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // don't verify ssl certs
-			},
+			Timeout:   RequestTimeout,
+			Transport: getTransport(managementURI),
 		},
+		RetryPolicy: DefaultRetryPolicy,
 	}
 }
 
 // Do processes a Splunk REST API request and unmarshals response into obj, if not nil.
-func (c *SplunkClient) Do(request *http.Request, expectedStatus []int, obj interface{}) error {
+// Idempotent requests are retried according to the RetryPolicy of the client, until ctx is done.
+func (c *SplunkClient) Do(ctx context.Context, request *http.Request, expectedStatus []int, obj interface{}) error {
+	start := time.Now()
+	labels := []string{getTarget(request.URL), request.Method, getEndpointLabel(request.URL.Path)}
+	ctx, span := tracing.Start(ctx, request.Method+" "+labels[2],
+		attribute.String("http.method", request.Method),
+		attribute.String("net.peer.name", request.URL.Hostname()),
+		attribute.String("http.target", request.URL.Path))
+	defer span.End()
+	err := c.do(ctx, request, expectedStatus, obj)
+	tracing.RecordError(span, err)
+	requestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	if err != nil {
+		requestErrors.WithLabelValues(labels...).Inc()
+	}
+	return err
+}
+
+// do sends a request for Do
+func (c *SplunkClient) do(ctx context.Context, request *http.Request, expectedStatus []int, obj interface{}) error {
 	// send HTTP response and check status
+	request = request.WithContext(ctx)
 	request.SetBasicAuth(c.Username, c.Password)
-	response, err := c.Client.Do(request)
+	response, err := c.send(ctx, request)
 	if err != nil {
 		return err
 	}
+	defer response.Body.Close()
+
 	//default set flag to false and the check response code
 	expectedStatusFlag := false
 	for i := 0; i < len(expectedStatus); i++ {
@@ -96,15 +147,94 @@ func (c *SplunkClient) Do(request *http.Request, expectedStatus []int, obj inter
 	return json.Unmarshal(data, obj)
 }
 
+// send sends a request, and retries it while it is idempotent, the previous attempt failed with a temporary error
+// and the attempts of the RetryPolicy are not exhausted
+func (c *SplunkClient) send(ctx context.Context, request *http.Request) (*http.Response, error) {
+	attempts := 1
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		if c.RetryPolicy.MaxAttempts > attempts {
+			attempts = c.RetryPolicy.MaxAttempts
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		response, err := c.Client.Do(request)
+		if attempt >= attempts || !isRetryable(ctx, response, err) {
+			return response, err
+		}
+		if err == nil {
+			_, _ = io.Copy(io.Discard, response.Body)
+			response.Body.Close()
+		}
+		requestRetries.WithLabelValues(getTarget(request.URL), request.Method, getEndpointLabel(request.URL.Path)).Inc()
+
+		err = c.RetryPolicy.wait(ctx, attempt)
+		if err != nil {
+			return nil, err
+		}
+		if request.GetBody != nil {
+			request.Body, err = request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// isRetryable returns true when an attempt failed because Splunk could not be reached, or is temporarily unavailable
+// (e.g. while a search head captain is elected)
+func isRetryable(ctx context.Context, response *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		// the errors of an http.Client are url.Error, a certificate rejected by the client is not temporary
+		var urlErr *url.Error
+		var authorityErr x509.UnknownAuthorityError
+		var hostnameErr x509.HostnameError
+		var invalidErr x509.CertificateInvalidError
+		return errors.As(err, &urlErr) && !errors.As(err, &authorityErr) && !errors.As(err, &hostnameErr) && !errors.As(err, &invalidErr)
+	}
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// wait waits for an exponential delay with full jitter after the failed attempt, or until ctx is done
+func (p RetryPolicy) wait(ctx context.Context, attempt int) error {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay > 0 {
+		delay = time.Duration(rand.Int63n(int64(delay)) + 1)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 // Get sends a REST API request and unmarshals response into obj, if not nil.
-func (c *SplunkClient) Get(path string, obj interface{}) error {
+func (c *SplunkClient) Get(ctx context.Context, path string, obj interface{}) error {
 	endpoint := fmt.Sprintf("%s%s?count=0&output_mode=json", c.ManagementURI, path)
 	request, err := http.NewRequest("GET", endpoint, nil)
 	if err != nil {
 		return err
 	}
 	expectedStatus := []int{200}
-	return c.Do(request, expectedStatus, obj)
+	return c.Do(ctx, request, expectedStatus, obj)
 }
 
 // SearchHeadCaptainInfo represents the status of the search head cluster.
@@ -144,14 +274,14 @@ type SearchHeadCaptainInfo struct {
 // GetSearchHeadCaptainInfo queries the captain for info about the search head cluster.
 // You can use this on any member of a search head cluster.
 // See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#shcluster.2Fcaptain.2Finfo
-func (c *SplunkClient) GetSearchHeadCaptainInfo() (*SearchHeadCaptainInfo, error) {
+func (c *SplunkClient) GetSearchHeadCaptainInfo(ctx context.Context) (*SearchHeadCaptainInfo, error) {
 	apiResponse := struct {
 		Entry []struct {
 			Content SearchHeadCaptainInfo `json:"content"`
 		} `json:"entry"`
 	}{}
 	path := "/services/shcluster/captain/info"
-	err := c.Get(path, &apiResponse)
+	err := c.Get(ctx, path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -213,14 +343,14 @@ type SearchHeadCaptainMemberInfo struct {
 // GetSearchHeadCaptainMembers queries the search head captain for info about cluster members.
 // You can only use this on a search head cluster captain.
 // See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#shcluster.2Fcaptain.2Fmembers
-func (c *SplunkClient) GetSearchHeadCaptainMembers() (map[string]SearchHeadCaptainMemberInfo, error) {
+func (c *SplunkClient) GetSearchHeadCaptainMembers(ctx context.Context) (map[string]SearchHeadCaptainMemberInfo, error) {
 	apiResponse := struct {
 		Entry []struct {
 			Content SearchHeadCaptainMemberInfo `json:"content"`
 		} `json:"entry"`
 	}{}
 	path := "/services/shcluster/captain/members"
-	err := c.Get(path, &apiResponse)
+	err := c.Get(ctx, path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -270,14 +400,14 @@ type SearchHeadClusterMemberInfo struct {
 // GetSearchHeadClusterMemberInfo queries info from a search head cluster member.
 // You can use this on any member of a search head cluster.
 // See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#shcluster.2Fmember.2Finfo
-func (c *SplunkClient) GetSearchHeadClusterMemberInfo() (*SearchHeadClusterMemberInfo, error) {
+func (c *SplunkClient) GetSearchHeadClusterMemberInfo(ctx context.Context) (*SearchHeadClusterMemberInfo, error) {
 	apiResponse := struct {
 		Entry []struct {
 			Content SearchHeadClusterMemberInfo `json:"content"`
 		} `json:"entry"`
 	}{}
 	path := "/services/shcluster/member/info"
-	err := c.Get(path, &apiResponse)
+	err := c.Get(ctx, path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -287,10 +417,45 @@ func (c *SplunkClient) GetSearchHeadClusterMemberInfo() (*SearchHeadClusterMembe
 	return &apiResponse.Entry[0].Content, nil
 }
 
+// KVStoreStatusInfo represents the status of the KV store of a Splunk instance.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTkvstore#kvstore.2Fstatus
+type KVStoreStatusInfo struct {
+	// Status of the KV store, ex. ready, starting or failed.
+	Status string `json:"status"`
+
+	// Replication status of the KV store, ex. KV store captain, Non-captain KV store member or Recovering.
+	ReplicationStatus string `json:"replicationStatus"`
+
+	// Status of the last KV store backup or restore.
+	BackupRestoreStatus string `json:"backupRestoreStatus"`
+}
+
+// GetKVStoreStatus queries the status of the KV store of a Splunk instance.
+// You can use this on any member of a search head cluster.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTkvstore#kvstore.2Fstatus
+func (c *SplunkClient) GetKVStoreStatus(ctx context.Context) (*KVStoreStatusInfo, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Content struct {
+				Current KVStoreStatusInfo `json:"current"`
+			} `json:"content"`
+		} `json:"entry"`
+	}{}
+	path := "/services/kvstore/status"
+	err := c.Get(ctx, path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+	if len(apiResponse.Entry) < 1 {
+		return nil, fmt.Errorf("invalid response from %s%s", c.ManagementURI, path)
+	}
+	return &apiResponse.Entry[0].Content.Current, nil
+}
+
 // SetSearchHeadDetention enables or disables detention of a search head cluster member.
 // You can use this on any member of a search head cluster.
 // See https://docs.splunk.com/Documentation/Splunk/latest/DistSearch/SHdetention
-func (c *SplunkClient) SetSearchHeadDetention(detain bool) error {
+func (c *SplunkClient) SetSearchHeadDetention(ctx context.Context, detain bool) error {
 	mode := "off"
 	if detain {
 		mode = "on"
@@ -301,13 +466,13 @@ func (c *SplunkClient) SetSearchHeadDetention(detain bool) error {
 		return err
 	}
 	expectedStatus := []int{200}
-	return c.Do(request, expectedStatus, nil)
+	return c.Do(ctx, request, expectedStatus, nil)
 }
 
 // RemoveSearchHeadClusterMember removes a search head cluster member.
 // You can use this on any member of a search head cluster.
 // See https://docs.splunk.com/Documentation/Splunk/latest/DistSearch/Removeaclustermember
-func (c *SplunkClient) RemoveSearchHeadClusterMember() error {
+func (c *SplunkClient) RemoveSearchHeadClusterMember(ctx context.Context) error {
 	// sent request to remove from search head cluster consensus
 	endpoint := fmt.Sprintf("%s/services/shcluster/member/consensus/default/remove_server?output_mode=json", c.ManagementURI)
 	request, err := http.NewRequest("POST", endpoint, nil)
@@ -404,14 +569,60 @@ type ClusterManagerInfo struct {
 // GetClusterManagerInfo queries the cluster manager for info about the indexer cluster.
 // You can only use this on a cluster manager.
 // See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#cluster.2Fmanager.2Finfo
-func (c *SplunkClient) GetClusterManagerInfo() (*ClusterManagerInfo, error) {
+func (c *SplunkClient) GetClusterManagerInfo(ctx context.Context) (*ClusterManagerInfo, error) {
 	apiResponse := struct {
 		Entry []struct {
 			Content ClusterManagerInfo `json:"content"`
 		} `json:"entry"`
 	}{}
 	path := "/services/cluster/manager/info"
-	err := c.Get(path, &apiResponse)
+	err := c.Get(ctx, path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+	if len(apiResponse.Entry) < 1 {
+		return nil, fmt.Errorf("invalid response from %s%s", c.ManagementURI, path)
+	}
+	return &apiResponse.Entry[0].Content, nil
+}
+
+// ClusterManagerHealth represents the health checks of an indexer cluster run by its manager, which are "1" when
+// they pass and "0" otherwise.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#cluster.2Fmanager.2Fhealth
+type ClusterManagerHealth struct {
+	// Indicates if all the data of the cluster is searchable.
+	AllDataIsSearchable string `json:"all_data_is_searchable"`
+
+	// Indicates if all the peers are up.
+	AllPeersAreUp string `json:"all_peers_are_up"`
+
+	// Indicates if no bucket fixup task is in progress.
+	NoFixupTasksInProgress string `json:"no_fixup_tasks_in_progress"`
+
+	// Indicates if the replication factor is met.
+	ReplicationFactorMet string `json:"replication_factor_met"`
+
+	// Indicates if the search factor is met.
+	SearchFactorMet string `json:"search_factor_met"`
+
+	// Indicates if the site replication factor of a multisite cluster is met.
+	SiteReplicationFactorMet string `json:"site_replication_factor_met,omitempty"`
+
+	// Indicates if the site search factor of a multisite cluster is met.
+	SiteSearchFactorMet string `json:"site_search_factor_met,omitempty"`
+}
+
+// GetClusterManagerHealth queries the cluster manager for the health of the indexer cluster.
+// You can only use this on a cluster manager.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#cluster.2Fmanager.2Fhealth
+func (c *SplunkClient) GetClusterManagerHealth(ctx context.Context) (*ClusterManagerHealth, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Content ClusterManagerHealth `json:"content"`
+		} `json:"entry"`
+	}{}
+	path := "/services/cluster/manager/health"
+	err := c.Get(ctx, path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -451,14 +662,14 @@ type IndexerClusterPeerInfo struct {
 // GetIndexerClusterPeerInfo queries info from a indexer cluster peer.
 // You can use this on any peer in an indexer cluster.
 // See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#cluster.2Fpeer.2Finfo
-func (c *SplunkClient) GetIndexerClusterPeerInfo() (*IndexerClusterPeerInfo, error) {
+func (c *SplunkClient) GetIndexerClusterPeerInfo(ctx context.Context) (*IndexerClusterPeerInfo, error) {
 	apiResponse := struct {
 		Entry []struct {
 			Content IndexerClusterPeerInfo `json:"content"`
 		} `json:"entry"`
 	}{}
 	path := "/services/cluster/peer/info"
-	err := c.Get(path, &apiResponse)
+	err := c.Get(ctx, path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -573,7 +784,7 @@ type ClusterManagerPeerInfo struct {
 // GetClusterManagerPeers queries the cluster manager for info about indexer cluster peers.
 // You can only use this on a cluster manager.
 // See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#cluster.2Fmanager.2Fpeers
-func (c *SplunkClient) GetClusterManagerPeers() (map[string]ClusterManagerPeerInfo, error) {
+func (c *SplunkClient) GetClusterManagerPeers(ctx context.Context) (map[string]ClusterManagerPeerInfo, error) {
 	apiResponse := struct {
 		Entry []struct {
 			Name    string                 `json:"name"`
@@ -581,7 +792,7 @@ func (c *SplunkClient) GetClusterManagerPeers() (map[string]ClusterManagerPeerIn
 		} `json:"entry"`
 	}{}
 	path := "/services/cluster/manager/peers"
-	err := c.Get(path, &apiResponse)
+	err := c.Get(ctx, path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -598,7 +809,7 @@ func (c *SplunkClient) GetClusterManagerPeers() (map[string]ClusterManagerPeerIn
 // RemoveIndexerClusterPeer removes peer from an indexer cluster, where id=unique GUID for the peer.
 // You can only use this on a cluster manager.
 // See https://docs.splunk.com/Documentation/Splunk/latest/Indexer/Removepeerfrommanagerlist
-func (c *SplunkClient) RemoveIndexerClusterPeer(id string) error {
+func (c *SplunkClient) RemoveIndexerClusterPeer(ctx context.Context, id string) error {
 	// sent request to remove a peer from Cluster Manager peers list
 	endpoint := fmt.Sprintf("%s%s?peers=%s", c.ManagementURI, "/services/cluster/manager/control/control/remove_peers", id)
 	request, err := http.NewRequest("POST", endpoint, nil)
@@ -606,13 +817,13 @@ func (c *SplunkClient) RemoveIndexerClusterPeer(id string) error {
 		return err
 	}
 	expectedStatus := []int{200}
-	return c.Do(request, expectedStatus, nil)
+	return c.Do(ctx, request, expectedStatus, nil)
 }
 
 // DecommissionIndexerClusterPeer takes an indexer cluster peer offline using the decommission endpoint.
 // You can use this on any peer in an indexer cluster.
 // See https://docs.splunk.com/Documentation/Splunk/latest/Indexer/Takeapeeroffline
-func (c *SplunkClient) DecommissionIndexerClusterPeer(enforceCounts bool) error {
+func (c *SplunkClient) DecommissionIndexerClusterPeer(ctx context.Context, enforceCounts bool) error {
 	enforceCountsAsInt := 0
 	if enforceCounts {
 		enforceCountsAsInt = 1
@@ -623,11 +834,11 @@ func (c *SplunkClient) DecommissionIndexerClusterPeer(enforceCounts bool) error
 		return err
 	}
 	expectedStatus := []int{200}
-	return c.Do(request, expectedStatus, nil)
+	return c.Do(ctx, request, expectedStatus, nil)
 }
 
 // BundlePush pushes the Cluster manager apps bundle to all the indexer peers
-func (c *SplunkClient) BundlePush(ignoreIdenticalBundle bool) error {
+func (c *SplunkClient) BundlePush(ctx context.Context, ignoreIdenticalBundle bool) error {
 	endpoint := fmt.Sprintf("%s%s", c.ManagementURI, "/services/cluster/manager/control/default/apply")
 	reqBody := fmt.Sprintf("&ignore_identical_bundle=%t", ignoreIdenticalBundle)
 
@@ -637,7 +848,7 @@ func (c *SplunkClient) BundlePush(ignoreIdenticalBundle bool) error {
 	}
 	expectedStatus := []int{200}
 
-	return c.Do(request, expectedStatus, nil)
+	return c.Do(ctx, request, expectedStatus, nil)
 }
 
 // MCServerRolesInfo is the struct for the server roles of the localhost, in this case SplunkMonitoringConsole
@@ -649,12 +860,13 @@ type MCServerRolesInfo struct {
 type MCDistributedPeers struct {
 	ClusterLabel []string `json:"cluster_label"`
 	ServerRoles  []string `json:"server_roles"`
+	Status       string   `json:"status"`
 }
 
 // AutomateMCApplyChanges change the state of new indexers from "New" to "Configured" and add them in monitoring console asset table
-func (c *SplunkClient) AutomateMCApplyChanges() error {
+func (c *SplunkClient) AutomateMCApplyChanges(ctx context.Context) error {
 	var configuredPeers, indexerMemberList, licenseManagerMemberList string
-	apiResponseServerRoles, err := c.GetMonitoringconsoleServerRoles()
+	apiResponseServerRoles, err := c.GetMonitoringconsoleServerRoles(ctx)
 	if err != nil {
 		return err
 	}
@@ -666,7 +878,7 @@ func (c *SplunkClient) AutomateMCApplyChanges() error {
 		} `json:"entry"`
 	}{}
 	path := "/services/search/distributed/peers"
-	err = c.Get(path, &apiResponseMCDistributedPeers)
+	err = c.Get(ctx, path, &apiResponseMCDistributedPeers)
 	if err != nil {
 		return err
 	}
@@ -698,11 +910,11 @@ func (c *SplunkClient) AutomateMCApplyChanges() error {
 	}
 	reqBodyIndexer := indexerMemberList + "&default=true"
 	reqBodyLicenseManager := licenseManagerMemberList + "&default=false"
-	err = c.UpdateDMCGroups("dmc_group_indexer", reqBodyIndexer)
+	err = c.UpdateDMCGroups(ctx, "dmc_group_indexer", reqBodyIndexer)
 	if err != nil {
 		return err
 	}
-	err = c.UpdateDMCGroups(splcommon.LicenseManagerDMCGroup, reqBodyLicenseManager)
+	err = c.UpdateDMCGroups(ctx, splcommon.LicenseManagerDMCGroup, reqBodyLicenseManager)
 	if err != nil {
 		return err
 	}
@@ -729,43 +941,43 @@ func (c *SplunkClient) AutomateMCApplyChanges() error {
 
 	for key, value := range clusterRoleDictToDictString {
 		if key == "" {
-			break
+			continue
 		} else {
-			err = c.UpdateDMCClusteringLabelGroup(key, value)
+			err = c.UpdateDMCClusteringLabelGroup(ctx, key, value)
 			if err != nil {
 				return err
 			}
 		}
 	}
-	apiResponseMCAssetTableBuild, err := c.GetMonitoringconsoleAssetTable()
+	apiResponseMCAssetTableBuild, err := c.GetMonitoringconsoleAssetTable(ctx)
 	if err != nil {
 		return err
 	}
-	err = c.PostMonitoringConsoleAssetTable(apiResponseMCAssetTableBuild)
+	err = c.PostMonitoringConsoleAssetTable(ctx, apiResponseMCAssetTableBuild)
 	if err != nil {
 		return err
 	}
-	UISettingsObject, err := c.GetMonitoringConsoleUISettings()
+	UISettingsObject, err := c.GetMonitoringConsoleUISettings(ctx)
 	if err != nil {
 		return err
 	}
-	err = c.UpdateLookupUISettings(configuredPeers, UISettingsObject)
+	err = c.UpdateLookupUISettings(ctx, configuredPeers, UISettingsObject)
 	if err != nil {
 		return err
 	}
-	err = c.UpdateMonitoringConsoleApp()
+	err = c.UpdateMonitoringConsoleApp(ctx)
 	return err
 }
 
 // GetMonitoringconsoleServerRoles to retrive server roles of the local host or SplunkMonitoringConsole
-func (c *SplunkClient) GetMonitoringconsoleServerRoles() (*MCServerRolesInfo, error) {
+func (c *SplunkClient) GetMonitoringconsoleServerRoles(ctx context.Context) (*MCServerRolesInfo, error) {
 	apiResponseServerRoles := struct {
 		Entry []struct {
 			Content MCServerRolesInfo `json:"content"`
 		} `json:"entry"`
 	}{}
 	path := "/services/server/info/server-info"
-	err := c.Get(path, &apiResponseServerRoles)
+	err := c.Get(ctx, path, &apiResponseServerRoles)
 	if err != nil {
 		return nil, err
 	}
@@ -776,24 +988,110 @@ func (c *SplunkClient) GetMonitoringconsoleServerRoles() (*MCServerRolesInfo, er
 }
 
 // UpdateDMCGroups dmc* groups with new members
-func (c *SplunkClient) UpdateDMCGroups(dmcGroupName string, groupMembers string) error {
+func (c *SplunkClient) UpdateDMCGroups(ctx context.Context, dmcGroupName string, groupMembers string) error {
 	endpoint := fmt.Sprintf("%s/services/search/distributed/groups/%s/edit", c.ManagementURI, dmcGroupName)
 	request, _ := http.NewRequest("POST", endpoint, strings.NewReader(groupMembers))
 	expectedStatus := []int{200, 201, 409}
-	err := c.Do(request, expectedStatus, nil)
+	err := c.Do(ctx, request, expectedStatus, nil)
 	return err
 }
 
 // UpdateDMCClusteringLabelGroup update respective clustering group
-func (c *SplunkClient) UpdateDMCClusteringLabelGroup(groupName string, groupMembers string) error {
+func (c *SplunkClient) UpdateDMCClusteringLabelGroup(ctx context.Context, groupName string, groupMembers string) error {
 	endpoint := fmt.Sprintf("%s/services/search/distributed/groups/dmc_indexerclustergroup_%s/edit", c.ManagementURI, groupName)
 	reqBodyClusterGroup := groupMembers + "&default=false"
 	request, _ := http.NewRequest("POST", endpoint, strings.NewReader(reqBodyClusterGroup))
 	expectedStatus := []int{200, 201, 409}
-	err := c.Do(request, expectedStatus, nil)
+	err := c.Do(ctx, request, expectedStatus, nil)
 	return err
 }
 
+// GetMonitoringConsoleDistributedPeers returns the distributed peers of the monitoring console, keyed by peer name (host:port)
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTsearch#search.2Fdistributed.2Fpeers
+func (c *SplunkClient) GetMonitoringConsoleDistributedPeers(ctx context.Context) (map[string]MCDistributedPeers, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Name    string             `json:"name"`
+			Content MCDistributedPeers `json:"content"`
+		} `json:"entry"`
+	}{}
+	path := "/services/search/distributed/peers"
+	err := c.Get(ctx, path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make(map[string]MCDistributedPeers)
+	for _, e := range apiResponse.Entry {
+		peers[e.Name] = e.Content
+	}
+	return peers, nil
+}
+
+// AddMonitoringConsoleDistributedPeer adds a search peer to the monitoring console
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTsearch#search.2Fdistributed.2Fpeers
+func (c *SplunkClient) AddMonitoringConsoleDistributedPeer(ctx context.Context, peer, username, password string) error {
+	endpoint := fmt.Sprintf("%s/services/search/distributed/peers", c.ManagementURI)
+	reqBody := url.Values{
+		"name":           {peer},
+		"remoteUsername": {username},
+		"remotePassword": {password},
+	}
+	request, err := http.NewRequest("POST", endpoint, strings.NewReader(reqBody.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	expectedStatus := []int{200, 201}
+	return c.Do(ctx, request, expectedStatus, nil)
+}
+
+// RemoveMonitoringConsoleDistributedPeer removes a search peer from the monitoring console
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTsearch#search.2Fdistributed.2Fpeers.2F.7Bname.7D
+func (c *SplunkClient) RemoveMonitoringConsoleDistributedPeer(ctx context.Context, peer string) error {
+	endpoint := fmt.Sprintf("%s/services/search/distributed/peers/%s", c.ManagementURI, url.PathEscape(peer))
+	request, err := http.NewRequest("DELETE", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	// peer is already gone
+	expectedStatus := []int{200, 404}
+	return c.Do(ctx, request, expectedStatus, nil)
+}
+
+// GetDMCClusteringLabelGroups returns the cluster labels having a dmc_indexerclustergroup_* group on the monitoring console
+func (c *SplunkClient) GetDMCClusteringLabelGroups(ctx context.Context) ([]string, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Name string `json:"name"`
+		} `json:"entry"`
+	}{}
+	path := "/services/search/distributed/groups"
+	err := c.Get(ctx, path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	for _, e := range apiResponse.Entry {
+		if strings.HasPrefix(e.Name, "dmc_indexerclustergroup_") {
+			labels = append(labels, strings.TrimPrefix(e.Name, "dmc_indexerclustergroup_"))
+		}
+	}
+	return labels, nil
+}
+
+// DeleteDMCClusteringLabelGroup deletes the clustering group of a cluster label no longer used by any peer
+func (c *SplunkClient) DeleteDMCClusteringLabelGroup(ctx context.Context, groupName string) error {
+	endpoint := fmt.Sprintf("%s/services/search/distributed/groups/dmc_indexerclustergroup_%s", c.ManagementURI, url.PathEscape(groupName))
+	request, err := http.NewRequest("DELETE", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	expectedStatus := []int{200, 404}
+	return c.Do(ctx, request, expectedStatus, nil)
+}
+
 // MCAssetBuildTable is the struct for information about asset table
 type MCAssetBuildTable struct {
 	DispatchAutoCancel string `json:"dispatch.auto_cancel"`
@@ -801,14 +1099,14 @@ type MCAssetBuildTable struct {
 }
 
 // GetMonitoringconsoleAssetTable to GET monitoring console asset table data.
-func (c *SplunkClient) GetMonitoringconsoleAssetTable() (*MCAssetBuildTable, error) {
+func (c *SplunkClient) GetMonitoringconsoleAssetTable(ctx context.Context) (*MCAssetBuildTable, error) {
 	apiResponseMCAssetTableBuild := struct {
 		Entry []struct {
 			Content MCAssetBuildTable `json:"content"`
 		} `json:"entry"`
 	}{}
 	path := "/servicesNS/nobody/splunk_monitoring_console/saved/searches/DMC%20Asset%20-%20Build%20Full"
-	err := c.Get(path, &apiResponseMCAssetTableBuild)
+	err := c.Get(ctx, path, &apiResponseMCAssetTableBuild)
 	if err != nil {
 		return nil, err
 	}
@@ -819,13 +1117,13 @@ func (c *SplunkClient) GetMonitoringconsoleAssetTable() (*MCAssetBuildTable, err
 }
 
 // PostMonitoringConsoleAssetTable to build monitoring console asset table. Kicks off the search [Build Asset Table full]
-func (c *SplunkClient) PostMonitoringConsoleAssetTable(apiResponseMCAssetTableBuild *MCAssetBuildTable) error {
+func (c *SplunkClient) PostMonitoringConsoleAssetTable(ctx context.Context, apiResponseMCAssetTableBuild *MCAssetBuildTable) error {
 	reqBodyAssetTable := "&trigger_actions=true&dispatch.auto_cancel=" + apiResponseMCAssetTableBuild.DispatchAutoCancel + "&dispatch.buckets=" + strconv.FormatInt(apiResponseMCAssetTableBuild.DispatchBuckets, 10) + "&dispatch.enablePreview=true"
 	endpoint := c.ManagementURI + "/servicesNS/nobody/splunk_monitoring_console/saved/searches/DMC%20Asset%20-%20Build%20Full/dispatch"
 	request, _ := http.NewRequest("POST", endpoint, strings.NewReader(reqBodyAssetTable))
 	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	expectedStatus := []int{200, 201, 409}
-	err := c.Do(request, expectedStatus, nil)
+	err := c.Do(ctx, request, expectedStatus, nil)
 	return err
 }
 
@@ -839,14 +1137,14 @@ type UISettings struct {
 }
 
 // GetMonitoringConsoleUISettings do a Get for app UI settings
-func (c *SplunkClient) GetMonitoringConsoleUISettings() (*UISettings, error) {
+func (c *SplunkClient) GetMonitoringConsoleUISettings(ctx context.Context) (*UISettings, error) {
 	apiResponseUISettings := struct {
 		Entry []struct {
 			Content UISettings `json:"content"`
 		} `json:"entry"`
 	}{}
 	path := "/servicesNS/nobody/splunk_monitoring_console/data/ui/nav/default.distributed"
-	err := c.Get(path, &apiResponseUISettings)
+	err := c.Get(ctx, path, &apiResponseUISettings)
 	if err != nil {
 		return nil, err
 	}
@@ -857,25 +1155,25 @@ func (c *SplunkClient) GetMonitoringConsoleUISettings() (*UISettings, error) {
 }
 
 // UpdateLookupUISettings updates assets.csv
-func (c *SplunkClient) UpdateLookupUISettings(configuredPeers string, apiResponseUISettings *UISettings) error {
+func (c *SplunkClient) UpdateLookupUISettings(ctx context.Context, configuredPeers string, apiResponseUISettings *UISettings) error {
 	reqBodyMCLookups := "configuredPeers=" + configuredPeers + "&eai:appName=" + apiResponseUISettings.EaiAppName + "&eai:acl=" + apiResponseUISettings.EaiACL + "&eai:userName=" + apiResponseUISettings.EaiUserName + "&disabled=" + strconv.FormatBool(apiResponseUISettings.Disabled)
 	endpoint := fmt.Sprintf("%s/servicesNS/nobody/splunk_monitoring_console/configs/conf-splunk_monitoring_console_assets/settings", c.ManagementURI)
 	request, _ := http.NewRequest("POST", endpoint, strings.NewReader(reqBodyMCLookups))
 	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	expectedStatus := []int{200, 201, 409}
-	err := c.Do(request, expectedStatus, nil)
+	err := c.Do(ctx, request, expectedStatus, nil)
 	return err
 }
 
 // UpdateMonitoringConsoleApp updates the monitoring console app
-func (c *SplunkClient) UpdateMonitoringConsoleApp() error {
+func (c *SplunkClient) UpdateMonitoringConsoleApp(ctx context.Context) error {
 	endpoint := fmt.Sprintf("%s/servicesNS/nobody/system/apps/local/splunk_monitoring_console", c.ManagementURI)
 	request, err := http.NewRequest("POST", endpoint, nil)
 	if err != nil {
 		return err
 	}
 	expectedStatus := []int{200, 201}
-	err = c.Do(request, expectedStatus, nil)
+	err = c.Do(ctx, request, expectedStatus, nil)
 	return err
 }
 
@@ -888,7 +1186,7 @@ type ClusterInfo struct {
 
 // GetClusterInfo queries the cluster about multi-site or single-site.
 // See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#cluster.2Fconfig
-func (c *SplunkClient) GetClusterInfo(mockCall bool) (*ClusterInfo, error) {
+func (c *SplunkClient) GetClusterInfo(ctx context.Context, mockCall bool) (*ClusterInfo, error) {
 	if mockCall {
 		return nil, nil
 	}
@@ -898,7 +1196,7 @@ func (c *SplunkClient) GetClusterInfo(mockCall bool) (*ClusterInfo, error) {
 		} `json:"entry"`
 	}{}
 	path := "/services/cluster/config"
-	err := c.Get(path, &apiResponse)
+	err := c.Get(ctx, path, &apiResponse)
 	if err != nil {
 		return nil, err
 	}
@@ -908,10 +1206,62 @@ func (c *SplunkClient) GetClusterInfo(mockCall bool) (*ClusterInfo, error) {
 	return &apiResponse.Entry[0].Content, nil
 }
 
+// DeploymentClientInfo represents a deployment client that phoned home to a deployment server.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTdeploy#deployment.2Fserver.2Fclients
+type DeploymentClientInfo struct {
+	// Unique identifier or GUID for the deployment client
+	ID string `json:"guid"`
+
+	// Name of the deployment client
+	ClientName string `json:"clientName"`
+
+	// Host name of the deployment client
+	Hostname string `json:"hostname"`
+
+	// IP address of the deployment client
+	IP string `json:"ip"`
+
+	// DNS name of the deployment client
+	DNS string `json:"dns"`
+
+	// Machine type of the deployment client, ex. linux-x86_64
+	MachineType string `json:"utsname"`
+
+	// Timestamp of the last phone home of the deployment client
+	LastPhoneHomeTime int64 `json:"lastPhoneHomeTime"`
+}
+
+// GetDeploymentServerClients queries the deployment server for the deployment clients that phoned home.
+// You can only use this on a deployment server.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTdeploy#deployment.2Fserver.2Fclients
+func (c *SplunkClient) GetDeploymentServerClients(ctx context.Context) (map[string]DeploymentClientInfo, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Name    string               `json:"name"`
+			Content DeploymentClientInfo `json:"content"`
+		} `json:"entry"`
+	}{}
+	path := "/services/deployment/server/clients"
+	err := c.Get(ctx, path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	clients := make(map[string]DeploymentClientInfo)
+	for _, e := range apiResponse.Entry {
+		if e.Content.ID == "" {
+			e.Content.ID = e.Name
+		}
+		clients[e.Content.ID] = e.Content
+	}
+
+	return clients, nil
+}
+
 // SetIdxcSecret sets idxc_secret for a Splunk Instance
 // Can be used on any peer in an indexer cluster as long as the idxc_secret matches the cluster manager
 // See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTcluster#cluster.2Fconfig.2Fconfig
-func (c *SplunkClient) SetIdxcSecret(idxcSecret string) error {
+func (c *SplunkClient) SetIdxcSecret(ctx context.Context, idxcSecret string) error {
 	endpoint := fmt.Sprintf("%s/services/cluster/config/config?secret=%s", c.ManagementURI, idxcSecret)
 	request, err := http.NewRequest("POST", endpoint, nil)
 	if err != nil {
@@ -919,18 +1269,284 @@ func (c *SplunkClient) SetIdxcSecret(idxcSecret string) error {
 	}
 	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	expectedStatus := []int{200}
-	return c.Do(request, expectedStatus, nil)
+	return c.Do(ctx, request, expectedStatus, nil)
 }
 
 // RestartSplunk restarts specific Splunk instance
 // Can be used for any Splunk Instance
 // See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTsystem#server.2Fcontrol.2Frestart
-func (c *SplunkClient) RestartSplunk() error {
+func (c *SplunkClient) RestartSplunk(ctx context.Context) error {
 	endpoint := fmt.Sprintf("%s/services/server/control/restart", c.ManagementURI)
 	request, err := http.NewRequest("POST", endpoint, nil)
 	if err != nil {
 		return err
 	}
 	expectedStatus := []int{200}
-	return c.Do(request, expectedStatus, nil)
+	return c.Do(ctx, request, expectedStatus, nil)
+}
+
+// LicenseInfo represents a license installed on a license manager.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTlicense#licenser.2Flicenses
+type LicenseInfo struct {
+	// Unique identifier or GUID of the license
+	GUID string `json:"guid"`
+
+	// Label of the license
+	Label string `json:"label"`
+
+	// License stack the license belongs to
+	StackID string `json:"stack_id"`
+
+	// Type of the license, ex. enterprise
+	Type string `json:"type"`
+
+	// Status of the license, ex. VALID or EXPIRED
+	Status string `json:"status"`
+
+	// Daily indexing quota of the license in bytes
+	Quota int64 `json:"quota"`
+
+	// Expiration time of the license in epoch seconds
+	ExpirationTime int64 `json:"expiration_time"`
+}
+
+// GetLicenserLicenses queries the license manager for the licenses installed on it.
+// You can only use this on a license manager.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTlicense#licenser.2Flicenses
+func (c *SplunkClient) GetLicenserLicenses(ctx context.Context) (map[string]LicenseInfo, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Name    string      `json:"name"`
+			Content LicenseInfo `json:"content"`
+		} `json:"entry"`
+	}{}
+	path := "/services/licenser/licenses"
+	err := c.Get(ctx, path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	licenses := make(map[string]LicenseInfo)
+	for _, e := range apiResponse.Entry {
+		if e.Content.GUID == "" {
+			e.Content.GUID = e.Name
+		}
+		licenses[e.Content.GUID] = e.Content
+	}
+
+	return licenses, nil
+}
+
+// LicenseStackInfo represents a license stack of a license manager.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTlicense#licenser.2Fstacks
+type LicenseStackInfo struct {
+	// Label of the license stack
+	Label string `json:"label"`
+
+	// Daily indexing quota of the license stack in bytes
+	Quota int64 `json:"quota"`
+
+	// Type of the license stack, ex. enterprise
+	Type string `json:"type"`
+}
+
+// GetLicenserStacks queries the license manager for its license stacks, keyed by stack id.
+// You can only use this on a license manager.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTlicense#licenser.2Fstacks
+func (c *SplunkClient) GetLicenserStacks(ctx context.Context) (map[string]LicenseStackInfo, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Name    string           `json:"name"`
+			Content LicenseStackInfo `json:"content"`
+		} `json:"entry"`
+	}{}
+	path := "/services/licenser/stacks"
+	err := c.Get(ctx, path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	stacks := make(map[string]LicenseStackInfo)
+	for _, e := range apiResponse.Entry {
+		stacks[e.Name] = e.Content
+	}
+
+	return stacks, nil
+}
+
+// LicensePoolInfo represents a license pool of a license manager.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTlicense#licenser.2Fpools
+type LicensePoolInfo struct {
+	// Description of the license pool
+	Description string `json:"description"`
+
+	// License stack the pool draws from
+	StackID string `json:"stack_id"`
+
+	// Daily indexing quota of the pool in bytes, resolved from MAX if needed
+	EffectiveQuota int64 `json:"effective_quota"`
+
+	// Bytes indexed today against the pool
+	UsedBytes int64 `json:"used_bytes"`
+
+	// GUIDs of the license peers assigned to the pool
+	Peers []string `json:"peers"`
+}
+
+// GetLicenserPools queries the license manager for its license pools, keyed by pool name.
+// You can only use this on a license manager.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTlicense#licenser.2Fpools
+func (c *SplunkClient) GetLicenserPools(ctx context.Context) (map[string]LicensePoolInfo, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Name    string          `json:"name"`
+			Content LicensePoolInfo `json:"content"`
+		} `json:"entry"`
+	}{}
+	path := "/services/licenser/pools"
+	err := c.Get(ctx, path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	pools := make(map[string]LicensePoolInfo)
+	for _, e := range apiResponse.Entry {
+		pools[e.Name] = e.Content
+	}
+
+	return pools, nil
+}
+
+// CreateLicenserPool creates a license pool on the license manager.
+// quota is either MAX or a number of bytes, peers is a list of license peer GUIDs.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTlicense#licenser.2Fpools
+func (c *SplunkClient) CreateLicenserPool(ctx context.Context, name, stackID, quota, description string, peers []string) error {
+	endpoint := fmt.Sprintf("%s/services/licenser/pools", c.ManagementURI)
+	reqBody := url.Values{
+		"name":        {name},
+		"stack_id":    {stackID},
+		"quota":       {quota},
+		"description": {description},
+	}
+	if len(peers) > 0 {
+		reqBody.Set("peers", strings.Join(peers, ","))
+	}
+	request, err := http.NewRequest("POST", endpoint, strings.NewReader(reqBody.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	expectedStatus := []int{200, 201}
+	return c.Do(ctx, request, expectedStatus, nil)
+}
+
+// UpdateLicenserPool updates the quota, description and peers of a license pool on the license manager.
+// The peers of the pool are replaced with the given list of license peer GUIDs.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTlicense#licenser.2Fpools.2F.7Bname.7D
+func (c *SplunkClient) UpdateLicenserPool(ctx context.Context, name, quota, description string, peers []string) error {
+	endpoint := fmt.Sprintf("%s/services/licenser/pools/%s", c.ManagementURI, url.PathEscape(name))
+	reqBody := url.Values{
+		"quota":        {quota},
+		"description":  {description},
+		"append_peers": {"false"},
+		"peers":        {strings.Join(peers, ",")},
+	}
+	request, err := http.NewRequest("POST", endpoint, strings.NewReader(reqBody.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	expectedStatus := []int{200}
+	return c.Do(ctx, request, expectedStatus, nil)
+}
+
+// DeleteLicenserPool deletes a license pool from the license manager
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTlicense#licenser.2Fpools.2F.7Bname.7D
+func (c *SplunkClient) DeleteLicenserPool(ctx context.Context, name string) error {
+	endpoint := fmt.Sprintf("%s/services/licenser/pools/%s", c.ManagementURI, url.PathEscape(name))
+	request, err := http.NewRequest("DELETE", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	expectedStatus := []int{200, 404}
+	return c.Do(ctx, request, expectedStatus, nil)
+}
+
+// LicensePeerInfo represents a license peer reporting to a license manager.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTlicense#licenser.2Fpeers
+type LicensePeerInfo struct {
+	// Server name of the license peer
+	Label string `json:"label"`
+
+	// Names of the pools the license peer is assigned to
+	ActivePoolIDs []string `json:"active_pool_ids"`
+}
+
+// GetLicenserPeers queries the license manager for its license peers, keyed by peer GUID.
+// You can only use this on a license manager.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTlicense#licenser.2Fpeers
+func (c *SplunkClient) GetLicenserPeers(ctx context.Context) (map[string]LicensePeerInfo, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Name    string          `json:"name"`
+			Content LicensePeerInfo `json:"content"`
+		} `json:"entry"`
+	}{}
+	path := "/services/licenser/peers"
+	err := c.Get(ctx, path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	peers := make(map[string]LicensePeerInfo)
+	for _, e := range apiResponse.Entry {
+		peers[e.Name] = e.Content
+	}
+
+	return peers, nil
+}
+
+// LicenseMessageInfo represents a license warning or violation message raised by a license manager.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTlicense#licenser.2Fmessages
+type LicenseMessageInfo struct {
+	// Category of the message, ex. license_window or pool_over_quota
+	Category string `json:"category"`
+
+	// Severity of the message, ex. WARN or ERROR
+	Severity string `json:"severity"`
+
+	// License stack the message was raised for
+	StackID string `json:"stack_id"`
+
+	// License pool the message was raised for
+	PoolID string `json:"pool_id"`
+
+	// Description of the message
+	Description string `json:"description"`
+
+	// Creation time of the message in epoch seconds
+	CreateTime int64 `json:"create_time"`
+}
+
+// GetLicenserMessages queries the license manager for its license warning and violation messages.
+// You can only use this on a license manager.
+// See https://docs.splunk.com/Documentation/Splunk/latest/RESTREF/RESTlicense#licenser.2Fmessages
+func (c *SplunkClient) GetLicenserMessages(ctx context.Context) ([]LicenseMessageInfo, error) {
+	apiResponse := struct {
+		Entry []struct {
+			Content LicenseMessageInfo `json:"content"`
+		} `json:"entry"`
+	}{}
+	path := "/services/licenser/messages"
+	err := c.Get(ctx, path, &apiResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := []LicenseMessageInfo{}
+	for _, e := range apiResponse.Entry {
+		messages = append(messages, e.Content)
+	}
+
+	return messages, nil
 }
@@ -16,36 +16,43 @@
 package client
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 
 	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
 )
 
-func splunkClientTester(t *testing.T, testMethod string, status int, body string, wantRequest *http.Request, test func(SplunkClient) error) {
+func splunkClientTester(t *testing.T, testMethod string, status int, body string, wantRequest *http.Request, test func(context.Context, SplunkClient) error) {
 	mockSplunkClient := &spltest.MockHTTPClient{}
 	mockSplunkClient.AddHandler(wantRequest, status, body, nil)
 	c := NewSplunkClient("https://localhost:8089", "admin", "p@ssw0rd")
 	c.Client = mockSplunkClient
-	err := test(*c)
+	c.RetryPolicy = RetryPolicy{} // each wanted request is sent once
+	err := test(context.TODO(), *c)
 	if err != nil {
 		t.Errorf("%s err = %v", testMethod, err)
 	}
 	mockSplunkClient.CheckRequests(t, testMethod)
 }
 
-func splunkClientMultipleRequestTester(t *testing.T, testMethod string, status []int, body []string, wantRequest []*http.Request, test func(SplunkClient) error) {
+func splunkClientMultipleRequestTester(t *testing.T, testMethod string, status []int, body []string, wantRequest []*http.Request, test func(context.Context, SplunkClient) error) {
 	mockSplunkClient := &spltest.MockHTTPClient{}
 	for i := 0; i < len(wantRequest); i++ {
 		mockSplunkClient.AddHandler(wantRequest[i], status[i], body[i], nil)
 	}
 	c := NewSplunkClient("https://localhost:8089", "admin", "p@ssw0rd")
 	c.Client = mockSplunkClient
-	err := test(*c)
+	c.RetryPolicy = RetryPolicy{} // each wanted request is sent once
+	err := test(context.TODO(), *c)
 	if err != nil {
 		t.Errorf("%s err = %v", testMethod, err)
 	}
@@ -55,8 +62,8 @@ func splunkClientMultipleRequestTester(t *testing.T, testMethod string, status [
 func TestGetSearchHeadCaptainInfo(t *testing.T) {
 	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/shcluster/captain/info?count=0&output_mode=json", nil)
 	wantCaptainLabel := "splunk-s2-search-head-0"
-	test := func(c SplunkClient) error {
-		captainInfo, err := c.GetSearchHeadCaptainInfo()
+	test := func(ctx context.Context, c SplunkClient) error {
+		captainInfo, err := c.GetSearchHeadCaptainInfo(ctx)
 		if err != nil {
 			return err
 		}
@@ -69,8 +76,8 @@ func TestGetSearchHeadCaptainInfo(t *testing.T) {
 	splunkClientTester(t, "TestGetSearchHeadCaptainInfo", 200, body, wantRequest, test)
 
 	// test body with no entries
-	test = func(c SplunkClient) error {
-		_, err := c.GetSearchHeadCaptainInfo()
+	test = func(ctx context.Context, c SplunkClient) error {
+		_, err := c.GetSearchHeadCaptainInfo(ctx)
 		if err == nil {
 			t.Errorf("GetSearchHeadCaptainInfo returned nil; want error")
 		}
@@ -89,8 +96,8 @@ func TestGetSearchHeadCaptainInfo(t *testing.T) {
 func TestGetSearchHeadClusterMemberInfo(t *testing.T) {
 	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/shcluster/member/info?count=0&output_mode=json", nil)
 	wantMemberStatus := "Up"
-	test := func(c SplunkClient) error {
-		memberInfo, err := c.GetSearchHeadClusterMemberInfo()
+	test := func(ctx context.Context, c SplunkClient) error {
+		memberInfo, err := c.GetSearchHeadClusterMemberInfo(ctx)
 		if err != nil {
 			return err
 		}
@@ -103,8 +110,8 @@ func TestGetSearchHeadClusterMemberInfo(t *testing.T) {
 	splunkClientTester(t, "TestGetSearchHeadClusterMemberInfo", 200, body, wantRequest, test)
 
 	// test body with no entries
-	test = func(c SplunkClient) error {
-		_, err := c.GetSearchHeadClusterMemberInfo()
+	test = func(ctx context.Context, c SplunkClient) error {
+		_, err := c.GetSearchHeadClusterMemberInfo(ctx)
 		if err == nil {
 			t.Errorf("GetSearchHeadClusterMemberInfo returned nil; want error")
 		}
@@ -127,8 +134,8 @@ func TestGetSearchHeadCaptainMembers(t *testing.T) {
 	}
 	wantStatus := "Up"
 	wantCaptain := "splunk-s2-search-head-0"
-	test := func(c SplunkClient) error {
-		members, err := c.GetSearchHeadCaptainMembers()
+	test := func(ctx context.Context, c SplunkClient) error {
+		members, err := c.GetSearchHeadCaptainMembers(ctx)
 		if err != nil {
 			return err
 		}
@@ -159,8 +166,8 @@ func TestGetSearchHeadCaptainMembers(t *testing.T) {
 	splunkClientTester(t, "TestGetSearchHeadCaptainMembers", 200, body, wantRequest, test)
 
 	// test error response
-	test = func(c SplunkClient) error {
-		_, err := c.GetSearchHeadCaptainMembers()
+	test = func(ctx context.Context, c SplunkClient) error {
+		_, err := c.GetSearchHeadCaptainMembers(ctx)
 		if err == nil {
 			t.Errorf("GetSearchHeadCaptainMembers returned nil; want error")
 		}
@@ -169,10 +176,40 @@ func TestGetSearchHeadCaptainMembers(t *testing.T) {
 	splunkClientTester(t, "TestGetSearchHeadCaptainMembers", 503, "", wantRequest, test)
 }
 
+func TestGetKVStoreStatus(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/kvstore/status?count=0&output_mode=json", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		status, err := c.GetKVStoreStatus(ctx)
+		if err != nil {
+			return err
+		}
+		if status.Status != "ready" || status.ReplicationStatus != "KV store captain" || status.BackupRestoreStatus != "Ready" {
+			t.Errorf("status=%v; want ready KV store captain", status)
+		}
+		return nil
+	}
+	body := `{"entry":[{"name":"status","content":{"current":{"backupRestoreStatus":"Ready","replicationStatus":"KV store captain","status":"ready"}}}]}`
+	splunkClientTester(t, "TestGetKVStoreStatus", 200, body, wantRequest, test)
+
+	// test body with no entries
+	test = func(ctx context.Context, c SplunkClient) error {
+		_, err := c.GetKVStoreStatus(ctx)
+		if err == nil {
+			t.Errorf("GetKVStoreStatus returned nil; want error")
+		}
+		return nil
+	}
+	body = `{"entry":[]}`
+	splunkClientTester(t, "TestGetKVStoreStatus", 200, body, wantRequest, test)
+
+	// test error code
+	splunkClientTester(t, "TestGetKVStoreStatus", 500, "", wantRequest, test)
+}
+
 func TestSetSearchHeadDetention(t *testing.T) {
 	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/shcluster/member/control/control/set_manual_detention?manual_detention=on", nil)
-	test := func(c SplunkClient) error {
-		return c.SetSearchHeadDetention(true)
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.SetSearchHeadDetention(ctx, true)
 	}
 	splunkClientTester(t, "TestSetSearchHeadDetention", 200, "", wantRequest, test)
 }
@@ -181,8 +218,8 @@ func TestBundlePush(t *testing.T) {
 	body := strings.NewReader("&ignore_identical_bundle=true")
 	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/cluster/manager/control/default/apply", body)
 
-	test := func(c SplunkClient) error {
-		return c.BundlePush(true)
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.BundlePush(ctx, true)
 	}
 	splunkClientTester(t, "TestBundlePush", 200, "", wantRequest, test)
 }
@@ -190,8 +227,8 @@ func TestBundlePush(t *testing.T) {
 func TestRemoveSearchHeadClusterMember(t *testing.T) {
 	// test for 200 response first (sent on first removal request)
 	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/shcluster/member/consensus/default/remove_server?output_mode=json", nil)
-	test := func(c SplunkClient) error {
-		return c.RemoveSearchHeadClusterMember()
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.RemoveSearchHeadClusterMember(ctx)
 	}
 	splunkClientTester(t, "TestRemoveSearchHeadClusterMember", 200, "", wantRequest, test)
 
@@ -204,8 +241,8 @@ func TestRemoveSearchHeadClusterMember(t *testing.T) {
 	splunkClientTester(t, "TestRemoveSearchHeadClusterMember", 503, body, wantRequest, test)
 
 	// test unrecognized response message
-	test = func(c SplunkClient) error {
-		err := c.RemoveSearchHeadClusterMember()
+	test = func(ctx context.Context, c SplunkClient) error {
+		err := c.RemoveSearchHeadClusterMember(ctx)
 		if err == nil {
 			t.Errorf("RemoveSearchHeadClusterMember returned nil; want error")
 		}
@@ -250,8 +287,8 @@ func TestGetclusterManagerInfo(t *testing.T) {
 		},
 		StartTime: 1583948636,
 	}
-	test := func(c SplunkClient) error {
-		gotInfo, err := c.GetClusterManagerInfo()
+	test := func(ctx context.Context, c SplunkClient) error {
+		gotInfo, err := c.GetClusterManagerInfo(ctx)
 		if err != nil {
 			return err
 		}
@@ -264,8 +301,8 @@ func TestGetclusterManagerInfo(t *testing.T) {
 	splunkClientTester(t, "TestGetclusterManagerInfo", 200, body, wantRequest, test)
 
 	// test body with no entries
-	test = func(c SplunkClient) error {
-		_, err := c.GetClusterManagerInfo()
+	test = func(ctx context.Context, c SplunkClient) error {
+		_, err := c.GetClusterManagerInfo(ctx)
 		if err == nil {
 			t.Errorf("GetClusterManagerInfo returned nil; want error")
 		}
@@ -278,11 +315,41 @@ func TestGetclusterManagerInfo(t *testing.T) {
 	splunkClientTester(t, "TestGetClusterManagerInfo", 500, "", wantRequest, test)
 }
 
+func TestGetClusterManagerHealth(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/cluster/manager/health?count=0&output_mode=json", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		health, err := c.GetClusterManagerHealth(ctx)
+		if err != nil {
+			return err
+		}
+		if health.ReplicationFactorMet != "1" || health.SearchFactorMet != "0" {
+			t.Errorf("health = %+v; want replication factor met and search factor not met", health)
+		}
+		return nil
+	}
+	body := `{"links":{},"origin":"https://localhost:8089/services/cluster/manager/health","entry":[{"name":"manager","content":{"all_data_is_searchable":"0","all_peers_are_up":"1","no_fixup_tasks_in_progress":"0","replication_factor_met":"1","search_factor_met":"0"}}]}`
+	splunkClientTester(t, "TestGetClusterManagerHealth", 200, body, wantRequest, test)
+
+	// test body with no entries
+	test = func(ctx context.Context, c SplunkClient) error {
+		_, err := c.GetClusterManagerHealth(ctx)
+		if err == nil {
+			t.Errorf("GetClusterManagerHealth returned nil; want error")
+		}
+		return nil
+	}
+	body = `{"links":{},"origin":"https://localhost:8089/services/cluster/manager/health","entry":[]}`
+	splunkClientTester(t, "TestGetClusterManagerHealth", 200, body, wantRequest, test)
+
+	// test error code
+	splunkClientTester(t, "TestGetClusterManagerHealth", 500, "", wantRequest, test)
+}
+
 func TestGetIndexerClusterPeerInfo(t *testing.T) {
 	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/cluster/peer/info?count=0&output_mode=json", nil)
 	wantMemberStatus := "Up"
-	test := func(c SplunkClient) error {
-		info, err := c.GetIndexerClusterPeerInfo()
+	test := func(ctx context.Context, c SplunkClient) error {
+		info, err := c.GetIndexerClusterPeerInfo(ctx)
 		if err != nil {
 			return err
 		}
@@ -295,8 +362,8 @@ func TestGetIndexerClusterPeerInfo(t *testing.T) {
 	splunkClientTester(t, "TestGetIndexerClusterPeerInfo", 200, body, wantRequest, test)
 
 	// test body with no entries
-	test = func(c SplunkClient) error {
-		_, err := c.GetIndexerClusterPeerInfo()
+	test = func(ctx context.Context, c SplunkClient) error {
+		_, err := c.GetIndexerClusterPeerInfo(ctx)
 		if err == nil {
 			t.Errorf("GetIndexerClusterPeerInfo returned nil; want error")
 		}
@@ -318,8 +385,8 @@ func TestGetClusterManagerPeers(t *testing.T) {
 	}{
 		{ID: "D39B1729-E2C5-4273-B9B2-534DA7C2F866", Label: "splunk-s1-indexer-0", Status: "Up"},
 	}
-	test := func(c SplunkClient) error {
-		peers, err := c.GetClusterManagerPeers()
+	test := func(ctx context.Context, c SplunkClient) error {
+		peers, err := c.GetClusterManagerPeers(ctx)
 		if err != nil {
 			return err
 		}
@@ -347,8 +414,8 @@ func TestGetClusterManagerPeers(t *testing.T) {
 	splunkClientTester(t, "TestGetClusterManagerPeers", 200, body, wantRequest, test)
 
 	// test error response
-	test = func(c SplunkClient) error {
-		_, err := c.GetClusterManagerPeers()
+	test = func(ctx context.Context, c SplunkClient) error {
+		_, err := c.GetClusterManagerPeers(ctx)
 		if err == nil {
 			t.Errorf("GetClusterManagerPeers returned nil; want error")
 		}
@@ -359,16 +426,16 @@ func TestGetClusterManagerPeers(t *testing.T) {
 
 func TestRemoveIndexerClusterPeer(t *testing.T) {
 	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/cluster/manager/control/control/remove_peers?peers=D39B1729-E2C5-4273-B9B2-534DA7C2F866", nil)
-	test := func(c SplunkClient) error {
-		return c.RemoveIndexerClusterPeer("D39B1729-E2C5-4273-B9B2-534DA7C2F866")
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.RemoveIndexerClusterPeer(ctx, "D39B1729-E2C5-4273-B9B2-534DA7C2F866")
 	}
 	splunkClientTester(t, "TestRemoveIndexerClusterPeer", 200, "", wantRequest, test)
 }
 
 func TestDecommissionIndexerClusterPeer(t *testing.T) {
 	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/cluster/peer/control/control/decommission?enforce_counts=1", nil)
-	test := func(c SplunkClient) error {
-		return c.DecommissionIndexerClusterPeer(true)
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.DecommissionIndexerClusterPeer(ctx, true)
 	}
 	splunkClientTester(t, "TestDecommissionIndexerClusterPeer", 200, "", wantRequest, test)
 }
@@ -398,8 +465,8 @@ func TestAutomateMCApplyChanges(t *testing.T) {
 		"",
 		"",
 	}
-	test := func(c SplunkClient) error {
-		return c.AutomateMCApplyChanges()
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.AutomateMCApplyChanges(ctx)
 	}
 	status := []int{
 		200, 200, 200, 200, 200, 200, 201, 200, 200, 200, 200,
@@ -408,8 +475,8 @@ func TestAutomateMCApplyChanges(t *testing.T) {
 }
 func TestGetMonitoringconsoleServerRoles(t *testing.T) {
 	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/server/info/server-info?count=0&output_mode=json", nil)
-	test := func(c SplunkClient) error {
-		info, err := c.GetMonitoringconsoleServerRoles()
+	test := func(ctx context.Context, c SplunkClient) error {
+		info, err := c.GetMonitoringconsoleServerRoles(ctx)
 		if err != nil {
 			return err
 		}
@@ -423,8 +490,8 @@ func TestGetMonitoringconsoleServerRoles(t *testing.T) {
 }
 func TestUpdateDMCGroups(t *testing.T) {
 	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/search/distributed/groups/indexer/edit", nil)
-	test := func(c SplunkClient) error {
-		err := c.UpdateDMCGroups("indexer", "splunk_cluster_master")
+	test := func(ctx context.Context, c SplunkClient) error {
+		err := c.UpdateDMCGroups(ctx, "indexer", "splunk_cluster_master")
 		if err != nil {
 			t.Errorf("Unable to update monitoring console clustering groups")
 		}
@@ -434,8 +501,8 @@ func TestUpdateDMCGroups(t *testing.T) {
 }
 func TestUpdateDMCClusteringLabelGroup(t *testing.T) {
 	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/search/distributed/groups/dmc_indexerclustergroup_abc/edit", nil)
-	test := func(c SplunkClient) error {
-		err := c.UpdateDMCClusteringLabelGroup("abc", "splunk_cluster_master")
+	test := func(ctx context.Context, c SplunkClient) error {
+		err := c.UpdateDMCClusteringLabelGroup(ctx, "abc", "splunk_cluster_master")
 		if err != nil {
 			t.Errorf("Unable to update monitoring console clustering groups")
 		}
@@ -444,11 +511,94 @@ func TestUpdateDMCClusteringLabelGroup(t *testing.T) {
 	splunkClientTester(t, "TestUpdateDMCClusteringLabelGroup", 201, "", wantRequest, test)
 }
 
+func TestGetMonitoringConsoleDistributedPeers(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/search/distributed/peers?count=0&output_mode=json", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		peers, err := c.GetMonitoringConsoleDistributedPeers(ctx)
+		if err != nil {
+			return err
+		}
+		peer, ok := peers["splunk-s1-standalone-0.splunk-s1-standalone-headless.other.svc.cluster.local:8089"]
+		if !ok {
+			t.Errorf("wanted peer not found, got %v", peers)
+		}
+		if peer.Status != "Up" || len(peer.ServerRoles) != 2 || len(peer.ClusterLabel) != 1 {
+			t.Errorf("unexpected peer info %v", peer)
+		}
+		return nil
+	}
+	body := `{"entry":[{"name":"splunk-s1-standalone-0.splunk-s1-standalone-headless.other.svc.cluster.local:8089","content":{"cluster_label":["idxc_label"],"server_roles":["indexer","search_head"],"status":"Up"}}]}`
+	splunkClientTester(t, "TestGetMonitoringConsoleDistributedPeers", 200, body, wantRequest, test)
+
+	// test error response
+	test = func(ctx context.Context, c SplunkClient) error {
+		_, err := c.GetMonitoringConsoleDistributedPeers(ctx)
+		if err == nil {
+			t.Errorf("GetMonitoringConsoleDistributedPeers returned nil; want error")
+		}
+		return nil
+	}
+	splunkClientTester(t, "TestGetMonitoringConsoleDistributedPeers", 503, "", wantRequest, test)
+}
+
+func TestAddMonitoringConsoleDistributedPeer(t *testing.T) {
+	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/search/distributed/peers", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.AddMonitoringConsoleDistributedPeer(ctx, "splunk.example.com:8089", "admin", "p@ss&word")
+	}
+	splunkClientTester(t, "TestAddMonitoringConsoleDistributedPeer", 201, "", wantRequest, test)
+
+	// test error response
+	test = func(ctx context.Context, c SplunkClient) error {
+		err := c.AddMonitoringConsoleDistributedPeer(ctx, "splunk.example.com:8089", "admin", "p@ss&word")
+		if err == nil {
+			t.Errorf("AddMonitoringConsoleDistributedPeer returned nil; want error")
+		}
+		return nil
+	}
+	splunkClientTester(t, "TestAddMonitoringConsoleDistributedPeer", 400, "", wantRequest, test)
+}
+
+func TestRemoveMonitoringConsoleDistributedPeer(t *testing.T) {
+	wantRequest, _ := http.NewRequest("DELETE", "https://localhost:8089/services/search/distributed/peers/splunk.example.com:8089", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.RemoveMonitoringConsoleDistributedPeer(ctx, "splunk.example.com:8089")
+	}
+	splunkClientTester(t, "TestRemoveMonitoringConsoleDistributedPeer", 200, "", wantRequest, test)
+
+	// peer already removed
+	splunkClientTester(t, "TestRemoveMonitoringConsoleDistributedPeer", 404, "", wantRequest, test)
+}
+
+func TestGetDMCClusteringLabelGroups(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/search/distributed/groups?count=0&output_mode=json", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		labels, err := c.GetDMCClusteringLabelGroups(ctx)
+		if err != nil {
+			return err
+		}
+		if len(labels) != 2 || labels[0] != "idxc1" || labels[1] != "idxc2" {
+			t.Errorf("GetDMCClusteringLabelGroups()=%v; want [idxc1 idxc2]", labels)
+		}
+		return nil
+	}
+	body := `{"entry":[{"name":"dmc_group_indexer"},{"name":"dmc_indexerclustergroup_idxc1"},{"name":"dmc_group_license_master"},{"name":"dmc_indexerclustergroup_idxc2"}]}`
+	splunkClientTester(t, "TestGetDMCClusteringLabelGroups", 200, body, wantRequest, test)
+}
+
+func TestDeleteDMCClusteringLabelGroup(t *testing.T) {
+	wantRequest, _ := http.NewRequest("DELETE", "https://localhost:8089/services/search/distributed/groups/dmc_indexerclustergroup_idxc1", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.DeleteDMCClusteringLabelGroup(ctx, "idxc1")
+	}
+	splunkClientTester(t, "TestDeleteDMCClusteringLabelGroup", 200, "", wantRequest, test)
+}
+
 func TestGetMonitoringconsoleAssetTable(t *testing.T) {
 	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/servicesNS/nobody/splunk_monitoring_console/saved/searches/DMC%20Asset%20-%20Build%20Full?count=0&output_mode=json", nil)
 	wantDispatchBuckets := int64(0)
-	test := func(c SplunkClient) error {
-		info, err := c.GetMonitoringconsoleAssetTable()
+	test := func(ctx context.Context, c SplunkClient) error {
+		info, err := c.GetMonitoringconsoleAssetTable(ctx)
 		if err != nil {
 			return err
 		}
@@ -471,8 +621,8 @@ func TestPostMonitoringConsoleAssetTable(t *testing.T) {
 	body := strings.NewReader("output_mode=json&trigger_actions=true&dispatch.auto_cancel=30&dispatch.buckets=300&dispatch.enablePreview=true")
 	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/servicesNS/nobody/splunk_monitoring_console/saved/searches/DMC%20Asset%20-%20Build%20Full/dispatch", body)
 	wantRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	test := func(c SplunkClient) error {
-		return c.PostMonitoringConsoleAssetTable(apiResponseMCAssetBuild)
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.PostMonitoringConsoleAssetTable(ctx, apiResponseMCAssetBuild)
 	}
 	splunkClientTester(t, "TestPostMonitoringConsoleAssetTable", 201, "", wantRequest, test)
 }
@@ -480,8 +630,8 @@ func TestPostMonitoringConsoleAssetTable(t *testing.T) {
 func TestGetMonitoringConsoleUISettings(t *testing.T) {
 	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/servicesNS/nobody/splunk_monitoring_console/data/ui/nav/default.distributed?count=0&output_mode=json", nil)
 	wantEaiAppName := "splunk_monitoring_console"
-	test := func(c SplunkClient) error {
-		info, err := c.GetMonitoringConsoleUISettings()
+	test := func(ctx context.Context, c SplunkClient) error {
+		info, err := c.GetMonitoringConsoleUISettings(ctx)
 		if err != nil {
 			return err
 		}
@@ -506,16 +656,16 @@ func TestUpdateLookupUISettings(t *testing.T) {
 	body := strings.NewReader("output_mode=json&trigger_actions=true&dispatch.auto_cancel=30&dispatch.buckets=300&dispatch.enablePreview=true")
 	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/servicesNS/nobody/splunk_monitoring_console/configs/conf-splunk_monitoring_console_assets/settings", body)
 	wantRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	test := func(c SplunkClient) error {
-		return c.UpdateLookupUISettings(wantconfiguredPeers, apiResponseUISettings)
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.UpdateLookupUISettings(ctx, wantconfiguredPeers, apiResponseUISettings)
 	}
 	splunkClientTester(t, "TestPostMonitoringconsoleAssetTable", 200, "", wantRequest, test)
 }
 
 func TestUpdateMonitoringConsoleApp(t *testing.T) {
 	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/servicesNS/nobody/system/apps/local/splunk_monitoring_console", nil)
-	test := func(c SplunkClient) error {
-		err := c.UpdateMonitoringConsoleApp()
+	test := func(ctx context.Context, c SplunkClient) error {
+		err := c.UpdateMonitoringConsoleApp(ctx)
 		if err != nil {
 			t.Errorf("MonitoringConsole App not updated")
 		}
@@ -527,8 +677,8 @@ func TestUpdateMonitoringConsoleApp(t *testing.T) {
 func TestGetClusterInfo(t *testing.T) {
 	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/cluster/config?count=0&output_mode=json", nil)
 	wantMultisite := ""
-	test := func(c SplunkClient) error {
-		info, err := c.GetClusterInfo(false)
+	test := func(ctx context.Context, c SplunkClient) error {
+		info, err := c.GetClusterInfo(ctx, false)
 		if err != nil {
 			return err
 		}
@@ -541,21 +691,291 @@ func TestGetClusterInfo(t *testing.T) {
 	splunkClientTester(t, "TestGetClusterInfo", 200, body, wantRequest, test)
 }
 
+func TestGetDeploymentServerClients(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/deployment/server/clients?count=0&output_mode=json", nil)
+	wantClients := []struct {
+		ID          string
+		Hostname    string
+		MachineType string
+	}{
+		{ID: "4D4F5C29-8E33-4E5B-9C4C-5B0E5B7C7A61", Hostname: "forwarder-0", MachineType: "linux-x86_64"},
+		{ID: "A1C0E7C2-0C1B-4B3F-8F3A-2D3A0D9D9E12", Hostname: "forwarder-1", MachineType: "windows-x64"},
+	}
+	test := func(ctx context.Context, c SplunkClient) error {
+		clients, err := c.GetDeploymentServerClients(ctx)
+		if err != nil {
+			return err
+		}
+		if len(clients) != len(wantClients) {
+			t.Errorf("len(clients)=%d; want %d", len(clients), len(wantClients))
+		}
+		for _, want := range wantClients {
+			got, ok := clients[want.ID]
+			if !ok {
+				t.Errorf("wanted client not found: %s", want.ID)
+				continue
+			}
+			if got.Hostname != want.Hostname {
+				t.Errorf("client %s hostname=%s; want %s", want.ID, got.Hostname, want.Hostname)
+			}
+			if got.MachineType != want.MachineType {
+				t.Errorf("client %s machine type=%s; want %s", want.ID, got.MachineType, want.MachineType)
+			}
+		}
+		return nil
+	}
+	body := `{"links":{},"origin":"https://localhost:8089/services/deployment/server/clients","updated":"2022-08-01T10:00:00+00:00","generator":{"build":"6818ac46f2ec","version":"9.0.0"},"entry":[{"name":"4D4F5C29-8E33-4E5B-9C4C-5B0E5B7C7A61","content":{"clientName":"4D4F5C29-8E33-4E5B-9C4C-5B0E5B7C7A61","dns":"forwarder-0.test.svc.cluster.local","guid":"4D4F5C29-8E33-4E5B-9C4C-5B0E5B7C7A61","hostname":"forwarder-0","ip":"10.42.0.8","lastPhoneHomeTime":1659348000,"utsname":"linux-x86_64"}},{"name":"A1C0E7C2-0C1B-4B3F-8F3A-2D3A0D9D9E12","content":{"clientName":"A1C0E7C2-0C1B-4B3F-8F3A-2D3A0D9D9E12","dns":"forwarder-1","hostname":"forwarder-1","ip":"10.42.0.9","lastPhoneHomeTime":1659348010,"utsname":"windows-x64"}}],"paging":{"total":2,"perPage":30,"offset":0},"messages":[]}`
+	splunkClientTester(t, "TestGetDeploymentServerClients", 200, body, wantRequest, test)
+
+	// test error response
+	test = func(ctx context.Context, c SplunkClient) error {
+		_, err := c.GetDeploymentServerClients(ctx)
+		if err == nil {
+			t.Errorf("GetDeploymentServerClients returned nil; want error")
+		}
+		return nil
+	}
+	splunkClientTester(t, "TestGetDeploymentServerClients", 503, "", wantRequest, test)
+}
+
 func TestSetIdxcSecret(t *testing.T) {
 	endpoint := fmt.Sprintf("https://localhost:8089/services/cluster/config/config?secret=%s", "changeme")
 	wantRequest, _ := http.NewRequest("POST", endpoint, nil)
 	wantRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	test := func(c SplunkClient) error {
-		return c.SetIdxcSecret("changeme")
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.SetIdxcSecret(ctx, "changeme")
 	}
 	splunkClientTester(t, "TestSetIdxcSecret", 200, "", wantRequest, test)
 }
 
 func TestRestartSplunk(t *testing.T) {
 	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/server/control/restart", nil)
-	test := func(c SplunkClient) error {
-		return c.RestartSplunk()
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.RestartSplunk(ctx)
 	}
 	splunkClientTester(t, "TestRestartSplunk", 200, "", wantRequest, test)
 }
+
+func TestGetLicenserLicenses(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/licenser/licenses?count=0&output_mode=json", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		licenses, err := c.GetLicenserLicenses(ctx)
+		if err != nil {
+			return err
+		}
+		if len(licenses) != 1 {
+			t.Errorf("len(licenses)=%d; want %d", len(licenses), 1)
+		}
+		got, ok := licenses["5B6C8F0B-4A0E-4C4B-8E5E-0E1F0B5E4C11"]
+		if !ok {
+			t.Errorf("wanted license not found")
+		}
+		if got.StackID != "enterprise" || got.Status != "VALID" || got.Quota != 10737418240 || got.ExpirationTime != 1893456000 {
+			t.Errorf("license=%v; want stack enterprise, status VALID, quota 10737418240 and expiration 1893456000", got)
+		}
+		return nil
+	}
+	body := `{"entry":[{"name":"5B6C8F0B-4A0E-4C4B-8E5E-0E1F0B5E4C11","content":{"guid":"5B6C8F0B-4A0E-4C4B-8E5E-0E1F0B5E4C11","label":"Splunk Enterprise","stack_id":"enterprise","type":"enterprise","status":"VALID","quota":10737418240,"expiration_time":1893456000}}]}`
+	splunkClientTester(t, "TestGetLicenserLicenses", 200, body, wantRequest, test)
+
+	// test error response
+	test = func(ctx context.Context, c SplunkClient) error {
+		_, err := c.GetLicenserLicenses(ctx)
+		if err == nil {
+			t.Errorf("GetLicenserLicenses returned nil; want error")
+		}
+		return nil
+	}
+	splunkClientTester(t, "TestGetLicenserLicenses", 503, "", wantRequest, test)
+}
+
+func TestGetLicenserStacks(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/licenser/stacks?count=0&output_mode=json", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		stacks, err := c.GetLicenserStacks(ctx)
+		if err != nil {
+			return err
+		}
+		if len(stacks) != 2 {
+			t.Errorf("len(stacks)=%d; want %d", len(stacks), 2)
+		}
+		if stacks["enterprise"].Quota != 10737418240 {
+			t.Errorf("stacks[enterprise].Quota=%d; want %d", stacks["enterprise"].Quota, 10737418240)
+		}
+		return nil
+	}
+	body := `{"entry":[{"name":"enterprise","content":{"label":"Splunk Enterprise","quota":10737418240,"type":"enterprise"}},{"name":"forwarder","content":{"label":"Splunk Forwarder","quota":1048576,"type":"forwarder"}}]}`
+	splunkClientTester(t, "TestGetLicenserStacks", 200, body, wantRequest, test)
+
+	// test error response
+	test = func(ctx context.Context, c SplunkClient) error {
+		_, err := c.GetLicenserStacks(ctx)
+		if err == nil {
+			t.Errorf("GetLicenserStacks returned nil; want error")
+		}
+		return nil
+	}
+	splunkClientTester(t, "TestGetLicenserStacks", 503, "", wantRequest, test)
+}
+
+func TestGetLicenserPools(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/licenser/pools?count=0&output_mode=json", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		pools, err := c.GetLicenserPools(ctx)
+		if err != nil {
+			return err
+		}
+		got, ok := pools["idxc"]
+		if !ok {
+			t.Errorf("wanted pool not found: idxc")
+		}
+		if got.StackID != "enterprise" || got.EffectiveQuota != 5368709120 || got.UsedBytes != 1024 || !reflect.DeepEqual(got.Peers, []string{"guid1", "guid2"}) {
+			t.Errorf("pool=%v; want stack enterprise, quota 5368709120, used 1024 and peers [guid1 guid2]", got)
+		}
+		return nil
+	}
+	body := `{"entry":[{"name":"idxc","content":{"description":"indexer cluster","stack_id":"enterprise","quota":5368709120,"effective_quota":5368709120,"used_bytes":1024,"peers":["guid1","guid2"]}},{"name":"auto_generated_pool_enterprise","content":{"stack_id":"enterprise","quota":"MAX","effective_quota":10737418240,"used_bytes":0,"peers":[]}}]}`
+	splunkClientTester(t, "TestGetLicenserPools", 200, body, wantRequest, test)
+
+	// test error response
+	test = func(ctx context.Context, c SplunkClient) error {
+		_, err := c.GetLicenserPools(ctx)
+		if err == nil {
+			t.Errorf("GetLicenserPools returned nil; want error")
+		}
+		return nil
+	}
+	splunkClientTester(t, "TestGetLicenserPools", 503, "", wantRequest, test)
+}
+
+func TestCreateLicenserPool(t *testing.T) {
+	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/licenser/pools", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.CreateLicenserPool(ctx, "idxc", "enterprise", "MAX", "indexer cluster", []string{"guid1", "guid2"})
+	}
+	splunkClientTester(t, "TestCreateLicenserPool", 201, "", wantRequest, test)
+
+	// test error response
+	test = func(ctx context.Context, c SplunkClient) error {
+		err := c.CreateLicenserPool(ctx, "idxc", "enterprise", "MAX", "indexer cluster", nil)
+		if err == nil {
+			t.Errorf("CreateLicenserPool returned nil; want error")
+		}
+		return nil
+	}
+	splunkClientTester(t, "TestCreateLicenserPool", 400, "", wantRequest, test)
+}
+
+func TestUpdateLicenserPool(t *testing.T) {
+	wantRequest, _ := http.NewRequest("POST", "https://localhost:8089/services/licenser/pools/idxc", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.UpdateLicenserPool(ctx, "idxc", "1073741824", "indexer cluster", []string{"guid1"})
+	}
+	splunkClientTester(t, "TestUpdateLicenserPool", 200, "", wantRequest, test)
+}
+
+func TestDeleteLicenserPool(t *testing.T) {
+	wantRequest, _ := http.NewRequest("DELETE", "https://localhost:8089/services/licenser/pools/idxc", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		return c.DeleteLicenserPool(ctx, "idxc")
+	}
+	splunkClientTester(t, "TestDeleteLicenserPool", 200, "", wantRequest, test)
+	splunkClientTester(t, "TestDeleteLicenserPool", 404, "", wantRequest, test)
+}
+
+func TestGetLicenserPeers(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/licenser/peers?count=0&output_mode=json", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		peers, err := c.GetLicenserPeers(ctx)
+		if err != nil {
+			return err
+		}
+		if peers["guid1"].Label != "splunk-idxc-indexer-0" {
+			t.Errorf("peers[guid1].Label=%s; want %s", peers["guid1"].Label, "splunk-idxc-indexer-0")
+		}
+		return nil
+	}
+	body := `{"entry":[{"name":"guid1","content":{"label":"splunk-idxc-indexer-0","active_pool_ids":["idxc"]}}]}`
+	splunkClientTester(t, "TestGetLicenserPeers", 200, body, wantRequest, test)
+}
+
+func TestGetLicenserMessages(t *testing.T) {
+	wantRequest, _ := http.NewRequest("GET", "https://localhost:8089/services/licenser/messages?count=0&output_mode=json", nil)
+	test := func(ctx context.Context, c SplunkClient) error {
+		messages, err := c.GetLicenserMessages(ctx)
+		if err != nil {
+			return err
+		}
+		if len(messages) != 1 || messages[0].StackID != "enterprise" || messages[0].Category != "pool_over_quota" {
+			t.Errorf("messages=%v; want one pool_over_quota message for stack enterprise", messages)
+		}
+		return nil
+	}
+	body := `{"entry":[{"name":"2b4a3e","content":{"category":"pool_over_quota","severity":"WARN","stack_id":"enterprise","pool_id":"idxc","description":"pool idxc exceeded its quota","create_time":1659348000}}]}`
+	splunkClientTester(t, "TestGetLicenserMessages", 200, body, wantRequest, test)
+}
+
+func TestSplunkClientRetry(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx := context.TODO()
+	c := NewSplunkClient(server.URL, "admin", "p@ssw0rd")
+	c.RetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+	// idempotent requests are retried while splunkd is unavailable
+	if err := c.Get(ctx, "/services/server/info", nil); err != nil || attempts != 3 {
+		t.Errorf("Get() = %v after %d attempts; want nil after 3 attempts", err, attempts)
+	}
+
+	attempts = 0
+	c.RetryPolicy.MaxAttempts = 2
+	if err := c.Get(ctx, "/services/server/info", nil); err == nil || attempts != 2 {
+		t.Errorf("Get() = %v after %d attempts; want error after 2 attempts", err, attempts)
+	}
+
+	// other requests are sent once
+	attempts = 0
+	c.RetryPolicy.MaxAttempts = 3
+	if err := c.RestartSplunk(ctx); err == nil || attempts != 1 {
+		t.Errorf("RestartSplunk() = %v after %d attempts; want error after 1 attempt", err, attempts)
+	}
+
+	// the retries stop once the context is done
+	attempts = 0
+	c.RetryPolicy = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}
+	cancelCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := c.Get(cancelCtx, "/services/server/info", nil); !errors.Is(err, context.DeadlineExceeded) || attempts != 1 {
+		t.Errorf("Get() = %v after %d attempts; want %v after 1 attempt", err, attempts, context.DeadlineExceeded)
+	}
+}
+
+func TestRetryPolicyWait(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, BaseDelay: 2 * time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	for attempt := 1; attempt < policy.MaxAttempts; attempt++ {
+		start := time.Now()
+		if err := policy.wait(context.TODO(), attempt); err != nil {
+			t.Errorf("wait(%d) returned error: %v", attempt, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("wait(%d) took %v; want at most %v", attempt, elapsed, policy.MaxDelay)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	policy.BaseDelay, policy.MaxDelay = time.Hour, time.Hour
+	if err := policy.wait(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("wait() = %v; want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	labelTarget   = "target"
+	labelMethod   = "method"
+	labelEndpoint = "endpoint"
+)
+
+var requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "splunk_operator_splunk_request_duration_seconds",
+	Help:    "The time taken by the Splunk REST API requests, including their retries (in seconds)",
+	Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+}, []string{labelTarget, labelMethod, labelEndpoint})
+
+var requestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "splunk_operator_splunk_request_errors_total",
+	Help: "The number of Splunk REST API requests which have failed after their retries",
+}, []string{labelTarget, labelMethod, labelEndpoint})
+
+var requestRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "splunk_operator_splunk_request_retries_total",
+	Help: "The number of times a Splunk REST API request has been retried",
+}, []string{labelTarget, labelMethod, labelEndpoint})
+
+// endpointCollections are the REST collections whose entries are named after Splunk objects (peers, pools, ...):
+// the names are replaced in the endpoint label to bound its cardinality
+var endpointCollections = map[string]bool{"groups": true, "peers": true, "pools": true}
+
+// getEndpointLabel returns the endpoint label of the metrics of a request path
+func getEndpointLabel(path string) string {
+	segments := strings.Split(path, "/")
+	for i := 1; i < len(segments); i++ {
+		if endpointCollections[segments[i-1]] && segments[i] != "" {
+			segments[i] = "{name}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func init() {
+	metrics.Registry.MustRegister(requestDuration, requestErrors, requestRetries)
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"testing"
+)
+
+func TestGetEndpointLabel(t *testing.T) {
+	for path, want := range map[string]string{
+		"/services/cluster/manager/peers":                            "/services/cluster/manager/peers",
+		"/services/licenser/pools/idxc":                              "/services/licenser/pools/{name}",
+		"/services/search/distributed/peers/splunk-idx-0:8089":       "/services/search/distributed/peers/{name}",
+		"/services/search/distributed/groups/dmc_group_indexer/edit": "/services/search/distributed/groups/{name}/edit",
+		"/services/shcluster/member/consensus/default/remove_server": "/services/shcluster/member/consensus/default/remove_server",
+	} {
+		if got := getEndpointLabel(path); got != want {
+			t.Errorf("getEndpointLabel(%s) = %s; want %s", path, got, want)
+		}
+	}
+}
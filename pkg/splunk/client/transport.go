@@ -0,0 +1,82 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// transports holds the transports shared by the clients of the Splunk instances of a same CR, by target
+var transports = struct {
+	sync.Mutex
+	targets map[string]*http.Transport
+}{targets: make(map[string]*http.Transport)}
+
+// getTarget returns the target of a management URI, which is the headless service of the pod for the pods
+// of a statefulset (e.g. "splunk-s1-indexer-headless.ns.svc.cluster.local:8089"), and the host otherwise
+func getTarget(u *url.URL) string {
+	host := u.Host
+	labels := strings.SplitN(host, ".", 3)
+	if len(labels) > 1 && strings.HasSuffix(labels[1], "-headless") {
+		host = strings.TrimPrefix(host, labels[0]+".")
+	}
+	return host
+}
+
+// getTransport returns the transport shared by the clients of the target of a management URI, whose TLS
+// configuration is the one of the certificate authority registered for its domain
+func getTransport(managementURI string) *http.Transport {
+	u, err := url.Parse(managementURI)
+	if err != nil {
+		return &http.Transport{TLSClientConfig: getTLSConfig(managementURI)}
+	}
+	target := getTarget(u)
+
+	transports.Lock()
+	defer transports.Unlock()
+	transport, ok := transports.targets[target]
+	if !ok {
+		transport = &http.Transport{
+			TLSClientConfig:     getTLSConfig(managementURI),
+			MaxIdleConnsPerHost: 4,
+			IdleConnTimeout:     90 * time.Second,
+		}
+		transports.targets[target] = transport
+	}
+	return transport
+}
+
+// resetTransports closes the connections of the shared transports of the targets of a domain and of its sub domains,
+// so that the clients created afterwards use the current TLS configuration of the domain
+func resetTransports(domain string) {
+	transports.Lock()
+	defer transports.Unlock()
+	for target, transport := range transports.targets {
+		host, _, err := net.SplitHostPort(target)
+		if err != nil {
+			host = target
+		}
+		if isInDomain(host, domain) {
+			transport.CloseIdleConnections()
+			delete(transports.targets, target)
+		}
+	}
+}
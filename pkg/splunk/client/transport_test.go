@@ -0,0 +1,85 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestGetTarget(t *testing.T) {
+	for uri, want := range map[string]string{
+		"https://splunk-s1-indexer-0.splunk-s1-indexer-headless.test.svc.cluster.local:8089": "splunk-s1-indexer-headless.test.svc.cluster.local:8089",
+		"https://splunk-cm-cluster-manager-service.test.svc.cluster.local:8089":              "splunk-cm-cluster-manager-service.test.svc.cluster.local:8089",
+		"https://localhost:8089": "localhost:8089",
+	} {
+		u, _ := url.Parse(uri)
+		if got := getTarget(u); got != want {
+			t.Errorf("getTarget(%s) = %s; want %s", uri, got, want)
+		}
+	}
+}
+
+func TestGetTransport(t *testing.T) {
+	domain := "splunk-s1-indexer-headless.test.svc.cluster.local"
+	first := getTransport("https://splunk-s1-indexer-0." + domain + ":8089")
+	if getTransport("https://splunk-s1-indexer-1."+domain+":8089") != first {
+		t.Errorf("getTransport() should share the transport of the pods of a statefulset")
+	}
+	if getTransport("https://splunk-s2-indexer-0.splunk-s2-indexer-headless.test.svc.cluster.local:8089") == first {
+		t.Errorf("getTransport() should not share the transport of different statefulsets")
+	}
+
+	// the transports are replaced once a CA is registered, to verify the certificates
+	if err := RegisterCertificateAuthority(domain, nil); err != nil {
+		t.Fatalf("RegisterCertificateAuthority() returned error: %v", err)
+	}
+	defer UnregisterCertificateAuthority(domain)
+	transport := getTransport("https://splunk-s1-indexer-0." + domain + ":8089")
+	if transport == first || transport.TLSClientConfig.InsecureSkipVerify {
+		t.Errorf("getTransport() should verify the certificates once a CA is registered")
+	}
+
+	// the transports of other domains are kept
+	other := getTransport("https://splunk-s2-indexer-0.splunk-s2-indexer-headless.test.svc.cluster.local:8089")
+	if err := RegisterCertificateAuthority("splunk-s1-indexer-service.test.svc.cluster.local", nil); err != nil {
+		t.Fatalf("RegisterCertificateAuthority() returned error: %v", err)
+	}
+	defer UnregisterCertificateAuthority("splunk-s1-indexer-service.test.svc.cluster.local")
+	if getTransport("https://splunk-s2-indexer-0.splunk-s2-indexer-headless.test.svc.cluster.local:8089") != other {
+		t.Errorf("RegisterCertificateAuthority() should keep the transports of other domains")
+	}
+
+	// registering the same CA again keeps the transport and its connections
+	if err := RegisterCertificateAuthority(domain, nil); err != nil {
+		t.Fatalf("RegisterCertificateAuthority() returned error: %v", err)
+	}
+	if getTransport("https://splunk-s1-indexer-0."+domain+":8089") != transport {
+		t.Errorf("RegisterCertificateAuthority() of the same CA should keep the cached transport")
+	}
+
+	// unregistering a domain that is not registered keeps the transports
+	UnregisterCertificateAuthority("splunk-s3-indexer-headless.test.svc.cluster.local")
+	if getTransport("https://splunk-s1-indexer-0."+domain+":8089") != transport {
+		t.Errorf("UnregisterCertificateAuthority() of an unregistered domain should keep the cached transport")
+	}
+
+	// unregistering the domain resets its transport
+	UnregisterCertificateAuthority(domain)
+	if getTransport("https://splunk-s1-indexer-0."+domain+":8089") == transport {
+		t.Errorf("UnregisterCertificateAuthority() should reset the transport of the domain")
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronSchedule(t *testing.T) {
+	for _, expression := range []string{"* * * * *", "0 2 * * SAT", "*/15 0-6 1,15 JAN-jun mon-fri", "5/10 * ? * 7", "@weekly", "@Daily"} {
+		if _, err := ParseCronSchedule(expression); err != nil {
+			t.Errorf("ParseCronSchedule(%q) returned error: %v", expression, err)
+		}
+	}
+
+	for _, expression := range []string{"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * foo *", "@reboot"} {
+		if _, err := ParseCronSchedule(expression); err == nil {
+			t.Errorf("ParseCronSchedule(%q) should have returned an error", expression)
+		}
+	}
+}
+
+func TestCronScheduleNext(t *testing.T) {
+	// Wednesday
+	start := time.Date(2022, time.June, 15, 10, 30, 20, 0, time.UTC)
+	for _, test := range []struct {
+		expression string
+		want       time.Time
+	}{
+		{"* * * * *", time.Date(2022, time.June, 15, 10, 31, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2022, time.June, 16, 10, 30, 0, 0, time.UTC)},
+		{"*/20 * * * *", time.Date(2022, time.June, 15, 10, 40, 0, 0, time.UTC)},
+		{"0 2 * * SAT", time.Date(2022, time.June, 18, 2, 0, 0, 0, time.UTC)},
+		{"0 2 * * 7", time.Date(2022, time.June, 19, 2, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		// day of month and day of week match either one when both are restricted
+		{"0 0 1 * MON", time.Date(2022, time.June, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 16 * MON", time.Date(2022, time.June, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		schedule, err := ParseCronSchedule(test.expression)
+		if err != nil {
+			t.Errorf("ParseCronSchedule(%q) returned error: %v", test.expression, err)
+			continue
+		}
+		if got := schedule.Next(start); !got.Equal(test.want) {
+			t.Errorf("Next(%q) = %v; want %v", test.expression, got, test.want)
+		}
+	}
+
+	// schedules follow the time zone of the time
+	location, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone database not available: %v", err)
+	}
+	schedule, _ := ParseCronSchedule("0 2 * * *")
+	got := schedule.Next(start.In(location))
+	if want := time.Date(2022, time.June, 16, 6, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Next() in America/New_York = %v; want %v", got, want)
+	}
+
+	// hours skipped by daylight saving time changes are skipped
+	got = schedule.Next(time.Date(2022, time.March, 12, 12, 0, 0, 0, location))
+	if want := time.Date(2022, time.March, 14, 2, 0, 0, 0, location); !got.Equal(want) {
+		t.Errorf("Next() over daylight saving time change = %v; want %v", got, want)
+	}
+}
@@ -39,6 +39,9 @@ const (
 	// IdxcSecret represents indexer cluster pass4Symmkey secret token
 	IdxcSecret = "idxc_secret"
 
+	// HecTokenSecretKey represents the HEC token secret token
+	HecTokenSecretKey = "hec_token"
+
 	// PvcNamePrefix is a helper string representing prefix for persistent volume claim names
 	PvcNamePrefix = "pvc-%s"
 
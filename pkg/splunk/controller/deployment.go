@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 	appsv1 "k8s.io/api/apps/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -31,6 +32,9 @@ import (
 
 // ApplyDeployment creates or updates a Kubernetes Deployment
 func ApplyDeployment(ctx context.Context, c splcommon.ControllerClient, revised *appsv1.Deployment) (enterpriseApi.Phase, error) {
+	ctx, span := tracing.Start(ctx, "ApplyDeployment")
+	defer span.End()
+
 	log := log.FromContext(ctx)
 	scopedLog := log.WithName("ApplyDeployment").WithValues(
 		"name", revised.GetObjectMeta().GetName(),
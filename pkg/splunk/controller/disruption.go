@@ -0,0 +1,201 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// a lease is renewed by every reconcile of its StatefulSet, it expires when the StatefulSet is no longer reconciled
+	disruptionLeaseDuration = 30 * time.Minute
+
+	// StatefulSets waiting for the budget are forgotten when they stopped asking for it
+	disruptionRequestDuration = 2 * time.Minute
+)
+
+// DisruptionBudget is the max number of StatefulSets recycling or removing pods at the same time, 0 for no limit
+var DisruptionBudget = 0
+
+// DisruptionBudgetPerNamespace applies the DisruptionBudget to each namespace instead of the whole cluster
+var DisruptionBudgetPerNamespace = false
+
+// disruptionRequest is a StatefulSet waiting for the disruption budget
+type disruptionRequest struct {
+	scope    string
+	priority int
+	since    time.Time
+	lastSeen time.Time
+}
+
+// disruptionLease is a StatefulSet holding a part of the disruption budget
+type disruptionLease struct {
+	scope  string
+	expiry time.Time
+}
+
+// disruptionTracker shares the disruption budget between the StatefulSets of all custom resources
+type disruptionTracker struct {
+	// mutex to serialize the access to disruptionTracker
+	mutex sync.Mutex
+
+	// map of StatefulSet namespace/name:lease
+	leases map[string]*disruptionLease
+
+	// map of StatefulSet namespace/name:request
+	requests map[string]*disruptionRequest
+}
+
+var operatorDisruptionTracker = newDisruptionTracker()
+
+// newDisruptionTracker returns an empty disruptionTracker
+func newDisruptionTracker() *disruptionTracker {
+	return &disruptionTracker{
+		leases:   make(map[string]*disruptionLease),
+		requests: make(map[string]*disruptionRequest),
+	}
+}
+
+// acquire returns true if the StatefulSet holds or got a part of the budget of its scope. A StatefulSet that has
+// to wait only gets the budget before the waiting ones of lower priority, or of equal priority that came later
+func (t *disruptionTracker) acquire(key, scope string, priority, budget int, now time.Time) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if lease, ok := t.leases[key]; ok && lease.expiry.After(now) {
+		lease.expiry = now.Add(disruptionLeaseDuration)
+		return true
+	}
+
+	held := 0
+	for leaseKey, lease := range t.leases {
+		if !lease.expiry.After(now) {
+			delete(t.leases, leaseKey)
+		} else if lease.scope == scope {
+			held++
+		}
+	}
+
+	request, ok := t.requests[key]
+	if !ok {
+		request = &disruptionRequest{scope: scope, since: now}
+		t.requests[key] = request
+	}
+	request.priority = priority
+	request.lastSeen = now
+
+	if held >= budget {
+		return false
+	}
+
+	// the free parts of the budget go to the requests first in line
+	ahead := 0
+	for requestKey, other := range t.requests {
+		if !other.lastSeen.Add(disruptionRequestDuration).After(now) {
+			delete(t.requests, requestKey)
+			continue
+		}
+		if requestKey == key || other.scope != scope {
+			continue
+		}
+		if other.priority > priority || (other.priority == priority && other.since.Before(request.since)) {
+			ahead++
+		}
+	}
+	if held+ahead >= budget {
+		return false
+	}
+
+	delete(t.requests, key)
+	t.leases[key] = &disruptionLease{scope: scope, expiry: now.Add(disruptionLeaseDuration)}
+	return true
+}
+
+// renew extends the lease of a StatefulSet, if it holds one
+func (t *disruptionTracker) renew(key string, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if lease, ok := t.leases[key]; ok && lease.expiry.After(now) {
+		lease.expiry = now.Add(disruptionLeaseDuration)
+	}
+}
+
+// release returns the part of the budget held by a StatefulSet
+func (t *disruptionTracker) release(key string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.leases, key)
+	delete(t.requests, key)
+}
+
+// getDisruptionKey returns the key of a StatefulSet in the disruption tracker, and the scope of its budget
+func getDisruptionKey(statefulSet *appsv1.StatefulSet) (string, string) {
+	scope := ""
+	if DisruptionBudgetPerNamespace {
+		scope = statefulSet.GetNamespace()
+	}
+	return statefulSet.GetNamespace() + "/" + statefulSet.GetName(), scope
+}
+
+// getDisruptionPriority returns the priority of a StatefulSet for the disruption budget, higher values go first
+func getDisruptionPriority(statefulSet *appsv1.StatefulSet) int {
+	priority, err := strconv.Atoi(statefulSet.GetAnnotations()[enterpriseApi.DisruptionPriorityLabel])
+	if err != nil {
+		return 0
+	}
+	return priority
+}
+
+// acquireDisruptionBudget returns true if a StatefulSet may recycle or remove its pods within the disruption budget
+func acquireDisruptionBudget(ctx context.Context, statefulSet *appsv1.StatefulSet) bool {
+	if DisruptionBudget <= 0 {
+		return true
+	}
+
+	key, scope := getDisruptionKey(statefulSet)
+	priority := getDisruptionPriority(statefulSet)
+	if operatorDisruptionTracker.acquire(key, scope, priority, DisruptionBudget, time.Now()) {
+		return true
+	}
+
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("acquireDisruptionBudget").WithValues(
+		"name", statefulSet.GetObjectMeta().GetName(),
+		"namespace", statefulSet.GetObjectMeta().GetNamespace())
+	scopedLog.Info("Waiting for disruption budget", "budget", DisruptionBudget, "priority", priority)
+	return false
+}
+
+// renewDisruptionBudget keeps the part of the disruption budget held by a StatefulSet
+func renewDisruptionBudget(statefulSet *appsv1.StatefulSet) {
+	key, _ := getDisruptionKey(statefulSet)
+	operatorDisruptionTracker.renew(key, time.Now())
+}
+
+// ReleaseDisruptionBudget returns the part of the disruption budget held by a StatefulSet
+func ReleaseDisruptionBudget(statefulSet *appsv1.StatefulSet) {
+	key, _ := getDisruptionKey(statefulSet)
+	operatorDisruptionTracker.release(key)
+}
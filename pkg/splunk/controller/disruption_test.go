@@ -0,0 +1,196 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+)
+
+func TestDisruptionTracker(t *testing.T) {
+	tracker := newDisruptionTracker()
+	now := time.Date(2022, time.June, 18, 3, 30, 0, 0, time.UTC)
+
+	if !tracker.acquire("test/a", "", 0, 1, now) {
+		t.Errorf("acquire(a) = false; want true within the budget")
+	}
+	if !tracker.acquire("test/a", "", 0, 1, now) {
+		t.Errorf("acquire(a) = false; want true for the holder of a lease")
+	}
+	if tracker.acquire("test/b", "", 0, 1, now) {
+		t.Errorf("acquire(b) = true; want false over the budget")
+	}
+
+	// budgets of other scopes are separate
+	if !tracker.acquire("other/c", "other", 0, 1, now) {
+		t.Errorf("acquire(c) = false; want true in another scope")
+	}
+
+	// higher priorities get the budget first, then the earliest requests
+	now = now.Add(time.Second)
+	if tracker.acquire("test/d", "", 10, 1, now) {
+		t.Errorf("acquire(d) = true; want false over the budget")
+	}
+	now = now.Add(time.Second)
+	if tracker.acquire("test/e", "", 0, 1, now) {
+		t.Errorf("acquire(e) = true; want false over the budget")
+	}
+	tracker.release("test/a")
+	if tracker.acquire("test/b", "", 0, 1, now) {
+		t.Errorf("acquire(b) = true; want false behind a higher priority")
+	}
+	if !tracker.acquire("test/d", "", 10, 1, now) {
+		t.Errorf("acquire(d) = false; want true for the highest priority")
+	}
+	tracker.release("test/d")
+	if tracker.acquire("test/e", "", 0, 1, now) {
+		t.Errorf("acquire(e) = true; want false behind an earlier request")
+	}
+	if !tracker.acquire("test/b", "", 0, 1, now) {
+		t.Errorf("acquire(b) = false; want true for the earliest request")
+	}
+
+	// requests that are no longer renewed do not hold up the others
+	if tracker.acquire("test/e", "", 0, 1, now) {
+		t.Errorf("acquire(e) = true; want false over the budget")
+	}
+	now = now.Add(time.Second)
+	if tracker.acquire("test/f", "", 0, 1, now) {
+		t.Errorf("acquire(f) = true; want false over the budget")
+	}
+	tracker.release("test/b")
+	now = now.Add(disruptionRequestDuration)
+	if !tracker.acquire("test/f", "", 0, 1, now) {
+		t.Errorf("acquire(f) = false; want true when the earlier request expired")
+	}
+
+	// leases that are no longer renewed expire
+	now = now.Add(disruptionLeaseDuration / 2)
+	tracker.renew("test/f", now)
+	now = now.Add(disruptionLeaseDuration - time.Second)
+	if tracker.acquire("test/g", "", 0, 1, now) {
+		t.Errorf("acquire(g) = true; want false for a renewed lease")
+	}
+	if !tracker.acquire("test/g", "", 0, 1, now.Add(time.Second)) {
+		t.Errorf("acquire(g) = false; want true when the lease expired")
+	}
+}
+
+func TestUpdateStatefulSetPodsDisruptionBudget(t *testing.T) {
+	DisruptionBudget = 1
+	defer func() {
+		DisruptionBudget = 0
+		operatorDisruptionTracker = newDisruptionTracker()
+	}()
+
+	mgr := DefaultStatefulSetPodManager{}
+	var replicas int32 = 1
+	newStatefulSet := func(name string) (*appsv1.StatefulSet, *corev1.Pod) {
+		statefulSet := &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test",
+			},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+			},
+			Status: appsv1.StatefulSetStatus{
+				Replicas:       replicas,
+				ReadyReplicas:  replicas,
+				UpdateRevision: "v1",
+			},
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name + "-0",
+				Namespace: "test",
+				Labels: map[string]string{
+					"controller-revision-hash": "v0",
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Ready: true},
+				},
+			},
+		}
+		return statefulSet, pod
+	}
+	stack1, pod1 := newStatefulSet("splunk-stack1")
+	stack2, pod2 := newStatefulSet("splunk-stack2")
+
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	c.AddObjects([]client.Object{stack1, pod1, stack2, pod2})
+
+	// the first StatefulSet gets the budget
+	phase, err := UpdateStatefulSetPods(ctx, c, stack1, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhaseUpdating || len(c.Calls["Delete"]) != 1 {
+		t.Errorf("UpdateStatefulSetPods(stack1) returned %s, %v; want Updating", phase, err)
+	}
+
+	// the second one waits for it
+	phase, err = UpdateStatefulSetPods(ctx, c, stack2, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhaseUpdating || len(c.Calls["Delete"]) != 1 {
+		t.Errorf("UpdateStatefulSetPods(stack2) returned %s, %v; want Updating without recycle", phase, err)
+	}
+
+	// until all pods of the first one are updated
+	pod1.Labels["controller-revision-hash"] = "v1"
+	c.AddObject(pod1)
+	phase, err = UpdateStatefulSetPods(ctx, c, stack1, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhaseReady {
+		t.Errorf("UpdateStatefulSetPods(stack1) returned %s, %v; want Ready", phase, err)
+	}
+	phase, err = UpdateStatefulSetPods(ctx, c, stack2, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhaseUpdating || len(c.Calls["Delete"]) != 2 {
+		t.Errorf("UpdateStatefulSetPods(stack2) returned %s, %v; want Updating with recycle", phase, err)
+	}
+
+	// scale downs wait for the budget as well
+	replicas = 2
+	stack1.Status.Replicas = replicas
+	stack1.Status.ReadyReplicas = replicas
+	phase, err = UpdateStatefulSetPods(ctx, c, stack1, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhaseScalingDown || *stack1.Spec.Replicas != 2 {
+		t.Errorf("UpdateStatefulSetPods(stack1) returned %s, %v; want ScalingDown without scale down", phase, err)
+	}
+}
+
+func TestGetDisruptionPriority(t *testing.T) {
+	statefulSet := &appsv1.StatefulSet{}
+	if got := getDisruptionPriority(statefulSet); got != 0 {
+		t.Errorf("getDisruptionPriority() = %d; want 0 by default", got)
+	}
+	statefulSet.Annotations = map[string]string{enterpriseApi.DisruptionPriorityLabel: "10"}
+	if got := getDisruptionPriority(statefulSet); got != 10 {
+		t.Errorf("getDisruptionPriority() = %d; want 10", got)
+	}
+	statefulSet.Annotations[enterpriseApi.DisruptionPriorityLabel] = "high"
+	if got := getDisruptionPriority(statefulSet); got != 0 {
+		t.Errorf("getDisruptionPriority() = %d; want 0 for an invalid priority", got)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"reflect"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ApplyIngress creates or updates a Kubernetes Ingress
+func ApplyIngress(ctx context.Context, client splcommon.ControllerClient, revised *networkingv1.Ingress) error {
+	ctx, span := tracing.Start(ctx, "ApplyIngress")
+	defer span.End()
+
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("ApplyIngress").WithValues(
+		"name", revised.GetObjectMeta().GetName(),
+		"namespace", revised.GetObjectMeta().GetNamespace())
+
+	namespacedName := types.NamespacedName{Namespace: revised.GetNamespace(), Name: revised.GetName()}
+	var current networkingv1.Ingress
+
+	err := client.Get(ctx, namespacedName, &current)
+	if err != nil && k8serrors.IsNotFound(err) {
+		return splutil.CreateResource(ctx, client, revised)
+	} else if err != nil {
+		return err
+	}
+
+	hasUpdates := mergeObjectMetaUpdates(&current.ObjectMeta, &revised.ObjectMeta)
+	if !reflect.DeepEqual(current.Spec, revised.Spec) {
+		scopedLog.Info("Ingress Spec differs",
+			"current", current.Spec,
+			"revised", revised.Spec)
+		current.Spec = revised.Spec
+		hasUpdates = true
+	}
+	*revised = current // caller expects that object passed represents latest state
+
+	// only update if there are material differences, as determined by comparison function
+	if hasUpdates {
+		scopedLog.Info("Updating existing Ingress")
+		return splutil.UpdateResource(ctx, client, revised)
+	}
+
+	// all is good!
+	scopedLog.Info("No update to existing Ingress")
+	return nil
+}
+
+// ApplyUnstructured creates or updates an object of an optional API (e.g. a Gateway API HTTPRoute or a cert-manager
+// Certificate), which is handled as an unstructured object so that its CRDs are only required when it is used
+func ApplyUnstructured(ctx context.Context, client splcommon.ControllerClient, revised *unstructured.Unstructured) error {
+	ctx, span := tracing.Start(ctx, "ApplyUnstructured")
+	defer span.End()
+
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("ApplyUnstructured").WithValues(
+		"kind", revised.GetKind(),
+		"name", revised.GetName(),
+		"namespace", revised.GetNamespace())
+
+	namespacedName := types.NamespacedName{Namespace: revised.GetNamespace(), Name: revised.GetName()}
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(revised.GroupVersionKind())
+
+	err := client.Get(ctx, namespacedName, current)
+	if err != nil && k8serrors.IsNotFound(err) {
+		err = client.Create(ctx, revised)
+		if err != nil {
+			scopedLog.Error(err, "Failed to create object")
+		} else {
+			scopedLog.Info("Created object")
+		}
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	labels, annotations := current.GetLabels(), current.GetAnnotations()
+	currentMeta := metav1.ObjectMeta{Labels: labels, Annotations: annotations}
+	revisedMeta := metav1.ObjectMeta{Labels: revised.GetLabels(), Annotations: revised.GetAnnotations()}
+	hasUpdates := mergeObjectMetaUpdates(&currentMeta, &revisedMeta)
+	current.SetLabels(currentMeta.Labels)
+	current.SetAnnotations(currentMeta.Annotations)
+
+	if !reflect.DeepEqual(current.Object["spec"], revised.Object["spec"]) {
+		scopedLog.Info("Object spec differs",
+			"current", current.Object["spec"],
+			"revised", revised.Object["spec"])
+		current.Object["spec"] = revised.Object["spec"]
+		hasUpdates = true
+	}
+	*revised = *current // caller expects that object passed represents latest state
+
+	if hasUpdates {
+		scopedLog.Info("Updating existing object")
+		err = client.Update(ctx, revised)
+		if err != nil {
+			scopedLog.Error(err, "Failed to update object")
+		}
+		return err
+	}
+
+	scopedLog.Info("No update to existing object")
+	return nil
+}
+
+// mergeObjectMetaUpdates adds the revised labels and annotations to current, keeping the ones set by others (e.g. ingress controllers)
+func mergeObjectMetaUpdates(current *metav1.ObjectMeta, revised *metav1.ObjectMeta) bool {
+	result := false
+	for k, v := range revised.Labels {
+		if current.Labels[k] != v {
+			if current.Labels == nil {
+				current.Labels = make(map[string]string)
+			}
+			current.Labels[k] = v
+			result = true
+		}
+	}
+	for k, v := range revised.Annotations {
+		if current.Annotations[k] != v {
+			if current.Annotations == nil {
+				current.Annotations = make(map[string]string)
+			}
+			current.Annotations[k] = v
+			result = true
+		}
+	}
+	return result
+}
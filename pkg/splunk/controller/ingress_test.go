@@ -0,0 +1,58 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+)
+
+func TestApplyIngress(t *testing.T) {
+	funcCalls := []spltest.MockFuncCall{{MetaName: "*v1.Ingress-test-ingress"}}
+	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": funcCalls}
+	updateCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Update": funcCalls}
+	current := networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ingress",
+			Namespace: "test",
+		},
+	}
+	revised := current.DeepCopy()
+	revised.Spec.TLS = []networkingv1.IngressTLS{{SecretName: "hec-tls"}}
+	reconcile := func(c *spltest.MockClient, cr interface{}) error {
+		return ApplyIngress(context.TODO(), c, cr.(*networkingv1.Ingress))
+	}
+	spltest.ReconcileTester(t, "TestApplyIngress", &current, revised, createCalls, updateCalls, reconcile, false)
+}
+
+func TestMergeRouteMetaUpdates(t *testing.T) {
+	current := metav1.ObjectMeta{Annotations: map[string]string{"set-by-controller": "true"}}
+	revised := metav1.ObjectMeta{Labels: map[string]string{"app": "splunk"}}
+	if !mergeObjectMetaUpdates(&current, &revised) {
+		t.Errorf("mergeObjectMetaUpdates() = false; want true")
+	}
+	if current.Labels["app"] != "splunk" || current.Annotations["set-by-controller"] != "true" {
+		t.Errorf("mergeObjectMetaUpdates() did not merge labels correctly: %v", current)
+	}
+	if mergeObjectMetaUpdates(&current, &revised) {
+		t.Errorf("mergeObjectMetaUpdates() = true on unchanged meta; want false")
+	}
+}
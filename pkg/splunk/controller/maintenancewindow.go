@@ -0,0 +1,87 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	// the operator image does not ship the time zone database
+	_ "time/tzdata"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// ValidateMaintenanceWindows checks the schedules, durations and time zones of maintenance windows
+func ValidateMaintenanceWindows(windows []enterpriseApi.MaintenanceWindow) error {
+	_, _, err := GetMaintenanceWindowState(windows, time.Now())
+	return err
+}
+
+// GetMaintenanceWindowState returns true if t is within one of the maintenance windows. Otherwise it returns the
+// time the next window opens, which is zero if none does. Disruptive operations are always allowed without windows
+func GetMaintenanceWindowState(windows []enterpriseApi.MaintenanceWindow, t time.Time) (bool, time.Time, error) {
+	if len(windows) == 0 {
+		return true, t, nil
+	}
+
+	var nextOpen time.Time
+	for i, window := range windows {
+		schedule, err := splcommon.ParseCronSchedule(window.Schedule)
+		if err != nil {
+			return false, nextOpen, fmt.Errorf("maintenance window %d: %v", i, err)
+		}
+		duration, err := time.ParseDuration(window.Duration)
+		if err != nil || duration <= 0 {
+			return false, nextOpen, fmt.Errorf("maintenance window %d: invalid duration %q", i, window.Duration)
+		}
+		location := time.UTC
+		if window.TimeZone != "" {
+			location, err = time.LoadLocation(window.TimeZone)
+			if err != nil {
+				return false, nextOpen, fmt.Errorf("maintenance window %d: invalid time zone %q", i, window.TimeZone)
+			}
+		}
+
+		// the window is open if it started within its duration
+		local := t.In(location)
+		if start := schedule.Next(local.Add(-duration)); !start.IsZero() && !start.After(local) {
+			return true, t, nil
+		}
+		if start := schedule.Next(local); !start.IsZero() && (nextOpen.IsZero() || start.Before(nextOpen)) {
+			nextOpen = start
+		}
+	}
+	return false, nextOpen, nil
+}
+
+// IsStatefulSetInMaintenanceWindow returns true if the disruptive operations of a StatefulSet are allowed at time t,
+// according to the maintenance windows set in its annotations. Otherwise it returns the time the next window opens
+func IsStatefulSetInMaintenanceWindow(statefulSet *appsv1.StatefulSet, t time.Time) (bool, time.Time, error) {
+	value, ok := statefulSet.GetAnnotations()[enterpriseApi.MaintenanceWindowsAnnotation]
+	if !ok {
+		return true, t, nil
+	}
+	var windows []enterpriseApi.MaintenanceWindow
+	err := json.Unmarshal([]byte(value), &windows)
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("invalid %s annotation: %v", enterpriseApi.MaintenanceWindowsAnnotation, err)
+	}
+	return GetMaintenanceWindowState(windows, t)
+}
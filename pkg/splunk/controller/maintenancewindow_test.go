@@ -0,0 +1,216 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+)
+
+func TestGetMaintenanceWindowState(t *testing.T) {
+	// Saturday 03:30 UTC
+	now := time.Date(2022, time.June, 18, 3, 30, 0, 0, time.UTC)
+	windows := []enterpriseApi.MaintenanceWindow{
+		{Schedule: "0 2 * * SAT", Duration: "2h"},
+	}
+
+	open, _, err := GetMaintenanceWindowState(windows, now)
+	if err != nil || !open {
+		t.Errorf("GetMaintenanceWindowState() = %t, %v; want open", open, err)
+	}
+
+	// the window is closed at its end
+	open, nextOpen, err := GetMaintenanceWindowState(windows, now.Add(30*time.Minute))
+	if err != nil || open {
+		t.Errorf("GetMaintenanceWindowState() = %t, %v; want closed", open, err)
+	}
+	if want := time.Date(2022, time.June, 25, 2, 0, 0, 0, time.UTC); !nextOpen.Equal(want) {
+		t.Errorf("GetMaintenanceWindowState() next window = %v; want %v", nextOpen, want)
+	}
+
+	// the earliest of several windows opens next
+	windows = append(windows, enterpriseApi.MaintenanceWindow{Schedule: "0 22 * * *", Duration: "30m", TimeZone: "Europe/Berlin"})
+	open, nextOpen, err = GetMaintenanceWindowState(windows, now.Add(time.Hour))
+	if err != nil || open {
+		t.Errorf("GetMaintenanceWindowState() = %t, %v; want closed", open, err)
+	}
+	if want := time.Date(2022, time.June, 18, 20, 0, 0, 0, time.UTC); !nextOpen.Equal(want) {
+		t.Errorf("GetMaintenanceWindowState() next window = %v; want %v", nextOpen, want)
+	}
+	open, _, _ = GetMaintenanceWindowState(windows, time.Date(2022, time.June, 18, 20, 10, 0, 0, time.UTC))
+	if !open {
+		t.Errorf("GetMaintenanceWindowState() = closed; want open in the time zone of the window")
+	}
+
+	// without windows disruptive operations are always allowed
+	open, _, err = GetMaintenanceWindowState(nil, now)
+	if err != nil || !open {
+		t.Errorf("GetMaintenanceWindowState() without windows = %t, %v; want open", open, err)
+	}
+
+	for _, window := range []enterpriseApi.MaintenanceWindow{
+		{Schedule: "0 2 * *", Duration: "2h"},
+		{Schedule: "0 2 * * *", Duration: "2 hours"},
+		{Schedule: "0 2 * * *", Duration: "-1h"},
+		{Schedule: "0 2 * * *", Duration: "2h", TimeZone: "Mars/Olympus"},
+	} {
+		if err = ValidateMaintenanceWindows([]enterpriseApi.MaintenanceWindow{window}); err == nil {
+			t.Errorf("ValidateMaintenanceWindows(%v) should have returned an error", window)
+		}
+	}
+}
+
+func TestUpdateStatefulSetPodsMaintenanceWindow(t *testing.T) {
+	mgr := DefaultStatefulSetPodManager{}
+	var replicas int32 = 1
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1",
+			Namespace: "test",
+			// a window that only opens on 29 February
+			Annotations: map[string]string{
+				enterpriseApi.MaintenanceWindowsAnnotation: `[{"schedule":"0 0 29 2 *","duration":"1m"}]`,
+			},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+		},
+		Status: appsv1.StatefulSetStatus{
+			Replicas:       replicas,
+			ReadyReplicas:  replicas,
+			UpdateRevision: "v1",
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1-0",
+			Namespace: "test",
+			Labels: map[string]string{
+				"controller-revision-hash": "v0",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Ready: true},
+			},
+		},
+	}
+
+	// outdated pods are not recycled outside of the maintenance windows
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	c.AddObjects([]client.Object{statefulSet, pod})
+	phase, err := UpdateStatefulSetPods(ctx, c, statefulSet, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhasePendingMaintenanceWindow {
+		t.Errorf("UpdateStatefulSetPods() returned %s, %v; want PendingMaintenanceWindow", phase, err)
+	}
+	if len(c.Calls["Delete"]) != 0 {
+		t.Errorf("UpdateStatefulSetPods() recycled a pod outside of the maintenance windows")
+	}
+
+	// nor are pods scaled down
+	replicas = 2
+	statefulSet.Status.Replicas = replicas
+	statefulSet.Status.ReadyReplicas = replicas
+	phase, err = UpdateStatefulSetPods(ctx, c, statefulSet, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhasePendingMaintenanceWindow {
+		t.Errorf("UpdateStatefulSetPods() returned %s, %v; want PendingMaintenanceWindow", phase, err)
+	}
+	if len(c.Calls["Update"]) != 0 {
+		t.Errorf("UpdateStatefulSetPods() scaled down outside of the maintenance windows")
+	}
+
+	// scale ups are not disruptive
+	phase, err = UpdateStatefulSetPods(ctx, c, statefulSet, &mgr, 3)
+	if err != nil || phase != enterpriseApi.PhaseScalingUp {
+		t.Errorf("UpdateStatefulSetPods() returned %s, %v; want ScalingUp", phase, err)
+	}
+
+	// pods are recycled within a maintenance window
+	replicas = 1
+	statefulSet.Status.Replicas = replicas
+	statefulSet.Status.ReadyReplicas = replicas
+	statefulSet.Annotations[enterpriseApi.MaintenanceWindowsAnnotation] = `[{"schedule":"* * * * *","duration":"5m"}]`
+	c = spltest.NewMockClient()
+	c.AddObjects([]client.Object{statefulSet, pod})
+	phase, err = UpdateStatefulSetPods(ctx, c, statefulSet, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhaseUpdating {
+		t.Errorf("UpdateStatefulSetPods() returned %s, %v; want Updating", phase, err)
+	}
+	c.CheckCalls(t, "TestUpdateStatefulSetPodsMaintenanceWindow", map[string][]spltest.MockFuncCall{
+		"Get":    {{MetaName: "*v1.Pod-test-splunk-stack1-0"}},
+		"Update": {{MetaName: "*v1.StatefulSet-test-splunk-stack1"}},
+		"Delete": {{MetaName: "*v1.Pod-test-splunk-stack1-0"}},
+	})
+	if statefulSet.Annotations[enterpriseApi.MaintenanceInProgressAnnotation] != "splunk-stack1-0" {
+		t.Errorf("UpdateStatefulSetPods() did not remember the recycled pod")
+	}
+
+	// the recycle of a pod started within a window is completed after it closed
+	statefulSet.Annotations[enterpriseApi.MaintenanceWindowsAnnotation] = `[{"schedule":"0 0 29 2 *","duration":"1m"}]`
+	c = spltest.NewMockClient()
+	c.AddObjects([]client.Object{statefulSet, pod})
+	phase, err = UpdateStatefulSetPods(ctx, c, statefulSet, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhaseUpdating || len(c.Calls["Delete"]) != 1 {
+		t.Errorf("UpdateStatefulSetPods() returned %s, %v; want Updating", phase, err)
+	}
+
+	// the pod is forgotten once all pods are updated
+	pod.Labels["controller-revision-hash"] = "v1"
+	c = spltest.NewMockClient()
+	c.AddObjects([]client.Object{statefulSet, pod})
+	phase, err = UpdateStatefulSetPods(ctx, c, statefulSet, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhaseReady {
+		t.Errorf("UpdateStatefulSetPods() returned %s, %v; want Ready", phase, err)
+	}
+	if _, ok := statefulSet.Annotations[enterpriseApi.MaintenanceInProgressAnnotation]; ok {
+		t.Errorf("UpdateStatefulSetPods() kept the recycled pod")
+	}
+}
+
+func TestMergeMaintenanceWindowsAnnotation(t *testing.T) {
+	current := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1",
+			Namespace: "test",
+		},
+	}
+	revised := current.DeepCopy()
+	revised.Annotations = map[string]string{enterpriseApi.MaintenanceWindowsAnnotation: `[{"schedule":"* * * * *","duration":"5m"}]`}
+	if !mergeStatefulSetAnnotationUpdates(current, revised, enterpriseApi.MaintenanceWindowsAnnotation) {
+		t.Errorf("mergeStatefulSetAnnotationUpdates() = false; want true for an added annotation")
+	}
+	if current.Annotations[enterpriseApi.MaintenanceWindowsAnnotation] != revised.Annotations[enterpriseApi.MaintenanceWindowsAnnotation] {
+		t.Errorf("mergeStatefulSetAnnotationUpdates() did not copy the annotation")
+	}
+	if mergeStatefulSetAnnotationUpdates(current, revised, enterpriseApi.MaintenanceWindowsAnnotation) {
+		t.Errorf("mergeStatefulSetAnnotationUpdates() = true; want false without changes")
+	}
+	revised.Annotations = nil
+	if !mergeStatefulSetAnnotationUpdates(current, revised, enterpriseApi.MaintenanceWindowsAnnotation) || len(current.Annotations) != 0 {
+		t.Errorf("mergeStatefulSetAnnotationUpdates() did not remove the annotation")
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// VolumeSnapshotGroupVersionKind is the kind of the CSI volume snapshots
+var VolumeSnapshotGroupVersionKind = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+
+// GetVolumeSnapshotName returns the name of the volume snapshot taken of a persistent volume claim
+func GetVolumeSnapshotName(pvc *corev1.PersistentVolumeClaim) string {
+	// the uid distinguishes snapshots of a claim that was deleted and created again with the same name
+	uid := strings.Split(string(pvc.GetUID()), "-")[0]
+	if uid == "" {
+		return pvc.GetName()
+	}
+	return fmt.Sprintf("%s-%s", pvc.GetName(), uid)
+}
+
+// CreateVolumeSnapshot takes a CSI volume snapshot of a persistent volume claim. The snapshot is handled as an
+// unstructured object so that the snapshot CRDs are only required when snapshots are used
+func CreateVolumeSnapshot(ctx context.Context, c splcommon.ControllerClient, pvc *corev1.PersistentVolumeClaim, snapshotClassName string) error {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("CreateVolumeSnapshot").WithValues(
+		"pvcName", pvc.GetName(),
+		"namespace", pvc.GetNamespace())
+
+	spec := map[string]interface{}{
+		"source": map[string]interface{}{
+			"persistentVolumeClaimName": pvc.GetName(),
+		},
+	}
+	if snapshotClassName != "" {
+		spec["volumeSnapshotClassName"] = snapshotClassName
+	}
+
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGroupVersionKind)
+	snapshot.SetName(GetVolumeSnapshotName(pvc))
+	snapshot.SetNamespace(pvc.GetNamespace())
+	snapshot.SetLabels(pvc.GetLabels())
+
+	err := c.Create(ctx, snapshot)
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		scopedLog.Error(err, "Failed to create volume snapshot")
+		return err
+	}
+	scopedLog.Info("Created volume snapshot", "snapshotName", snapshot.GetName())
+	return nil
+}
+
+// getVolumeSnapshot returns the volume snapshot taken of a persistent volume claim
+func getVolumeSnapshot(ctx context.Context, c splcommon.ControllerClient, pvc *corev1.PersistentVolumeClaim) (*unstructured.Unstructured, error) {
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGroupVersionKind)
+	err := c.Get(ctx, types.NamespacedName{Namespace: pvc.GetNamespace(), Name: GetVolumeSnapshotName(pvc)}, snapshot)
+	return snapshot, err
+}
+
+// isVolumeSnapshotReady checks whether a volume snapshot was taken and can be restored without its source claim
+func isVolumeSnapshotReady(snapshot *unstructured.Unstructured) bool {
+	ready, _, _ := unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	return ready
+}
+
+// ApplyPVCRetentionPolicy retains, deletes or snapshots and deletes a persistent volume claim that is no longer used.
+// It returns false while a claim is kept for its snapshot: the snapshot controller only protects the claim once it
+// handles the snapshot, and a claim being deleted can not be snapshotted anymore, so the claim is only deleted once
+// its snapshot is ready to use
+func ApplyPVCRetentionPolicy(ctx context.Context, c splcommon.ControllerClient, pvc *corev1.PersistentVolumeClaim, policy enterpriseApi.PVCRetentionPolicyType, snapshotClassName string) (bool, error) {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("ApplyPVCRetentionPolicy").WithValues(
+		"pvcName", pvc.GetName(),
+		"namespace", pvc.GetNamespace())
+
+	switch policy {
+	case enterpriseApi.PVCRetentionPolicyRetain:
+		scopedLog.Info("Retaining PVC")
+		return true, nil
+	case enterpriseApi.PVCRetentionPolicySnapshot:
+		snapshot, err := getVolumeSnapshot(ctx, c, pvc)
+		if k8serrors.IsNotFound(err) {
+			return false, CreateVolumeSnapshot(ctx, c, pvc, snapshotClassName)
+		}
+		if err != nil {
+			return false, err
+		}
+		if !isVolumeSnapshotReady(snapshot) {
+			message, _, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message")
+			scopedLog.Info("Waiting for volume snapshot to be ready", "snapshotName", snapshot.GetName(), "error", message)
+			return false, nil
+		}
+	}
+
+	scopedLog.Info("Deleting PVC")
+	err := c.Delete(ctx, pvc)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return false, err
+	}
+	return true, nil
+}
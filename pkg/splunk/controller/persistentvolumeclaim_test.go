@@ -0,0 +1,140 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGetVolumeSnapshotName(t *testing.T) {
+	pvc := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pvc-var-splunk-stack1-indexer-2"}}
+	if got := GetVolumeSnapshotName(&pvc); got != "pvc-var-splunk-stack1-indexer-2" {
+		t.Errorf("GetVolumeSnapshotName() = %s; want pvc-var-splunk-stack1-indexer-2", got)
+	}
+	pvc.UID = "8a1f2c3d-aaaa-bbbb-cccc-dddddddddddd"
+	if got := GetVolumeSnapshotName(&pvc); got != "pvc-var-splunk-stack1-indexer-2-8a1f2c3d" {
+		t.Errorf("GetVolumeSnapshotName() = %s; want pvc-var-splunk-stack1-indexer-2-8a1f2c3d", got)
+	}
+}
+
+func TestApplyPVCRetentionPolicy(t *testing.T) {
+	ctx := context.TODO()
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pvc-var-splunk-stack1-indexer-2",
+			Namespace: "test",
+			Labels:    map[string]string{"app.kubernetes.io/instance": "splunk-stack1-indexer"},
+		},
+	}
+
+	tests := []struct {
+		policy    enterpriseApi.PVCRetentionPolicyType
+		want      bool
+		wantCalls map[string][]spltest.MockFuncCall
+	}{
+		{enterpriseApi.PVCRetentionPolicyRetain, true, map[string][]spltest.MockFuncCall{}},
+		{"", true, map[string][]spltest.MockFuncCall{
+			"Delete": {{MetaName: "*v1.PersistentVolumeClaim-test-pvc-var-splunk-stack1-indexer-2"}},
+		}},
+		{enterpriseApi.PVCRetentionPolicyDelete, true, map[string][]spltest.MockFuncCall{
+			"Delete": {{MetaName: "*v1.PersistentVolumeClaim-test-pvc-var-splunk-stack1-indexer-2"}},
+		}},
+		{enterpriseApi.PVCRetentionPolicySnapshot, false, map[string][]spltest.MockFuncCall{
+			"Get":    {{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-indexer-2"}},
+			"Create": {{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-indexer-2"}},
+			"Delete": {},
+		}},
+	}
+	for _, test := range tests {
+		c := spltest.NewMockClient()
+		got, err := ApplyPVCRetentionPolicy(ctx, c, pvc, test.policy, "csi-snapclass")
+		if err != nil {
+			t.Errorf("ApplyPVCRetentionPolicy(%s) returned error: %v", test.policy, err)
+		}
+		if got != test.want {
+			t.Errorf("ApplyPVCRetentionPolicy(%s) = %t; want %t", test.policy, got, test.want)
+		}
+		c.CheckCalls(t, "TestApplyPVCRetentionPolicy", test.wantCalls)
+
+		if test.policy == enterpriseApi.PVCRetentionPolicySnapshot {
+			snapshot := c.Calls["Create"][0].Obj.(*unstructured.Unstructured)
+			if snapshot.GetKind() != "VolumeSnapshot" || snapshot.GetLabels()["app.kubernetes.io/instance"] != "splunk-stack1-indexer" {
+				t.Errorf("unexpected volume snapshot: %v", snapshot.Object)
+			}
+			source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+			className, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+			if source != pvc.GetName() || className != "csi-snapclass" {
+				t.Errorf("volume snapshot spec = %v; want source %s and class csi-snapclass", snapshot.Object["spec"], pvc.GetName())
+			}
+		}
+	}
+}
+
+func TestApplyPVCRetentionPolicySnapshotNotReady(t *testing.T) {
+	ctx := context.TODO()
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pvc-var-splunk-stack1-indexer-2",
+			Namespace: "test",
+		},
+	}
+	snapshot := &unstructured.Unstructured{}
+	snapshot.SetGroupVersionKind(VolumeSnapshotGroupVersionKind)
+	snapshot.SetName(GetVolumeSnapshotName(pvc))
+	snapshot.SetNamespace("test")
+	c := spltest.NewMockClient()
+	c.AddObject(pvc)
+	c.AddObject(snapshot)
+
+	// the claim is kept while its snapshot is not ready to use
+	for _, status := range []map[string]interface{}{
+		nil,
+		{"readyToUse": false},
+		{"readyToUse": false, "error": map[string]interface{}{"message": "failed to take snapshot"}},
+	} {
+		snapshot.Object["status"] = status
+		c.ResetCalls()
+		deleted, err := ApplyPVCRetentionPolicy(ctx, c, pvc, enterpriseApi.PVCRetentionPolicySnapshot, "csi-snapclass")
+		if err != nil || deleted {
+			t.Errorf("ApplyPVCRetentionPolicy() with snapshot status %v = %t, %v; want false, nil", status, deleted, err)
+		}
+		c.CheckCalls(t, "TestApplyPVCRetentionPolicySnapshotNotReady", map[string][]spltest.MockFuncCall{
+			"Get":    {{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-indexer-2"}},
+			"Create": {},
+			"Delete": {},
+		})
+	}
+
+	// and deleted once it is
+	snapshot.Object["status"] = map[string]interface{}{"readyToUse": true}
+	c.ResetCalls()
+	deleted, err := ApplyPVCRetentionPolicy(ctx, c, pvc, enterpriseApi.PVCRetentionPolicySnapshot, "csi-snapclass")
+	if err != nil || !deleted {
+		t.Errorf("ApplyPVCRetentionPolicy() with ready snapshot = %t, %v; want true, nil", deleted, err)
+	}
+	c.CheckCalls(t, "TestApplyPVCRetentionPolicySnapshotNotReady", map[string][]spltest.MockFuncCall{
+		"Get":    {{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-indexer-2"}},
+		"Create": {},
+		"Delete": {{MetaName: "*v1.PersistentVolumeClaim-test-pvc-var-splunk-stack1-indexer-2"}},
+	})
+}
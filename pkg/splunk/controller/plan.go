@@ -0,0 +1,349 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// PlanActionCreate is the action of an object that would be created
+	PlanActionCreate = "Create"
+
+	// PlanActionUpdate is the action of an object that would be updated
+	PlanActionUpdate = "Update"
+
+	// PlanActionPatch is the action of an object that would be patched
+	PlanActionPatch = "Patch"
+
+	// PlanActionDelete is the action of an object that would be deleted
+	PlanActionDelete = "Delete"
+
+	// maximum length of the values shown in the diffs
+	planValueMaxLength = 120
+)
+
+// PlannedChange is a change of a Kubernetes object that a reconcile would make
+type PlannedChange struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	Action string   `json:"action"`
+	Diff   []string `json:"diff,omitempty"`
+}
+
+// Plan reports the changes that a reconcile of a custom resource would make, and the pods it would restart
+type Plan struct {
+	Changes     []PlannedChange `json:"changes"`
+	RecyclePods []string        `json:"recyclePods,omitempty"`
+	RemovePods  []string        `json:"removePods,omitempty"`
+}
+
+// PlanClient is a ControllerClient that records the changes of objects instead of writing them. The objects it
+// would create or update are returned by later reads, so that the reconcile logic sees the planned state
+type PlanClient struct {
+	splcommon.ControllerClient
+	Plan Plan
+
+	objects map[string]client.Object
+	deleted map[string]bool
+}
+
+// NewPlanClient returns a PlanClient reading from c
+func NewPlanClient(c splcommon.ControllerClient) *PlanClient {
+	return &PlanClient{
+		ControllerClient: c,
+		Plan:             Plan{Changes: []PlannedChange{}},
+		objects:          map[string]client.Object{},
+		deleted:          map[string]bool{},
+	}
+}
+
+// getPlanObjectKind returns the kind of an object, typed objects usually have an empty TypeMeta
+func getPlanObjectKind(obj client.Object) string {
+	if kind := obj.GetObjectKind().GroupVersionKind().Kind; kind != "" {
+		return kind
+	}
+	return reflect.Indirect(reflect.ValueOf(obj)).Type().Name()
+}
+
+// getPlanObjectKey returns the key of an object in the planned state
+func getPlanObjectKey(kind string, key types.NamespacedName) string {
+	return fmt.Sprintf("%s/%s/%s", kind, key.Namespace, key.Name)
+}
+
+// Get returns the planned state of an object, or reads it if it would not be changed
+func (c *PlanClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	planKey := getPlanObjectKey(getPlanObjectKind(obj), key)
+	if c.deleted[planKey] {
+		return k8serrors.NewNotFound(schema.GroupResource{Resource: getPlanObjectKind(obj)}, key.Name)
+	}
+	if planned, ok := c.objects[planKey]; ok && reflect.TypeOf(planned) == reflect.TypeOf(obj) {
+		reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(planned.DeepCopyObject()).Elem())
+		return nil
+	}
+	return c.ControllerClient.Get(ctx, key, obj, opts...)
+}
+
+// Create records the creation of an object
+func (c *PlanClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.record(ctx, obj, PlanActionCreate, nil)
+	return nil
+}
+
+// Update records the update of an object along with the differences to its current state
+func (c *PlanClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	current := obj.DeepCopyObject().(client.Object)
+	err := c.Get(ctx, client.ObjectKeyFromObject(obj), current)
+	if err != nil {
+		return err
+	}
+	diff := DiffObjects(current, obj, isSecretObject(obj))
+	if len(diff) > 0 {
+		c.record(ctx, obj, PlanActionUpdate, diff)
+	}
+	return nil
+}
+
+// Patch records the patch of an object
+func (c *PlanClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	var diff []string
+	if !isSecretObject(obj) {
+		if data, err := patch.Data(obj); err == nil {
+			diff = []string{truncatePlanValue(string(data))}
+		}
+	}
+	c.record(ctx, obj, PlanActionPatch, diff)
+	return nil
+}
+
+// Delete records the deletion of an object
+func (c *PlanClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.record(ctx, obj, PlanActionDelete, nil)
+	return nil
+}
+
+// DeleteAllOf records the deletion of the objects of a kind
+func (c *PlanClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	c.record(ctx, obj, PlanActionDelete, nil)
+	return nil
+}
+
+// Status returns a StatusWriter that ignores all writes, the status of the objects is not part of a plan
+func (c *PlanClient) Status() client.StatusWriter {
+	return planStatusWriter{}
+}
+
+// record adds a change to the plan and keeps the planned state of the object
+func (c *PlanClient) record(ctx context.Context, obj client.Object, action string, diff []string) {
+	reqLogger := log.FromContext(ctx)
+	kind := getPlanObjectKind(obj)
+	reqLogger.WithName("PlanClient").Info("Planned change", "kind", kind, "name", obj.GetName(), "action", action)
+
+	c.Plan.Changes = append(c.Plan.Changes, PlannedChange{
+		Kind:   kind,
+		Name:   obj.GetName(),
+		Action: action,
+		Diff:   diff,
+	})
+
+	planKey := getPlanObjectKey(kind, client.ObjectKeyFromObject(obj))
+	switch action {
+	case PlanActionDelete:
+		delete(c.objects, planKey)
+		c.deleted[planKey] = true
+	case PlanActionCreate, PlanActionUpdate:
+		c.objects[planKey] = obj.DeepCopyObject().(client.Object)
+		delete(c.deleted, planKey)
+	}
+}
+
+// HasChanged returns true if the plan changes an object, below path when it is not empty
+func (c *PlanClient) HasChanged(kind, name, path string) bool {
+	for _, change := range c.Plan.Changes {
+		if change.Kind != kind || change.Name != name {
+			continue
+		}
+		if path == "" || change.Action != PlanActionUpdate {
+			return true
+		}
+		for _, diff := range change.Diff {
+			if len(diff) > len(path) && diff[:len(path)] == path {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// PlanStatefulSetPods adds the pods of a StatefulSet that a reconcile would recycle or remove to the plan. Pods are
+// recycled when the pod template changes or when they do not run the current revision yet
+func (c *PlanClient) PlanStatefulSetPods(ctx context.Context, statefulSet *appsv1.StatefulSet, desiredReplicas int32) error {
+	current := &appsv1.StatefulSet{}
+	err := c.ControllerClient.Get(ctx, client.ObjectKeyFromObject(statefulSet), current)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	templateChanged := c.HasChanged(getPlanObjectKind(statefulSet), statefulSet.GetName(), "spec.template")
+	var replicas int32
+	if current.Spec.Replicas != nil {
+		replicas = *current.Spec.Replicas
+	}
+	for n := int32(0); n < replicas; n++ {
+		podName := fmt.Sprintf("%s-%d", current.GetName(), n)
+		if n >= desiredReplicas {
+			c.Plan.RemovePods = append(c.Plan.RemovePods, podName)
+			continue
+		}
+
+		pod := &corev1.Pod{}
+		err = c.ControllerClient.Get(ctx, types.NamespacedName{Namespace: current.GetNamespace(), Name: podName}, pod)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		outdated := current.Status.UpdateRevision != "" && pod.GetLabels()["controller-revision-hash"] != current.Status.UpdateRevision
+		if templateChanged || outdated {
+			c.Plan.RecyclePods = append(c.Plan.RecyclePods, podName)
+		}
+	}
+	return nil
+}
+
+// planStatusWriter ignores the status updates made while planning
+type planStatusWriter struct{}
+
+// Update ignores a status update
+func (planStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return nil
+}
+
+// Patch ignores a status patch
+func (planStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return nil
+}
+
+// isSecretObject returns true for Secrets, the values of which are never shown in a plan
+func isSecretObject(obj client.Object) bool {
+	_, ok := obj.(*corev1.Secret)
+	return ok || getPlanObjectKind(obj) == "Secret"
+}
+
+// DiffObjects returns the differences between the current and the revised state of an object, one per changed field
+// path. The metadata maintained by the API server and the status are ignored. Values are redacted if redact is true
+func DiffObjects(current, revised interface{}, redact bool) []string {
+	toMap := func(obj interface{}) map[string]interface{} {
+		result := map[string]interface{}{}
+		data, err := json.Marshal(obj)
+		if err == nil {
+			_ = json.Unmarshal(data, &result)
+		}
+		delete(result, "status")
+		if metadata, ok := result["metadata"].(map[string]interface{}); ok {
+			for _, field := range []string{"resourceVersion", "managedFields", "generation", "creationTimestamp", "uid"} {
+				delete(metadata, field)
+			}
+		}
+		return result
+	}
+
+	diff := []string{}
+	diffPlanValues("", toMap(current), toMap(revised), redact, &diff)
+	return diff
+}
+
+// diffPlanValues adds the differences between two decoded JSON values to diff
+func diffPlanValues(path string, current, revised interface{}, redact bool, diff *[]string) {
+	if !splcommon.CompareByMarshall(current, revised) {
+		return
+	}
+
+	currentMap, currentIsMap := current.(map[string]interface{})
+	revisedMap, revisedIsMap := revised.(map[string]interface{})
+	if currentIsMap && revisedIsMap {
+		keys := []string{}
+		for key := range currentMap {
+			keys = append(keys, key)
+		}
+		for key := range revisedMap {
+			if _, ok := currentMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			diffPlanValues(childPath, currentMap[key], revisedMap[key], redact, diff)
+		}
+		return
+	}
+
+	currentList, currentIsList := current.([]interface{})
+	revisedList, revisedIsList := revised.([]interface{})
+	if currentIsList && revisedIsList && len(currentList) == len(revisedList) {
+		for i := range currentList {
+			diffPlanValues(fmt.Sprintf("%s[%d]", path, i), currentList[i], revisedList[i], redact, diff)
+		}
+		return
+	}
+
+	if redact {
+		*diff = append(*diff, fmt.Sprintf("%s: changed", path))
+		return
+	}
+	*diff = append(*diff, fmt.Sprintf("%s: %s -> %s", path, formatPlanValue(current), formatPlanValue(revised)))
+}
+
+// formatPlanValue returns the compact JSON of a decoded value
+func formatPlanValue(value interface{}) string {
+	if value == nil {
+		return "<none>"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return truncatePlanValue(string(data))
+}
+
+// truncatePlanValue shortens long values shown in a plan
+func truncatePlanValue(value string) string {
+	if len(value) > planValueMaxLength {
+		return value[:planValueMaxLength] + "..."
+	}
+	return value
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+)
+
+func TestDiffObjects(t *testing.T) {
+	current := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "defaults",
+			Namespace:       "test",
+			ResourceVersion: "10",
+		},
+		Data: map[string]string{"a": "1", "b": "2"},
+	}
+	revised := current.DeepCopy()
+	revised.ResourceVersion = "11"
+	revised.Data["a"] = "3"
+	revised.Data["c"] = "4"
+	delete(revised.Data, "b")
+
+	want := []string{"data.a: \"1\" -> \"3\"", "data.b: \"2\" -> <none>", "data.c: <none> -> \"4\""}
+	got := DiffObjects(current, revised, false)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffObjects() = %v; want %v", got, want)
+	}
+
+	want = []string{"data.a: changed", "data.b: changed", "data.c: changed"}
+	got = DiffObjects(current, revised, true)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffObjects() redacted = %v; want %v", got, want)
+	}
+
+	if got = DiffObjects(current, current.DeepCopy(), false); len(got) != 0 {
+		t.Errorf("DiffObjects() of equal objects = %v; want none", got)
+	}
+
+	revised = current.DeepCopy()
+	revised.Data["a"] = strings.Repeat("x", 2*planValueMaxLength)
+	got = DiffObjects(current, revised, false)
+	if len(got) != 1 || !strings.HasSuffix(got[0], "...") {
+		t.Errorf("DiffObjects() long value = %v; want it truncated", got)
+	}
+}
+
+func TestPlanClient(t *testing.T) {
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	current := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "defaults",
+			Namespace: "test",
+		},
+		Data: map[string]string{"a": "1"},
+	}
+	c.AddObject(current)
+	planClient := NewPlanClient(c)
+
+	// creates are recorded and visible to later reads
+	created := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "created",
+			Namespace: "test",
+		},
+		Data: map[string]string{"b": "2"},
+	}
+	err := planClient.Create(ctx, created)
+	if err != nil {
+		t.Errorf("Create() returned error: %v", err)
+	}
+	got := &corev1.ConfigMap{}
+	err = planClient.Get(ctx, types.NamespacedName{Namespace: "test", Name: "created"}, got)
+	if err != nil || got.Data["b"] != "2" {
+		t.Errorf("Get() of planned object = %v, %v; want planned state", got.Data, err)
+	}
+
+	// updates without differences are not recorded
+	err = planClient.Update(ctx, current.DeepCopy())
+	if err != nil {
+		t.Errorf("Update() returned error: %v", err)
+	}
+	if len(planClient.Plan.Changes) != 1 {
+		t.Errorf("Update() without changes recorded %v", planClient.Plan.Changes)
+	}
+
+	revised := current.DeepCopy()
+	revised.Data["a"] = "2"
+	err = planClient.Update(ctx, revised)
+	if err != nil {
+		t.Errorf("Update() returned error: %v", err)
+	}
+	want := PlannedChange{Kind: "ConfigMap", Name: "defaults", Action: PlanActionUpdate, Diff: []string{"data.a: \"1\" -> \"2\""}}
+	if len(planClient.Plan.Changes) != 2 || !reflect.DeepEqual(planClient.Plan.Changes[1], want) {
+		t.Errorf("Update() recorded %v; want %v", planClient.Plan.Changes, want)
+	}
+	if !planClient.HasChanged("ConfigMap", "defaults", "data") || planClient.HasChanged("ConfigMap", "defaults", "metadata") {
+		t.Errorf("HasChanged() does not match the recorded diff")
+	}
+
+	// deletes hide the object from later reads
+	err = planClient.Delete(ctx, current)
+	if err != nil {
+		t.Errorf("Delete() returned error: %v", err)
+	}
+	err = planClient.Get(ctx, types.NamespacedName{Namespace: "test", Name: "defaults"}, &corev1.ConfigMap{})
+	if !k8serrors.IsNotFound(err) {
+		t.Errorf("Get() of deleted object returned %v; want NotFound", err)
+	}
+
+	// secret values are never shown
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: "test",
+		},
+		Data: map[string][]byte{"password": []byte("old")},
+	}
+	c.AddObject(secret)
+	revisedSecret := secret.DeepCopy()
+	revisedSecret.Data["password"] = []byte("new")
+	err = planClient.Update(ctx, revisedSecret)
+	if err != nil {
+		t.Errorf("Update() returned error: %v", err)
+	}
+	last := planClient.Plan.Changes[len(planClient.Plan.Changes)-1]
+	if !reflect.DeepEqual(last.Diff, []string{"data.password: changed"}) {
+		t.Errorf("Update() of secret recorded %v; want redacted diff", last.Diff)
+	}
+
+	// nothing reaches the underlying client
+	for _, method := range []string{"Create", "Update", "Delete", "Patch"} {
+		if len(c.Calls[method]) != 0 {
+			t.Errorf("PlanClient called %s on the underlying client", method)
+		}
+	}
+	if planClient.Status().Update(ctx, secret) != nil {
+		t.Errorf("Status().Update() returned error")
+	}
+}
+
+func TestPlanStatefulSetPods(t *testing.T) {
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	var replicas int32 = 3
+	current := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1-indexer",
+			Namespace: "test",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+		},
+		Status: appsv1.StatefulSetStatus{
+			UpdateRevision: "v2",
+		},
+	}
+	c.AddObject(current)
+	for n, revision := range []string{"v2", "v1", "v2"} {
+		c.AddObject(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("%s-%d", current.GetName(), n),
+				Namespace: "test",
+				Labels:    map[string]string{"controller-revision-hash": revision},
+			},
+		})
+	}
+
+	// outdated pods are recycled and pods above the desired replicas are removed
+	planClient := NewPlanClient(c)
+	err := planClient.PlanStatefulSetPods(ctx, current, 2)
+	if err != nil {
+		t.Errorf("PlanStatefulSetPods() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(planClient.Plan.RecyclePods, []string{"splunk-stack1-indexer-1"}) {
+		t.Errorf("PlanStatefulSetPods() recycles %v; want [splunk-stack1-indexer-1]", planClient.Plan.RecyclePods)
+	}
+	if !reflect.DeepEqual(planClient.Plan.RemovePods, []string{"splunk-stack1-indexer-2"}) {
+		t.Errorf("PlanStatefulSetPods() removes %v; want [splunk-stack1-indexer-2]", planClient.Plan.RemovePods)
+	}
+
+	// all pods are recycled when the pod template changes
+	planClient = NewPlanClient(c)
+	revised := current.DeepCopy()
+	revised.Spec.Template.Spec.ServiceAccountName = "sa"
+	err = planClient.Update(ctx, revised)
+	if err != nil {
+		t.Errorf("Update() returned error: %v", err)
+	}
+	err = planClient.PlanStatefulSetPods(ctx, revised, 3)
+	if err != nil {
+		t.Errorf("PlanStatefulSetPods() returned error: %v", err)
+	}
+	if len(planClient.Plan.RecyclePods) != 3 || len(planClient.Plan.RemovePods) != 0 {
+		t.Errorf("PlanStatefulSetPods() recycles %v and removes %v; want all pods recycled", planClient.Plan.RecyclePods, planClient.Plan.RemovePods)
+	}
+}
@@ -26,11 +26,15 @@ import (
 	"time"
 
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 )
 
 // ApplySecret creates or updates a Kubernetes Secret, and returns active secrets if successful
 func ApplySecret(ctx context.Context, client splcommon.ControllerClient, secret *corev1.Secret) (*corev1.Secret, error) {
+	ctx, span := tracing.Start(ctx, "ApplySecret")
+	defer span.End()
+
 	// Invalid secret object
 	if secret == nil {
 		return nil, errors.New(splcommon.InvalidSecretObjectError)
@@ -19,6 +19,7 @@ import (
 	"context"
 
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
@@ -28,6 +29,9 @@ import (
 
 // ApplyService creates or updates a Kubernetes Service
 func ApplyService(ctx context.Context, client splcommon.ControllerClient, revised *corev1.Service) error {
+	ctx, span := tracing.Start(ctx, "ApplyService")
+	defer span.End()
+
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("ApplyService").WithValues(
 		"name", revised.GetObjectMeta().GetName(),
@@ -43,8 +47,12 @@ func ApplyService(ctx context.Context, client splcommon.ControllerClient, revise
 		return err
 	}
 
-	// check for changes in service template
+	// check for changes in service template, labels and annotations set by others (e.g. cloud controllers) are kept
 	hasUpdates := MergeServiceSpecUpdates(ctx, &current.Spec, &revised.Spec, current.GetObjectMeta().GetName())
+	if mergeObjectMetaUpdates(&current.ObjectMeta, &revised.ObjectMeta) {
+		scopedLog.Info("Service labels or annotations differ")
+		hasUpdates = true
+	}
 	*revised = current // caller expects that object passed represents latest state
 
 	// only update if there are material differences, as determined by comparison function
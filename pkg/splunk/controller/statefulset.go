@@ -17,16 +17,25 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
 
+	"github.com/splunk/splunk-operator/pkg/config"
+	"github.com/splunk/splunk-operator/pkg/splunk/audit"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -34,11 +43,14 @@ import (
 )
 
 // DefaultStatefulSetPodManager is a simple StatefulSetPodManager that does nothing
-type DefaultStatefulSetPodManager struct{}
+type DefaultStatefulSetPodManager struct {
+	// VolumeExpansion tracks the online expansion of the persistent volumes, if set
+	VolumeExpansion *enterpriseApi.VolumeExpansionStatus
+}
 
 // Update for DefaultStatefulSetPodManager handles all updates for a statefulset of standard pods
 func (mgr *DefaultStatefulSetPodManager) Update(ctx context.Context, client splcommon.ControllerClient, statefulSet *appsv1.StatefulSet, desiredReplicas int32) (enterpriseApi.Phase, error) {
-	phase, err := ApplyStatefulSet(ctx, client, statefulSet)
+	phase, err := ApplyStatefulSetWithVolumeExpansion(ctx, client, statefulSet, mgr.VolumeExpansion)
 	if err == nil && phase == enterpriseApi.PhaseReady {
 		phase, err = UpdateStatefulSetPods(ctx, client, statefulSet, mgr, desiredReplicas)
 	}
@@ -62,6 +74,16 @@ func (mgr *DefaultStatefulSetPodManager) FinishRecycle(ctx context.Context, n in
 
 // ApplyStatefulSet creates or updates a Kubernetes StatefulSet
 func ApplyStatefulSet(ctx context.Context, c splcommon.ControllerClient, revised *appsv1.StatefulSet) (enterpriseApi.Phase, error) {
+	return ApplyStatefulSetWithVolumeExpansion(ctx, c, revised, nil)
+}
+
+// ApplyStatefulSetWithVolumeExpansion creates or updates a Kubernetes StatefulSet. Raising the storage capacity
+// of a volume claim template expands the persistent volumes of the existing pods online, the progress of the
+// expansion is tracked in status (optional)
+func ApplyStatefulSetWithVolumeExpansion(ctx context.Context, c splcommon.ControllerClient, revised *appsv1.StatefulSet, status *enterpriseApi.VolumeExpansionStatus) (enterpriseApi.Phase, error) {
+	ctx, span := tracing.Start(ctx, "ApplyStatefulSet")
+	defer span.End()
+
 	namespacedName := types.NamespacedName{Namespace: revised.GetNamespace(), Name: revised.GetName()}
 	var current appsv1.StatefulSet
 
@@ -79,13 +101,43 @@ func ApplyStatefulSet(ctx context.Context, c splcommon.ControllerClient, revised
 
 		// no StatefulSet exists -> just create a new one
 		err = splutil.CreateResource(ctx, c, revised)
+		if err == nil && status != nil && status.Phase == enterpriseApi.VolumeExpansionRecreating {
+			// the StatefulSet was deleted after expanding its volumes, the orphaned pods are adopted again
+			status.Phase = enterpriseApi.VolumeExpansionCompleted
+			status.Message = ""
+		}
 		return enterpriseApi.PhasePending, err
 	}
 
 	// found an existing StatefulSet
 
+	// volume claim templates are immutable, expand the existing volumes before applying any other change
+	expanding, err := ExpandStatefulSetVolumes(ctx, c, &current, revised, status)
+	if err != nil || expanding {
+		*revised = current
+		return enterpriseApi.PhaseUpdating, err
+	}
+
+	// recreate the StatefulSet when volume claim templates were added or removed, leaving its pods behind. The pods are
+	// recycled afterwards, as their template changed as well
+	if CompareVolumeClaimTemplates(ctx, current.Spec.VolumeClaimTemplates, revised.Spec.VolumeClaimTemplates, current.GetName()) {
+		if status != nil {
+			status.Phase = enterpriseApi.VolumeExpansionRecreating
+			status.Message = "recreating the StatefulSet with the added or removed volume claim templates"
+		}
+		err = c.Delete(ctx, &current, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+		*revised = current
+		return enterpriseApi.PhaseUpdating, err
+	}
+
 	// check for changes in Pod template
 	hasUpdates := MergePodUpdates(ctx, &current.Spec.Template, &revised.Spec.Template, current.GetObjectMeta().GetName())
+	if MergePVCRetentionPolicyUpdates(&current, revised) {
+		hasUpdates = true
+	}
+	if mergeStatefulSetAnnotationUpdates(&current, revised, enterpriseApi.MaintenanceWindowsAnnotation, enterpriseApi.DisruptionPriorityLabel, enterpriseApi.RestartPodsAnnotation) {
+		hasUpdates = true
+	}
 	*revised = current // caller expects that object passed represents latest state
 
 	// only update if there are material differences, as determined by comparison function
@@ -107,13 +159,308 @@ func ApplyStatefulSet(ctx context.Context, c splcommon.ControllerClient, revised
 	return enterpriseApi.PhaseReady, nil
 }
 
+// MergePVCRetentionPolicyUpdates copies the PVC retention policy of a revised StatefulSet into the current one.
+// Returns true if there are material differences between them
+func MergePVCRetentionPolicyUpdates(current, revised *appsv1.StatefulSet) bool {
+	result := mergeStatefulSetAnnotationUpdates(current, revised, enterpriseApi.PVCRetentionWhenScaledAnnotation, enterpriseApi.VolumeSnapshotClassAnnotation)
+
+	// the API server drops the policy when the StatefulSetAutoDeletePVC feature is not enabled and defaults it otherwise,
+	// so only a policy that was accepted before gets updated
+	if current.Spec.PersistentVolumeClaimRetentionPolicy != nil {
+		policy := revised.Spec.PersistentVolumeClaimRetentionPolicy
+		if policy == nil {
+			policy = &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+				WhenDeleted: appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+				WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+			}
+		}
+		if !reflect.DeepEqual(current.Spec.PersistentVolumeClaimRetentionPolicy, policy) {
+			current.Spec.PersistentVolumeClaimRetentionPolicy = policy
+			result = true
+		}
+	}
+
+	return result
+}
+
+// mergeStatefulSetAnnotationUpdates copies the annotations of a revised StatefulSet with the given keys into the
+// current one. Returns true if there are differences between them
+func mergeStatefulSetAnnotationUpdates(current, revised *appsv1.StatefulSet, keys ...string) bool {
+	result := false
+
+	annotations := current.GetAnnotations()
+	for _, key := range keys {
+		revisedValue, ok := revised.GetAnnotations()[key]
+		if annotations[key] == revisedValue {
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		if ok {
+			annotations[key] = revisedValue
+		} else {
+			delete(annotations, key)
+		}
+		result = true
+	}
+	current.SetAnnotations(annotations)
+	return result
+}
+
+// IsVolumeExpansionInProgress returns true while the persistent volumes of a StatefulSet are being expanded
+func IsVolumeExpansionInProgress(status *enterpriseApi.VolumeExpansionStatus) bool {
+	switch status.Phase {
+	case enterpriseApi.VolumeExpansionExpanding, enterpriseApi.VolumeExpansionResizing, enterpriseApi.VolumeExpansionRecreating:
+		return true
+	}
+	return false
+}
+
+// ExpandStatefulSetVolumes expands the persistent volumes of a StatefulSet whose revised volume claim templates
+// request more storage than the current ones. The volume claims are expanded one pod at a time, waiting for the
+// file systems to be resized before moving on to the next pod. Once all volumes are resized, the StatefulSet is
+// deleted leaving its pods behind, so that it gets recreated with the revised volume claim templates.
+// Returns true while the expansion is in progress.
+func ExpandStatefulSetVolumes(ctx context.Context, c splcommon.ControllerClient, current, revised *appsv1.StatefulSet, status *enterpriseApi.VolumeExpansionStatus) (bool, error) {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("ExpandStatefulSetVolumes").WithValues(
+		"name", current.GetName(),
+		"namespace", current.GetNamespace())
+
+	if status == nil {
+		status = &enterpriseApi.VolumeExpansionStatus{}
+	}
+
+	capacity := getExpandedVolumeClaimCapacity(current, revised)
+	if len(capacity) == 0 {
+		if status.Phase == enterpriseApi.VolumeExpansionUnsupported {
+			// the storage capacity was reverted
+			*status = enterpriseApi.VolumeExpansionStatus{}
+		}
+		return false, nil
+	}
+
+	templateNames := make([]string, 0, len(capacity))
+	status.Capacity = make(map[string]string, len(capacity))
+	for name, quantity := range capacity {
+		templateNames = append(templateNames, name)
+		status.Capacity[name] = quantity.String()
+	}
+	sort.Strings(templateNames)
+
+	var replicas int32
+	if current.Spec.Replicas != nil {
+		replicas = *current.Spec.Replicas
+	}
+
+	for n := int32(0); n < replicas; n++ {
+		expanded, resized := false, true
+		for _, templateName := range templateNames {
+			desired := capacity[templateName]
+			var pvc corev1.PersistentVolumeClaim
+			namespacedName := types.NamespacedName{Namespace: current.GetNamespace(), Name: fmt.Sprintf("%s-%s-%d", templateName, current.GetName(), n)}
+			err := c.Get(ctx, namespacedName, &pvc)
+			if err != nil {
+				if k8serrors.IsNotFound(err) {
+					// the volume gets created from the revised template along with the pod
+					continue
+				}
+				return true, err
+			}
+
+			request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			if request.Cmp(desired) < 0 {
+				allowed, err := isVolumeExpansionAllowed(ctx, c, &pvc)
+				if err != nil {
+					return true, err
+				}
+				if !allowed {
+					status.Phase = enterpriseApi.VolumeExpansionUnsupported
+					status.Message = fmt.Sprintf("storage class of %s does not allow volume expansion", pvc.GetName())
+					scopedLog.Info("Unable to expand persistent volume", "pvc", pvc.GetName())
+					return false, nil
+				}
+
+				scopedLog.Info("Expanding persistent volume", "pvc", pvc.GetName(), "capacity", desired.String())
+				if pvc.Spec.Resources.Requests == nil {
+					pvc.Spec.Resources.Requests = corev1.ResourceList{}
+				}
+				pvc.Spec.Resources.Requests[corev1.ResourceStorage] = desired
+				err = splutil.UpdateResource(ctx, c, &pvc)
+				if isVolumeExpansionRejected(err) {
+					status.Phase = enterpriseApi.VolumeExpansionUnsupported
+					status.Message = fmt.Sprintf("expansion of %s was rejected: %v", pvc.GetName(), err)
+					scopedLog.Info("Unable to expand persistent volume", "pvc", pvc.GetName(), "error", err.Error())
+					return false, nil
+				}
+				if err != nil {
+					return true, err
+				}
+				expanded, resized = true, false
+				status.Message = fmt.Sprintf("expanding %s to %s", pvc.GetName(), desired.String())
+			} else if !isVolumeClaimResized(&pvc, desired) {
+				resized = false
+				status.Message = fmt.Sprintf("waiting for %s to be resized to %s", pvc.GetName(), desired.String())
+			}
+		}
+
+		if !resized {
+			status.ResizedPods = n
+			if expanded {
+				status.Phase = enterpriseApi.VolumeExpansionExpanding
+			} else {
+				status.Phase = enterpriseApi.VolumeExpansionResizing
+			}
+			return true, nil
+		}
+	}
+
+	// all volumes were resized, recreate the StatefulSet with the revised volume claim templates
+	scopedLog.Info("Recreating StatefulSet with expanded volume claim templates")
+	status.ResizedPods = replicas
+	status.Phase = enterpriseApi.VolumeExpansionRecreating
+	status.Message = "recreating the StatefulSet with the expanded volume claim templates"
+	err := c.Delete(ctx, current, client.PropagationPolicy(metav1.DeletePropagationOrphan))
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return true, err
+	}
+	return true, nil
+}
+
+// getExpandedVolumeClaimCapacity returns the storage capacity of the revised volume claim templates requesting
+// more storage than the current ones, keyed by the name of the template
+func getExpandedVolumeClaimCapacity(current, revised *appsv1.StatefulSet) map[string]resource.Quantity {
+	capacity := make(map[string]resource.Quantity)
+	for i := range revised.Spec.VolumeClaimTemplates {
+		revisedTemplate := &revised.Spec.VolumeClaimTemplates[i]
+		for j := range current.Spec.VolumeClaimTemplates {
+			currentTemplate := &current.Spec.VolumeClaimTemplates[j]
+			if currentTemplate.GetName() != revisedTemplate.GetName() {
+				continue
+			}
+			revisedRequest, ok := revisedTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+			currentRequest := currentTemplate.Spec.Resources.Requests[corev1.ResourceStorage]
+			if ok && revisedRequest.Cmp(currentRequest) > 0 {
+				capacity[revisedTemplate.GetName()] = revisedRequest
+			}
+		}
+	}
+	return capacity
+}
+
+// applyScaledDownPVCRetentionPolicy applies the whenScaled PVC retention policy of a StatefulSet to the PVCs of a
+// scaled down pod. It returns false while some PVCs are kept until their snapshots are ready. With snapshotted set,
+// only the PVCs whose snapshot was already taken are handled.
+func applyScaledDownPVCRetentionPolicy(ctx context.Context, c splcommon.ControllerClient, statefulSet *appsv1.StatefulSet, podName string, snapshotted bool) (bool, error) {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("applyScaledDownPVCRetentionPolicy").WithValues(
+		"name", statefulSet.GetObjectMeta().GetName(),
+		"namespace", statefulSet.GetObjectMeta().GetNamespace())
+
+	policy := enterpriseApi.PVCRetentionPolicyType(statefulSet.GetAnnotations()[enterpriseApi.PVCRetentionWhenScaledAnnotation])
+	if policy == enterpriseApi.PVCRetentionPolicyRetain {
+		scopedLog.Info("Retaining PVCs of scaled down Pod", "podName", podName)
+		return true, nil
+	}
+	snapshotClassName := statefulSet.GetAnnotations()[enterpriseApi.VolumeSnapshotClassAnnotation]
+	done := true
+	for _, vol := range statefulSet.Spec.VolumeClaimTemplates {
+		namespacedName := types.NamespacedName{
+			Namespace: vol.ObjectMeta.Namespace,
+			Name:      fmt.Sprintf("%s-%s", vol.ObjectMeta.Name, podName),
+		}
+		var pvc corev1.PersistentVolumeClaim
+		err := c.Get(ctx, namespacedName, &pvc)
+		if k8serrors.IsNotFound(err) {
+			// already deleted by the StatefulSet controller, or once its snapshot was ready
+			continue
+		}
+		if err != nil {
+			scopedLog.Error(err, "Unable to find PVC for deletion", "pvcName", namespacedName.Name)
+			return false, err
+		}
+		if snapshotted {
+			_, err = getVolumeSnapshot(ctx, c, &pvc)
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return false, err
+			}
+		}
+		deleted, err := ApplyPVCRetentionPolicy(ctx, c, &pvc, policy, snapshotClassName)
+		if err != nil {
+			scopedLog.Error(err, "Unable to delete PVC", "pvcName", pvc.ObjectMeta.Name)
+			return false, err
+		}
+		done = done && deleted
+	}
+	return done, nil
+}
+
+// isVolumeExpansionAllowed checks whether the storage class of a persistent volume claim allows volume expansion
+func isVolumeExpansionAllowed(ctx context.Context, c splcommon.ControllerClient, pvc *corev1.PersistentVolumeClaim) (bool, error) {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		// statically provisioned volumes can not be expanded
+		return false, nil
+	}
+
+	if len(config.GetWatchNamespaces()) > 0 {
+		// storage classes are cluster scoped, they are not cached by an operator watching some namespaces only
+		return true, nil
+	}
+
+	var storageClass storagev1.StorageClass
+	err := c.Get(ctx, types.NamespacedName{Name: *pvc.Spec.StorageClassName}, &storageClass)
+	if err != nil {
+		if k8serrors.IsForbidden(err) {
+			// storage classes are cluster scoped, let the API server decide when they can't be read
+			return true, nil
+		}
+		return false, err
+	}
+	return storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion, nil
+}
+
+// volumeExpansionRejectedMessage is the message of the PersistentVolumeClaimResize admission plugin
+// when the storage class of a claim does not allow its expansion
+const volumeExpansionRejectedMessage = "only dynamically provisioned pvc can be resized and the storageclass that provisions the pvc must support resize"
+
+// isVolumeExpansionRejected checks whether the API server rejected the expansion of a persistent volume claim
+// because its storage class does not allow it; other forbidden errors, such as RBAC denials, are real errors
+func isVolumeExpansionRejected(err error) bool {
+	return k8serrors.IsForbidden(err) && strings.Contains(err.Error(), volumeExpansionRejectedMessage)
+}
+
+// isVolumeClaimResized checks whether a persistent volume claim reached the desired capacity, including its file system
+func isVolumeClaimResized(pvc *corev1.PersistentVolumeClaim, desired resource.Quantity) bool {
+	capacity := pvc.Status.Capacity[corev1.ResourceStorage]
+	if capacity.Cmp(desired) < 0 {
+		return false
+	}
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Status == corev1.ConditionTrue &&
+			(condition.Type == corev1.PersistentVolumeClaimResizing || condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending) {
+			return false
+		}
+	}
+	return true
+}
+
 // UpdateStatefulSetPods manages scaling and config updates for StatefulSets
 func UpdateStatefulSetPods(ctx context.Context, c splcommon.ControllerClient, statefulSet *appsv1.StatefulSet, mgr splcommon.StatefulSetPodManager, desiredReplicas int32) (enterpriseApi.Phase, error) {
+	ctx, span := tracing.Start(ctx, "UpdateStatefulSetPods")
+	defer span.End()
+
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("UpdateStatefulSetPods").WithValues(
 		"name", statefulSet.GetObjectMeta().GetName(),
 		"namespace", statefulSet.GetObjectMeta().GetNamespace())
 
+	// keep the part of the disruption budget held by a disruptive operation in progress
+	renewDisruptionBudget(statefulSet)
+
 	// wait for all replicas ready
 	replicas := *statefulSet.Spec.Replicas
 	readyReplicas := statefulSet.Status.ReadyReplicas
@@ -130,6 +477,19 @@ func UpdateStatefulSetPods(ctx context.Context, c splcommon.ControllerClient, st
 
 	// readyReplicas == replicas
 
+	// the PVCs of the last scaled down pod are kept until their snapshots are ready, before scaling again
+	if statefulSet.GetAnnotations()[enterpriseApi.PVCRetentionWhenScaledAnnotation] == string(enterpriseApi.PVCRetentionPolicySnapshot) {
+		podName := fmt.Sprintf("%s-%d", statefulSet.GetName(), replicas)
+		done, err := applyScaledDownPVCRetentionPolicy(ctx, c, statefulSet, podName, true)
+		if err != nil {
+			return enterpriseApi.PhaseError, err
+		}
+		if !done {
+			scopedLog.Info("Waiting for the volume snapshots of scaled down Pod", "podName", podName)
+			return enterpriseApi.PhaseScalingDown, nil
+		}
+	}
+
 	// check for scaling up
 	if readyReplicas < desiredReplicas {
 		// scale up StatefulSet to match desiredReplicas
@@ -143,6 +503,13 @@ func UpdateStatefulSetPods(ctx context.Context, c splcommon.ControllerClient, st
 		// prepare pod for removal via scale down
 		n := readyReplicas - 1
 		podName := fmt.Sprintf("%s-%d", statefulSet.GetName(), n)
+
+		// scale downs decommission the pods, so they wait for a maintenance window and the disruption budget
+		allowed, phase, err := startPodDisruption(ctx, c, statefulSet, podName, enterpriseApi.PhaseScalingDown)
+		if err != nil || !allowed {
+			return phase, err
+		}
+
 		ready, err := mgr.PrepareScaleDown(ctx, n)
 		if err != nil {
 			scopedLog.Error(err, "Unable to decommission Pod", "podName", podName)
@@ -157,29 +524,16 @@ func UpdateStatefulSetPods(ctx context.Context, c splcommon.ControllerClient, st
 		scopedLog.Info("Scaling replicas down", "replicas", n)
 		*statefulSet.Spec.Replicas = n
 		err = splutil.UpdateResource(ctx, c, statefulSet)
+		audit.Log(ctx, audit.PodRemoved, podName, fmt.Sprintf("scale down to %d replicas", n), err)
 		if err != nil {
 			scopedLog.Error(err, "Scale down update failed for StatefulSet")
 			return enterpriseApi.PhaseError, err
 		}
 
-		// delete PVCs used by the pod so that a future scale up will have clean state
-		for _, vol := range statefulSet.Spec.VolumeClaimTemplates {
-			namespacedName := types.NamespacedName{
-				Namespace: vol.ObjectMeta.Namespace,
-				Name:      fmt.Sprintf("%s-%s", vol.ObjectMeta.Name, podName),
-			}
-			var pvc corev1.PersistentVolumeClaim
-			err := c.Get(ctx, namespacedName, &pvc)
-			if err != nil {
-				scopedLog.Error(err, "Unable to find PVC for deletion", "pvcName", pvc.ObjectMeta.Name)
-				return enterpriseApi.PhaseError, err
-			}
-			scopedLog.Info("Deleting PVC", "pvcName", pvc.ObjectMeta.Name)
-			err = c.Delete(ctx, &pvc)
-			if err != nil {
-				scopedLog.Error(err, "Unable to delete PVC", "pvcName", pvc.ObjectMeta.Name)
-				return enterpriseApi.PhaseError, err
-			}
+		// delete PVCs used by the pod so that a future scale up will have clean state, unless they should be retained
+		_, err = applyScaledDownPVCRetentionPolicy(ctx, c, statefulSet, podName, false)
+		if err != nil {
+			return enterpriseApi.PhaseError, err
 		}
 
 		return enterpriseApi.PhaseScalingDown, nil
@@ -204,8 +558,14 @@ func UpdateStatefulSetPods(ctx context.Context, c splcommon.ControllerClient, st
 			return enterpriseApi.PhaseUpdating, err
 		}
 
-		// terminate pod if it has pending updates; k8s will start a new one with revised template
-		if statefulSet.Status.UpdateRevision != "" && statefulSet.Status.UpdateRevision != pod.GetLabels()["controller-revision-hash"] {
+		// terminate pod if it has pending updates or a restart was requested; k8s will start a new one with revised template
+		restartRequested := isPodRestartRequested(ctx, statefulSet, &pod)
+		if restartRequested || (statefulSet.Status.UpdateRevision != "" && statefulSet.Status.UpdateRevision != pod.GetLabels()["controller-revision-hash"]) {
+			allowed, phase, err := startPodDisruption(ctx, c, statefulSet, podName, enterpriseApi.PhaseUpdating)
+			if err != nil || !allowed {
+				return phase, err
+			}
+
 			// pod needs to be updated; first, prepare it to be recycled
 			ready, err := mgr.PrepareRecycle(ctx, n)
 			if err != nil {
@@ -220,9 +580,15 @@ func UpdateStatefulSetPods(ctx context.Context, c splcommon.ControllerClient, st
 			// deleting pod will cause StatefulSet controller to create a new one with latest template
 			scopedLog.Info("Recycling Pod for updates", "podName", podName,
 				"statefulSetRevision", statefulSet.Status.UpdateRevision,
-				"podRevision", pod.GetLabels()["controller-revision-hash"])
+				"podRevision", pod.GetLabels()["controller-revision-hash"],
+				"restartRequested", restartRequested)
 			preconditions := client.Preconditions{UID: &pod.ObjectMeta.UID, ResourceVersion: &pod.ObjectMeta.ResourceVersion}
 			err = c.Delete(context.Background(), &pod, preconditions)
+			reason := fmt.Sprintf("update to revision %s", statefulSet.Status.UpdateRevision)
+			if restartRequested {
+				reason = "restart requested"
+			}
+			audit.Log(ctx, audit.PodDeleted, podName, reason, err)
 			if err != nil {
 				scopedLog.Error(err, "Unable to delete Pod", "podName", podName)
 				return enterpriseApi.PhaseError, err
@@ -244,6 +610,18 @@ func UpdateStatefulSetPods(ctx context.Context, c splcommon.ControllerClient, st
 		}
 	}
 
+	// all disruptive operations completed
+	ReleaseDisruptionBudget(statefulSet)
+	if _, ok := statefulSet.GetAnnotations()[enterpriseApi.MaintenanceInProgressAnnotation]; ok {
+		annotations := statefulSet.GetAnnotations()
+		delete(annotations, enterpriseApi.MaintenanceInProgressAnnotation)
+		statefulSet.SetAnnotations(annotations)
+		err := splutil.UpdateResource(ctx, c, statefulSet)
+		if err != nil {
+			return enterpriseApi.PhaseReady, err
+		}
+	}
+
 	// Remove unwanted owner references
 	err := splutil.RemoveUnwantedSecrets(ctx, c, statefulSet.GetName(), statefulSet.GetNamespace())
 	if err != nil {
@@ -255,6 +633,81 @@ func UpdateStatefulSetPods(ctx context.Context, c splcommon.ControllerClient, st
 	return enterpriseApi.PhaseReady, nil
 }
 
+// isPodRestartRequested returns true if the restart pods annotation of a StatefulSet requests a restart of a pod
+// after the pod was created
+func isPodRestartRequested(ctx context.Context, statefulSet *appsv1.StatefulSet, pod *corev1.Pod) bool {
+	value, ok := statefulSet.GetAnnotations()[enterpriseApi.RestartPodsAnnotation]
+	if !ok {
+		return false
+	}
+
+	var requests map[string]string
+	if err := json.Unmarshal([]byte(value), &requests); err != nil {
+		log.FromContext(ctx).Error(err, "Ignoring invalid restart pods annotation", "name", statefulSet.GetName(), "value", value)
+		return false
+	}
+	request, ok := requests[pod.GetName()]
+	if !ok {
+		return false
+	}
+	requestTime, err := time.Parse(time.RFC3339, request)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Ignoring invalid restart request", "podName", pod.GetName(), "time", request)
+		return false
+	}
+	return pod.GetCreationTimestamp().Time.Before(requestTime)
+}
+
+// startPodDisruption returns true if a disruptive operation of a pod of a StatefulSet may start or continue now, within
+// the maintenance windows and the disruption budget. Otherwise it returns the phase of the StatefulSet while it waits
+func startPodDisruption(ctx context.Context, c splcommon.ControllerClient, statefulSet *appsv1.StatefulSet, podName string, waitPhase enterpriseApi.Phase) (bool, enterpriseApi.Phase, error) {
+	allowed, err := isMaintenanceAllowed(ctx, statefulSet, podName)
+	if err != nil {
+		return false, enterpriseApi.PhaseError, err
+	}
+	if !allowed {
+		return false, enterpriseApi.PhasePendingMaintenanceWindow, nil
+	}
+	if !acquireDisruptionBudget(ctx, statefulSet) {
+		return false, waitPhase, nil
+	}
+
+	annotations := statefulSet.GetAnnotations()
+	if _, ok := annotations[enterpriseApi.MaintenanceWindowsAnnotation]; !ok || annotations[enterpriseApi.MaintenanceInProgressAnnotation] == podName {
+		return true, waitPhase, nil
+	}
+
+	// remember the pod, so that its operation is completed if the window closes in between
+	annotations[enterpriseApi.MaintenanceInProgressAnnotation] = podName
+	statefulSet.SetAnnotations(annotations)
+	err = splutil.UpdateResource(ctx, c, statefulSet)
+	if err != nil {
+		return false, enterpriseApi.PhaseError, err
+	}
+	return true, waitPhase, nil
+}
+
+// isMaintenanceAllowed returns true if a disruptive operation of a pod of a StatefulSet is allowed now, and logs when
+// the next maintenance window opens otherwise. An operation started within a window is completed after it closed
+func isMaintenanceAllowed(ctx context.Context, statefulSet *appsv1.StatefulSet, podName string) (bool, error) {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("isMaintenanceAllowed").WithValues(
+		"name", statefulSet.GetObjectMeta().GetName(),
+		"namespace", statefulSet.GetObjectMeta().GetNamespace())
+
+	allowed, nextOpen, err := IsStatefulSetInMaintenanceWindow(statefulSet, time.Now())
+	if err != nil {
+		scopedLog.Error(err, "Unable to evaluate maintenance windows")
+		return false, err
+	}
+
+	if !allowed && statefulSet.GetAnnotations()[enterpriseApi.MaintenanceInProgressAnnotation] != podName {
+		scopedLog.Info("Pending maintenance window", "podName", podName, "nextWindow", nextOpen)
+		return false, nil
+	}
+	return true, nil
+}
+
 // SetStatefulSetOwnerRef sets owner references for statefulset
 func SetStatefulSetOwnerRef(ctx context.Context, client splcommon.ControllerClient, cr splcommon.MetaObject, namespacedName types.NamespacedName) error {
 
@@ -17,17 +17,25 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/splunk/splunk-operator/pkg/config"
+	"github.com/splunk/splunk-operator/pkg/splunk/audit"
 	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 
@@ -62,6 +70,227 @@ func TestApplyStatefulSet(t *testing.T) {
 	spltest.ReconcileTester(t, "TestApplyStatefulSet", current, revised, createCalls, updateCalls, reconcile, false)
 }
 
+func TestApplyStatefulSetVolumeClaimTemplates(t *testing.T) {
+	ctx := context.TODO()
+	var replicas int32 = 1
+	current := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1-indexer",
+			Namespace: "test",
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "pvc-etc"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "pvc-var"}},
+			},
+		},
+	}
+	c := spltest.NewMockClient()
+	c.AddObject(current)
+
+	// adding a volume claim template recreates the StatefulSet
+	revised := current.DeepCopy()
+	revised.Spec.VolumeClaimTemplates = append(revised.Spec.VolumeClaimTemplates, corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "cold"}})
+	status := enterpriseApi.VolumeExpansionStatus{}
+	phase, err := ApplyStatefulSetWithVolumeExpansion(ctx, c, revised, &status)
+	if err != nil || phase != enterpriseApi.PhaseUpdating || status.Phase != enterpriseApi.VolumeExpansionRecreating {
+		t.Errorf("ApplyStatefulSetWithVolumeExpansion() returned %s, %v, %s; want Updating, nil, Recreating", phase, err, status.Phase)
+	}
+	c.CheckCalls(t, "TestApplyStatefulSetVolumeClaimTemplates", map[string][]spltest.MockFuncCall{
+		"Get":    {{MetaName: "*v1.StatefulSet-test-splunk-stack1-indexer"}},
+		"Delete": {{MetaName: "*v1.StatefulSet-test-splunk-stack1-indexer"}},
+	})
+}
+
+func TestApplyStatefulSetWithVolumeExpansion(t *testing.T) {
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+
+	var replicas int32 = 2
+	storageClassName := "gp2"
+	newStatefulSet := func(capacity string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "splunk-stack1-standalone", Namespace: "test"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+					ObjectMeta: metav1.ObjectMeta{Name: "pvc-var"},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						StorageClassName: &storageClassName,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+						},
+					},
+				}},
+			},
+		}
+	}
+	newPVC := func(n int) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("pvc-var-splunk-stack1-standalone-%d", n), Namespace: "test"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: &storageClassName,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")},
+				},
+			},
+			Status: corev1.PersistentVolumeClaimStatus{
+				Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")},
+			},
+		}
+	}
+	allowVolumeExpansion := false
+	storageClass := &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: storageClassName},
+		AllowVolumeExpansion: &allowVolumeExpansion,
+	}
+	pvc0, pvc1 := newPVC(0), newPVC(1)
+	c.AddObjects([]client.Object{newStatefulSet("100Gi"), pvc0, pvc1, storageClass})
+
+	status := enterpriseApi.VolumeExpansionStatus{}
+	apply := func(capacity string) enterpriseApi.Phase {
+		phase, err := ApplyStatefulSetWithVolumeExpansion(ctx, c, newStatefulSet(capacity), &status)
+		if err != nil {
+			t.Errorf("ApplyStatefulSetWithVolumeExpansion returned error: %v", err)
+		}
+		return phase
+	}
+	getPVC := func(pvc *corev1.PersistentVolumeClaim) *corev1.PersistentVolumeClaim {
+		var current corev1.PersistentVolumeClaim
+		err := c.Get(ctx, types.NamespacedName{Namespace: pvc.GetNamespace(), Name: pvc.GetName()}, &current)
+		if err != nil {
+			t.Errorf("unable to get %s: %v", pvc.GetName(), err)
+		}
+		return &current
+	}
+	checkStatus := func(step string, phase enterpriseApi.Phase, wantPhase enterpriseApi.Phase, want enterpriseApi.VolumeExpansionPhase, wantResized int32) {
+		if phase != wantPhase || status.Phase != want || status.ResizedPods != wantResized {
+			t.Errorf("%s: got phase=%s expansion=%s resized=%d; want %s %s %d (%s)", step, phase, status.Phase, status.ResizedPods, wantPhase, want, wantResized, status.Message)
+		}
+	}
+
+	// unchanged storage capacity
+	checkStatus("unchanged", apply("100Gi"), enterpriseApi.PhaseReady, "", 0)
+
+	// storage class does not allow expansion
+	checkStatus("unsupported", apply("200Gi"), enterpriseApi.PhaseReady, enterpriseApi.VolumeExpansionUnsupported, 0)
+	checkStatus("reverted", apply("100Gi"), enterpriseApi.PhaseReady, "", 0)
+
+	// expand the volume of the first pod
+	allowVolumeExpansion = true
+	checkStatus("expand pod 0", apply("200Gi"), enterpriseApi.PhaseUpdating, enterpriseApi.VolumeExpansionExpanding, 0)
+	if request := getPVC(pvc0).Spec.Resources.Requests[corev1.ResourceStorage]; request.String() != "200Gi" {
+		t.Errorf("pvc-0 request = %s; want 200Gi", request.String())
+	}
+	if request := getPVC(pvc1).Spec.Resources.Requests[corev1.ResourceStorage]; request.String() != "100Gi" {
+		t.Errorf("pvc-1 request = %s; want 100Gi", request.String())
+	}
+	if status.Capacity["pvc-var"] != "200Gi" {
+		t.Errorf("status capacity = %v; want pvc-var: 200Gi", status.Capacity)
+	}
+
+	// wait for the file system resize of the first pod
+	checkStatus("resize pod 0", apply("200Gi"), enterpriseApi.PhaseUpdating, enterpriseApi.VolumeExpansionResizing, 0)
+	pvc := getPVC(pvc0)
+	pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("200Gi")}
+	pvc.Status.Conditions = []corev1.PersistentVolumeClaimCondition{{Type: corev1.PersistentVolumeClaimFileSystemResizePending, Status: corev1.ConditionTrue}}
+	c.AddObject(pvc)
+	checkStatus("resize pending pod 0", apply("200Gi"), enterpriseApi.PhaseUpdating, enterpriseApi.VolumeExpansionResizing, 0)
+	pvc.Status.Conditions = nil
+	c.AddObject(pvc)
+
+	// expand and resize the volume of the second pod
+	checkStatus("expand pod 1", apply("200Gi"), enterpriseApi.PhaseUpdating, enterpriseApi.VolumeExpansionExpanding, 1)
+	pvc = getPVC(pvc1)
+	pvc.Status.Capacity = corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("200Gi")}
+	c.AddObject(pvc)
+
+	// the StatefulSet is deleted, orphaning its pods, and recreated
+	c.ResetCalls()
+	checkStatus("recreate", apply("200Gi"), enterpriseApi.PhaseUpdating, enterpriseApi.VolumeExpansionRecreating, 2)
+	if len(c.Calls["Delete"]) != 1 {
+		t.Errorf("StatefulSet deletes = %d; want 1", len(c.Calls["Delete"]))
+	}
+	checkStatus("recreated", apply("200Gi"), enterpriseApi.PhasePending, enterpriseApi.VolumeExpansionCompleted, 2)
+	if !IsVolumeExpansionInProgress(&enterpriseApi.VolumeExpansionStatus{Phase: enterpriseApi.VolumeExpansionResizing}) || IsVolumeExpansionInProgress(&status) {
+		t.Errorf("IsVolumeExpansionInProgress returned unexpected result")
+	}
+}
+
+// forbiddenUpdateClient rejects updates, as the API server does for claims whose storage class does not allow expansion,
+// or for an operator not allowed to update them
+type forbiddenUpdateClient struct {
+	*spltest.MockClient
+	reason error
+}
+
+func (c forbiddenUpdateClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return k8serrors.NewForbidden(schema.GroupResource{Resource: "persistentvolumeclaims"}, obj.GetName(), c.reason)
+}
+
+func TestApplyStatefulSetWithVolumeExpansionNamespaceScoped(t *testing.T) {
+	ctx := context.TODO()
+	t.Setenv(config.WatchNamespaceEnvVar, "test")
+
+	var replicas int32 = 1
+	storageClassName := "gp2"
+	newStatefulSet := func(capacity string) *appsv1.StatefulSet {
+		return &appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "splunk-stack1-standalone", Namespace: "test"},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: &replicas,
+				VolumeClaimTemplates: []corev1.PersistentVolumeClaim{{
+					ObjectMeta: metav1.ObjectMeta{Name: "pvc-var"},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						StorageClassName: &storageClassName,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+						},
+					},
+				}},
+			},
+		}
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-var-splunk-stack1-standalone-0", Namespace: "test"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClassName,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("100Gi")},
+			},
+		},
+	}
+
+	// the storage class is not read, the API server rejects the expansion
+	c := spltest.NewMockClient()
+	c.AddObjects([]client.Object{newStatefulSet("100Gi"), pvc.DeepCopy()})
+	status := enterpriseApi.VolumeExpansionStatus{}
+	phase, err := ApplyStatefulSetWithVolumeExpansion(ctx, forbiddenUpdateClient{c, fmt.Errorf(volumeExpansionRejectedMessage)}, newStatefulSet("200Gi"), &status)
+	if err != nil || phase != enterpriseApi.PhaseReady || status.Phase != enterpriseApi.VolumeExpansionUnsupported {
+		t.Errorf("ApplyStatefulSetWithVolumeExpansion()=%s,%v with expansion %s; want Ready,nil with Unsupported", phase, err, status.Phase)
+	}
+
+	// the operator is not allowed to update the claims, which is an error rather than an unsupported expansion
+	c = spltest.NewMockClient()
+	c.AddObjects([]client.Object{newStatefulSet("100Gi"), pvc.DeepCopy()})
+	status = enterpriseApi.VolumeExpansionStatus{}
+	rbacDenial := fmt.Errorf(`User "system:serviceaccount:test:splunk-operator-controller-manager" cannot update resource "persistentvolumeclaims" in API group "" in the namespace "test"`)
+	_, err = ApplyStatefulSetWithVolumeExpansion(ctx, forbiddenUpdateClient{c, rbacDenial}, newStatefulSet("200Gi"), &status)
+	if !k8serrors.IsForbidden(err) || status.Phase == enterpriseApi.VolumeExpansionUnsupported {
+		t.Errorf("ApplyStatefulSetWithVolumeExpansion()=%v with expansion %s; want forbidden error", err, status.Phase)
+	}
+
+	// the storage class is not read, the API server accepts the expansion
+	c = spltest.NewMockClient()
+	c.AddObjects([]client.Object{newStatefulSet("100Gi"), pvc.DeepCopy()})
+	status = enterpriseApi.VolumeExpansionStatus{}
+	phase, err = ApplyStatefulSetWithVolumeExpansion(ctx, c, newStatefulSet("200Gi"), &status)
+	if err != nil || phase != enterpriseApi.PhaseUpdating || status.Phase != enterpriseApi.VolumeExpansionExpanding {
+		t.Errorf("ApplyStatefulSetWithVolumeExpansion()=%s,%v with expansion %s; want Updating,nil with Expanding", phase, err, status.Phase)
+	}
+}
+
 func TestDefaultStatefulSetPodManager(t *testing.T) {
 
 	// test for updating
@@ -157,6 +386,228 @@ func TestUpdateStatefulSetPods(t *testing.T) {
 	}
 }
 
+func TestUpdateStatefulSetPodsPVCRetention(t *testing.T) {
+	mgr := DefaultStatefulSetPodManager{}
+	var replicas int32 = 2
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "splunk-stack1",
+			Namespace:   "test",
+			Annotations: map[string]string{enterpriseApi.PVCRetentionWhenScaledAnnotation: "Retain"},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{ObjectMeta: metav1.ObjectMeta{Name: "pvc-etc", Namespace: "test"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "pvc-var", Namespace: "test"}},
+			},
+		},
+		Status: appsv1.StatefulSetStatus{
+			Replicas:      replicas,
+			ReadyReplicas: replicas,
+		},
+	}
+	pvcEtc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pvc-etc-splunk-stack1-1", Namespace: "test"}}
+	pvcVar := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pvc-var-splunk-stack1-1", Namespace: "test"}}
+
+	// PVCs of the scaled down pod are retained
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	c.AddObjects([]client.Object{statefulSet, pvcEtc, pvcVar})
+	phase, err := UpdateStatefulSetPods(ctx, c, statefulSet, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhaseScalingDown {
+		t.Errorf("UpdateStatefulSetPods() returned %s, %v; want ScalingDown", phase, err)
+	}
+	c.CheckCalls(t, "TestUpdateStatefulSetPodsPVCRetention", map[string][]spltest.MockFuncCall{
+		"Update": {{MetaName: "*v1.StatefulSet-test-splunk-stack1"}},
+	})
+
+	// PVCs already deleted by the StatefulSet controller are skipped
+	replicas = 2
+	statefulSet.Annotations[enterpriseApi.PVCRetentionWhenScaledAnnotation] = "Delete"
+	c = spltest.NewMockClient()
+	c.AddObjects([]client.Object{statefulSet, pvcVar})
+	phase, err = UpdateStatefulSetPods(ctx, c, statefulSet, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhaseScalingDown {
+		t.Errorf("UpdateStatefulSetPods() returned %s, %v; want ScalingDown", phase, err)
+	}
+	c.CheckCalls(t, "TestUpdateStatefulSetPodsPVCRetention", map[string][]spltest.MockFuncCall{
+		"Get": {
+			{MetaName: "*v1.PersistentVolumeClaim-test-pvc-etc-splunk-stack1-1"},
+			{MetaName: "*v1.PersistentVolumeClaim-test-pvc-var-splunk-stack1-1"},
+		},
+		"Update": {{MetaName: "*v1.StatefulSet-test-splunk-stack1"}},
+		"Delete": {{MetaName: "*v1.PersistentVolumeClaim-test-pvc-var-splunk-stack1-1"}},
+	})
+
+	// PVCs of the scaled down pod are snapshotted, and only deleted once their snapshots are ready
+	replicas = 2
+	statefulSet.Status.ReadyReplicas = 2
+	statefulSet.Annotations[enterpriseApi.PVCRetentionWhenScaledAnnotation] = "Snapshot"
+	c = spltest.NewMockClient()
+	c.AddObjects([]client.Object{statefulSet, pvcVar})
+	phase, err = UpdateStatefulSetPods(ctx, c, statefulSet, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhaseScalingDown {
+		t.Errorf("UpdateStatefulSetPods() returned %s, %v; want ScalingDown", phase, err)
+	}
+	c.CheckCalls(t, "TestUpdateStatefulSetPodsPVCRetention", map[string][]spltest.MockFuncCall{
+		"Get": {
+			{MetaName: "*v1.PersistentVolumeClaim-test-pvc-etc-splunk-stack1-2"},
+			{MetaName: "*v1.PersistentVolumeClaim-test-pvc-var-splunk-stack1-2"},
+			{MetaName: "*v1.PersistentVolumeClaim-test-pvc-etc-splunk-stack1-1"},
+			{MetaName: "*v1.PersistentVolumeClaim-test-pvc-var-splunk-stack1-1"},
+			{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-1"},
+		},
+		"Update": {{MetaName: "*v1.StatefulSet-test-splunk-stack1"}},
+		"Create": {{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-1"}},
+		"Delete": {},
+	})
+	snapshot := c.Calls["Create"][0].Obj.(*unstructured.Unstructured)
+
+	// the PVC survives, and scaling waits, while the snapshot is not ready
+	statefulSet.Status.ReadyReplicas = 1
+	c.ResetCalls()
+	phase, err = UpdateStatefulSetPods(ctx, c, statefulSet, &mgr, 2)
+	if err != nil || phase != enterpriseApi.PhaseScalingDown {
+		t.Errorf("UpdateStatefulSetPods() returned %s, %v; want ScalingDown", phase, err)
+	}
+	c.CheckCalls(t, "TestUpdateStatefulSetPodsPVCRetention", map[string][]spltest.MockFuncCall{
+		"Get": {
+			{MetaName: "*v1.PersistentVolumeClaim-test-pvc-etc-splunk-stack1-1"},
+			{MetaName: "*v1.PersistentVolumeClaim-test-pvc-var-splunk-stack1-1"},
+			{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-1"},
+			{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-1"},
+		},
+		"Update": {},
+		"Delete": {},
+	})
+
+	// the PVC is deleted once the snapshot is ready, before scaling up again
+	snapshot.Object["status"] = map[string]interface{}{"readyToUse": true}
+	c.ResetCalls()
+	phase, err = UpdateStatefulSetPods(ctx, c, statefulSet, &mgr, 2)
+	if err != nil || phase != enterpriseApi.PhaseScalingUp {
+		t.Errorf("UpdateStatefulSetPods() returned %s, %v; want ScalingUp", phase, err)
+	}
+	c.CheckCalls(t, "TestUpdateStatefulSetPodsPVCRetention", map[string][]spltest.MockFuncCall{
+		"Get": {
+			{MetaName: "*v1.PersistentVolumeClaim-test-pvc-etc-splunk-stack1-1"},
+			{MetaName: "*v1.PersistentVolumeClaim-test-pvc-var-splunk-stack1-1"},
+			{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-1"},
+			{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-1"},
+		},
+		"Update": {{MetaName: "*v1.StatefulSet-test-splunk-stack1"}},
+		"Delete": {{MetaName: "*v1.PersistentVolumeClaim-test-pvc-var-splunk-stack1-1"}},
+	})
+}
+
+func TestUpdateStatefulSetPodsRestart(t *testing.T) {
+	mgr := DefaultStatefulSetPodManager{}
+	var replicas int32 = 1
+	created := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC)
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "splunk-stack1",
+			Namespace:   "test",
+			Annotations: map[string]string{enterpriseApi.RestartPodsAnnotation: `{"splunk-stack1-0":"2022-09-30T00:00:00Z"}`},
+		},
+		Spec: appsv1.StatefulSetSpec{Replicas: &replicas},
+		Status: appsv1.StatefulSetStatus{
+			Replicas:       replicas,
+			ReadyReplicas:  replicas,
+			UpdateRevision: "v1",
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "splunk-stack1-0",
+			Namespace:         "test",
+			CreationTimestamp: metav1.NewTime(created),
+			Labels:            map[string]string{"controller-revision-hash": "v1"},
+		},
+		Status: corev1.PodStatus{
+			Phase:             corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{{Ready: true}},
+		},
+	}
+
+	// the pod was created after the restart request
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	c.AddObjects([]client.Object{statefulSet, pod})
+	phase, err := UpdateStatefulSetPods(ctx, c, statefulSet, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhaseReady {
+		t.Errorf("UpdateStatefulSetPods() returned %s, %v; want Ready", phase, err)
+	}
+	if len(c.Calls["Delete"]) != 0 {
+		t.Errorf("UpdateStatefulSetPods() deleted the pod restarted after the request")
+	}
+
+	// the pod was created before the restart request, its deletion is audited
+	statefulSet.Annotations[enterpriseApi.RestartPodsAnnotation] = `{"splunk-stack1-0":"2022-10-02T00:00:00Z"}`
+	c = spltest.NewMockClient()
+	c.AddObjects([]client.Object{statefulSet, pod})
+	cr := &enterpriseApi.Standalone{ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"}}
+	phase, err = UpdateStatefulSetPods(audit.NewContext(ctx, cr, &cr.Status.AuditTrail), c, statefulSet, &mgr, 1)
+	if err != nil || phase != enterpriseApi.PhaseUpdating {
+		t.Errorf("UpdateStatefulSetPods() returned %s, %v; want Updating", phase, err)
+	}
+	c.CheckCalls(t, "TestUpdateStatefulSetPodsRestart", map[string][]spltest.MockFuncCall{
+		"Get":    {{MetaName: "*v1.Pod-test-splunk-stack1-0"}},
+		"Delete": {{MetaName: "*v1.Pod-test-splunk-stack1-0"}},
+	})
+	if len(cr.Status.AuditTrail) != 1 || cr.Status.AuditTrail[0].Action != audit.PodDeleted || cr.Status.AuditTrail[0].Reason != "restart requested" {
+		t.Errorf("UpdateStatefulSetPods() audit trail = %+v", cr.Status.AuditTrail)
+	}
+
+	// invalid requests are ignored
+	statefulSet.Annotations[enterpriseApi.RestartPodsAnnotation] = `{"splunk-stack1-0":"tomorrow"}`
+	if isPodRestartRequested(ctx, statefulSet, pod) {
+		t.Errorf("isPodRestartRequested() returned true for an invalid request time")
+	}
+	statefulSet.Annotations[enterpriseApi.RestartPodsAnnotation] = `splunk-stack1-0`
+	if isPodRestartRequested(ctx, statefulSet, pod) {
+		t.Errorf("isPodRestartRequested() returned true for an invalid annotation")
+	}
+}
+
+func TestMergePVCRetentionPolicyUpdates(t *testing.T) {
+	current := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "splunk-stack1"}}
+	revised := current.DeepCopy()
+	if MergePVCRetentionPolicyUpdates(current, revised) {
+		t.Errorf("MergePVCRetentionPolicyUpdates() returned true for identical StatefulSets")
+	}
+
+	// annotations are merged, the policy is left alone when not supported by the cluster
+	revised.Annotations = map[string]string{enterpriseApi.PVCRetentionWhenScaledAnnotation: "Snapshot", enterpriseApi.VolumeSnapshotClassAnnotation: "csi-snapclass"}
+	revised.Spec.PersistentVolumeClaimRetentionPolicy = &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+		WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+	}
+	if !MergePVCRetentionPolicyUpdates(current, revised) || current.Annotations[enterpriseApi.PVCRetentionWhenScaledAnnotation] != "Snapshot" {
+		t.Errorf("MergePVCRetentionPolicyUpdates() did not merge annotations: %v", current.Annotations)
+	}
+	if current.Spec.PersistentVolumeClaimRetentionPolicy != nil {
+		t.Errorf("MergePVCRetentionPolicyUpdates() set an unsupported policy")
+	}
+
+	// supported policy is updated
+	current.Spec.PersistentVolumeClaimRetentionPolicy = &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+		WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+	}
+	if !MergePVCRetentionPolicyUpdates(current, revised) || current.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted != appsv1.DeletePersistentVolumeClaimRetentionPolicyType {
+		t.Errorf("MergePVCRetentionPolicyUpdates() did not update the policy")
+	}
+
+	// removing the policy resets it to Retain
+	revised = &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "splunk-stack1"}}
+	if !MergePVCRetentionPolicyUpdates(current, revised) || len(current.Annotations) != 0 ||
+		current.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted != appsv1.RetainPersistentVolumeClaimRetentionPolicyType {
+		t.Errorf("MergePVCRetentionPolicyUpdates() did not reset the policy: %v %v", current.Annotations, current.Spec.PersistentVolumeClaimRetentionPolicy)
+	}
+}
+
 func TestSetStatefulSetOwnerRef(t *testing.T) {
 
 	ctx := context.TODO()
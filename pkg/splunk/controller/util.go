@@ -18,6 +18,7 @@ package controller
 import (
 	"context"
 	"reflect"
+	"sort"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -128,6 +129,24 @@ func MergePodSpecUpdates(ctx context.Context, current *corev1.PodSpec, revised *
 		result = true
 	}
 
+	// check for changes in ShareProcessNamespace
+	if splcommon.CompareByMarshall(current.ShareProcessNamespace, revised.ShareProcessNamespace) {
+		scopedLog.Info("Pod ShareProcessNamespace differs",
+			"current", current.ShareProcessNamespace,
+			"revised", revised.ShareProcessNamespace)
+		current.ShareProcessNamespace = revised.ShareProcessNamespace
+		result = true
+	}
+
+	// check for changes in ReadinessGates
+	if splcommon.CompareByMarshall(current.ReadinessGates, revised.ReadinessGates) {
+		scopedLog.Info("Pod ReadinessGates differs",
+			"current", current.ReadinessGates,
+			"revised", revised.ReadinessGates)
+		current.ReadinessGates = revised.ReadinessGates
+		result = true
+	}
+
 	// check for changes in SchedulerName
 	if current.SchedulerName != revised.SchedulerName {
 		scopedLog.Info("Pod SchedulerName differs",
@@ -146,8 +165,8 @@ func MergePodSpecUpdates(ctx context.Context, current *corev1.PodSpec, revised *
 		result = true
 	}
 
-	// Check for changes in Init containers
-	if len(current.InitContainers) != len(revised.InitContainers) {
+	// Check for changes in Init containers, which are matched by name
+	if containerNamesDiffer(current.InitContainers, revised.InitContainers) {
 		scopedLog.Info("Pod init containers  differ",
 			"current", len(current.InitContainers),
 			"revised", len(revised.InitContainers))
@@ -163,12 +182,54 @@ func MergePodSpecUpdates(ctx context.Context, current *corev1.PodSpec, revised *
 				current.InitContainers[idx].Image = revised.InitContainers[idx].Image
 				result = true
 			}
+
+			// check Name, Command and Args
+			if mergeContainerCommandUpdates(&current.InitContainers[idx], &revised.InitContainers[idx]) {
+				scopedLog.Info("Init Container Commands differ",
+					"current", current.InitContainers[idx].Command,
+					"revised", revised.InitContainers[idx].Command)
+				result = true
+			}
+
+			// check VolumeMounts
+			if splcommon.CompareVolumeMounts(current.InitContainers[idx].VolumeMounts, revised.InitContainers[idx].VolumeMounts) {
+				scopedLog.Info("Init Container VolumeMounts differ",
+					"current", current.InitContainers[idx].VolumeMounts,
+					"revised", revised.InitContainers[idx].VolumeMounts)
+				current.InitContainers[idx].VolumeMounts = revised.InitContainers[idx].VolumeMounts
+				result = true
+			}
+
+			// check Resources
+			if splcommon.CompareByMarshall(&current.InitContainers[idx].Resources, &revised.InitContainers[idx].Resources) {
+				scopedLog.Info("Init Container Resources differ",
+					"current", current.InitContainers[idx].Resources,
+					"revised", revised.InitContainers[idx].Resources)
+				current.InitContainers[idx].Resources = revised.InitContainers[idx].Resources
+				result = true
+			}
+
+			// check Env
+			if splcommon.CompareEnvs(current.InitContainers[idx].Env, revised.InitContainers[idx].Env) {
+				scopedLog.Info("Init Container Envs differ",
+					"current", current.InitContainers[idx].Env,
+					"revised", revised.InitContainers[idx].Env)
+				current.InitContainers[idx].Env = revised.InitContainers[idx].Env
+				result = true
+			}
+
+			// check SecurityContext, EnvFrom and Lifecycle
+			if mergeContainerRuntimeUpdates(&current.InitContainers[idx], &revised.InitContainers[idx]) {
+				scopedLog.Info("Init Container SecurityContext, EnvFrom or Lifecycle differ",
+					"name", current.InitContainers[idx].Name)
+				result = true
+			}
 		}
 	}
 
-	// check for changes in container images; assume that the ordering is same for pods with > 1 container
-	if len(current.Containers) != len(revised.Containers) {
-		scopedLog.Info("Pod Container counts differ",
+	// check for changes in containers, which are matched by name; added, removed or reordered containers replace them all
+	if containerNamesDiffer(current.Containers, revised.Containers) {
+		scopedLog.Info("Pod Containers differ",
 			"current", len(current.Containers),
 			"revised", len(revised.Containers))
 		current.Containers = revised.Containers
@@ -184,6 +245,14 @@ func MergePodSpecUpdates(ctx context.Context, current *corev1.PodSpec, revised *
 				result = true
 			}
 
+			// check Name, Command and Args
+			if mergeContainerCommandUpdates(&current.Containers[idx], &revised.Containers[idx]) {
+				scopedLog.Info("Pod Container Commands differ",
+					"current", current.Containers[idx].Command,
+					"revised", revised.Containers[idx].Command)
+				result = true
+			}
+
 			// check Ports
 			if splcommon.CompareContainerPorts(current.Containers[idx].Ports, revised.Containers[idx].Ports) {
 				scopedLog.Info("Pod Container Ports differ",
@@ -220,6 +289,13 @@ func MergePodSpecUpdates(ctx context.Context, current *corev1.PodSpec, revised *
 				result = true
 			}
 
+			// check SecurityContext, EnvFrom and Lifecycle
+			if mergeContainerRuntimeUpdates(&current.Containers[idx], &revised.Containers[idx]) {
+				scopedLog.Info("Pod Container SecurityContext, EnvFrom or Lifecycle differ",
+					"name", current.Containers[idx].Name)
+				result = true
+			}
+
 			// check probes
 			if hasProbeChanged(current.Containers[idx].LivenessProbe, revised.Containers[idx].LivenessProbe) {
 				scopedLog.Info("Pod Container Liveness Probe differ",
@@ -250,6 +326,82 @@ func MergePodSpecUpdates(ctx context.Context, current *corev1.PodSpec, revised *
 	return result
 }
 
+// containerNamesDiffer returns true if two lists of containers do not have the same names in the same order
+func containerNamesDiffer(current []corev1.Container, revised []corev1.Container) bool {
+	if len(current) != len(revised) {
+		return true
+	}
+	for idx := range current {
+		if current[idx].Name != revised[idx].Name {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeContainerRuntimeUpdates merges changes of the security context, environment sources and lifecycle hooks of a
+// container. It returns true if there are differences between them, or false otherwise
+func mergeContainerRuntimeUpdates(current *corev1.Container, revised *corev1.Container) bool {
+	result := false
+	if splcommon.CompareByMarshall(current.SecurityContext, revised.SecurityContext) {
+		current.SecurityContext = revised.SecurityContext
+		result = true
+	}
+	if splcommon.CompareByMarshall(current.EnvFrom, revised.EnvFrom) {
+		current.EnvFrom = revised.EnvFrom
+		result = true
+	}
+	if splcommon.CompareByMarshall(current.Lifecycle, revised.Lifecycle) {
+		current.Lifecycle = revised.Lifecycle
+		result = true
+	}
+	return result
+}
+
+// mergeContainerCommandUpdates merges changes of the name, command and arguments of a container. It returns true if
+// there are differences between them, or false otherwise
+func mergeContainerCommandUpdates(current *corev1.Container, revised *corev1.Container) bool {
+	result := false
+	if current.Name != revised.Name {
+		current.Name = revised.Name
+		result = true
+	}
+	if splcommon.CompareByMarshall(current.Command, revised.Command) {
+		current.Command = revised.Command
+		result = true
+	}
+	if splcommon.CompareByMarshall(current.Args, revised.Args) {
+		current.Args = revised.Args
+		result = true
+	}
+	return result
+}
+
+// CompareVolumeClaimTemplates returns true if volume claim templates were added to or removed from a StatefulSet.
+// Other changes of the templates are not material, raised storage capacities are handled by ExpandStatefulSetVolumes
+// and the remaining fields only apply to persistent volume claims created later on
+func CompareVolumeClaimTemplates(ctx context.Context, current, revised []corev1.PersistentVolumeClaim, name string) bool {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("CompareVolumeClaimTemplates").WithValues("name", name)
+
+	getNames := func(templates []corev1.PersistentVolumeClaim) []string {
+		names := make([]string, 0, len(templates))
+		for i := range templates {
+			names = append(names, templates[i].GetName())
+		}
+		sort.Strings(names)
+		return names
+	}
+	currentNames, revisedNames := getNames(current), getNames(revised)
+	if splcommon.CompareSortedStrings(currentNames, revisedNames) {
+		scopedLog.Info("StatefulSet VolumeClaimTemplates differ",
+			"current", currentNames,
+			"revised", revisedNames)
+		return true
+	}
+	return false
+}
+
 // SortStatefulSetSlices sorts required slices in a statefulSet
 func SortStatefulSetSlices(ctx context.Context, current *corev1.PodSpec, name string) error {
 	reqLogger := log.FromContext(ctx)
@@ -322,6 +474,34 @@ func MergeServiceSpecUpdates(ctx context.Context, current *corev1.ServiceSpec, r
 		result = true
 	}
 
+	if revised.SessionAffinity != "" && current.SessionAffinity != revised.SessionAffinity {
+		scopedLog.Info("Session Affinity differs",
+			"current", current.SessionAffinity,
+			"revised", revised.SessionAffinity)
+		current.SessionAffinity = revised.SessionAffinity
+		current.SessionAffinityConfig = revised.SessionAffinityConfig
+		result = true
+	}
+
+	if splcommon.CompareSortedStrings(current.LoadBalancerSourceRanges, revised.LoadBalancerSourceRanges) {
+		scopedLog.Info("Load Balancer Source Ranges differs",
+			"current", current.LoadBalancerSourceRanges,
+			"revised", revised.LoadBalancerSourceRanges)
+		current.LoadBalancerSourceRanges = revised.LoadBalancerSourceRanges
+		result = true
+	}
+
+	// keep the node ports allocated by the cluster
+	if revised.Type == corev1.ServiceTypeNodePort || revised.Type == corev1.ServiceTypeLoadBalancer {
+		for i := range revised.Ports {
+			for j := range current.Ports {
+				if revised.Ports[i].NodePort == 0 && revised.Ports[i].Name == current.Ports[j].Name && revised.Ports[i].Port == current.Ports[j].Port {
+					revised.Ports[i].NodePort = current.Ports[j].NodePort
+				}
+			}
+		}
+	}
+
 	// check for changes in Ports
 	if splcommon.CompareServicePorts(current.Ports, revised.Ports) {
 		scopedLog.Info("Service Ports differs",
@@ -339,6 +519,9 @@ func hasProbeChanged(currentProbe *corev1.Probe, revisedProbe *corev1.Probe) boo
 	if currentProbe == nil {
 		return revisedProbe != nil
 	}
+	if revisedProbe == nil {
+		return true
+	}
 	if currentProbe.InitialDelaySeconds != revisedProbe.InitialDelaySeconds {
 		return true
 	}
@@ -351,5 +534,8 @@ func hasProbeChanged(currentProbe *corev1.Probe, revisedProbe *corev1.Probe) boo
 	if currentProbe.FailureThreshold != revisedProbe.FailureThreshold {
 		return true
 	}
+	if splcommon.CompareByMarshall(currentProbe.ProbeHandler, revisedProbe.ProbeHandler) {
+		return true
+	}
 	return false
 }
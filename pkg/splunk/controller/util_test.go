@@ -22,6 +22,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestMergePodUpdates(t *testing.T) {
@@ -31,7 +32,8 @@ func TestMergePodUpdates(t *testing.T) {
 	matcher := func() bool { return false }
 
 	podUpdateTester := func(param string) {
-		if !MergePodUpdates(ctx, &current, &revised, name) {
+		// merge a copy, so that the containers replaced in current are not changed with revised afterwards
+		if !MergePodUpdates(ctx, &current, revised.DeepCopy(), name) {
 			t.Errorf("MergePodUpdates() returned %t; want %t", false, true)
 		}
 		if !matcher() {
@@ -55,7 +57,7 @@ func TestMergePodUpdates(t *testing.T) {
 			},
 		},
 	}
-	matcher = func() bool { return current.Spec.Affinity == revised.Spec.Affinity }
+	matcher = func() bool { return reflect.DeepEqual(current.Spec.Affinity, revised.Spec.Affinity) }
 	podUpdateTester("Affinity")
 
 	// check SchedulerName
@@ -100,6 +102,16 @@ func TestMergePodUpdates(t *testing.T) {
 	matcher = func() bool { return reflect.DeepEqual(current.Spec.InitContainers, revised.Spec.InitContainers) }
 	podUpdateTester("InitContainer image changed")
 
+	// check init container command modified
+	revised.Spec.InitContainers = []corev1.Container{{Image: "splunk/splunk:a.b.d", Name: "init", Command: []string{"bash", "-c", "ls"}}}
+	matcher = func() bool { return reflect.DeepEqual(current.Spec.InitContainers, revised.Spec.InitContainers) }
+	podUpdateTester("InitContainer command changed")
+
+	// check init container env modified
+	revised.Spec.InitContainers[0].Env = []corev1.EnvVar{{Name: "VAULT_ADDR", Value: "https://vault:8200"}}
+	matcher = func() bool { return reflect.DeepEqual(current.Spec.InitContainers, revised.Spec.InitContainers) }
+	podUpdateTester("InitContainer env changed")
+
 	// check new container added
 	revised.Spec.Containers = []corev1.Container{{Image: "splunk/splunk"}}
 	matcher = func() bool { return reflect.DeepEqual(current.Spec.Containers, revised.Spec.Containers) }
@@ -130,6 +142,11 @@ func TestMergePodUpdates(t *testing.T) {
 	matcher = func() bool { return reflect.DeepEqual(current.Spec.Containers, revised.Spec.Containers) }
 	podUpdateTester("Container Resources")
 
+	// check container different Args
+	revised.Spec.Containers[0].Args = []string{"--verbose"}
+	matcher = func() bool { return reflect.DeepEqual(current.Spec.Containers, revised.Spec.Containers) }
+	podUpdateTester("Container Args")
+
 	// check pod env update
 	current.Spec.Containers[0].Env = append(current.Spec.Containers[0].Env, corev1.EnvVar{
 		Name:  "SPLUNK_DEFAULTS_URL",
@@ -166,6 +183,37 @@ func TestMergePodUpdates(t *testing.T) {
 	}
 	podUpdateTester("Pod ReadinessProbe changed")
 
+	// check sidecar container added
+	revised.Spec.Containers = append(revised.Spec.Containers, corev1.Container{Name: "vault-agent", Image: "vault"})
+	matcher = func() bool { return reflect.DeepEqual(current.Spec.Containers, revised.Spec.Containers) }
+	podUpdateTester("Sidecar container added")
+
+	// check containers reordered
+	revised.Spec.Containers = []corev1.Container{revised.Spec.Containers[1], revised.Spec.Containers[0]}
+	matcher = func() bool { return reflect.DeepEqual(current.Spec.Containers, revised.Spec.Containers) }
+	podUpdateTester("Containers reordered")
+
+	// check sidecar container different SecurityContext
+	runAsNonRoot := true
+	revised.Spec.Containers[0].SecurityContext = &corev1.SecurityContext{RunAsNonRoot: &runAsNonRoot}
+	matcher = func() bool { return reflect.DeepEqual(current.Spec.Containers, revised.Spec.Containers) }
+	podUpdateTester("Sidecar container SecurityContext")
+
+	// check sidecar container different EnvFrom
+	revised.Spec.Containers[0].EnvFrom = []corev1.EnvFromSource{{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "vault"}}}}
+	matcher = func() bool { return reflect.DeepEqual(current.Spec.Containers, revised.Spec.Containers) }
+	podUpdateTester("Sidecar container EnvFrom")
+
+	// check sidecar container different Lifecycle
+	revised.Spec.Containers[0].Lifecycle = &corev1.Lifecycle{PreStop: &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"sleep", "5"}}}}
+	matcher = func() bool { return reflect.DeepEqual(current.Spec.Containers, revised.Spec.Containers) }
+	podUpdateTester("Sidecar container Lifecycle")
+
+	// check init container different SecurityContext
+	revised.Spec.InitContainers[0].SecurityContext = &corev1.SecurityContext{RunAsNonRoot: &runAsNonRoot}
+	matcher = func() bool { return reflect.DeepEqual(current.Spec.InitContainers, revised.Spec.InitContainers) }
+	podUpdateTester("InitContainer SecurityContext")
+
 	// check container removed
 	revised.Spec.Containers = []corev1.Container{}
 	matcher = func() bool { return reflect.DeepEqual(current.Spec.Containers, revised.Spec.Containers) }
@@ -232,6 +280,62 @@ func TestMergeServiceSpecUpdates(t *testing.T) {
 	revised.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
 	matcher = func() bool { return current.ExternalTrafficPolicy == revised.ExternalTrafficPolicy }
 	svcUpdateTester("Service ExternalTrafficPolicy changed")
+
+	current.SessionAffinity = corev1.ServiceAffinityNone
+	revised.SessionAffinity = corev1.ServiceAffinityClientIP
+	matcher = func() bool { return current.SessionAffinity == revised.SessionAffinity }
+	svcUpdateTester("Service SessionAffinity changed")
+
+	// the session affinity defaulted by the cluster is kept
+	revised.SessionAffinity = ""
+	if MergeServiceSpecUpdates(ctx, &current, &revised, name) {
+		t.Errorf("MergeServiceSpecUpdates() returned %t without session affinity; want %t", true, false)
+	}
+
+	revised.LoadBalancerSourceRanges = []string{"10.0.0.0/8"}
+	matcher = func() bool {
+		return reflect.DeepEqual(current.LoadBalancerSourceRanges, revised.LoadBalancerSourceRanges)
+	}
+	svcUpdateTester("Service LoadBalancerSourceRanges changed")
+
+	// the node ports allocated by the cluster are kept
+	current.Ports = []corev1.ServicePort{{Name: "port-changed", Port: 32000, NodePort: 30123}}
+	revised.Ports = []corev1.ServicePort{{Name: "port-changed", Port: 32000}}
+	if MergeServiceSpecUpdates(ctx, &current, &revised, name) {
+		t.Errorf("MergeServiceSpecUpdates() returned %t for an allocated node port; want %t", true, false)
+	}
+}
+
+func TestCompareVolumeClaimTemplates(t *testing.T) {
+	ctx := context.TODO()
+	newTemplate := func(name, capacity string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(capacity)},
+				},
+			},
+		}
+	}
+	current := []corev1.PersistentVolumeClaim{newTemplate("pvc-etc", "10Gi"), newTemplate("pvc-var", "100Gi")}
+
+	// order and capacity are not material
+	revised := []corev1.PersistentVolumeClaim{newTemplate("pvc-var", "200Gi"), newTemplate("pvc-etc", "10Gi")}
+	if CompareVolumeClaimTemplates(ctx, current, revised, "test") {
+		t.Errorf("CompareVolumeClaimTemplates() returned true for the same templates")
+	}
+
+	// added template
+	revised = append(revised, newTemplate("cold", "1Ti"))
+	if !CompareVolumeClaimTemplates(ctx, current, revised, "test") {
+		t.Errorf("CompareVolumeClaimTemplates() did not detect an added template")
+	}
+
+	// removed template
+	if !CompareVolumeClaimTemplates(ctx, current, current[:1], "test") {
+		t.Errorf("CompareVolumeClaimTemplates() did not detect a removed template")
+	}
 }
 
 func TestSortStatefulSetSlices(t *testing.T) {
@@ -314,6 +418,12 @@ func TestHasProbeChanged(t *testing.T) {
 		t.Errorf("current Probe nil. hasProbeChanged() returned %t; want %t", false, true)
 	}
 
+	// Check return is true when revisedProbe is nil and currentProbe is not nil
+	result = hasProbeChanged(current.Spec.Containers[0].LivenessProbe, nil)
+	if !result {
+		t.Errorf("revised Probe nil. hasProbeChanged() returned %t; want %t", false, true)
+	}
+
 	// Check return is true when current probe and revised probe InitialDelaySeconds is different
 	result = hasProbeChanged(current.Spec.Containers[0].LivenessProbe, revised.Spec.Containers[0].LivenessProbe)
 	if !result {
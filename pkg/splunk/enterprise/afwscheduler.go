@@ -27,10 +27,15 @@ import (
 	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
 
 	"github.com/pkg/errors"
+	"github.com/splunk/splunk-operator/pkg/splunk/audit"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -103,9 +108,11 @@ func getApplicablePodNameForAppFramework(cr splcommon.MetaObject, ordinalIdx int
 	case "ClusterMaster":
 		podType = "cluster-master"
 	case "ClusterManager":
-		podType = "cluster-manager"
+		podType = string(getClusterManagerInstanceType(cr))
 	case "MonitoringConsole":
 		podType = "monitoring-console"
+	case "DeploymentServer":
+		podType = "deployment-server"
 	}
 
 	return fmt.Sprintf("splunk-%s-%s-%d", cr.GetName(), podType, ordinalIdx)
@@ -153,6 +160,8 @@ func getTelAppNameExtension(crKind string) (string, error) {
 		return "cmaster", nil
 	case "ClusterManager":
 		return "cmanager", nil
+	case "DeploymentServer":
+		return "dserver", nil
 	default:
 		return "", errors.New("Invalid CR kind for telemetry app")
 	}
@@ -465,6 +474,8 @@ func (downloadWorker *PipelineWorker) createDownloadDirOnOperator(ctx context.Co
 
 // download API will do the actual work of downloading apps from remote storage
 func (downloadWorker *PipelineWorker) download(ctx context.Context, pplnPhase *PipelinePhase, remoteDataClientMgr RemoteDataClientManager, localPath string, downloadWorkersRunPool chan struct{}) {
+	ctx, span := startWorkerSpan(ctx, "download", downloadWorker)
+	defer span.End()
 
 	defer func() {
 		downloadWorker.isActive = false
@@ -514,6 +525,14 @@ func (downloadWorker *PipelineWorker) download(ctx context.Context, pplnPhase *P
 	scopedLog.Info("Finished downloading app")
 }
 
+// startWorkerSpan starts the span of the work of a pipeline worker on an app
+func startWorkerSpan(ctx context.Context, name string, worker *PipelineWorker) (context.Context, trace.Span) {
+	return tracing.Start(ctx, name,
+		attribute.String("app.source", worker.appSrcName),
+		attribute.String("app.name", worker.appDeployInfo.AppName),
+		attribute.String("k8s.pod.name", worker.targetPodName))
+}
+
 // downloadWorkerHandler schedules the download workers to download app/s
 func (pplnPhase *PipelinePhase) downloadWorkerHandler(ctx context.Context, ppln *AppInstallPipeline, maxWorkers uint64, scheduleDownloadsWaiter *sync.WaitGroup) {
 
@@ -622,6 +641,9 @@ func (ppln *AppInstallPipeline) shutdownPipelinePhase(ctx context.Context, phase
 
 // downloadPhaseManager creates download phase manager for the install pipeline
 func (ppln *AppInstallPipeline) downloadPhaseManager(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "AppFramework download phase")
+	defer span.End()
+
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("downloadPhaseManager")
 	scopedLog.Info("Starting Download phase manager")
@@ -654,6 +676,7 @@ downloadPhase:
 				if isPhaseMaxRetriesReached(ctx, phaseInfo, downloadWorker.afwConfig) {
 
 					downloadWorker.appDeployInfo.PhaseInfo.Status = enterpriseApi.AppPkgDownloadError
+					publishAppEvent(ctx, downloadWorker, corev1.EventTypeWarning, AppDownloadFailed, fmt.Sprintf("download of app %s failed after %d attempts", downloadWorker.appDeployInfo.AppName, phaseInfo.FailCount))
 					ppln.deleteWorkerFromPipelinePhase(ctx, phaseInfo.Phase, downloadWorker)
 				} else if isPhaseStatusComplete(phaseInfo) {
 					ppln.transitionWorkerPhase(ctx, downloadWorker, enterpriseApi.PhaseDownload, enterpriseApi.PhasePodCopy)
@@ -693,10 +716,19 @@ func markWorkerPhaseInstallationComplete(ctx context.Context, phaseInfo *enterpr
 
 			//For now, set the deploy status as complete. Eventually, we can phase it out
 			worker.appDeployInfo.DeployStatus = enterpriseApi.DeployStatusComplete
+			publishAppEvent(ctx, worker, corev1.EventTypeNormal, AppInstalled, fmt.Sprintf("installed app %s on all the pods", worker.appDeployInfo.AppName))
 		}
+	} else {
+		publishAppEvent(ctx, worker, corev1.EventTypeNormal, AppInstalled, fmt.Sprintf("installed app %s on pod %s", worker.appDeployInfo.AppName, worker.targetPodName))
 	}
 }
 
+// publishAppEvent publishes an event on the custom resource of a pipeline worker
+func publishAppEvent(ctx context.Context, worker *PipelineWorker, eventType string, reason EventReason, message string) {
+	eventPublisher, _ := newK8EventPublisher(worker.cr)
+	eventPublisher.publishEvent(ctx, eventType, reason, message)
+}
+
 // installApp installs an app for an install worker
 func installApp(rctx context.Context, localCtx *localScopePlaybookContext, cr splcommon.MetaObject, phaseInfo *enterpriseApi.PhaseInfo) error {
 	worker := localCtx.worker
@@ -854,6 +886,9 @@ func cleanupApp(rctx context.Context, localCtx *localScopePlaybookContext, cr sp
 
 // runPlaybook implements the playbook for local scoped app install
 func (localCtx *localScopePlaybookContext) runPlaybook(rctx context.Context) error {
+	rctx, span := startWorkerSpan(rctx, "install", localCtx.worker)
+	defer span.End()
+
 	worker := localCtx.worker
 	cr := worker.cr
 	reqLogger := log.FromContext(rctx)
@@ -926,6 +961,9 @@ func extractClusterScopedAppOnPod(ctx context.Context, worker *PipelineWorker, a
 
 // runPodCopyWorker runs one pod copy worker
 func runPodCopyWorker(ctx context.Context, worker *PipelineWorker, ch chan struct{}) {
+	ctx, span := startWorkerSpan(ctx, "podCopy", worker)
+	defer span.End()
+
 	cr := worker.cr
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("runPodCopyWorker").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace(), "app name", worker.appDeployInfo.AppName, "pod", worker.targetPodName)
@@ -1027,6 +1065,9 @@ podCopyHandler:
 
 // podCopyPhaseManager creates pod copy phase manager for the install pipeline
 func (ppln *AppInstallPipeline) podCopyPhaseManager(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "AppFramework podCopy phase")
+	defer span.End()
+
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("podCopyPhaseManager")
 	scopedLog.Info("Starting Pod copy phase manager")
@@ -1059,6 +1100,7 @@ podCopyPhase:
 				if isPhaseMaxRetriesReached(ctx, phaseInfo, podCopyWorker.afwConfig) {
 
 					podCopyWorker.appDeployInfo.PhaseInfo.Status = enterpriseApi.AppPkgPodCopyError
+					publishAppEvent(ctx, podCopyWorker, corev1.EventTypeWarning, AppInstallFailed, fmt.Sprintf("copy of app %s to pod %s failed after %d attempts", podCopyWorker.appDeployInfo.AppName, podCopyWorker.targetPodName, phaseInfo.FailCount))
 					ppln.deleteWorkerFromPipelinePhase(ctx, phaseInfo.Phase, podCopyWorker)
 				} else if isPhaseStatusComplete(phaseInfo) {
 					// For cluster scoped apps, just delete the worker. install handler will trigger the bundle push
@@ -1249,6 +1291,9 @@ installHandler:
 
 // installPhaseManager creates install phase manager for the afw installation pipeline
 func (ppln *AppInstallPipeline) installPhaseManager(ctx context.Context) {
+	ctx, span := tracing.Start(ctx, "AppFramework install phase")
+	defer span.End()
+
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("installPhaseManager")
 	scopedLog.Info("Starting Install phase manager")
@@ -1301,6 +1346,7 @@ installPhase:
 				phaseInfo := getPhaseInfoByPhaseType(ctx, installWorker, enterpriseApi.PhaseInstall)
 				if isPhaseMaxRetriesReached(ctx, phaseInfo, installWorker.afwConfig) {
 					phaseInfo.Status = enterpriseApi.AppPkgInstallError
+					publishAppEvent(ctx, installWorker, corev1.EventTypeWarning, AppInstallFailed, fmt.Sprintf("install of app %s on pod %s failed after %d attempts", installWorker.appDeployInfo.AppName, installWorker.targetPodName, phaseInfo.FailCount))
 					ppln.deleteWorkerFromPipelinePhase(ctx, phaseInfo.Phase, installWorker)
 				} else if isPhaseStatusComplete(phaseInfo) {
 					ppln.deleteWorkerFromPipelinePhase(ctx, phaseInfo.Phase, installWorker)
@@ -1408,9 +1454,9 @@ func isAppInstallationCompleteOnAllReplicas(auxPhaseInfo []enterpriseApi.PhaseIn
 	return true
 }
 
-// isClusterScoped checks whether current cr is a SHC or a CM
+// isClusterScoped checks whether current cr is a SHC, a CM or a deployment server
 func isClusterScoped(kind string) bool {
-	return kind == "ClusterMaster" || kind == "ClusterManager" || kind == "SearchHeadCluster"
+	return kind == "ClusterMaster" || kind == "ClusterManager" || kind == "SearchHeadCluster" || kind == "DeploymentServer"
 }
 
 // checkIfBundlePushIsDone checks if the bundle push is done, if there are cluster scoped apps
@@ -1488,9 +1534,11 @@ func afwGetReleventStatefulsetByKind(ctx context.Context, cr splcommon.MetaObjec
 	case "ClusterMaster":
 		instanceID = SplunkClusterMaster
 	case "ClusterManager":
-		instanceID = SplunkClusterManager
+		instanceID = getClusterManagerInstanceType(cr)
 	case "MonitoringConsole":
 		instanceID = SplunkMonitoringConsole
+	case "DeploymentServer":
+		instanceID = SplunkDeploymentServer
 	default:
 		return nil
 	}
@@ -1539,6 +1587,17 @@ func getSHCPlaybookContext(ctx context.Context, client splcommon.ControllerClien
 	}
 }
 
+// getDeploymentServerPlaybookContext returns the deployment server playbook context
+func getDeploymentServerPlaybookContext(ctx context.Context, client splcommon.ControllerClient, cr splcommon.MetaObject, afwPipeline *AppInstallPipeline, podName string, podExecClient splutil.PodExecClientImpl) *DeploymentServerPlaybookContext {
+	return &DeploymentServerPlaybookContext{
+		client:        client,
+		cr:            cr,
+		afwPipeline:   afwPipeline,
+		targetPodName: podName,
+		podExecClient: podExecClient,
+	}
+}
+
 // getLocalScopePlaybookContext returns the local scoped app install playbook context
 func getLocalScopePlaybookContext(ctx context.Context, installWorker *PipelineWorker, sem chan struct{}, podExecClient splutil.PodExecClientImpl) *localScopePlaybookContext {
 	return &localScopePlaybookContext{
@@ -1576,6 +1635,8 @@ func getClusterScopePlaybookContext(ctx context.Context, client splcommon.Contro
 		return getIdxcPlaybookContext(ctx, client, cr, afwPipeline, podName, podExecClient)
 	case "SearchHeadCluster":
 		return getSHCPlaybookContext(ctx, client, cr, afwPipeline, podName, podExecClient)
+	case "DeploymentServer":
+		return getDeploymentServerPlaybookContext(ctx, client, cr, afwPipeline, podName, podExecClient)
 	default:
 		return nil
 	}
@@ -1702,7 +1763,7 @@ func (shcPlaybookContext *SHCPlaybookContext) setLivenessProbeLevel(ctx context.
 			shcPlaybookContext.podExecClient.SetTargetPodName(ctx, targetPodname)
 		}()
 
-		err = setProbeLevelOnCRPods(ctx, shcPlaybookContext.cr, *shcSts.Spec.Replicas, shcPlaybookContext.podExecClient, probeLevel)
+		err = setProbeLevelOnCRPods(ctx, shcPlaybookContext.client, shcPlaybookContext.cr, *shcSts.Spec.Replicas, shcPlaybookContext.podExecClient, probeLevel)
 		if err != nil {
 			scopedLog.Error(err, "Unable to set the Liveness probe level")
 			return err
@@ -1721,6 +1782,8 @@ func getClusterScopedAppsLocOnPod(cr splcommon.MetaObject) string {
 		return idxcAppsLocationOnClusterManager
 	case "SearchHeadCluster":
 		return shcAppsLocationOnDeployer
+	case "DeploymentServer":
+		return deploymentAppsLocationOnDeploymentServer
 	default:
 		return ""
 	}
@@ -1745,6 +1808,9 @@ func adjustClusterAppsFilePermissions(ctx context.Context, podExecClient splutil
 
 // runPlaybook will implement the bundle push logic for SHC
 func (shcPlaybookContext *SHCPlaybookContext) runPlaybook(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "installClusterScopedApps")
+	defer span.End()
+
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("runPlaybook").WithValues("crName", shcPlaybookContext.cr.GetName(), "namespace", shcPlaybookContext.cr.GetNamespace())
 
@@ -1798,6 +1864,7 @@ func (shcPlaybookContext *SHCPlaybookContext) runPlaybook(ctx context.Context) e
 		}
 
 		err = shcPlaybookContext.triggerBundlePush(ctx)
+		audit.Log(ctx, audit.BundlePushed, shcPlaybookContext.targetPodName, "App Framework cluster scoped apps changed", err)
 		if err != nil {
 			scopedLog.Error(err, "failed to apply SHC Bundle")
 			return err
@@ -1905,7 +1972,7 @@ func (idxcPlaybookContext *IdxcPlaybookContext) setLivenessProbeLevel(ctx contex
 				continue
 			}
 
-			err = setProbeLevelOnCRPods(ctx, &idxcCR, *idxcSts.Spec.Replicas, idxcPlaybookContext.podExecClient, probeLevel)
+			err = setProbeLevelOnCRPods(ctx, idxcPlaybookContext.client, &idxcCR, *idxcSts.Spec.Replicas, idxcPlaybookContext.podExecClient, probeLevel)
 			if err != nil {
 				scopedLog.Error(err, "Unable to set the Liveness probe level")
 				return err
@@ -1921,6 +1988,8 @@ func (idxcPlaybookContext *IdxcPlaybookContext) setLivenessProbeLevel(ctx contex
 // 1. If the bundle push is not in progress, run the logic to push the bundle from CM to indexer peers
 // 2. OR else, if the bundle push is already in progress, check the status of bundle push
 func (idxcPlaybookContext *IdxcPlaybookContext) runPlaybook(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "installClusterScopedApps")
+	defer span.End()
 
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("RunPlaybook").WithValues("crName", idxcPlaybookContext.cr.GetName(), "namespace", idxcPlaybookContext.cr.GetNamespace())
@@ -1957,6 +2026,7 @@ func (idxcPlaybookContext *IdxcPlaybookContext) runPlaybook(ctx context.Context)
 		// run the command to apply cluster bundle
 		scopedLog.Info("running command to apply IndexerCluster Bundle")
 		err = idxcPlaybookContext.triggerBundlePush(ctx)
+		audit.Log(ctx, audit.BundlePushed, idxcPlaybookContext.targetPodName, "App Framework cluster scoped apps changed", err)
 		if err != nil {
 			scopedLog.Error(err, "failed to apply IndexerCluster Bundle")
 			return err
@@ -1973,6 +2043,50 @@ func (idxcPlaybookContext *IdxcPlaybookContext) runPlaybook(ctx context.Context)
 	return nil
 }
 
+// runPlaybook reloads the deployment server, so that the apps synced into etc/deployment-apps
+// are served to the deployment clients. Unlike the cluster bundle pushes, the reload is a sync call
+func (dsPlaybookContext *DeploymentServerPlaybookContext) runPlaybook(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "installClusterScopedApps")
+	defer span.End()
+
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("runPlaybook").WithValues("crName", dsPlaybookContext.cr.GetName(), "namespace", dsPlaybookContext.cr.GetNamespace())
+
+	appDeployContext := dsPlaybookContext.afwPipeline.appDeployContext
+
+	switch appDeployContext.BundlePushStatus.BundlePushStage {
+	case enterpriseApi.BundlePushPending, enterpriseApi.BundlePushInProgress:
+		// Adjust the file permissions
+		err := adjustClusterAppsFilePermissions(ctx, dsPlaybookContext.podExecClient)
+		if err != nil {
+			scopedLog.Error(err, "failed to adjust the file permissions")
+			return err
+		}
+
+		scopedLog.Info("running command to reload the deployment server")
+		streamOptions := splutil.NewStreamOptionsObject(reloadDeploymentServerCmdStr)
+		stdOut, stdErr, err := dsPlaybookContext.podExecClient.RunPodExecCommand(ctx, streamOptions, []string{"/bin/sh"})
+		if err != nil {
+			err = fmt.Errorf("error while reloading the deployment server. stdout: %s, stderr: %s, err: %v", stdOut, stdErr, err)
+			scopedLog.Error(err, "failed to reload the deployment server")
+			return err
+		}
+
+		// set the bundle push status to complete
+		setBundlePushState(ctx, dsPlaybookContext.afwPipeline, enterpriseApi.BundlePushComplete)
+
+		// reset the retry count
+		dsPlaybookContext.afwPipeline.appDeployContext.BundlePushStatus.RetryCount = 0
+
+		// set the state to install complete for all the cluster scoped apps
+		setInstallStateForClusterScopedApps(ctx, appDeployContext)
+	default:
+		return fmt.Errorf("invalid bundle push state=%s", bundlePushStateAsStr(ctx, appDeployContext.BundlePushStatus.BundlePushStage))
+	}
+
+	return nil
+}
+
 // getSslCliOption gets the ssl cli option for installing ES app.
 // Returns `strict` if not configured. Note: Validation of spec done already
 // Reference: https://docs.splunk.com/Documentation/ES/latest/Install/InstallEnterpriseSecuritySHC
@@ -2023,6 +2137,9 @@ func handleEsappPostinstall(rctx context.Context, preCtx *premiumAppScopePlayboo
 //  2. Runs the post install command for the ES app
 //  3. Sets the bundle push flag for the deployer only
 func (preCtx *premiumAppScopePlaybookContext) runPlaybook(rctx context.Context) error {
+	rctx, span := startWorkerSpan(rctx, "installPremiumApp", preCtx.localCtx.worker)
+	defer span.End()
+
 	cr := preCtx.cr
 	worker := preCtx.localCtx.worker
 	appSrcSpec := preCtx.appSrcSpec
@@ -2114,6 +2231,8 @@ func isPhaseInfoEligibleForSchedulerEntry(ctx context.Context, appSrcName string
 func afwSchedulerEntry(ctx context.Context, client splcommon.ControllerClient, cr splcommon.MetaObject, appDeployContext *enterpriseApi.AppDeploymentContext, appFrameworkConfig *enterpriseApi.AppFrameworkSpec) (bool, error) {
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("afwSchedulerEntry").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+	ctx, span := tracing.Start(ctx, "AppFramework")
+	defer span.End()
 
 	// return error, if there is no storage defined for the Operator pod
 	if !isPersistantVolConfigured() {
@@ -259,13 +259,18 @@ func TestMarkWorkerPhaseInstallationComplete(t *testing.T) {
 				Kind: "ClusterManager",
 			},
 		},
+		appDeployInfo: &enterpriseApi.AppDeploymentInfo{AppName: "app1.tgz"},
+		targetPodName: "splunk-s1-cluster-manager-0",
 	}
+	recorder, restore := setFakeEventRecorder()
+	defer restore()
 
 	// Mark basic status for non fan-out CRs
 	markWorkerPhaseInstallationComplete(ctx, &phaseInfo, &worker)
 	if phaseInfo.Status != enterpriseApi.AppPkgInstallComplete || phaseInfo.FailCount != 0 {
 		t.Errorf("Phase info not marked as install complete properly")
 	}
+	checkEvents(t, recorder, "Normal AppInstalled installed app app1.tgz on pod splunk-s1-cluster-manager-0")
 
 	// Fan out CRs test
 	worker.cr = &enterpriseApi.Standalone{
@@ -279,6 +284,7 @@ func TestMarkWorkerPhaseInstallationComplete(t *testing.T) {
 	}
 
 	worker.appDeployInfo = &enterpriseApi.AppDeploymentInfo{
+		AppName:      "app1.tgz",
 		AuxPhaseInfo: make([]enterpriseApi.PhaseInfo, 1),
 	}
 
@@ -294,7 +300,7 @@ func TestMarkWorkerPhaseInstallationComplete(t *testing.T) {
 		worker.appDeployInfo.DeployStatus != enterpriseApi.DeployStatusComplete {
 		t.Errorf("Aux phase info for fanout CRs not working")
 	}
-
+	checkEvents(t, recorder, "Normal AppInstalled installed app app1.tgz on all the pods")
 }
 
 func TestGetApplicablePodNameForAppFramework(t *testing.T) {
@@ -335,6 +341,13 @@ func TestGetApplicablePodNameForAppFramework(t *testing.T) {
 		t.Errorf("Unable to fetch correct pod name. Expected %s, returned %s", expectedPodName, returnedPodName)
 	}
 
+	cr.TypeMeta.Kind = "DeploymentServer"
+	expectedPodName = "splunk-stack1-deployment-server-0"
+	returnedPodName = getApplicablePodNameForAppFramework(&cr, podID)
+	if expectedPodName != returnedPodName {
+		t.Errorf("Unable to fetch correct pod name. Expected %s, returned %s", expectedPodName, returnedPodName)
+	}
+
 	cr.TypeMeta.Kind = "SearchHeadCluster"
 	expectedPodName = "splunk-stack1-deployer-0"
 	returnedPodName = getApplicablePodNameForAppFramework(&cr, podID)
@@ -2364,6 +2377,112 @@ func TestIDXCRunPlaybook(t *testing.T) {
 	mockPodExecClient.CheckPodExecCommands(t, "idxcPlayBookContext")
 }
 
+func TestDeploymentServerRunPlaybook(t *testing.T) {
+	ctx := context.TODO()
+	cr := enterpriseApi.DeploymentServer{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "DeploymentServer",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+
+	c := spltest.NewMockClient()
+	var appDeployContext *enterpriseApi.AppDeploymentContext = &enterpriseApi.AppDeploymentContext{
+		AppsStatusMaxConcurrentAppDownloads: 10,
+	}
+
+	appDeployContext.AppFrameworkConfig.AppSources = []enterpriseApi.AppSourceSpec{
+		{
+			Name: "appSrc1",
+			AppSourceDefaultSpec: enterpriseApi.AppSourceDefaultSpec{
+				Scope: enterpriseApi.ScopeCluster,
+			},
+		},
+	}
+	appDeployContext.AppsSrcDeployStatus = make(map[string]enterpriseApi.AppSrcDeployInfo)
+	appDeployInfoList := []enterpriseApi.AppDeploymentInfo{
+		{
+			AppName: "app1.tgz",
+			PhaseInfo: enterpriseApi.PhaseInfo{
+				Phase:     enterpriseApi.PhasePodCopy,
+				Status:    enterpriseApi.AppPkgPodCopyComplete,
+				FailCount: 0,
+			},
+			ObjectHash: "abcd1111",
+			Size:       10,
+		},
+	}
+
+	var appSrcDeployInfo enterpriseApi.AppSrcDeployInfo
+	appSrcDeployInfo.AppDeploymentInfoList = appDeployInfoList
+	appDeployContext.AppsSrcDeployStatus["appSrc1"] = appSrcDeployInfo
+
+	appDeployContext.BundlePushStatus.BundlePushStage = enterpriseApi.BundlePushPending
+	afwPipeline := initAppInstallPipeline(ctx, appDeployContext, c, &cr)
+	targetPodName := getApplicablePodNameForAppFramework(&cr, 0)
+	kind := cr.GetObjectKind().GroupVersionKind().Kind
+
+	podExecCommands := []string{
+		fmt.Sprintf(cmdSetFilePermissionsToRW, deploymentAppsLocationOnDeploymentServer),
+		reloadDeploymentServerCmdStr,
+	}
+	mockPodExecReturnContexts := []*spltest.MockPodExecReturnContext{
+		{
+			StdOut: "",
+			StdErr: "",
+		},
+		// this is for reloading the deployment server
+		{
+			StdOut: "Reloading serverclass(es).",
+			StdErr: "",
+		},
+	}
+
+	var mockPodExecClient *spltest.MockPodExecClient = &spltest.MockPodExecClient{Cr: &cr}
+	mockPodExecClient.AddMockPodExecReturnContexts(ctx, podExecCommands, mockPodExecReturnContexts...)
+
+	playbookContext := getClusterScopePlaybookContext(ctx, c, &cr, afwPipeline, targetPodName, kind, mockPodExecClient)
+	if _, ok := playbookContext.(*DeploymentServerPlaybookContext); !ok {
+		t.Errorf("getClusterScopePlaybookContext() should have returned a deployment server playbook context")
+	}
+
+	// If the podExec failed for changing the file permissions, should return an error
+	mockPodExecReturnContexts[0].StdErr = "Failed"
+	err := playbookContext.runPlaybook(ctx)
+	if err == nil {
+		t.Errorf("runPlaybook() should return an error if the command %v execution fails", podExecCommands[0])
+	}
+	mockPodExecReturnContexts[0].StdErr = ""
+
+	// If the reload fails, should return an error and keep the bundle push pending
+	mockPodExecReturnContexts[1].Err = fmt.Errorf("reload failed")
+	err = playbookContext.runPlaybook(ctx)
+	if err == nil || getBundlePushState(afwPipeline) != enterpriseApi.BundlePushPending {
+		t.Errorf("runPlaybook() should return an error if the deployment server reload fails, bundle push state=%s", bundlePushStateAsStr(ctx, getBundlePushState(afwPipeline)))
+	}
+	mockPodExecReturnContexts[1].Err = nil
+
+	// reload is a sync call, so the bundle push is complete right away
+	err = playbookContext.runPlaybook(ctx)
+	if err != nil || getBundlePushState(afwPipeline) != enterpriseApi.BundlePushComplete {
+		t.Errorf("runPlaybook() should not have returned error or wrong bundle push state, err=%v, bundle push state=%s", err, bundlePushStateAsStr(ctx, getBundlePushState(afwPipeline)))
+	}
+	if appDeployContext.AppsSrcDeployStatus["appSrc1"].AppDeploymentInfoList[0].PhaseInfo.Status != enterpriseApi.AppPkgInstallComplete {
+		t.Errorf("runPlaybook() should have marked the cluster scoped apps as installed")
+	}
+
+	// invalid bundle push state
+	err = playbookContext.runPlaybook(ctx)
+	if err == nil {
+		t.Errorf("runPlaybook() should have returned error since we passed invalid bundle push state")
+	}
+
+	mockPodExecClient.CheckPodExecCommands(t, "DeploymentServerPlaybookContext")
+}
+
 func TestSetLivenessProbeLevelForSHC(t *testing.T) {
 	ctx := context.TODO()
 	cr := &enterpriseApi.SearchHeadCluster{
@@ -4031,6 +4150,7 @@ func TestGetTelAppNameExtension(t *testing.T) {
 		"SearchHeadCluster": "shc",
 		"ClusterMaster":     "cmaster",
 		"ClusterManager":    "cmanager",
+		"DeploymentServer":  "dserver",
 	}
 
 	// Test all CR kinds
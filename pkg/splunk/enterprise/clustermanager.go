@@ -25,9 +25,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/go-logr/logr"
+	"github.com/splunk/splunk-operator/pkg/splunk/audit"
 	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -38,6 +40,8 @@ import (
 
 // ApplyClusterManager reconciles the state of a Splunk Enterprise cluster manager.
 func ApplyClusterManager(ctx context.Context, client splcommon.ControllerClient, cr *enterpriseApi.ClusterManager) (reconcile.Result, error) {
+	ctx, span := tracing.Start(ctx, "ApplyClusterManager")
+	defer span.End()
 
 	// unless modified, reconcile for this object will be requeued after 5 seconds
 	result := reconcile.Result{
@@ -46,7 +50,9 @@ func ApplyClusterManager(ctx context.Context, client splcommon.ControllerClient,
 	}
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("ApplyClusterManager")
-	eventPublisher, _ := newK8EventPublisher(client, cr)
+	eventPublisher, _ := newK8EventPublisher(cr)
+	defer eventPublisher.publishPhaseChange(ctx, cr.Status.Phase, &cr.Status.Phase)
+	ctx = audit.NewContext(ctx, cr, &cr.Status.AuditTrail)
 
 	if cr.Status.ResourceRevMap == nil {
 		cr.Status.ResourceRevMap = make(map[string]string)
@@ -58,15 +64,27 @@ func ApplyClusterManager(ctx context.Context, client splcommon.ControllerClient,
 		return result, err
 	}
 
+	// take over the resources of the cluster master of the same name, if requested
+	_, adopt := cr.GetAnnotations()[enterpriseApi.ClusterManagerAdoptClusterMasterAnnotation]
+	if adopt && !cr.Status.ClusterMasterAdopted && cr.ObjectMeta.DeletionTimestamp == nil {
+		err = adoptClusterMaster(ctx, client, cr)
+		if err != nil {
+			eventPublisher.Warning(ctx, AdoptionFailed, fmt.Sprintf("adopt cluster master failed %s", err.Error()))
+			return result, err
+		}
+		eventPublisher.Normal(ctx, ClusterMasterAdopted, fmt.Sprintf("adopted the resources of cluster master %s", cr.GetName()))
+	}
+	instanceType := getClusterManagerInstanceType(cr)
+
 	// updates status after function completes
 	cr.Status.Phase = enterpriseApi.PhaseError
-	cr.Status.Selector = fmt.Sprintf("app.kubernetes.io/instance=splunk-%s-%s", cr.GetName(), "cluster-manager")
+	cr.Status.Selector = fmt.Sprintf("app.kubernetes.io/instance=splunk-%s-%s", cr.GetName(), instanceType)
 
 	if !reflect.DeepEqual(cr.Status.SmartStore, cr.Spec.SmartStore) ||
-		AreRemoteVolumeKeysChanged(ctx, client, cr, SplunkClusterManager, &cr.Spec.SmartStore, cr.Status.ResourceRevMap, &err) {
+		AreRemoteVolumeKeysChanged(ctx, client, cr, instanceType, &cr.Spec.SmartStore, cr.Status.ResourceRevMap, &err) {
 
 		if err != nil {
-			eventPublisher.Warning(ctx, "AreRemoteVolumeKeysChanged", fmt.Sprintf("check remote volume key change failed %s", err.Error()))
+			eventPublisher.Warning(ctx, SmartStoreUpdateFailed, fmt.Sprintf("check remote volume key change failed %s", err.Error()))
 			return result, err
 		}
 
@@ -104,7 +122,7 @@ func ApplyClusterManager(ctx context.Context, client splcommon.ControllerClient,
 	if len(cr.Spec.AppFrameworkConfig.AppSources) != 0 {
 		err := initAndCheckAppInfoStatus(ctx, client, cr, &cr.Spec.AppFrameworkConfig, &cr.Status.AppContext)
 		if err != nil {
-			eventPublisher.Warning(ctx, "initAndCheckAppInfoStatus", fmt.Sprintf("init and check app info status failed %s", err.Error()))
+			eventPublisher.Warning(ctx, AppRepoCheckFailed, fmt.Sprintf("init and check app info status failed %s", err.Error()))
 			cr.Status.AppContext.IsDeploymentInProgress = false
 			return result, err
 		}
@@ -114,7 +132,7 @@ func ApplyClusterManager(ctx context.Context, client splcommon.ControllerClient,
 	namespaceScopedSecret, err := ApplySplunkConfig(ctx, client, cr, cr.Spec.CommonSplunkSpec, SplunkIndexer)
 	if err != nil {
 		scopedLog.Error(err, "create or update general config failed", "error", err.Error())
-		eventPublisher.Warning(ctx, "ApplySplunkConfig", fmt.Sprintf("create or update general config failed with error %s", err.Error()))
+		eventPublisher.Warning(ctx, ConfigApplyFailed, fmt.Sprintf("create or update general config failed with error %s", err.Error()))
 		return result, err
 	}
 
@@ -132,7 +150,7 @@ func ApplyClusterManager(ctx context.Context, client splcommon.ControllerClient,
 		// remove the entry for this CR type from configMap or else
 		// just decrement the refCount for this CR type.
 		if len(cr.Spec.AppFrameworkConfig.AppSources) != 0 {
-			err = UpdateOrRemoveEntryFromConfigMapLocked(ctx, client, cr, SplunkClusterManager)
+			err = UpdateOrRemoveEntryFromConfigMapLocked(ctx, client, cr, instanceType)
 			if err != nil {
 				return result, err
 			}
@@ -144,7 +162,7 @@ func ApplyClusterManager(ctx context.Context, client splcommon.ControllerClient,
 			return result, err
 		}
 
-		DeleteOwnerReferencesForResources(ctx, client, cr, &cr.Spec.SmartStore, SplunkClusterManager)
+		DeleteOwnerReferencesForResources(ctx, client, cr, &cr.Spec.SmartStore, instanceType)
 		terminating, err := splctrl.CheckForDeletion(ctx, cr, client)
 
 		if terminating && err != nil { // don't bother if no error, since it will just be removed immmediately after
@@ -153,13 +171,25 @@ func ApplyClusterManager(ctx context.Context, client splcommon.ControllerClient,
 			result.Requeue = false
 		}
 		if err != nil {
-			eventPublisher.Warning(ctx, "Delete", fmt.Sprintf("delete custom resource failed %s", err.Error()))
+			eventPublisher.Warning(ctx, DeleteFailed, fmt.Sprintf("delete custom resource failed %s", err.Error()))
 		}
 		return result, err
 	}
 
 	// create or update a regular service for the cluster manager
-	err = splctrl.ApplyService(ctx, client, getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkClusterManager, false))
+	err = splctrl.ApplyService(ctx, client, getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, instanceType, false))
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the services of each type of traffic and their routes
+	err = ApplySplunkTrafficServices(ctx, client, cr, &cr.Spec.CommonSplunkSpec, instanceType, false)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the certificates of the ports and the secret mounting them
+	err = ApplySplunkTLS(ctx, client, cr, &cr.Spec.CommonSplunkSpec, instanceType)
 	if err != nil {
 		return result, err
 	}
@@ -177,7 +207,7 @@ func ApplyClusterManager(ctx context.Context, client splcommon.ControllerClient,
 		return result, err
 	}
 
-	clusterManagerManager := splctrl.DefaultStatefulSetPodManager{}
+	clusterManagerManager := splctrl.DefaultStatefulSetPodManager{VolumeExpansion: &cr.Status.VolumeExpansion}
 	phase, err := clusterManagerManager.Update(ctx, client, statefulSet, 1)
 	if err != nil {
 		return result, err
@@ -223,6 +253,11 @@ func ApplyClusterManager(ctx context.Context, client splcommon.ControllerClient,
 
 		finalResult := handleAppFrameworkActivity(ctx, client, cr, &cr.Status.AppContext, &cr.Spec.AppFrameworkConfig)
 		result = *finalResult
+
+		// retry a bundle push pending a maintenance window
+		if cr.Status.Phase == enterpriseApi.PhasePendingMaintenanceWindow {
+			updateReconcileRequeueTime(ctx, &result, maintenanceWindowRetryDelay, true)
+		}
 	}
 	// RequeueAfter if greater than 0, tells the Controller to requeue the reconcile key after the Duration.
 	// Implies that Requeue is true, there is no need to set Requeue to true at the same time as RequeueAfter.
@@ -243,7 +278,7 @@ type clusterManagerPodManager struct {
 
 // getClusterManagerClient for clusterManagerPodManager returns a SplunkClient for cluster manager
 func (mgr *clusterManagerPodManager) getClusterManagerClient(cr *enterpriseApi.ClusterManager) *splclient.SplunkClient {
-	fqdnName := splcommon.GetServiceFQDN(cr.GetNamespace(), GetSplunkServiceName(SplunkClusterManager, cr.GetName(), false))
+	fqdnName := splcommon.GetServiceFQDN(cr.GetNamespace(), GetSplunkServiceName(getClusterManagerInstanceType(cr), cr.GetName(), false))
 	return mgr.newSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", string(mgr.secrets.Data["password"]))
 }
 
@@ -271,11 +306,11 @@ func validateClusterManagerSpec(ctx context.Context, c splcommon.ControllerClien
 func getClusterManagerStatefulSet(ctx context.Context, client splcommon.ControllerClient, cr *enterpriseApi.ClusterManager) (*appsv1.StatefulSet, error) {
 	var extraEnvVar []corev1.EnvVar
 
-	ss, err := getSplunkStatefulSet(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkClusterManager, 1, extraEnvVar)
+	ss, err := getSplunkStatefulSet(ctx, client, cr, &cr.Spec.CommonSplunkSpec, getClusterManagerInstanceType(cr), 1, extraEnvVar)
 	if err != nil {
 		return ss, err
 	}
-	smartStoreConfigMap := getSmartstoreConfigMap(ctx, client, cr, SplunkClusterManager)
+	smartStoreConfigMap := getSmartstoreConfigMap(ctx, client, cr, getClusterManagerInstanceType(cr))
 
 	if smartStoreConfigMap != nil {
 		setupInitContainer(&ss.Spec.Template, cr.Spec.Image, cr.Spec.ImagePullPolicy, commandForCMSmartstore, cr.Spec.CommonSplunkSpec.EtcVolumeStorageConfig.EphemeralStorage)
@@ -290,46 +325,64 @@ func getClusterManagerStatefulSet(ctx context.Context, client splcommon.Controll
 func CheckIfsmartstoreConfigMapUpdatedToPod(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApi.ClusterManager, podExecClient splutil.PodExecClientImpl) error {
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("CheckIfsmartstoreConfigMapUpdatedToPod").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
-	eventPublisher, _ := newK8EventPublisher(c, cr)
+	eventPublisher, _ := newK8EventPublisher(cr)
 
 	command := fmt.Sprintf("cat /mnt/splunk-operator/local/%s", configToken)
 	streamOptions := splutil.NewStreamOptionsObject(command)
 
 	stdOut, stdErr, err := podExecClient.RunPodExecCommand(ctx, streamOptions, []string{"/bin/sh"})
 	if err != nil || stdErr != "" {
-		eventPublisher.Warning(ctx, "PodExecCommand", fmt.Sprintf("Failed to check config token value on pod. stdout=%s, stderror=%s, error=%v", stdOut, stdErr, err))
+		eventPublisher.Warning(ctx, SmartStoreUpdateFailed, fmt.Sprintf("Failed to check config token value on pod. stdout=%s, stderror=%s, error=%v", stdOut, stdErr, err))
 		return fmt.Errorf("failed to check config token value on pod. stdout=%s, stderror=%s, error=%v", stdOut, stdErr, err)
 	}
 
-	smartStoreConfigMap := getSmartstoreConfigMap(ctx, c, cr, SplunkClusterManager)
+	smartStoreConfigMap := getSmartstoreConfigMap(ctx, c, cr, getClusterManagerInstanceType(cr))
 	if smartStoreConfigMap != nil {
 		tokenFromConfigMap := smartStoreConfigMap.Data[configToken]
 		if tokenFromConfigMap == stdOut {
 			scopedLog.Info("Token Matched.", "on Pod=", stdOut, "from configMap=", tokenFromConfigMap)
 			return nil
 		}
-		eventPublisher.Warning(ctx, "getSmartstoreConfigMap", fmt.Sprintf("waiting for the configMap update to the Pod. Token on Pod=%s, Token from configMap=%s", stdOut, tokenFromConfigMap))
+		eventPublisher.Warning(ctx, SmartStoreUpdateFailed, fmt.Sprintf("waiting for the configMap update to the Pod. Token on Pod=%s, Token from configMap=%s", stdOut, tokenFromConfigMap))
 		return fmt.Errorf("waiting for the configMap update to the Pod. Token on Pod=%s, Token from configMap=%s", stdOut, tokenFromConfigMap)
 	}
 
 	// Somehow the configmap was deleted, ideally this should not happen
-	eventPublisher.Warning(ctx, "getSmartstoreConfigMap", "smartstore ConfigMap is missing")
+	eventPublisher.Warning(ctx, SmartStoreUpdateFailed, "smartstore ConfigMap is missing")
 	return fmt.Errorf("smartstore ConfigMap is missing")
 }
 
 // PerformCmBundlePush initiates the bundle push from cluster manager
 func PerformCmBundlePush(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApi.ClusterManager) error {
+	// a bundle push requested with the annotation goes through the same steps as the one of an apps update
+	request, requested := cr.GetAnnotations()[enterpriseApi.ClusterManagerBundlePushAnnotation]
+	if requested && request != cr.Status.BundlePushTracker.LastBundlePushRequest {
+		cr.Status.BundlePushTracker.NeedToPushManagerApps = true
+		cr.Status.BundlePushTracker.LastBundlePushRequest = request
+	}
+
 	if !cr.Status.BundlePushTracker.NeedToPushManagerApps {
 		return nil
 	}
 
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("PerformCmBundlePush").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	// the bundle push may restart the peers, so it waits for a maintenance window
+	allowed, err := isInMaintenanceWindow(ctx, c, cr, &cr.Spec.CommonSplunkSpec)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		cr.Status.Phase = enterpriseApi.PhasePendingMaintenanceWindow
+		return nil
+	}
+
+	eventPublisher, _ := newK8EventPublisher(cr)
+
 	// Reconciler can be called for multiple reasons. If we are waiting on configMap update to happen,
 	// do not increment the Retry Count unless the last check was 5 seconds ago.
 	// This helps, to wait for the required time
-	//eventPublisher, _ := newK8EventPublisher(c, cr)
-
 	currentEpoch := time.Now().Unix()
 	if cr.Status.BundlePushTracker.LastCheckInterval+5 > currentEpoch {
 		return fmt.Errorf("will re-attempt to push the bundle after the 5 seconds period passed from last check. LastCheckInterval=%d, current epoch=%d", cr.Status.BundlePushTracker.LastCheckInterval, currentEpoch)
@@ -344,9 +397,9 @@ func PerformCmBundlePush(ctx context.Context, c splcommon.ControllerClient, cr *
 	// for the configMap update to the Pod before proceeding for the manager apps
 	// bundle push.
 
-	cmPodName := fmt.Sprintf("splunk-%s-%s-0", cr.GetName(), "cluster-manager")
+	cmPodName := fmt.Sprintf("splunk-%s-%s-0", cr.GetName(), getClusterManagerInstanceType(cr))
 	podExecClient := splutil.GetPodExecClient(c, cr, cmPodName)
-	err := CheckIfsmartstoreConfigMapUpdatedToPod(ctx, c, cr, podExecClient)
+	err = CheckIfsmartstoreConfigMapUpdatedToPod(ctx, c, cr, podExecClient)
 	if err != nil {
 		return err
 	}
@@ -358,44 +411,47 @@ func PerformCmBundlePush(ctx context.Context, c splcommon.ControllerClient, cr *
 	}
 
 	err = PushManagerAppsBundle(ctx, c, cr)
-	if err == nil {
-		scopedLog.Info("Bundle push success")
-		cr.Status.BundlePushTracker.NeedToPushManagerApps = false
+	audit.Log(ctx, audit.BundlePushed, cmPodName, "SmartStore configuration changed or bundle push requested", err)
+	if err != nil {
+		eventPublisher.Warning(ctx, BundlePushFailed, fmt.Sprintf("Bundle push failed %s", err.Error()))
+		return err
 	}
 
-	//eventPublisher.Warning(ctx, "BundlePush", fmt.Sprintf("Bundle push failed %s", err.Error()))
-	return err
+	scopedLog.Info("Bundle push success")
+	cr.Status.BundlePushTracker.NeedToPushManagerApps = false
+	eventPublisher.Normal(ctx, BundlePushSucceeded, "pushed the cluster bundle to the peers")
+	return nil
 }
 
 // PushManagerAppsBundle issues the REST command to for cluster manager bundle push
 func PushManagerAppsBundle(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApi.ClusterManager) error {
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("PushManagerApps").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
-	eventPublisher, _ := newK8EventPublisher(c, cr)
+	eventPublisher, _ := newK8EventPublisher(cr)
 
 	defaultSecretObjName := splcommon.GetNamespaceScopedSecretName(cr.GetNamespace())
 	defaultSecret, err := splutil.GetSecretByName(ctx, c, cr.GetNamespace(), cr.GetName(), defaultSecretObjName)
 	if err != nil {
-		eventPublisher.Warning(ctx, "PushManagerAppsBundle", fmt.Sprintf("Could not access default secret object to fetch admin password. Reason %v", err))
+		eventPublisher.Warning(ctx, BundlePushFailed, fmt.Sprintf("Could not access default secret object to fetch admin password. Reason %v", err))
 		return fmt.Errorf("could not access default secret object to fetch admin password. Reason %v", err)
 	}
 
 	//Get the admin password from the secret object
 	adminPwd, foundSecret := defaultSecret.Data["password"]
 	if !foundSecret {
-		eventPublisher.Warning(ctx, "PushManagerAppsBundle", "could not find admin password while trying to push the manager apps bundle")
+		eventPublisher.Warning(ctx, BundlePushFailed, "could not find admin password while trying to push the manager apps bundle")
 		return fmt.Errorf("could not find admin password while trying to push the manager apps bundle")
 	}
 
 	scopedLog.Info("Issuing REST call to push manager aps bundle")
 
 	managerIdxcName := cr.GetName()
-	fqdnName := splcommon.GetServiceFQDN(cr.GetNamespace(), GetSplunkServiceName(SplunkClusterManager, managerIdxcName, false))
+	fqdnName := splcommon.GetServiceFQDN(cr.GetNamespace(), GetSplunkServiceName(getClusterManagerInstanceType(cr), managerIdxcName, false))
 
 	// Get a Splunk client to execute the REST call
 	splunkClient := splclient.NewSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", string(adminPwd))
 
-	return splunkClient.BundlePush(true)
+	return splunkClient.BundlePush(ctx, true)
 }
 
 // helper function to get the list of ClusterManager types in the current namespace
@@ -423,14 +479,14 @@ func VerifyCMisMultisite(ctx context.Context, cr *enterpriseApi.ClusterManager,
 	scopedLog := reqLogger.WithName("Verify if Multisite Indexer Cluster").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
 	mgr := clusterManagerPodManager{log: scopedLog, cr: cr, secrets: namespaceScopedSecret, newSplunkClient: splclient.NewSplunkClient}
 	cm := mgr.getClusterManagerClient(cr)
-	clusterInfo, err := cm.GetClusterInfo(false)
+	clusterInfo, err := cm.GetClusterInfo(ctx, false)
 	if err != nil {
 		return nil, err
 	}
 	multiSite := clusterInfo.MultiSite
 	extraEnv := getClusterManagerExtraEnv(cr, &cr.Spec.CommonSplunkSpec)
 	if multiSite == "true" {
-		extraEnv = append(extraEnv, corev1.EnvVar{Name: "SPLUNK_SITE", Value: "site0"}, corev1.EnvVar{Name: "SPLUNK_MULTISITE_MASTER", Value: GetSplunkServiceName(SplunkClusterManager, cr.GetName(), false)})
+		extraEnv = append(extraEnv, corev1.EnvVar{Name: "SPLUNK_SITE", Value: "site0"}, corev1.EnvVar{Name: "SPLUNK_MULTISITE_MASTER", Value: GetSplunkServiceName(getClusterManagerInstanceType(cr), cr.GetName(), false)})
 	}
 	return extraEnv, err
 }
@@ -450,6 +450,19 @@ func TestPerformCmBundlePush(t *testing.T) {
 	if err != nil {
 		t.Errorf("Should not return an error when the Bundle push is not required. Error: %s", err.Error())
 	}
+
+	// A change of the bundle push annotation requests a bundle push, once
+	current.Annotations = map[string]string{enterpriseApi.ClusterManagerBundlePushAnnotation: "request1"}
+	current.Status.BundlePushTracker.LastCheckInterval = time.Now().Unix() - 1
+	err = PerformCmBundlePush(ctx, client, &current)
+	if err == nil || !current.Status.BundlePushTracker.NeedToPushManagerApps || current.Status.BundlePushTracker.LastBundlePushRequest != "request1" {
+		t.Errorf("Bundle push not attempted for a new bundle push request")
+	}
+	current.Status.BundlePushTracker.NeedToPushManagerApps = false
+	err = PerformCmBundlePush(ctx, client, &current)
+	if err != nil || current.Status.BundlePushTracker.NeedToPushManagerApps {
+		t.Errorf("Bundle push attempted again for the same bundle push request")
+	}
 }
 
 func TestPushManagerAppsBundle(t *testing.T) {
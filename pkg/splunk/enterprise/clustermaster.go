@@ -25,9 +25,11 @@ import (
 
 	"github.com/go-logr/logr"
 	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	"github.com/splunk/splunk-operator/pkg/splunk/audit"
 	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -39,6 +41,8 @@ import (
 
 // ApplyClusterMaster reconciles the state of a Splunk Enterprise cluster manager.
 func ApplyClusterMaster(ctx context.Context, client splcommon.ControllerClient, cr *enterpriseApiV3.ClusterMaster) (reconcile.Result, error) {
+	ctx, span := tracing.Start(ctx, "ApplyClusterMaster")
+	defer span.End()
 
 	// unless modified, reconcile for this object will be requeued after 5 seconds
 	result := reconcile.Result{
@@ -47,7 +51,15 @@ func ApplyClusterMaster(ctx context.Context, client splcommon.ControllerClient,
 	}
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("ApplyClusterMaster")
-	eventPublisher, _ := newK8EventPublisher(client, cr)
+	eventPublisher, _ := newK8EventPublisher(cr)
+	defer eventPublisher.publishPhaseChange(ctx, cr.Status.Phase, &cr.Status.Phase)
+	ctx = audit.NewContext(ctx, cr, &cr.Status.AuditTrail)
+
+	// the resources of an adopted cluster master are managed by its cluster manager
+	if isClusterMasterAdopted(cr) {
+		scopedLog.Info("Cluster master adopted by a cluster manager")
+		return applyAdoptedClusterMaster(ctx, client, cr)
+	}
 
 	if cr.Status.ResourceRevMap == nil {
 		cr.Status.ResourceRevMap = make(map[string]string)
@@ -67,7 +79,7 @@ func ApplyClusterMaster(ctx context.Context, client splcommon.ControllerClient,
 		AreRemoteVolumeKeysChanged(ctx, client, cr, SplunkClusterMaster, &cr.Spec.SmartStore, cr.Status.ResourceRevMap, &err) {
 
 		if err != nil {
-			eventPublisher.Warning(ctx, "AreRemoteVolumeKeysChanged", fmt.Sprintf("check remote volume key change failed %s", err.Error()))
+			eventPublisher.Warning(ctx, SmartStoreUpdateFailed, fmt.Sprintf("check remote volume key change failed %s", err.Error()))
 			return result, err
 		}
 
@@ -105,7 +117,7 @@ func ApplyClusterMaster(ctx context.Context, client splcommon.ControllerClient,
 	if len(cr.Spec.AppFrameworkConfig.AppSources) != 0 {
 		err := initAndCheckAppInfoStatus(ctx, client, cr, &cr.Spec.AppFrameworkConfig, &cr.Status.AppContext)
 		if err != nil {
-			eventPublisher.Warning(ctx, "initAndCheckAppInfoStatus", fmt.Sprintf("init and check app info status failed %s", err.Error()))
+			eventPublisher.Warning(ctx, AppRepoCheckFailed, fmt.Sprintf("init and check app info status failed %s", err.Error()))
 			cr.Status.AppContext.IsDeploymentInProgress = false
 			return result, err
 		}
@@ -115,7 +127,7 @@ func ApplyClusterMaster(ctx context.Context, client splcommon.ControllerClient,
 	namespaceScopedSecret, err := ApplySplunkConfig(ctx, client, cr, cr.Spec.CommonSplunkSpec, SplunkIndexer)
 	if err != nil {
 		scopedLog.Error(err, "create or update general config failed", "error", err.Error())
-		eventPublisher.Warning(ctx, "ApplySplunkConfig", fmt.Sprintf("create or update general config failed with error %s", err.Error()))
+		eventPublisher.Warning(ctx, ConfigApplyFailed, fmt.Sprintf("create or update general config failed with error %s", err.Error()))
 		return result, err
 	}
 
@@ -147,7 +159,7 @@ func ApplyClusterMaster(ctx context.Context, client splcommon.ControllerClient,
 			result.Requeue = false
 		}
 		if err != nil {
-			eventPublisher.Warning(ctx, "Delete", fmt.Sprintf("delete custom resource failed %s", err.Error()))
+			eventPublisher.Warning(ctx, DeleteFailed, fmt.Sprintf("delete custom resource failed %s", err.Error()))
 		}
 		return result, err
 	}
@@ -164,6 +176,18 @@ func ApplyClusterMaster(ctx context.Context, client splcommon.ControllerClient,
 		return result, err
 	}
 
+	// create or update the services of each type of traffic and their routes
+	err = ApplySplunkTrafficServices(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkClusterMaster, false)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the certificates of the ports and the secret mounting them
+	err = ApplySplunkTLS(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkClusterMaster)
+	if err != nil {
+		return result, err
+	}
+
 	// create or update statefulset for the cluster manager
 	statefulSet, err := getClusterMasterStatefulSet(ctx, client, cr)
 	if err != nil {
@@ -223,6 +247,11 @@ func ApplyClusterMaster(ctx context.Context, client splcommon.ControllerClient,
 
 		finalResult := handleAppFrameworkActivity(ctx, client, cr, &cr.Status.AppContext, &cr.Spec.AppFrameworkConfig)
 		result = *finalResult
+
+		// retry a bundle push pending a maintenance window
+		if cr.Status.Phase == enterpriseApi.PhasePendingMaintenanceWindow {
+			updateReconcileRequeueTime(ctx, &result, maintenanceWindowRetryDelay, true)
+		}
 	}
 	// RequeueAfter if greater than 0, tells the Controller to requeue the reconcile key after the Duration.
 	// Implies that Requeue is true, there is no need to set Requeue to true at the same time as RequeueAfter.
@@ -290,14 +319,14 @@ func getClusterMasterStatefulSet(ctx context.Context, client splcommon.Controlle
 func CheckIfMastersmartstoreConfigMapUpdatedToPod(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApiV3.ClusterMaster, podExecClient splutil.PodExecClientImpl) error {
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("CheckIfMastersmartstoreConfigMapUpdatedToPod").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
-	eventPublisher, _ := newK8EventPublisher(c, cr)
+	eventPublisher, _ := newK8EventPublisher(cr)
 
 	command := fmt.Sprintf("cat /mnt/splunk-operator/local/%s", configToken)
 	streamOptions := splutil.NewStreamOptionsObject(command)
 
 	stdOut, stdErr, err := podExecClient.RunPodExecCommand(ctx, streamOptions, []string{"/bin/sh"})
 	if err != nil || stdErr != "" {
-		eventPublisher.Warning(ctx, "PodExecCommand", fmt.Sprintf("Failed to check config token value on pod. stdout=%s, stderror=%s, error=%v", stdOut, stdErr, err))
+		eventPublisher.Warning(ctx, SmartStoreUpdateFailed, fmt.Sprintf("Failed to check config token value on pod. stdout=%s, stderror=%s, error=%v", stdOut, stdErr, err))
 		return fmt.Errorf("failed to check config token value on pod. stdout=%s, stderror=%s, error=%v", stdOut, stdErr, err)
 	}
 
@@ -308,28 +337,46 @@ func CheckIfMastersmartstoreConfigMapUpdatedToPod(ctx context.Context, c splcomm
 			scopedLog.Info("Token Matched.", "on Pod=", stdOut, "from configMap=", tokenFromConfigMap)
 			return nil
 		}
-		eventPublisher.Warning(ctx, "getSmartstoreConfigMap", fmt.Sprintf("waiting for the configMap update to the Pod. Token on Pod=%s, Token from configMap=%s", stdOut, tokenFromConfigMap))
+		eventPublisher.Warning(ctx, SmartStoreUpdateFailed, fmt.Sprintf("waiting for the configMap update to the Pod. Token on Pod=%s, Token from configMap=%s", stdOut, tokenFromConfigMap))
 		return fmt.Errorf("waiting for the configMap update to the Pod. Token on Pod=%s, Token from configMap=%s", stdOut, tokenFromConfigMap)
 	}
 
 	// Somehow the configmap was deleted, ideally this should not happen
-	eventPublisher.Warning(ctx, "getSmartstoreConfigMap", "smartstore ConfigMap is missing")
+	eventPublisher.Warning(ctx, SmartStoreUpdateFailed, "smartstore ConfigMap is missing")
 	return fmt.Errorf("smartstore ConfigMap is missing")
 }
 
 // PerformCmasterBundlePush initiates the bundle push from cluster manager
 func PerformCmasterBundlePush(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApiV3.ClusterMaster) error {
+	// a bundle push requested with the annotation goes through the same steps as the one of an apps update
+	request, requested := cr.GetAnnotations()[enterpriseApiV3.ClusterMasterBundlePushAnnotation]
+	if requested && request != cr.Status.BundlePushTracker.LastBundlePushRequest {
+		cr.Status.BundlePushTracker.NeedToPushMasterApps = true
+		cr.Status.BundlePushTracker.LastBundlePushRequest = request
+	}
+
 	if !cr.Status.BundlePushTracker.NeedToPushMasterApps {
 		return nil
 	}
 
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("PerformCmasterBundlePush").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	// the bundle push may restart the peers, so it waits for a maintenance window
+	allowed, err := isInMaintenanceWindow(ctx, c, cr, &cr.Spec.CommonSplunkSpec)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		cr.Status.Phase = enterpriseApi.PhasePendingMaintenanceWindow
+		return nil
+	}
+
+	eventPublisher, _ := newK8EventPublisher(cr)
+
 	// Reconciler can be called for multiple reasons. If we are waiting on configMap update to happen,
 	// do not increment the Retry Count unless the last check was 5 seconds ago.
 	// This helps, to wait for the required time
-	//eventPublisher, _ := newK8EventPublisher(c, cr)
-
 	currentEpoch := time.Now().Unix()
 	if cr.Status.BundlePushTracker.LastCheckInterval+5 > currentEpoch {
 		return fmt.Errorf("will re-attempt to push the bundle after the 5 seconds period passed from last check. LastCheckInterval=%d, current epoch=%d", cr.Status.BundlePushTracker.LastCheckInterval, currentEpoch)
@@ -346,7 +393,7 @@ func PerformCmasterBundlePush(ctx context.Context, c splcommon.ControllerClient,
 
 	cmPodName := fmt.Sprintf("splunk-%s-%s-0", cr.GetName(), splcommon.ClusterManager)
 	podExecClient := splutil.GetPodExecClient(c, cr, cmPodName)
-	err := CheckIfMastersmartstoreConfigMapUpdatedToPod(ctx, c, cr, podExecClient)
+	err = CheckIfMastersmartstoreConfigMapUpdatedToPod(ctx, c, cr, podExecClient)
 	if err != nil {
 		return err
 	}
@@ -358,32 +405,35 @@ func PerformCmasterBundlePush(ctx context.Context, c splcommon.ControllerClient,
 	}
 
 	err = PushMasterAppsBundle(ctx, c, cr)
-	if err == nil {
-		scopedLog.Info("Bundle push success")
-		cr.Status.BundlePushTracker.NeedToPushMasterApps = false
+	audit.Log(ctx, audit.BundlePushed, cmPodName, "SmartStore configuration changed or bundle push requested", err)
+	if err != nil {
+		eventPublisher.Warning(ctx, BundlePushFailed, fmt.Sprintf("Bundle push failed %s", err.Error()))
+		return err
 	}
 
-	//eventPublisher.Warning(ctx, "BundlePush", fmt.Sprintf("Bundle push failed %s", err.Error()))
-	return err
+	scopedLog.Info("Bundle push success")
+	cr.Status.BundlePushTracker.NeedToPushMasterApps = false
+	eventPublisher.Normal(ctx, BundlePushSucceeded, "pushed the cluster bundle to the peers")
+	return nil
 }
 
 // PushMasterAppsBundle issues the REST command to for cluster manager bundle push
 func PushMasterAppsBundle(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApiV3.ClusterMaster) error {
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("PushMasterApps").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
-	eventPublisher, _ := newK8EventPublisher(c, cr)
+	eventPublisher, _ := newK8EventPublisher(cr)
 
 	defaultSecretObjName := splcommon.GetNamespaceScopedSecretName(cr.GetNamespace())
 	defaultSecret, err := splutil.GetSecretByName(ctx, c, cr.GetNamespace(), cr.GetName(), defaultSecretObjName)
 	if err != nil {
-		eventPublisher.Warning(ctx, "PushMasterAppsBundle", fmt.Sprintf("Could not access default secret object to fetch admin password. Reason %v", err))
+		eventPublisher.Warning(ctx, BundlePushFailed, fmt.Sprintf("Could not access default secret object to fetch admin password. Reason %v", err))
 		return fmt.Errorf("could not access default secret object to fetch admin password. Reason %v", err)
 	}
 
 	//Get the admin password from the secret object
 	adminPwd, foundSecret := defaultSecret.Data["password"]
 	if !foundSecret {
-		eventPublisher.Warning(ctx, "PushMasterAppsBundle", "Could not find admin password while trying to push the manager apps bundle")
+		eventPublisher.Warning(ctx, BundlePushFailed, "Could not find admin password while trying to push the manager apps bundle")
 		return fmt.Errorf("could not find admin password while trying to push the manager apps bundle")
 	}
 
@@ -395,7 +445,7 @@ func PushMasterAppsBundle(ctx context.Context, c splcommon.ControllerClient, cr
 	// Get a Splunk client to execute the REST call
 	splunkClient := splclient.NewSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", string(adminPwd))
 
-	return splunkClient.BundlePush(true)
+	return splunkClient.BundlePush(ctx, true)
 }
 
 // helper function to get the list of ClusterMaster types in the current namespace
@@ -423,7 +473,7 @@ func VerifyCMasterisMultisite(ctx context.Context, cr *enterpriseApiV3.ClusterMa
 	scopedLog := reqLogger.WithName("Verify if Multisite Indexer Cluster").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
 	mgr := clusterMasterPodManager{log: scopedLog, cr: cr, secrets: namespaceScopedSecret, newSplunkClient: splclient.NewSplunkClient}
 	cm := mgr.getClusterMasterClient(cr)
-	clusterInfo, err := cm.GetClusterInfo(false)
+	clusterInfo, err := cm.GetClusterInfo(ctx, false)
 	if err != nil {
 		return nil, err
 	}
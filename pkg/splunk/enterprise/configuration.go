@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strconv"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -31,6 +32,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
 	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
@@ -167,25 +169,7 @@ func getSplunkService(ctx context.Context, cr splcommon.MetaObject, spec *enterp
 
 	service.ObjectMeta.Name = GetSplunkServiceName(instanceType, cr.GetName(), isHeadless)
 	service.ObjectMeta.Namespace = cr.GetNamespace()
-	instanceIdentifier := cr.GetName()
-	var partOfIdentifier string
-	if instanceType == SplunkIndexer {
-		if len(spec.ClusterManagerRef.Name) == 0 && len(spec.ClusterMasterRef.Name) == 0 {
-			// Do not specify the instance label in the selector of IndexerCluster services, so that the services of the main part
-			// of multisite / multipart IndexerCluster can be used to resolve (headless) or load balance traffic to the indexers of all parts
-			partOfIdentifier = instanceIdentifier
-			instanceIdentifier = ""
-		} else if len(spec.ClusterManagerRef.Name) > 0 {
-			// And for child parts of multisite / multipart IndexerCluster, use the name of the part containing the cluster-manager
-			// in the app.kubernetes.io/part-of label
-			partOfIdentifier = spec.ClusterManagerRef.Name
-		} else if len(spec.ClusterMasterRef.Name) > 0 {
-			// And for child parts of multisite / multipart IndexerCluster, use the name of the part containing the cluster-manager
-			// in the app.kubernetes.io/part-of label
-			partOfIdentifier = spec.ClusterMasterRef.Name
-		}
-	}
-	service.Spec.Selector = getSplunkLabels(instanceIdentifier, instanceType, partOfIdentifier)
+	service.Spec.Selector = getSplunkServiceSelector(cr, spec, instanceType)
 	service.Spec.Ports = append(service.Spec.Ports, splcommon.SortServicePorts(getSplunkServicePorts(instanceType))...) // note that port order is important for tests
 
 	// ensure labels and annotations are not nil
@@ -214,6 +198,29 @@ func getSplunkService(ctx context.Context, cr splcommon.MetaObject, spec *enterp
 	return service
 }
 
+// getSplunkServiceSelector returns the pod selector used by the services of a Splunk Enterprise instance type
+func getSplunkServiceSelector(cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec, instanceType InstanceType) map[string]string {
+	instanceIdentifier := cr.GetName()
+	var partOfIdentifier string
+	if instanceType == SplunkIndexer {
+		if len(spec.ClusterManagerRef.Name) == 0 && len(spec.ClusterMasterRef.Name) == 0 {
+			// Do not specify the instance label in the selector of IndexerCluster services, so that the services of the main part
+			// of multisite / multipart IndexerCluster can be used to resolve (headless) or load balance traffic to the indexers of all parts
+			partOfIdentifier = instanceIdentifier
+			instanceIdentifier = ""
+		} else if len(spec.ClusterManagerRef.Name) > 0 {
+			// And for child parts of multisite / multipart IndexerCluster, use the name of the part containing the cluster-manager
+			// in the app.kubernetes.io/part-of label
+			partOfIdentifier = spec.ClusterManagerRef.Name
+		} else if len(spec.ClusterMasterRef.Name) > 0 {
+			// And for child parts of multisite / multipart IndexerCluster, use the name of the part containing the cluster-manager
+			// in the app.kubernetes.io/part-of label
+			partOfIdentifier = spec.ClusterMasterRef.Name
+		}
+	}
+	return getSplunkLabels(instanceIdentifier, instanceType, partOfIdentifier)
+}
+
 // setVolumeDefaults set properties in Volumes to default values
 func setVolumeDefaults(spec *enterpriseApi.CommonSplunkSpec) {
 
@@ -378,9 +385,104 @@ func validateCommonSplunkSpec(ctx context.Context, c splcommon.ControllerClient,
 
 	setVolumeDefaults(spec)
 
+	err = validateVolumes(spec)
+	if err != nil {
+		return err
+	}
+
+	err = validateExtraContainers(spec)
+	if err != nil {
+		return err
+	}
+
+	err = splctrl.ValidateMaintenanceWindows(spec.MaintenanceWindows)
+	if err != nil {
+		return err
+	}
+
+	err = validateServicesSpec(&spec.Services)
+	if err != nil {
+		return err
+	}
+
+	err = validateTLSSpec(&spec.TLS)
+	if err != nil {
+		return err
+	}
+
 	return ValidateSpec(&spec.Spec, defaultResources)
 }
 
+// validateVolumes checks the custom volume mounts and the user defined persistent volume claim templates
+func validateVolumes(spec *enterpriseApi.CommonSplunkSpec) error {
+	// names and paths reserved for the etc and var volumes
+	volumeNames := map[string]bool{}
+	mountPaths := map[string]bool{}
+	for _, volumeType := range []string{splcommon.EtcVolumeStorage, splcommon.VarVolumeStorage} {
+		volumeNames[fmt.Sprintf(splcommon.PvcNamePrefix, volumeType)] = true
+		volumeNames[fmt.Sprintf(splcommon.SplunkMountNamePrefix, volumeType)] = true
+		mountPaths[fmt.Sprintf(splcommon.SplunkMountDirecPrefix, volumeType)] = true
+	}
+	for _, volume := range spec.Volumes {
+		volumeNames[volume.Name] = true
+	}
+
+	for _, volumeMount := range spec.VolumeMounts {
+		if !volumeNames[volumeMount.Name] {
+			return fmt.Errorf("volumeMount %s does not refer to a volume", volumeMount.Name)
+		}
+		if !filepath.IsAbs(volumeMount.MountPath) || mountPaths[filepath.Clean(volumeMount.MountPath)] {
+			return fmt.Errorf("invalid mountPath %s for volume %s", volumeMount.MountPath, volumeMount.Name)
+		}
+		mountPaths[filepath.Clean(volumeMount.MountPath)] = true
+	}
+
+	for i := range spec.VolumeClaimTemplates {
+		template := &spec.VolumeClaimTemplates[i]
+		if errs := validation.IsDNS1123Label(template.Name); len(errs) > 0 {
+			return fmt.Errorf("invalid volumeClaimTemplate name %s: %s", template.Name, strings.Join(errs, ", "))
+		}
+		if volumeNames[template.Name] {
+			return fmt.Errorf("volumeClaimTemplate name %s is already used by another volume", template.Name)
+		}
+		volumeNames[template.Name] = true
+
+		mountPath := getVolumeClaimTemplateMountPath(template)
+		if !filepath.IsAbs(mountPath) || mountPaths[filepath.Clean(mountPath)] {
+			return fmt.Errorf("invalid mountPath %s for volumeClaimTemplate %s", mountPath, template.Name)
+		}
+		mountPaths[filepath.Clean(mountPath)] = true
+
+		storageCapacity, err := resource.ParseQuantity(template.StorageCapacity)
+		if err != nil || storageCapacity.Sign() <= 0 {
+			return fmt.Errorf("invalid storageCapacity %s for volumeClaimTemplate %s", template.StorageCapacity, template.Name)
+		}
+	}
+	return nil
+}
+
+// validateExtraContainers checks that the user defined containers have unique names, which differ from the ones of the
+// containers added by the operator
+func validateExtraContainers(spec *enterpriseApi.CommonSplunkSpec) error {
+	containerNames := map[string]bool{splunkContainerName: true, "init": true}
+	for _, containers := range [][]corev1.Container{spec.ExtraInitContainers, spec.ExtraContainers} {
+		for i := range containers {
+			name := containers[i].Name
+			if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+				return fmt.Errorf("invalid container name %s: %s", name, strings.Join(errs, ", "))
+			}
+			if containerNames[name] {
+				return fmt.Errorf("container name %s is already used by another container", name)
+			}
+			containerNames[name] = true
+			if containers[i].Image == "" {
+				return fmt.Errorf("image of container %s is not set", name)
+			}
+		}
+	}
+	return nil
+}
+
 // ValidateImagePullSecrets sets default values for imagePullSecrets if not provided
 func ValidateImagePullSecrets(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec) error {
 	reqLogger := log.FromContext(ctx)
@@ -475,6 +577,9 @@ func addSplunkVolumeToTemplate(podTemplateSpec *corev1.PodTemplateSpec, name str
 
 	for idx := range podTemplateSpec.Spec.Containers {
 		containerSpec := &podTemplateSpec.Spec.Containers[idx]
+		if containerSpec.Name != splunkContainerName {
+			continue
+		}
 		containerSpec.VolumeMounts = append(containerSpec.VolumeMounts, corev1.VolumeMount{
 			Name:      name,
 			MountPath: mountPath,
@@ -502,6 +607,52 @@ func addPVCVolumes(cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec
 	return nil
 }
 
+// getVolumeClaimTemplateMountPath returns the path a user defined persistent volume claim is mounted at
+func getVolumeClaimTemplateMountPath(template *enterpriseApi.VolumeClaimTemplateSpec) string {
+	if template.MountPath != "" {
+		return template.MountPath
+	}
+	return "/mnt/" + template.Name
+}
+
+// addVolumeClaimTemplate adds a user defined persistent volume claim template to statefulSet
+func addVolumeClaimTemplate(cr splcommon.MetaObject, template *enterpriseApi.VolumeClaimTemplateSpec, statefulSet *appsv1.StatefulSet, labels map[string]string) error {
+	storageCapacity, err := resource.ParseQuantity(template.StorageCapacity)
+	if err != nil {
+		return fmt.Errorf("%s: %s", template.Name, err)
+	}
+
+	volumeClaim := corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      template.Name,
+			Namespace: cr.GetNamespace(),
+			Labels:    labels,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{"ReadWriteOnce"},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: storageCapacity,
+				},
+			},
+		},
+	}
+	if template.StorageClassName != "" {
+		storageClassName := template.StorageClassName
+		volumeClaim.Spec.StorageClassName = &storageClassName
+	}
+	statefulSet.Spec.VolumeClaimTemplates = append(statefulSet.Spec.VolumeClaimTemplates, volumeClaim)
+
+	// add volume mounts to splunk container for the PVCs
+	statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts = append(statefulSet.Spec.Template.Spec.Containers[0].VolumeMounts,
+		corev1.VolumeMount{
+			Name:      template.Name,
+			MountPath: getVolumeClaimTemplateMountPath(template),
+		})
+
+	return nil
+}
+
 // addEphemeralVolumes adds ephemeral volumes to statefulSet
 func addEphemeralVolumes(statefulSet *appsv1.StatefulSet, volumeType string) error {
 	// add ephemeral volumes to the splunk pod
@@ -553,6 +704,14 @@ func addStorageVolumes(ctx context.Context, cr splcommon.MetaObject, client splc
 		}
 	}
 
+	// add the user defined persistent volume claims
+	for i := range spec.VolumeClaimTemplates {
+		err := addVolumeClaimTemplate(cr, &spec.VolumeClaimTemplates[i], statefulSet, labels)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Add Splunk Probe config map
 	probeConfigMap, err := getProbeConfigMap(ctx, client, cr)
 	if err != nil {
@@ -680,7 +839,7 @@ func getSplunkStatefulSet(ctx context.Context, client splcommon.ControllerClient
 					{
 						Image:           spec.Image,
 						ImagePullPolicy: corev1.PullPolicy(spec.ImagePullPolicy),
-						Name:            "splunk",
+						Name:            splunkContainerName,
 						Ports:           ports,
 					},
 				},
@@ -694,6 +853,22 @@ func getSplunkStatefulSet(ctx context.Context, client splcommon.ControllerClient
 		return statefulSet, err
 	}
 
+	// apply the retention policy of the persistent volume claims
+	setPVCRetentionPolicy(statefulSet, &spec.PVCRetentionPolicy)
+
+	// set the maintenance windows for the disruptive updates of the pods
+	windows, err := getMaintenanceWindows(ctx, client, cr, spec)
+	if err != nil {
+		return statefulSet, err
+	}
+	setMaintenanceWindows(statefulSet, windows)
+
+	// set the priority of the disruptive updates of the pods within the disruption budget
+	setDisruptionPriority(statefulSet, cr)
+
+	// request the restart of some pods
+	setRestartPods(statefulSet, cr)
+
 	// add serviceaccount if configured
 	if spec.ServiceAccount != "" {
 		namespacedName := types.NamespacedName{Namespace: statefulSet.GetNamespace(), Name: spec.ServiceAccount}
@@ -704,9 +879,17 @@ func getSplunkStatefulSet(ctx context.Context, client splcommon.ControllerClient
 		}
 	}
 
+	// add the pod labels and annotations, these take precedence over the ones of the parent
+	addPodMeta(&statefulSet.Spec.Template, spec)
+
 	// append labels and annotations from parent
 	splcommon.AppendParentMeta(statefulSet.Spec.Template.GetObjectMeta(), cr.GetObjectMeta())
 
+	// the disruption priority only concerns the StatefulSet, changing it must not recycle the pods
+	if _, ok := spec.PodLabels[enterpriseApi.DisruptionPriorityLabel]; !ok {
+		delete(statefulSet.Spec.Template.GetLabels(), enterpriseApi.DisruptionPriorityLabel)
+	}
+
 	// retrieve the secret to upload to the statefulSet pod
 	statefulSetSecret, err := splutil.GetLatestVersionedSecret(ctx, client, cr, cr.GetNamespace(), statefulSet.GetName())
 	if err != nil || statefulSetSecret == nil {
@@ -716,12 +899,180 @@ func getSplunkStatefulSet(ctx context.Context, client splcommon.ControllerClient
 	// update statefulset's pod template with common splunk pod config
 	updateSplunkPodTemplateWithConfig(ctx, client, &statefulSet.Spec.Template, cr, spec, instanceType, extraEnv, statefulSetSecret.GetName())
 
+	// mount the certificates of the ports, see ApplySplunkTLS
+	addTLSConfigToTemplate(ctx, client, &statefulSet.Spec.Template, cr, spec, instanceType)
+
+	// add the health agent, which serves the probes of the splunk container
+	addHealthAgent(&statefulSet.Spec.Template, spec, instanceType)
+
+	// cluster members are ready once they joined their cluster, see updateClusterMemberReadyCondition
+	statefulSet.Spec.Template.Spec.ReadinessGates = getReadinessGates(instanceType)
+
+	// add the user defined containers last, so that they are not changed by the splunk pod config
+	addExtraContainers(&statefulSet.Spec.Template, spec)
+
 	// make Splunk Enterprise object the owner
 	statefulSet.SetOwnerReferences(append(statefulSet.GetOwnerReferences(), splcommon.AsOwner(cr, true)))
 
 	return statefulSet, nil
 }
 
+// addPodMeta adds the user defined labels and annotations to a pod template, without overriding the ones set by the operator
+func addPodMeta(podTemplateSpec *corev1.PodTemplateSpec, spec *enterpriseApi.CommonSplunkSpec) {
+	for k, v := range spec.PodLabels {
+		if _, ok := podTemplateSpec.Labels[k]; !ok {
+			podTemplateSpec.Labels[k] = v
+		}
+	}
+
+	if len(spec.PodAnnotations) > 0 && podTemplateSpec.Annotations == nil {
+		podTemplateSpec.Annotations = make(map[string]string)
+	}
+	for k, v := range spec.PodAnnotations {
+		if _, ok := podTemplateSpec.Annotations[k]; !ok {
+			podTemplateSpec.Annotations[k] = v
+		}
+	}
+}
+
+// addExtraContainers adds the user defined containers and init containers to a pod template
+func addExtraContainers(podTemplateSpec *corev1.PodTemplateSpec, spec *enterpriseApi.CommonSplunkSpec) {
+	for i := range spec.ExtraInitContainers {
+		container := spec.ExtraInitContainers[i].DeepCopy()
+		setContainerDefaults(container)
+		podTemplateSpec.Spec.InitContainers = append(podTemplateSpec.Spec.InitContainers, *container)
+	}
+	for i := range spec.ExtraContainers {
+		container := spec.ExtraContainers[i].DeepCopy()
+		setContainerDefaults(container)
+		podTemplateSpec.Spec.Containers = append(podTemplateSpec.Spec.Containers, *container)
+	}
+}
+
+// setContainerDefaults explicitly sets the default values of the compared container fields, so that a user defined
+// container can be compared for changes correctly with the one of the current statefulset
+func setContainerDefaults(container *corev1.Container) {
+	for i := range container.Ports {
+		if container.Ports[i].Protocol == "" {
+			container.Ports[i].Protocol = corev1.ProtocolTCP
+		}
+	}
+
+	for i := range container.Env {
+		fieldRef := container.Env[i].ValueFrom
+		if fieldRef != nil && fieldRef.FieldRef != nil && fieldRef.FieldRef.APIVersion == "" {
+			fieldRef.FieldRef.APIVersion = "v1"
+		}
+	}
+
+	// requests default to the limits
+	for name, limit := range container.Resources.Limits {
+		if _, ok := container.Resources.Requests[name]; !ok {
+			if container.Resources.Requests == nil {
+				container.Resources.Requests = corev1.ResourceList{}
+			}
+			container.Resources.Requests[name] = limit
+		}
+	}
+
+	for _, probe := range []*corev1.Probe{container.LivenessProbe, container.ReadinessProbe, container.StartupProbe} {
+		if probe == nil {
+			continue
+		}
+		if probe.TimeoutSeconds == 0 {
+			probe.TimeoutSeconds = 1
+		}
+		if probe.PeriodSeconds == 0 {
+			probe.PeriodSeconds = 10
+		}
+		if probe.SuccessThreshold == 0 {
+			probe.SuccessThreshold = 1
+		}
+		if probe.FailureThreshold == 0 {
+			probe.FailureThreshold = 3
+		}
+	}
+
+	if container.Lifecycle != nil {
+		for _, handler := range []*corev1.LifecycleHandler{container.Lifecycle.PostStart, container.Lifecycle.PreStop} {
+			if handler != nil && handler.HTTPGet != nil && handler.HTTPGet.Scheme == "" {
+				handler.HTTPGet.Scheme = corev1.URISchemeHTTP
+			}
+		}
+	}
+}
+
+// setDisruptionPriority copies the disruption priority label of a Splunk Enterprise resource to its StatefulSet
+func setDisruptionPriority(statefulSet *appsv1.StatefulSet, cr splcommon.MetaObject) {
+	annotations := statefulSet.GetAnnotations()
+	priority, ok := cr.GetLabels()[enterpriseApi.DisruptionPriorityLabel]
+	if !ok {
+		delete(annotations, enterpriseApi.DisruptionPriorityLabel)
+		statefulSet.SetAnnotations(annotations)
+		return
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[enterpriseApi.DisruptionPriorityLabel] = priority
+	statefulSet.SetAnnotations(annotations)
+}
+
+// setRestartPods copies the restart pods annotation of a Splunk Enterprise resource to its StatefulSet
+func setRestartPods(statefulSet *appsv1.StatefulSet, cr splcommon.MetaObject) {
+	annotations := statefulSet.GetAnnotations()
+	requests, ok := cr.GetAnnotations()[enterpriseApi.RestartPodsAnnotation]
+	if !ok {
+		delete(annotations, enterpriseApi.RestartPodsAnnotation)
+		statefulSet.SetAnnotations(annotations)
+		return
+	}
+
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[enterpriseApi.RestartPodsAnnotation] = requests
+	statefulSet.SetAnnotations(annotations)
+}
+
+// setPVCRetentionPolicy applies the PVC retention policy of a Splunk Enterprise resource to its StatefulSet. The policy
+// is mapped onto the StatefulSet persistentVolumeClaimRetentionPolicy, snapshots are taken by the operator
+func setPVCRetentionPolicy(statefulSet *appsv1.StatefulSet, policy *enterpriseApi.PVCRetentionPolicy) {
+	annotations := statefulSet.GetAnnotations()
+	for key, value := range map[string]string{
+		enterpriseApi.PVCRetentionWhenScaledAnnotation: string(policy.WhenScaled),
+		enterpriseApi.VolumeSnapshotClassAnnotation:    policy.VolumeSnapshotClassName,
+	} {
+		if value == "" {
+			delete(annotations, key)
+			continue
+		}
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[key] = value
+	}
+	statefulSet.SetAnnotations(annotations)
+
+	if policy.WhenScaled == "" && policy.WhenDeleted == "" {
+		statefulSet.Spec.PersistentVolumeClaimRetentionPolicy = nil
+		return
+	}
+
+	// the StatefulSet controller may only delete the claims, which is done after the pod was removed
+	getPolicyType := func(policyType enterpriseApi.PVCRetentionPolicyType) appsv1.PersistentVolumeClaimRetentionPolicyType {
+		if policyType == enterpriseApi.PVCRetentionPolicyDelete {
+			return appsv1.DeletePersistentVolumeClaimRetentionPolicyType
+		}
+		return appsv1.RetainPersistentVolumeClaimRetentionPolicyType
+	}
+	statefulSet.Spec.PersistentVolumeClaimRetentionPolicy = &appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: getPolicyType(policy.WhenDeleted),
+		WhenScaled:  getPolicyType(policy.WhenScaled),
+	}
+}
+
 // getSmartstoreConfigMap returns the smartstore configMap, if it exists and applicable for that instanceType
 func getSmartstoreConfigMap(ctx context.Context, client splcommon.ControllerClient, cr splcommon.MetaObject, instanceType InstanceType) *corev1.ConfigMap {
 	var configMap *corev1.ConfigMap
@@ -735,6 +1086,23 @@ func getSmartstoreConfigMap(ctx context.Context, client splcommon.ControllerClie
 	return configMap
 }
 
+// getVolumeMount returns the mount of a custom volume, which defaults to /mnt/<name>
+func getVolumeMount(spec *enterpriseApi.CommonSplunkSpec, name string) corev1.VolumeMount {
+	for _, volumeMount := range spec.VolumeMounts {
+		if volumeMount.Name == name {
+			return corev1.VolumeMount{
+				Name:      name,
+				MountPath: volumeMount.MountPath,
+				ReadOnly:  volumeMount.ReadOnly,
+			}
+		}
+	}
+	return corev1.VolumeMount{
+		Name:      name,
+		MountPath: "/mnt/" + name,
+	}
+}
+
 // updateSplunkPodTemplateWithConfig modifies the podTemplateSpec object based on configuration of the Splunk Enterprise resource.
 func updateSplunkPodTemplateWithConfig(ctx context.Context, client splcommon.ControllerClient, podTemplateSpec *corev1.PodTemplateSpec, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec, instanceType InstanceType, extraEnv []corev1.EnvVar, secretToMount string) {
 
@@ -743,6 +1111,9 @@ func updateSplunkPodTemplateWithConfig(ctx context.Context, client splcommon.Con
 	// Add custom ports to splunk containers
 	if spec.ServiceTemplate.Spec.Ports != nil {
 		for idx := range podTemplateSpec.Spec.Containers {
+			if podTemplateSpec.Spec.Containers[idx].Name != splunkContainerName {
+				continue
+			}
 			for _, p := range spec.ServiceTemplate.Spec.Ports {
 
 				podTemplateSpec.Spec.Containers[idx].Ports = append(podTemplateSpec.Spec.Containers[idx].Ports, corev1.ContainerPort{
@@ -758,11 +1129,12 @@ func updateSplunkPodTemplateWithConfig(ctx context.Context, client splcommon.Con
 	if spec.Volumes != nil {
 		podTemplateSpec.Spec.Volumes = append(podTemplateSpec.Spec.Volumes, spec.Volumes...)
 		for idx := range podTemplateSpec.Spec.Containers {
+			if podTemplateSpec.Spec.Containers[idx].Name != splunkContainerName {
+				continue
+			}
 			for v := range spec.Volumes {
-				podTemplateSpec.Spec.Containers[idx].VolumeMounts = append(podTemplateSpec.Spec.Containers[idx].VolumeMounts, corev1.VolumeMount{
-					Name:      spec.Volumes[v].Name,
-					MountPath: "/mnt/" + spec.Volumes[v].Name,
-				})
+				podTemplateSpec.Spec.Containers[idx].VolumeMounts = append(podTemplateSpec.Spec.Containers[idx].VolumeMounts,
+					getVolumeMount(spec, spec.Volumes[v].Name))
 			}
 		}
 	}
@@ -919,6 +1291,14 @@ func updateSplunkPodTemplateWithConfig(ctx context.Context, client splcommon.Con
 				scopedLog.Error(err, "Unable to get ClusterManager")
 			}
 
+			// a cluster manager which adopted a cluster master keeps serving under the name of the cluster master
+			if managerIdxCluster.Status.ClusterMasterAdopted {
+				clusterManagerURL = GetSplunkServiceName(SplunkClusterMaster, spec.ClusterManagerRef.Name, false)
+				if spec.ClusterManagerRef.Namespace != "" {
+					clusterManagerURL = splcommon.GetServiceFQDN(spec.ClusterManagerRef.Namespace, clusterManagerURL)
+				}
+			}
+
 			if managerIdxCluster.Spec.LicenseManagerRef.Name != "" {
 				licenseManagerURL := GetSplunkServiceName(SplunkLicenseManager, managerIdxCluster.Spec.LicenseManagerRef.Name, false)
 				if managerIdxCluster.Spec.LicenseManagerRef.Namespace != "" {
@@ -999,8 +1379,11 @@ func updateSplunkPodTemplateWithConfig(ctx context.Context, client splcommon.Con
 	// append any extra variables
 	env = append(env, extraEnv...)
 
-	// update each container in pod
+	// update the splunk container in pod, the user defined containers keep their own resources, probes and env
 	for idx := range podTemplateSpec.Spec.Containers {
+		if podTemplateSpec.Spec.Containers[idx].Name != splunkContainerName {
+			continue
+		}
 		podTemplateSpec.Spec.Containers[idx].Resources = spec.Resources
 		podTemplateSpec.Spec.Containers[idx].LivenessProbe = livenessProbe
 		podTemplateSpec.Spec.Containers[idx].ReadinessProbe = readinessProbe
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -32,6 +33,7 @@ import (
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -1374,6 +1376,14 @@ func TestAddStorageVolumes(t *testing.T) {
 		},
 	}
 	test(`{"kind":"StatefulSet","apiVersion":"apps/v1","metadata":{"name":"test-statefulset","namespace":"test","creationTimestamp":null},"spec":{"replicas":1,"selector":null,"template":{"metadata":{"creationTimestamp":null},"spec":{"volumes":[{"name":"mnt-splunk-etc","emptyDir":{}},{"name":"mnt-splunk-var","emptyDir":{}},{"name":"splunk-test-probe-configmap","configMap":{"name":"splunk-test-probe-configmap","defaultMode":365}}],"containers":[{"name":"splunk","image":"test","resources":{},"volumeMounts":[{"name":"mnt-splunk-etc","mountPath":"/opt/splunk/etc"},{"name":"mnt-splunk-var","mountPath":"/opt/splunk/var"},{"name":"splunk-test-probe-configmap","mountPath":"/mnt/probes"}]}]}},"serviceName":"","updateStrategy":{}},"status":{"replicas":0,"availableReplicas":0}}`)
+	// Define additional volume claim templates, mounted at the default and at a custom path
+	spec = &enterpriseApi.CommonSplunkSpec{
+		VolumeClaimTemplates: []enterpriseApi.VolumeClaimTemplateSpec{
+			{Name: "hot", StorageCapacity: "50Gi", StorageClassName: "fast"},
+			{Name: "cold", StorageCapacity: "500Gi", MountPath: "/opt/splunk/cold"},
+		},
+	}
+	test(`{"kind":"StatefulSet","apiVersion":"apps/v1","metadata":{"name":"test-statefulset","namespace":"test","creationTimestamp":null},"spec":{"replicas":1,"selector":null,"template":{"metadata":{"creationTimestamp":null},"spec":{"volumes":[{"name":"splunk-test-probe-configmap","configMap":{"name":"splunk-test-probe-configmap","defaultMode":365}}],"containers":[{"name":"splunk","image":"test","resources":{},"volumeMounts":[{"name":"pvc-etc","mountPath":"/opt/splunk/etc"},{"name":"pvc-var","mountPath":"/opt/splunk/var"},{"name":"hot","mountPath":"/mnt/hot"},{"name":"cold","mountPath":"/opt/splunk/cold"},{"name":"splunk-test-probe-configmap","mountPath":"/mnt/probes"}]}]}},"volumeClaimTemplates":[{"metadata":{"name":"pvc-etc","namespace":"test","creationTimestamp":null},"spec":{"accessModes":["ReadWriteOnce"],"resources":{"requests":{"storage":"10Gi"}}},"status":{}},{"metadata":{"name":"pvc-var","namespace":"test","creationTimestamp":null},"spec":{"accessModes":["ReadWriteOnce"],"resources":{"requests":{"storage":"100Gi"}}},"status":{}},{"metadata":{"name":"hot","namespace":"test","creationTimestamp":null},"spec":{"accessModes":["ReadWriteOnce"],"resources":{"requests":{"storage":"50Gi"}},"storageClassName":"fast"},"status":{}},{"metadata":{"name":"cold","namespace":"test","creationTimestamp":null},"spec":{"accessModes":["ReadWriteOnce"],"resources":{"requests":{"storage":"500Gi"}}},"status":{}}],"serviceName":"","updateStrategy":{}},"status":{"replicas":0,"availableReplicas":0}}`)
 	// Define invalid EtcVolumeStorageConfig
 	spec = &enterpriseApi.CommonSplunkSpec{
 		EtcVolumeStorageConfig: enterpriseApi.StorageClassSpec{
@@ -1661,6 +1671,77 @@ func TestValidateStartupProbe(t *testing.T) {
 	}
 }
 
+func TestValidateVolumes(t *testing.T) {
+	spec := &enterpriseApi.CommonSplunkSpec{
+		Volumes: []corev1.Volume{{Name: "test"}},
+		VolumeMounts: []enterpriseApi.VolumeMountSpec{
+			{Name: "test", MountPath: "/opt/test", ReadOnly: true},
+		},
+		VolumeClaimTemplates: []enterpriseApi.VolumeClaimTemplateSpec{
+			{Name: "hot", StorageCapacity: "50Gi"},
+			{Name: "cold", StorageCapacity: "500Gi", MountPath: "/opt/splunk/cold"},
+		},
+	}
+	err := validateVolumes(spec)
+	if err != nil {
+		t.Errorf("validateVolumes() returned error for valid volumes: %v", err)
+	}
+
+	test := func(description string, update func(spec *enterpriseApi.CommonSplunkSpec)) {
+		invalid := spec.DeepCopy()
+		update(invalid)
+		if validateVolumes(invalid) == nil {
+			t.Errorf("validateVolumes() did not return error for %s", description)
+		}
+	}
+	test("mount of unknown volume", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.VolumeMounts[0].Name = "unknown"
+	})
+	test("relative mount path", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.VolumeMounts[0].MountPath = "opt/test"
+	})
+	test("mount at the etc volume path", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.VolumeMounts[0].MountPath = "/opt/splunk/etc/"
+	})
+	test("invalid template name", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.VolumeClaimTemplates[0].Name = "Hot_Data"
+	})
+	test("template named after the var volume", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.VolumeClaimTemplates[0].Name = "pvc-var"
+	})
+	test("template named after a custom volume", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.VolumeClaimTemplates[0].Name = "test"
+	})
+	test("duplicate template names", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.VolumeClaimTemplates[1].Name = "hot"
+	})
+	test("duplicate mount paths", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.VolumeClaimTemplates[1].MountPath = "/opt/test"
+	})
+	test("invalid storage capacity", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.VolumeClaimTemplates[0].StorageCapacity = "----"
+	})
+	test("zero storage capacity", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.VolumeClaimTemplates[0].StorageCapacity = "0"
+	})
+}
+
+func TestGetVolumeMount(t *testing.T) {
+	spec := &enterpriseApi.CommonSplunkSpec{
+		VolumeMounts: []enterpriseApi.VolumeMountSpec{
+			{Name: "test", MountPath: "/opt/test", ReadOnly: true},
+		},
+	}
+	want := corev1.VolumeMount{Name: "test", MountPath: "/opt/test", ReadOnly: true}
+	if got := getVolumeMount(spec, "test"); got != want {
+		t.Errorf("getVolumeMount() = %v; want %v", got, want)
+	}
+	want = corev1.VolumeMount{Name: "other", MountPath: "/mnt/other"}
+	if got := getVolumeMount(spec, "other"); got != want {
+		t.Errorf("getVolumeMount() = %v; want %v", got, want)
+	}
+}
+
 func TestValidateReadinessProbe(t *testing.T) {
 	ctx := context.TODO()
 	cr := &enterpriseApi.ClusterManager{
@@ -1726,3 +1807,246 @@ func TestValidateLivenessProbe(t *testing.T) {
 		t.Errorf("Unexpected error when less than deault values passed for livenessProbe InitialDelaySeconds %d, TimeoutSeconds %d, PeriodSeconds %d. Error %s", livenessProbe.InitialDelaySeconds, livenessProbe.TimeoutSeconds, livenessProbe.PeriodSeconds, err)
 	}
 }
+
+func TestSetPVCRetentionPolicy(t *testing.T) {
+	statefulSet := &appsv1.StatefulSet{}
+	setPVCRetentionPolicy(statefulSet, &enterpriseApi.PVCRetentionPolicy{})
+	if statefulSet.GetAnnotations() != nil || statefulSet.Spec.PersistentVolumeClaimRetentionPolicy != nil {
+		t.Errorf("setPVCRetentionPolicy() changed the StatefulSet without a policy")
+	}
+
+	policy := &enterpriseApi.PVCRetentionPolicy{
+		WhenScaled:              enterpriseApi.PVCRetentionPolicySnapshot,
+		WhenDeleted:             enterpriseApi.PVCRetentionPolicyDelete,
+		VolumeSnapshotClassName: "csi-snapclass",
+	}
+	setPVCRetentionPolicy(statefulSet, policy)
+	want := appsv1.StatefulSetPersistentVolumeClaimRetentionPolicy{
+		WhenDeleted: appsv1.DeletePersistentVolumeClaimRetentionPolicyType,
+		WhenScaled:  appsv1.RetainPersistentVolumeClaimRetentionPolicyType,
+	}
+	if statefulSet.Spec.PersistentVolumeClaimRetentionPolicy == nil || *statefulSet.Spec.PersistentVolumeClaimRetentionPolicy != want {
+		t.Errorf("setPVCRetentionPolicy() policy = %v; want %v", statefulSet.Spec.PersistentVolumeClaimRetentionPolicy, want)
+	}
+	annotations := statefulSet.GetAnnotations()
+	if annotations[enterpriseApi.PVCRetentionWhenScaledAnnotation] != "Snapshot" || annotations[enterpriseApi.VolumeSnapshotClassAnnotation] != "csi-snapclass" {
+		t.Errorf("setPVCRetentionPolicy() annotations = %v", annotations)
+	}
+
+	policy.VolumeSnapshotClassName = ""
+	setPVCRetentionPolicy(statefulSet, policy)
+	if _, ok := statefulSet.GetAnnotations()[enterpriseApi.VolumeSnapshotClassAnnotation]; ok {
+		t.Errorf("setPVCRetentionPolicy() kept the volume snapshot class annotation")
+	}
+}
+
+func TestValidateExtraContainers(t *testing.T) {
+	spec := &enterpriseApi.CommonSplunkSpec{
+		ExtraInitContainers: []corev1.Container{{Name: "fetch-config", Image: "busybox"}},
+		ExtraContainers:     []corev1.Container{{Name: "vault-agent", Image: "vault"}},
+	}
+	err := validateExtraContainers(spec)
+	if err != nil {
+		t.Errorf("validateExtraContainers() returned error for valid containers: %v", err)
+	}
+
+	test := func(description string, update func(spec *enterpriseApi.CommonSplunkSpec)) {
+		invalid := spec.DeepCopy()
+		update(invalid)
+		if validateExtraContainers(invalid) == nil {
+			t.Errorf("validateExtraContainers() did not return error for %s", description)
+		}
+	}
+	test("container named after the splunk container", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.ExtraContainers[0].Name = "splunk"
+	})
+	test("init container named after the operator init container", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.ExtraInitContainers[0].Name = "init"
+	})
+	test("duplicate container names", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.ExtraContainers[0].Name = "fetch-config"
+	})
+	test("invalid container name", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.ExtraContainers[0].Name = "Vault_Agent"
+	})
+	test("missing image", func(spec *enterpriseApi.CommonSplunkSpec) {
+		spec.ExtraContainers[0].Image = ""
+	})
+}
+
+func TestSetContainerDefaults(t *testing.T) {
+	container := corev1.Container{
+		Name:  "shipper",
+		Image: "fluent-bit",
+		Ports: []corev1.ContainerPort{{ContainerPort: 2020}},
+		Env: []corev1.EnvVar{{
+			Name:      "POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}},
+		}},
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("128Mi")},
+		},
+		LivenessProbe: &corev1.Probe{InitialDelaySeconds: 5},
+	}
+	setContainerDefaults(&container)
+
+	if container.Ports[0].Protocol != corev1.ProtocolTCP {
+		t.Errorf("setContainerDefaults() port protocol = %s; want %s", container.Ports[0].Protocol, corev1.ProtocolTCP)
+	}
+	if container.Env[0].ValueFrom.FieldRef.APIVersion != "v1" {
+		t.Errorf("setContainerDefaults() field ref apiVersion = %s; want v1", container.Env[0].ValueFrom.FieldRef.APIVersion)
+	}
+	if request := container.Resources.Requests[corev1.ResourceMemory]; request.String() != "128Mi" {
+		t.Errorf("setContainerDefaults() memory request = %s; want 128Mi", request.String())
+	}
+	want := corev1.Probe{InitialDelaySeconds: 5, TimeoutSeconds: 1, PeriodSeconds: 10, SuccessThreshold: 1, FailureThreshold: 3}
+	if !reflect.DeepEqual(*container.LivenessProbe, want) {
+		t.Errorf("setContainerDefaults() liveness probe = %v; want %v", *container.LivenessProbe, want)
+	}
+}
+
+func TestGetSplunkStatefulSetExtraContainers(t *testing.T) {
+	ctx := context.TODO()
+	cr := enterpriseApi.Standalone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	cr.Spec.ExtraInitContainers = []corev1.Container{{Name: "fetch-config", Image: "busybox", Command: []string{"sh", "-c", "true"}}}
+	cr.Spec.ExtraContainers = []corev1.Container{{
+		Name:  "vault-agent",
+		Image: "vault",
+		Env:   []corev1.EnvVar{{Name: "VAULT_ADDR", Value: "https://vault:8200"}},
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("64Mi")},
+		},
+		LivenessProbe: &corev1.Probe{ProbeHandler: corev1.ProbeHandler{Exec: &corev1.ExecAction{Command: []string{"vault", "status"}}}},
+	}}
+	cr.Spec.Resources = corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")},
+	}
+	cr.Spec.ExtraEnv = []corev1.EnvVar{{Name: "SPLUNK_EXTRA", Value: "true"}}
+	cr.Spec.ServiceTemplate.Spec.Ports = []corev1.ServicePort{{Name: "user-defined", Port: 32000, TargetPort: intstr.FromInt(32000), Protocol: corev1.ProtocolTCP}}
+	cr.Spec.Volumes = []corev1.Volume{{Name: "extra-volume", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+	cr.Spec.PodLabels = map[string]string{"team": "observability", "app.kubernetes.io/name": "override"}
+	cr.Spec.PodAnnotations = map[string]string{"vault.hashicorp.com/role": "splunk"}
+
+	c := spltest.NewMockClient()
+	_, err := splutil.ApplyNamespaceScopedSecretObject(ctx, c, "test")
+	if err != nil {
+		t.Errorf("Failed to create namespace scoped object")
+	}
+	if err := validateStandaloneSpec(ctx, c, &cr); err != nil {
+		t.Errorf("validateStandaloneSpec() returned error: %v", err)
+	}
+	ss, err := getStandaloneStatefulSet(ctx, c, &cr)
+	if err != nil {
+		t.Errorf("getStandaloneStatefulSet() returned error: %v", err)
+	}
+
+	podSpec := ss.Spec.Template.Spec
+	if len(podSpec.Containers) != 2 || podSpec.Containers[0].Name != "splunk" || podSpec.Containers[1].Name != "vault-agent" {
+		t.Errorf("getStandaloneStatefulSet() containers = %v; want splunk and vault-agent", podSpec.Containers)
+	}
+	sidecar := podSpec.Containers[1]
+	if len(sidecar.Env) != 1 || len(sidecar.VolumeMounts) != 0 || len(sidecar.Ports) != 0 {
+		t.Errorf("getStandaloneStatefulSet() applied the splunk container config to the extra container: %v", sidecar)
+	}
+	if sidecar.Resources.Limits.Memory().String() != "64Mi" || sidecar.LivenessProbe == nil || sidecar.LivenessProbe.Exec == nil || sidecar.ReadinessProbe != nil {
+		t.Errorf("getStandaloneStatefulSet() did not keep the resources and probes of the extra container: %v", sidecar)
+	}
+	if podSpec.Containers[0].Resources.Limits.Memory().String() != "8Gi" {
+		t.Errorf("getStandaloneStatefulSet() splunk container resources = %v; want 8Gi", podSpec.Containers[0].Resources)
+	}
+	if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].Name != "fetch-config" {
+		t.Errorf("getStandaloneStatefulSet() init containers = %v; want fetch-config", podSpec.InitContainers)
+	}
+
+	labels := ss.Spec.Template.GetLabels()
+	if labels["team"] != "observability" || labels["app.kubernetes.io/name"] != "standalone" {
+		t.Errorf("getStandaloneStatefulSet() pod labels = %v", labels)
+	}
+	if ss.Spec.Template.GetAnnotations()["vault.hashicorp.com/role"] != "splunk" {
+		t.Errorf("getStandaloneStatefulSet() pod annotations = %v", ss.Spec.Template.GetAnnotations())
+	}
+
+	// the extra containers in the spec are not changed
+	if cr.Spec.ExtraContainers[0].Resources.Requests != nil {
+		t.Errorf("getStandaloneStatefulSet() changed the extra containers of the spec")
+	}
+
+	// an unchanged spec does not update the statefulset, while a changed sidecar field does
+	revised, err := getStandaloneStatefulSet(ctx, c, &cr)
+	if err != nil {
+		t.Errorf("getStandaloneStatefulSet() returned error: %v", err)
+	}
+	if splctrl.MergePodSpecUpdates(ctx, &ss.Spec.Template.Spec, &revised.Spec.Template.Spec, "test") {
+		t.Errorf("MergePodSpecUpdates() returned true for an unchanged spec")
+	}
+	runAsNonRoot := true
+	cr.Spec.ExtraContainers[0].SecurityContext = &corev1.SecurityContext{RunAsNonRoot: &runAsNonRoot}
+	revised, err = getStandaloneStatefulSet(ctx, c, &cr)
+	if err != nil {
+		t.Errorf("getStandaloneStatefulSet() returned error: %v", err)
+	}
+	if !splctrl.MergePodSpecUpdates(ctx, &ss.Spec.Template.Spec, &revised.Spec.Template.Spec, "test") {
+		t.Errorf("MergePodSpecUpdates() returned false for a changed sidecar security context")
+	}
+	if !reflect.DeepEqual(ss.Spec.Template.Spec.Containers[1].SecurityContext, cr.Spec.ExtraContainers[0].SecurityContext) {
+		t.Errorf("MergePodSpecUpdates() did not merge the sidecar security context")
+	}
+}
+
+func TestGetSplunkStatefulSetDisruptionPriority(t *testing.T) {
+	ctx := context.TODO()
+	cr := enterpriseApi.Standalone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+			Labels:    map[string]string{enterpriseApi.DisruptionPriorityLabel: "10"},
+		},
+	}
+
+	c := spltest.NewMockClient()
+	_, err := splutil.ApplyNamespaceScopedSecretObject(ctx, c, "test")
+	if err != nil {
+		t.Errorf("Failed to create namespace scoped object")
+	}
+	if err := validateStandaloneSpec(ctx, c, &cr); err != nil {
+		t.Errorf("validateStandaloneSpec() returned error: %v", err)
+	}
+	ss, err := getStandaloneStatefulSet(ctx, c, &cr)
+	if err != nil {
+		t.Errorf("getStandaloneStatefulSet() returned error: %v", err)
+	}
+
+	// the priority is set on the StatefulSet, but not on its pods
+	if got := ss.GetAnnotations()[enterpriseApi.DisruptionPriorityLabel]; got != "10" {
+		t.Errorf("getStandaloneStatefulSet() disruption priority = %q; want 10", got)
+	}
+	if _, ok := ss.Spec.Template.GetLabels()[enterpriseApi.DisruptionPriorityLabel]; ok {
+		t.Errorf("getStandaloneStatefulSet() set the disruption priority on the pods")
+	}
+
+	setDisruptionPriority(ss, &enterpriseApi.Standalone{})
+	if _, ok := ss.GetAnnotations()[enterpriseApi.DisruptionPriorityLabel]; ok {
+		t.Errorf("setDisruptionPriority() kept the disruption priority")
+	}
+}
+
+func TestSetRestartPods(t *testing.T) {
+	ss := &appsv1.StatefulSet{}
+	requests := `{"splunk-stack1-standalone-0":"2022-10-02T00:00:00Z"}`
+	setRestartPods(ss, &enterpriseApi.Standalone{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{enterpriseApi.RestartPodsAnnotation: requests}},
+	})
+	if got := ss.GetAnnotations()[enterpriseApi.RestartPodsAnnotation]; got != requests {
+		t.Errorf("setRestartPods() restart requests = %q; want %q", got, requests)
+	}
+
+	setRestartPods(ss, &enterpriseApi.Standalone{})
+	if _, ok := ss.GetAnnotations()[enterpriseApi.RestartPodsAnnotation]; ok {
+		t.Errorf("setRestartPods() kept the restart requests")
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"sync"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+)
+
+// podCredentialsKey identifies the admin password of a pod for a resource version of the namespace scoped secret
+type podCredentialsKey struct {
+	namespace             string
+	podName               string
+	secretResourceVersion string
+}
+
+// podCredentials caches the admin passwords read from the secrets mounted on the pods of the clusters. The secret
+// of a pod only changes with the namespace scoped secret it derives from, or when the operator updates it after
+// changing the admin password of the pod, which resets the passwords of the namespace
+var podCredentials = struct {
+	sync.Mutex
+	passwords map[podCredentialsKey]string
+}{passwords: make(map[podCredentialsKey]string)}
+
+// getPodAdminPassword returns the admin password of a pod, which is only read from the secret mounted on the pod
+// once per resource version of the namespace scoped secret. The password is always read when the resource version
+// is unknown
+func getPodAdminPassword(ctx context.Context, c splcommon.ControllerClient, namespace, podName, secretResourceVersion string) (string, error) {
+	key := podCredentialsKey{namespace: namespace, podName: podName, secretResourceVersion: secretResourceVersion}
+	if secretResourceVersion != "" {
+		podCredentials.Lock()
+		password, ok := podCredentials.passwords[key]
+		podCredentials.Unlock()
+		if ok {
+			return password, nil
+		}
+	}
+
+	password, err := splutil.GetSpecificSecretTokenFromPod(ctx, c, podName, namespace, "password")
+	if err != nil || secretResourceVersion == "" {
+		return password, err
+	}
+
+	podCredentials.Lock()
+	defer podCredentials.Unlock()
+	for k := range podCredentials.passwords {
+		if k.namespace == namespace && k.podName == podName {
+			delete(podCredentials.passwords, k)
+		}
+	}
+	podCredentials.passwords[key] = password
+	return password, nil
+}
+
+// resetPodCredentials removes the cached admin passwords of the pods of a namespace
+func resetPodCredentials(namespace string) {
+	podCredentials.Lock()
+	defer podCredentials.Unlock()
+	for k := range podCredentials.passwords {
+		if k.namespace == namespace {
+			delete(podCredentials.passwords, k)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetPodAdminPassword(t *testing.T) {
+	ctx := context.TODO()
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "splunk-creds-indexer-0", Namespace: "creds"},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name:         "mnt-splunk-secrets",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "splunk-creds-indexer-secret-v1"}},
+		}}},
+	}
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "splunk-creds-indexer-secret-v1", Namespace: "creds"},
+		Data:       map[string][]byte{"password": []byte("first")},
+	}
+	c := fake.NewClientBuilder().WithObjects(&pod, &secret).Build()
+	defer resetPodCredentials("creds")
+
+	check := func(secretResourceVersion, want string) {
+		t.Helper()
+		got, err := getPodAdminPassword(ctx, c, "creds", pod.GetName(), secretResourceVersion)
+		if err != nil || got != want {
+			t.Errorf("getPodAdminPassword(%q) = %s, %v; want %s", secretResourceVersion, got, err, want)
+		}
+	}
+	check("1", "first")
+
+	// the password is read once per resource version of the namespace scoped secret
+	secret.Data["password"] = []byte("second")
+	if err := c.Update(ctx, &secret); err != nil {
+		t.Fatalf("Update() returned error: %v", err)
+	}
+	check("1", "first")
+	check("", "second")
+	check("2", "second")
+
+	// the passwords are read again once the operator updates the secrets of the pods
+	secret.Data["password"] = []byte("third")
+	if err := c.Update(ctx, &secret); err != nil {
+		t.Fatalf("Update() returned error: %v", err)
+	}
+	resetPodCredentials("creds")
+	check("2", "third")
+
+	if _, err := getPodAdminPassword(ctx, c, "creds", "splunk-creds-indexer-1", "2"); err == nil {
+		t.Errorf("getPodAdminPassword() should have returned an error for a missing pod")
+	}
+}
@@ -0,0 +1,431 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/splunk/splunk-operator/pkg/splunk/audit"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+)
+
+const (
+	// identifier to track the serverclass.conf revision on the Pod
+	serverClassConfigRev = "serverClassConfigRev"
+
+	// volume name and mount location of the rendered serverclass.conf
+	serverClassVolumeName = "mnt-splunk-serverclass"
+	serverClassMountPath  = "/mnt/splunk-serverclass"
+
+	// name of the rendered serverclass file, also used as the ConfigMap key
+	serverClassConfFile = "serverclass.conf"
+
+	// command for the init container linking the rendered serverclass.conf into etc/system/local
+	commandForDeploymentServerClasses = "mkdir -p /opt/splk/etc/system/local && ln -sfn " + serverClassMountPath + "/" + serverClassConfFile + " /opt/splk/etc/system/local/" + serverClassConfFile
+
+	// command for the init container removing a serverclass.conf link left over from removed server classes
+	commandForDeploymentServerClassesCleanup = "if [ -L /opt/splk/etc/system/local/" + serverClassConfFile + " ]; then rm -f /opt/splk/etc/system/local/" + serverClassConfFile + "; fi"
+
+	// interval to refresh the number of deployment clients once the deployment server is ready
+	deploymentServerClientsPollInterval = time.Second * 60
+)
+
+// ApplyDeploymentServer reconciles the state for the Splunk Enterprise deployment server.
+func ApplyDeploymentServer(ctx context.Context, client splcommon.ControllerClient, cr *enterpriseApi.DeploymentServer) (reconcile.Result, error) {
+	ctx, span := tracing.Start(ctx, "ApplyDeploymentServer")
+	defer span.End()
+
+	// unless modified, reconcile for this object will be requeued after 5 seconds
+	result := reconcile.Result{
+		Requeue:      true,
+		RequeueAfter: time.Second * 5,
+	}
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("ApplyDeploymentServer")
+	eventPublisher, _ := newK8EventPublisher(cr)
+	defer eventPublisher.publishPhaseChange(ctx, cr.Status.Phase, &cr.Status.Phase)
+	ctx = audit.NewContext(ctx, cr, &cr.Status.AuditTrail)
+
+	// validate and updates defaults for CR
+	err := validateDeploymentServerSpec(ctx, client, cr)
+	if err != nil {
+		scopedLog.Error(err, "Failed to validate deployment server spec")
+		return result, err
+	}
+
+	// If needed, Migrate the app framework status
+	err = checkAndMigrateAppDeployStatus(ctx, client, cr, &cr.Status.AppContext, &cr.Spec.AppFrameworkConfig, false)
+	if err != nil {
+		return result, err
+	}
+
+	// If the app framework is configured then do following things -
+	// 1. Initialize the S3Clients based on providers
+	// 2. Check the status of apps on remote storage.
+	if len(cr.Spec.AppFrameworkConfig.AppSources) != 0 {
+		err := initAndCheckAppInfoStatus(ctx, client, cr, &cr.Spec.AppFrameworkConfig, &cr.Status.AppContext)
+		if err != nil {
+			eventPublisher.Warning(ctx, AppRepoCheckFailed, fmt.Sprintf("init and check app info status failed %s", err.Error()))
+			cr.Status.AppContext.IsDeploymentInProgress = false
+			return result, err
+		}
+	}
+
+	// updates status after function completes
+	cr.Status.Phase = enterpriseApi.PhaseError
+
+	// Update the CR Status
+	defer updateCRStatus(ctx, client, cr)
+
+	// create or update general config resources
+	namespaceScopedSecret, err := ApplySplunkConfig(ctx, client, cr, cr.Spec.CommonSplunkSpec, SplunkDeploymentServer)
+	if err != nil {
+		scopedLog.Error(err, "create or update general config failed", "error", err.Error())
+		eventPublisher.Warning(ctx, ConfigApplyFailed, fmt.Sprintf("create or update general config failed with error %s", err.Error()))
+		return result, err
+	}
+
+	// check if deletion has been requested
+	if cr.ObjectMeta.DeletionTimestamp != nil {
+		// If this is the last of its kind getting deleted,
+		// remove the entry for this CR type from configMap or else
+		// just decrement the refCount for this CR type.
+		if len(cr.Spec.AppFrameworkConfig.AppSources) != 0 {
+			err = UpdateOrRemoveEntryFromConfigMapLocked(ctx, client, cr, SplunkDeploymentServer)
+			if err != nil {
+				return result, err
+			}
+		}
+
+		DeleteOwnerReferencesForResources(ctx, client, cr, nil, SplunkDeploymentServer)
+		terminating, err := splctrl.CheckForDeletion(ctx, cr, client)
+
+		if terminating && err != nil { // don't bother if no error, since it will just be removed immmediately after
+			cr.Status.Phase = enterpriseApi.PhaseTerminating
+		} else {
+			result.Requeue = false
+		}
+		if err != nil {
+			eventPublisher.Warning(ctx, DeleteFailed, fmt.Sprintf("delete custom resource failed %s", err.Error()))
+		}
+		return result, err
+	}
+
+	// create or update a service, deployment clients phone home on the management port
+	err = splctrl.ApplyService(ctx, client, getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkDeploymentServer, false))
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the services of each type of traffic and their routes
+	err = ApplySplunkTrafficServices(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkDeploymentServer, false)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the certificates of the ports and the secret mounting them
+	err = ApplySplunkTLS(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkDeploymentServer)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the rendered serverclass.conf
+	err = ApplyServerClassConfigMap(ctx, client, cr)
+	if err != nil {
+		eventPublisher.Warning(ctx, ConfigApplyFailed, fmt.Sprintf("create or update serverclass config failed with error %s", err.Error()))
+		return result, err
+	}
+
+	// create or update statefulset
+	statefulSet, err := getDeploymentServerStatefulSet(ctx, client, cr)
+	if err != nil {
+		return result, err
+	}
+
+	mgr := splctrl.DefaultStatefulSetPodManager{}
+	phase, err := mgr.Update(ctx, client, statefulSet, 1)
+	if err != nil {
+		return result, err
+	}
+	cr.Status.Phase = phase
+
+	// no need to requeue if everything is ready
+	if cr.Status.Phase == enterpriseApi.PhaseReady {
+		// Add a splunk operator telemetry app
+		if cr.Spec.EtcVolumeStorageConfig.EphemeralStorage || !cr.Status.TelAppInstalled {
+			podExecClient := splutil.GetPodExecClient(client, cr, "")
+			err := addTelApp(ctx, podExecClient, 1, cr)
+			if err != nil {
+				return result, err
+			}
+
+			// Mark telemetry app as installed
+			cr.Status.TelAppInstalled = true
+		}
+
+		// the client count is informational, so a failure here doesn't fail the reconcile
+		clientCount, err := getDeploymentServerClientCount(ctx, cr, namespaceScopedSecret)
+		if err != nil {
+			scopedLog.Error(err, "Unable to get the deployment clients from the deployment server")
+		} else {
+			cr.Status.ClientCount = clientCount
+		}
+
+		finalResult := handleAppFrameworkActivity(ctx, client, cr, &cr.Status.AppContext, &cr.Spec.AppFrameworkConfig)
+		result = *finalResult
+
+		// keep the client count current while there is no app framework activity
+		if !result.Requeue {
+			result.Requeue = true
+			result.RequeueAfter = deploymentServerClientsPollInterval
+		}
+	}
+	// RequeueAfter if greater than 0, tells the Controller to requeue the reconcile key after the Duration.
+	// Implies that Requeue is true, there is no need to set Requeue to true at the same time as RequeueAfter.
+	if !result.Requeue {
+		result.RequeueAfter = 0
+	}
+	return result, nil
+}
+
+// getDeploymentServerStatefulSet returns a Kubernetes StatefulSet object for a Splunk Enterprise deployment server.
+func getDeploymentServerStatefulSet(ctx context.Context, client splcommon.ControllerClient, cr *enterpriseApi.DeploymentServer) (*appsv1.StatefulSet, error) {
+	ss, err := getSplunkStatefulSet(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkDeploymentServer, 1, []corev1.EnvVar{})
+	if err != nil {
+		return ss, err
+	}
+
+	// Setup App framework staging volume for apps
+	setupAppsStagingVolume(ctx, client, cr, &ss.Spec.Template, &cr.Spec.AppFrameworkConfig)
+
+	// Add the operator managed serverclass.conf
+	addServerClassConfigToTemplate(ctx, client, &ss.Spec.Template, cr)
+
+	return ss, err
+}
+
+// validateDeploymentServerSpec checks validity and makes default updates to a DeploymentServerSpec, and returns error if something is wrong.
+func validateDeploymentServerSpec(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApi.DeploymentServer) error {
+
+	if !reflect.DeepEqual(cr.Status.AppContext.AppFrameworkConfig, cr.Spec.AppFrameworkConfig) {
+		err := ValidateAppFrameworkSpec(ctx, &cr.Spec.AppFrameworkConfig, &cr.Status.AppContext, false, cr.GetObjectKind().GroupVersionKind().Kind)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := validateServerClasses(cr.Spec.ServerClasses)
+	if err != nil {
+		return err
+	}
+
+	return validateCommonSplunkSpec(ctx, c, &cr.Spec.CommonSplunkSpec, cr)
+}
+
+// validateServerClasses checks validity and makes default updates to the server classes of a deployment server
+func validateServerClasses(serverClasses []enterpriseApi.ServerClassSpec) error {
+	classNames := make(map[string]bool, len(serverClasses))
+	for i := range serverClasses {
+		serverClass := &serverClasses[i]
+		if serverClass.Name == "" {
+			return fmt.Errorf("server class name can not be empty")
+		}
+		if strings.ContainsAny(serverClass.Name, ":[]") {
+			return fmt.Errorf("invalid server class name %s", serverClass.Name)
+		}
+		if classNames[serverClass.Name] {
+			return fmt.Errorf("duplicate server class name %s", serverClass.Name)
+		}
+		classNames[serverClass.Name] = true
+
+		if len(serverClass.Whitelist) == 0 {
+			return fmt.Errorf("server class %s requires at least one whitelist entry", serverClass.Name)
+		}
+
+		appNames := make(map[string]bool, len(serverClass.Apps))
+		for j := range serverClass.Apps {
+			app := &serverClass.Apps[j]
+			if app.Name == "" {
+				return fmt.Errorf("app name can not be empty in server class %s", serverClass.Name)
+			}
+			if appNames[app.Name] {
+				return fmt.Errorf("duplicate app %s in server class %s", app.Name, serverClass.Name)
+			}
+			appNames[app.Name] = true
+
+			switch app.StateOnClient {
+			case "":
+				app.StateOnClient = enterpriseApi.AppStateOnClientEnabled
+			case enterpriseApi.AppStateOnClientEnabled, enterpriseApi.AppStateOnClientDisabled, enterpriseApi.AppStateOnClientNoop:
+			default:
+				return fmt.Errorf("invalid stateOnClient %s for app %s in server class %s", app.StateOnClient, app.Name, serverClass.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// getServerClassConf renders the server classes of a deployment server into serverclass.conf
+func getServerClassConf(serverClasses []enterpriseApi.ServerClassSpec) string {
+	var sb strings.Builder
+
+	sb.WriteString("# This file is managed by the Splunk Operator, changes made on the deployment server are not persisted\n")
+	sb.WriteString("[global]\n")
+
+	for _, serverClass := range serverClasses {
+		fmt.Fprintf(&sb, "\n[serverClass:%s]\n", serverClass.Name)
+		for i, entry := range serverClass.Whitelist {
+			fmt.Fprintf(&sb, "whitelist.%d = %s\n", i, entry)
+		}
+		for i, entry := range serverClass.Blacklist {
+			fmt.Fprintf(&sb, "blacklist.%d = %s\n", i, entry)
+		}
+		if len(serverClass.MachineTypesFilter) > 0 {
+			fmt.Fprintf(&sb, "machineTypesFilter = %s\n", strings.Join(serverClass.MachineTypesFilter, ","))
+		}
+
+		for _, app := range serverClass.Apps {
+			stateOnClient := app.StateOnClient
+			if stateOnClient == "" {
+				stateOnClient = enterpriseApi.AppStateOnClientEnabled
+			}
+			fmt.Fprintf(&sb, "\n[serverClass:%s:app:%s]\n", serverClass.Name, app.Name)
+			fmt.Fprintf(&sb, "restartSplunkd = %t\n", app.RestartSplunkd)
+			fmt.Fprintf(&sb, "stateOnClient = %s\n", stateOnClient)
+		}
+	}
+
+	return sb.String()
+}
+
+// getServerClassConfigMap returns the Kubernetes ConfigMap holding the rendered serverclass.conf of a deployment server
+func getServerClassConfigMap(cr *enterpriseApi.DeploymentServer) *corev1.ConfigMap {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetSplunkServerClassConfigMapName(cr.GetName()),
+			Namespace: cr.GetNamespace(),
+		},
+		Data: map[string]string{
+			serverClassConfFile: getServerClassConf(cr.Spec.ServerClasses),
+		},
+	}
+	configMap.SetOwnerReferences(append(configMap.GetOwnerReferences(), splcommon.AsOwner(cr, true)))
+
+	return configMap
+}
+
+// ApplyServerClassConfigMap creates or updates the rendered serverclass.conf of a deployment server, or removes it when there are no server classes
+func ApplyServerClassConfigMap(ctx context.Context, client splcommon.ControllerClient, cr *enterpriseApi.DeploymentServer) error {
+	ctx, span := tracing.Start(ctx, "ApplyServerClassConfigMap")
+	defer span.End()
+
+	if len(cr.Spec.ServerClasses) > 0 {
+		_, err := splctrl.ApplyConfigMap(ctx, client, getServerClassConfigMap(cr))
+		return err
+	}
+
+	namespacedName := types.NamespacedName{Namespace: cr.GetNamespace(), Name: GetSplunkServerClassConfigMapName(cr.GetName())}
+	configMap, err := splctrl.GetConfigMap(ctx, client, namespacedName)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("ApplyServerClassConfigMap").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+	scopedLog.Info("Deleting serverclass config of removed server classes", "configMap", configMap.GetName())
+	return splutil.DeleteResource(ctx, client, configMap)
+}
+
+// addServerClassConfigToTemplate mounts the rendered serverclass.conf into the deployment server and links it into etc/system/local
+func addServerClassConfigToTemplate(ctx context.Context, client splcommon.ControllerClient, podTemplateSpec *corev1.PodTemplateSpec, cr *enterpriseApi.DeploymentServer) {
+	isEtcVolEph := cr.Spec.CommonSplunkSpec.EtcVolumeStorageConfig.EphemeralStorage
+
+	// drop the link to a serverclass.conf which is no longer managed, files created on the pod are left alone
+	if len(cr.Spec.ServerClasses) == 0 {
+		setupInitContainer(podTemplateSpec, cr.Spec.Image, cr.Spec.ImagePullPolicy, commandForDeploymentServerClassesCleanup, isEtcVolEph)
+		return
+	}
+
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("addServerClassConfigToTemplate").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	configMapName := GetSplunkServerClassConfigMapName(cr.GetName())
+	configMapVolDefaultMode := int32(corev1.ConfigMapVolumeSourceDefaultMode)
+	addSplunkVolumeToTemplate(podTemplateSpec, serverClassVolumeName, serverClassMountPath, corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: configMapName,
+			},
+			DefaultMode: &configMapVolDefaultMode,
+		},
+	})
+	setupInitContainer(podTemplateSpec, cr.Spec.Image, cr.Spec.ImagePullPolicy, commandForDeploymentServerClasses, isEtcVolEph)
+
+	// We will update the annotation for resource version in the pod template spec
+	// so that any change in the server classes will lead to recycle of the pod.
+	namespacedName := types.NamespacedName{Namespace: cr.GetNamespace(), Name: configMapName}
+	configMapResourceVersion, err := splctrl.GetConfigMapResourceVersion(ctx, client, namespacedName)
+	if err != nil {
+		scopedLog.Error(err, "Updation of serverclass config annotation failed")
+		return
+	}
+	if podTemplateSpec.ObjectMeta.Annotations == nil {
+		podTemplateSpec.ObjectMeta.Annotations = make(map[string]string)
+	}
+	podTemplateSpec.ObjectMeta.Annotations[serverClassConfigRev] = configMapResourceVersion
+}
+
+// newDeploymentServerClient returns a Splunk client for the deployment server service
+var newDeploymentServerClient = func(cr *enterpriseApi.DeploymentServer, secret *corev1.Secret) *splclient.SplunkClient {
+	fqdnName := splcommon.GetServiceFQDN(cr.GetNamespace(), GetSplunkServiceName(SplunkDeploymentServer, cr.GetName(), false))
+	return splclient.NewSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", string(secret.Data["password"]))
+}
+
+// getDeploymentServerClientCount returns the number of deployment clients that phoned home to the deployment server
+func getDeploymentServerClientCount(ctx context.Context, cr *enterpriseApi.DeploymentServer, secret *corev1.Secret) (int32, error) {
+	if secret == nil {
+		return 0, fmt.Errorf("namespace scoped secret not found")
+	}
+
+	clients, err := newDeploymentServerClient(cr, secret).GetDeploymentServerClients(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(len(clients)), nil
+}
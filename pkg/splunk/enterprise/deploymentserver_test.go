@@ -0,0 +1,361 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+)
+
+func TestApplyDeploymentServer(t *testing.T) {
+	funcCalls := []spltest.MockFuncCall{
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.Service-test-splunk-stack1-deployment-server-service"},
+		{MetaName: "*v1.ConfigMap-test-splunk-stack1-deployment-server-serverclass"},
+		{MetaName: "*v1.StatefulSet-test-splunk-stack1-deployment-server"},
+		{MetaName: "*v1.ConfigMap-test-splunk-test-probe-configmap"},
+		{MetaName: "*v1.ConfigMap-test-splunk-test-probe-configmap"},
+		{MetaName: "*v1.Secret-test-splunk-test-secret"},
+		{MetaName: "*v1.Secret-test-splunk-stack1-deployment-server-secret-v1"},
+		{MetaName: "*v1.StatefulSet-test-splunk-stack1-deployment-server"},
+		{MetaName: "*v1.StatefulSet-test-splunk-stack1-deployment-server"},
+		{MetaName: "*v4.DeploymentServer-test-stack1"},
+		{MetaName: "*v4.DeploymentServer-test-stack1"},
+	}
+
+	labels := map[string]string{
+		"app.kubernetes.io/component":  "versionedSecrets",
+		"app.kubernetes.io/managed-by": "splunk-operator",
+	}
+	listOpts := []client.ListOption{
+		client.InNamespace("test"),
+		client.MatchingLabels(labels),
+	}
+	listmockCall := []spltest.MockFuncCall{
+		{ListOpts: listOpts}}
+
+	createFuncCalls := []spltest.MockFuncCall{funcCalls[0], funcCalls[1], funcCalls[2], funcCalls[3], funcCalls[4], funcCalls[5], funcCalls[6], funcCalls[7], funcCalls[8], funcCalls[9], funcCalls[10], funcCalls[12], funcCalls[13]}
+	createCalls := map[string][]spltest.MockFuncCall{"Get": createFuncCalls, "Create": {funcCalls[0], funcCalls[3], funcCalls[7], funcCalls[9], funcCalls[11]}, "Update": {funcCalls[0]}, "List": {listmockCall[0]}}
+	updateFuncCalls := []spltest.MockFuncCall{funcCalls[0], funcCalls[1], funcCalls[3], funcCalls[4], funcCalls[5], funcCalls[6], funcCalls[8], funcCalls[9], funcCalls[10], funcCalls[11], funcCalls[12], funcCalls[13]}
+	updateCalls := map[string][]spltest.MockFuncCall{"Get": updateFuncCalls, "Update": {funcCalls[5]}, "List": {listmockCall[0]}}
+	current := enterpriseApi.DeploymentServer{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "DeploymentServer",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+
+	revised := current.DeepCopy()
+	revised.Spec.Image = "splunk/test"
+	reconcile := func(c *spltest.MockClient, cr interface{}) error {
+		_, err := ApplyDeploymentServer(context.Background(), c, cr.(*enterpriseApi.DeploymentServer))
+		return err
+	}
+	spltest.ReconcileTesterWithoutRedundantCheck(t, "TestApplyDeploymentServer", &current, revised, createCalls, updateCalls, reconcile, true)
+
+	// test deletion
+	currentTime := metav1.NewTime(time.Now())
+	revised.ObjectMeta.DeletionTimestamp = &currentTime
+	revised.ObjectMeta.Finalizers = []string{"enterprise.splunk.com/delete-pvc"}
+	deleteFunc := func(cr splcommon.MetaObject, c splcommon.ControllerClient) (bool, error) {
+		_, err := ApplyDeploymentServer(context.Background(), c, cr.(*enterpriseApi.DeploymentServer))
+		return true, err
+	}
+	splunkDeletionTester(t, revised, deleteFunc)
+}
+
+func TestValidateServerClasses(t *testing.T) {
+	serverClasses := []enterpriseApi.ServerClassSpec{
+		{
+			Name:      "linux",
+			Whitelist: []string{"forwarder-*"},
+			Apps: []enterpriseApi.ServerClassAppSpec{
+				{Name: "Splunk_TA_nix"},
+				{Name: "outputs", StateOnClient: enterpriseApi.AppStateOnClientDisabled},
+			},
+		},
+	}
+	err := validateServerClasses(serverClasses)
+	if err != nil {
+		t.Errorf("validateServerClasses() returned error %v", err)
+	}
+	if serverClasses[0].Apps[0].StateOnClient != enterpriseApi.AppStateOnClientEnabled {
+		t.Errorf("validateServerClasses() should default stateOnClient to enabled, got %s", serverClasses[0].Apps[0].StateOnClient)
+	}
+	if serverClasses[0].Apps[1].StateOnClient != enterpriseApi.AppStateOnClientDisabled {
+		t.Errorf("validateServerClasses() should not override stateOnClient, got %s", serverClasses[0].Apps[1].StateOnClient)
+	}
+
+	invalidServerClasses := map[string][]enterpriseApi.ServerClassSpec{
+		"empty name":     {{Whitelist: []string{"*"}}},
+		"invalid name":   {{Name: "linux:all", Whitelist: []string{"*"}}},
+		"duplicate name": {{Name: "linux", Whitelist: []string{"*"}}, {Name: "linux", Whitelist: []string{"*"}}},
+		"no whitelist":   {{Name: "linux"}},
+		"empty app name": {{Name: "linux", Whitelist: []string{"*"}, Apps: []enterpriseApi.ServerClassAppSpec{{}}}},
+		"duplicate app":  {{Name: "linux", Whitelist: []string{"*"}, Apps: []enterpriseApi.ServerClassAppSpec{{Name: "app1"}, {Name: "app1"}}}},
+		"invalid state":  {{Name: "linux", Whitelist: []string{"*"}, Apps: []enterpriseApi.ServerClassAppSpec{{Name: "app1", StateOnClient: "invalid"}}}},
+	}
+	for test, serverClasses := range invalidServerClasses {
+		err = validateServerClasses(serverClasses)
+		if err == nil {
+			t.Errorf("validateServerClasses() should have returned error for %s", test)
+		}
+	}
+}
+
+func TestGetServerClassConf(t *testing.T) {
+	serverClasses := []enterpriseApi.ServerClassSpec{
+		{
+			Name:               "linux",
+			Whitelist:          []string{"forwarder-*", "10.1.1.*"},
+			Blacklist:          []string{"forwarder-test"},
+			MachineTypesFilter: []string{"linux-x86_64", "linux-aarch64"},
+			Apps: []enterpriseApi.ServerClassAppSpec{
+				{Name: "Splunk_TA_nix", RestartSplunkd: true},
+				{Name: "outputs", StateOnClient: enterpriseApi.AppStateOnClientNoop},
+			},
+		},
+	}
+
+	want := `# This file is managed by the Splunk Operator, changes made on the deployment server are not persisted
+[global]
+
+[serverClass:linux]
+whitelist.0 = forwarder-*
+whitelist.1 = 10.1.1.*
+blacklist.0 = forwarder-test
+machineTypesFilter = linux-x86_64,linux-aarch64
+
+[serverClass:linux:app:Splunk_TA_nix]
+restartSplunkd = true
+stateOnClient = enabled
+
+[serverClass:linux:app:outputs]
+restartSplunkd = false
+stateOnClient = noop
+`
+	got := getServerClassConf(serverClasses)
+	if got != want {
+		t.Errorf("getServerClassConf() got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestApplyServerClassConfigMap(t *testing.T) {
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	cr := enterpriseApi.DeploymentServer{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "DeploymentServer",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+		Spec: enterpriseApi.DeploymentServerSpec{
+			ServerClasses: []enterpriseApi.ServerClassSpec{
+				{Name: "linux", Whitelist: []string{"*"}},
+			},
+		},
+	}
+
+	err := ApplyServerClassConfigMap(ctx, c, &cr)
+	if err != nil {
+		t.Errorf("ApplyServerClassConfigMap() returned error %v", err)
+	}
+
+	namespacedName := types.NamespacedName{Namespace: "test", Name: GetSplunkServerClassConfigMapName("stack1")}
+	configMap, err := splctrl.GetConfigMap(ctx, c, namespacedName)
+	if err != nil {
+		t.Fatalf("serverclass config map should have been created, err %v", err)
+	}
+	if !strings.Contains(configMap.Data[serverClassConfFile], "[serverClass:linux]") {
+		t.Errorf("serverclass config map has unexpected contents %s", configMap.Data[serverClassConfFile])
+	}
+
+	// removing the server classes should remove the config map
+	cr.Spec.ServerClasses = nil
+	err = ApplyServerClassConfigMap(ctx, c, &cr)
+	if err != nil {
+		t.Errorf("ApplyServerClassConfigMap() returned error %v", err)
+	}
+	_, err = splctrl.GetConfigMap(ctx, c, namespacedName)
+	if err == nil {
+		t.Errorf("serverclass config map should have been deleted")
+	}
+
+	// nothing to delete
+	err = ApplyServerClassConfigMap(ctx, c, &cr)
+	if err != nil {
+		t.Errorf("ApplyServerClassConfigMap() should not return error when there is no config map, err %v", err)
+	}
+}
+
+func TestAddServerClassConfigToTemplate(t *testing.T) {
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	cr := enterpriseApi.DeploymentServer{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "DeploymentServer",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+
+	// no server classes, only the cleanup init container is added
+	podTemplateSpec := corev1.PodTemplateSpec{}
+	addServerClassConfigToTemplate(ctx, c, &podTemplateSpec, &cr)
+	if len(podTemplateSpec.Spec.Volumes) != 0 {
+		t.Errorf("addServerClassConfigToTemplate() should not add a volume without server classes")
+	}
+	if len(podTemplateSpec.Spec.InitContainers) != 1 || podTemplateSpec.Spec.InitContainers[0].Command[2] != commandForDeploymentServerClassesCleanup {
+		t.Errorf("addServerClassConfigToTemplate() should add the cleanup init container, got %v", podTemplateSpec.Spec.InitContainers)
+	}
+
+	cr.Spec.ServerClasses = []enterpriseApi.ServerClassSpec{
+		{Name: "linux", Whitelist: []string{"*"}},
+	}
+	err := ApplyServerClassConfigMap(ctx, c, &cr)
+	if err != nil {
+		t.Errorf("ApplyServerClassConfigMap() returned error %v", err)
+	}
+
+	podTemplateSpec = corev1.PodTemplateSpec{}
+	addServerClassConfigToTemplate(ctx, c, &podTemplateSpec, &cr)
+	if len(podTemplateSpec.Spec.Volumes) != 1 || podTemplateSpec.Spec.Volumes[0].ConfigMap.Name != GetSplunkServerClassConfigMapName("stack1") {
+		t.Errorf("addServerClassConfigToTemplate() should add the serverclass config map volume, got %v", podTemplateSpec.Spec.Volumes)
+	}
+	if len(podTemplateSpec.Spec.InitContainers) != 1 || podTemplateSpec.Spec.InitContainers[0].Command[2] != commandForDeploymentServerClasses {
+		t.Errorf("addServerClassConfigToTemplate() should add the serverclass init container, got %v", podTemplateSpec.Spec.InitContainers)
+	}
+	if _, ok := podTemplateSpec.ObjectMeta.Annotations[serverClassConfigRev]; !ok {
+		t.Errorf("addServerClassConfigToTemplate() should set the %s annotation", serverClassConfigRev)
+	}
+}
+
+func TestGetDeploymentServerClientCount(t *testing.T) {
+	ctx := context.TODO()
+	cr := enterpriseApi.DeploymentServer{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "DeploymentServer",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	secret := &corev1.Secret{
+		Data: map[string][]byte{
+			"password": []byte("123"),
+		},
+	}
+
+	_, err := getDeploymentServerClientCount(ctx, &cr, nil)
+	if err == nil {
+		t.Errorf("getDeploymentServerClientCount() should have returned error without a secret")
+	}
+
+	mockSplunkClient := &spltest.MockHTTPClient{}
+	mockSplunkClient.AddHandlers(spltest.MockHTTPHandler{
+		Method: "GET",
+		URL:    "https://splunk-stack1-deployment-server-service.test.svc.cluster.local:8089/services/deployment/server/clients?count=0&output_mode=json",
+		Status: 200,
+		Err:    nil,
+		Body:   `{"entry":[{"name":"client1","content":{"guid":"client1","hostname":"forwarder-0"}},{"name":"client2","content":{"guid":"client2","hostname":"forwarder-1"}}]}`,
+	})
+	savedNewDeploymentServerClient := newDeploymentServerClient
+	defer func() { newDeploymentServerClient = savedNewDeploymentServerClient }()
+	newDeploymentServerClient = func(cr *enterpriseApi.DeploymentServer, secret *corev1.Secret) *splclient.SplunkClient {
+		c := savedNewDeploymentServerClient(cr, secret)
+		c.Client = mockSplunkClient
+		return c
+	}
+
+	count, err := getDeploymentServerClientCount(ctx, &cr, secret)
+	if err != nil || count != 2 {
+		t.Errorf("getDeploymentServerClientCount() got count=%d, err=%v; want 2", count, err)
+	}
+	mockSplunkClient.CheckRequests(t, "TestGetDeploymentServerClientCount")
+
+	mockSplunkClient.Handlers = nil
+	_, err = getDeploymentServerClientCount(ctx, &cr, secret)
+	if err == nil {
+		t.Errorf("getDeploymentServerClientCount() should have returned error when the deployment server is not reachable")
+	}
+}
+
+func TestGetDeploymentServerStatefulSet(t *testing.T) {
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	cr := enterpriseApi.DeploymentServer{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "DeploymentServer",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+
+	_, err := splutil.ApplyNamespaceScopedSecretObject(ctx, c, "test")
+	if err != nil {
+		t.Errorf("Failed to create namespace scoped object")
+	}
+
+	ss, err := getDeploymentServerStatefulSet(ctx, c, &cr)
+	if err != nil {
+		t.Fatalf("getDeploymentServerStatefulSet() returned error %v", err)
+	}
+	if ss.GetName() != fmt.Sprintf("splunk-%s-deployment-server", cr.GetName()) {
+		t.Errorf("getDeploymentServerStatefulSet() returned unexpected name %s", ss.GetName())
+	}
+	if *ss.Spec.Replicas != 1 {
+		t.Errorf("getDeploymentServerStatefulSet() should have 1 replica, got %d", *ss.Spec.Replicas)
+	}
+	for _, env := range ss.Spec.Template.Spec.Containers[0].Env {
+		if env.Name == "SPLUNK_ROLE" && env.Value != "splunk_deployment_server" {
+			t.Errorf("getDeploymentServerStatefulSet() has unexpected SPLUNK_ROLE %s", env.Value)
+		}
+	}
+
+	cr.Spec.ServerClasses = []enterpriseApi.ServerClassSpec{{Name: "linux"}}
+	err = validateDeploymentServerSpec(ctx, c, &cr)
+	if err == nil {
+		t.Errorf("validateDeploymentServerSpec() should have returned error for a server class without whitelist")
+	}
+}
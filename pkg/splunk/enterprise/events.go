@@ -17,111 +17,264 @@ package enterprise
 
 import (
 	"context"
-	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	"fmt"
+	"sync"
+	"time"
 
-	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	"golang.org/x/time/rate"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// EventReason is the reason of the events published on the custom resources
+type EventReason string
+
+// Reasons of the Warning events
+const (
+	// ValidationFailed is published when the spec of a custom resource, or of the resources it references, is invalid
+	ValidationFailed EventReason = "ValidationFailed"
+
+	// ConfigApplyFailed is published when the secrets, ConfigMaps or HEC configuration of a custom resource cannot be applied
+	ConfigApplyFailed EventReason = "ConfigApplyFailed"
+
+	// ServiceApplyFailed is published when the services of a custom resource cannot be applied
+	ServiceApplyFailed EventReason = "ServiceApplyFailed"
+
+	// StatefulSetUpdateFailed is published when the StatefulSet of a custom resource cannot be created or updated
+	StatefulSetUpdateFailed EventReason = "StatefulSetUpdateFailed"
+
+	// DeleteFailed is published when the resources of a deleted custom resource cannot be cleaned up
+	DeleteFailed EventReason = "DeleteFailed"
+
+	// AppRepoCheckFailed is published when the App Framework cannot check the remote storage of the apps
+	AppRepoCheckFailed EventReason = "AppRepoCheckFailed"
+
+	// AppDownloadFailed is published when the App Framework gives up downloading an app
+	AppDownloadFailed EventReason = "AppDownloadFailed"
+
+	// AppInstallFailed is published when the App Framework gives up copying an app to a pod or installing it
+	AppInstallFailed EventReason = "AppInstallFailed"
+
+	// SmartStoreUpdateFailed is published when the SmartStore configuration cannot be applied
+	SmartStoreUpdateFailed EventReason = "SmartStoreUpdateFailed"
+
+	// BundlePushFailed is published when the cluster bundle of a cluster manager cannot be pushed to the peers
+	BundlePushFailed EventReason = "BundlePushFailed"
+
+	// MaintenanceModeFailed is published when the maintenance mode of an indexer cluster cannot be turned on or off
+	MaintenanceModeFailed EventReason = "MaintenanceModeFailed"
+
+	// MonitoringConsoleUpdateFailed is published when the peers of a monitoring console cannot be updated
+	MonitoringConsoleUpdateFailed EventReason = "MonitoringConsoleUpdateFailed"
+
+	// LicensePoolsFailed is published when the license pools of a license manager cannot be reconciled
+	LicensePoolsFailed EventReason = "LicensePoolsFailed"
+
+	// KVStoreBackupFailed is published when a KV store backup or restore of a search head cluster fails
+	KVStoreBackupFailed EventReason = "KVStoreBackupFailed"
+
+	// AdoptionFailed is published when a cluster manager cannot take over the resources of a cluster master
+	AdoptionFailed EventReason = "AdoptionFailed"
+
+	// PlanFailed is published when the plan of the changes of a custom resource cannot be reported
+	PlanFailed EventReason = "PlanFailed"
+
+	// DecommissionFailed is published when an indexer peer cannot be decommissioned
+	DecommissionFailed EventReason = "DecommissionFailed"
+)
+
+// Reasons of the Normal events
+const (
+	// ScalingUp is published when a custom resource starts adding pods
+	ScalingUp EventReason = "ScalingUp"
+
+	// ScalingDown is published when a custom resource starts removing pods
+	ScalingDown EventReason = "ScalingDown"
+
+	// Updating is published when a custom resource starts updating its pods
+	Updating EventReason = "Updating"
+
+	// Ready is published when all the pods of a custom resource are ready
+	Ready EventReason = "Ready"
+
+	// PendingMaintenanceWindow is published when the disruptive changes of a custom resource wait for a maintenance window
+	PendingMaintenanceWindow EventReason = "PendingMaintenanceWindow"
+
+	// DecommissionStarted is published when the decommission of an indexer peer starts
+	DecommissionStarted EventReason = "DecommissionStarted"
+
+	// DecommissionCompleted is published when an indexer peer is decommissioned
+	DecommissionCompleted EventReason = "DecommissionCompleted"
+
+	// BundlePushSucceeded is published when the cluster bundle of a cluster manager is pushed to the peers
+	BundlePushSucceeded EventReason = "BundlePushSucceeded"
+
+	// AppInstalled is published when an app is installed
+	AppInstalled EventReason = "AppInstalled"
+
+	// SecretRotated is published when the changed namespace scoped secret is applied to a pod
+	SecretRotated EventReason = "SecretRotated"
+
+	// MaintenanceModeEnabled is published when the maintenance mode of an indexer cluster is turned on
+	MaintenanceModeEnabled EventReason = "MaintenanceModeEnabled"
+
+	// MaintenanceModeDisabled is published when the maintenance mode of an indexer cluster is turned off
+	MaintenanceModeDisabled EventReason = "MaintenanceModeDisabled"
+
+	// KVStoreBackedUp is published when a KV store backup of a search head cluster completes
+	KVStoreBackedUp EventReason = "KVStoreBackedUp"
+
+	// KVStoreRestored is published when a KV store restore of a search head cluster completes
+	KVStoreRestored EventReason = "KVStoreRestored"
+
+	// ClusterMasterAdopted is published when a cluster manager takes over the resources of a cluster master
+	ClusterMasterAdopted EventReason = "ClusterMasterAdopted"
+
+	// Planned is published when the plan of the changes of a custom resource is reported
+	Planned EventReason = "Planned"
+)
+
+// phaseEventReasons are the reasons of the events published when a custom resource enters a phase
+var phaseEventReasons = map[enterpriseApi.Phase]EventReason{
+	enterpriseApi.PhaseScalingUp:                ScalingUp,
+	enterpriseApi.PhaseScalingDown:              ScalingDown,
+	enterpriseApi.PhaseUpdating:                 Updating,
+	enterpriseApi.PhaseReady:                    Ready,
+	enterpriseApi.PhasePendingMaintenanceWindow: PendingMaintenanceWindow,
+}
+
+// EventRecorder records the events of the custom resources, no events are published when nil
+var EventRecorder record.EventRecorder
+
+// EventDedupWindow is the time during which an event identical to a published one is dropped
+var EventDedupWindow = 10 * time.Minute
+
+const (
+	// eventBurst is the number of events a custom resource can publish at once
+	eventBurst = 10
+
+	// eventRefillInterval is the time it takes for a custom resource to be allowed one more event after its burst
+	eventRefillInterval = 30 * time.Second
+)
+
 // K8EventPublisher structure used to publish k8s event
 type K8EventPublisher struct {
-	client   splcommon.ControllerClient
-	instance interface{}
+	instance splcommon.MetaObject
 }
 
 // private function to get new k8s event publisher
-func newK8EventPublisher(client splcommon.ControllerClient, instance interface{}) (*K8EventPublisher, error) {
+func newK8EventPublisher(instance splcommon.MetaObject) (*K8EventPublisher, error) {
 	eventPublisher := &K8EventPublisher{
-		client:   client,
 		instance: instance,
 	}
 
 	return eventPublisher, nil
 }
 
-// publishEvents adds events to k8s
-func (k *K8EventPublisher) publishEvent(ctx context.Context, eventType, reason, message string) {
-
-	var event corev1.Event
-
-	// in the case of testing, client is not passed
-	if k.client == nil {
-		return
-	}
-
-	// based on the custom resource instance type find name, type and create new event
-	switch k.instance.(type) {
-	case *enterpriseApi.Standalone:
-		cr, ok := k.instance.(*enterpriseApi.Standalone)
-		if ok {
-			return
-		}
-		event = cr.NewEvent(eventType, reason, message)
-	case *enterpriseApiV3.LicenseMaster:
-		cr, ok := k.instance.(*enterpriseApiV3.LicenseMaster)
-		if ok {
-			return
-		}
-		event = cr.NewEvent(eventType, reason, message)
-	case *enterpriseApi.LicenseManager:
-		cr, ok := k.instance.(*enterpriseApi.LicenseManager)
-		if ok {
-			return
-		}
-		event = cr.NewEvent(eventType, reason, message)
-	case *enterpriseApi.IndexerCluster:
-		cr, ok := k.instance.(*enterpriseApi.IndexerCluster)
-		if ok {
-			return
-		}
-		event = cr.NewEvent(eventType, reason, message)
-	case *enterpriseApi.ClusterManager:
-		cr, ok := k.instance.(*enterpriseApi.ClusterManager)
-		if ok {
-			return
-		}
-		event = cr.NewEvent(eventType, reason, message)
-	case *enterpriseApiV3.ClusterMaster:
-		cr, ok := k.instance.(*enterpriseApiV3.ClusterMaster)
-		if ok {
-			return
-		}
-		event = cr.NewEvent(eventType, reason, message)
-	case *enterpriseApi.MonitoringConsole:
-		cr, ok := k.instance.(*enterpriseApi.MonitoringConsole)
-		if ok {
-			return
-		}
-		event = cr.NewEvent(eventType, reason, message)
-	case *enterpriseApi.SearchHeadCluster:
-		cr, ok := k.instance.(*enterpriseApi.SearchHeadCluster)
-		if ok {
-			return
-		}
-		event = cr.NewEvent(eventType, reason, message)
-	default:
+// publishEvents adds events to k8s, unless an identical event was published within EventDedupWindow or the custom
+// resource exceeded its event rate
+func (k *K8EventPublisher) publishEvent(ctx context.Context, eventType string, reason EventReason, message string) {
+	if EventRecorder == nil || k.instance == nil {
 		return
 	}
 
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("PublishEvent")
-	scopedLog.Info("publishing event", "reason", event.Reason, "message", event.Message)
 
-	err := k.client.Create(ctx, &event)
-	if err != nil {
-		scopedLog.Error(err, "failed to record event, ignoring",
-			"reason", event.Reason, "message", event.Message, "error", err)
+	key := fmt.Sprintf("%T/%s/%s", k.instance, k.instance.GetNamespace(), k.instance.GetName())
+	if !publishedEvents.allow(key, eventType+"/"+string(reason)+"/"+message, time.Now()) {
+		scopedLog.V(1).Info("dropping duplicate or rate limited event", "reason", reason, "message", message)
+		return
 	}
+
+	scopedLog.Info("publishing event", "reason", reason, "message", message)
+	EventRecorder.Event(k.instance, eventType, string(reason), message)
 }
 
 // Normal publish normal events to k8s
-func (k *K8EventPublisher) Normal(ctx context.Context, reason, message string) {
+func (k *K8EventPublisher) Normal(ctx context.Context, reason EventReason, message string) {
 	k.publishEvent(ctx, corev1.EventTypeNormal, reason, message)
 }
 
 // Warning publish warning events to k8s
-func (k *K8EventPublisher) Warning(ctx context.Context, reason, message string) {
+func (k *K8EventPublisher) Warning(ctx context.Context, reason EventReason, message string) {
 	k.publishEvent(ctx, corev1.EventTypeWarning, reason, message)
 }
+
+// publishPhaseChange publishes a Normal event when the phase of the custom resource changed during the reconcile.
+// It is deferred by the Apply functions with the phase the reconcile started from, errors having their own Warnings
+func (k *K8EventPublisher) publishPhaseChange(ctx context.Context, previous enterpriseApi.Phase, phase *enterpriseApi.Phase) {
+	if *phase == previous {
+		return
+	}
+	if reason, ok := phaseEventReasons[*phase]; ok {
+		k.Normal(ctx, reason, fmt.Sprintf("phase changed from %s to %s", previous, *phase))
+	}
+}
+
+// eventFilter drops the events of a custom resource identical to the ones it published within EventDedupWindow,
+// and the events exceeding its rate limit
+type eventFilter struct {
+	mutex     sync.Mutex
+	objects   map[string]*objectEvents
+	lastPrune time.Time
+}
+
+// objectEvents are the events recently published on a custom resource
+type objectEvents struct {
+	limiter   *rate.Limiter
+	published map[string]time.Time
+	lastEvent time.Time
+}
+
+// publishedEvents filters the events of all the custom resources
+var publishedEvents = newEventFilter()
+
+// newEventFilter returns an empty event filter
+func newEventFilter() *eventFilter {
+	return &eventFilter{objects: make(map[string]*objectEvents)}
+}
+
+// allow returns whether an event of an object can be published at a time, and records it if so
+func (f *eventFilter) allow(object, event string, now time.Time) bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	// forget the objects without events within the dedup window, which are deleted or quiet
+	if now.Sub(f.lastPrune) > EventDedupWindow {
+		for key, events := range f.objects {
+			if now.Sub(events.lastEvent) > EventDedupWindow {
+				delete(f.objects, key)
+			}
+		}
+		f.lastPrune = now
+	}
+
+	events, ok := f.objects[object]
+	if !ok {
+		events = &objectEvents{
+			limiter:   rate.NewLimiter(rate.Every(eventRefillInterval), eventBurst),
+			published: make(map[string]time.Time),
+		}
+		f.objects[object] = events
+	}
+
+	if published, ok := events.published[event]; ok && now.Sub(published) < EventDedupWindow {
+		return false
+	}
+	if !events.limiter.AllowN(now, 1) {
+		return false
+	}
+
+	for key, published := range events.published {
+		if now.Sub(published) >= EventDedupWindow {
+			delete(events.published, key)
+		}
+	}
+	events.published[event] = now
+	events.lastEvent = now
+	return true
+}
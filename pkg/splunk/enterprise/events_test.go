@@ -17,101 +17,156 @@ package enterprise
 
 import (
 	"context"
-	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	"fmt"
 	"testing"
+	"time"
 
-	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 )
 
-func init() {
+// setFakeEventRecorder records the events in a fake recorder until the returned function is called
+func setFakeEventRecorder() (*record.FakeRecorder, func()) {
+	recorder := record.NewFakeRecorder(100)
+	EventRecorder = recorder
+	publishedEvents = newEventFilter()
+	return recorder, func() {
+		EventRecorder = nil
+		publishedEvents = newEventFilter()
+	}
 }
 
-func TestClusterManagerEventPublisher(t *testing.T) {
+// checkEvents checks the events recorded by a fake recorder
+func checkEvents(t *testing.T, recorder *record.FakeRecorder, want ...string) {
+	t.Helper()
+	for _, event := range want {
+		select {
+		case got := <-recorder.Events:
+			if got != event {
+				t.Errorf("event = %q; want %q", got, event)
+			}
+		default:
+			t.Errorf("event %q not recorded", event)
+		}
+	}
+	select {
+	case got := <-recorder.Events:
+		t.Errorf("unexpected event %q", got)
+	default:
+	}
+}
 
-	builder := fake.NewClientBuilder()
-	c := builder.Build()
+func testEventPublisher(t *testing.T, cr splcommon.MetaObject) {
+	recorder, restore := setFakeEventRecorder()
+	defer restore()
 
-	cm := enterpriseApi.ClusterManager{}
-	k8sevent, err := newK8EventPublisher(c, &cm)
+	k8sevent, err := newK8EventPublisher(cr)
 	if err != nil {
 		t.Errorf("Unexpected error while creating new event publisher %v", err)
 	}
 
-	k8sevent.Normal(context.TODO(), "testing", "normal message")
-	k8sevent.Warning(context.TODO(), "testing", "warning message")
+	k8sevent.Normal(context.TODO(), Ready, "normal message")
+	k8sevent.Warning(context.TODO(), ConfigApplyFailed, "warning message")
+	checkEvents(t, recorder, "Normal Ready normal message", "Warning ConfigApplyFailed warning message")
 }
 
-func TestIndexerClusterEventPublisher(t *testing.T) {
-
-	builder := fake.NewClientBuilder()
-	c := builder.Build()
-
-	cm := enterpriseApi.IndexerCluster{}
-	k8sevent, err := newK8EventPublisher(c, &cm)
-	if err != nil {
-		t.Errorf("Unexpected error while creating new event publisher %v", err)
-	}
+func TestClusterManagerEventPublisher(t *testing.T) {
+	testEventPublisher(t, &enterpriseApi.ClusterManager{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"}})
+}
 
-	k8sevent.Normal(context.TODO(), "testing", "normal message")
-	k8sevent.Warning(context.TODO(), "testing", "warning message")
+func TestIndexerClusterEventPublisher(t *testing.T) {
+	testEventPublisher(t, &enterpriseApi.IndexerCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"}})
 }
 
 func TestMonitoringConsoleEventPublisher(t *testing.T) {
+	testEventPublisher(t, &enterpriseApi.MonitoringConsole{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"}})
+}
 
-	builder := fake.NewClientBuilder()
-	c := builder.Build()
+func TestSearchHeadClusterEventPublisher(t *testing.T) {
+	testEventPublisher(t, &enterpriseApi.SearchHeadCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"}})
+}
 
-	cm := enterpriseApi.MonitoringConsole{}
-	k8sevent, err := newK8EventPublisher(c, &cm)
-	if err != nil {
-		t.Errorf("Unexpected error while creating new event publisher %v", err)
-	}
+func TestStandaloneEventPublisher(t *testing.T) {
+	testEventPublisher(t, &enterpriseApi.Standalone{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"}})
+}
 
-	k8sevent.Normal(context.TODO(), "testing", "normal message")
-	k8sevent.Warning(context.TODO(), "testing", "warning message")
+func TestLicenseManagerEventPublisher(t *testing.T) {
+	testEventPublisher(t, &enterpriseApi.LicenseManager{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"}})
 }
 
-func TestSearchHeadClusterEventPublisher(t *testing.T) {
+func TestEventPublisherWithoutRecorder(t *testing.T) {
+	k8sevent, _ := newK8EventPublisher(&enterpriseApi.Standalone{})
 
-	builder := fake.NewClientBuilder()
-	c := builder.Build()
+	// the reconciles of the unit tests run without a recorder
+	k8sevent.Normal(context.TODO(), Ready, "normal message")
+}
 
-	cm := enterpriseApi.SearchHeadCluster{}
-	k8sevent, err := newK8EventPublisher(c, &cm)
-	if err != nil {
-		t.Errorf("Unexpected error while creating new event publisher %v", err)
+func TestEventPublisherDeduplication(t *testing.T) {
+	recorder, restore := setFakeEventRecorder()
+	defer restore()
+
+	cr := &enterpriseApi.IndexerCluster{ObjectMeta: metav1.ObjectMeta{Name: "idxc", Namespace: "test"}}
+	k8sevent, _ := newK8EventPublisher(cr)
+	for i := 0; i < 3; i++ {
+		k8sevent.Warning(context.TODO(), StatefulSetUpdateFailed, "update statefulset failed")
 	}
+	k8sevent.Normal(context.TODO(), StatefulSetUpdateFailed, "update statefulset failed")
+	checkEvents(t, recorder, "Warning StatefulSetUpdateFailed update statefulset failed", "Normal StatefulSetUpdateFailed update statefulset failed")
 
-	k8sevent.Normal(context.TODO(), "testing", "normal message")
-	k8sevent.Warning(context.TODO(), "testing", "warning message")
+	// the same event of another custom resource is published
+	other, _ := newK8EventPublisher(&enterpriseApi.IndexerCluster{ObjectMeta: metav1.ObjectMeta{Name: "idxc2", Namespace: "test"}})
+	other.Warning(context.TODO(), StatefulSetUpdateFailed, "update statefulset failed")
+	checkEvents(t, recorder, "Warning StatefulSetUpdateFailed update statefulset failed")
 }
 
-func TestStandaloneEventPublisher(t *testing.T) {
+func TestEventFilter(t *testing.T) {
+	filter := newEventFilter()
+	now := time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)
 
-	builder := fake.NewClientBuilder()
-	c := builder.Build()
+	if !filter.allow("idxc", "event", now) {
+		t.Errorf("allow() = false for a new event")
+	}
+	if filter.allow("idxc", "event", now.Add(EventDedupWindow-time.Second)) {
+		t.Errorf("allow() = true for an event published within the dedup window")
+	}
+	if !filter.allow("idxc", "event", now.Add(EventDedupWindow)) {
+		t.Errorf("allow() = false for an event published before the dedup window")
+	}
 
-	cm := enterpriseApi.Standalone{}
-	k8sevent, err := newK8EventPublisher(c, &cm)
-	if err != nil {
-		t.Errorf("Unexpected error while creating new event publisher %v", err)
+	// distinct events are rate limited once the burst is exhausted
+	now = now.Add(time.Hour)
+	for i := 0; i < eventBurst; i++ {
+		if !filter.allow("shc", fmt.Sprintf("event %d", i), now) {
+			t.Errorf("allow() = false for event %d of the burst", i)
+		}
+	}
+	if filter.allow("shc", "event after burst", now) {
+		t.Errorf("allow() = true for an event exceeding the burst")
+	}
+	if !filter.allow("shc", "event after burst", now.Add(eventRefillInterval)) {
+		t.Errorf("allow() = false for an event after the refill interval")
 	}
 
-	k8sevent.Normal(context.TODO(), "testing", "normal message")
-	k8sevent.Warning(context.TODO(), "testing", "warning message")
+	// the quiet objects are forgotten
+	filter.allow("shc", "event", now.Add(2*EventDedupWindow))
+	if _, ok := filter.objects["idxc"]; ok {
+		t.Errorf("allow() kept the events of a quiet object")
+	}
 }
 
-func TestLicenseManagerEventPublisher(t *testing.T) {
-
-	builder := fake.NewClientBuilder()
-	c := builder.Build()
+func TestPublishPhaseChange(t *testing.T) {
+	recorder, restore := setFakeEventRecorder()
+	defer restore()
 
-	cm := enterpriseApi.LicenseManager{}
-	k8sevent, err := newK8EventPublisher(c, &cm)
-	if err != nil {
-		t.Errorf("Unexpected error while creating new event publisher %v", err)
-	}
+	cr := &enterpriseApi.Standalone{ObjectMeta: metav1.ObjectMeta{Name: "s1", Namespace: "test"}}
+	k8sevent, _ := newK8EventPublisher(cr)
 
-	k8sevent.Normal(context.TODO(), "testing", "normal message")
-	k8sevent.Warning(context.TODO(), "testing", "warning message")
+	cr.Status.Phase = enterpriseApi.PhaseScalingDown
+	k8sevent.publishPhaseChange(context.TODO(), enterpriseApi.PhaseReady, &cr.Status.Phase)
+	k8sevent.publishPhaseChange(context.TODO(), enterpriseApi.PhaseScalingDown, &cr.Status.Phase)
+	cr.Status.Phase = enterpriseApi.PhaseError
+	k8sevent.publishPhaseChange(context.TODO(), enterpriseApi.PhaseScalingDown, &cr.Status.Phase)
+	checkEvents(t, recorder, "Normal ScalingDown phase changed from Ready to ScalingDown")
 }
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -51,16 +52,34 @@ func DeleteSplunkPvc(ctx context.Context, cr splcommon.MetaObject, c splcommon.C
 	case "IndexerCluster":
 		components = append(components, "indexer")
 	case "ClusterManager":
-		components = append(components, "cluster-manager")
+		components = append(components, string(getClusterManagerInstanceType(cr)))
 	case "ClusterMaster":
+		// the volumes of an adopted cluster master belong to its cluster manager
+		if isClusterMasterAdopted(cr) {
+			scopedLog.Info("Skipping PVC removal of adopted cluster master")
+			return nil
+		}
 		components = append(components, splcommon.ClusterManager)
 	case "MonitoringConsole":
 		components = append(components, "monitoring-console")
+	case "DeploymentServer":
+		components = append(components, "deployment-server")
 	default:
 		scopedLog.Info("Skipping PVC removal")
 		return nil
 	}
 
+	policy := getPVCRetentionPolicy(cr)
+	whenDeleted := policy.WhenDeleted
+	if whenDeleted == "" {
+		whenDeleted = enterpriseApi.PVCRetentionPolicyDelete
+	}
+	if whenDeleted == enterpriseApi.PVCRetentionPolicyRetain {
+		scopedLog.Info("Retaining PVCs")
+		return nil
+	}
+
+	pending := 0
 	for _, component := range components {
 		// get list of PVCs associated with this CR
 		labels := map[string]string{
@@ -76,18 +95,31 @@ func DeleteSplunkPvc(ctx context.Context, cr splcommon.MetaObject, c splcommon.C
 		}
 
 		if len(pvclist.Items) == 0 {
-			scopedLog.Info("No PVC found")
-			return nil
+			scopedLog.Info("No PVC found", "component", component)
+			continue
 		}
 
-		// delete each PVC
-		for _, pvc := range pvclist.Items {
-			scopedLog.Info("Deleting PVC", "name", pvc.ObjectMeta.Name)
-			if err := c.Delete(context.Background(), &pvc); err != nil {
+		// delete each PVC, taking a snapshot first if required
+		for i := range pvclist.Items {
+			deleted, err := splctrl.ApplyPVCRetentionPolicy(ctx, c, &pvclist.Items[i], whenDeleted, policy.VolumeSnapshotClassName)
+			if err != nil {
 				return err
 			}
+			if !deleted {
+				pending++
+			}
 		}
 
 	}
+
+	// the finalizer is kept, and the deletion retried, until the snapshots are ready and their PVCs deleted
+	if pending > 0 {
+		return fmt.Errorf("waiting for the volume snapshots of %d PVCs to be ready", pending)
+	}
 	return nil
 }
+
+// getPVCRetentionPolicy returns the PVC retention policy of a Splunk Enterprise custom resource
+func getPVCRetentionPolicy(cr splcommon.MetaObject) *enterpriseApi.PVCRetentionPolicy {
+	return &getCommonSplunkSpec(cr).PVCRetentionPolicy
+}
@@ -25,6 +25,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -53,6 +54,8 @@ func splunkDeletionTester(t *testing.T, cr splcommon.MetaObject, delete func(spl
 		component = "cluster-master"
 	case "MonitoringConsole":
 		component = "monitoring-console"
+	case "DeploymentServer":
+		component = "deployment-server"
 	}
 
 	labelsB := map[string]string{
@@ -158,6 +161,13 @@ func splunkDeletionTester(t *testing.T, cr splcommon.MetaObject, delete func(spl
 					{MetaName: "*v4.LicenseManager-test-stack1"},
 				}...)
 
+			case "DeploymentServer":
+				mockCalls["Get"] = append(mockCalls["Get"], []spltest.MockFuncCall{
+					{MetaName: "*v1.StatefulSet-test-splunk-stack1-deployment-server"},
+					{MetaName: "*v4.DeploymentServer-test-stack1"},
+					{MetaName: "*v4.DeploymentServer-test-stack1"},
+				}...)
+
 			case "SearchHeadCluster":
 				mockCalls["Get"] = append(mockCalls["Get"], []spltest.MockFuncCall{
 					{MetaName: "*v1.StatefulSet-test-splunk-stack1-search-head"},
@@ -358,3 +368,80 @@ func TestDeleteSplunkClusterManagerPvc(t *testing.T) {
 		t.Errorf("splctrl.CheckForDeletion() returned %t, %v; want false, (error)", deleted, err)
 	}
 }
+
+func TestDeleteSplunkPvcRetentionPolicy(t *testing.T) {
+	ctx := context.TODO()
+	cr := enterpriseApi.Standalone{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "Standalone",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	pvclist := corev1.PersistentVolumeClaimList{
+		Items: []corev1.PersistentVolumeClaim{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pvc-var-splunk-stack1-standalone-0",
+					Namespace: "test",
+					UID:       "3c1c9d3e-0000-0000-0000-000000000000",
+				},
+			},
+		},
+	}
+
+	// retained PVCs are not even listed
+	c := spltest.NewMockClient()
+	c.ListObj = &pvclist
+	cr.Spec.PVCRetentionPolicy.WhenDeleted = enterpriseApi.PVCRetentionPolicyRetain
+	err := DeleteSplunkPvc(ctx, &cr, c)
+	if err != nil {
+		t.Errorf("DeleteSplunkPvc() returned error: %v", err)
+	}
+	c.CheckCalls(t, "TestDeleteSplunkPvcRetentionPolicy", map[string][]spltest.MockFuncCall{})
+
+	// a snapshot is taken before deleting the PVC, and the finalizer is kept while it is not ready
+	c = spltest.NewMockClient()
+	c.ListObj = &pvclist
+	cr.Spec.PVCRetentionPolicy.WhenDeleted = enterpriseApi.PVCRetentionPolicySnapshot
+	cr.Spec.PVCRetentionPolicy.VolumeSnapshotClassName = "csi-snapclass"
+	err = DeleteSplunkPvc(ctx, &cr, c)
+	if err == nil {
+		t.Errorf("DeleteSplunkPvc() should have returned error while the volume snapshot is not ready")
+	}
+	listCall := []spltest.MockFuncCall{{ListOpts: []client.ListOption{client.InNamespace("test"), client.MatchingLabels(map[string]string{"app.kubernetes.io/instance": "splunk-stack1-standalone"})}}}
+	c.CheckCalls(t, "TestDeleteSplunkPvcRetentionPolicy", map[string][]spltest.MockFuncCall{
+		"Get":    {{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-standalone-0-3c1c9d3e"}},
+		"List":   listCall,
+		"Create": {{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-standalone-0-3c1c9d3e"}},
+		"Delete": {},
+	})
+	snapshot := c.Calls["Create"][0].Obj.(*unstructured.Unstructured)
+
+	c.ResetCalls()
+	err = DeleteSplunkPvc(ctx, &cr, c)
+	if err == nil {
+		t.Errorf("DeleteSplunkPvc() should have returned error while the volume snapshot is not ready")
+	}
+	c.CheckCalls(t, "TestDeleteSplunkPvcRetentionPolicy", map[string][]spltest.MockFuncCall{
+		"Get":    {{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-standalone-0-3c1c9d3e"}},
+		"List":   listCall,
+		"Create": {},
+		"Delete": {},
+	})
+
+	// the PVC is deleted once the snapshot is ready
+	snapshot.Object["status"] = map[string]interface{}{"readyToUse": true}
+	c.ResetCalls()
+	err = DeleteSplunkPvc(ctx, &cr, c)
+	if err != nil {
+		t.Errorf("DeleteSplunkPvc() returned error: %v", err)
+	}
+	c.CheckCalls(t, "TestDeleteSplunkPvcRetentionPolicy", map[string][]spltest.MockFuncCall{
+		"Get":    {{MetaName: "*unstructured.Unstructured-test-pvc-var-splunk-stack1-standalone-0-3c1c9d3e"}},
+		"List":   listCall,
+		"Delete": {{MetaName: "*v1.PersistentVolumeClaim-test-pvc-var-splunk-stack1-standalone-0"}},
+	})
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	"github.com/splunk/splunk-operator/pkg/splunk/health"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// newHealthAgentClient is used to change the probe level of the health agents, it can be replaced in tests
+var newHealthAgentClient = func() *health.Client {
+	return health.NewClient()
+}
+
+// getHealthAgentChecks returns the role specific readiness checks of the health agent of an instance type
+func getHealthAgentChecks(instanceType InstanceType) []string {
+	switch instanceType {
+	case SplunkSearchHead:
+		return []string{health.CheckSHCMember, health.CheckKVStore}
+	case SplunkStandalone:
+		return []string{health.CheckKVStore}
+	case SplunkIndexer:
+		return []string{health.CheckIndexerPeer}
+	}
+	return nil
+}
+
+// addHealthAgent adds the health agent sidecar to a pod template, and makes the probes of the splunk container use it.
+// The pod shares its process namespace, so that the agent sees the splunkd process
+func addHealthAgent(podTemplateSpec *corev1.PodTemplateSpec, spec *enterpriseApi.CommonSplunkSpec, instanceType InstanceType) {
+	if spec.HealthAgent == nil {
+		return
+	}
+
+	container := corev1.Container{
+		Name:            healthAgentContainerName,
+		Image:           GetHealthAgentImage(spec.HealthAgent.Image),
+		ImagePullPolicy: corev1.PullPolicy(spec.ImagePullPolicy),
+		Command:         []string{healthAgentCommand},
+		Ports: []corev1.ContainerPort{
+			{Name: "health", ContainerPort: health.DefaultPort, Protocol: corev1.ProtocolTCP},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "mnt-splunk-secrets", MountPath: "/mnt/splunk-secrets"},
+		},
+		Resources: *spec.HealthAgent.Resources.DeepCopy(),
+	}
+	if checks := getHealthAgentChecks(instanceType); len(checks) > 0 {
+		container.Args = []string{"--checks=" + strings.Join(checks, ",")}
+	}
+	setContainerDefaults(&container)
+	podTemplateSpec.Spec.Containers = append(podTemplateSpec.Spec.Containers, container)
+
+	shareProcessNamespace := true
+	podTemplateSpec.Spec.ShareProcessNamespace = &shareProcessNamespace
+
+	splunkContainer := &podTemplateSpec.Spec.Containers[0]
+	splunkContainer.LivenessProbe = getHealthAgentProbe(splunkContainer.LivenessProbe, health.LivenessPath)
+	splunkContainer.ReadinessProbe = getHealthAgentProbe(splunkContainer.ReadinessProbe, health.ReadinessPath)
+	splunkContainer.StartupProbe = getHealthAgentProbe(splunkContainer.StartupProbe, health.StartupPath)
+}
+
+// getHealthAgentProbe returns a copy of a probe that gets the given path of the health agent instead of running a script
+func getHealthAgentProbe(probe *corev1.Probe, path string) *corev1.Probe {
+	if probe == nil {
+		return nil
+	}
+	derivedProbe := *probe
+	derivedProbe.ProbeHandler = corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path:   path,
+			Port:   intstr.FromInt(health.DefaultPort),
+			Scheme: corev1.URISchemeHTTPS,
+		},
+	}
+	return &derivedProbe
+}
+
+// setProbeLevelOnHealthAgent sets the probe level on the health agent of a splunk pod. Returns false if the pod does
+// not run the health agent
+func setProbeLevelOnHealthAgent(ctx context.Context, c splcommon.ControllerClient, namespace, podName string, probeLevel int) (bool, error) {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("setProbeLevelOnHealthAgent").WithValues("podName", podName, "probeLevel", probeLevel)
+
+	var pod corev1.Pod
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: podName}, &pod)
+	if err != nil {
+		return false, nil
+	}
+	found := false
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == healthAgentContainerName {
+			found = true
+		}
+	}
+	if !found {
+		return false, nil
+	}
+	if pod.Status.PodIP == "" {
+		return true, fmt.Errorf("pod %s has no IP address", podName)
+	}
+
+	password, err := splutil.GetSpecificSecretTokenFromPod(ctx, c, podName, namespace, "password")
+	if err != nil {
+		return true, err
+	}
+	agentURL := "https://" + net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(health.DefaultPort))
+	err = newHealthAgentClient().SetProbeLevel(ctx, agentURL, "admin", password, probeLevel)
+	if err != nil {
+		scopedLog.Error(err, "Failed to set probe level")
+		return true, err
+	}
+
+	scopedLog.Info("Successfully set probe level on health agent")
+	return true, nil
+}
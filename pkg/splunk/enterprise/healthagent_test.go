@@ -0,0 +1,160 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	"github.com/splunk/splunk-operator/pkg/splunk/health"
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestGetSplunkStatefulSetHealthAgent(t *testing.T) {
+	ctx := context.TODO()
+	cr := enterpriseApi.Standalone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	cr.Spec.HealthAgent = &enterpriseApi.HealthAgentSpec{Image: "splunk/splunk-operator:test"}
+
+	c := spltest.NewMockClient()
+	_, err := splutil.ApplyNamespaceScopedSecretObject(ctx, c, "test")
+	if err != nil {
+		t.Errorf("Failed to create namespace scoped object")
+	}
+	if err := validateStandaloneSpec(ctx, c, &cr); err != nil {
+		t.Errorf("validateStandaloneSpec() returned error: %v", err)
+	}
+	ss, err := getStandaloneStatefulSet(ctx, c, &cr)
+	if err != nil {
+		t.Errorf("getStandaloneStatefulSet() returned error: %v", err)
+	}
+
+	podSpec := ss.Spec.Template.Spec
+	if len(podSpec.Containers) != 2 || podSpec.Containers[1].Name != healthAgentContainerName {
+		t.Fatalf("getStandaloneStatefulSet() containers = %v; want splunk and health-agent", podSpec.Containers)
+	}
+	agent := podSpec.Containers[1]
+	if agent.Image != "splunk/splunk-operator:test" || len(agent.Args) != 1 || agent.Args[0] != "--checks=kvstore" {
+		t.Errorf("getStandaloneStatefulSet() health agent = %v", agent)
+	}
+	if podSpec.ShareProcessNamespace == nil || !*podSpec.ShareProcessNamespace {
+		t.Errorf("getStandaloneStatefulSet() did not share the process namespace")
+	}
+
+	// the probes of the splunk container get the health agent, with their configured timings
+	splunk := podSpec.Containers[0]
+	for path, probe := range map[string]*corev1.Probe{
+		health.LivenessPath:  splunk.LivenessProbe,
+		health.ReadinessPath: splunk.ReadinessProbe,
+		health.StartupPath:   splunk.StartupProbe,
+	} {
+		if probe == nil || probe.Exec != nil || probe.HTTPGet == nil || probe.HTTPGet.Path != path || probe.HTTPGet.Port.IntValue() != health.DefaultPort {
+			t.Errorf("getStandaloneStatefulSet() probe = %v; want GET %s", probe, path)
+		}
+	}
+	if splunk.LivenessProbe.InitialDelaySeconds != livenessProbeDefaultDelaySec {
+		t.Errorf("getStandaloneStatefulSet() liveness probe delay = %d; want %d", splunk.LivenessProbe.InitialDelaySeconds, livenessProbeDefaultDelaySec)
+	}
+
+	// the default probes are not changed
+	if defaultLivenessProbe.Exec == nil || defaultLivenessProbe.HTTPGet != nil {
+		t.Errorf("getStandaloneStatefulSet() changed the default liveness probe")
+	}
+
+	if got := getHealthAgentChecks(SplunkSearchHead); len(got) != 2 {
+		t.Errorf("getHealthAgentChecks(SplunkSearchHead) = %v; want shc-member and kvstore", got)
+	}
+	if got := getHealthAgentChecks(SplunkClusterManager); len(got) != 0 {
+		t.Errorf("getHealthAgentChecks(SplunkClusterManager) = %v; want none", got)
+	}
+}
+
+func TestSetProbeLevelOnHealthAgent(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("changeme"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	agent := health.NewAgent(health.Config{Username: "admin", PasswordFile: passwordFile})
+	server := httptest.NewTLSServer(agent.Handler())
+	defer server.Close()
+
+	// connect to the test server instead of the pod IP
+	savedNewHealthAgentClient := newHealthAgentClient
+	defer func() { newHealthAgentClient = savedNewHealthAgentClient }()
+	newHealthAgentClient = func() *health.Client {
+		c := health.NewClient().Client
+		transport := c.Transport.(*http.Transport).Clone()
+		transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, server.Listener.Addr().String())
+		}
+		c.Transport = transport
+		return &health.Client{Client: c}
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "splunk-stack1-standalone-secret-v1", Namespace: "test"},
+		Data:       map[string][]byte{"password": []byte("changeme")},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "splunk-stack1-standalone-0", Namespace: "test"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "splunk"}},
+			Volumes: []corev1.Volume{{
+				Name:         "mnt-splunk-secrets",
+				VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secret.GetName()}},
+			}},
+		},
+		Status: corev1.PodStatus{PodIP: "10.0.0.1"},
+	}
+	c := spltest.NewMockClient()
+	c.AddObjects([]client.Object{secret, pod})
+
+	// pods without the health agent are left to the probe scripts
+	found, err := setProbeLevelOnHealthAgent(ctx, c, "test", pod.GetName(), livenessProbeLevelOne)
+	if found || err != nil {
+		t.Errorf("setProbeLevelOnHealthAgent() = %t, %v; want false without health agent", found, err)
+	}
+	found, err = setProbeLevelOnHealthAgent(ctx, c, "test", "splunk-stack1-standalone-1", livenessProbeLevelOne)
+	if found || err != nil {
+		t.Errorf("setProbeLevelOnHealthAgent() = %t, %v; want false without pod", found, err)
+	}
+
+	pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: healthAgentContainerName})
+	c.AddObject(pod)
+	found, err = setProbeLevelOnHealthAgent(ctx, c, "test", pod.GetName(), livenessProbeLevelOne)
+	if !found || err != nil {
+		t.Errorf("setProbeLevelOnHealthAgent() = %t, %v; want true", found, err)
+	}
+	if agent.GetProbeLevel() != livenessProbeLevelOne {
+		t.Errorf("setProbeLevelOnHealthAgent() probe level = %d; want %d", agent.GetProbeLevel(), livenessProbeLevelOne)
+	}
+}
@@ -0,0 +1,638 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	splhec "github.com/splunk/splunk-operator/pkg/splunk/hec"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// label identifying the custom resource a HEC token secret belongs to
+	hecTokenOwnerLabel = "enterprise.splunk.com/hec-token-owner"
+
+	// label holding the name of the HEC token stored in a secret
+	hecTokenNameLabel = "enterprise.splunk.com/hec-token"
+
+	// identifier to track the HEC defaults revision on the Pod
+	hecConfigRev = "hecConfigRev"
+
+	// volume name and mount location of the rendered HEC defaults
+	hecDefaultsVolumeName = "mnt-splunk-hec"
+	hecDefaultsMountPath  = "/mnt/splunk-hec"
+
+	// directory the HEC inputs are written to on the Splunk pods
+	hecInputsDirectory = "/opt/splunk/etc/apps/splunk_httpinput/local"
+
+	// HEC endpoints path prefix exposed by the ingress routes
+	hecCollectorPath = "/services/collector"
+
+	// HEC port on the Splunk pods
+	hecContainerPort = 8088
+)
+
+// HecHTTPRouteGVK is the Gateway API HTTPRoute kind used when exposing HEC through a Gateway
+var HecHTTPRouteGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1beta1", Kind: "HTTPRoute"}
+
+// validateHecSpec checks validity and makes default updates to a HecSpec, and returns error if something is wrong.
+func validateHecSpec(hec *enterpriseApi.HecSpec) error {
+	if !hec.Enabled {
+		return nil
+	}
+
+	tokenNames := make(map[string]bool, len(hec.Tokens))
+	for _, token := range hec.Tokens {
+		if token.Name == "" {
+			return fmt.Errorf("hec token name can not be empty")
+		}
+		if tokenNames[token.Name] {
+			return fmt.Errorf("duplicate hec token name %s", token.Name)
+		}
+		tokenNames[token.Name] = true
+
+		if token.DefaultIndex != "" && len(token.AllowedIndexes) > 0 {
+			allowed := false
+			for _, index := range token.AllowedIndexes {
+				if index == token.DefaultIndex {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return fmt.Errorf("default index %s of hec token %s is not part of the allowed indexes", token.DefaultIndex, token.Name)
+			}
+		}
+	}
+
+	if hec.Ingress.Type == "" {
+		hec.Ingress.Type = enterpriseApi.HecIngressNone
+	}
+
+	switch hec.Ingress.Type {
+	case enterpriseApi.HecIngressNone, enterpriseApi.HecIngressIngress:
+	case enterpriseApi.HecIngressGateway:
+		if hec.Ingress.GatewayRef.Name == "" {
+			return fmt.Errorf("hec ingress of type Gateway requires gatewayRef.name")
+		}
+	default:
+		return fmt.Errorf("invalid hec ingress type %s", hec.Ingress.Type)
+	}
+
+	return nil
+}
+
+// ApplyHecConfig reconciles the HEC token secrets, the rendered HEC inputs, the HEC service and the optional ingress route
+func ApplyHecConfig(ctx context.Context, client splcommon.ControllerClient, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec, hec *enterpriseApi.HecSpec, instanceType InstanceType) (enterpriseApi.HecStatus, error) {
+	ctx, span := tracing.Start(ctx, "ApplyHecConfig")
+	defer span.End()
+
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("ApplyHecConfig").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	status := enterpriseApi.HecStatus{}
+	if !hec.Enabled {
+		return status, removeHecConfig(ctx, client, cr, spec, instanceType)
+	}
+
+	// create the secrets for new tokens, existing token values are kept
+	tokenValues := make(map[string]string, len(hec.Tokens))
+	for _, token := range hec.Tokens {
+		secretName := GetSplunkHecTokenSecretName(cr.GetName(), instanceType, token.Name)
+		secret, err := splutil.ApplyHecTokenSecret(ctx, client, cr, secretName, getHecTokenLabels(cr, instanceType, token.Name))
+		if err != nil {
+			return status, err
+		}
+		tokenValues[token.Name] = string(secret.Data[splcommon.HecTokenSecretKey])
+		status.Tokens = append(status.Tokens, enterpriseApi.HecTokenStatus{Name: token.Name, SecretName: secretName})
+	}
+
+	// remove the secrets of tokens which are no longer part of the spec
+	err := removeStaleHecTokenSecrets(ctx, client, cr, hec, instanceType)
+	if err != nil {
+		return status, err
+	}
+
+	// render the tokens as HEC inputs consumed through SPLUNK_DEFAULTS_URL
+	hecDefaults, err := getHecDefaults(hec, tokenValues)
+	if err != nil {
+		return status, err
+	}
+	_, err = splutil.ApplySplunkSecret(ctx, client, cr, map[string][]byte{"default.yml": hecDefaults}, GetSplunkHecDefaultsName(cr.GetName(), instanceType), cr.GetNamespace())
+	if err != nil {
+		return status, err
+	}
+
+	// create or update the HEC service, it only selects ready pods
+	service := getSplunkHecService(ctx, cr, spec, instanceType)
+	err = splctrl.ApplyService(ctx, client, service)
+	if err != nil {
+		return status, err
+	}
+	status.ServiceName = service.GetName()
+
+	// create or update the route exposing HEC outside of the cluster, and remove the unused one
+	if hec.Ingress.Type == enterpriseApi.HecIngressIngress {
+		err = splctrl.ApplyIngress(ctx, client, getHecIngress(cr, hec, instanceType))
+	} else {
+		err = deleteHecRoute(ctx, client, &networkingv1.Ingress{}, cr, instanceType)
+	}
+	if err != nil {
+		return status, err
+	}
+
+	if hec.Ingress.Type == enterpriseApi.HecIngressGateway {
+		err = splctrl.ApplyUnstructured(ctx, client, getHecHTTPRoute(cr, hec, instanceType))
+	} else {
+		route := &unstructured.Unstructured{}
+		route.SetGroupVersionKind(HecHTTPRouteGVK)
+		err = deleteHecRoute(ctx, client, route, cr, instanceType)
+	}
+	if err != nil {
+		return status, err
+	}
+
+	scopedLog.Info("HEC configuration applied", "tokens", len(status.Tokens), "ingressType", hec.Ingress.Type)
+	return status, nil
+}
+
+// removeHecConfig deletes the HEC token secrets, defaults, service and routes of a custom resource whose HEC was
+// disabled. The service is kept when it is configured as a traffic service. The defaults secret is deleted last, so
+// that it tells whether a cleanup is left to do.
+func removeHecConfig(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec, instanceType InstanceType) error {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("removeHecConfig").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	defaultsName := types.NamespacedName{Namespace: cr.GetNamespace(), Name: GetSplunkHecDefaultsName(cr.GetName(), instanceType)}
+	defaults := &corev1.Secret{}
+	err := c.Get(ctx, defaultsName, defaults)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	scopedLog.Info("Removing the HEC configuration of the disabled HEC")
+	err = removeStaleHecTokenSecrets(ctx, c, cr, &enterpriseApi.HecSpec{}, instanceType)
+	if err != nil {
+		return err
+	}
+
+	err = deleteHecRoute(ctx, c, &networkingv1.Ingress{}, cr, instanceType)
+	if err != nil {
+		return err
+	}
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(HecHTTPRouteGVK)
+	err = deleteHecRoute(ctx, c, route, cr, instanceType)
+	if err != nil {
+		return err
+	}
+
+	if spec.Services.Hec == nil {
+		serviceName := types.NamespacedName{Namespace: cr.GetNamespace(), Name: GetSplunkHecServiceName(instanceType, cr.GetName())}
+		err = deleteOptionalObject(ctx, c, &corev1.Service{}, serviceName)
+		if err != nil {
+			return err
+		}
+	}
+
+	return c.Delete(ctx, defaults)
+}
+
+// getHecTokenLabels returns the labels set on the secret of a HEC token
+func getHecTokenLabels(cr splcommon.MetaObject, instanceType InstanceType, tokenName string) map[string]string {
+	labels := getHecTokenOwnerLabels(cr, instanceType)
+	labels[hecTokenNameLabel] = tokenName
+	return labels
+}
+
+// getHecTokenOwnerLabels returns the labels selecting all HEC token secrets of a custom resource
+func getHecTokenOwnerLabels(cr splcommon.MetaObject, instanceType InstanceType) map[string]string {
+	return map[string]string{
+		hecTokenOwnerLabel: GetSplunkStatefulsetName(instanceType, cr.GetName()),
+	}
+}
+
+// removeStaleHecTokenSecrets deletes the token secrets of a custom resource which are no longer part of the HecSpec
+func removeStaleHecTokenSecrets(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, hec *enterpriseApi.HecSpec, instanceType InstanceType) error {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("removeStaleHecTokenSecrets").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	secretList := corev1.SecretList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(cr.GetNamespace()),
+		client.MatchingLabels(getHecTokenOwnerLabels(cr, instanceType)),
+	}
+	err := c.List(ctx, &secretList, listOpts...)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	wanted := make(map[string]bool, len(hec.Tokens))
+	for _, token := range hec.Tokens {
+		wanted[GetSplunkHecTokenSecretName(cr.GetName(), instanceType, token.Name)] = true
+	}
+
+	for i := range secretList.Items {
+		secret := &secretList.Items[i]
+		if wanted[secret.GetName()] {
+			continue
+		}
+		scopedLog.Info("Deleting secret of removed HEC token", "secret", secret.GetName(), "token", secret.GetLabels()[hecTokenNameLabel])
+		err = splutil.DeleteResource(ctx, c, secret)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getHecDefaults renders the HEC tokens into a defaults file understood by splunk-ansible
+func getHecDefaults(hec *enterpriseApi.HecSpec, tokenValues map[string]string) ([]byte, error) {
+	stanzas := make(map[string]map[string]string, len(hec.Tokens))
+	for _, token := range hec.Tokens {
+		stanza := map[string]string{
+			"token":    tokenValues[token.Name],
+			"disabled": "0",
+		}
+		if token.Disabled {
+			stanza["disabled"] = "1"
+		}
+		if token.DefaultIndex != "" {
+			stanza["index"] = token.DefaultIndex
+		}
+		if len(token.AllowedIndexes) > 0 {
+			indexes := append([]string{}, token.AllowedIndexes...)
+			sort.Strings(indexes)
+			stanza["indexes"] = strings.Join(indexes, ",")
+		}
+		if token.SourceType != "" {
+			stanza["sourcetype"] = token.SourceType
+		}
+		stanzas["http://"+token.Name] = stanza
+	}
+
+	defaults := map[string]interface{}{
+		"splunk": map[string]interface{}{
+			"hec": map[string]interface{}{
+				"enable": true,
+			},
+			"conf": []interface{}{
+				map[string]interface{}{
+					"key": "inputs",
+					"value": map[string]interface{}{
+						"directory": hecInputsDirectory,
+						"content":   stanzas,
+					},
+				},
+			},
+		},
+	}
+
+	return yaml.Marshal(defaults)
+}
+
+// getSplunkHecService returns the Kubernetes Service load balancing HEC traffic across the ready pods of a Splunk Enterprise resource
+func getSplunkHecService(ctx context.Context, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec, instanceType InstanceType) *corev1.Service {
+	service := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        GetSplunkHecServiceName(instanceType, cr.GetName()),
+			Namespace:   cr.GetNamespace(),
+			Labels:      make(map[string]string),
+			Annotations: make(map[string]string),
+		},
+	}
+
+	// PublishNotReadyAddresses is left unset so that only ready pods receive events
+	service.Spec.Type = corev1.ServiceTypeClusterIP
+	service.Spec.Selector = getSplunkServiceSelector(cr, spec, instanceType)
+	service.Spec.Ports = []corev1.ServicePort{
+		{
+			Name:       GetPortName(hecPort, protoHTTP),
+			Port:       hecContainerPort,
+			TargetPort: intstr.FromInt(hecContainerPort),
+			Protocol:   corev1.ProtocolTCP,
+		},
+	}
+
+	// append same labels as selector
+	for k, v := range service.Spec.Selector {
+		service.ObjectMeta.Labels[k] = v
+	}
+
+	// append labels and annotations from parent
+	splcommon.AppendParentMeta(service.ObjectMeta.GetObjectMeta(), cr.GetObjectMeta())
+
+	// the HEC service is also the traffic service for HEC
+	if spec.Services.Hec != nil {
+		applySplunkServiceSpec(service, cr, instanceType, hecPort, &spec.Services.Hec.SplunkServiceSpec)
+	}
+
+	service.SetOwnerReferences(append(service.GetOwnerReferences(), splcommon.AsOwner(cr, true)))
+
+	return service
+}
+
+// getHecIngress returns the Kubernetes Ingress exposing the HEC service
+func getHecIngress(cr splcommon.MetaObject, hec *enterpriseApi.HecSpec, instanceType InstanceType) *networkingv1.Ingress {
+	serviceName := GetSplunkHecServiceName(instanceType, cr.GetName())
+	pathType := networkingv1.PathTypePrefix
+
+	ingress := &networkingv1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Ingress",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        serviceName,
+			Namespace:   cr.GetNamespace(),
+			Labels:      getSplunkLabels(cr.GetName(), instanceType, ""),
+			Annotations: make(map[string]string),
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: hec.Ingress.Host,
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     hecCollectorPath,
+									PathType: &pathType,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: serviceName,
+											Port: networkingv1.ServiceBackendPort{
+												Name: GetPortName(hecPort, protoHTTP),
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for k, v := range hec.Ingress.Annotations {
+		ingress.ObjectMeta.Annotations[k] = v
+	}
+
+	if hec.Ingress.IngressClassName != "" {
+		ingressClassName := hec.Ingress.IngressClassName
+		ingress.Spec.IngressClassName = &ingressClassName
+	}
+
+	if hec.Ingress.TLSSecretName != "" {
+		tls := networkingv1.IngressTLS{SecretName: hec.Ingress.TLSSecretName}
+		if hec.Ingress.Host != "" {
+			tls.Hosts = []string{hec.Ingress.Host}
+		}
+		ingress.Spec.TLS = []networkingv1.IngressTLS{tls}
+	}
+
+	ingress.SetOwnerReferences(append(ingress.GetOwnerReferences(), splcommon.AsOwner(cr, true)))
+
+	return ingress
+}
+
+// getHecHTTPRoute returns the Gateway API HTTPRoute exposing the HEC service
+func getHecHTTPRoute(cr splcommon.MetaObject, hec *enterpriseApi.HecSpec, instanceType InstanceType) *unstructured.Unstructured {
+	serviceName := GetSplunkHecServiceName(instanceType, cr.GetName())
+
+	parentRef := map[string]interface{}{
+		"name": hec.Ingress.GatewayRef.Name,
+	}
+	if hec.Ingress.GatewayRef.Namespace != "" {
+		parentRef["namespace"] = hec.Ingress.GatewayRef.Namespace
+	}
+	if hec.Ingress.GatewayRef.SectionName != "" {
+		parentRef["sectionName"] = hec.Ingress.GatewayRef.SectionName
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"matches": []interface{}{
+					map[string]interface{}{
+						"path": map[string]interface{}{
+							"type":  "PathPrefix",
+							"value": hecCollectorPath,
+						},
+					},
+				},
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": serviceName,
+						"port": int64(hecContainerPort),
+					},
+				},
+			},
+		},
+	}
+	if hec.Ingress.Host != "" {
+		spec["hostnames"] = []interface{}{hec.Ingress.Host}
+	}
+
+	route := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	route.SetGroupVersionKind(HecHTTPRouteGVK)
+	route.SetName(serviceName)
+	route.SetNamespace(cr.GetNamespace())
+	route.SetLabels(getSplunkLabels(cr.GetName(), instanceType, ""))
+	if len(hec.Ingress.Annotations) > 0 {
+		route.SetAnnotations(hec.Ingress.Annotations)
+	}
+	route.SetOwnerReferences([]metav1.OwnerReference{splcommon.AsOwner(cr, true)})
+
+	return route
+}
+
+// deleteHecRoute removes a HEC ingress route which is no longer wanted, if it exists
+func deleteHecRoute(ctx context.Context, c splcommon.ControllerClient, route client.Object, cr splcommon.MetaObject, instanceType InstanceType) error {
+	namespacedName := types.NamespacedName{Namespace: cr.GetNamespace(), Name: GetSplunkHecServiceName(instanceType, cr.GetName())}
+	return deleteOptionalObject(ctx, c, route, namespacedName)
+}
+
+// deleteOptionalObject removes an object which is no longer wanted (e.g. an ingress route), if it exists
+func deleteOptionalObject(ctx context.Context, c splcommon.ControllerClient, obj client.Object, namespacedName types.NamespacedName) error {
+	err := c.Get(ctx, namespacedName, obj)
+	if err != nil {
+		// the CRDs of the object (e.g. Gateway API, cert-manager) may not be installed in the cluster
+		if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("deleteOptionalObject").WithValues("name", namespacedName.Name, "namespace", namespacedName.Namespace)
+	scopedLog.Info("Deleting object", "kind", obj.GetObjectKind().GroupVersionKind().Kind)
+	return c.Delete(ctx, obj)
+}
+
+// addHecConfigToTemplate mounts the rendered HEC inputs into the Splunk containers and adds them to SPLUNK_DEFAULTS_URL
+func addHecConfigToTemplate(ctx context.Context, client splcommon.ControllerClient, podTemplateSpec *corev1.PodTemplateSpec, cr splcommon.MetaObject, hec *enterpriseApi.HecSpec, instanceType InstanceType) {
+	if !hec.Enabled {
+		return
+	}
+
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("addHecConfigToTemplate").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	secretName := GetSplunkHecDefaultsName(cr.GetName(), instanceType)
+	secretVolDefaultMode := int32(corev1.SecretVolumeSourceDefaultMode)
+	addSplunkVolumeToTemplate(podTemplateSpec, hecDefaultsVolumeName, hecDefaultsMountPath, corev1.VolumeSource{
+		Secret: &corev1.SecretVolumeSource{
+			SecretName:  secretName,
+			DefaultMode: &secretVolDefaultMode,
+		},
+	})
+
+	// HEC inputs go first, so that the inline defaults of the CR can still override them
+	hecDefaults := hecDefaultsMountPath + "/default.yml"
+	for idx := range podTemplateSpec.Spec.Containers {
+		if podTemplateSpec.Spec.Containers[idx].Name != splunkContainerName {
+			continue
+		}
+		for i, env := range podTemplateSpec.Spec.Containers[idx].Env {
+			if env.Name == "SPLUNK_DEFAULTS_URL" {
+				podTemplateSpec.Spec.Containers[idx].Env[i].Value = fmt.Sprintf("%s,%s", hecDefaults, env.Value)
+			}
+		}
+	}
+
+	// We will update the annotation for resource version in the pod template spec
+	// so that any change in the HEC tokens will lead to recycle of the pod.
+	secret, err := splutil.GetSecretByName(ctx, client, cr.GetNamespace(), cr.GetName(), secretName)
+	if err != nil {
+		scopedLog.Error(err, "Updation of HEC config annotation failed")
+		return
+	}
+	if podTemplateSpec.ObjectMeta.Annotations == nil {
+		podTemplateSpec.ObjectMeta.Annotations = make(map[string]string)
+	}
+	podTemplateSpec.ObjectMeta.Annotations[hecConfigRev] = secret.GetResourceVersion()
+}
+
+// HecTarget is the HTTP Event Collector of a Standalone or an IndexerCluster the operator forwards its own logs and
+// metrics to. The endpoint is resolved from the HEC status of the custom resource, so that it follows its token.
+type HecTarget struct {
+	// Reader reads the custom resource and the secret of its token, it must be set before resolving the endpoint
+	Reader client.Reader
+
+	Kind      string
+	Namespace string
+	Name      string
+
+	// Token is the name of the HEC token, the first token of the custom resource when empty
+	Token string
+}
+
+// ParseHecTarget parses a reference to the HEC of a custom resource, as Kind/namespace/name[/token]
+func ParseHecTarget(ref string) (*HecTarget, error) {
+	parts := strings.Split(ref, "/")
+	if len(parts) < 3 || len(parts) > 4 || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("invalid HEC reference %q, expected Kind/namespace/name[/token]", ref)
+	}
+	target := &HecTarget{Namespace: parts[1], Name: parts[2]}
+	switch strings.ToLower(parts[0]) {
+	case "standalone":
+		target.Kind = "Standalone"
+	case "indexercluster":
+		target.Kind = "IndexerCluster"
+	default:
+		return nil, fmt.Errorf("invalid HEC reference %q, the kind must be Standalone or IndexerCluster", ref)
+	}
+	if len(parts) == 4 {
+		target.Token = parts[3]
+	}
+	return target, nil
+}
+
+// Endpoint returns the URL of the HEC service of the custom resource and the value of its token
+func (t *HecTarget) Endpoint(ctx context.Context) (splhec.Endpoint, error) {
+	var status enterpriseApi.HecStatus
+	namespacedName := types.NamespacedName{Namespace: t.Namespace, Name: t.Name}
+	switch t.Kind {
+	case "Standalone":
+		cr := &enterpriseApi.Standalone{}
+		if err := t.Reader.Get(ctx, namespacedName, cr); err != nil {
+			return splhec.Endpoint{}, err
+		}
+		status = cr.Status.Hec
+	case "IndexerCluster":
+		cr := &enterpriseApi.IndexerCluster{}
+		if err := t.Reader.Get(ctx, namespacedName, cr); err != nil {
+			return splhec.Endpoint{}, err
+		}
+		status = cr.Status.Hec
+	}
+	if status.ServiceName == "" || len(status.Tokens) == 0 {
+		return splhec.Endpoint{}, fmt.Errorf("HEC is not enabled with a token on %s %s", t.Kind, namespacedName)
+	}
+
+	secretName := ""
+	for _, token := range status.Tokens {
+		if t.Token == "" || token.Name == t.Token {
+			secretName = token.SecretName
+			break
+		}
+	}
+	if secretName == "" {
+		return splhec.Endpoint{}, fmt.Errorf("%s %s has no HEC token %s", t.Kind, namespacedName, t.Token)
+	}
+
+	secret := &corev1.Secret{}
+	if err := t.Reader.Get(ctx, types.NamespacedName{Namespace: t.Namespace, Name: secretName}, secret); err != nil {
+		return splhec.Endpoint{}, err
+	}
+	return splhec.Endpoint{
+		URL:   fmt.Sprintf("https://%s.%s.svc:%d", status.ServiceName, t.Namespace, hecContainerPort),
+		Token: string(secret.Data[splcommon.HecTokenSecretKey]),
+	}, nil
+}
@@ -0,0 +1,360 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestValidateHecSpec(t *testing.T) {
+	hec := enterpriseApi.HecSpec{}
+	if err := validateHecSpec(&hec); err != nil {
+		t.Errorf("validateHecSpec() returned %v for disabled hec; want nil", err)
+	}
+
+	hec.Enabled = true
+	hec.Tokens = []enterpriseApi.HecTokenSpec{{Name: "ingest"}, {Name: "audit", DefaultIndex: "audit", AllowedIndexes: []string{"audit"}}}
+	if err := validateHecSpec(&hec); err != nil {
+		t.Errorf("validateHecSpec() returned %v; want nil", err)
+	}
+	if hec.Ingress.Type != enterpriseApi.HecIngressNone {
+		t.Errorf("validateHecSpec() did not default ingress type, got %s", hec.Ingress.Type)
+	}
+
+	hec.Tokens = append(hec.Tokens, enterpriseApi.HecTokenSpec{Name: "ingest"})
+	if err := validateHecSpec(&hec); err == nil {
+		t.Errorf("validateHecSpec() did not detect duplicate token names")
+	}
+
+	hec.Tokens = []enterpriseApi.HecTokenSpec{{Name: "audit", DefaultIndex: "main", AllowedIndexes: []string{"audit"}}}
+	if err := validateHecSpec(&hec); err == nil {
+		t.Errorf("validateHecSpec() did not detect default index outside of the allowed indexes")
+	}
+
+	hec.Tokens = nil
+	hec.Ingress.Type = enterpriseApi.HecIngressGateway
+	if err := validateHecSpec(&hec); err == nil {
+		t.Errorf("validateHecSpec() did not detect missing gatewayRef")
+	}
+
+	hec.Ingress.Type = "LoadBalancer"
+	if err := validateHecSpec(&hec); err == nil {
+		t.Errorf("validateHecSpec() did not detect invalid ingress type")
+	}
+}
+
+func TestGetHecDefaults(t *testing.T) {
+	hec := enterpriseApi.HecSpec{
+		Enabled: true,
+		Tokens: []enterpriseApi.HecTokenSpec{
+			{Name: "ingest", DefaultIndex: "main", AllowedIndexes: []string{"main", "apps"}, SourceType: "_json"},
+			{Name: "legacy", Disabled: true},
+		},
+	}
+	tokens := map[string]string{"ingest": "token-1", "legacy": "token-2"}
+
+	got, err := getHecDefaults(&hec, tokens)
+	if err != nil {
+		t.Errorf("getHecDefaults() returned %v", err)
+	}
+	want := `splunk:
+  conf:
+  - key: inputs
+    value:
+      content:
+        http://ingest:
+          disabled: "0"
+          index: main
+          indexes: apps,main
+          sourcetype: _json
+          token: token-1
+        http://legacy:
+          disabled: "1"
+          token: token-2
+      directory: /opt/splunk/etc/apps/splunk_httpinput/local
+  hec:
+    enable: true
+`
+	if string(got) != want {
+		t.Errorf("getHecDefaults() = %s; want %s", got, want)
+	}
+}
+
+func TestGetSplunkHecService(t *testing.T) {
+	ctx := context.TODO()
+	cr := enterpriseApi.IndexerCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	cr.Spec.ClusterManagerRef.Name = "cluster1"
+
+	f := func() (interface{}, error) {
+		return getSplunkHecService(ctx, &cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer), nil
+	}
+	configTester(t, "getSplunkHecService()", f, `{"kind":"Service","apiVersion":"v1","metadata":{"name":"splunk-stack1-indexer-hec","namespace":"test","creationTimestamp":null,"labels":{"app.kubernetes.io/component":"indexer","app.kubernetes.io/instance":"splunk-stack1-indexer","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"indexer","app.kubernetes.io/part-of":"splunk-cluster1-indexer"},"ownerReferences":[{"apiVersion":"","kind":"","name":"stack1","uid":"","controller":true}]},"spec":{"ports":[{"name":"http-hec","protocol":"TCP","port":8088,"targetPort":8088}],"selector":{"app.kubernetes.io/component":"indexer","app.kubernetes.io/instance":"splunk-stack1-indexer","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"indexer","app.kubernetes.io/part-of":"splunk-cluster1-indexer"},"type":"ClusterIP"},"status":{"loadBalancer":{}}}`)
+}
+
+func TestGetHecIngress(t *testing.T) {
+	cr := enterpriseApi.Standalone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	hec := enterpriseApi.HecSpec{
+		Enabled: true,
+		Ingress: enterpriseApi.HecIngressSpec{
+			Type:             enterpriseApi.HecIngressIngress,
+			Host:             "hec.example.com",
+			IngressClassName: "nginx",
+			TLSSecretName:    "hec-tls",
+			Annotations:      map[string]string{"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS"},
+		},
+	}
+
+	f := func() (interface{}, error) {
+		return getHecIngress(&cr, &hec, SplunkStandalone), nil
+	}
+	configTester(t, "getHecIngress()", f, `{"kind":"Ingress","apiVersion":"networking.k8s.io/v1","metadata":{"name":"splunk-stack1-standalone-hec","namespace":"test","creationTimestamp":null,"labels":{"app.kubernetes.io/component":"standalone","app.kubernetes.io/instance":"splunk-stack1-standalone","app.kubernetes.io/managed-by":"splunk-operator","app.kubernetes.io/name":"standalone","app.kubernetes.io/part-of":"splunk-stack1-standalone"},"annotations":{"nginx.ingress.kubernetes.io/backend-protocol":"HTTPS"},"ownerReferences":[{"apiVersion":"","kind":"","name":"stack1","uid":"","controller":true}]},"spec":{"ingressClassName":"nginx","tls":[{"hosts":["hec.example.com"],"secretName":"hec-tls"}],"rules":[{"host":"hec.example.com","http":{"paths":[{"path":"/services/collector","pathType":"Prefix","backend":{"service":{"name":"splunk-stack1-standalone-hec","port":{"name":"http-hec"}}}}]}}]},"status":{"loadBalancer":{}}}`)
+}
+
+func TestGetHecHTTPRoute(t *testing.T) {
+	cr := enterpriseApi.Standalone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	hec := enterpriseApi.HecSpec{
+		Enabled: true,
+		Ingress: enterpriseApi.HecIngressSpec{
+			Type:       enterpriseApi.HecIngressGateway,
+			Host:       "hec.example.com",
+			GatewayRef: enterpriseApi.HecGatewayRef{Name: "gw", Namespace: "infra", SectionName: "https"},
+		},
+	}
+
+	route := getHecHTTPRoute(&cr, &hec, SplunkStandalone)
+	if route.GetKind() != "HTTPRoute" || route.GetName() != "splunk-stack1-standalone-hec" {
+		t.Errorf("getHecHTTPRoute() returned %s %s", route.GetKind(), route.GetName())
+	}
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	if len(parentRefs) != 1 || parentRefs[0].(map[string]interface{})["sectionName"] != "https" {
+		t.Errorf("getHecHTTPRoute() parentRefs = %v", parentRefs)
+	}
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	if len(hostnames) != 1 || hostnames[0] != "hec.example.com" {
+		t.Errorf("getHecHTTPRoute() hostnames = %v", hostnames)
+	}
+}
+
+func TestApplyHecConfig(t *testing.T) {
+	ctx := context.TODO()
+	cr := enterpriseApi.Standalone{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "Standalone",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	cr.Spec.Hec = enterpriseApi.HecSpec{
+		Enabled: true,
+		Tokens:  []enterpriseApi.HecTokenSpec{{Name: "ingest"}},
+		Ingress: enterpriseApi.HecIngressSpec{Type: enterpriseApi.HecIngressIngress, Host: "hec.example.com"},
+	}
+
+	// a token removed from the spec
+	staleSecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      GetSplunkHecTokenSecretName("stack1", SplunkStandalone, "removed"),
+			Namespace: "test",
+			Labels:    getHecTokenLabels(&cr, SplunkStandalone, "removed"),
+		},
+	}
+
+	c := spltest.NewMockClient()
+	c.AddObject(&staleSecret)
+	c.ListObj = &corev1.SecretList{Items: []corev1.Secret{staleSecret}}
+
+	status, err := ApplyHecConfig(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, &cr.Spec.Hec, SplunkStandalone)
+	if err != nil {
+		t.Errorf("ApplyHecConfig() returned %v", err)
+	}
+	if status.ServiceName != "splunk-stack1-standalone-hec" || len(status.Tokens) != 1 || status.Tokens[0].SecretName != "splunk-stack1-standalone-hec-token-ingest" {
+		t.Errorf("ApplyHecConfig() status = %v", status)
+	}
+
+	wantCreates := []string{"*v1.Secret-test-splunk-stack1-standalone-hec-token-ingest", "*v1.Secret-test-splunk-stack1-standalone-hec-defaults", "*v1.Service-test-splunk-stack1-standalone-hec", "*v1.Ingress-test-splunk-stack1-standalone-hec"}
+	if len(c.Calls["Create"]) != len(wantCreates) {
+		t.Errorf("ApplyHecConfig() made %d Create calls; want %d", len(c.Calls["Create"]), len(wantCreates))
+	}
+	for i, call := range c.Calls["Create"] {
+		if i < len(wantCreates) && !strings.HasSuffix(wantCreates[i], call.Obj.GetName()) {
+			t.Errorf("ApplyHecConfig() Create call %d = %s; want %s", i, call.Obj.GetName(), wantCreates[i])
+		}
+	}
+	if len(c.Calls["Delete"]) != 1 || c.Calls["Delete"][0].Obj.GetName() != staleSecret.GetName() {
+		t.Errorf("ApplyHecConfig() did not delete the secret of the removed token")
+	}
+
+	// the rendered inputs hold the generated token
+	var token, defaults corev1.Secret
+	_ = c.Get(ctx, types.NamespacedName{Namespace: "test", Name: "splunk-stack1-standalone-hec-token-ingest"}, &token)
+	_ = c.Get(ctx, types.NamespacedName{Namespace: "test", Name: "splunk-stack1-standalone-hec-defaults"}, &defaults)
+	if !strings.Contains(string(defaults.Data["default.yml"]), string(token.Data[splcommon.HecTokenSecretKey])) {
+		t.Errorf("HEC defaults do not contain the generated token")
+	}
+
+	// disabling hec removes its tokens, defaults, service and route
+	c.ResetCalls()
+	c.ListObj = &corev1.SecretList{Items: []corev1.Secret{token}}
+	cr.Spec.Hec.Enabled = false
+	status, err = ApplyHecConfig(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, &cr.Spec.Hec, SplunkStandalone)
+	if err != nil || status.ServiceName != "" || len(status.Tokens) != 0 {
+		t.Errorf("ApplyHecConfig() with disabled hec returned %v, status %v", err, status)
+	}
+	wantDeletes := []string{"splunk-stack1-standalone-hec-token-ingest", "splunk-stack1-standalone-hec", "splunk-stack1-standalone-hec", "splunk-stack1-standalone-hec-defaults"}
+	if len(c.Calls["Delete"]) != len(wantDeletes) {
+		t.Errorf("ApplyHecConfig() with disabled hec made %d Delete calls; want %d", len(c.Calls["Delete"]), len(wantDeletes))
+	}
+	for i, call := range c.Calls["Delete"] {
+		if i < len(wantDeletes) && call.Obj.GetName() != wantDeletes[i] {
+			t.Errorf("ApplyHecConfig() Delete call %d = %s; want %s", i, call.Obj.GetName(), wantDeletes[i])
+		}
+	}
+
+	// once removed, disabled hec only looks up the defaults
+	c.ResetCalls()
+	_, err = ApplyHecConfig(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, &cr.Spec.Hec, SplunkStandalone)
+	if err != nil || len(c.Calls) != 1 || len(c.Calls["Get"]) != 1 {
+		t.Errorf("ApplyHecConfig() with removed hec returned %v, calls %v", err, c.Calls)
+	}
+
+	// the service of the hec traffic is kept
+	cr.Spec.Services.Hec = &enterpriseApi.SplunkRoutedServiceSpec{}
+	c.AddObject(&defaults)
+	c.AddObject(getSplunkHecService(ctx, &cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone))
+	c.ResetCalls()
+	_, err = ApplyHecConfig(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, &cr.Spec.Hec, SplunkStandalone)
+	if err != nil || len(c.Calls["Delete"]) == 0 {
+		t.Errorf("ApplyHecConfig() with a hec traffic service returned %v, calls %v", err, c.Calls)
+	}
+	for _, call := range c.Calls["Delete"] {
+		if _, ok := call.Obj.(*corev1.Service); ok {
+			t.Errorf("ApplyHecConfig() deleted the hec traffic service")
+		}
+	}
+}
+
+func TestAddHecConfigToTemplate(t *testing.T) {
+	ctx := context.TODO()
+	cr := enterpriseApi.Standalone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	cr.Spec.Hec.Enabled = true
+
+	c := spltest.NewMockClient()
+	secret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            GetSplunkHecDefaultsName("stack1", SplunkStandalone),
+			Namespace:       "test",
+			ResourceVersion: "42",
+		},
+	}
+	c.AddObject(&secret)
+
+	podTemplateSpec := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name: "splunk",
+					Env:  []corev1.EnvVar{{Name: "SPLUNK_DEFAULTS_URL", Value: "/mnt/splunk-secrets/default.yml"}},
+				},
+			},
+		},
+	}
+	addHecConfigToTemplate(ctx, c, &podTemplateSpec, &cr, &cr.Spec.Hec, SplunkStandalone)
+
+	if got := podTemplateSpec.Spec.Containers[0].Env[0].Value; got != "/mnt/splunk-hec/default.yml,/mnt/splunk-secrets/default.yml" {
+		t.Errorf("SPLUNK_DEFAULTS_URL = %s", got)
+	}
+	if len(podTemplateSpec.Spec.Volumes) != 1 || podTemplateSpec.Spec.Volumes[0].Secret.SecretName != secret.GetName() {
+		t.Errorf("HEC defaults volume not added, volumes = %v", podTemplateSpec.Spec.Volumes)
+	}
+	if podTemplateSpec.ObjectMeta.Annotations[hecConfigRev] != "42" {
+		t.Errorf("HEC config revision annotation = %s; want 42", podTemplateSpec.ObjectMeta.Annotations[hecConfigRev])
+	}
+}
+
+func TestHecTarget(t *testing.T) {
+	ctx := context.TODO()
+	for _, ref := range []string{"Standalone/test", "Forwarder/test/s1", "IndexerCluster//idxc", "Standalone/test/s1/token/extra"} {
+		if _, err := ParseHecTarget(ref); err == nil {
+			t.Errorf("ParseHecTarget(%q) returned no error", ref)
+		}
+	}
+	target, err := ParseHecTarget("standalone/test/stack1/operator")
+	if err != nil || target.Kind != "Standalone" || target.Namespace != "test" || target.Name != "stack1" || target.Token != "operator" {
+		t.Fatalf("ParseHecTarget() = %+v, %v", target, err)
+	}
+
+	cr := enterpriseApi.Standalone{ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"}}
+	c := spltest.NewMockClient()
+	c.AddObject(&cr)
+	target.Reader = c
+	if _, err = target.Endpoint(ctx); err == nil {
+		t.Errorf("Endpoint() returned no error while HEC is disabled")
+	}
+
+	cr.Status.Hec = enterpriseApi.HecStatus{
+		ServiceName: "splunk-stack1-standalone-hec",
+		Tokens: []enterpriseApi.HecTokenStatus{
+			{Name: "ingest", SecretName: "splunk-stack1-standalone-hec-token-ingest"},
+			{Name: "operator", SecretName: "splunk-stack1-standalone-hec-token-operator"},
+		},
+	}
+	c.AddObject(&cr)
+	c.AddObject(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "splunk-stack1-standalone-hec-token-operator", Namespace: "test"},
+		Data:       map[string][]byte{splcommon.HecTokenSecretKey: []byte("operator-token")},
+	})
+	endpoint, err := target.Endpoint(ctx)
+	if err != nil || endpoint.URL != "https://splunk-stack1-standalone-hec.test.svc:8088" || endpoint.Token != "operator-token" {
+		t.Errorf("Endpoint() = %+v, %v", endpoint, err)
+	}
+
+	target.Token = "missing"
+	if _, err = target.Endpoint(ctx); err == nil {
+		t.Errorf("Endpoint() returned no error for a missing token")
+	}
+}
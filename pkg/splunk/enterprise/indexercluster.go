@@ -28,9 +28,11 @@ import (
 
 	"github.com/go-logr/logr"
 	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	"github.com/splunk/splunk-operator/pkg/splunk/audit"
 	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -46,6 +48,8 @@ type NewSplunkClientFunc func(managementURI, username, password string) *splclie
 
 // ApplyIndexerClusterManager reconciles the state of a Splunk Enterprise indexer cluster.
 func ApplyIndexerClusterManager(ctx context.Context, client splcommon.ControllerClient, cr *enterpriseApi.IndexerCluster) (reconcile.Result, error) {
+	ctx, span := tracing.Start(ctx, "ApplyIndexerClusterManager")
+	defer span.End()
 
 	// unless modified, reconcile for this object will be requeued after 5 seconds
 	result := reconcile.Result{
@@ -54,7 +58,9 @@ func ApplyIndexerClusterManager(ctx context.Context, client splcommon.Controller
 	}
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("ApplyIndexerClusterManager").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
-	eventPublisher, _ := newK8EventPublisher(client, cr)
+	eventPublisher, _ := newK8EventPublisher(cr)
+	defer eventPublisher.publishPhaseChange(ctx, cr.Status.Phase, &cr.Status.Phase)
+	ctx = audit.NewContext(ctx, cr, &cr.Status.AuditTrail)
 
 	// validate and updates defaults for CR
 	err := validateIndexerClusterSpec(ctx, client, cr)
@@ -84,7 +90,7 @@ func ApplyIndexerClusterManager(ctx context.Context, client splcommon.Controller
 	namespaceScopedSecret, err := ApplySplunkConfig(ctx, client, cr, cr.Spec.CommonSplunkSpec, SplunkIndexer)
 	if err != nil {
 		scopedLog.Error(err, "create or update general config failed", "error", err.Error())
-		eventPublisher.Warning(ctx, "ApplySplunkConfig", fmt.Sprintf("create or update general config failed with error %s", err.Error()))
+		eventPublisher.Warning(ctx, ConfigApplyFailed, fmt.Sprintf("create or update general config failed with error %s", err.Error()))
 		return result, err
 	}
 
@@ -108,11 +114,12 @@ func ApplyIndexerClusterManager(ctx context.Context, client splcommon.Controller
 	}
 
 	mgr := newIndexerClusterPodManager(scopedLog, cr, namespaceScopedSecret, splclient.NewSplunkClient)
+	mgr.clusterManagerType = getClusterManagerInstanceType(managerIdxCluster)
 	// Check if we have configured enough number(<= RF) of replicas
 	if mgr.cr.Status.ClusterManagerPhase == enterpriseApi.PhaseReady {
 		err = VerifyRFPeers(ctx, mgr, client)
 		if err != nil {
-			eventPublisher.Warning(ctx, "verifyRFPeers", fmt.Sprintf("verify RF peer failed %s", err.Error()))
+			eventPublisher.Warning(ctx, ValidationFailed, fmt.Sprintf("verify RF peer failed %s", err.Error()))
 			return result, err
 		}
 	}
@@ -128,28 +135,47 @@ func ApplyIndexerClusterManager(ctx context.Context, client splcommon.Controller
 			result.Requeue = false
 		}
 		if err != nil {
-			eventPublisher.Warning(ctx, "Delete", fmt.Sprintf("delete custom resource failed %s", err.Error()))
+			eventPublisher.Warning(ctx, DeleteFailed, fmt.Sprintf("delete custom resource failed %s", err.Error()))
 		}
 		return result, err
 	}
 	// create or update a headless service for indexer cluster
 	err = splctrl.ApplyService(ctx, client, getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, true))
 	if err != nil {
-		eventPublisher.Warning(ctx, "ApplyService", fmt.Sprintf("create/update headless service for indexer cluster failed %s", err.Error()))
+		eventPublisher.Warning(ctx, ServiceApplyFailed, fmt.Sprintf("create/update headless service for indexer cluster failed %s", err.Error()))
 		return result, err
 	}
 
 	// create or update a regular service for indexer cluster (ingestion)
 	err = splctrl.ApplyService(ctx, client, getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, false))
 	if err != nil {
-		eventPublisher.Warning(ctx, "ApplyService", fmt.Sprintf("create/update service for indexer cluster failed %s", err.Error()))
+		eventPublisher.Warning(ctx, ServiceApplyFailed, fmt.Sprintf("create/update service for indexer cluster failed %s", err.Error()))
+		return result, err
+	}
+
+	// create or update the services of each type of traffic and their routes
+	err = ApplySplunkTrafficServices(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, cr.Spec.Hec.Enabled)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the certificates of the ports and the secret mounting them
+	err = ApplySplunkTLS(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the HEC tokens, service and ingress route
+	cr.Status.Hec, err = ApplyHecConfig(ctx, client, cr, &cr.Spec.CommonSplunkSpec, &cr.Spec.Hec, SplunkIndexer)
+	if err != nil {
+		eventPublisher.Warning(ctx, ConfigApplyFailed, fmt.Sprintf("create/update hec configuration failed %s", err.Error()))
 		return result, err
 	}
 
 	// create or update statefulset for the indexers
 	statefulSet, err := getIndexerStatefulSet(ctx, client, cr)
 	if err != nil {
-		eventPublisher.Warning(ctx, "getIndexerStatefulSet", fmt.Sprintf("get indexer stateful set failed %s", err.Error()))
+		eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("get indexer stateful set failed %s", err.Error()))
 		return result, err
 	}
 
@@ -198,15 +224,15 @@ func ApplyIndexerClusterManager(ctx context.Context, client splcommon.Controller
 	if !versionUpgrade {
 		phase, err = mgr.Update(ctx, client, statefulSet, cr.Spec.Replicas)
 		if err != nil {
-			eventPublisher.Warning(ctx, "UpdateManager", fmt.Sprintf("update statefulset failed %s", err.Error()))
+			eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("update statefulset failed %s", err.Error()))
 			return result, err
 		}
 	} else {
 		// Delete the statefulset and recreate new one
 		err = client.Delete(ctx, statefulSet)
 		if err != nil {
-			eventPublisher.Warning(ctx, "UpdateManager", fmt.Sprintf("version mitmatch for indexer clustre and indexer container, delete statefulset failed %s", err.Error()))
-			eventPublisher.Warning(ctx, "UpdateManager", fmt.Sprintf("%s-%s, %s-%s", "indexer-image", cr.Spec.Image, "container-image", statefulSet.Spec.Template.Spec.Containers[0].Image))
+			eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("version mitmatch for indexer clustre and indexer container, delete statefulset failed %s", err.Error()))
+			eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("%s-%s, %s-%s", "indexer-image", cr.Spec.Image, "container-image", statefulSet.Spec.Template.Spec.Containers[0].Image))
 			return result, err
 		}
 		time.Sleep(1 * time.Second)
@@ -214,7 +240,7 @@ func ApplyIndexerClusterManager(ctx context.Context, client splcommon.Controller
 		statefulSet.ResourceVersion = ""
 		phase, err = mgr.Update(ctx, client, statefulSet, cr.Spec.Replicas)
 		if err != nil {
-			eventPublisher.Warning(ctx, "UpdateManager", fmt.Sprintf("update statefulset failed %s", err.Error()))
+			eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("update statefulset failed %s", err.Error()))
 			return result, err
 		}
 	}
@@ -226,7 +252,7 @@ func ApplyIndexerClusterManager(ctx context.Context, client splcommon.Controller
 		//Retrieve monitoring  console ref from CM Spec
 		cmMonitoringConsoleConfigRef, err := RetrieveCMSpec(ctx, client, cr)
 		if err != nil {
-			eventPublisher.Warning(ctx, "RetrieveCMSpec", fmt.Sprintf("retrive cluster manager spec failed %s", err.Error()))
+			eventPublisher.Warning(ctx, ValidationFailed, fmt.Sprintf("retrive cluster manager spec failed %s", err.Error()))
 			return result, err
 		}
 		if cmMonitoringConsoleConfigRef != "" {
@@ -235,9 +261,9 @@ func ApplyIndexerClusterManager(ctx context.Context, client splcommon.Controller
 			//if MC pod already exists
 			if err == nil {
 				c := mgr.getMonitoringConsoleClient(cr, cmMonitoringConsoleConfigRef)
-				err := c.AutomateMCApplyChanges()
+				err := c.AutomateMCApplyChanges(ctx)
 				if err != nil {
-					eventPublisher.Warning(ctx, "AutomateMCApplyChanges", fmt.Sprintf("get monitoring console client failed %s", err.Error()))
+					eventPublisher.Warning(ctx, MonitoringConsoleUpdateFailed, fmt.Sprintf("get monitoring console client failed %s", err.Error()))
 					return result, err
 				}
 			}
@@ -252,12 +278,21 @@ func ApplyIndexerClusterManager(ctx context.Context, client splcommon.Controller
 			} else {
 				return result, errors.New("empty cluster manager reference")
 			}
-			cmPodName := fmt.Sprintf("splunk-%s-cluster-manager-%s", managerIdxcName, "0")
+			cmPodName := fmt.Sprintf("splunk-%s-%s-%s", managerIdxcName, getClusterManagerInstanceType(managerIdxCluster), "0")
 			podExecClient := splutil.GetPodExecClient(client, cr, cmPodName)
 			// Disable maintenance mode
 			err = SetClusterMaintenanceMode(ctx, client, cr, false, cmPodName, podExecClient)
 			if err != nil {
-				eventPublisher.Warning(ctx, "SetClusterMaintenanceMode", fmt.Sprintf("set cluster maintainance mode failed %s", err.Error()))
+				eventPublisher.Warning(ctx, MaintenanceModeFailed, fmt.Sprintf("set cluster maintainance mode failed %s", err.Error()))
+				return result, err
+			}
+		}
+
+		if len(cr.Spec.ClusterManagerRef.Name) > 0 {
+			cmPodName := fmt.Sprintf("splunk-%s-%s-%s", cr.Spec.ClusterManagerRef.Name, getClusterManagerInstanceType(managerIdxCluster), "0")
+			err = reconcileRequestedMaintenanceMode(ctx, client, cr, cmPodName, splutil.GetPodExecClient(client, cr, cmPodName))
+			if err != nil {
+				eventPublisher.Warning(ctx, MaintenanceModeFailed, fmt.Sprintf("set requested cluster maintenance mode failed %s", err.Error()))
 				return result, err
 			}
 		}
@@ -271,11 +306,11 @@ func ApplyIndexerClusterManager(ctx context.Context, client splcommon.Controller
 		// Set indexer cluster CR as owner reference for clustermanager
 		scopedLog.Info("Setting indexer cluster as owner for cluster manager")
 		if len(cr.Spec.ClusterManagerRef.Name) > 0 {
-			namespacedName = types.NamespacedName{Namespace: cr.GetNamespace(), Name: GetSplunkStatefulsetName(SplunkClusterManager, cr.Spec.ClusterManagerRef.Name)}
+			namespacedName = types.NamespacedName{Namespace: cr.GetNamespace(), Name: GetSplunkStatefulsetName(getClusterManagerInstanceType(managerIdxCluster), cr.Spec.ClusterManagerRef.Name)}
 		}
 		err = splctrl.SetStatefulSetOwnerRef(ctx, client, cr, namespacedName)
 		if err != nil {
-			eventPublisher.Warning(ctx, "SetStatefulSetOwnerRef", fmt.Sprintf("set stateful set owner reference failed %s", err.Error()))
+			eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("set stateful set owner reference failed %s", err.Error()))
 			result.Requeue = true
 			return result, err
 		}
@@ -290,6 +325,8 @@ func ApplyIndexerClusterManager(ctx context.Context, client splcommon.Controller
 
 // ApplyIndexerCluster reconciles the state of a Splunk Enterprise indexer cluster for Older CM CRDs.
 func ApplyIndexerCluster(ctx context.Context, client splcommon.ControllerClient, cr *enterpriseApi.IndexerCluster) (reconcile.Result, error) {
+	ctx, span := tracing.Start(ctx, "ApplyIndexerCluster")
+	defer span.End()
 
 	// unless modified, reconcile for this object will be requeued after 5 seconds
 	result := reconcile.Result{
@@ -298,7 +335,9 @@ func ApplyIndexerCluster(ctx context.Context, client splcommon.ControllerClient,
 	}
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("ApplyIndexerCluster")
-	eventPublisher, _ := newK8EventPublisher(client, cr)
+	eventPublisher, _ := newK8EventPublisher(cr)
+	defer eventPublisher.publishPhaseChange(ctx, cr.Status.Phase, &cr.Status.Phase)
+	ctx = audit.NewContext(ctx, cr, &cr.Status.AuditTrail)
 
 	// validate and updates defaults for CR
 	err := validateIndexerClusterSpec(ctx, client, cr)
@@ -328,7 +367,7 @@ func ApplyIndexerCluster(ctx context.Context, client splcommon.ControllerClient,
 	namespaceScopedSecret, err := ApplySplunkConfig(ctx, client, cr, cr.Spec.CommonSplunkSpec, SplunkIndexer)
 	if err != nil {
 		scopedLog.Error(err, "create or update general config failed", "error", err.Error())
-		eventPublisher.Warning(ctx, "ApplySplunkConfig", fmt.Sprintf("create or update general config failed with error %s", err.Error()))
+		eventPublisher.Warning(ctx, ConfigApplyFailed, fmt.Sprintf("create or update general config failed with error %s", err.Error()))
 		return result, err
 	}
 
@@ -355,7 +394,7 @@ func ApplyIndexerCluster(ctx context.Context, client splcommon.ControllerClient,
 	if mgr.cr.Status.ClusterMasterPhase == enterpriseApi.PhaseReady {
 		err = VerifyRFPeers(ctx, mgr, client)
 		if err != nil {
-			eventPublisher.Warning(ctx, "verifyRFPeers", fmt.Sprintf("verify RF peer failed %s", err.Error()))
+			eventPublisher.Warning(ctx, ValidationFailed, fmt.Sprintf("verify RF peer failed %s", err.Error()))
 			return result, err
 		}
 	}
@@ -371,7 +410,7 @@ func ApplyIndexerCluster(ctx context.Context, client splcommon.ControllerClient,
 			result.Requeue = false
 		}
 		if err != nil {
-			eventPublisher.Warning(ctx, "Delete", fmt.Sprintf("delete custom resource failed %s", err.Error()))
+			eventPublisher.Warning(ctx, DeleteFailed, fmt.Sprintf("delete custom resource failed %s", err.Error()))
 		}
 		return result, err
 	}
@@ -379,21 +418,40 @@ func ApplyIndexerCluster(ctx context.Context, client splcommon.ControllerClient,
 	// create or update a headless service for indexer cluster
 	err = splctrl.ApplyService(ctx, client, getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, true))
 	if err != nil {
-		eventPublisher.Warning(ctx, "ApplyService", fmt.Sprintf("create/update headless service for indexer cluster failed %s", err.Error()))
+		eventPublisher.Warning(ctx, ServiceApplyFailed, fmt.Sprintf("create/update headless service for indexer cluster failed %s", err.Error()))
 		return result, err
 	}
 
 	// create or update a regular service for indexer cluster (ingestion)
 	err = splctrl.ApplyService(ctx, client, getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, false))
 	if err != nil {
-		eventPublisher.Warning(ctx, "ApplyService", fmt.Sprintf("create/update service for indexer cluster failed %s", err.Error()))
+		eventPublisher.Warning(ctx, ServiceApplyFailed, fmt.Sprintf("create/update service for indexer cluster failed %s", err.Error()))
+		return result, err
+	}
+
+	// create or update the services of each type of traffic and their routes
+	err = ApplySplunkTrafficServices(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, cr.Spec.Hec.Enabled)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the certificates of the ports and the secret mounting them
+	err = ApplySplunkTLS(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the HEC tokens, service and ingress route
+	cr.Status.Hec, err = ApplyHecConfig(ctx, client, cr, &cr.Spec.CommonSplunkSpec, &cr.Spec.Hec, SplunkIndexer)
+	if err != nil {
+		eventPublisher.Warning(ctx, ConfigApplyFailed, fmt.Sprintf("create/update hec configuration failed %s", err.Error()))
 		return result, err
 	}
 
 	// create or update statefulset for the indexers
 	statefulSet, err := getIndexerStatefulSet(ctx, client, cr)
 	if err != nil {
-		eventPublisher.Warning(ctx, "getIndexerStatefulSet", fmt.Sprintf("get indexer stateful set failed %s", err.Error()))
+		eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("get indexer stateful set failed %s", err.Error()))
 		return result, err
 	}
 
@@ -445,15 +503,15 @@ func ApplyIndexerCluster(ctx context.Context, client splcommon.ControllerClient,
 	if !versionUpgrade {
 		phase, err = mgr.Update(ctx, client, statefulSet, cr.Spec.Replicas)
 		if err != nil {
-			eventPublisher.Warning(ctx, "UpdateManager", fmt.Sprintf("update statefulset failed %s", err.Error()))
+			eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("update statefulset failed %s", err.Error()))
 			return result, err
 		}
 	} else {
 		// Delete the statefulset and recreate new one
 		err = client.Delete(ctx, statefulSet)
 		if err != nil {
-			eventPublisher.Warning(ctx, "UpdateManager", fmt.Sprintf("version mitmatch for indexer clustre and indexer container, delete statefulset failed %s", err.Error()))
-			eventPublisher.Warning(ctx, "UpdateManager", fmt.Sprintf("%s-%s, %s-%s", "indexer-image", cr.Spec.Image, "container-image", statefulSet.Spec.Template.Spec.Containers[0].Image))
+			eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("version mitmatch for indexer clustre and indexer container, delete statefulset failed %s", err.Error()))
+			eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("%s-%s, %s-%s", "indexer-image", cr.Spec.Image, "container-image", statefulSet.Spec.Template.Spec.Containers[0].Image))
 			return result, err
 		}
 		time.Sleep(1 * time.Second)
@@ -461,7 +519,7 @@ func ApplyIndexerCluster(ctx context.Context, client splcommon.ControllerClient,
 		statefulSet.ResourceVersion = ""
 		phase, err = mgr.Update(ctx, client, statefulSet, cr.Spec.Replicas)
 		if err != nil {
-			eventPublisher.Warning(ctx, "UpdateManager", fmt.Sprintf("update statefulset failed %s", err.Error()))
+			eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("update statefulset failed %s", err.Error()))
 			return result, err
 		}
 	}
@@ -473,7 +531,7 @@ func ApplyIndexerCluster(ctx context.Context, client splcommon.ControllerClient,
 		//Retrieve monitoring  console ref from CM Spec
 		cmMonitoringConsoleConfigRef, err := RetrieveCMSpec(ctx, client, cr)
 		if err != nil {
-			eventPublisher.Warning(ctx, "RetrieveCMSpec", fmt.Sprintf("retrive cluster master spec failed %s", err.Error()))
+			eventPublisher.Warning(ctx, ValidationFailed, fmt.Sprintf("retrive cluster master spec failed %s", err.Error()))
 			return result, err
 		}
 		if cmMonitoringConsoleConfigRef != "" {
@@ -482,9 +540,9 @@ func ApplyIndexerCluster(ctx context.Context, client splcommon.ControllerClient,
 			//if MC pod already exists
 			if err == nil {
 				c := mgr.getMonitoringConsoleClient(cr, cmMonitoringConsoleConfigRef)
-				err := c.AutomateMCApplyChanges()
+				err := c.AutomateMCApplyChanges(ctx)
 				if err != nil {
-					eventPublisher.Warning(ctx, "AutomateMCApplyChanges", fmt.Sprintf("get monitoring console client failed %s", err.Error()))
+					eventPublisher.Warning(ctx, MonitoringConsoleUpdateFailed, fmt.Sprintf("get monitoring console client failed %s", err.Error()))
 					return result, err
 				}
 			}
@@ -504,7 +562,16 @@ func ApplyIndexerCluster(ctx context.Context, client splcommon.ControllerClient,
 			// Disable maintenance mode
 			err = SetClusterMaintenanceMode(ctx, client, cr, false, cmPodName, podExecClient)
 			if err != nil {
-				eventPublisher.Warning(ctx, "SetClusterMaintenanceMode", fmt.Sprintf("set cluster maintainance mode failed %s", err.Error()))
+				eventPublisher.Warning(ctx, MaintenanceModeFailed, fmt.Sprintf("set cluster maintainance mode failed %s", err.Error()))
+				return result, err
+			}
+		}
+
+		if len(cr.Spec.ClusterMasterRef.Name) > 0 {
+			cmPodName := fmt.Sprintf("splunk-%s-cluster-master-%s", cr.Spec.ClusterMasterRef.Name, "0")
+			err = reconcileRequestedMaintenanceMode(ctx, client, cr, cmPodName, splutil.GetPodExecClient(client, cr, cmPodName))
+			if err != nil {
+				eventPublisher.Warning(ctx, MaintenanceModeFailed, fmt.Sprintf("set requested cluster maintenance mode failed %s", err.Error()))
 				return result, err
 			}
 		}
@@ -520,7 +587,7 @@ func ApplyIndexerCluster(ctx context.Context, client splcommon.ControllerClient,
 		namespacedName = types.NamespacedName{Namespace: cr.GetNamespace(), Name: GetSplunkStatefulsetName(SplunkClusterMaster, cr.Spec.ClusterMasterRef.Name)}
 		err = splctrl.SetStatefulSetOwnerRef(ctx, client, cr, namespacedName)
 		if err != nil {
-			eventPublisher.Warning(ctx, "SetStatefulSetOwnerRef", fmt.Sprintf("set stateful set owner reference failed %s", err.Error()))
+			eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("set stateful set owner reference failed %s", err.Error()))
 			result.Requeue = true
 			return result, err
 		}
@@ -545,6 +612,9 @@ type indexerClusterPodManager struct {
 	cr              *enterpriseApi.IndexerCluster
 	secrets         *corev1.Secret
 	newSplunkClient func(managementURI, username, password string) *splclient.SplunkClient
+
+	// instance type naming the resources of the referenced ClusterManager
+	clusterManagerType InstanceType
 }
 
 // newIndexerClusterPodManager function to create pod manager this is added to write unit test case
@@ -585,17 +655,46 @@ func SetClusterMaintenanceMode(ctx context.Context, c splcommon.ControllerClient
 	}
 
 	// Set cluster manager maintenance mode
+	eventPublisher, _ := newK8EventPublisher(cr)
 	if enable {
 		cr.Status.MaintenanceMode = true
+		eventPublisher.Normal(ctx, MaintenanceModeEnabled, "enabled the maintenance mode of the indexer cluster")
 	} else {
 		cr.Status.MaintenanceMode = false
+		eventPublisher.Normal(ctx, MaintenanceModeDisabled, "disabled the maintenance mode of the indexer cluster")
 	}
 
 	return nil
 }
 
+// reconcileRequestedMaintenanceMode turns the maintenance mode of the indexer cluster on while the maintenance mode
+// annotation is set, and off once the annotation is removed. Maintenance mode turned on by other means is left as is
+func reconcileRequestedMaintenanceMode(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApi.IndexerCluster, cmPodName string, podExecClient splutil.PodExecClientImpl) error {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("reconcileRequestedMaintenanceMode").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	_, requested := cr.GetAnnotations()[enterpriseApi.IndexerClusterMaintenanceModeAnnotation]
+	switch {
+	case requested && !cr.Status.MaintenanceMode:
+		scopedLog.Info("Enabling requested maintenance mode")
+		if err := SetClusterMaintenanceMode(ctx, c, cr, true, cmPodName, podExecClient); err != nil {
+			return err
+		}
+	case !requested && cr.Status.MaintenanceModeRequested && cr.Status.MaintenanceMode:
+		scopedLog.Info("Disabling requested maintenance mode")
+		if err := SetClusterMaintenanceMode(ctx, c, cr, false, cmPodName, podExecClient); err != nil {
+			return err
+		}
+	}
+	cr.Status.MaintenanceModeRequested = requested
+	return nil
+}
+
 // ApplyIdxcSecret checks if any of the indexer's have a different idxc_secret from namespace scoped secret and changes it
 func ApplyIdxcSecret(ctx context.Context, mgr *indexerClusterPodManager, replicas int32, podExecClient splutil.PodExecClientImpl) error {
+	ctx, span := tracing.Start(ctx, "ApplyIdxcSecret")
+	defer span.End()
+
 	var indIdxcSecret string
 	// Get namespace scoped secret
 	namespaceSecret, err := splutil.ApplyNamespaceScopedSecretObject(ctx, mgr.c, mgr.cr.GetNamespace())
@@ -618,6 +717,7 @@ func ApplyIdxcSecret(ctx context.Context, mgr *indexerClusterPodManager, replica
 	}
 
 	scopedLog.Info("Namespaced scoped secret revision has changed")
+	eventPublisher, _ := newK8EventPublisher(mgr.cr)
 
 	// Retrieve idxc_secret password from secret data
 	nsIdxcSecret := string(namespaceSecret.Data[splcommon.IdxcSecret])
@@ -650,7 +750,7 @@ func ApplyIdxcSecret(ctx context.Context, mgr *indexerClusterPodManager, replica
 				var cmPodName string
 				if len(mgr.cr.Spec.ClusterManagerRef.Name) > 0 {
 					managerIdxcName = mgr.cr.Spec.ClusterManagerRef.Name
-					cmPodName = fmt.Sprintf("splunk-%s-cluster-manager-%s", managerIdxcName, "0")
+					cmPodName = fmt.Sprintf("splunk-%s-%s-%s", managerIdxcName, mgr.getClusterManagerType(), "0")
 				} else if len(mgr.cr.Spec.ClusterMasterRef.Name) > 0 {
 					managerIdxcName = mgr.cr.Spec.ClusterMasterRef.Name
 					cmPodName = fmt.Sprintf("splunk-%s-cluster-master-%s", managerIdxcName, "0")
@@ -676,18 +776,20 @@ func ApplyIdxcSecret(ctx context.Context, mgr *indexerClusterPodManager, replica
 			idxcClient := mgr.getClient(ctx, i)
 
 			// Change idxc secret key
-			err = idxcClient.SetIdxcSecret(nsIdxcSecret)
+			err = idxcClient.SetIdxcSecret(ctx, nsIdxcSecret)
+			audit.Log(ctx, audit.SecretChanged, indexerPodName, "idxc secret changed in the namespace scoped secret", err)
 			if err != nil {
 				return err
 			}
 			scopedLog.Info("Changed idxc secret")
 
 			// Restart splunk instance on pod
-			err = idxcClient.RestartSplunk()
+			err = idxcClient.RestartSplunk(ctx)
 			if err != nil {
 				return err
 			}
 			scopedLog.Info("Restarted splunk")
+			eventPublisher.Normal(ctx, SecretRotated, fmt.Sprintf("changed the idxc secret of pod %s", indexerPodName))
 
 			// Keep a track of all the secrets on pods to change their idxc secret below
 			mgr.cr.Status.IdxcPasswordChangedSecrets[podSecret.GetName()] = true
@@ -727,6 +829,7 @@ func ApplyIdxcSecret(ctx context.Context, mgr *indexerClusterPodManager, replica
 				podSecret.Data["default.yml"] = splunkReadableData["default.yml"]
 
 				_, err = splctrl.ApplySecret(ctx, mgr.c, podSecret)
+				resetPodCredentials(mgr.cr.GetNamespace())
 				if err != nil {
 					return err
 				}
@@ -752,10 +855,13 @@ func (mgr *indexerClusterPodManager) Update(ctx context.Context, c splcommon.Con
 	}
 	// update statefulset, if necessary
 	if mgr.cr.Status.ClusterManagerPhase == enterpriseApi.PhaseReady || mgr.cr.Status.ClusterMasterPhase == enterpriseApi.PhaseReady {
-		_, err = splctrl.ApplyStatefulSet(ctx, mgr.c, statefulSet)
+		_, err = splctrl.ApplyStatefulSetWithVolumeExpansion(ctx, mgr.c, statefulSet, &mgr.cr.Status.VolumeExpansion)
 		if err != nil {
 			return enterpriseApi.PhaseError, err
 		}
+		if splctrl.IsVolumeExpansionInProgress(&mgr.cr.Status.VolumeExpansion) {
+			return enterpriseApi.PhaseUpdating, nil
+		}
 	} else {
 		mgr.log.Info("Cluster Manager is not ready yet", "reason ", err)
 	}
@@ -793,7 +899,7 @@ func (mgr *indexerClusterPodManager) PrepareScaleDown(ctx context.Context, n int
 
 	// next, remove the peer
 	c := mgr.getClusterManagerClient(ctx)
-	return true, c.RemoveIndexerClusterPeer(mgr.cr.Status.Peers[n].ID)
+	return true, c.RemoveIndexerClusterPeer(ctx, mgr.cr.Status.Peers[n].ID)
 }
 
 // PrepareRecycle for indexerClusterPodManager prepares indexer pod to be recycled for updates; it returns true when ready
@@ -812,11 +918,12 @@ func (mgr *indexerClusterPodManager) FinishRecycle(ctx context.Context, n int32)
 // decommission for indexerClusterPodManager decommissions an indexer pod; it returns true when ready
 func (mgr *indexerClusterPodManager) decommission(ctx context.Context, n int32, enforceCounts bool) (bool, error) {
 	peerName := GetSplunkStatefulsetPodName(SplunkIndexer, mgr.cr.GetName(), n)
+	eventPublisher, _ := newK8EventPublisher(mgr.cr)
 
 	switch mgr.cr.Status.Peers[n].Status {
 	case "Up":
 		podExecClient := splutil.GetPodExecClient(mgr.c, mgr.cr, getApplicablePodNameForK8Probes(mgr.cr, n))
-		err := setProbeLevelOnSplunkPod(ctx, podExecClient, livenessProbeLevelOne)
+		err := setProbeLevelOnSplunkPod(ctx, mgr.c, mgr.cr, podExecClient, livenessProbeLevelOne)
 		if err != nil {
 			// Don't return error here. We may be reconciling several times, and the actual Pod status is down, but
 			// not yet reflecting on the Cluster Master, in which case, the podExec fails, though the decommission is
@@ -826,7 +933,14 @@ func (mgr *indexerClusterPodManager) decommission(ctx context.Context, n int32,
 
 		mgr.log.Info("Decommissioning indexer cluster peer", "peerName", peerName, "enforceCounts", enforceCounts)
 		c := mgr.getClient(ctx, n)
-		return false, c.DecommissionIndexerClusterPeer(enforceCounts)
+		err = c.DecommissionIndexerClusterPeer(ctx, enforceCounts)
+		audit.Log(ctx, audit.PeerDecommissioned, peerName, fmt.Sprintf("scale down, enforceCounts=%t", enforceCounts), err)
+		if err != nil {
+			eventPublisher.Warning(ctx, DecommissionFailed, fmt.Sprintf("decommission of peer %s failed %s", peerName, err.Error()))
+			return false, err
+		}
+		eventPublisher.Normal(ctx, DecommissionStarted, fmt.Sprintf("decommissioning peer %s, enforceCounts=%t", peerName, enforceCounts))
+		return false, nil
 
 	case "Decommissioning":
 		mgr.log.Info("Waiting for decommission to complete", "peerName", peerName)
@@ -838,10 +952,12 @@ func (mgr *indexerClusterPodManager) decommission(ctx context.Context, n int32,
 
 	case "GracefulShutdown":
 		mgr.log.Info("Decommission complete", "peerName", peerName, "Status", mgr.cr.Status.Peers[n].Status)
+		eventPublisher.Normal(ctx, DecommissionCompleted, fmt.Sprintf("decommissioned peer %s", peerName))
 		return true, nil
 
 	case "Down":
 		mgr.log.Info("Decommission complete", "peerName", peerName, "Status", mgr.cr.Status.Peers[n].Status)
+		eventPublisher.Normal(ctx, DecommissionCompleted, fmt.Sprintf("decommissioned peer %s", peerName))
 		return true, nil
 
 	case "": // this can happen after the peer has been removed from the indexer cluster
@@ -866,7 +982,7 @@ func (mgr *indexerClusterPodManager) getClient(ctx context.Context, n int32) *sp
 		fmt.Sprintf("%s.%s", memberName, GetSplunkServiceName(SplunkIndexer, mgr.cr.GetName(), true)))
 
 	// Retrieve admin password from Pod
-	adminPwd, err := splutil.GetSpecificSecretTokenFromPod(ctx, mgr.c, memberName, mgr.cr.GetNamespace(), "password")
+	adminPwd, err := getPodAdminPassword(ctx, mgr.c, mgr.cr.GetNamespace(), memberName, mgr.cr.Status.NamespaceSecretResourceVersion)
 	if err != nil {
 		scopedLog.Error(err, "Couldn't retrieve the admin password from pod")
 	}
@@ -874,6 +990,14 @@ func (mgr *indexerClusterPodManager) getClient(ctx context.Context, n int32) *sp
 	return mgr.newSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", adminPwd)
 }
 
+// getClusterManagerType returns the instance type naming the resources of the ClusterManager of the indexer cluster
+func (mgr *indexerClusterPodManager) getClusterManagerType() InstanceType {
+	if mgr.clusterManagerType == "" {
+		return SplunkClusterManager
+	}
+	return mgr.clusterManagerType
+}
+
 // getClusterManagerClient for indexerClusterPodManager returns a SplunkClient for cluster manager
 func (mgr *indexerClusterPodManager) getClusterManagerClient(ctx context.Context) *splclient.SplunkClient {
 	reqLogger := log.FromContext(ctx)
@@ -884,7 +1008,7 @@ func (mgr *indexerClusterPodManager) getClusterManagerClient(ctx context.Context
 	var cm InstanceType
 	if len(mgr.cr.Spec.ClusterManagerRef.Name) > 0 {
 		managerIdxcName = mgr.cr.Spec.ClusterManagerRef.Name
-		cm = SplunkClusterManager
+		cm = mgr.getClusterManagerType()
 	} else if len(mgr.cr.Spec.ClusterMasterRef.Name) > 0 {
 		managerIdxcName = mgr.cr.Spec.ClusterMasterRef.Name
 		cm = SplunkClusterMaster
@@ -923,7 +1047,7 @@ func (mgr *indexerClusterPodManager) verifyRFPeers(ctx context.Context, c splcom
 		mgr.c = c
 	}
 	cm := mgr.getClusterManagerClient(ctx)
-	clusterInfo, err := cm.GetClusterInfo(false)
+	clusterInfo, err := cm.GetClusterInfo(ctx, false)
 	if err != nil {
 		return fmt.Errorf("could not get cluster info from cluster manager")
 	}
@@ -956,7 +1080,7 @@ func (mgr *indexerClusterPodManager) updateStatus(ctx context.Context, statefulS
 
 	// get indexer cluster info from cluster manager if it's ready
 	c := mgr.getClusterManagerClient(ctx)
-	clusterInfo, err := c.GetClusterManagerInfo()
+	clusterInfo, err := c.GetClusterManagerInfo(ctx)
 	if err != nil {
 		return err
 	}
@@ -966,23 +1090,45 @@ func (mgr *indexerClusterPodManager) updateStatus(ctx context.Context, statefulS
 	mgr.cr.Status.MaintenanceMode = clusterInfo.MaintenanceMode
 
 	// get peer information from cluster manager
-	peers, err := c.GetClusterManagerPeers()
+	peers, err := c.GetClusterManagerPeers(ctx)
 	if err != nil {
 		return err
 	}
+
+	// the health checks are not available on all Splunk versions, and are not required to manage the peers
+	health, err := c.GetClusterManagerHealth(ctx)
+	if err != nil {
+		mgr.log.Info("Unable to get the health of the indexer cluster", "error", err.Error())
+		mgr.cr.Status.ReplicationFactorMet = false
+		mgr.cr.Status.SearchFactorMet = false
+	} else {
+		mgr.cr.Status.ReplicationFactorMet = health.ReplicationFactorMet == "1" && health.SiteReplicationFactorMet != "0"
+		mgr.cr.Status.SearchFactorMet = health.SearchFactorMet == "1" && health.SiteSearchFactorMet != "0"
+	}
+	hasReadinessGate := hasClusterMemberReadinessGate(&statefulSet.Spec.Template.Spec)
 	for n := int32(0); n < statefulSet.Status.Replicas; n++ {
 		peerName := GetSplunkStatefulsetPodName(SplunkIndexer, mgr.cr.GetName(), n)
 		peerStatus := enterpriseApi.IndexerClusterMemberStatus{Name: peerName}
 		peerInfo, ok := peers[peerName]
+		reason, message := "PeerNotKnown", "peer is not known by the cluster manager"
 		if ok {
 			peerStatus.ID = peerInfo.ID
 			peerStatus.Status = peerInfo.Status
 			peerStatus.ActiveBundleID = peerInfo.ActiveBundleID
 			peerStatus.BucketCount = peerInfo.BucketCount
 			peerStatus.Searchable = peerInfo.Searchable
+			reason, message = "PeerStatus", fmt.Sprintf("peer status is %s", peerInfo.Status)
 		} else {
 			mgr.log.Info("Peer is not known by cluster manager", "peerName", peerName)
 		}
+
+		// the peer is ready to serve once it is up in the cluster
+		if hasReadinessGate {
+			err = updateClusterMemberReadyCondition(ctx, mgr.c, mgr.cr.GetNamespace(), peerName, ok && peerInfo.Status == "Up", reason, message)
+			if err != nil {
+				mgr.log.Error(err, "Unable to update the cluster member ready condition", "peerName", peerName)
+			}
+		}
 		if n < int32(len(mgr.cr.Status.Peers)) {
 			mgr.cr.Status.Peers[n] = peerStatus
 		} else {
@@ -1006,7 +1152,15 @@ func getIndexerStatefulSet(ctx context.Context, client splcommon.ControllerClien
 	// 1. Introduce the new env variables in the function getIndexerExtraEnv
 	// 2. Avoid SPLUNK_INDEXER_URL in getIndexerExtraEnv for idxc CR
 	// 3. Re-introduce the call to getIndexerExtraEnv here.
-	return getSplunkStatefulSet(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, cr.Spec.Replicas, make([]corev1.EnvVar, 0))
+	ss, err := getSplunkStatefulSet(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, cr.Spec.Replicas, make([]corev1.EnvVar, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the operator managed HEC inputs
+	addHecConfigToTemplate(ctx, client, &ss.Spec.Template, cr, &cr.Spec.Hec, SplunkIndexer)
+
+	return ss, nil
 }
 
 // validateIndexerClusterSpec checks validity and makes default updates to a IndexerClusterSpec, and returns error if something is wrong.
@@ -1026,6 +1180,12 @@ func validateIndexerClusterSpec(ctx context.Context, c splcommon.ControllerClien
 		len(cr.Spec.ClusterMasterRef.Namespace) > 0 && cr.Spec.ClusterMasterRef.Namespace != cr.GetNamespace() {
 		return fmt.Errorf("multisite cluster does not support cluster manager to be located in a different namespace")
 	}
+
+	err := validateHecSpec(&cr.Spec.Hec)
+	if err != nil {
+		return err
+	}
+
 	return validateCommonSplunkSpec(ctx, c, &cr.Spec.CommonSplunkSpec, cr)
 }
 
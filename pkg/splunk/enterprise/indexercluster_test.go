@@ -72,6 +72,7 @@ func TestApplyIndexerCluster(t *testing.T) {
 		{MetaName: "*v4.ClusterManager-test-manager1"},
 		{MetaName: "*v1.Service-test-splunk-stack1-indexer-headless"},
 		{MetaName: "*v1.Service-test-splunk-stack1-indexer-service"},
+		{MetaName: "*v1.Secret-test-splunk-stack1-indexer-hec-defaults"},
 		{MetaName: "*v1.StatefulSet-test-splunk-stack1-indexer"},
 		{MetaName: "*v1.ConfigMap-test-splunk-test-probe-configmap"},
 		{MetaName: "*v1.ConfigMap-test-splunk-test-probe-configmap"},
@@ -87,6 +88,7 @@ func TestApplyIndexerCluster(t *testing.T) {
 		{MetaName: "*v4.ClusterManager-test-manager1"},
 		{MetaName: "*v1.Service-test-splunk-stack1-indexer-headless"},
 		{MetaName: "*v1.Service-test-splunk-stack1-indexer-service"},
+		{MetaName: "*v1.Secret-test-splunk-stack1-indexer-hec-defaults"},
 		{MetaName: "*v1.StatefulSet-test-splunk-stack1-indexer"},
 		{MetaName: "*v1.ConfigMap-test-splunk-test-probe-configmap"},
 		{MetaName: "*v1.Secret-test-splunk-test-secret"},
@@ -111,7 +113,7 @@ func TestApplyIndexerCluster(t *testing.T) {
 		{ListOpts: listOpts},
 		{ListOpts: listOpts1},
 	}
-	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[0], funcCalls[4], funcCalls[5], funcCalls[8], funcCalls[10]}, "Update": {funcCalls[0]}, "List": {listmockCall[0], listmockCall[1]}}
+	createCalls := map[string][]spltest.MockFuncCall{"Get": funcCalls, "Create": {funcCalls[0], funcCalls[4], funcCalls[5], funcCalls[9], funcCalls[11]}, "Update": {funcCalls[0]}, "List": {listmockCall[0], listmockCall[1]}}
 	updateCalls := map[string][]spltest.MockFuncCall{"Get": updateFuncCalls, "List": {listmockCall[0], listmockCall[1]}}
 
 	current := enterpriseApi.IndexerCluster{
@@ -598,6 +600,13 @@ func TestIndexerClusterPodManager(t *testing.T) {
 			Err:    nil,
 			Body:   splcommon.TestIndexerClusterPodManagerPeer,
 		},
+		{
+			Method: "GET",
+			URL:    "https://splunk-manager1-cluster-manager-service.test.svc.cluster.local:8089/services/cluster/manager/health?count=0&output_mode=json",
+			Status: 200,
+			Err:    nil,
+			Body:   `{"entry":[{"name":"manager","content":{"replication_factor_met":"1","search_factor_met":"1"}}]}`,
+		},
 	}
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -635,6 +644,7 @@ func TestIndexerClusterPodManager(t *testing.T) {
 		{MetaName: "*v1.Pod-test-splunk-stack1-0"},
 		{MetaName: "*v1.Pod-test-splunk-stack1-indexer-0"},
 		{MetaName: "*v1.Pod-test-splunk-stack1-indexer-0"},
+		{MetaName: "*v1.Pod-test-splunk-stack1-indexer-0"},
 	}
 	wantDecomPodCalls := map[string][]spltest.MockFuncCall{"Get": decommisonFuncCalls, "Create": {funcCalls[1]}}
 	indexerClusterPodManagerUpdateTester(t, method, mockHandlers, 1, enterpriseApi.PhaseUpdating, statefulSet, wantDecomPodCalls, nil, statefulSet, pod)
@@ -647,7 +657,7 @@ func TestIndexerClusterPodManager(t *testing.T) {
 		{MetaName: "*v1.Pod-test-splunk-manager1-cluster-manager-0"},
 		{MetaName: "*v1.Pod-test-splunk-stack1-0"},
 	}
-	mockHandlers = []spltest.MockHTTPHandler{mockHandlers[0], mockHandlers[1]}
+	mockHandlers = []spltest.MockHTTPHandler{mockHandlers[0], mockHandlers[1], mockHandlers[2]}
 	mockHandlers[1].Body = strings.Replace(mockHandlers[1].Body, `"status":"Up"`, `"status":"ReassigningPrimaries"`, 1)
 	method = "indexerClusterPodManager.Update(ReassigningPrimaries)"
 	wantReasCalls := map[string][]spltest.MockFuncCall{"Get": reassigningFuncCalls, "Create": {funcCalls[1]}}
@@ -828,6 +838,81 @@ func TestSetClusterMaintenanceMode(t *testing.T) {
 	mockPodExecClient.CheckPodExecCommands(t, "SetClusterMaintenanceMode")
 }
 
+func TestReconcileRequestedMaintenanceMode(t *testing.T) {
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "splunk-stack1-cluster-manager-0",
+			Namespace: "test",
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					VolumeMounts: []corev1.VolumeMount{{MountPath: "/mnt/splunk-secrets", Name: "mnt-splunk-secrets"}},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name:         "mnt-splunk-secrets",
+					VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "stack1-secrets"}},
+				},
+			},
+		},
+	}
+	secrets := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1-secrets", Namespace: "test"},
+		Data:       map[string][]byte{"password": []byte("123")},
+	}
+	c.AddObjects([]client.Object{pod, secrets})
+
+	cr := enterpriseApi.IndexerCluster{
+		TypeMeta:   metav1.TypeMeta{Kind: "IndexerCluster"},
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	cr.Spec.ClusterManagerRef.Name = cr.GetName()
+
+	reconcile := func(annotated bool, wantCmds ...string) {
+		t.Helper()
+		cr.Annotations = nil
+		if annotated {
+			cr.Annotations = map[string]string{enterpriseApi.IndexerClusterMaintenanceModeAnnotation: ""}
+		}
+		mockPodExecClient := &spltest.MockPodExecClient{}
+		for _, cmd := range wantCmds {
+			mockPodExecClient.AddMockPodExecReturnContext(ctx, cmd, &spltest.MockPodExecReturnContext{})
+		}
+		err := reconcileRequestedMaintenanceMode(ctx, c, &cr, pod.GetName(), mockPodExecClient)
+		if err != nil {
+			t.Errorf("reconcileRequestedMaintenanceMode() returned error: %v", err)
+		}
+		mockPodExecClient.CheckPodExecCommands(t, "reconcileRequestedMaintenanceMode")
+		if cr.Status.MaintenanceModeRequested != annotated {
+			t.Errorf("MaintenanceModeRequested = %t; want %t", cr.Status.MaintenanceModeRequested, annotated)
+		}
+	}
+
+	// maintenance mode turned on by other means is left on
+	cr.Status.MaintenanceMode = true
+	reconcile(false)
+	if !cr.Status.MaintenanceMode {
+		t.Errorf("maintenance mode not requested was turned off")
+	}
+
+	cr.Status.MaintenanceMode = false
+	reconcile(true, "splunk enable maintenance-mode")
+	if !cr.Status.MaintenanceMode {
+		t.Errorf("requested maintenance mode not turned on")
+	}
+	reconcile(true)
+
+	reconcile(false, "splunk disable maintenance-mode")
+	if cr.Status.MaintenanceMode {
+		t.Errorf("maintenance mode not turned off once the annotation was removed")
+	}
+	reconcile(false)
+}
+
 func TestApplyIdxcSecret(t *testing.T) {
 	method := "ApplyIdxcSecret"
 	scopedLog := logt.WithName(method)
@@ -18,10 +18,16 @@ package enterprise
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -31,12 +37,20 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/splunk/splunk-operator/pkg/splunk/audit"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
 )
 
+const (
+	// interval at which the license usage of a ready license manager is refreshed
+	licenseUsagePollInterval = time.Second * 60
+)
+
 // ApplyLicenseManager reconciles the state for the Splunk Enterprise license manager.
 func ApplyLicenseManager(ctx context.Context, client splcommon.ControllerClient, cr *enterpriseApi.LicenseManager) (reconcile.Result, error) {
+	ctx, span := tracing.Start(ctx, "ApplyLicenseManager")
+	defer span.End()
 
 	// unless modified, reconcile for this object will be requeued after 5 seconds
 	result := reconcile.Result{
@@ -45,7 +59,9 @@ func ApplyLicenseManager(ctx context.Context, client splcommon.ControllerClient,
 	}
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("ApplyLicenseManager")
-	eventPublisher, _ := newK8EventPublisher(client, cr)
+	eventPublisher, _ := newK8EventPublisher(cr)
+	defer eventPublisher.publishPhaseChange(ctx, cr.Status.Phase, &cr.Status.Phase)
+	ctx = audit.NewContext(ctx, cr, &cr.Status.AuditTrail)
 
 	// validate and updates defaults for CR
 	err := validateLicenseManagerSpec(ctx, client, cr)
@@ -66,7 +82,7 @@ func ApplyLicenseManager(ctx context.Context, client splcommon.ControllerClient,
 	if len(cr.Spec.AppFrameworkConfig.AppSources) != 0 {
 		err := initAndCheckAppInfoStatus(ctx, client, cr, &cr.Spec.AppFrameworkConfig, &cr.Status.AppContext)
 		if err != nil {
-			eventPublisher.Warning(ctx, "initAndCheckAppInfoStatus", fmt.Sprintf("init and check app info status failed %s", err.Error()))
+			eventPublisher.Warning(ctx, AppRepoCheckFailed, fmt.Sprintf("init and check app info status failed %s", err.Error()))
 			cr.Status.AppContext.IsDeploymentInProgress = false
 			return result, err
 		}
@@ -79,10 +95,10 @@ func ApplyLicenseManager(ctx context.Context, client splcommon.ControllerClient,
 	defer updateCRStatus(ctx, client, cr)
 
 	// create or update general config resources
-	_, err = ApplySplunkConfig(ctx, client, cr, cr.Spec.CommonSplunkSpec, SplunkLicenseManager)
+	namespaceScopedSecret, err := ApplySplunkConfig(ctx, client, cr, cr.Spec.CommonSplunkSpec, SplunkLicenseManager)
 	if err != nil {
 		scopedLog.Error(err, "create or update general config failed", "error", err.Error())
-		eventPublisher.Warning(ctx, "ApplySplunkConfig", fmt.Sprintf("create or update general config failed with error %s", err.Error()))
+		eventPublisher.Warning(ctx, ConfigApplyFailed, fmt.Sprintf("create or update general config failed with error %s", err.Error()))
 		return result, err
 	}
 
@@ -113,7 +129,7 @@ func ApplyLicenseManager(ctx context.Context, client splcommon.ControllerClient,
 			result.Requeue = false
 		}
 		if err != nil {
-			eventPublisher.Warning(ctx, "Delete", fmt.Sprintf("delete custom resource failed %s", err.Error()))
+			eventPublisher.Warning(ctx, DeleteFailed, fmt.Sprintf("delete custom resource failed %s", err.Error()))
 		}
 		return result, err
 	}
@@ -124,6 +140,18 @@ func ApplyLicenseManager(ctx context.Context, client splcommon.ControllerClient,
 		return result, err
 	}
 
+	// create or update the services of each type of traffic and their routes
+	err = ApplySplunkTrafficServices(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkLicenseManager, false)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the certificates of the ports and the secret mounting them
+	err = ApplySplunkTLS(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkLicenseManager)
+	if err != nil {
+		return result, err
+	}
+
 	// create or update statefulset
 	statefulSet, err := getLicenseManagerStatefulSet(ctx, client, cr)
 	if err != nil {
@@ -170,8 +198,21 @@ func ApplyLicenseManager(ctx context.Context, client splcommon.ControllerClient,
 			cr.Status.TelAppInstalled = true
 		}
 
+		// an unreachable licenser shouldn't hold back the app framework, so the error is only reported
+		err = reconcileLicensePools(ctx, client, cr, namespaceScopedSecret)
+		if err != nil {
+			scopedLog.Error(err, "Unable to reconcile the license pools of the license manager")
+			eventPublisher.Warning(ctx, LicensePoolsFailed, fmt.Sprintf("reconcile license pools failed %s", err.Error()))
+		}
+
 		finalResult := handleAppFrameworkActivity(ctx, client, cr, &cr.Status.AppContext, &cr.Spec.AppFrameworkConfig)
 		result = *finalResult
+
+		// keep the license usage current while there is no app framework activity
+		if !result.Requeue {
+			result.Requeue = true
+			result.RequeueAfter = licenseUsagePollInterval
+		}
 	}
 	// RequeueAfter if greater than 0, tells the Controller to requeue the reconcile key after the Duration.
 	// Implies that Requeue is true, there is no need to set Requeue to true at the same time as RequeueAfter.
@@ -191,9 +232,84 @@ func getLicenseManagerStatefulSet(ctx context.Context, client splcommon.Controll
 	// Setup App framework staging volume for apps
 	setupAppsStagingVolume(ctx, client, cr, &ss.Spec.Template, &cr.Spec.AppFrameworkConfig)
 
+	// Mount the license files from secrets
+	err = addLicenseSecretsToTemplate(ctx, client, cr, &ss.Spec.Template)
+
 	return ss, err
 }
 
+// addLicenseSecretsToTemplate mounts the license files referenced by the license manager from secrets
+// and appends them to SPLUNK_LICENSE_URI. The secret revisions are tracked on the pod template, so
+// that the license manager is restarted with the new licenses when a secret is updated.
+func addLicenseSecretsToTemplate(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApi.LicenseManager, podTemplateSpec *corev1.PodTemplateSpec) error {
+	if len(cr.Spec.Licenses) == 0 {
+		return nil
+	}
+
+	var secretNames []string
+	secretKeys := make(map[string][]string)
+	for _, license := range cr.Spec.Licenses {
+		if _, ok := secretKeys[license.SecretName]; !ok {
+			secretNames = append(secretNames, license.SecretName)
+		}
+		secretKeys[license.SecretName] = append(secretKeys[license.SecretName], license.Key)
+	}
+
+	// Explicitly set the default value here so we can compare for changes correctly with current statefulset.
+	secretVolDefaultMode := int32(corev1.SecretVolumeSourceDefaultMode)
+	var licensePaths, secretRevs []string
+	for i, secretName := range secretNames {
+		secret, err := splutil.GetSecretByName(ctx, c, cr.GetNamespace(), cr.GetName(), secretName)
+		if err != nil {
+			return err
+		}
+
+		mountPath := filepath.Join(licenseSecretsMountPath, secretName)
+		var items []corev1.KeyToPath
+		for _, key := range secretKeys[secretName] {
+			if _, ok := secret.Data[key]; !ok {
+				return fmt.Errorf("key %s not found in license secret %s", key, secretName)
+			}
+			items = append(items, corev1.KeyToPath{Key: key, Path: key})
+			licensePaths = append(licensePaths, filepath.Join(mountPath, key))
+		}
+
+		addSplunkVolumeToTemplate(podTemplateSpec, fmt.Sprintf("mnt-splunk-license-%d", i), mountPath, corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  secretName,
+				Items:       items,
+				DefaultMode: &secretVolDefaultMode,
+			},
+		})
+		secretRevs = append(secretRevs, fmt.Sprintf("%s:%s", secretName, secret.GetResourceVersion()))
+	}
+
+	licenseURI := strings.Join(licensePaths, ",")
+	for idx := range podTemplateSpec.Spec.Containers {
+		container := &podTemplateSpec.Spec.Containers[idx]
+		if container.Name != splunkContainerName {
+			continue
+		}
+		found := false
+		for i := range container.Env {
+			if container.Env[i].Name == "SPLUNK_LICENSE_URI" {
+				container.Env[i].Value = fmt.Sprintf("%s,%s", container.Env[i].Value, licenseURI)
+				found = true
+			}
+		}
+		if !found {
+			container.Env = append(container.Env, corev1.EnvVar{Name: "SPLUNK_LICENSE_URI", Value: licenseURI})
+		}
+	}
+
+	if podTemplateSpec.ObjectMeta.Annotations == nil {
+		podTemplateSpec.ObjectMeta.Annotations = make(map[string]string)
+	}
+	podTemplateSpec.ObjectMeta.Annotations[licenseSecretsRev] = strings.Join(secretRevs, ",")
+
+	return nil
+}
+
 // validateLicenseManagerSpec checks validity and makes default updates to a LicenseManagerSpec, and returns error if something is wrong.
 func validateLicenseManagerSpec(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApi.LicenseManager) error {
 
@@ -204,9 +320,271 @@ func validateLicenseManagerSpec(ctx context.Context, c splcommon.ControllerClien
 		}
 	}
 
+	err := validateLicenseManagerLicenses(cr)
+	if err != nil {
+		return err
+	}
+
 	return validateCommonSplunkSpec(ctx, c, &cr.Spec.CommonSplunkSpec, cr)
 }
 
+// validateLicenseManagerLicenses checks validity of the license files and pools of a LicenseManagerSpec
+func validateLicenseManagerLicenses(cr *enterpriseApi.LicenseManager) error {
+	licenses := make(map[string]bool)
+	for _, license := range cr.Spec.Licenses {
+		if license.SecretName == "" || license.Key == "" {
+			return fmt.Errorf("license secretName and key must be specified")
+		}
+		id := fmt.Sprintf("%s/%s", license.SecretName, license.Key)
+		if licenses[id] {
+			return fmt.Errorf("duplicate license %s", id)
+		}
+		licenses[id] = true
+	}
+
+	pools := make(map[string]bool)
+	peers := make(map[string]string)
+	for _, pool := range cr.Spec.Pools {
+		if pool.Name == "" {
+			return fmt.Errorf("license pool name must be specified")
+		}
+		if pools[pool.Name] {
+			return fmt.Errorf("duplicate license pool %s", pool.Name)
+		}
+		pools[pool.Name] = true
+
+		if pool.Quota != nil && pool.Quota.Sign() <= 0 {
+			return fmt.Errorf("quota of license pool %s must be positive", pool.Name)
+		}
+
+		for _, peer := range pool.Peers {
+			if peer.Kind != "IndexerCluster" && peer.Kind != "Standalone" {
+				return fmt.Errorf("unsupported kind %s of license pool %s peer %s", peer.Kind, pool.Name, peer.Name)
+			}
+			if peer.Name == "" {
+				return fmt.Errorf("peer name of license pool %s must be specified", pool.Name)
+			}
+			// a license peer can only draw from one pool of a stack
+			id := fmt.Sprintf("%s/%s", peer.Kind, peer.Name)
+			if other, ok := peers[id]; ok {
+				return fmt.Errorf("%s is assigned to license pools %s and %s", id, other, pool.Name)
+			}
+			peers[id] = pool.Name
+		}
+	}
+
+	return nil
+}
+
+// newLicenseManagerClient returns a Splunk client for the license manager service
+var newLicenseManagerClient = func(cr *enterpriseApi.LicenseManager, secret *corev1.Secret) *splclient.SplunkClient {
+	fqdnName := splcommon.GetServiceFQDN(cr.GetNamespace(), GetSplunkServiceName(SplunkLicenseManager, cr.GetName(), false))
+	return splclient.NewSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", string(secret.Data["password"]))
+}
+
+// getLicensePoolQuota returns the quota of a license pool in the format expected by the licenser
+func getLicensePoolQuota(pool *enterpriseApi.LicensePoolSpec) string {
+	if pool.Quota == nil {
+		return "MAX"
+	}
+	return strconv.FormatInt(pool.Quota.Value(), 10)
+}
+
+// getLicensePoolPeers returns the sorted GUIDs of the license peers that are instances of the custom resources
+// assigned to a license pool. License peers are reported by their server name, which is the pod name.
+func getLicensePoolPeers(pool *enterpriseApi.LicensePoolSpec, licensePeers map[string]splclient.LicensePeerInfo) []string {
+	peers := []string{}
+	for _, ref := range pool.Peers {
+		instanceType := SplunkStandalone
+		if ref.Kind == "IndexerCluster" {
+			instanceType = SplunkIndexer
+		}
+		prefix := GetSplunkStatefulsetName(instanceType, ref.Name) + "-"
+		for guid, peer := range licensePeers {
+			if !strings.HasPrefix(peer.Label, prefix) {
+				continue
+			}
+			if _, err := strconv.Atoi(strings.TrimPrefix(peer.Label, prefix)); err == nil {
+				peers = append(peers, guid)
+			}
+		}
+	}
+	sort.Strings(peers)
+	return peers
+}
+
+// reconcileLicensePools creates, updates and removes the license pools managed by the license manager
+// and records the licenses, stacks and pools with their daily usage in the status
+func reconcileLicensePools(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApi.LicenseManager, secret *corev1.Secret) error {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("reconcileLicensePools").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	if secret == nil {
+		return fmt.Errorf("namespace scoped secret not found")
+	}
+
+	lmClient := newLicenseManagerClient(cr, secret)
+	stacks, err := lmClient.GetLicenserStacks(ctx)
+	if err != nil {
+		return err
+	}
+	pools, err := lmClient.GetLicenserPools(ctx)
+	if err != nil {
+		return err
+	}
+
+	// remove the pools previously created by the operator which are no longer desired first,
+	// since their peers may be assigned to other pools of the same stack
+	poolsChanged := false
+	desired := make(map[string]bool, len(cr.Spec.Pools))
+	for _, pool := range cr.Spec.Pools {
+		desired[pool.Name] = true
+	}
+	for _, pool := range cr.Status.Pools {
+		if desired[pool.Name] {
+			continue
+		}
+		if _, ok := pools[pool.Name]; ok {
+			scopedLog.Info("Removing license pool", "pool", pool.Name)
+			err = lmClient.DeleteLicenserPool(ctx, pool.Name)
+			if err != nil {
+				return err
+			}
+			poolsChanged = true
+		}
+	}
+
+	if len(cr.Spec.Pools) > 0 {
+		licensePeers, err := lmClient.GetLicenserPeers(ctx)
+		if err != nil {
+			return err
+		}
+
+		for i := range cr.Spec.Pools {
+			pool := &cr.Spec.Pools[i]
+			stackID := pool.StackID
+			if stackID == "" {
+				stackID = defaultLicenseStackID
+			}
+			quota := getLicensePoolQuota(pool)
+			wantQuota := stacks[stackID].Quota
+			if pool.Quota != nil {
+				wantQuota = pool.Quota.Value()
+			}
+			peers := getLicensePoolPeers(pool, licensePeers)
+
+			current, ok := pools[pool.Name]
+			if ok && current.StackID != stackID {
+				// the stack of a pool can't be edited, so the pool is recreated
+				scopedLog.Info("Recreating license pool on a different stack", "pool", pool.Name, "stack", stackID)
+				err = lmClient.DeleteLicenserPool(ctx, pool.Name)
+				if err != nil {
+					return err
+				}
+				ok = false
+			}
+
+			if !ok {
+				scopedLog.Info("Creating license pool", "pool", pool.Name, "stack", stackID, "quota", quota)
+				err = lmClient.CreateLicenserPool(ctx, pool.Name, stackID, quota, pool.Description, peers)
+				if err != nil {
+					return err
+				}
+				poolsChanged = true
+				continue
+			}
+
+			currentPeers := append([]string{}, current.Peers...)
+			sort.Strings(currentPeers)
+			if current.EffectiveQuota != wantQuota || current.Description != pool.Description || !reflect.DeepEqual(currentPeers, peers) {
+				scopedLog.Info("Updating license pool", "pool", pool.Name, "quota", quota)
+				err = lmClient.UpdateLicenserPool(ctx, pool.Name, quota, pool.Description, peers)
+				if err != nil {
+					return err
+				}
+				poolsChanged = true
+			}
+		}
+	}
+
+	if poolsChanged {
+		pools, err = lmClient.GetLicenserPools(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	licenses, err := lmClient.GetLicenserLicenses(ctx)
+	if err != nil {
+		return err
+	}
+	messages, err := lmClient.GetLicenserMessages(ctx)
+	if err != nil {
+		return err
+	}
+
+	setLicenseManagerStatus(cr, licenses, stacks, pools, messages)
+	return nil
+}
+
+// setLicenseManagerStatus records the licenses, stacks and managed pools reported by the licenser in the status
+func setLicenseManagerStatus(cr *enterpriseApi.LicenseManager, licenses map[string]splclient.LicenseInfo, stacks map[string]splclient.LicenseStackInfo, pools map[string]splclient.LicensePoolInfo, messages []splclient.LicenseMessageInfo) {
+	cr.Status.Licenses = nil
+	for _, license := range licenses {
+		cr.Status.Licenses = append(cr.Status.Licenses, enterpriseApi.LicenseInfo{
+			Label:          license.Label,
+			StackID:        license.StackID,
+			Type:           license.Type,
+			Status:         license.Status,
+			Quota:          license.Quota,
+			ExpirationTime: license.ExpirationTime,
+		})
+	}
+	sort.Slice(cr.Status.Licenses, func(i, j int) bool {
+		if cr.Status.Licenses[i].StackID != cr.Status.Licenses[j].StackID {
+			return cr.Status.Licenses[i].StackID < cr.Status.Licenses[j].StackID
+		}
+		return cr.Status.Licenses[i].ExpirationTime < cr.Status.Licenses[j].ExpirationTime
+	})
+
+	// the daily usage of a stack is the sum of the usage of its pools
+	usedBytes := make(map[string]int64)
+	for _, pool := range pools {
+		usedBytes[pool.StackID] += pool.UsedBytes
+	}
+	violations := make(map[string]int32)
+	for _, message := range messages {
+		violations[message.StackID]++
+	}
+	cr.Status.Stacks = nil
+	for name, stack := range stacks {
+		cr.Status.Stacks = append(cr.Status.Stacks, enterpriseApi.LicenseStackInfo{
+			Name:       name,
+			Quota:      stack.Quota,
+			UsedBytes:  usedBytes[name],
+			Violations: violations[name],
+		})
+	}
+	sort.Slice(cr.Status.Stacks, func(i, j int) bool {
+		return cr.Status.Stacks[i].Name < cr.Status.Stacks[j].Name
+	})
+
+	cr.Status.Pools = nil
+	for _, spec := range cr.Spec.Pools {
+		pool, ok := pools[spec.Name]
+		if !ok {
+			continue
+		}
+		cr.Status.Pools = append(cr.Status.Pools, enterpriseApi.LicensePoolInfo{
+			Name:      spec.Name,
+			StackID:   pool.StackID,
+			Quota:     pool.EffectiveQuota,
+			UsedBytes: pool.UsedBytes,
+			Peers:     int32(len(pool.Peers)),
+		})
+	}
+}
+
 // helper function to get the list of LicenseManager types in the current namespace
 func getLicenseManagerList(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, listOpts []client.ListOption) (enterpriseApi.LicenseManagerList, error) {
 	reqLogger := log.FromContext(ctx)
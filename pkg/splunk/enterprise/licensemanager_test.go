@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime/debug"
 	"testing"
 	"time"
@@ -29,6 +30,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -1193,3 +1195,237 @@ func TestLicenseManagerWithReadyState(t *testing.T) {
 		debug.PrintStack()
 	}
 }
+
+func TestValidateLicenseManagerLicenses(t *testing.T) {
+	quota := resource.MustParse("10G")
+	zero := resource.MustParse("0")
+	cr := enterpriseApi.LicenseManager{
+		Spec: enterpriseApi.LicenseManagerSpec{
+			Licenses: []enterpriseApi.LicenseSecretSpec{
+				{SecretName: "licenses", Key: "enterprise.lic"},
+				{SecretName: "licenses", Key: "itsi.lic"},
+			},
+			Pools: []enterpriseApi.LicensePoolSpec{
+				{Name: "idxc", Quota: &quota, Peers: []enterpriseApi.LicensePoolPeerRef{{Kind: "IndexerCluster", Name: "idxc"}}},
+				{Name: "standalone", Peers: []enterpriseApi.LicensePoolPeerRef{{Kind: "Standalone", Name: "s1"}}},
+			},
+		},
+	}
+	if err := validateLicenseManagerLicenses(&cr); err != nil {
+		t.Errorf("validateLicenseManagerLicenses() returned error %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(cr *enterpriseApi.LicenseManager)
+	}{
+		{"missing key", func(cr *enterpriseApi.LicenseManager) { cr.Spec.Licenses[0].Key = "" }},
+		{"duplicate license", func(cr *enterpriseApi.LicenseManager) { cr.Spec.Licenses[1].Key = "enterprise.lic" }},
+		{"missing pool name", func(cr *enterpriseApi.LicenseManager) { cr.Spec.Pools[0].Name = "" }},
+		{"duplicate pool", func(cr *enterpriseApi.LicenseManager) { cr.Spec.Pools[1].Name = "idxc" }},
+		{"zero quota", func(cr *enterpriseApi.LicenseManager) { cr.Spec.Pools[0].Quota = &zero }},
+		{"unsupported kind", func(cr *enterpriseApi.LicenseManager) { cr.Spec.Pools[0].Peers[0].Kind = "SearchHeadCluster" }},
+		{"missing peer name", func(cr *enterpriseApi.LicenseManager) { cr.Spec.Pools[0].Peers[0].Name = "" }},
+		{"peer in two pools", func(cr *enterpriseApi.LicenseManager) { cr.Spec.Pools[1].Peers[0] = cr.Spec.Pools[0].Peers[0] }},
+	}
+	for _, tt := range tests {
+		revised := cr.DeepCopy()
+		tt.modify(revised)
+		if err := validateLicenseManagerLicenses(revised); err == nil {
+			t.Errorf("validateLicenseManagerLicenses() should have returned error for %s", tt.name)
+		}
+	}
+}
+
+func TestAddLicenseSecretsToTemplate(t *testing.T) {
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	cr := enterpriseApi.LicenseManager{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+		Spec: enterpriseApi.LicenseManagerSpec{
+			Licenses: []enterpriseApi.LicenseSecretSpec{
+				{SecretName: "licenses", Key: "enterprise.lic"},
+				{SecretName: "licenses", Key: "itsi.lic"},
+				{SecretName: "other", Key: "other.lic"},
+			},
+		},
+	}
+	podTemplateSpec := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "splunk", Env: []corev1.EnvVar{{Name: "SPLUNK_LICENSE_URI", Value: "/mnt/splunk.lic"}}},
+			},
+		},
+	}
+
+	err := addLicenseSecretsToTemplate(ctx, c, &cr, &podTemplateSpec)
+	if err == nil {
+		t.Errorf("addLicenseSecretsToTemplate() should have returned error when the secrets don't exist")
+	}
+
+	licenses := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "licenses", Namespace: "test", ResourceVersion: "1"},
+		Data:       map[string][]byte{"enterprise.lic": []byte("lic1"), "itsi.lic": []byte("lic2")},
+	}
+	other := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test", ResourceVersion: "2"},
+		Data:       map[string][]byte{"wrong.lic": []byte("lic3")},
+	}
+	c.AddObject(licenses)
+	c.AddObject(other)
+	err = addLicenseSecretsToTemplate(ctx, c, &cr, podTemplateSpec.DeepCopy())
+	if err == nil {
+		t.Errorf("addLicenseSecretsToTemplate() should have returned error when a key doesn't exist")
+	}
+
+	other.Data["other.lic"] = []byte("lic3")
+	err = addLicenseSecretsToTemplate(ctx, c, &cr, &podTemplateSpec)
+	if err != nil {
+		t.Errorf("addLicenseSecretsToTemplate() returned error %v", err)
+	}
+
+	wantURI := "/mnt/splunk.lic,/mnt/splunk-licenses/licenses/enterprise.lic,/mnt/splunk-licenses/licenses/itsi.lic,/mnt/splunk-licenses/other/other.lic"
+	if got := podTemplateSpec.Spec.Containers[0].Env[0].Value; got != wantURI {
+		t.Errorf("SPLUNK_LICENSE_URI=%s; want %s", got, wantURI)
+	}
+	if len(podTemplateSpec.Spec.Volumes) != 2 || podTemplateSpec.Spec.Volumes[0].Secret.SecretName != "licenses" || len(podTemplateSpec.Spec.Volumes[0].Secret.Items) != 2 {
+		t.Errorf("addLicenseSecretsToTemplate() got unexpected volumes %v", podTemplateSpec.Spec.Volumes)
+	}
+	if len(podTemplateSpec.Spec.Containers[0].VolumeMounts) != 2 || podTemplateSpec.Spec.Containers[0].VolumeMounts[1].MountPath != "/mnt/splunk-licenses/other" {
+		t.Errorf("addLicenseSecretsToTemplate() got unexpected volume mounts %v", podTemplateSpec.Spec.Containers[0].VolumeMounts)
+	}
+	if got := podTemplateSpec.ObjectMeta.Annotations[licenseSecretsRev]; got != "licenses:1,other:2" {
+		t.Errorf("%s=%s; want %s", licenseSecretsRev, got, "licenses:1,other:2")
+	}
+}
+
+func TestGetLicensePoolPeers(t *testing.T) {
+	licensePeers := map[string]splclient.LicensePeerInfo{
+		"guid1": {Label: "splunk-idxc-indexer-0"},
+		"guid2": {Label: "splunk-idxc-indexer-1"},
+		"guid3": {Label: "splunk-idxc-indexer-extra-0"},
+		"guid4": {Label: "splunk-s1-standalone-0"},
+		"guid5": {Label: "splunk-idxc-cluster-manager-0"},
+	}
+	pool := enterpriseApi.LicensePoolSpec{
+		Name: "pool1",
+		Peers: []enterpriseApi.LicensePoolPeerRef{
+			{Kind: "IndexerCluster", Name: "idxc"},
+			{Kind: "Standalone", Name: "s1"},
+		},
+	}
+	got := getLicensePoolPeers(&pool, licensePeers)
+	want := []string{"guid1", "guid2", "guid4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getLicensePoolPeers()=%v; want %v", got, want)
+	}
+
+	if quota := getLicensePoolQuota(&pool); quota != "MAX" {
+		t.Errorf("getLicensePoolQuota()=%s; want MAX", quota)
+	}
+	quota := resource.MustParse("1Gi")
+	pool.Quota = &quota
+	if quota := getLicensePoolQuota(&pool); quota != "1073741824" {
+		t.Errorf("getLicensePoolQuota()=%s; want 1073741824", quota)
+	}
+}
+
+func TestReconcileLicensePools(t *testing.T) {
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	quota := resource.MustParse("1Gi")
+	cr := enterpriseApi.LicenseManager{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+		Spec: enterpriseApi.LicenseManagerSpec{
+			Pools: []enterpriseApi.LicensePoolSpec{
+				{Name: "idxc", Quota: &quota, Peers: []enterpriseApi.LicensePoolPeerRef{{Kind: "IndexerCluster", Name: "idxc"}}},
+				{Name: "standalone", Peers: []enterpriseApi.LicensePoolPeerRef{{Kind: "Standalone", Name: "s1"}}},
+				{Name: "new", Quota: &quota},
+			},
+		},
+		Status: enterpriseApi.LicenseManagerStatus{
+			Pools: []enterpriseApi.LicensePoolInfo{{Name: "old"}},
+		},
+	}
+	secret := &corev1.Secret{
+		Data: map[string][]byte{"password": []byte("lm-password")},
+	}
+
+	err := reconcileLicensePools(ctx, c, &cr, nil)
+	if err == nil {
+		t.Errorf("reconcileLicensePools() should have returned error without a secret")
+	}
+
+	lmURI := "https://splunk-stack1-license-manager-service.test.svc.cluster.local:8089"
+	mockSplunkClient := &spltest.MockHTTPClient{}
+	savedNewLicenseManagerClient := newLicenseManagerClient
+	defer func() { newLicenseManagerClient = savedNewLicenseManagerClient }()
+	newLicenseManagerClient = func(cr *enterpriseApi.LicenseManager, secret *corev1.Secret) *splclient.SplunkClient {
+		c := savedNewLicenseManagerClient(cr, secret)
+		c.Client = mockSplunkClient
+		return c
+	}
+
+	// the old pool is removed, the idxc pool is up to date, the standalone pool gets its peer and the new pool is created
+	getStacksURL := lmURI + "/services/licenser/stacks?count=0&output_mode=json"
+	getPoolsURL := lmURI + "/services/licenser/pools?count=0&output_mode=json"
+	getPeersURL := lmURI + "/services/licenser/peers?count=0&output_mode=json"
+	getLicensesURL := lmURI + "/services/licenser/licenses?count=0&output_mode=json"
+	getMessagesURL := lmURI + "/services/licenser/messages?count=0&output_mode=json"
+	stacks := `{"entry":[{"name":"enterprise","content":{"label":"Splunk Enterprise","quota":10737418240,"type":"enterprise"}}]}`
+	pools := `{"entry":[{"name":"old","content":{"stack_id":"enterprise","effective_quota":1024,"used_bytes":0,"peers":[]}},{"name":"idxc","content":{"stack_id":"enterprise","effective_quota":1073741824,"used_bytes":2048,"peers":["guid2","guid1"]}},{"name":"standalone","content":{"stack_id":"enterprise","effective_quota":10737418240,"used_bytes":4096,"peers":[]}}]}`
+	peers := `{"entry":[{"name":"guid1","content":{"label":"splunk-idxc-indexer-0"}},{"name":"guid2","content":{"label":"splunk-idxc-indexer-1"}},{"name":"guid3","content":{"label":"splunk-s1-standalone-0"}}]}`
+	licenses := `{"entry":[{"name":"guid","content":{"guid":"guid","label":"Splunk Enterprise","stack_id":"enterprise","type":"enterprise","status":"VALID","quota":10737418240,"expiration_time":1893456000}}]}`
+	messages := `{"entry":[{"name":"msg1","content":{"category":"pool_over_quota","severity":"WARN","stack_id":"enterprise","pool_id":"idxc"}}]}`
+	mockSplunkClient.AddHandlers([]spltest.MockHTTPHandler{
+		{Method: "GET", URL: getStacksURL, Status: 200, Body: stacks},
+		{Method: "GET", URL: getPoolsURL, Status: 200, Body: pools},
+		{Method: "DELETE", URL: lmURI + "/services/licenser/pools/old", Status: 200},
+		{Method: "GET", URL: getPeersURL, Status: 200, Body: peers},
+		{Method: "POST", URL: lmURI + "/services/licenser/pools/standalone", Status: 200},
+		{Method: "POST", URL: lmURI + "/services/licenser/pools", Status: 201},
+		{Method: "GET", URL: getPoolsURL, Status: 200, Body: pools},
+		{Method: "GET", URL: getLicensesURL, Status: 200, Body: licenses},
+		{Method: "GET", URL: getMessagesURL, Status: 200, Body: messages},
+	}...)
+
+	err = reconcileLicensePools(ctx, c, &cr, secret)
+	if err != nil {
+		t.Errorf("reconcileLicensePools() returned error %v", err)
+	}
+	mockSplunkClient.CheckRequests(t, "TestReconcileLicensePools")
+
+	wantLicenses := []enterpriseApi.LicenseInfo{
+		{Label: "Splunk Enterprise", StackID: "enterprise", Type: "enterprise", Status: "VALID", Quota: 10737418240, ExpirationTime: 1893456000},
+	}
+	if !reflect.DeepEqual(cr.Status.Licenses, wantLicenses) {
+		t.Errorf("Status.Licenses=%v; want %v", cr.Status.Licenses, wantLicenses)
+	}
+	wantStacks := []enterpriseApi.LicenseStackInfo{
+		{Name: "enterprise", Quota: 10737418240, UsedBytes: 6144, Violations: 1},
+	}
+	if !reflect.DeepEqual(cr.Status.Stacks, wantStacks) {
+		t.Errorf("Status.Stacks=%v; want %v", cr.Status.Stacks, wantStacks)
+	}
+	wantPools := []enterpriseApi.LicensePoolInfo{
+		{Name: "idxc", StackID: "enterprise", Quota: 1073741824, UsedBytes: 2048, Peers: 2},
+		{Name: "standalone", StackID: "enterprise", Quota: 10737418240, UsedBytes: 4096, Peers: 0},
+	}
+	if !reflect.DeepEqual(cr.Status.Pools, wantPools) {
+		t.Errorf("Status.Pools=%v; want %v", cr.Status.Pools, wantPools)
+	}
+
+	// licenser is unreachable
+	mockSplunkClient = &spltest.MockHTTPClient{}
+	mockSplunkClient.AddHandlers(spltest.MockHTTPHandler{Method: "GET", URL: getStacksURL, Status: 503})
+	err = reconcileLicensePools(ctx, c, &cr, secret)
+	if err == nil {
+		t.Errorf("reconcileLicensePools() should have returned error when the licenser is unreachable")
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// key of the operator-wide maintenance windows in their ConfigMap
+	maintenanceWindowsConfigMapKey = "maintenanceWindows"
+
+	// maintenance windows open on the minute, so a pending operation is retried every minute
+	maintenanceWindowRetryDelay = time.Minute
+)
+
+// MaintenanceWindowsConfigMap is the namespace/name of the ConfigMap with the operator-wide maintenance windows,
+// applied to the custom resources that do not set their own
+var MaintenanceWindowsConfigMap = ""
+
+// getMaintenanceWindows returns the maintenance windows in effect for a custom resource. None are in effect while
+// the override annotation is set
+func getMaintenanceWindows(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec) ([]enterpriseApi.MaintenanceWindow, error) {
+	if _, ok := cr.GetAnnotations()[enterpriseApi.MaintenanceOverrideAnnotation]; ok {
+		return nil, nil
+	}
+	if len(spec.MaintenanceWindows) > 0 || MaintenanceWindowsConfigMap == "" {
+		return spec.MaintenanceWindows, nil
+	}
+
+	parts := strings.SplitN(MaintenanceWindowsConfigMap, "/", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid maintenance windows ConfigMap %q, expected namespace/name", MaintenanceWindowsConfigMap)
+	}
+	configMap, err := splctrl.GetConfigMap(ctx, c, types.NamespacedName{Namespace: parts[0], Name: parts[1]})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var windows []enterpriseApi.MaintenanceWindow
+	err = yaml.Unmarshal([]byte(configMap.Data[maintenanceWindowsConfigMapKey]), &windows)
+	if err == nil {
+		err = splctrl.ValidateMaintenanceWindows(windows)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance windows in ConfigMap %s: %v", MaintenanceWindowsConfigMap, err)
+	}
+	return windows, nil
+}
+
+// setMaintenanceWindows sets the maintenance windows in effect for the disruptive updates of the pods of a StatefulSet
+func setMaintenanceWindows(statefulSet *appsv1.StatefulSet, windows []enterpriseApi.MaintenanceWindow) {
+	annotations := statefulSet.GetAnnotations()
+	if len(windows) == 0 {
+		delete(annotations, enterpriseApi.MaintenanceWindowsAnnotation)
+		statefulSet.SetAnnotations(annotations)
+		return
+	}
+
+	value, _ := json.Marshal(windows)
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[enterpriseApi.MaintenanceWindowsAnnotation] = string(value)
+	statefulSet.SetAnnotations(annotations)
+}
+
+// isInMaintenanceWindow returns true if disruptive operations of a custom resource are allowed now
+func isInMaintenanceWindow(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec) (bool, error) {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("isInMaintenanceWindow").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	windows, err := getMaintenanceWindows(ctx, c, cr, spec)
+	if err != nil {
+		return false, err
+	}
+	allowed, nextOpen, err := splctrl.GetMaintenanceWindowState(windows, time.Now())
+	if err != nil {
+		return false, err
+	}
+	if !allowed {
+		scopedLog.Info("Pending maintenance window", "nextWindow", nextOpen)
+	}
+	return allowed, nil
+}
+
+// getCommonSplunkSpec returns the CommonSplunkSpec of a custom resource
+func getCommonSplunkSpec(cr splcommon.MetaObject) *enterpriseApi.CommonSplunkSpec {
+	switch cr := cr.(type) {
+	case *enterpriseApi.Standalone:
+		return &cr.Spec.CommonSplunkSpec
+	case *enterpriseApi.ClusterManager:
+		return &cr.Spec.CommonSplunkSpec
+	case *enterpriseApiV3.ClusterMaster:
+		return &cr.Spec.CommonSplunkSpec
+	case *enterpriseApi.IndexerCluster:
+		return &cr.Spec.CommonSplunkSpec
+	case *enterpriseApi.SearchHeadCluster:
+		return &cr.Spec.CommonSplunkSpec
+	case *enterpriseApi.LicenseManager:
+		return &cr.Spec.CommonSplunkSpec
+	case *enterpriseApiV3.LicenseMaster:
+		return &cr.Spec.CommonSplunkSpec
+	case *enterpriseApi.MonitoringConsole:
+		return &cr.Spec.CommonSplunkSpec
+	case *enterpriseApi.DeploymentServer:
+		return &cr.Spec.CommonSplunkSpec
+	}
+	return &enterpriseApi.CommonSplunkSpec{}
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+)
+
+func TestGetMaintenanceWindows(t *testing.T) {
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	cr := &enterpriseApi.Standalone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	spec := &cr.Spec.CommonSplunkSpec
+
+	// no windows by default
+	windows, err := getMaintenanceWindows(ctx, c, cr, spec)
+	if err != nil || len(windows) != 0 {
+		t.Errorf("getMaintenanceWindows() = %v, %v; want none", windows, err)
+	}
+
+	// operator-wide windows apply when the custom resource has none
+	MaintenanceWindowsConfigMap = "splunk-operator/maintenance-windows"
+	defer func() { MaintenanceWindowsConfigMap = "" }()
+	windows, err = getMaintenanceWindows(ctx, c, cr, spec)
+	if err != nil || len(windows) != 0 {
+		t.Errorf("getMaintenanceWindows() without ConfigMap = %v, %v; want none", windows, err)
+	}
+	c.AddObject(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "maintenance-windows",
+			Namespace: "splunk-operator",
+		},
+		Data: map[string]string{
+			"maintenanceWindows": "- schedule: 0 2 * * SAT\n  duration: 4h\n  timeZone: Europe/Berlin\n",
+		},
+	})
+	want := []enterpriseApi.MaintenanceWindow{{Schedule: "0 2 * * SAT", Duration: "4h", TimeZone: "Europe/Berlin"}}
+	windows, err = getMaintenanceWindows(ctx, c, cr, spec)
+	if err != nil || !reflect.DeepEqual(windows, want) {
+		t.Errorf("getMaintenanceWindows() = %v, %v; want %v", windows, err, want)
+	}
+
+	// the windows of the custom resource take precedence
+	spec.MaintenanceWindows = []enterpriseApi.MaintenanceWindow{{Schedule: "@daily", Duration: "1h"}}
+	windows, err = getMaintenanceWindows(ctx, c, cr, spec)
+	if err != nil || !reflect.DeepEqual(windows, spec.MaintenanceWindows) {
+		t.Errorf("getMaintenanceWindows() = %v, %v; want %v", windows, err, spec.MaintenanceWindows)
+	}
+
+	// the override annotation lifts all windows
+	cr.Annotations = map[string]string{enterpriseApi.MaintenanceOverrideAnnotation: ""}
+	windows, err = getMaintenanceWindows(ctx, c, cr, spec)
+	if err != nil || len(windows) != 0 {
+		t.Errorf("getMaintenanceWindows() with override = %v, %v; want none", windows, err)
+	}
+
+	// invalid operator-wide windows are reported
+	cr.Annotations = nil
+	spec.MaintenanceWindows = nil
+	MaintenanceWindowsConfigMap = "splunk-operator/invalid"
+	c.AddObject(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "invalid",
+			Namespace: "splunk-operator",
+		},
+		Data: map[string]string{
+			"maintenanceWindows": "- schedule: every day\n  duration: 4h\n",
+		},
+	})
+	_, err = getMaintenanceWindows(ctx, c, cr, spec)
+	if err == nil {
+		t.Errorf("getMaintenanceWindows() should have returned an error for invalid windows")
+	}
+}
+
+func TestSetMaintenanceWindows(t *testing.T) {
+	statefulSet := &appsv1.StatefulSet{}
+	setMaintenanceWindows(statefulSet, nil)
+	if statefulSet.GetAnnotations() != nil {
+		t.Errorf("setMaintenanceWindows() changed the StatefulSet without windows")
+	}
+
+	setMaintenanceWindows(statefulSet, []enterpriseApi.MaintenanceWindow{{Schedule: "0 2 * * SAT", Duration: "4h"}})
+	want := `[{"schedule":"0 2 * * SAT","duration":"4h"}]`
+	if got := statefulSet.GetAnnotations()[enterpriseApi.MaintenanceWindowsAnnotation]; got != want {
+		t.Errorf("setMaintenanceWindows() annotation = %s; want %s", got, want)
+	}
+
+	setMaintenanceWindows(statefulSet, nil)
+	if _, ok := statefulSet.GetAnnotations()[enterpriseApi.MaintenanceWindowsAnnotation]; ok {
+		t.Errorf("setMaintenanceWindows() kept the annotation")
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// getClusterManagerInstanceType returns the instance type naming the resources of a cluster manager, which remains
+// the one of a cluster master once a ClusterManager has adopted the resources of a ClusterMaster
+func getClusterManagerInstanceType(cr splcommon.MetaObject) InstanceType {
+	if cm, ok := cr.(*enterpriseApi.ClusterManager); ok && cm.Status.ClusterMasterAdopted {
+		return SplunkClusterMaster
+	}
+	return SplunkClusterManager
+}
+
+// isClusterMasterAdopted returns true when a ClusterManager has adopted the resources of a ClusterMaster
+func isClusterMasterAdopted(cr splcommon.MetaObject) bool {
+	_, ok := cr.GetAnnotations()[enterpriseApiV3.ClusterMasterAdoptedAnnotation]
+	return ok
+}
+
+// adoptClusterMaster transfers the ownership of the statefulset, volumes and other resources of the ClusterMaster
+// of the same name to a ClusterManager, which keeps managing them under their names so that the pod is not recreated
+func adoptClusterMaster(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApi.ClusterManager) error {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("adoptClusterMaster").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	// a cluster manager already running its own pod cannot take over another one
+	statefulSet := &appsv1.StatefulSet{}
+	namespacedName := types.NamespacedName{Namespace: cr.GetNamespace(), Name: GetSplunkStatefulsetName(SplunkClusterManager, cr.GetName())}
+	err := c.Get(ctx, namespacedName, statefulSet)
+	if err == nil {
+		return fmt.Errorf("cannot adopt ClusterMaster %s, statefulset %s already exists", cr.GetName(), namespacedName.Name)
+	} else if !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	clusterMaster := &enterpriseApiV3.ClusterMaster{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: cr.GetName()}, clusterMaster)
+	if err != nil {
+		return fmt.Errorf("cannot adopt ClusterMaster %s: %w", cr.GetName(), err)
+	}
+
+	// stop reconciling the cluster master before taking over its resources
+	if !isClusterMasterAdopted(clusterMaster) {
+		annotations := clusterMaster.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[enterpriseApiV3.ClusterMasterAdoptedAnnotation] = cr.GetName()
+		clusterMaster.SetAnnotations(annotations)
+		err = splutil.UpdateResource(ctx, c, clusterMaster)
+		if err != nil {
+			return err
+		}
+	}
+
+	owner := splcommon.AsOwner(cr, true)
+	for _, list := range []client.ObjectList{
+		&appsv1.StatefulSetList{},
+		&corev1.ServiceList{},
+		&corev1.SecretList{},
+		&corev1.ConfigMapList{},
+		&corev1.PersistentVolumeClaimList{},
+	} {
+		err = c.List(ctx, list, client.InNamespace(cr.GetNamespace()))
+		if err != nil {
+			return err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			obj, ok := item.(splcommon.MetaObject)
+			if !ok || !transferOwnerReference(obj, clusterMaster.GetUID(), owner) {
+				continue
+			}
+			scopedLog.Info("Adopting resource of the cluster master", "kind", fmt.Sprintf("%T", obj), "resource", obj.GetName())
+			err = splutil.UpdateResource(ctx, c, obj)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	cr.Status.ClusterMasterAdopted = true
+	return nil
+}
+
+// transferOwnerReference replaces the owner reference of a previous owner with the one of a new owner, and
+// returns true when the object has changed
+func transferOwnerReference(obj metav1.Object, previousOwner types.UID, owner metav1.OwnerReference) bool {
+	references := []metav1.OwnerReference{}
+	found, owned := false, false
+	for _, ref := range obj.GetOwnerReferences() {
+		switch ref.UID {
+		case previousOwner:
+			found = true
+			owner.Controller = ref.Controller
+		case owner.UID:
+			owned = true
+			references = append(references, ref)
+		default:
+			references = append(references, ref)
+		}
+	}
+	if !found {
+		return false
+	}
+	if !owned {
+		references = append(references, owner)
+	}
+	obj.SetOwnerReferences(references)
+	return true
+}
+
+// applyAdoptedClusterMaster reconciles a ClusterMaster whose resources have been adopted by a ClusterManager, which
+// only removes its finalizers once it is deleted: its resources are left to the ClusterManager
+func applyAdoptedClusterMaster(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApiV3.ClusterMaster) (reconcile.Result, error) {
+	result := reconcile.Result{}
+	if cr.ObjectMeta.DeletionTimestamp != nil {
+		_, err := splctrl.CheckForDeletion(ctx, cr, c)
+		return result, err
+	}
+	return result, nil
+}
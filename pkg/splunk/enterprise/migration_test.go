@@ -0,0 +1,153 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"testing"
+
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAdoptClusterMaster(t *testing.T) {
+	utilruntime.Must(enterpriseApi.AddToScheme(clientgoscheme.Scheme))
+	utilruntime.Must(enterpriseApiV3.AddToScheme(clientgoscheme.Scheme))
+	ctx := context.TODO()
+
+	clusterMaster := enterpriseApiV3.ClusterMaster{
+		TypeMeta:   metav1.TypeMeta{Kind: "ClusterMaster", APIVersion: enterpriseApiV3.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "test", UID: "cluster-master-uid"},
+	}
+	cr := enterpriseApi.ClusterManager{
+		TypeMeta: metav1.TypeMeta{Kind: "ClusterManager", APIVersion: enterpriseApi.GroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "cm",
+			Namespace:   "test",
+			UID:         "cluster-manager-uid",
+			Annotations: map[string]string{enterpriseApi.ClusterManagerAdoptClusterMasterAnnotation: ""},
+		},
+	}
+	idxcOwner := metav1.OwnerReference{APIVersion: enterpriseApi.GroupVersion.String(), Kind: "IndexerCluster", Name: "idxc", UID: "idxc-uid"}
+
+	statefulSet := appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{
+		Name:            "splunk-cm-cluster-master",
+		Namespace:       "test",
+		OwnerReferences: []metav1.OwnerReference{splcommon.AsOwner(&clusterMaster, true), idxcOwner},
+	}}
+	service := corev1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:            "splunk-cm-cluster-master-service",
+		Namespace:       "test",
+		OwnerReferences: []metav1.OwnerReference{splcommon.AsOwner(&clusterMaster, true)},
+	}}
+	secret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:            "splunk-test-secret",
+		Namespace:       "test",
+		OwnerReferences: []metav1.OwnerReference{splcommon.AsOwner(&clusterMaster, false), splcommon.AsOwner(&cr, false)},
+	}}
+	pvc := corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Name:      "pvc-etc-splunk-cm-cluster-master-0",
+		Namespace: "test",
+		Labels:    map[string]string{"app.kubernetes.io/instance": "splunk-cm-cluster-master"},
+	}}
+
+	c := fake.NewClientBuilder().WithObjects(&clusterMaster, &statefulSet, &service, &secret, &pvc).Build()
+	if err := adoptClusterMaster(ctx, c, &cr); err != nil {
+		t.Fatalf("adoptClusterMaster() returned error: %v", err)
+	}
+	if !cr.Status.ClusterMasterAdopted || getClusterManagerInstanceType(&cr) != SplunkClusterMaster {
+		t.Errorf("adoptClusterMaster() did not mark the cluster master adopted")
+	}
+
+	got := enterpriseApiV3.ClusterMaster{}
+	_ = c.Get(ctx, types.NamespacedName{Namespace: "test", Name: "cm"}, &got)
+	if !isClusterMasterAdopted(&got) {
+		t.Errorf("adoptClusterMaster() did not annotate the cluster master")
+	}
+
+	// the resources keep their names, and are owned by the cluster manager instead of the cluster master
+	gotStatefulSet := appsv1.StatefulSet{}
+	_ = c.Get(ctx, types.NamespacedName{Namespace: "test", Name: GetSplunkStatefulsetName(getClusterManagerInstanceType(&cr), "cm")}, &gotStatefulSet)
+	refs := gotStatefulSet.GetOwnerReferences()
+	if len(refs) != 2 || refs[0].UID != idxcOwner.UID || refs[1].UID != cr.GetUID() || refs[1].Controller == nil || !*refs[1].Controller {
+		t.Errorf("adoptClusterMaster() statefulset owners = %v", refs)
+	}
+	gotService := corev1.Service{}
+	_ = c.Get(ctx, types.NamespacedName{Namespace: "test", Name: "splunk-cm-cluster-master-service"}, &gotService)
+	if refs := gotService.GetOwnerReferences(); len(refs) != 1 || refs[0].UID != cr.GetUID() {
+		t.Errorf("adoptClusterMaster() service owners = %v", refs)
+	}
+	gotSecret := corev1.Secret{}
+	_ = c.Get(ctx, types.NamespacedName{Namespace: "test", Name: "splunk-test-secret"}, &gotSecret)
+	if refs := gotSecret.GetOwnerReferences(); len(refs) != 1 || refs[0].UID != cr.GetUID() {
+		t.Errorf("adoptClusterMaster() secret owners = %v", refs)
+	}
+
+	// the volumes of the adopted cluster master are left to the cluster manager
+	if err := DeleteSplunkPvc(ctx, &got, c); err != nil {
+		t.Errorf("DeleteSplunkPvc() returned error: %v", err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "test", Name: pvc.GetName()}, &corev1.PersistentVolumeClaim{}); err != nil {
+		t.Errorf("DeleteSplunkPvc() removed the volume of an adopted cluster master: %v", err)
+	}
+	if err := DeleteSplunkPvc(ctx, &cr, c); err != nil {
+		t.Errorf("DeleteSplunkPvc() returned error: %v", err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "test", Name: pvc.GetName()}, &corev1.PersistentVolumeClaim{}); err == nil {
+		t.Errorf("DeleteSplunkPvc() did not remove the volume of the cluster manager")
+	}
+
+	// a cluster manager running its own pod cannot adopt a cluster master
+	cr.Status.ClusterMasterAdopted = false
+	ownStatefulSet := appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "splunk-cm-cluster-manager", Namespace: "test"}}
+	_ = c.Create(ctx, &ownStatefulSet)
+	if err := adoptClusterMaster(ctx, c, &cr); err == nil {
+		t.Errorf("adoptClusterMaster() should have returned an error for a cluster manager running its own pod")
+	}
+
+	// there must be a cluster master of the same name
+	c = fake.NewClientBuilder().Build()
+	if err := adoptClusterMaster(ctx, c, &cr); err == nil {
+		t.Errorf("adoptClusterMaster() should have returned an error without cluster master")
+	}
+}
+
+func TestTransferOwnerReference(t *testing.T) {
+	previous := metav1.OwnerReference{Kind: "ClusterMaster", Name: "cm", UID: "previous"}
+	owner := metav1.OwnerReference{Kind: "ClusterManager", Name: "cm", UID: "owner"}
+	other := metav1.OwnerReference{Kind: "IndexerCluster", Name: "idxc", UID: "other"}
+
+	obj := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{other}}}
+	if transferOwnerReference(&obj, previous.UID, owner) {
+		t.Errorf("transferOwnerReference() changed an object not owned by the previous owner")
+	}
+
+	obj.SetOwnerReferences([]metav1.OwnerReference{previous, other})
+	if !transferOwnerReference(&obj, previous.UID, owner) {
+		t.Errorf("transferOwnerReference() did not change an object owned by the previous owner")
+	}
+	if refs := obj.GetOwnerReferences(); len(refs) != 2 || refs[0].UID != other.UID || refs[1].UID != owner.UID {
+		t.Errorf("transferOwnerReference() owners = %v", refs)
+	}
+}
@@ -18,15 +18,22 @@ package enterprise
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
+	"net"
 	"reflect"
 	"sort"
 	"strings"
 	"time"
 
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
 	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
 
+	"github.com/splunk/splunk-operator/pkg/config"
+	"github.com/splunk/splunk-operator/pkg/splunk/audit"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -38,8 +45,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+const (
+	// key of the DMC peers revision in the resource revision tracker of the monitoring console
+	dmcPeersRevKey = "dmcPeers"
+
+	// interval to reconcile the distributed peers and DMC groups once the monitoring console is ready
+	monitoringConsolePeersPollInterval = time.Second * 60
+)
+
 // ApplyMonitoringConsole reconciles the StatefulSet for N monitoring console instances of Splunk Enterprise.
 func ApplyMonitoringConsole(ctx context.Context, client splcommon.ControllerClient, cr *enterpriseApi.MonitoringConsole) (reconcile.Result, error) {
+	ctx, span := tracing.Start(ctx, "ApplyMonitoringConsole")
+	defer span.End()
 
 	// unless modified, reconcile for this object will be requeued after 5 seconds
 	result := reconcile.Result{
@@ -48,7 +65,9 @@ func ApplyMonitoringConsole(ctx context.Context, client splcommon.ControllerClie
 	}
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("ApplyMonitoringConsole")
-	eventPublisher, _ := newK8EventPublisher(client, cr)
+	eventPublisher, _ := newK8EventPublisher(cr)
+	defer eventPublisher.publishPhaseChange(ctx, cr.Status.Phase, &cr.Status.Phase)
+	ctx = audit.NewContext(ctx, cr, &cr.Status.AuditTrail)
 
 	if cr.Status.ResourceRevMap == nil {
 		cr.Status.ResourceRevMap = make(map[string]string)
@@ -75,7 +94,7 @@ func ApplyMonitoringConsole(ctx context.Context, client splcommon.ControllerClie
 	if len(cr.Spec.AppFrameworkConfig.AppSources) != 0 {
 		err := initAndCheckAppInfoStatus(ctx, client, cr, &cr.Spec.AppFrameworkConfig, &cr.Status.AppContext)
 		if err != nil {
-			eventPublisher.Warning(ctx, "initAndCheckAppInfoStatus", fmt.Sprintf("init and check app info status failed %s", err.Error()))
+			eventPublisher.Warning(ctx, AppRepoCheckFailed, fmt.Sprintf("init and check app info status failed %s", err.Error()))
 			cr.Status.AppContext.IsDeploymentInProgress = false
 			return result, err
 		}
@@ -87,10 +106,10 @@ func ApplyMonitoringConsole(ctx context.Context, client splcommon.ControllerClie
 	defer updateCRStatus(ctx, client, cr)
 
 	// create or update general config resources
-	_, err = ApplySplunkConfig(ctx, client, cr, cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole)
+	namespaceScopedSecret, err := ApplySplunkConfig(ctx, client, cr, cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole)
 	if err != nil {
 		scopedLog.Error(err, "create or update general config failed", "error", err.Error())
-		eventPublisher.Warning(ctx, "ApplySplunkConfig", fmt.Sprintf("create or update general config failed with error %s", err.Error()))
+		eventPublisher.Warning(ctx, ConfigApplyFailed, fmt.Sprintf("create or update general config failed with error %s", err.Error()))
 		return result, err
 	}
 
@@ -118,36 +137,61 @@ func ApplyMonitoringConsole(ctx context.Context, client splcommon.ControllerClie
 	// create or update a headless service
 	err = splctrl.ApplyService(ctx, client, getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole, true))
 	if err != nil {
-		eventPublisher.Warning(ctx, "ApplyService", fmt.Sprintf("create or update headless service failed %s", err.Error()))
+		eventPublisher.Warning(ctx, ServiceApplyFailed, fmt.Sprintf("create or update headless service failed %s", err.Error()))
 		return result, err
 	}
 
 	// create or update a regular service
 	err = splctrl.ApplyService(ctx, client, getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole, false))
 	if err != nil {
-		eventPublisher.Warning(ctx, "ApplyService", fmt.Sprintf("create or update regular service failed %s", err.Error()))
+		eventPublisher.Warning(ctx, ServiceApplyFailed, fmt.Sprintf("create or update regular service failed %s", err.Error()))
+		return result, err
+	}
+
+	// create or update the services of each type of traffic and their routes
+	err = ApplySplunkTrafficServices(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole, false)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the certificates of the ports and the secret mounting them
+	err = ApplySplunkTLS(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole)
+	if err != nil {
 		return result, err
 	}
 
 	// create or update statefulset
 	statefulSet, err := getMonitoringConsoleStatefulSet(ctx, client, cr)
 	if err != nil {
-		eventPublisher.Warning(ctx, "getMonitoringConsoleStatefulSet", fmt.Sprintf("get monitoring console stateful set failed %s", err.Error()))
+		eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("get monitoring console stateful set failed %s", err.Error()))
 		return result, err
 	}
 
 	mgr := splctrl.DefaultStatefulSetPodManager{}
 	phase, err := mgr.Update(ctx, client, statefulSet, 1)
 	if err != nil {
-		eventPublisher.Warning(ctx, "getMonitoringConsoleStatefulSet", fmt.Sprintf("update to default statefuleset pod manager failed %s", err.Error()))
+		eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("update to default statefuleset pod manager failed %s", err.Error()))
 		return result, err
 	}
 	cr.Status.Phase = phase
 
 	// no need to requeue if everything is ready
 	if cr.Status.Phase == enterpriseApi.PhaseReady {
+		// an unreachable peer shouldn't hold back the app framework, so the error is only reported
+		err = reconcileMonitoringConsolePeers(ctx, client, cr, namespaceScopedSecret)
+		if err != nil {
+			scopedLog.Error(err, "Unable to reconcile the distributed peers of the monitoring console")
+			eventPublisher.Warning(ctx, MonitoringConsoleUpdateFailed, fmt.Sprintf("reconcile distributed peers failed %s", err.Error()))
+		}
+
 		finalResult := handleAppFrameworkActivity(ctx, client, cr, &cr.Status.AppContext, &cr.Spec.AppFrameworkConfig)
 		result = *finalResult
+
+		// keep the distributed peers and DMC groups current while there is no app framework activity
+		if !result.Requeue {
+			result.Requeue = true
+			result.RequeueAfter = monitoringConsolePeersPollInterval
+		}
 	}
 	// RequeueAfter if greater than 0, tells the Controller to requeue the reconcile key after the Duration.
 	// Implies that Requeue is true, there is no need to set Requeue to true at the same time as RequeueAfter.
@@ -214,11 +258,56 @@ func validateMonitoringConsoleSpec(ctx context.Context, c splcommon.ControllerCl
 			return err
 		}
 	}
+
+	err := validateMonitoringConsolePeers(cr)
+	if err != nil {
+		return err
+	}
 	return validateCommonSplunkSpec(ctx, c, &cr.Spec.CommonSplunkSpec, cr)
 }
 
+// validateMonitoringConsolePeers checks validity of the peer selectors and external peers of a MonitoringConsole
+func validateMonitoringConsolePeers(cr *enterpriseApi.MonitoringConsole) error {
+	if cr.Spec.PeerSelector != nil {
+		_, err := metav1.LabelSelectorAsSelector(cr.Spec.PeerSelector)
+		if err != nil {
+			return fmt.Errorf("invalid peerSelector: %v", err)
+		}
+	}
+	if cr.Spec.PeerNamespaceSelector != nil {
+		if cr.Spec.PeerSelector == nil {
+			return fmt.Errorf("peerNamespaceSelector requires peerSelector")
+		}
+		_, err := metav1.LabelSelectorAsSelector(cr.Spec.PeerNamespaceSelector)
+		if err != nil {
+			return fmt.Errorf("invalid peerNamespaceSelector: %v", err)
+		}
+		// the namespaces and peers are listed cluster wide, which a namespace scoped operator can neither cache nor read
+		if namespaces := config.GetWatchNamespaces(); len(namespaces) > 0 {
+			return fmt.Errorf("peerNamespaceSelector requires the operator to watch all namespaces, it only watches %s", strings.Join(namespaces, ","))
+		}
+	}
+
+	hosts := make(map[string]bool, len(cr.Spec.ExternalPeers))
+	for _, externalPeer := range cr.Spec.ExternalPeers {
+		if externalPeer.Host == "" {
+			return fmt.Errorf("external peer host can not be empty")
+		}
+		if externalPeer.SecretRef == "" {
+			return fmt.Errorf("secretRef of external peer %s can not be empty", externalPeer.Host)
+		}
+		if hosts[externalPeer.Host] {
+			return fmt.Errorf("duplicate external peer %s", externalPeer.Host)
+		}
+		hosts[externalPeer.Host] = true
+	}
+	return nil
+}
+
 // ApplyMonitoringConsoleEnvConfigMap creates or updates a Kubernetes ConfigMap for extra env for monitoring console pod
 func ApplyMonitoringConsoleEnvConfigMap(ctx context.Context, client splcommon.ControllerClient, namespace string, crName string, monitoringConsoleRef string, newURLs []corev1.EnvVar, addNewURLs bool) (*corev1.ConfigMap, error) {
+	ctx, span := tracing.Start(ctx, "ApplyMonitoringConsoleEnvConfigMap")
+	defer span.End()
 
 	var current corev1.ConfigMap
 
@@ -355,3 +444,376 @@ func DeleteURLsConfigMap(revised *corev1.ConfigMap, crName string, newURLs []cor
 		}
 	}
 }
+
+// monitoringConsolePeer is a distributed peer added to the monitoring console by the operator
+type monitoringConsolePeer struct {
+	name     string
+	source   string
+	username string
+	password string
+}
+
+// selectedMonitoringConsolePeer is a custom resource matching the peer selector of the monitoring console
+type selectedMonitoringConsolePeer struct {
+	cr           splcommon.MetaObject
+	kind         string
+	instanceType InstanceType
+	replicas     int32
+}
+
+// newMonitoringConsoleClient returns a Splunk client for the monitoring console service
+var newMonitoringConsoleClient = func(cr *enterpriseApi.MonitoringConsole, secret *corev1.Secret) *splclient.SplunkClient {
+	fqdnName := splcommon.GetServiceFQDN(cr.GetNamespace(), GetSplunkServiceName(SplunkMonitoringConsole, cr.GetName(), false))
+	return splclient.NewSplunkClient(fmt.Sprintf("https://%s:8089", fqdnName), "admin", string(secret.Data["password"]))
+}
+
+// getMonitoringConsolePeerNamespaces returns the namespaces searched for custom resources matching the peer selector
+func getMonitoringConsolePeerNamespaces(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApi.MonitoringConsole) ([]string, error) {
+	if cr.Spec.PeerNamespaceSelector == nil {
+		return []string{cr.GetNamespace()}, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(cr.Spec.PeerNamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaceList := corev1.NamespaceList{}
+	err = c.List(ctx, &namespaceList, client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]string, 0, len(namespaceList.Items))
+	for _, namespace := range namespaceList.Items {
+		namespaces = append(namespaces, namespace.GetName())
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// getMonitoringConsoleSelectedPeers returns the pods of the custom resources matching the peer selector of the monitoring console.
+// Custom resources referencing the monitoring console are skipped, as they are already configured through the monitoring console config map
+func getMonitoringConsoleSelectedPeers(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApi.MonitoringConsole) ([]monitoringConsolePeer, error) {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("getMonitoringConsoleSelectedPeers").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	var peers []monitoringConsolePeer
+	if cr.Spec.PeerSelector == nil {
+		return peers, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(cr.Spec.PeerSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err := getMonitoringConsolePeerNamespaces(ctx, c, cr)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, namespace := range namespaces {
+		var selected []selectedMonitoringConsolePeer
+		listOpts := []client.ListOption{
+			client.InNamespace(namespace),
+			client.MatchingLabelsSelector{Selector: selector},
+		}
+
+		standaloneList := enterpriseApi.StandaloneList{}
+		err = c.List(ctx, &standaloneList, listOpts...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range standaloneList.Items {
+			selected = append(selected, selectedMonitoringConsolePeer{cr: &standaloneList.Items[i], kind: "Standalone", instanceType: SplunkStandalone, replicas: standaloneList.Items[i].Spec.Replicas})
+		}
+
+		searchHeadClusterList := enterpriseApi.SearchHeadClusterList{}
+		err = c.List(ctx, &searchHeadClusterList, listOpts...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range searchHeadClusterList.Items {
+			selected = append(selected, selectedMonitoringConsolePeer{cr: &searchHeadClusterList.Items[i], kind: "SearchHeadCluster", instanceType: SplunkSearchHead, replicas: searchHeadClusterList.Items[i].Spec.Replicas})
+		}
+
+		indexerClusterList := enterpriseApi.IndexerClusterList{}
+		err = c.List(ctx, &indexerClusterList, listOpts...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range indexerClusterList.Items {
+			selected = append(selected, selectedMonitoringConsolePeer{cr: &indexerClusterList.Items[i], kind: "IndexerCluster", instanceType: SplunkIndexer, replicas: indexerClusterList.Items[i].Spec.Replicas})
+		}
+
+		clusterManagerList := enterpriseApi.ClusterManagerList{}
+		err = c.List(ctx, &clusterManagerList, listOpts...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range clusterManagerList.Items {
+			selected = append(selected, selectedMonitoringConsolePeer{cr: &clusterManagerList.Items[i], kind: "ClusterManager", instanceType: getClusterManagerInstanceType(&clusterManagerList.Items[i]), replicas: 1})
+		}
+
+		clusterMasterList := enterpriseApiV3.ClusterMasterList{}
+		err = c.List(ctx, &clusterMasterList, listOpts...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range clusterMasterList.Items {
+			// an adopted cluster master is monitored through its cluster manager
+			if isClusterMasterAdopted(&clusterMasterList.Items[i]) {
+				continue
+			}
+			selected = append(selected, selectedMonitoringConsolePeer{cr: &clusterMasterList.Items[i], kind: "ClusterMaster", instanceType: SplunkClusterMaster, replicas: 1})
+		}
+
+		licenseManagerList := enterpriseApi.LicenseManagerList{}
+		err = c.List(ctx, &licenseManagerList, listOpts...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range licenseManagerList.Items {
+			selected = append(selected, selectedMonitoringConsolePeer{cr: &licenseManagerList.Items[i], kind: "LicenseManager", instanceType: SplunkLicenseManager, replicas: 1})
+		}
+
+		licenseMasterList := enterpriseApiV3.LicenseMasterList{}
+		err = c.List(ctx, &licenseMasterList, listOpts...)
+		if err != nil {
+			return nil, err
+		}
+		for i := range licenseMasterList.Items {
+			selected = append(selected, selectedMonitoringConsolePeer{cr: &licenseMasterList.Items[i], kind: "LicenseMaster", instanceType: SplunkLicenseMaster, replicas: 1})
+		}
+
+		if len(selected) == 0 {
+			continue
+		}
+
+		// all the Splunk Enterprise instances of a namespace share the namespace scoped secret
+		namespaceScopedSecret, err := splutil.GetNamespaceScopedSecret(ctx, c, namespace)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				scopedLog.Info("Namespace scoped secret not found, skipping the selected peers of the namespace", "peerNamespace", namespace)
+				continue
+			}
+			return nil, err
+		}
+
+		for _, peerCR := range selected {
+			if peerCR.cr.GetDeletionTimestamp() != nil {
+				continue
+			}
+			if namespace == cr.GetNamespace() && getMonitoringConsoleRefName(peerCR.cr) == cr.GetName() {
+				continue
+			}
+			for index := int32(0); index < peerCR.replicas; index++ {
+				peers = append(peers, monitoringConsolePeer{
+					name:     fmt.Sprintf("%s:8089", GetSplunkStatefulsetURL(namespace, peerCR.instanceType, peerCR.cr.GetName(), index, false)),
+					source:   fmt.Sprintf("%s/%s/%s", peerCR.kind, namespace, peerCR.cr.GetName()),
+					username: "admin",
+					password: string(namespaceScopedSecret.Data["password"]),
+				})
+			}
+		}
+	}
+
+	return peers, nil
+}
+
+// getMonitoringConsoleRefName returns the name of the monitoring console referenced by a Splunk Enterprise custom resource
+func getMonitoringConsoleRefName(cr splcommon.MetaObject) string {
+	switch peerCR := cr.(type) {
+	case *enterpriseApi.Standalone:
+		return peerCR.Spec.MonitoringConsoleRef.Name
+	case *enterpriseApi.SearchHeadCluster:
+		return peerCR.Spec.MonitoringConsoleRef.Name
+	case *enterpriseApi.IndexerCluster:
+		return peerCR.Spec.MonitoringConsoleRef.Name
+	case *enterpriseApi.ClusterManager:
+		return peerCR.Spec.MonitoringConsoleRef.Name
+	case *enterpriseApiV3.ClusterMaster:
+		return peerCR.Spec.MonitoringConsoleRef.Name
+	case *enterpriseApi.LicenseManager:
+		return peerCR.Spec.MonitoringConsoleRef.Name
+	case *enterpriseApiV3.LicenseMaster:
+		return peerCR.Spec.MonitoringConsoleRef.Name
+	}
+	return ""
+}
+
+// getMonitoringConsoleExternalPeers returns the statically declared external peers of the monitoring console
+func getMonitoringConsoleExternalPeers(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApi.MonitoringConsole) ([]monitoringConsolePeer, error) {
+	var peers []monitoringConsolePeer
+	for _, externalPeer := range cr.Spec.ExternalPeers {
+		peerName := externalPeer.Host
+		if _, _, err := net.SplitHostPort(peerName); err != nil {
+			peerName = net.JoinHostPort(peerName, "8089")
+		}
+
+		secret, err := splutil.GetSecretByName(ctx, c, cr.GetNamespace(), cr.GetName(), externalPeer.SecretRef)
+		if err != nil {
+			return nil, err
+		}
+		password, ok := secret.Data["password"]
+		if !ok {
+			return nil, fmt.Errorf("password missing in secret %s of external peer %s", externalPeer.SecretRef, externalPeer.Host)
+		}
+		username := "admin"
+		if secretUsername, ok := secret.Data["username"]; ok {
+			username = string(secretUsername)
+		}
+
+		peers = append(peers, monitoringConsolePeer{
+			name:     peerName,
+			source:   "external",
+			username: username,
+			password: string(password),
+		})
+	}
+	return peers, nil
+}
+
+// getDMCPeersRev returns a revision of the distributed peers, server roles and cluster labels the DMC groups are built from
+func getDMCPeersRev(peers map[string]splclient.MCDistributedPeers) string {
+	names := make([]string, 0, len(peers))
+	for name := range peers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := fnv.New64a()
+	for _, name := range names {
+		roles := append([]string{}, peers[name].ServerRoles...)
+		sort.Strings(roles)
+		labels := append([]string{}, peers[name].ClusterLabel...)
+		sort.Strings(labels)
+		fmt.Fprintf(h, "%s|%s|%s\n", name, strings.Join(roles, ","), strings.Join(labels, ","))
+	}
+	return fmt.Sprintf("%x", h.Sum64())
+}
+
+// reconcileMonitoringConsolePeers registers the selected and external peers with the monitoring console, removes the peers
+// no longer selected and keeps the DMC groups and cluster labels in sync with the distributed peers of the monitoring console
+func reconcileMonitoringConsolePeers(ctx context.Context, c splcommon.ControllerClient, cr *enterpriseApi.MonitoringConsole, secret *corev1.Secret) error {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("reconcileMonitoringConsolePeers").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	if secret == nil {
+		return fmt.Errorf("namespace scoped secret not found")
+	}
+
+	desiredPeers, err := getMonitoringConsoleSelectedPeers(ctx, c, cr)
+	if err != nil {
+		return err
+	}
+	externalPeers, err := getMonitoringConsoleExternalPeers(ctx, c, cr)
+	if err != nil {
+		return err
+	}
+	desiredPeers = append(desiredPeers, externalPeers...)
+
+	mcClient := newMonitoringConsoleClient(cr, secret)
+	currentPeers, err := mcClient.GetMonitoringConsoleDistributedPeers(ctx)
+	if err != nil {
+		return err
+	}
+
+	peersChanged := false
+	desired := make(map[string]bool, len(desiredPeers))
+	var peerStatus []enterpriseApi.MonitoringConsolePeerStatus
+	for _, peer := range desiredPeers {
+		if desired[peer.name] {
+			continue
+		}
+		desired[peer.name] = true
+		peerStatus = append(peerStatus, enterpriseApi.MonitoringConsolePeerStatus{Name: peer.name, Source: peer.source})
+
+		if _, ok := currentPeers[peer.name]; ok {
+			continue
+		}
+		scopedLog.Info("Adding distributed peer", "peer", peer.name, "source", peer.source)
+		err = mcClient.AddMonitoringConsoleDistributedPeer(ctx, peer.name, peer.username, peer.password)
+		if err != nil {
+			return err
+		}
+		peersChanged = true
+	}
+
+	// only the peers added by the operator are removed, peers added by other means are left alone
+	for _, peer := range cr.Status.Peers {
+		if desired[peer.Name] {
+			continue
+		}
+		if _, ok := currentPeers[peer.Name]; !ok {
+			continue
+		}
+		scopedLog.Info("Removing distributed peer", "peer", peer.Name, "source", peer.Source)
+		err = mcClient.RemoveMonitoringConsoleDistributedPeer(ctx, peer.Name)
+		if err != nil {
+			return err
+		}
+		peersChanged = true
+	}
+
+	if peersChanged {
+		currentPeers, err = mcClient.GetMonitoringConsoleDistributedPeers(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range peerStatus {
+		peerStatus[i].Status = currentPeers[peerStatus[i].Name].Status
+	}
+	cr.Status.Peers = peerStatus
+
+	// rebuild the DMC groups and cluster labels whenever the distributed peers, their roles or labels change
+	peersRev := getDMCPeersRev(currentPeers)
+	if cr.Status.ResourceRevMap[dmcPeersRevKey] == peersRev {
+		return nil
+	}
+
+	scopedLog.Info("Updating DMC groups", "peers", len(currentPeers))
+	err = mcClient.AutomateMCApplyChanges(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = removeStaleDMCClusteringLabelGroups(ctx, mcClient, currentPeers)
+	if err != nil {
+		return err
+	}
+
+	cr.Status.ResourceRevMap[dmcPeersRevKey] = peersRev
+	return nil
+}
+
+// removeStaleDMCClusteringLabelGroups drops the DMC groups of cluster labels no longer used by any distributed peer
+func removeStaleDMCClusteringLabelGroups(ctx context.Context, mcClient *splclient.SplunkClient, peers map[string]splclient.MCDistributedPeers) error {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("removeStaleDMCClusteringLabelGroups")
+
+	clusterLabels := make(map[string]bool)
+	for _, peer := range peers {
+		for _, label := range peer.ClusterLabel {
+			clusterLabels[label] = true
+		}
+	}
+
+	labelGroups, err := mcClient.GetDMCClusteringLabelGroups(ctx)
+	if err != nil {
+		return err
+	}
+	for _, label := range labelGroups {
+		if clusterLabels[label] {
+			continue
+		}
+		scopedLog.Info("Removing DMC group of stale cluster label", "label", label)
+		err = mcClient.DeleteDMCClusteringLabelGroup(ctx, label)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -19,10 +19,12 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime/debug"
 	"testing"
 	"time"
 
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
 	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
 
 	appsv1 "k8s.io/api/apps/v1"
@@ -34,6 +36,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/splunk/splunk-operator/pkg/config"
 	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
@@ -1102,3 +1105,340 @@ func TestGetMonitoringConsoleList(t *testing.T) {
 		t.Errorf("Got wrong number of IndexerCluster objects. Expected=%d, Got=%d", 1, numOfObjects)
 	}
 }
+
+func TestValidateMonitoringConsolePeers(t *testing.T) {
+	cr := enterpriseApi.MonitoringConsole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+		Spec: enterpriseApi.MonitoringConsoleSpec{
+			PeerSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"monitoring": "stack1"},
+			},
+			PeerNamespaceSelector: &metav1.LabelSelector{},
+			ExternalPeers: []enterpriseApi.MonitoringConsoleExternalPeer{
+				{Host: "splunk.example.com", SecretRef: "external-secret"},
+			},
+		},
+	}
+	err := validateMonitoringConsolePeers(&cr)
+	if err != nil {
+		t.Errorf("validateMonitoringConsolePeers() returned error %v", err)
+	}
+
+	invalid := cr.DeepCopy()
+	invalid.Spec.PeerSelector = nil
+	if validateMonitoringConsolePeers(invalid) == nil {
+		t.Errorf("validateMonitoringConsolePeers() should have returned error for a namespace selector without peer selector")
+	}
+
+	// peers of other namespaces can only be selected by an operator watching all namespaces
+	t.Setenv(config.WatchNamespaceEnvVar, "test")
+	if validateMonitoringConsolePeers(&cr) == nil {
+		t.Errorf("validateMonitoringConsolePeers() should have returned error for a namespace selector of a namespace scoped operator")
+	}
+	t.Setenv(config.WatchNamespaceEnvVar, "")
+	if err = validateMonitoringConsolePeers(&cr); err != nil {
+		t.Errorf("validateMonitoringConsolePeers() returned error %v for an operator watching all namespaces", err)
+	}
+	os.Unsetenv(config.WatchNamespaceEnvVar)
+
+	invalid = cr.DeepCopy()
+	invalid.Spec.PeerSelector.MatchExpressions = []metav1.LabelSelectorRequirement{{Key: "monitoring", Operator: "Invalid"}}
+	if validateMonitoringConsolePeers(invalid) == nil {
+		t.Errorf("validateMonitoringConsolePeers() should have returned error for an invalid peer selector")
+	}
+
+	invalid = cr.DeepCopy()
+	invalid.Spec.ExternalPeers[0].SecretRef = ""
+	if validateMonitoringConsolePeers(invalid) == nil {
+		t.Errorf("validateMonitoringConsolePeers() should have returned error for an external peer without secretRef")
+	}
+
+	invalid = cr.DeepCopy()
+	invalid.Spec.ExternalPeers = append(invalid.Spec.ExternalPeers, invalid.Spec.ExternalPeers[0])
+	if validateMonitoringConsolePeers(invalid) == nil {
+		t.Errorf("validateMonitoringConsolePeers() should have returned error for duplicate external peers")
+	}
+}
+
+func TestGetMonitoringConsoleSelectedPeers(t *testing.T) {
+	ctx := context.TODO()
+	utilruntime.Must(enterpriseApi.AddToScheme(clientgoscheme.Scheme))
+	utilruntime.Must(enterpriseApiV3.AddToScheme(clientgoscheme.Scheme))
+
+	selectedLabels := map[string]string{"monitoring": "stack1"}
+	objects := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "other", Labels: map[string]string{"team": "a"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ignored"}},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: splcommon.GetNamespaceScopedSecretName("test"), Namespace: "test"},
+			Data:       map[string][]byte{"password": []byte("test-password")},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: splcommon.GetNamespaceScopedSecretName("other"), Namespace: "other"},
+			Data:       map[string][]byte{"password": []byte("other-password")},
+		},
+		&enterpriseApi.Standalone{
+			ObjectMeta: metav1.ObjectMeta{Name: "s1", Namespace: "other", Labels: selectedLabels},
+			Spec:       enterpriseApi.StandaloneSpec{Replicas: 2},
+		},
+		&enterpriseApi.Standalone{
+			ObjectMeta: metav1.ObjectMeta{Name: "s2", Namespace: "other"},
+			Spec:       enterpriseApi.StandaloneSpec{Replicas: 1},
+		},
+		&enterpriseApi.ClusterManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "test", Labels: selectedLabels},
+		},
+		// already configured through monitoringConsoleRef
+		&enterpriseApi.LicenseManager{
+			ObjectMeta: metav1.ObjectMeta{Name: "lm", Namespace: "test", Labels: selectedLabels},
+			Spec: enterpriseApi.LicenseManagerSpec{
+				CommonSplunkSpec: enterpriseApi.CommonSplunkSpec{
+					MonitoringConsoleRef: corev1.ObjectReference{Name: "stack1"},
+				},
+			},
+		},
+		&enterpriseApi.Standalone{
+			ObjectMeta: metav1.ObjectMeta{Name: "s3", Namespace: "ignored", Labels: selectedLabels},
+			Spec:       enterpriseApi.StandaloneSpec{Replicas: 1},
+		},
+	}
+	c := fake.NewClientBuilder().WithObjects(objects...).Build()
+
+	cr := enterpriseApi.MonitoringConsole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+
+	// no peer selector
+	peers, err := getMonitoringConsoleSelectedPeers(ctx, c, &cr)
+	if err != nil || len(peers) != 0 {
+		t.Errorf("getMonitoringConsoleSelectedPeers() without peer selector got %v, err %v", peers, err)
+	}
+
+	// only the namespace of the monitoring console
+	cr.Spec.PeerSelector = &metav1.LabelSelector{MatchLabels: selectedLabels}
+	peers, err = getMonitoringConsoleSelectedPeers(ctx, c, &cr)
+	if err != nil {
+		t.Errorf("getMonitoringConsoleSelectedPeers() returned error %v", err)
+	}
+	if len(peers) != 1 || peers[0].name != "splunk-cm-cluster-manager-0.splunk-cm-cluster-manager-headless.test.svc.cluster.local:8089" || peers[0].source != "ClusterManager/test/cm" || peers[0].password != "test-password" {
+		t.Errorf("getMonitoringConsoleSelectedPeers() got unexpected peers %v", peers)
+	}
+
+	// namespaces selected by label
+	cr.Spec.PeerNamespaceSelector = &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: "team", Operator: metav1.LabelSelectorOpExists},
+		},
+	}
+	peers, err = getMonitoringConsoleSelectedPeers(ctx, c, &cr)
+	if err != nil {
+		t.Errorf("getMonitoringConsoleSelectedPeers() returned error %v", err)
+	}
+	wantPeers := []string{
+		"splunk-s1-standalone-0.splunk-s1-standalone-headless.other.svc.cluster.local:8089",
+		"splunk-s1-standalone-1.splunk-s1-standalone-headless.other.svc.cluster.local:8089",
+	}
+	if len(peers) != len(wantPeers) {
+		t.Fatalf("getMonitoringConsoleSelectedPeers() got %d peers; want %d", len(peers), len(wantPeers))
+	}
+	for i := range wantPeers {
+		if peers[i].name != wantPeers[i] || peers[i].password != "other-password" {
+			t.Errorf("getMonitoringConsoleSelectedPeers() got peer %v; want %s", peers[i], wantPeers[i])
+		}
+	}
+
+	// all namespaces
+	cr.Spec.PeerNamespaceSelector = &metav1.LabelSelector{}
+	peers, err = getMonitoringConsoleSelectedPeers(ctx, c, &cr)
+	if err != nil {
+		t.Errorf("getMonitoringConsoleSelectedPeers() returned error %v", err)
+	}
+	// the namespace without namespace scoped secret is skipped
+	if len(peers) != 3 {
+		t.Errorf("getMonitoringConsoleSelectedPeers() got %d peers; want 3", len(peers))
+	}
+}
+
+func TestGetMonitoringConsoleExternalPeers(t *testing.T) {
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	cr := enterpriseApi.MonitoringConsole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+		Spec: enterpriseApi.MonitoringConsoleSpec{
+			ExternalPeers: []enterpriseApi.MonitoringConsoleExternalPeer{
+				{Host: "splunk1.example.com", SecretRef: "external-secret"},
+				{Host: "splunk2.example.com:8090", SecretRef: "external-secret-with-user"},
+			},
+		},
+	}
+
+	_, err := getMonitoringConsoleExternalPeers(ctx, c, &cr)
+	if err == nil {
+		t.Errorf("getMonitoringConsoleExternalPeers() should have returned error when the secret is missing")
+	}
+
+	c.AddObject(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "external-secret", Namespace: "test"},
+		Data:       map[string][]byte{"password": []byte("p1")},
+	})
+	c.AddObject(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "external-secret-with-user", Namespace: "test"},
+		Data:       map[string][]byte{"username": []byte("monitor"), "password": []byte("p2")},
+	})
+	peers, err := getMonitoringConsoleExternalPeers(ctx, c, &cr)
+	if err != nil {
+		t.Errorf("getMonitoringConsoleExternalPeers() returned error %v", err)
+	}
+	want := []monitoringConsolePeer{
+		{name: "splunk1.example.com:8089", source: "external", username: "admin", password: "p1"},
+		{name: "splunk2.example.com:8090", source: "external", username: "monitor", password: "p2"},
+	}
+	if !reflect.DeepEqual(peers, want) {
+		t.Errorf("getMonitoringConsoleExternalPeers() got %v; want %v", peers, want)
+	}
+}
+
+func TestReconcileMonitoringConsolePeers(t *testing.T) {
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	cr := enterpriseApi.MonitoringConsole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+		Spec: enterpriseApi.MonitoringConsoleSpec{
+			ExternalPeers: []enterpriseApi.MonitoringConsoleExternalPeer{
+				{Host: "splunk1.example.com", SecretRef: "external-secret"},
+			},
+		},
+		Status: enterpriseApi.MonitoringConsoleStatus{
+			ResourceRevMap: map[string]string{},
+			Peers: []enterpriseApi.MonitoringConsolePeerStatus{
+				{Name: "splunk-old.example.com:8089", Source: "external"},
+			},
+		},
+	}
+	c.AddObject(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "external-secret", Namespace: "test"},
+		Data:       map[string][]byte{"password": []byte("p1")},
+	})
+	secret := &corev1.Secret{
+		Data: map[string][]byte{"password": []byte("mc-password")},
+	}
+
+	err := reconcileMonitoringConsolePeers(ctx, c, &cr, nil)
+	if err == nil {
+		t.Errorf("reconcileMonitoringConsolePeers() should have returned error without a secret")
+	}
+
+	mcURI := "https://splunk-stack1-monitoring-console-service.test.svc.cluster.local:8089"
+	mockSplunkClient := &spltest.MockHTTPClient{}
+	savedNewMonitoringConsoleClient := newMonitoringConsoleClient
+	defer func() { newMonitoringConsoleClient = savedNewMonitoringConsoleClient }()
+	newMonitoringConsoleClient = func(cr *enterpriseApi.MonitoringConsole, secret *corev1.Secret) *splclient.SplunkClient {
+		c := savedNewMonitoringConsoleClient(cr, secret)
+		c.Client = mockSplunkClient
+		return c
+	}
+
+	// the peer added before is gone from the spec and the new one is added, the manually added peer is left alone
+	getPeersURL := mcURI + "/services/search/distributed/peers?count=0&output_mode=json"
+	peersBefore := `{"entry":[{"name":"splunk-old.example.com:8089","content":{"status":"Up","server_roles":["indexer"],"cluster_label":["old"]}},{"name":"manual.example.com:8089","content":{"status":"Up","server_roles":["indexer"]}}]}`
+	mockSplunkClient.AddHandlers([]spltest.MockHTTPHandler{
+		{Method: "GET", URL: getPeersURL, Status: 200, Body: peersBefore},
+		{Method: "POST", URL: mcURI + "/services/search/distributed/peers", Status: 201},
+		{Method: "DELETE", URL: mcURI + "/services/search/distributed/peers/splunk-old.example.com:8089", Status: 200},
+	}...)
+
+	// the DMC groups can't be updated, as the server info isn't mocked
+	err = reconcileMonitoringConsolePeers(ctx, c, &cr, secret)
+	if err == nil {
+		t.Errorf("reconcileMonitoringConsolePeers() should have returned error when the DMC groups can't be updated")
+	}
+	wantRequests := []string{
+		"GET " + getPeersURL,
+		"POST " + mcURI + "/services/search/distributed/peers",
+		"DELETE " + mcURI + "/services/search/distributed/peers/splunk-old.example.com:8089",
+		"GET " + getPeersURL,
+		"GET " + mcURI + "/services/server/info/server-info?count=0&output_mode=json",
+	}
+	if len(mockSplunkClient.GotRequests) != len(wantRequests) {
+		t.Fatalf("reconcileMonitoringConsolePeers() sent %d requests; want %d", len(mockSplunkClient.GotRequests), len(wantRequests))
+	}
+	for i, req := range mockSplunkClient.GotRequests {
+		if got := req.Method + " " + req.URL.String(); got != wantRequests[i] {
+			t.Errorf("reconcileMonitoringConsolePeers() request %d=%s; want %s", i, got, wantRequests[i])
+		}
+	}
+	if len(cr.Status.Peers) != 1 || cr.Status.Peers[0].Name != "splunk1.example.com:8089" || cr.Status.Peers[0].Source != "external" {
+		t.Errorf("reconcileMonitoringConsolePeers() got unexpected peer status %v", cr.Status.Peers)
+	}
+	if _, ok := cr.Status.ResourceRevMap[dmcPeersRevKey]; ok {
+		t.Errorf("reconcileMonitoringConsolePeers() should not record the DMC peers revision when the DMC groups can't be updated")
+	}
+
+	// nothing changed since the DMC groups were last updated
+	mockSplunkClient = &spltest.MockHTTPClient{}
+	peersAfter := `{"entry":[{"name":"splunk1.example.com:8089","content":{"status":"Up","server_roles":["indexer"],"cluster_label":["idxc"]}}]}`
+	mockSplunkClient.AddHandlers(spltest.MockHTTPHandler{Method: "GET", URL: getPeersURL, Status: 200, Body: peersAfter})
+	cr.Status.ResourceRevMap[dmcPeersRevKey] = getDMCPeersRev(map[string]splclient.MCDistributedPeers{
+		"splunk1.example.com:8089": {ServerRoles: []string{"indexer"}, ClusterLabel: []string{"idxc"}},
+	})
+	err = reconcileMonitoringConsolePeers(ctx, c, &cr, secret)
+	if err != nil {
+		t.Errorf("reconcileMonitoringConsolePeers() returned error %v", err)
+	}
+	mockSplunkClient.CheckRequests(t, "TestReconcileMonitoringConsolePeers")
+	if len(cr.Status.Peers) != 1 || cr.Status.Peers[0].Status != "Up" {
+		t.Errorf("reconcileMonitoringConsolePeers() got unexpected peer status %v", cr.Status.Peers)
+	}
+}
+
+func TestRemoveStaleDMCClusteringLabelGroups(t *testing.T) {
+	ctx := context.TODO()
+	mockSplunkClient := &spltest.MockHTTPClient{}
+	mockSplunkClient.AddHandlers([]spltest.MockHTTPHandler{
+		{Method: "GET", URL: "https://localhost:8089/services/search/distributed/groups?count=0&output_mode=json", Status: 200, Body: `{"entry":[{"name":"dmc_group_indexer"},{"name":"dmc_indexerclustergroup_idxc"},{"name":"dmc_indexerclustergroup_old"}]}`},
+		{Method: "DELETE", URL: "https://localhost:8089/services/search/distributed/groups/dmc_indexerclustergroup_old", Status: 200},
+	}...)
+	mcClient := splclient.NewSplunkClient("https://localhost:8089", "admin", "p@ssw0rd")
+	mcClient.Client = mockSplunkClient
+
+	peers := map[string]splclient.MCDistributedPeers{
+		"splunk1.example.com:8089": {ServerRoles: []string{"indexer"}, ClusterLabel: []string{"idxc"}},
+	}
+	err := removeStaleDMCClusteringLabelGroups(ctx, mcClient, peers)
+	if err != nil {
+		t.Errorf("removeStaleDMCClusteringLabelGroups() returned error %v", err)
+	}
+	mockSplunkClient.CheckRequests(t, "TestRemoveStaleDMCClusteringLabelGroups")
+}
+
+func TestGetDMCPeersRev(t *testing.T) {
+	peers := map[string]splclient.MCDistributedPeers{
+		"splunk1.example.com:8089": {ServerRoles: []string{"indexer", "search_head"}, ClusterLabel: []string{"idxc"}, Status: "Up"},
+		"splunk2.example.com:8089": {ServerRoles: []string{"license_master"}},
+	}
+	rev := getDMCPeersRev(peers)
+
+	// the order of the roles and the peer status don't matter
+	peers["splunk1.example.com:8089"] = splclient.MCDistributedPeers{ServerRoles: []string{"search_head", "indexer"}, ClusterLabel: []string{"idxc"}, Status: "Down"}
+	if getDMCPeersRev(peers) != rev {
+		t.Errorf("getDMCPeersRev() should not change when only the order of roles or the status changes")
+	}
+
+	peers["splunk1.example.com:8089"] = splclient.MCDistributedPeers{ServerRoles: []string{"indexer"}, ClusterLabel: []string{"idxc"}}
+	if getDMCPeersRev(peers) == rev {
+		t.Errorf("getDMCPeersRev() should change when the roles change")
+	}
+}
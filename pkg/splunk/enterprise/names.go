@@ -38,12 +38,33 @@ const (
 	// identifier, instanceType, "headless" or "service"
 	serviceTemplateStr = "splunk-%s-%s-%s"
 
+	// identifier, instanceType, traffic (ex: splunkweb, hec)
+	trafficRouteTemplateStr = "splunk-%s-%s-%s-route"
+
 	// identifier
 	defaultsTemplateStr = "splunk-%s-%s-defaults"
 
 	// identifier
 	smartstoreTemplateStr = "splunk-%s-%s-smartstore"
 
+	// identifier, instanceType, token name
+	hecTokenSecretTemplateStr = "splunk-%s-%s-hec-token-%s"
+
+	// identifier, instanceType
+	hecDefaultsTemplateStr = "splunk-%s-%s-hec-defaults"
+
+	// identifier, instanceType
+	tlsSecretTemplateStr = "splunk-%s-%s-tls"
+
+	// identifier, instanceType, port (ex: mgmt, web)
+	certificateTemplateStr = "splunk-%s-%s-%s-certificate"
+
+	// identifier
+	serverClassConfigMapTemplateStr = "splunk-%s-deployment-server-serverclass"
+
+	// identifier, instanceType
+	planConfigMapTemplateStr = "splunk-%s-%s-plan"
+
 	// identifier
 	probeConfigMapTemplateStr = "splunk-%s-probe-configmap"
 
@@ -89,6 +110,15 @@ const (
 	// default docker image used for Splunk instances
 	defaultSplunkImage = "splunk/splunk"
 
+	// default docker image used for the health agent of the Splunk instances
+	defaultHealthAgentImage = "splunk/splunk-operator"
+
+	// name of the health agent container of the Splunk pods
+	healthAgentContainerName = "health-agent"
+
+	// path of the health agent binary in its image
+	healthAgentCommand = "/health-agent"
+
 	// identifier used for S3 access key
 	s3AccessKey = "s3_access_key"
 
@@ -101,10 +131,36 @@ const (
 	// identifier to track the smartstore config rev. on Pod
 	smartStoreConfigRev = "SmartStoreConfigRev"
 
+	// identifier to track the license secrets rev. on Pod
+	licenseSecretsRev = "LicenseSecretsRev"
+
+	// mount path of the license files from secrets on the license manager
+	licenseSecretsMountPath = "/mnt/splunk-licenses"
+
+	// license stack used by license pools which don't specify one
+	defaultLicenseStackID = "enterprise"
+
 	manualAppUpdateCMStr = "splunk-%s-manual-app-update"
 
 	applySHCBundleCmdStr = "/opt/splunk/bin/splunk apply shcluster-bundle -target https://%s:8089 -auth admin:`cat /mnt/splunk-secrets/password` --answer-yes -push-default-apps true &> %s &"
 
+	// directory holding the KV store backup archives on a search head
+	kvStoreBackupDir = "/opt/splunk/var/lib/splunk/kvstorebackup"
+
+	// prefix of the KV store backup archives taken by the operator
+	kvStoreBackupArchivePrefix = "splunk-operator-kvstore-"
+
+	// number of KV store backup archives taken by the operator to keep by default
+	defaultKVStoreMaxBackups = 7
+
+	backupKVStoreCmdStr = "/opt/splunk/bin/splunk backup kvstore -archiveName %s -auth admin:`cat /mnt/splunk-secrets/password`"
+
+	restoreKVStoreCmdStr = "/opt/splunk/bin/splunk restore kvstore -archiveName %s -auth admin:`cat /mnt/splunk-secrets/password`"
+
+	resyncKVStoreCmdStr = "/opt/splunk/bin/splunk resync kvstore -auth admin:`cat /mnt/splunk-secrets/password`"
+
+	pruneKVStoreBackupsCmdStr = "ls -1t " + kvStoreBackupDir + "/" + kvStoreBackupArchivePrefix + "*.tar.gz 2>/dev/null | tail -n +%d | xargs -r rm -f"
+
 	shcBundlePushCompleteStr = "Bundle has been pushed successfully to all the cluster members.\n"
 
 	shcBundlePushStatusCheckFile = "/operator-staging/appframework/.shcluster_bundle_status.txt"
@@ -119,6 +175,10 @@ const (
 
 	idxcAppsLocationOnClusterManager = "/opt/splunk/etc/manager-apps/"
 
+	deploymentAppsLocationOnDeploymentServer = "/opt/splunk/etc/deployment-apps/"
+
+	reloadDeploymentServerCmdStr = "/opt/splunk/bin/splunk reload deploy-server -auth admin:`cat /mnt/splunk-secrets/password`"
+
 	// command to append FS permissions to +rw-rw-
 	cmdSetFilePermissionsToRW = "chmod +660 -R %s"
 
@@ -146,6 +206,9 @@ const (
 	protoHTTPS = "https"
 	protoTCP   = "tcp"
 
+	// name of the Splunk instance container, which is the first container of the pods
+	splunkContainerName = "splunk"
+
 	// Volume name for splunk containers to store apps temporarily
 	appVolumeMntName = "operator-staging"
 
@@ -235,6 +298,51 @@ func GetSplunkServiceName(instanceType InstanceType, identifier string, isHeadle
 	return result
 }
 
+// GetSplunkHecServiceName uses a template to name the Kubernetes Service used for HTTP Event Collector traffic.
+func GetSplunkHecServiceName(instanceType InstanceType, identifier string) string {
+	return fmt.Sprintf(serviceTemplateStr, identifier, instanceType, hecPort)
+}
+
+// GetSplunkTrafficServiceName uses a template to name the Kubernetes Service of one type of traffic, e.g. splunkweb.
+func GetSplunkTrafficServiceName(instanceType InstanceType, identifier string, traffic string) string {
+	return fmt.Sprintf(serviceTemplateStr, identifier, instanceType, traffic)
+}
+
+// GetSplunkTrafficRouteName uses a template to name the Gateway API route exposing the Service of one type of traffic.
+func GetSplunkTrafficRouteName(instanceType InstanceType, identifier string, traffic string) string {
+	return fmt.Sprintf(trafficRouteTemplateStr, identifier, instanceType, traffic)
+}
+
+// GetSplunkHecTokenSecretName uses a template to name the Kubernetes Secret holding a named HEC token.
+func GetSplunkHecTokenSecretName(identifier string, instanceType InstanceType, tokenName string) string {
+	return fmt.Sprintf(hecTokenSecretTemplateStr, identifier, instanceType.ToKind(), tokenName)
+}
+
+// GetSplunkHecDefaultsName uses a template to name the Kubernetes Secret holding the rendered HEC inputs.
+func GetSplunkHecDefaultsName(identifier string, instanceType InstanceType) string {
+	return fmt.Sprintf(hecDefaultsTemplateStr, identifier, instanceType.ToKind())
+}
+
+// GetSplunkTLSSecretName uses a template to name the Kubernetes Secret holding the certificates mounted into the Splunk pods.
+func GetSplunkTLSSecretName(identifier string, instanceType InstanceType) string {
+	return fmt.Sprintf(tlsSecretTemplateStr, identifier, instanceType.ToKind())
+}
+
+// GetSplunkCertificateName uses a template to name the cert-manager Certificate of a port, and the Secret it is issued to.
+func GetSplunkCertificateName(identifier string, instanceType InstanceType, port string) string {
+	return fmt.Sprintf(certificateTemplateStr, identifier, instanceType.ToKind(), port)
+}
+
+// GetSplunkServerClassConfigMapName uses a template to name the Kubernetes ConfigMap holding the rendered serverclass.conf of a deployment server.
+func GetSplunkServerClassConfigMapName(identifier string) string {
+	return fmt.Sprintf(serverClassConfigMapTemplateStr, identifier)
+}
+
+// GetSplunkPlanConfigMapName uses a template to name the ConfigMap reporting the plan of a Splunk Enterprise resource.
+func GetSplunkPlanConfigMapName(identifier string, instanceType InstanceType) string {
+	return fmt.Sprintf(planConfigMapTemplateStr, identifier, instanceType)
+}
+
 // GetSplunkDefaultsName uses a template to name a Kubernetes ConfigMap for a SplunkEnterprise resource.
 func GetSplunkDefaultsName(identifier string, instanceType InstanceType) string {
 	return fmt.Sprintf(defaultsTemplateStr, identifier, instanceType.ToKind())
@@ -296,6 +404,18 @@ func GetSplunkImage(specImage string) string {
 	return name
 }
 
+// GetHealthAgentImage returns the docker image to use for the health agent of Splunk instances.
+func GetHealthAgentImage(specImage string) string {
+	if specImage != "" {
+		return specImage
+	}
+	name := os.Getenv("RELATED_IMAGE_SPLUNK_HEALTH_AGENT")
+	if name == "" {
+		name = defaultHealthAgentImage
+	}
+	return name
+}
+
 // GetPortName uses a template to enrich a port name with protocol information for usage with mesh services
 func GetPortName(port string, protocol string) string {
 	return fmt.Sprintf(portNameTemplateStr, protocol, port)
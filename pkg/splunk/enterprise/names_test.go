@@ -119,3 +119,21 @@ func TestGetSplunkImage(t *testing.T) {
 	specImage = "splunk/splunk-test"
 	test("splunk/splunk-test")
 }
+
+func TestGetSplunkHecNames(t *testing.T) {
+	if got, want := GetSplunkHecServiceName(SplunkIndexer, "t1"), "splunk-t1-indexer-hec"; got != want {
+		t.Errorf("GetSplunkHecServiceName() = %s; want %s", got, want)
+	}
+	if got, want := GetSplunkHecTokenSecretName("t1", SplunkIndexer, "ingest"), "splunk-t1-indexer-hec-token-ingest"; got != want {
+		t.Errorf("GetSplunkHecTokenSecretName() = %s; want %s", got, want)
+	}
+	if got, want := GetSplunkHecDefaultsName("t1", SplunkStandalone), "splunk-t1-standalone-hec-defaults"; got != want {
+		t.Errorf("GetSplunkHecDefaultsName() = %s; want %s", got, want)
+	}
+}
+
+func TestGetSplunkServerClassConfigMapName(t *testing.T) {
+	if got, want := GetSplunkServerClassConfigMapName("t1"), "splunk-t1-deployment-server-serverclass"; got != want {
+		t.Errorf("GetSplunkServerClassConfigMapName() = %s; want %s", got, want)
+	}
+}
@@ -0,0 +1,340 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+)
+
+// PlanOnly makes the operator report the changes of all custom resources instead of making them, as if all of them
+// had the plan annotation
+var PlanOnly = false
+
+// IsPlanRequested returns true if the changes a reconcile of a custom resource would make are to be reported instead
+// of made. Custom resources being deleted are always reconciled
+func IsPlanRequested(cr splcommon.MetaObject) bool {
+	if cr.GetDeletionTimestamp() != nil {
+		return false
+	}
+	_, ok := cr.GetAnnotations()[enterpriseApi.PlanAnnotation]
+	return ok || PlanOnly
+}
+
+// ApplyPlan runs the parts of the reconcile of a custom resource that manage its Kubernetes objects against the live
+// cluster without writing to it, and reports the resulting changes and the pods that would be recycled or removed in
+// a ConfigMap. Steps talking to the Splunk instances, like app installs or cluster maintenance, are never planned
+func ApplyPlan(ctx context.Context, client splcommon.ControllerClient, cr splcommon.MetaObject) (reconcile.Result, error) {
+	ctx, span := tracing.Start(ctx, "ApplyPlan")
+	defer span.End()
+
+	result := reconcile.Result{
+		Requeue:      true,
+		RequeueAfter: time.Second * 30,
+	}
+
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("ApplyPlan").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+	eventPublisher, _ := newK8EventPublisher(cr)
+
+	planClient := splctrl.NewPlanClient(client)
+	instanceType, err := planSplunkResources(ctx, planClient, cr)
+	if err != nil {
+		scopedLog.Error(err, "Failed to plan the reconcile")
+		eventPublisher.Warning(ctx, PlanFailed, fmt.Sprintf("plan failed %s", err.Error()))
+		return result, err
+	}
+
+	plan, err := yaml.Marshal(&planClient.Plan)
+	if err != nil {
+		return result, err
+	}
+	summary := fmt.Sprintf("%d changes, %d pods to recycle, %d pods to remove",
+		len(planClient.Plan.Changes), len(planClient.Plan.RecyclePods), len(planClient.Plan.RemovePods))
+
+	configMap := splctrl.PrepareConfigMap(GetSplunkPlanConfigMapName(cr.GetName(), instanceType), cr.GetNamespace(), map[string]string{
+		"plan.yaml": string(plan),
+		"summary":   summary,
+	})
+	configMap.SetOwnerReferences(append(configMap.GetOwnerReferences(), splcommon.AsOwner(cr, true)))
+	dataUpdated, err := splctrl.ApplyConfigMap(ctx, client, configMap)
+	if err != nil {
+		return result, err
+	}
+	if dataUpdated {
+		scopedLog.Info("Updated plan", "summary", summary)
+		eventPublisher.Normal(ctx, Planned, summary)
+	}
+	return result, nil
+}
+
+// planSplunkResources applies the Kubernetes objects of a custom resource through a PlanClient, and returns the
+// instance type used to name the plan
+func planSplunkResources(ctx context.Context, c *splctrl.PlanClient, cr splcommon.MetaObject) (InstanceType, error) {
+	switch cr := cr.(type) {
+	case *enterpriseApi.Standalone:
+		err := validateStandaloneSpec(ctx, c, cr)
+		if err == nil {
+			err = planSmartstoreConfigMap(ctx, c, cr, &cr.Spec.SmartStore, &cr.Status.SmartStore)
+		}
+		if err == nil {
+			err = planSplunkConfig(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone,
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone, true),
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone, false))
+		}
+		if err == nil {
+			err = ApplySplunkTrafficServices(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone, cr.Spec.Hec.Enabled)
+		}
+		if err == nil {
+			err = planSplunkTLS(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone)
+		}
+		if err == nil {
+			_, err = ApplyHecConfig(ctx, c, cr, &cr.Spec.CommonSplunkSpec, &cr.Spec.Hec, SplunkStandalone)
+		}
+		if err == nil {
+			err = planStatefulSet(ctx, c, func() (*appsv1.StatefulSet, error) { return getStandaloneStatefulSet(ctx, c, cr) })
+		}
+		return SplunkStandalone, err
+
+	case *enterpriseApi.ClusterManager:
+		err := validateClusterManagerSpec(ctx, c, cr)
+		if err == nil {
+			err = planSmartstoreConfigMap(ctx, c, cr, &cr.Spec.SmartStore, &cr.Status.SmartStore)
+		}
+		if err == nil {
+			err = planSplunkConfig(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer,
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, getClusterManagerInstanceType(cr), false))
+		}
+		if err == nil {
+			err = ApplySplunkTrafficServices(ctx, c, cr, &cr.Spec.CommonSplunkSpec, getClusterManagerInstanceType(cr), false)
+		}
+		if err == nil {
+			err = planSplunkTLS(ctx, c, cr, &cr.Spec.CommonSplunkSpec, getClusterManagerInstanceType(cr))
+		}
+		if err == nil {
+			err = planStatefulSet(ctx, c, func() (*appsv1.StatefulSet, error) { return getClusterManagerStatefulSet(ctx, c, cr) })
+		}
+		return getClusterManagerInstanceType(cr), err
+
+	case *enterpriseApiV3.ClusterMaster:
+		err := validateClusterMasterSpec(ctx, c, cr)
+		if err == nil {
+			err = planSmartstoreConfigMap(ctx, c, cr, &cr.Spec.SmartStore, &cr.Status.SmartStore)
+		}
+		if err == nil {
+			err = planSplunkConfig(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer,
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, false),
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkClusterMaster, false))
+		}
+		if err == nil {
+			err = ApplySplunkTrafficServices(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkClusterMaster, false)
+		}
+		if err == nil {
+			err = planSplunkTLS(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkClusterMaster)
+		}
+		if err == nil {
+			err = planStatefulSet(ctx, c, func() (*appsv1.StatefulSet, error) { return getClusterMasterStatefulSet(ctx, c, cr) })
+		}
+		return SplunkClusterMaster, err
+
+	case *enterpriseApi.IndexerCluster:
+		err := validateIndexerClusterSpec(ctx, c, cr)
+		if err == nil {
+			err = planSplunkConfig(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer,
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, true),
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, false))
+		}
+		if err == nil {
+			err = ApplySplunkTrafficServices(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer, cr.Spec.Hec.Enabled)
+		}
+		if err == nil {
+			err = planSplunkTLS(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkIndexer)
+		}
+		if err == nil {
+			_, err = ApplyHecConfig(ctx, c, cr, &cr.Spec.CommonSplunkSpec, &cr.Spec.Hec, SplunkIndexer)
+		}
+		if err == nil {
+			err = planStatefulSet(ctx, c, func() (*appsv1.StatefulSet, error) { return getIndexerStatefulSet(ctx, c, cr) })
+		}
+		return SplunkIndexer, err
+
+	case *enterpriseApi.SearchHeadCluster:
+		err := validateSearchHeadClusterSpec(ctx, c, cr)
+		if err == nil {
+			err = planSplunkConfig(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkSearchHead,
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkSearchHead, true),
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkSearchHead, false),
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkDeployer, false))
+		}
+		if err == nil {
+			err = ApplySplunkTrafficServices(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkSearchHead, false)
+		}
+		if err == nil {
+			err = planSplunkTLS(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkSearchHead)
+		}
+		if err == nil {
+			err = planSplunkTLS(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkDeployer)
+		}
+		if err == nil {
+			err = planStatefulSet(ctx, c, func() (*appsv1.StatefulSet, error) { return getDeployerStatefulSet(ctx, c, cr) })
+		}
+		if err == nil {
+			err = planStatefulSet(ctx, c, func() (*appsv1.StatefulSet, error) { return getSearchHeadStatefulSet(ctx, c, cr) })
+		}
+		return SplunkSearchHead, err
+
+	case *enterpriseApi.LicenseManager:
+		err := validateLicenseManagerSpec(ctx, c, cr)
+		if err == nil {
+			err = planSplunkConfig(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkLicenseManager,
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkLicenseManager, false))
+		}
+		if err == nil {
+			err = ApplySplunkTrafficServices(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkLicenseManager, false)
+		}
+		if err == nil {
+			err = planSplunkTLS(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkLicenseManager)
+		}
+		if err == nil {
+			err = planStatefulSet(ctx, c, func() (*appsv1.StatefulSet, error) { return getLicenseManagerStatefulSet(ctx, c, cr) })
+		}
+		return SplunkLicenseManager, err
+
+	case *enterpriseApiV3.LicenseMaster:
+		err := validateLicenseMasterSpec(ctx, c, cr)
+		if err == nil {
+			err = planSplunkConfig(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkLicenseMaster,
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkLicenseMaster, false))
+		}
+		if err == nil {
+			err = ApplySplunkTrafficServices(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkLicenseMaster, false)
+		}
+		if err == nil {
+			err = planSplunkTLS(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkLicenseMaster)
+		}
+		if err == nil {
+			err = planStatefulSet(ctx, c, func() (*appsv1.StatefulSet, error) { return getLicenseMasterStatefulSet(ctx, c, cr) })
+		}
+		return SplunkLicenseMaster, err
+
+	case *enterpriseApi.MonitoringConsole:
+		err := validateMonitoringConsoleSpec(ctx, c, cr)
+		if err == nil {
+			err = planSplunkConfig(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole,
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole, true),
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole, false))
+		}
+		if err == nil {
+			err = ApplySplunkTrafficServices(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole, false)
+		}
+		if err == nil {
+			err = planSplunkTLS(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkMonitoringConsole)
+		}
+		if err == nil {
+			err = planStatefulSet(ctx, c, func() (*appsv1.StatefulSet, error) { return getMonitoringConsoleStatefulSet(ctx, c, cr) })
+		}
+		return SplunkMonitoringConsole, err
+
+	case *enterpriseApi.DeploymentServer:
+		err := validateDeploymentServerSpec(ctx, c, cr)
+		if err == nil {
+			err = planSplunkConfig(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkDeploymentServer,
+				getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkDeploymentServer, false))
+		}
+		if err == nil {
+			err = ApplySplunkTrafficServices(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkDeploymentServer, false)
+		}
+		if err == nil {
+			err = planSplunkTLS(ctx, c, cr, &cr.Spec.CommonSplunkSpec, SplunkDeploymentServer)
+		}
+		if err == nil {
+			err = ApplyServerClassConfigMap(ctx, c, cr)
+		}
+		if err == nil {
+			err = planStatefulSet(ctx, c, func() (*appsv1.StatefulSet, error) { return getDeploymentServerStatefulSet(ctx, c, cr) })
+		}
+		return SplunkDeploymentServer, err
+	}
+
+	return "", fmt.Errorf("plans are not supported for %s", reflect.TypeOf(cr))
+}
+
+// planSmartstoreConfigMap applies the smartstore ConfigMap if the smartstore configuration changed
+func planSmartstoreConfigMap(ctx context.Context, c *splctrl.PlanClient, cr splcommon.MetaObject, spec, status *enterpriseApi.SmartStoreSpec) error {
+	if reflect.DeepEqual(*status, *spec) {
+		return nil
+	}
+	_, _, err := ApplySmartstoreConfigMap(ctx, c, cr, spec)
+	return err
+}
+
+// planSplunkConfig applies the general config resources and the services of a custom resource
+func planSplunkConfig(ctx context.Context, c *splctrl.PlanClient, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec, configType InstanceType, services ...*corev1.Service) error {
+	_, err := ApplySplunkConfig(ctx, c, cr, *spec, configType)
+	if err != nil {
+		return err
+	}
+
+	for _, service := range services {
+		err = splctrl.ApplyService(ctx, c, service)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// planSplunkTLS applies the certificates of an instance type and the secret mounting them
+func planSplunkTLS(ctx context.Context, c *splctrl.PlanClient, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec, instanceType InstanceType) error {
+	_, err := applySplunkTLSSecret(ctx, c, cr, spec, instanceType)
+	if k8serrors.IsNotFound(err) {
+		// the secrets of planned certificates are only issued once they are applied
+		return nil
+	}
+	return err
+}
+
+// planStatefulSet applies a StatefulSet and adds the pods it would recycle or remove to the plan
+func planStatefulSet(ctx context.Context, c *splctrl.PlanClient, getStatefulSet func() (*appsv1.StatefulSet, error)) error {
+	statefulSet, err := getStatefulSet()
+	if err != nil {
+		return err
+	}
+
+	var replicas int32
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	_, err = splctrl.ApplyStatefulSet(ctx, c, statefulSet)
+	if err != nil {
+		return err
+	}
+	return c.PlanStatefulSetPods(ctx, statefulSet, replicas)
+}
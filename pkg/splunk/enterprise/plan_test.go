@@ -0,0 +1,109 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	spltest "github.com/splunk/splunk-operator/pkg/splunk/test"
+)
+
+func TestIsPlanRequested(t *testing.T) {
+	cr := &enterpriseApi.Standalone{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	if IsPlanRequested(cr) {
+		t.Errorf("IsPlanRequested() = true without the annotation")
+	}
+
+	cr.Annotations = map[string]string{enterpriseApi.PlanAnnotation: ""}
+	if !IsPlanRequested(cr) {
+		t.Errorf("IsPlanRequested() = false with the annotation")
+	}
+
+	now := metav1.NewTime(time.Now())
+	cr.DeletionTimestamp = &now
+	if IsPlanRequested(cr) {
+		t.Errorf("IsPlanRequested() = true for a custom resource being deleted")
+	}
+
+	cr.DeletionTimestamp = nil
+	cr.Annotations = nil
+	PlanOnly = true
+	defer func() { PlanOnly = false }()
+	if !IsPlanRequested(cr) {
+		t.Errorf("IsPlanRequested() = false with PlanOnly")
+	}
+}
+
+func TestApplyPlan(t *testing.T) {
+	ctx := context.TODO()
+	c := spltest.NewMockClient()
+	cr := &enterpriseApi.Standalone{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "Standalone",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "stack1",
+			Namespace:   "test",
+			Annotations: map[string]string{enterpriseApi.PlanAnnotation: ""},
+		},
+	}
+	c.AddObject(cr)
+
+	_, err := ApplyPlan(ctx, c, cr)
+	if err != nil {
+		t.Errorf("ApplyPlan() returned error: %v", err)
+	}
+
+	// only the plan is written
+	for _, call := range c.Calls["Create"] {
+		if _, ok := call.Obj.(*corev1.ConfigMap); !ok || call.Obj.GetName() != "splunk-stack1-standalone-plan" {
+			t.Errorf("ApplyPlan() created %s %s", call.Obj.GetObjectKind().GroupVersionKind().Kind, call.Obj.GetName())
+		}
+	}
+	for _, method := range []string{"Update", "Patch", "Delete"} {
+		if len(c.Calls[method]) != 0 {
+			t.Errorf("ApplyPlan() called %s", method)
+		}
+	}
+
+	plan := &corev1.ConfigMap{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: "test", Name: GetSplunkPlanConfigMapName("stack1", SplunkStandalone)}, plan)
+	if err != nil {
+		t.Errorf("ApplyPlan() did not write the plan: %v", err)
+	}
+	for _, want := range []string{"kind: StatefulSet", "name: splunk-stack1-standalone", "action: Create", "kind: Service"} {
+		if !strings.Contains(plan.Data["plan.yaml"], want) {
+			t.Errorf("ApplyPlan() plan does not contain %q:\n%s", want, plan.Data["plan.yaml"])
+		}
+	}
+	want := "7 changes, 0 pods to recycle, 0 pods to remove"
+	if plan.Data["summary"] != want {
+		t.Errorf("ApplyPlan() summary = %q; want %q", plan.Data["summary"], want)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ClusterMemberReadyCondition is the pod condition of the readiness gate of the indexer cluster peers and of the
+// search head cluster members, set by the operator once the pod has joined its cluster
+const ClusterMemberReadyCondition corev1.PodConditionType = "splunk.com/cluster-member-ready"
+
+// getReadinessGates returns the readiness gates of the pods of an instance type
+func getReadinessGates(instanceType InstanceType) []corev1.PodReadinessGate {
+	switch instanceType {
+	case SplunkIndexer, SplunkSearchHead:
+		return []corev1.PodReadinessGate{{ConditionType: ClusterMemberReadyCondition}}
+	}
+	return nil
+}
+
+// hasClusterMemberReadinessGate returns true if the pods of a pod spec wait for the cluster member ready condition
+func hasClusterMemberReadinessGate(podSpec *corev1.PodSpec) bool {
+	for _, gate := range podSpec.ReadinessGates {
+		if gate.ConditionType == ClusterMemberReadyCondition {
+			return true
+		}
+	}
+	return false
+}
+
+// updateClusterMemberReadyCondition sets the cluster member ready condition of a pod, if the pod has its readiness gate
+// and the condition changed
+func updateClusterMemberReadyCondition(ctx context.Context, c splcommon.ControllerClient, namespace, podName string, ready bool, reason, message string) error {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("updateClusterMemberReadyCondition").WithValues("podName", podName, "ready", ready)
+
+	var pod corev1.Pod
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: podName}, &pod)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !hasClusterMemberReadinessGate(&pod.Spec) {
+		return nil
+	}
+
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	condition := corev1.PodCondition{
+		Type:               ClusterMemberReadyCondition,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+
+	original := pod.DeepCopy()
+	found := false
+	for i := range pod.Status.Conditions {
+		if pod.Status.Conditions[i].Type != ClusterMemberReadyCondition {
+			continue
+		}
+		if pod.Status.Conditions[i].Status == status {
+			return nil
+		}
+		pod.Status.Conditions[i] = condition
+		found = true
+	}
+	if !found {
+		pod.Status.Conditions = append(pod.Status.Conditions, condition)
+	}
+
+	// the kubelet updates the other conditions, so only the cluster member ready condition is patched
+	err = c.Status().Patch(ctx, &pod, client.StrategicMergeFrom(original))
+	if err != nil {
+		scopedLog.Error(err, "Failed to update the cluster member ready condition")
+		return err
+	}
+	scopedLog.Info("Updated the cluster member ready condition", "reason", reason)
+	return nil
+}
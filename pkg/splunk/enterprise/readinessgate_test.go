@@ -0,0 +1,100 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetReadinessGates(t *testing.T) {
+	for _, instanceType := range []InstanceType{SplunkIndexer, SplunkSearchHead} {
+		podSpec := corev1.PodSpec{ReadinessGates: getReadinessGates(instanceType)}
+		if !hasClusterMemberReadinessGate(&podSpec) {
+			t.Errorf("getReadinessGates(%s) = %v; want %s", instanceType, podSpec.ReadinessGates, ClusterMemberReadyCondition)
+		}
+	}
+	for _, instanceType := range []InstanceType{SplunkStandalone, SplunkClusterManager, SplunkDeployer, SplunkLicenseManager, SplunkMonitoringConsole} {
+		if gates := getReadinessGates(instanceType); len(gates) != 0 {
+			t.Errorf("getReadinessGates(%s) = %v; want none", instanceType, gates)
+		}
+	}
+}
+
+func TestUpdateClusterMemberReadyCondition(t *testing.T) {
+	ctx := context.TODO()
+	gatedPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "splunk-stack1-indexer-0", Namespace: "test"},
+		Spec:       corev1.PodSpec{ReadinessGates: getReadinessGates(SplunkIndexer)},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{Type: corev1.ContainersReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "splunk-stack1-indexer-1", Namespace: "test"},
+	}
+	c := fake.NewClientBuilder().WithObjects(gatedPod, pod).Build()
+
+	getCondition := func(podName string) *corev1.PodCondition {
+		var got corev1.Pod
+		if err := c.Get(ctx, types.NamespacedName{Namespace: "test", Name: podName}, &got); err != nil {
+			t.Fatalf("Get(%s) returned error: %v", podName, err)
+		}
+		for i := range got.Status.Conditions {
+			if got.Status.Conditions[i].Type == ClusterMemberReadyCondition {
+				return &got.Status.Conditions[i]
+			}
+		}
+		return nil
+	}
+
+	if err := updateClusterMemberReadyCondition(ctx, c, "test", gatedPod.GetName(), false, "PeerNotKnown", "peer is not known by the cluster manager"); err != nil {
+		t.Errorf("updateClusterMemberReadyCondition() returned error: %v", err)
+	}
+	if got := getCondition(gatedPod.GetName()); got == nil || got.Status != corev1.ConditionFalse || got.Reason != "PeerNotKnown" {
+		t.Errorf("updateClusterMemberReadyCondition() condition = %v; want False", got)
+	}
+
+	if err := updateClusterMemberReadyCondition(ctx, c, "test", gatedPod.GetName(), true, "PeerStatus", "peer status is Up"); err != nil {
+		t.Errorf("updateClusterMemberReadyCondition() returned error: %v", err)
+	}
+	if got := getCondition(gatedPod.GetName()); got == nil || got.Status != corev1.ConditionTrue || got.Message != "peer status is Up" {
+		t.Errorf("updateClusterMemberReadyCondition() condition = %v; want True", got)
+	}
+
+	// the conditions of the kubelet are kept
+	var got corev1.Pod
+	c.Get(ctx, types.NamespacedName{Namespace: "test", Name: gatedPod.GetName()}, &got)
+	if len(got.Status.Conditions) != 2 || got.Status.Conditions[0].Type != corev1.ContainersReady {
+		t.Errorf("updateClusterMemberReadyCondition() conditions = %v; want ContainersReady kept", got.Status.Conditions)
+	}
+
+	// pods without the readiness gate and missing pods are left alone
+	if err := updateClusterMemberReadyCondition(ctx, c, "test", pod.GetName(), true, "PeerStatus", "peer status is Up"); err != nil {
+		t.Errorf("updateClusterMemberReadyCondition() returned error: %v", err)
+	}
+	if got := getCondition(pod.GetName()); got != nil {
+		t.Errorf("updateClusterMemberReadyCondition() condition = %v; want none without readiness gate", got)
+	}
+	if err := updateClusterMemberReadyCondition(ctx, c, "test", "splunk-stack1-indexer-2", true, "PeerStatus", "peer status is Up"); err != nil {
+		t.Errorf("updateClusterMemberReadyCondition() returned error: %v", err)
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
@@ -26,12 +27,15 @@ import (
 
 	"github.com/go-logr/logr"
 
+	"github.com/splunk/splunk-operator/pkg/splunk/audit"
 	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/remotecommand"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -41,6 +45,9 @@ import (
 
 // ApplySearchHeadCluster reconciles the state for a Splunk Enterprise search head cluster.
 func ApplySearchHeadCluster(ctx context.Context, client splcommon.ControllerClient, cr *enterpriseApi.SearchHeadCluster) (reconcile.Result, error) {
+	ctx, span := tracing.Start(ctx, "ApplySearchHeadCluster")
+	defer span.End()
+
 	// unless modified, reconcile for this object will be requeued after 5 seconds
 	result := reconcile.Result{
 		Requeue:      true,
@@ -48,7 +55,9 @@ func ApplySearchHeadCluster(ctx context.Context, client splcommon.ControllerClie
 	}
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("ApplySearchHeadCluster")
-	eventPublisher, _ := newK8EventPublisher(client, cr)
+	eventPublisher, _ := newK8EventPublisher(cr)
+	defer eventPublisher.publishPhaseChange(ctx, cr.Status.Phase, &cr.Status.Phase)
+	ctx = audit.NewContext(ctx, cr, &cr.Status.AuditTrail)
 
 	// validate and updates defaults for CR
 	err := validateSearchHeadClusterSpec(ctx, client, cr)
@@ -68,7 +77,7 @@ func ApplySearchHeadCluster(ctx context.Context, client splcommon.ControllerClie
 	if len(cr.Spec.AppFrameworkConfig.AppSources) != 0 {
 		err := initAndCheckAppInfoStatus(ctx, client, cr, &cr.Spec.AppFrameworkConfig, &cr.Status.AppContext)
 		if err != nil {
-			eventPublisher.Warning(ctx, "initAndCheckAppInfoStatus", fmt.Sprintf("init and check app info status failed %s", err.Error()))
+			eventPublisher.Warning(ctx, AppRepoCheckFailed, fmt.Sprintf("init and check app info status failed %s", err.Error()))
 			cr.Status.AppContext.IsDeploymentInProgress = false
 			return result, err
 		}
@@ -99,7 +108,7 @@ func ApplySearchHeadCluster(ctx context.Context, client splcommon.ControllerClie
 	namespaceScopedSecret, err := ApplySplunkConfig(ctx, client, cr, cr.Spec.CommonSplunkSpec, SplunkSearchHead)
 	if err != nil {
 		scopedLog.Error(err, "create or update general config failed", "error", err.Error())
-		eventPublisher.Warning(ctx, "ApplySplunkConfig", fmt.Sprintf("create or update general config failed with error %s", err.Error()))
+		eventPublisher.Warning(ctx, ConfigApplyFailed, fmt.Sprintf("create or update general config failed with error %s", err.Error()))
 		return result, err
 	}
 
@@ -131,7 +140,7 @@ func ApplySearchHeadCluster(ctx context.Context, client splcommon.ControllerClie
 			result.Requeue = false
 		}
 		if err != nil {
-			eventPublisher.Warning(ctx, "Delete", fmt.Sprintf("delete custom resource failed %s", err.Error()))
+			eventPublisher.Warning(ctx, DeleteFailed, fmt.Sprintf("delete custom resource failed %s", err.Error()))
 		}
 		return result, err
 	}
@@ -148,6 +157,24 @@ func ApplySearchHeadCluster(ctx context.Context, client splcommon.ControllerClie
 		return result, err
 	}
 
+	// create or update the services of each type of traffic and their routes
+	err = ApplySplunkTrafficServices(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkSearchHead, false)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the certificates of the ports and the secret mounting them
+	err = ApplySplunkTLS(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkSearchHead)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the certificates of the deployer
+	err = ApplySplunkTLS(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkDeployer)
+	if err != nil {
+		return result, err
+	}
+
 	// create or update a deployer service
 	err = splctrl.ApplyService(ctx, client, getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkDeployer, false))
 	if err != nil {
@@ -223,10 +250,25 @@ func ApplySearchHeadCluster(ctx context.Context, client splcommon.ControllerClie
 			// Mark telemetry app as installed
 			cr.Status.TelAppInstalled = true
 		}
+
+		// a failed KV store backup or restore shouldn't hold back the app framework, so the error is only reported
+		podExecClient := splutil.GetPodExecClient(client, cr, GetSplunkStatefulsetPodName(SplunkSearchHead, cr.GetName(), 0))
+		nextBackup, err := reconcileKVStoreBackups(ctx, cr, podExecClient)
+		if err != nil {
+			scopedLog.Error(err, "Unable to reconcile the KV store backups")
+			eventPublisher.Warning(ctx, KVStoreBackupFailed, fmt.Sprintf("reconcile KV store backups failed %s", err.Error()))
+		}
+
 		// Update the requeue result as needed by the app framework
 		if finalResult != nil {
 			result = *finalResult
 		}
+
+		// make sure the next scheduled KV store backup isn't missed
+		if nextBackup > 0 && (!result.Requeue || result.RequeueAfter > nextBackup) {
+			result.Requeue = true
+			result.RequeueAfter = nextBackup
+		}
 	}
 	// RequeueAfter if greater than 0, tells the Controller to requeue the reconcile key after the Duration.
 	// Implies that Requeue is true, there is no need to set Requeue to true at the same time as RequeueAfter.
@@ -259,6 +301,9 @@ var newSerachHeadClusterPodManager = func(client splcommon.ControllerClient, log
 
 // ApplyShcSecret checks if any of the search heads have a different shc_secret from namespace scoped secret and changes it
 func ApplyShcSecret(ctx context.Context, mgr *searchHeadClusterPodManager, replicas int32, podExecClient splutil.PodExecClientImpl) error {
+	ctx, span := tracing.Start(ctx, "ApplyShcSecret")
+	defer span.End()
+
 	// Get namespace scoped secret
 	namespaceSecret, err := splutil.ApplyNamespaceScopedSecretObject(ctx, mgr.c, mgr.cr.GetNamespace())
 	if err != nil {
@@ -280,6 +325,7 @@ func ApplyShcSecret(ctx context.Context, mgr *searchHeadClusterPodManager, repli
 	}
 
 	scopedLog.Info("Namespaced scoped secret revision has changed")
+	eventPublisher, _ := newK8EventPublisher(mgr.cr)
 
 	// Retrieve shc_secret password from secret data
 	nsShcSecret := string(namespaceSecret.Data["shc_secret"])
@@ -327,6 +373,7 @@ func ApplyShcSecret(ctx context.Context, mgr *searchHeadClusterPodManager, repli
 			streamOptions.Stdin = strings.NewReader(command)
 
 			_, _, err = podExecClient.RunPodExecCommand(ctx, streamOptions, []string{"/bin/sh"})
+			audit.Log(ctx, audit.SecretChanged, shPodName, "shc secret changed in the namespace scoped secret", err)
 			if err != nil {
 				return err
 			}
@@ -334,11 +381,12 @@ func ApplyShcSecret(ctx context.Context, mgr *searchHeadClusterPodManager, repli
 
 			// Get client for Pod and restart splunk instance on pod
 			shClient := mgr.getClient(ctx, i)
-			err = shClient.RestartSplunk()
+			err = shClient.RestartSplunk(ctx)
 			if err != nil {
 				return err
 			}
 			scopedLog.Info("Restarted Splunk")
+			eventPublisher.Normal(ctx, SecretRotated, fmt.Sprintf("changed the shc secret of pod %s", shPodName))
 
 			// Set the shc_secret changed flag to true
 			if i < int32(len(mgr.cr.Status.ShcSecretChanged)) {
@@ -362,6 +410,7 @@ func ApplyShcSecret(ctx context.Context, mgr *searchHeadClusterPodManager, repli
 			command := fmt.Sprintf("/opt/splunk/bin/splunk cmd splunkd rest --noauth POST /services/admin/users/admin 'password=%s'", nsAdminSecret)
 			streamOptions.Stdin = strings.NewReader(command)
 			_, _, err = podExecClient.RunPodExecCommand(ctx, streamOptions, []string{"/bin/sh"})
+			audit.Log(ctx, audit.SecretChanged, shPodName, "admin password changed in the namespace scoped secret", err)
 			if err != nil {
 				return err
 			}
@@ -369,11 +418,12 @@ func ApplyShcSecret(ctx context.Context, mgr *searchHeadClusterPodManager, repli
 
 			// Get client for Pod and restart splunk instance on pod
 			shClient := mgr.getClient(ctx, i)
-			err = shClient.RestartSplunk()
+			err = shClient.RestartSplunk(ctx)
 			if err != nil {
 				return err
 			}
 			scopedLog.Info("Restarted Splunk")
+			eventPublisher.Normal(ctx, SecretRotated, fmt.Sprintf("changed the admin password of pod %s", shPodName))
 
 			// Set the adminSecretChanged changed flag to true
 			if i < int32(len(mgr.cr.Status.AdminSecretChanged)) {
@@ -409,6 +459,7 @@ func ApplyShcSecret(ctx context.Context, mgr *searchHeadClusterPodManager, repli
 			}
 			podSecret.Data["password"] = []byte(nsAdminSecret)
 			_, err = splctrl.ApplySecret(ctx, mgr.c, podSecret)
+			resetPodCredentials(mgr.cr.GetNamespace())
 			if err != nil {
 				return err
 			}
@@ -427,10 +478,13 @@ func (mgr *searchHeadClusterPodManager) Update(ctx context.Context, c splcommon.
 	}
 
 	// update statefulset, if necessary
-	_, err := splctrl.ApplyStatefulSet(ctx, mgr.c, statefulSet)
+	_, err := splctrl.ApplyStatefulSetWithVolumeExpansion(ctx, mgr.c, statefulSet, &mgr.cr.Status.VolumeExpansion)
 	if err != nil {
 		return enterpriseApi.PhaseError, err
 	}
+	if splctrl.IsVolumeExpansionInProgress(&mgr.cr.Status.VolumeExpansion) {
+		return enterpriseApi.PhaseUpdating, nil
+	}
 
 	// for now pass the targetPodName as empty since we are going to fill it in ApplyShcSecret
 	podExecClient := splutil.GetPodExecClient(mgr.c, mgr.cr, "")
@@ -464,7 +518,7 @@ func (mgr *searchHeadClusterPodManager) PrepareScaleDown(ctx context.Context, n
 	memberName := GetSplunkStatefulsetPodName(SplunkSearchHead, mgr.cr.GetName(), n)
 	mgr.log.Info("Removing member from search head cluster", "memberName", memberName)
 	c := mgr.getClient(ctx, n)
-	err = c.RemoveSearchHeadClusterMember()
+	err = c.RemoveSearchHeadClusterMember(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -483,7 +537,7 @@ func (mgr *searchHeadClusterPodManager) PrepareRecycle(ctx context.Context, n in
 		mgr.log.Info("Detaining search head cluster member", "memberName", memberName)
 		c := mgr.getClient(ctx, n)
 		podExecClient := splutil.GetPodExecClient(mgr.c, mgr.cr, getApplicablePodNameForK8Probes(mgr.cr, n))
-		err := setProbeLevelOnSplunkPod(ctx, podExecClient, livenessProbeLevelOne)
+		err := setProbeLevelOnSplunkPod(ctx, mgr.c, mgr.cr, podExecClient, livenessProbeLevelOne)
 		if err != nil {
 			// During the Recycle, our reconcile loop is entered multiple times. If the Pod is already down,
 			// there is a chance of readiness probe failing, in which case, even the podExec will not be successful.
@@ -491,7 +545,7 @@ func (mgr *searchHeadClusterPodManager) PrepareRecycle(ctx context.Context, n in
 			mgr.log.Info("Setting Probe level failed. Probably, the Pod is already down", "memberName", memberName)
 		}
 
-		return false, c.SetSearchHeadDetention(true)
+		return false, c.SetSearchHeadDetention(ctx, true)
 
 	case "ManualDetention":
 		// Wait until active searches have drained
@@ -522,10 +576,22 @@ func (mgr *searchHeadClusterPodManager) FinishRecycle(ctx context.Context, n int
 		return true, nil
 
 	case "ManualDetention":
+		// a stale KV store is resynced before the member serves searches again
+		member := &mgr.cr.Status.Members[n]
+		if needsKVStoreResync(member, time.Now()) {
+			mgr.log.Info("Resyncing stale KV store of search head cluster member", "memberName", memberName)
+			podExecClient := splutil.GetPodExecClient(mgr.c, mgr.cr, memberName)
+			return false, resyncKVStore(ctx, member, podExecClient)
+		}
+		if isKVStoreStale(member) || isKVStoreSyncing(member) {
+			mgr.log.Info("Waiting for KV store of search head cluster member to sync", "memberName", memberName, "kvStoreStatus", member.KVStoreStatus, "replicationStatus", member.KVStoreReplicationStatus)
+			return false, nil
+		}
+
 		// release from detention
 		mgr.log.Info("Releasing search head cluster member from detention", "memberName", memberName)
 		c := mgr.getClient(ctx, n)
-		return false, c.SetSearchHeadDetention(false)
+		return false, c.SetSearchHeadDetention(ctx, false)
 	}
 
 	// unhandled status
@@ -545,7 +611,7 @@ func (mgr *searchHeadClusterPodManager) getClient(ctx context.Context, n int32)
 		fmt.Sprintf("%s.%s", memberName, GetSplunkServiceName(SplunkSearchHead, mgr.cr.GetName(), true)))
 
 	// Retrieve admin password from Pod
-	adminPwd, err := splutil.GetSpecificSecretTokenFromPod(ctx, mgr.c, memberName, mgr.cr.GetNamespace(), "password")
+	adminPwd, err := getPodAdminPassword(ctx, mgr.c, mgr.cr.GetNamespace(), memberName, mgr.cr.Status.NamespaceSecretResourceVersion)
 	if err != nil {
 		scopedLog.Error(err, "Couldn't retrieve the admin password from Pod")
 	}
@@ -559,7 +625,10 @@ func (mgr *searchHeadClusterPodManager) updateStatus(ctx context.Context, statef
 	mgr.cr.Status.Captain = ""
 	mgr.cr.Status.CaptainReady = false
 	mgr.cr.Status.ReadyReplicas = statefulSet.Status.ReadyReplicas
-	if mgr.cr.Status.ReadyReplicas == 0 {
+
+	// members with a readiness gate only become ready once registered, so they are checked before any is ready
+	hasReadinessGate := hasClusterMemberReadinessGate(&statefulSet.Spec.Template.Spec)
+	if mgr.cr.Status.ReadyReplicas == 0 && !hasReadinessGate {
 		return nil
 	}
 	gotCaptainInfo := false
@@ -567,20 +636,40 @@ func (mgr *searchHeadClusterPodManager) updateStatus(ctx context.Context, statef
 		c := mgr.getClient(ctx, n)
 		memberName := GetSplunkStatefulsetPodName(SplunkSearchHead, mgr.cr.GetName(), n)
 		memberStatus := enterpriseApi.SearchHeadClusterMemberStatus{Name: memberName}
-		memberInfo, err := c.GetSearchHeadClusterMemberInfo()
+		memberInfo, err := c.GetSearchHeadClusterMemberInfo(ctx)
 		if err == nil {
 			memberStatus.Status = memberInfo.Status
 			memberStatus.Adhoc = memberInfo.Adhoc
 			memberStatus.Registered = memberInfo.Registered
 			memberStatus.ActiveHistoricalSearchCount = memberInfo.ActiveHistoricalSearchCount
 			memberStatus.ActiveRealtimeSearchCount = memberInfo.ActiveRealtimeSearchCount
+
+			kvStoreStatus, kvStoreErr := c.GetKVStoreStatus(ctx)
+			if kvStoreErr == nil {
+				memberStatus.KVStoreStatus = kvStoreStatus.Status
+				memberStatus.KVStoreReplicationStatus = kvStoreStatus.ReplicationStatus
+			} else {
+				mgr.log.Error(kvStoreErr, "Unable to retrieve KV store status", "memberName", memberName)
+			}
 		} else {
 			mgr.log.Error(err, "Unable to retrieve search head cluster member info", "memberName", memberName)
 		}
 
+		// the member is ready to serve once it is registered with the captain
+		if hasReadinessGate {
+			reason, message := "MemberNotRegistered", "member is not registered with the captain"
+			if memberStatus.Registered {
+				reason, message = "MemberRegistered", "member is registered with the captain"
+			}
+			conditionErr := updateClusterMemberReadyCondition(ctx, mgr.c, mgr.cr.GetNamespace(), memberName, memberStatus.Registered, reason, message)
+			if conditionErr != nil {
+				mgr.log.Error(conditionErr, "Unable to update the cluster member ready condition", "memberName", memberName)
+			}
+		}
+
 		if err == nil && !gotCaptainInfo {
 			// try querying captain api; note that this should work on any node
-			captainInfo, err := c.GetSearchHeadCaptainInfo()
+			captainInfo, err := c.GetSearchHeadCaptainInfo(ctx)
 			if err == nil {
 				mgr.cr.Status.Captain = captainInfo.Label
 				mgr.cr.Status.CaptainReady = captainInfo.ServiceReady
@@ -594,8 +683,10 @@ func (mgr *searchHeadClusterPodManager) updateStatus(ctx context.Context, statef
 		}
 
 		if n < int32(len(mgr.cr.Status.Members)) {
+			trackKVStoreStaleness(&memberStatus, &mgr.cr.Status.Members[n], time.Now())
 			mgr.cr.Status.Members[n] = memberStatus
 		} else {
+			trackKVStoreStaleness(&memberStatus, nil, time.Now())
 			mgr.cr.Status.Members = append(mgr.cr.Status.Members, memberStatus)
 		}
 	}
@@ -649,9 +740,148 @@ func validateSearchHeadClusterSpec(ctx context.Context, c splcommon.ControllerCl
 		}
 	}
 
+	if cr.Spec.KVStoreBackup.Period != nil && cr.Spec.KVStoreBackup.Period.Duration <= 0 {
+		return fmt.Errorf("KV store backup period must be positive")
+	}
+	if cr.Spec.KVStoreBackup.MaxBackups <= 0 {
+		cr.Spec.KVStoreBackup.MaxBackups = defaultKVStoreMaxBackups
+	}
+
 	return validateCommonSplunkSpec(ctx, c, &cr.Spec.CommonSplunkSpec, cr)
 }
 
+// isKVStoreStale returns true if the KV store of a search head cluster member fell too far behind to catch up on its own
+func isKVStoreStale(member *enterpriseApi.SearchHeadClusterMemberStatus) bool {
+	return member.KVStoreStatus == "failed" || member.KVStoreReplicationStatus == "Recovering"
+}
+
+// isKVStoreSyncing returns true if the KV store of a search head cluster member is still catching up
+func isKVStoreSyncing(member *enterpriseApi.SearchHeadClusterMemberStatus) bool {
+	return member.KVStoreStatus == "starting" || member.KVStoreReplicationStatus == "Initial sync" || member.KVStoreReplicationStatus == "Startup recovery"
+}
+
+// kvStoreResyncTimeout is the time a recovering KV store is given to catch up before it is resynced, and the time a
+// resync is given to settle before another one is issued
+var kvStoreResyncTimeout = 15 * time.Minute
+
+// trackKVStoreStaleness carries over from the previous status of a member the time its KV store became stale and the
+// time it was last resynced, until the KV store is ready again. They are also kept while the KV store status is unknown.
+func trackKVStoreStaleness(member, previous *enterpriseApi.SearchHeadClusterMemberStatus, now time.Time) {
+	if previous != nil && previous.Name == member.Name && (member.KVStoreStatus == "" || isKVStoreStale(member) || isKVStoreSyncing(member)) {
+		member.KVStoreStaleTime = previous.KVStoreStaleTime
+		member.KVStoreResyncTime = previous.KVStoreResyncTime
+	}
+	if isKVStoreStale(member) && member.KVStoreStaleTime == nil {
+		staleTime := metav1.NewTime(now)
+		member.KVStoreStaleTime = &staleTime
+	}
+}
+
+// needsKVStoreResync returns true if the KV store of a member should be resynced: right away when it failed, or once
+// it has been recovering for longer than kvStoreResyncTimeout. A resync is given the same time before another one.
+func needsKVStoreResync(member *enterpriseApi.SearchHeadClusterMemberStatus, now time.Time) bool {
+	if !isKVStoreStale(member) {
+		return false
+	}
+	if member.KVStoreResyncTime != nil && now.Sub(member.KVStoreResyncTime.Time) < kvStoreResyncTimeout {
+		return false
+	}
+	if member.KVStoreStatus == "failed" {
+		return true
+	}
+	return member.KVStoreStaleTime != nil && now.Sub(member.KVStoreStaleTime.Time) >= kvStoreResyncTimeout
+}
+
+// resyncKVStore resyncs the KV store of a member and records the time of the resync in its status
+func resyncKVStore(ctx context.Context, member *enterpriseApi.SearchHeadClusterMemberStatus, podExecClient splutil.PodExecClientImpl) error {
+	err := runKVStoreCommand(ctx, podExecClient, resyncKVStoreCmdStr)
+	if err != nil {
+		return err
+	}
+	now := metav1.Now()
+	member.KVStoreResyncTime = &now
+	return nil
+}
+
+// kvStoreArchiveNameRegex matches the KV store backup archive names that can be restored
+var kvStoreArchiveNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// runKVStoreCommand runs a KV store command on a search head
+func runKVStoreCommand(ctx context.Context, podExecClient splutil.PodExecClientImpl, command string) error {
+	streamOptions := splutil.NewStreamOptionsObject(command)
+	stdOut, stdErr, err := podExecClient.RunPodExecCommand(ctx, streamOptions, []string{"/bin/sh"})
+	if err != nil || stdErr != "" {
+		return fmt.Errorf("unable to run command %s on pod %s. stdout: %s, stderr: %s, err: %v", command, podExecClient.GetTargetPodName(), stdOut, stdErr, err)
+	}
+	return nil
+}
+
+// reconcileKVStoreBackups runs the KV store restore requested through the restore annotation, and takes the
+// backups requested through the backup annotation or due by schedule. Both run on the first search head,
+// which keeps the backup archives on its var volume: they are not copied off the pod, so the backup pod and
+// path are reported in the status. It returns the time left until the next scheduled backup.
+func reconcileKVStoreBackups(ctx context.Context, cr *enterpriseApi.SearchHeadCluster, podExecClient splutil.PodExecClientImpl) (time.Duration, error) {
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("reconcileKVStoreBackups").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+	eventPublisher, _ := newK8EventPublisher(cr)
+
+	annotations := cr.GetAnnotations()
+	restoreArchive, restoreRequested := annotations[enterpriseApi.SearchHeadClusterKVStoreRestoreAnnotation]
+	if restoreRequested && restoreArchive != cr.Status.KVStore.LastRestoreArchive {
+		if !kvStoreArchiveNameRegex.MatchString(restoreArchive) {
+			return 0, fmt.Errorf("invalid KV store backup archive name %q", restoreArchive)
+		}
+		scopedLog.Info("Restoring KV store", "archive", restoreArchive)
+		err := runKVStoreCommand(ctx, podExecClient, fmt.Sprintf(restoreKVStoreCmdStr, restoreArchive))
+		if err != nil {
+			return 0, err
+		}
+		now := metav1.Now()
+		cr.Status.KVStore.LastRestoreArchive = restoreArchive
+		cr.Status.KVStore.LastRestoreTime = &now
+		eventPublisher.Normal(ctx, KVStoreRestored, fmt.Sprintf("restored the KV store from %s", restoreArchive))
+	}
+
+	period := time.Duration(0)
+	if cr.Spec.KVStoreBackup.Period != nil {
+		period = cr.Spec.KVStoreBackup.Period.Duration
+	}
+	backupRequest, backupRequested := annotations[enterpriseApi.SearchHeadClusterKVStoreBackupAnnotation]
+	backupRequested = backupRequested && backupRequest != cr.Status.KVStore.LastBackupRequest
+	backupDue := period > 0 && (cr.Status.KVStore.LastBackupTime == nil || time.Since(cr.Status.KVStore.LastBackupTime.Time) >= period)
+	if backupRequested || backupDue {
+		now := metav1.Now()
+		archive := kvStoreBackupArchivePrefix + now.UTC().Format("20060102150405")
+		scopedLog.Info("Backing up KV store", "archive", archive, "requested", backupRequested)
+		err := runKVStoreCommand(ctx, podExecClient, fmt.Sprintf(backupKVStoreCmdStr, archive))
+		if err != nil {
+			return 0, err
+		}
+		cr.Status.KVStore.LastBackupArchive = archive + ".tar.gz"
+		cr.Status.KVStore.LastBackupTime = &now
+		cr.Status.KVStore.BackupPod = podExecClient.GetTargetPodName()
+		cr.Status.KVStore.BackupPath = kvStoreBackupDir
+		if backupRequested {
+			cr.Status.KVStore.LastBackupRequest = backupRequest
+		}
+		eventPublisher.Normal(ctx, KVStoreBackedUp, fmt.Sprintf("backed up the KV store to %s/%s on pod %s", kvStoreBackupDir, cr.Status.KVStore.LastBackupArchive, cr.Status.KVStore.BackupPod))
+
+		maxBackups := cr.Spec.KVStoreBackup.MaxBackups
+		if maxBackups <= 0 {
+			maxBackups = defaultKVStoreMaxBackups
+		}
+		err = runKVStoreCommand(ctx, podExecClient, fmt.Sprintf(pruneKVStoreBackupsCmdStr, maxBackups+1))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if period == 0 {
+		return 0, nil
+	}
+	return time.Until(cr.Status.KVStore.LastBackupTime.Add(period)), nil
+}
+
 // helper function to get the list of SearchHeadCluster types in the current namespace
 func getSearchHeadClusterList(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, listOpts []client.ListOption) (enterpriseApi.SearchHeadClusterList, error) {
 	reqLogger := log.FromContext(ctx)
@@ -23,8 +23,10 @@ import (
 
 	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
 
+	"github.com/splunk/splunk-operator/pkg/splunk/audit"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -36,6 +38,8 @@ import (
 
 // ApplyStandalone reconciles the StatefulSet for N standalone instances of Splunk Enterprise.
 func ApplyStandalone(ctx context.Context, client splcommon.ControllerClient, cr *enterpriseApi.Standalone) (reconcile.Result, error) {
+	ctx, span := tracing.Start(ctx, "ApplyStandalone")
+	defer span.End()
 
 	// unless modified, reconcile for this object will be requeued after 5 seconds
 	result := reconcile.Result{
@@ -48,12 +52,14 @@ func ApplyStandalone(ctx context.Context, client splcommon.ControllerClient, cr
 	if cr.Status.ResourceRevMap == nil {
 		cr.Status.ResourceRevMap = make(map[string]string)
 	}
-	eventPublisher, _ := newK8EventPublisher(client, cr)
+	eventPublisher, _ := newK8EventPublisher(cr)
+	defer eventPublisher.publishPhaseChange(ctx, cr.Status.Phase, &cr.Status.Phase)
+	ctx = audit.NewContext(ctx, cr, &cr.Status.AuditTrail)
 
 	// validate and updates defaults for CR
 	err := validateStandaloneSpec(ctx, client, cr)
 	if err != nil {
-		eventPublisher.Warning(ctx, "validateStandaloneSpec", fmt.Sprintf("validate standalone spec failed %s", err.Error()))
+		eventPublisher.Warning(ctx, ValidationFailed, fmt.Sprintf("validate standalone spec failed %s", err.Error()))
 		scopedLog.Error(err, "Failed to validate standalone spec")
 		return result, err
 	}
@@ -72,7 +78,7 @@ func ApplyStandalone(ctx context.Context, client splcommon.ControllerClient, cr
 		AreRemoteVolumeKeysChanged(ctx, client, cr, SplunkStandalone, &cr.Spec.SmartStore, cr.Status.ResourceRevMap, &err) {
 
 		if err != nil {
-			eventPublisher.Warning(ctx, "AreRemoteVolumeKeysChanged", fmt.Sprintf("check remote volume key change failed %s", err.Error()))
+			eventPublisher.Warning(ctx, SmartStoreUpdateFailed, fmt.Sprintf("check remote volume key change failed %s", err.Error()))
 			return result, err
 		}
 
@@ -90,7 +96,7 @@ func ApplyStandalone(ctx context.Context, client splcommon.ControllerClient, cr
 	if len(cr.Spec.AppFrameworkConfig.AppSources) != 0 {
 		err := initAndCheckAppInfoStatus(ctx, client, cr, &cr.Spec.AppFrameworkConfig, &cr.Status.AppContext)
 		if err != nil {
-			eventPublisher.Warning(ctx, "initAndCheckAppInfoStatus", fmt.Sprintf("init and check app info status failed %s", err.Error()))
+			eventPublisher.Warning(ctx, AppRepoCheckFailed, fmt.Sprintf("init and check app info status failed %s", err.Error()))
 			cr.Status.AppContext.IsDeploymentInProgress = false
 			return result, err
 		}
@@ -104,7 +110,7 @@ func ApplyStandalone(ctx context.Context, client splcommon.ControllerClient, cr
 	_, err = ApplySplunkConfig(ctx, client, cr, cr.Spec.CommonSplunkSpec, SplunkStandalone)
 	if err != nil {
 		scopedLog.Error(err, "create or update general config failed", "error", err.Error())
-		eventPublisher.Warning(ctx, "ApplySplunkConfig", fmt.Sprintf("create or update general config failed with error %s", err.Error()))
+		eventPublisher.Warning(ctx, ConfigApplyFailed, fmt.Sprintf("create or update general config failed with error %s", err.Error()))
 		return result, err
 	}
 
@@ -113,7 +119,7 @@ func ApplyStandalone(ctx context.Context, client splcommon.ControllerClient, cr
 		if cr.Spec.MonitoringConsoleRef.Name != "" {
 			_, err = ApplyMonitoringConsoleEnvConfigMap(ctx, client, cr.GetNamespace(), cr.GetName(), cr.Spec.MonitoringConsoleRef.Name, getStandaloneExtraEnv(cr, cr.Spec.Replicas), false)
 			if err != nil {
-				eventPublisher.Warning(ctx, "ApplyMonitoringConsoleEnvConfigMap", fmt.Sprintf("create/update monitoring console config map failed %s", err.Error()))
+				eventPublisher.Warning(ctx, MonitoringConsoleUpdateFailed, fmt.Sprintf("create/update monitoring console config map failed %s", err.Error()))
 				return result, err
 			}
 		}
@@ -140,14 +146,33 @@ func ApplyStandalone(ctx context.Context, client splcommon.ControllerClient, cr
 	// create or update a headless service
 	err = splctrl.ApplyService(ctx, client, getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone, true))
 	if err != nil {
-		eventPublisher.Warning(ctx, "ApplyService", fmt.Sprintf("create/update headless service failed %s", err.Error()))
+		eventPublisher.Warning(ctx, ServiceApplyFailed, fmt.Sprintf("create/update headless service failed %s", err.Error()))
 		return result, err
 	}
 
 	// create or update a regular service
 	err = splctrl.ApplyService(ctx, client, getSplunkService(ctx, cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone, false))
 	if err != nil {
-		eventPublisher.Warning(ctx, "ApplyService", fmt.Sprintf("create/update regular service failed %s", err.Error()))
+		eventPublisher.Warning(ctx, ServiceApplyFailed, fmt.Sprintf("create/update regular service failed %s", err.Error()))
+		return result, err
+	}
+
+	// create or update the services of each type of traffic and their routes
+	err = ApplySplunkTrafficServices(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone, cr.Spec.Hec.Enabled)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the certificates of the ports and the secret mounting them
+	err = ApplySplunkTLS(ctx, client, cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone)
+	if err != nil {
+		return result, err
+	}
+
+	// create or update the HEC tokens, service and ingress route
+	cr.Status.Hec, err = ApplyHecConfig(ctx, client, cr, &cr.Spec.CommonSplunkSpec, &cr.Spec.Hec, SplunkStandalone)
+	if err != nil {
+		eventPublisher.Warning(ctx, ConfigApplyFailed, fmt.Sprintf("create/update hec configuration failed %s", err.Error()))
 		return result, err
 	}
 
@@ -189,22 +214,22 @@ func ApplyStandalone(ctx context.Context, client splcommon.ControllerClient, cr
 	// create or update statefulset
 	statefulSet, err := getStandaloneStatefulSet(ctx, client, cr)
 	if err != nil {
-		eventPublisher.Warning(ctx, "getStandaloneStatefulSet", fmt.Sprintf("get standalone status set failed %s", err.Error()))
+		eventPublisher.Warning(ctx, StatefulSetUpdateFailed, fmt.Sprintf("get standalone status set failed %s", err.Error()))
 		return result, err
 	}
 
 	//make changes to respective mc configmap when changing/removing mcRef from spec
 	err = validateMonitoringConsoleRef(ctx, client, statefulSet, getStandaloneExtraEnv(cr, cr.Spec.Replicas))
 	if err != nil {
-		eventPublisher.Warning(ctx, "validateMonitoringConsoleRef", fmt.Sprintf("validate monitoring console reference failed %s", err.Error()))
+		eventPublisher.Warning(ctx, ValidationFailed, fmt.Sprintf("validate monitoring console reference failed %s", err.Error()))
 		return result, err
 	}
 
-	mgr := splctrl.DefaultStatefulSetPodManager{}
+	mgr := splctrl.DefaultStatefulSetPodManager{VolumeExpansion: &cr.Status.VolumeExpansion}
 	phase, err := mgr.Update(ctx, client, statefulSet, cr.Spec.Replicas)
 	cr.Status.ReadyReplicas = statefulSet.Status.ReadyReplicas
 	if err != nil {
-		eventPublisher.Warning(ctx, "validateStandaloneSpec", fmt.Sprintf("update stateful set failed %s", err.Error()))
+		eventPublisher.Warning(ctx, ValidationFailed, fmt.Sprintf("update stateful set failed %s", err.Error()))
 
 		return result, err
 	}
@@ -216,13 +241,13 @@ func ApplyStandalone(ctx context.Context, client splcommon.ControllerClient, cr
 		namespacedName := types.NamespacedName{Namespace: cr.GetNamespace(), Name: GetSplunkStatefulsetName(SplunkMonitoringConsole, cr.GetNamespace())}
 		err = splctrl.DeleteReferencesToAutomatedMCIfExists(ctx, client, cr, namespacedName)
 		if err != nil {
-			eventPublisher.Warning(ctx, "DeleteReferencesToAutomatedMCIfExists", fmt.Sprintf("delete reference to automated MC if exists failed %s", err.Error()))
+			eventPublisher.Warning(ctx, MonitoringConsoleUpdateFailed, fmt.Sprintf("delete reference to automated MC if exists failed %s", err.Error()))
 			scopedLog.Error(err, "Error in deleting automated monitoring console resource")
 		}
 		if cr.Spec.MonitoringConsoleRef.Name != "" {
 			_, err = ApplyMonitoringConsoleEnvConfigMap(ctx, client, cr.GetNamespace(), cr.GetName(), cr.Spec.MonitoringConsoleRef.Name, getStandaloneExtraEnv(cr, cr.Spec.Replicas), true)
 			if err != nil {
-				eventPublisher.Warning(ctx, "ApplyMonitoringConsoleEnvConfigMap", fmt.Sprintf("apply monitoring console environment config map failed %s", err.Error()))
+				eventPublisher.Warning(ctx, MonitoringConsoleUpdateFailed, fmt.Sprintf("apply monitoring console environment config map failed %s", err.Error()))
 				return result, err
 			}
 		}
@@ -268,6 +293,9 @@ func getStandaloneStatefulSet(ctx context.Context, client splcommon.ControllerCl
 	// Setup App framework staging volume for apps
 	setupAppsStagingVolume(ctx, client, cr, &ss.Spec.Template, &cr.Spec.AppFrameworkConfig)
 
+	// Add the operator managed HEC inputs
+	addHecConfigToTemplate(ctx, client, &ss.Spec.Template, cr, &cr.Spec.Hec, SplunkStandalone)
+
 	return ss, nil
 }
 
@@ -291,6 +319,11 @@ func validateStandaloneSpec(ctx context.Context, c splcommon.ControllerClient, c
 		}
 	}
 
+	err := validateHecSpec(&cr.Spec.Hec)
+	if err != nil {
+		return err
+	}
+
 	return validateCommonSplunkSpec(ctx, c, &cr.Spec.CommonSplunkSpec, cr)
 }
 
@@ -0,0 +1,445 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// identifier to track the certificates revision on the Pod
+	tlsConfigRev = "tlsConfigRev"
+
+	// volume name and mount location of the certificates
+	tlsVolumeName = "mnt-splunk-tls"
+	tlsMountPath  = "/mnt/splunk-tls"
+
+	// key of the CA certificate in a Secret of type kubernetes.io/tls, as written by cert-manager
+	tlsCACertKey = "ca.crt"
+
+	// ports of the tls spec, they name the files of their certificates
+	tlsMgmtPort = "mgmt"
+	tlsWebPort  = "web"
+	tlsS2SPort  = "s2s"
+	tlsHecPort  = "hec"
+)
+
+// certificateGVK is the cert-manager Certificate kind requesting the certificates from an issuer
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// tlsCertificate is the certificate of a port of the Splunk instances
+type tlsCertificate struct {
+	// port identifies the port in the tls spec, e.g. mgmt
+	port string
+
+	// configuration of the certificate
+	spec *enterpriseApi.CertificateSpec
+}
+
+// getTLSCertificates returns the configured certificates of the ports served by an instance type
+func getTLSCertificates(spec *enterpriseApi.CommonSplunkSpec, instanceType InstanceType) []tlsCertificate {
+	ports := getSplunkPorts(instanceType)
+	result := []tlsCertificate{}
+	for _, cert := range []struct {
+		tlsCertificate
+		portName string
+	}{
+		{tlsCertificate{port: tlsMgmtPort, spec: spec.TLS.Mgmt}, GetPortName(splunkdPort, protoHTTPS)},
+		{tlsCertificate{port: tlsWebPort, spec: spec.TLS.Web}, GetPortName(splunkwebPort, protoHTTP)},
+		{tlsCertificate{port: tlsS2SPort, spec: spec.TLS.S2S}, GetPortName(s2sPort, protoTCP)},
+		{tlsCertificate{port: tlsHecPort, spec: spec.TLS.Hec}, GetPortName(hecPort, protoHTTP)},
+	} {
+		if _, ok := ports[cert.portName]; ok && cert.spec != nil {
+			result = append(result, cert.tlsCertificate)
+		}
+	}
+	return result
+}
+
+// validateTLSSpec checks validity and makes default updates to a SplunkTLSSpec, and returns error if something is wrong.
+func validateTLSSpec(tls *enterpriseApi.SplunkTLSSpec) error {
+	for _, cert := range []tlsCertificate{
+		{port: tlsMgmtPort, spec: tls.Mgmt},
+		{port: tlsWebPort, spec: tls.Web},
+		{port: tlsS2SPort, spec: tls.S2S},
+		{port: tlsHecPort, spec: tls.Hec},
+	} {
+		if cert.spec == nil {
+			continue
+		}
+		if (cert.spec.SecretName == "") == (cert.spec.IssuerRef == nil) {
+			return fmt.Errorf("%s certificate requires either secretName or issuerRef", cert.port)
+		}
+		if cert.spec.IssuerRef == nil {
+			continue
+		}
+		if cert.spec.IssuerRef.Name == "" {
+			return fmt.Errorf("issuerRef of the %s certificate requires a name", cert.port)
+		}
+		if cert.spec.IssuerRef.Kind == "" {
+			cert.spec.IssuerRef.Kind = "Issuer"
+		}
+	}
+	return nil
+}
+
+// getCertificateSecretName returns the name of the Secret a certificate is read from
+func getCertificateSecretName(cr splcommon.MetaObject, instanceType InstanceType, cert tlsCertificate) string {
+	if cert.spec.SecretName != "" {
+		return cert.spec.SecretName
+	}
+	return GetSplunkCertificateName(cr.GetName(), instanceType, cert.port)
+}
+
+// getCertificateDNSNames returns the DNS names of a certificate requested from an issuer: the names of the services
+// of the instances, their pods and the additional names of the spec
+func getCertificateDNSNames(cr splcommon.MetaObject, instanceType InstanceType, cert tlsCertificate) []string {
+	services := []string{
+		GetSplunkServiceName(instanceType, cr.GetName(), false),
+		GetSplunkServiceName(instanceType, cr.GetName(), true),
+	}
+	switch cert.port {
+	case tlsWebPort:
+		services = append(services, GetSplunkTrafficServiceName(instanceType, cr.GetName(), splunkwebPort))
+	case tlsMgmtPort:
+		services = append(services, GetSplunkTrafficServiceName(instanceType, cr.GetName(), splunkdPort))
+	case tlsS2SPort:
+		services = append(services, GetSplunkTrafficServiceName(instanceType, cr.GetName(), s2sPort))
+	case tlsHecPort:
+		services = append(services, GetSplunkHecServiceName(instanceType, cr.GetName()))
+	}
+
+	result := []string{}
+	for _, service := range services {
+		result = append(result,
+			service,
+			fmt.Sprintf("%s.%s", service, cr.GetNamespace()),
+			fmt.Sprintf("%s.%s.svc", service, cr.GetNamespace()),
+			splcommon.GetServiceFQDN(cr.GetNamespace(), service))
+	}
+
+	// the pods are addressed through the headless service
+	result = append(result, "*."+splcommon.GetServiceFQDN(cr.GetNamespace(), GetSplunkServiceName(instanceType, cr.GetName(), true)))
+	return append(result, cert.spec.DNSNames...)
+}
+
+// getSplunkCertificate returns the cert-manager Certificate requesting the certificate of a port from its issuer
+func getSplunkCertificate(cr splcommon.MetaObject, instanceType InstanceType, cert tlsCertificate) *unstructured.Unstructured {
+	dnsNames := []interface{}{}
+	for _, name := range getCertificateDNSNames(cr, instanceType, cert) {
+		dnsNames = append(dnsNames, name)
+	}
+
+	name := GetSplunkCertificateName(cr.GetName(), instanceType, cert.port)
+	spec := map[string]interface{}{
+		"secretName": name,
+		"dnsNames":   dnsNames,
+		"issuerRef": map[string]interface{}{
+			"name":  cert.spec.IssuerRef.Name,
+			"kind":  cert.spec.IssuerRef.Kind,
+			"group": certificateGVK.Group,
+		},
+	}
+	if cert.spec.Duration != nil {
+		spec["duration"] = cert.spec.Duration.Duration.String()
+	}
+	if cert.spec.RenewBefore != nil {
+		spec["renewBefore"] = cert.spec.RenewBefore.Duration.String()
+	}
+
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	certificate.SetGroupVersionKind(certificateGVK)
+	certificate.SetName(name)
+	certificate.SetNamespace(cr.GetNamespace())
+	certificate.SetLabels(getSplunkLabels(cr.GetName(), instanceType, ""))
+	certificate.SetOwnerReferences([]metav1.OwnerReference{splcommon.AsOwner(cr, true)})
+
+	return certificate
+}
+
+// getTLSDefaults renders the certificates mounted into the pods as splunk-ansible defaults, which configure
+// server.conf, web.conf and inputs.conf
+func getTLSDefaults(data map[string][]byte) ([]byte, error) {
+	path := func(key string) string {
+		return filepath.Join(tlsMountPath, key)
+	}
+	withCA := func(settings map[string]interface{}, port string) map[string]interface{} {
+		if _, ok := data[port+"-"+tlsCACertKey]; ok {
+			settings["ca"] = path(port + "-" + tlsCACertKey)
+		}
+		return settings
+	}
+
+	splunk := map[string]interface{}{}
+	if _, ok := data[tlsMgmtPort+".pem"]; ok {
+		splunk["ssl"] = withCA(map[string]interface{}{
+			"enable": true,
+			"cert":   path(tlsMgmtPort + ".pem"),
+		}, tlsMgmtPort)
+	}
+	if _, ok := data[tlsWebPort+".crt"]; ok {
+		splunk["http_enableSSL"] = 1
+		splunk["http_enableSSL_cert"] = path(tlsWebPort + ".crt")
+		splunk["http_enableSSL_privKey"] = path(tlsWebPort + ".key")
+	}
+	if _, ok := data[tlsS2SPort+".pem"]; ok {
+		splunk["s2s"] = withCA(map[string]interface{}{
+			"ssl":  true,
+			"cert": path(tlsS2SPort + ".pem"),
+		}, tlsS2SPort)
+	}
+	if _, ok := data[tlsHecPort+".pem"]; ok {
+		splunk["hec"] = map[string]interface{}{
+			"ssl":  true,
+			"cert": path(tlsHecPort + ".pem"),
+		}
+	}
+
+	return yaml.Marshal(map[string]interface{}{"splunk": splunk})
+}
+
+// applySplunkTLSSecret requests the certificates from their issuers, and copies all the certificates of an instance type
+// into the Secret mounted into its pods, along with the defaults configuring them. It returns nil when no certificate is configured.
+func applySplunkTLSSecret(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec, instanceType InstanceType) (*corev1.Secret, error) {
+	certificates := getTLSCertificates(spec, instanceType)
+	if len(certificates) == 0 {
+		return nil, nil
+	}
+
+	data := map[string][]byte{}
+	for _, port := range []string{tlsMgmtPort, tlsWebPort, tlsS2SPort, tlsHecPort} {
+		var cert *tlsCertificate
+		for i := range certificates {
+			if certificates[i].port == port {
+				cert = &certificates[i]
+			}
+		}
+
+		// remove the Certificates of the ports no longer issued by cert-manager
+		if cert == nil || cert.spec.IssuerRef == nil {
+			certificate := &unstructured.Unstructured{}
+			certificate.SetGroupVersionKind(certificateGVK)
+			namespacedName := types.NamespacedName{Namespace: cr.GetNamespace(), Name: GetSplunkCertificateName(cr.GetName(), instanceType, port)}
+			err := deleteOptionalObject(ctx, c, certificate, namespacedName)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			err := splctrl.ApplyUnstructured(ctx, c, getSplunkCertificate(cr, instanceType, *cert))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if cert == nil {
+			continue
+		}
+
+		// the Secret of a Certificate only exists once it is issued
+		var secret corev1.Secret
+		secretName := getCertificateSecretName(cr, instanceType, *cert)
+		err := c.Get(ctx, types.NamespacedName{Namespace: cr.GetNamespace(), Name: secretName}, &secret)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the %s certificate from secret %s: %w", port, secretName, err)
+		}
+		crt, key := secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey]
+		if len(crt) == 0 || len(key) == 0 {
+			return nil, fmt.Errorf("secret %s of the %s certificate requires %s and %s", secretName, port, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+		}
+
+		// splunkd expects the certificate and its key in the same file, splunkweb in separate ones
+		if port == tlsWebPort {
+			data[port+".crt"] = crt
+			data[port+".key"] = key
+		} else {
+			data[port+".pem"] = append(append(append([]byte{}, crt...), '\n'), key...)
+		}
+		if ca := secret.Data[tlsCACertKey]; len(ca) > 0 {
+			data[port+"-"+tlsCACertKey] = ca
+		}
+	}
+
+	defaults, err := getTLSDefaults(data)
+	if err != nil {
+		return nil, err
+	}
+	data["default.yml"] = defaults
+
+	return splutil.ApplySplunkSecret(ctx, c, cr, data, GetSplunkTLSSecretName(cr.GetName(), instanceType), cr.GetNamespace())
+}
+
+// registerManagementCA makes the Splunk clients of the operator verify the management certificate of an instance type
+// against its CA, once all the pods serve it. Until then the self signed certificates of Splunk are accepted.
+func registerManagementCA(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, instanceType InstanceType, secret *corev1.Secret) error {
+	domains := []string{
+		splcommon.GetServiceFQDN(cr.GetNamespace(), GetSplunkServiceName(instanceType, cr.GetName(), false)),
+		splcommon.GetServiceFQDN(cr.GetNamespace(), GetSplunkServiceName(instanceType, cr.GetName(), true)),
+	}
+
+	verify := false
+	if secret != nil {
+		if _, ok := secret.Data[tlsMgmtPort+".pem"]; ok {
+			var statefulSet appsv1.StatefulSet
+			namespacedName := types.NamespacedName{Namespace: cr.GetNamespace(), Name: GetSplunkStatefulsetName(instanceType, cr.GetName())}
+			err := c.Get(ctx, namespacedName, &statefulSet)
+			if err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+			verify = err == nil && isTLSRolledOut(&statefulSet, secret)
+		}
+	}
+
+	for _, domain := range domains {
+		if !verify {
+			splclient.UnregisterCertificateAuthority(domain)
+			continue
+		}
+		err := splclient.RegisterCertificateAuthority(domain, secret.Data[tlsMgmtPort+"-"+tlsCACertKey])
+		if err != nil {
+			return err
+		}
+	}
+	if verify {
+		reqLogger := log.FromContext(ctx)
+		scopedLog := reqLogger.WithName("registerManagementCA").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+		scopedLog.Info("Verifying the management certificate", "domains", domains)
+	}
+	return nil
+}
+
+// isTLSRolledOut returns true when all the pods of a StatefulSet have been recycled with the current certificates
+func isTLSRolledOut(statefulSet *appsv1.StatefulSet, secret *corev1.Secret) bool {
+	if statefulSet.Spec.Template.ObjectMeta.Annotations[tlsConfigRev] != secret.GetResourceVersion() {
+		return false
+	}
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	return statefulSet.Status.ObservedGeneration == statefulSet.GetGeneration() &&
+		statefulSet.Status.UpdatedReplicas == replicas
+}
+
+// ApplySplunkTLS reconciles the certificates of an instance type: the cert-manager Certificates, the Secret mounted
+// into the pods and the verification of the management certificate by the operator
+func ApplySplunkTLS(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec, instanceType InstanceType) error {
+	ctx, span := tracing.Start(ctx, "ApplySplunkTLS")
+	defer span.End()
+
+	secret, err := applySplunkTLSSecret(ctx, c, cr, spec, instanceType)
+	if err != nil {
+		return err
+	}
+	return registerManagementCA(ctx, c, cr, instanceType, secret)
+}
+
+// addTLSConfigToTemplate mounts the certificates into the Splunk containers and adds their defaults to SPLUNK_DEFAULTS_URL
+func addTLSConfigToTemplate(ctx context.Context, client splcommon.ControllerClient, podTemplateSpec *corev1.PodTemplateSpec, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec, instanceType InstanceType) {
+	if len(getTLSCertificates(spec, instanceType)) == 0 {
+		return
+	}
+
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("addTLSConfigToTemplate").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	secretName := GetSplunkTLSSecretName(cr.GetName(), instanceType)
+	secretVolDefaultMode := int32(corev1.SecretVolumeSourceDefaultMode)
+	addSplunkVolumeToTemplate(podTemplateSpec, tlsVolumeName, tlsMountPath, corev1.VolumeSource{
+		Secret: &corev1.SecretVolumeSource{
+			SecretName:  secretName,
+			DefaultMode: &secretVolDefaultMode,
+		},
+	})
+
+	// the certificates go first, so that the inline defaults of the CR can still override them
+	tlsDefaults := tlsMountPath + "/default.yml"
+	for idx := range podTemplateSpec.Spec.Containers {
+		if podTemplateSpec.Spec.Containers[idx].Name != splunkContainerName {
+			continue
+		}
+		for i, env := range podTemplateSpec.Spec.Containers[idx].Env {
+			if env.Name == "SPLUNK_DEFAULTS_URL" {
+				podTemplateSpec.Spec.Containers[idx].Env[i].Value = fmt.Sprintf("%s,%s", tlsDefaults, env.Value)
+			}
+		}
+	}
+
+	// We will update the annotation for resource version in the pod template spec
+	// so that any renewal of the certificates will lead to recycle of the pod.
+	secret, err := splutil.GetSecretByName(ctx, client, cr.GetNamespace(), cr.GetName(), secretName)
+	if err != nil {
+		scopedLog.Error(err, "Updation of TLS config annotation failed")
+		return
+	}
+	if podTemplateSpec.ObjectMeta.Annotations == nil {
+		podTemplateSpec.ObjectMeta.Annotations = make(map[string]string)
+	}
+	podTemplateSpec.ObjectMeta.Annotations[tlsConfigRev] = secret.GetResourceVersion()
+}
+
+// IsTLSSourceSecret returns true when some certificates of a custom resource are read from a Secret, so that
+// the custom resource is reconciled when they are renewed
+func IsTLSSourceSecret(cr splcommon.MetaObject, secretName string) bool {
+	var instanceTypes []InstanceType
+	switch cr.(type) {
+	case *enterpriseApi.Standalone:
+		instanceTypes = []InstanceType{SplunkStandalone}
+	case *enterpriseApi.ClusterManager:
+		instanceTypes = []InstanceType{SplunkClusterManager}
+	case *enterpriseApiV3.ClusterMaster:
+		instanceTypes = []InstanceType{SplunkClusterMaster}
+	case *enterpriseApi.IndexerCluster:
+		instanceTypes = []InstanceType{SplunkIndexer}
+	case *enterpriseApi.SearchHeadCluster:
+		instanceTypes = []InstanceType{SplunkSearchHead, SplunkDeployer}
+	case *enterpriseApi.LicenseManager:
+		instanceTypes = []InstanceType{SplunkLicenseManager}
+	case *enterpriseApiV3.LicenseMaster:
+		instanceTypes = []InstanceType{SplunkLicenseMaster}
+	case *enterpriseApi.MonitoringConsole:
+		instanceTypes = []InstanceType{SplunkMonitoringConsole}
+	case *enterpriseApi.DeploymentServer:
+		instanceTypes = []InstanceType{SplunkDeploymentServer}
+	}
+
+	spec := getCommonSplunkSpec(cr)
+	for _, instanceType := range instanceTypes {
+		for _, cert := range getTLSCertificates(spec, instanceType) {
+			if getCertificateSecretName(cr, instanceType, cert) == secretName {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateTLSSpec(t *testing.T) {
+	tls := enterpriseApi.SplunkTLSSpec{Mgmt: &enterpriseApi.CertificateSpec{}}
+	if err := validateTLSSpec(&tls); err == nil {
+		t.Errorf("validateTLSSpec() should have returned an error without secretName and issuerRef")
+	}
+
+	tls.Mgmt.SecretName = "mgmt-tls"
+	tls.Mgmt.IssuerRef = &enterpriseApi.CertificateIssuerRef{Name: "ca-issuer"}
+	if err := validateTLSSpec(&tls); err == nil {
+		t.Errorf("validateTLSSpec() should have returned an error with both secretName and issuerRef")
+	}
+
+	tls.Mgmt.SecretName = ""
+	if err := validateTLSSpec(&tls); err != nil {
+		t.Errorf("validateTLSSpec() returned error: %v", err)
+	}
+	if tls.Mgmt.IssuerRef.Kind != "Issuer" {
+		t.Errorf("validateTLSSpec() issuer kind = %s; want Issuer", tls.Mgmt.IssuerRef.Kind)
+	}
+}
+
+func TestGetSplunkCertificate(t *testing.T) {
+	cr := enterpriseApi.SearchHeadCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	cr.Spec.TLS = enterpriseApi.SplunkTLSSpec{
+		Web: &enterpriseApi.CertificateSpec{
+			IssuerRef:   &enterpriseApi.CertificateIssuerRef{Name: "ca-issuer", Kind: "ClusterIssuer"},
+			DNSNames:    []string{"search.example.com"},
+			RenewBefore: &metav1.Duration{Duration: 360 * 3600 * 1e9},
+		},
+		S2S: &enterpriseApi.CertificateSpec{SecretName: "s2s-tls"},
+	}
+
+	// search heads do not receive forwarder traffic
+	certificates := getTLSCertificates(&cr.Spec.CommonSplunkSpec, SplunkSearchHead)
+	if len(certificates) != 1 || certificates[0].port != tlsWebPort {
+		t.Fatalf("getTLSCertificates() = %v; want web", certificates)
+	}
+
+	got := getSplunkCertificate(&cr, SplunkSearchHead, certificates[0])
+	if got.GroupVersionKind() != certificateGVK || got.GetName() != "splunk-stack1-search-head-web-certificate" {
+		t.Errorf("getSplunkCertificate() = %v %s; want splunk-stack1-search-head-web-certificate", got.GroupVersionKind(), got.GetName())
+	}
+	secretName, _, _ := unstructured.NestedString(got.Object, "spec", "secretName")
+	if secretName != got.GetName() {
+		t.Errorf("getSplunkCertificate() secretName = %s; want %s", secretName, got.GetName())
+	}
+	issuer, _, _ := unstructured.NestedStringMap(got.Object, "spec", "issuerRef")
+	if issuer["name"] != "ca-issuer" || issuer["kind"] != "ClusterIssuer" || issuer["group"] != "cert-manager.io" {
+		t.Errorf("getSplunkCertificate() issuerRef = %v", issuer)
+	}
+	renewBefore, _, _ := unstructured.NestedString(got.Object, "spec", "renewBefore")
+	if renewBefore != "360h0m0s" {
+		t.Errorf("getSplunkCertificate() renewBefore = %s; want 360h0m0s", renewBefore)
+	}
+	dnsNames, _, _ := unstructured.NestedStringSlice(got.Object, "spec", "dnsNames")
+	for _, want := range []string{
+		"splunk-stack1-search-head-service.test.svc.cluster.local",
+		"*.splunk-stack1-search-head-headless.test.svc.cluster.local",
+		"splunk-stack1-search-head-splunkweb",
+		"search.example.com",
+	} {
+		found := false
+		for _, name := range dnsNames {
+			found = found || name == want
+		}
+		if !found {
+			t.Errorf("getSplunkCertificate() dnsNames = %v; want %s", dnsNames, want)
+		}
+	}
+}
+
+func TestApplySplunkTLS(t *testing.T) {
+	ctx := context.TODO()
+	cr := enterpriseApi.Standalone{
+		TypeMeta:   metav1.TypeMeta{Kind: "Standalone"},
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	c := fake.NewClientBuilder().Build()
+	secretName := GetSplunkTLSSecretName("stack1", SplunkStandalone)
+	domain := splcommon.GetServiceFQDN("test", GetSplunkServiceName(SplunkStandalone, "stack1", true))
+	defer registerManagementCA(ctx, c, &cr, SplunkStandalone, nil)
+
+	// nothing is created without certificates
+	if err := ApplySplunkTLS(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone); err != nil {
+		t.Errorf("ApplySplunkTLS() returned error: %v", err)
+	}
+	var secret corev1.Secret
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "test", Name: secretName}, &secret); !k8serrors.IsNotFound(err) {
+		t.Errorf("ApplySplunkTLS() created secret %s without certificates", secretName)
+	}
+
+	// the certificates are read from user secrets, or requested from cert-manager
+	cr.Spec.TLS = enterpriseApi.SplunkTLSSpec{
+		Mgmt: &enterpriseApi.CertificateSpec{SecretName: "mgmt-tls"},
+		Web:  &enterpriseApi.CertificateSpec{IssuerRef: &enterpriseApi.CertificateIssuerRef{Name: "ca-issuer", Kind: "Issuer"}},
+	}
+	c.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mgmt-tls", Namespace: "test"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte("mgmt-crt"),
+			corev1.TLSPrivateKeyKey: []byte("mgmt-key"),
+		},
+	})
+	err := ApplySplunkTLS(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone)
+	if !k8serrors.IsNotFound(err) {
+		t.Errorf("ApplySplunkTLS() error = %v; want the certificate not to be issued yet", err)
+	}
+	certificate := &unstructured.Unstructured{}
+	certificate.SetGroupVersionKind(certificateGVK)
+	webCertificateName := GetSplunkCertificateName("stack1", SplunkStandalone, tlsWebPort)
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "test", Name: webCertificateName}, certificate); err != nil {
+		t.Errorf("ApplySplunkTLS() did not create the Certificate: %v", err)
+	}
+
+	// once issued, the certificates are copied into the secret mounted into the pods
+	c.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: webCertificateName, Namespace: "test"},
+		Data: map[string][]byte{
+			corev1.TLSCertKey:       []byte("web-crt"),
+			corev1.TLSPrivateKeyKey: []byte("web-key"),
+			tlsCACertKey:            []byte("web-ca"),
+		},
+	})
+	if err := ApplySplunkTLS(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone); err != nil {
+		t.Errorf("ApplySplunkTLS() returned error: %v", err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "test", Name: secretName}, &secret); err != nil {
+		t.Fatalf("ApplySplunkTLS() did not create secret %s: %v", secretName, err)
+	}
+	if string(secret.Data["mgmt.pem"]) != "mgmt-crt\nmgmt-key" || string(secret.Data["web.crt"]) != "web-crt" || string(secret.Data["web-ca.crt"]) != "web-ca" {
+		t.Errorf("ApplySplunkTLS() secret data = %v", secret.Data)
+	}
+	defaults := string(secret.Data["default.yml"])
+	for _, want := range []string{"cert: /mnt/splunk-tls/mgmt.pem", "http_enableSSL_privKey: /mnt/splunk-tls/web.key"} {
+		if !strings.Contains(defaults, want) {
+			t.Errorf("ApplySplunkTLS() defaults = %s; want %s", defaults, want)
+		}
+	}
+	if !getTLSConfigOf(domain).InsecureSkipVerify {
+		t.Errorf("ApplySplunkTLS() should not verify the management certificate before the pods are recycled")
+	}
+
+	// the management certificate is verified once all pods serve it
+	replicas := int32(1)
+	c.Create(ctx, &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: GetSplunkStatefulsetName(SplunkStandalone, "stack1"), Namespace: "test"},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{tlsConfigRev: secret.GetResourceVersion()}},
+			},
+		},
+		Status: appsv1.StatefulSetStatus{UpdatedReplicas: 1},
+	})
+	if err := ApplySplunkTLS(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone); err != nil {
+		t.Errorf("ApplySplunkTLS() returned error: %v", err)
+	}
+	if getTLSConfigOf(domain).InsecureSkipVerify {
+		t.Errorf("ApplySplunkTLS() should verify the management certificate once the pods are recycled")
+	}
+
+	// the Certificate is removed once the port is no longer issued by cert-manager
+	cr.Spec.TLS.Web = nil
+	if err := ApplySplunkTLS(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone); err != nil {
+		t.Errorf("ApplySplunkTLS() returned error: %v", err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Namespace: "test", Name: webCertificateName}, certificate); !k8serrors.IsNotFound(err) {
+		t.Errorf("ApplySplunkTLS() did not remove the Certificate")
+	}
+
+	if !IsTLSSourceSecret(&cr, "mgmt-tls") || IsTLSSourceSecret(&cr, webCertificateName) {
+		t.Errorf("IsTLSSourceSecret() should only match the secrets of the configured certificates")
+	}
+}
+
+func TestAddTLSConfigToTemplate(t *testing.T) {
+	ctx := context.TODO()
+	cr := enterpriseApi.Standalone{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	c := fake.NewClientBuilder().Build()
+	podTemplateSpec := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{
+				Name: splunkContainerName,
+				Env:  []corev1.EnvVar{{Name: "SPLUNK_DEFAULTS_URL", Value: "/mnt/splunk-secrets/default.yml"}},
+			}},
+		},
+	}
+
+	// the pods are not changed without certificates
+	addTLSConfigToTemplate(ctx, c, &podTemplateSpec, &cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone)
+	if len(podTemplateSpec.Spec.Volumes) != 0 {
+		t.Errorf("addTLSConfigToTemplate() added volumes without certificates")
+	}
+
+	cr.Spec.TLS.Hec = &enterpriseApi.CertificateSpec{SecretName: "hec-tls"}
+	c.Create(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: GetSplunkTLSSecretName("stack1", SplunkStandalone), Namespace: "test"}})
+	addTLSConfigToTemplate(ctx, c, &podTemplateSpec, &cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone)
+	if len(podTemplateSpec.Spec.Volumes) != 1 || podTemplateSpec.Spec.Containers[0].VolumeMounts[0].MountPath != tlsMountPath {
+		t.Errorf("addTLSConfigToTemplate() did not mount the certificates: %v", podTemplateSpec.Spec)
+	}
+	if got := podTemplateSpec.Spec.Containers[0].Env[0].Value; got != "/mnt/splunk-tls/default.yml,/mnt/splunk-secrets/default.yml" {
+		t.Errorf("addTLSConfigToTemplate() SPLUNK_DEFAULTS_URL = %s", got)
+	}
+	if podTemplateSpec.ObjectMeta.Annotations[tlsConfigRev] == "" {
+		t.Errorf("addTLSConfigToTemplate() did not set the %s annotation", tlsConfigRev)
+	}
+}
+
+// getTLSConfigOf returns the TLS configuration of the Splunk clients of a domain
+func getTLSConfigOf(domain string) *tls.Config {
+	c := splclient.NewSplunkClient(fmt.Sprintf("https://%s:8089", domain), "admin", "p@ssw0rd")
+	return c.Client.(*http.Client).Transport.(*http.Transport).TLSClientConfig
+}
@@ -0,0 +1,349 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"fmt"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// label identifying the custom resource a traffic service belongs to
+	trafficServiceOwnerLabel = "enterprise.splunk.com/traffic-service-owner"
+
+	// label holding the type of traffic of a service, e.g. splunkweb
+	trafficServiceLabel = "enterprise.splunk.com/traffic-service"
+
+	// name of the cookie keeping the Splunk Web sessions of a client on the same search head
+	webSessionCookieName = "splunkweb-session"
+
+	// how long the Splunk Web sessions of a client stay on the same search head, in seconds
+	webSessionAffinityTimeoutSec = 10800
+)
+
+// gatewayTLSRouteGVK is the Gateway API TLSRoute kind used when passing TLS connections through a Gateway
+var gatewayTLSRouteGVK = schema.GroupVersionKind{Group: "gateway.networking.k8s.io", Version: "v1alpha2", Kind: "TLSRoute"}
+
+// trafficService is a type of traffic with its own service
+type trafficService struct {
+	// traffic identifies the type of traffic, it is the name of its port without protocol, e.g. splunkweb
+	traffic string
+
+	// protocol of the port
+	protocol string
+
+	// configuration of the service
+	spec *enterpriseApi.SplunkRoutedServiceSpec
+}
+
+// getTrafficServices returns the configured traffic services served by an instance type
+func getTrafficServices(spec *enterpriseApi.CommonSplunkSpec, instanceType InstanceType) []trafficService {
+	withoutRoute := func(serviceSpec *enterpriseApi.SplunkServiceSpec) *enterpriseApi.SplunkRoutedServiceSpec {
+		if serviceSpec == nil {
+			return nil
+		}
+		return &enterpriseApi.SplunkRoutedServiceSpec{SplunkServiceSpec: *serviceSpec}
+	}
+
+	ports := getSplunkPorts(instanceType)
+	result := []trafficService{}
+	for _, service := range []trafficService{
+		{traffic: splunkwebPort, protocol: protoHTTP, spec: spec.Services.Web},
+		{traffic: splunkdPort, protocol: protoHTTPS, spec: withoutRoute(spec.Services.Mgmt)},
+		{traffic: s2sPort, protocol: protoTCP, spec: withoutRoute(spec.Services.S2S)},
+		{traffic: hecPort, protocol: protoHTTP, spec: spec.Services.Hec},
+	} {
+		if _, ok := ports[GetPortName(service.traffic, service.protocol)]; ok && service.spec != nil {
+			result = append(result, service)
+		}
+	}
+	return result
+}
+
+// validateServicesSpec checks validity and makes default updates to a SplunkServicesSpec, and returns error if something is wrong.
+func validateServicesSpec(services *enterpriseApi.SplunkServicesSpec) error {
+	for traffic, service := range map[string]*enterpriseApi.SplunkRoutedServiceSpec{splunkwebPort: services.Web, hecPort: services.Hec} {
+		if service == nil || service.Route == nil {
+			continue
+		}
+		if service.Route.Kind == "" {
+			service.Route.Kind = enterpriseApi.GatewayHTTPRoute
+		}
+		if service.Route.GatewayRef.Name == "" {
+			return fmt.Errorf("route of the %s service requires gatewayRef.name", traffic)
+		}
+	}
+	return nil
+}
+
+// applySplunkServiceSpec customizes a traffic service with its SplunkServiceSpec
+func applySplunkServiceSpec(service *corev1.Service, cr splcommon.MetaObject, instanceType InstanceType, traffic string, serviceSpec *enterpriseApi.SplunkServiceSpec) {
+	service.ObjectMeta.Labels[trafficServiceOwnerLabel] = GetSplunkStatefulsetName(instanceType, cr.GetName())
+	service.ObjectMeta.Labels[trafficServiceLabel] = traffic
+	for k, v := range serviceSpec.Annotations {
+		service.ObjectMeta.Annotations[k] = v
+	}
+
+	service.Spec.Type = corev1.ServiceTypeClusterIP
+	if serviceSpec.Type != "" {
+		service.Spec.Type = serviceSpec.Type
+	}
+	if service.Spec.Type == corev1.ServiceTypeNodePort || service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		// set the default of the cluster, so that the service is not updated on every reconcile
+		service.Spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyTypeCluster
+		if serviceSpec.ExternalTrafficPolicy != "" {
+			service.Spec.ExternalTrafficPolicy = serviceSpec.ExternalTrafficPolicy
+		}
+	}
+	if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+		service.Spec.LoadBalancerSourceRanges = serviceSpec.LoadBalancerSourceRanges
+	}
+}
+
+// getSplunkTrafficService returns the Kubernetes Service load balancing one type of traffic across the ready pods of a Splunk Enterprise resource
+func getSplunkTrafficService(ctx context.Context, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec, instanceType InstanceType, service trafficService) *corev1.Service {
+	if service.traffic == hecPort {
+		return getSplunkHecService(ctx, cr, spec, instanceType)
+	}
+
+	portName := GetPortName(service.traffic, service.protocol)
+	port := getSplunkPorts(instanceType)[portName]
+	result := &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        GetSplunkTrafficServiceName(instanceType, cr.GetName(), service.traffic),
+			Namespace:   cr.GetNamespace(),
+			Labels:      make(map[string]string),
+			Annotations: make(map[string]string),
+		},
+	}
+
+	// PublishNotReadyAddresses is left unset so that only ready pods receive traffic
+	result.Spec.Selector = getSplunkServiceSelector(cr, spec, instanceType)
+	result.Spec.Ports = []corev1.ServicePort{
+		{
+			Name:       portName,
+			Port:       int32(port),
+			TargetPort: intstr.FromInt(port),
+			Protocol:   corev1.ProtocolTCP,
+		},
+	}
+
+	// Splunk Web sessions are only known by the search head which created them
+	result.Spec.SessionAffinity = corev1.ServiceAffinityNone
+	if service.traffic == splunkwebPort && instanceType == SplunkSearchHead {
+		timeout := int32(webSessionAffinityTimeoutSec)
+		result.Spec.SessionAffinity = corev1.ServiceAffinityClientIP
+		result.Spec.SessionAffinityConfig = &corev1.SessionAffinityConfig{
+			ClientIP: &corev1.ClientIPConfig{TimeoutSeconds: &timeout},
+		}
+	}
+
+	// append same labels as selector
+	for k, v := range result.Spec.Selector {
+		result.ObjectMeta.Labels[k] = v
+	}
+
+	// append labels and annotations from parent
+	splcommon.AppendParentMeta(result.ObjectMeta.GetObjectMeta(), cr.GetObjectMeta())
+
+	applySplunkServiceSpec(result, cr, instanceType, service.traffic, &service.spec.SplunkServiceSpec)
+
+	result.SetOwnerReferences(append(result.GetOwnerReferences(), splcommon.AsOwner(cr, true)))
+
+	return result
+}
+
+// getSplunkTrafficRoute returns the Gateway API route exposing the service of one type of traffic
+func getSplunkTrafficRoute(cr splcommon.MetaObject, instanceType InstanceType, service trafficService) *unstructured.Unstructured {
+	routeSpec := service.spec.Route
+
+	parentRef := map[string]interface{}{
+		"name": routeSpec.GatewayRef.Name,
+	}
+	if routeSpec.GatewayRef.Namespace != "" {
+		parentRef["namespace"] = routeSpec.GatewayRef.Namespace
+	}
+	if routeSpec.GatewayRef.SectionName != "" {
+		parentRef["sectionName"] = routeSpec.GatewayRef.SectionName
+	}
+
+	rule := map[string]interface{}{
+		"backendRefs": []interface{}{
+			map[string]interface{}{
+				"name": GetSplunkTrafficServiceName(instanceType, cr.GetName(), service.traffic),
+				"port": int64(getSplunkPorts(instanceType)[GetPortName(service.traffic, service.protocol)]),
+			},
+		},
+	}
+
+	gvk := HecHTTPRouteGVK
+	if routeSpec.Kind == enterpriseApi.GatewayTLSRoute {
+		gvk = gatewayTLSRouteGVK
+	} else if service.traffic == hecPort {
+		rule["matches"] = []interface{}{
+			map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":  "PathPrefix",
+					"value": hecCollectorPath,
+				},
+			},
+		}
+	} else if instanceType == SplunkSearchHead {
+		// the Gateway keeps the Splunk Web sessions of a client on the same search head
+		rule["sessionPersistence"] = map[string]interface{}{
+			"sessionName": webSessionCookieName,
+			"type":        "Cookie",
+		}
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": []interface{}{parentRef},
+		"rules":      []interface{}{rule},
+	}
+	if len(routeSpec.Hostnames) > 0 {
+		hostnames := make([]interface{}, 0, len(routeSpec.Hostnames))
+		for _, hostname := range routeSpec.Hostnames {
+			hostnames = append(hostnames, hostname)
+		}
+		spec["hostnames"] = hostnames
+	}
+
+	route := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	route.SetGroupVersionKind(gvk)
+	route.SetName(GetSplunkTrafficRouteName(instanceType, cr.GetName(), service.traffic))
+	route.SetNamespace(cr.GetNamespace())
+	route.SetLabels(getSplunkLabels(cr.GetName(), instanceType, ""))
+	if len(routeSpec.Annotations) > 0 {
+		route.SetAnnotations(routeSpec.Annotations)
+	}
+	route.SetOwnerReferences([]metav1.OwnerReference{splcommon.AsOwner(cr, true)})
+
+	return route
+}
+
+// ApplySplunkTrafficServices creates or updates the services of each type of traffic with their Gateway API routes,
+// and removes the ones which are no longer configured. The HEC service is kept while hecEnabled, it is then owned by
+// ApplyHecConfig
+func ApplySplunkTrafficServices(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, spec *enterpriseApi.CommonSplunkSpec, instanceType InstanceType, hecEnabled bool) error {
+	ctx, span := tracing.Start(ctx, "ApplySplunkTrafficServices")
+	defer span.End()
+
+	// like the HEC routes, the services are only reconciled while some are configured
+	if spec.Services == (enterpriseApi.SplunkServicesSpec{}) {
+		return nil
+	}
+
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("ApplySplunkTrafficServices").WithValues("name", cr.GetName(), "namespace", cr.GetNamespace())
+
+	wanted := make(map[string]bool)
+	for _, service := range getTrafficServices(spec, instanceType) {
+		wanted[service.traffic] = true
+		err := splctrl.ApplyService(ctx, c, getSplunkTrafficService(ctx, cr, spec, instanceType, service))
+		if err != nil {
+			return err
+		}
+
+		// create or update the route, and remove the one of the other kind
+		var kind enterpriseApi.GatewayRouteKind
+		if service.spec.Route != nil {
+			route := getSplunkTrafficRoute(cr, instanceType, service)
+			err = splctrl.ApplyUnstructured(ctx, c, route)
+			if err != nil {
+				return err
+			}
+			kind = enterpriseApi.GatewayRouteKind(route.GetKind())
+		}
+		err = deleteSplunkTrafficRoutes(ctx, c, cr, instanceType, service.traffic, kind)
+		if err != nil {
+			return err
+		}
+	}
+
+	// remove the services which are no longer configured, with their routes
+	serviceList := corev1.ServiceList{}
+	listOpts := []client.ListOption{
+		client.InNamespace(cr.GetNamespace()),
+		client.MatchingLabels{trafficServiceOwnerLabel: GetSplunkStatefulsetName(instanceType, cr.GetName())},
+	}
+	err := c.List(ctx, &serviceList, listOpts...)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	for i := range serviceList.Items {
+		service := &serviceList.Items[i]
+		traffic := service.GetLabels()[trafficServiceLabel]
+		if wanted[traffic] {
+			continue
+		}
+		err = deleteSplunkTrafficRoutes(ctx, c, cr, instanceType, traffic, "")
+		if err != nil {
+			return err
+		}
+		if traffic == hecPort && hecEnabled {
+			continue
+		}
+		scopedLog.Info("Deleting service of removed traffic type", "service", service.GetName(), "traffic", traffic)
+		err = splutil.DeleteResource(ctx, c, service)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteSplunkTrafficRoutes removes the routes of a traffic service, except the one of the given kind
+func deleteSplunkTrafficRoutes(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, instanceType InstanceType, traffic string, keep enterpriseApi.GatewayRouteKind) error {
+	if traffic != splunkwebPort && traffic != hecPort {
+		return nil
+	}
+	namespacedName := types.NamespacedName{Namespace: cr.GetNamespace(), Name: GetSplunkTrafficRouteName(instanceType, cr.GetName(), traffic)}
+	for _, gvk := range []schema.GroupVersionKind{HecHTTPRouteGVK, gatewayTLSRouteGVK} {
+		if enterpriseApi.GatewayRouteKind(gvk.Kind) == keep {
+			continue
+		}
+		route := &unstructured.Unstructured{}
+		route.SetGroupVersionKind(gvk)
+		err := deleteOptionalObject(ctx, c, route, namespacedName)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package enterprise
+
+import (
+	"context"
+	"testing"
+
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestValidateServicesSpec(t *testing.T) {
+	services := enterpriseApi.SplunkServicesSpec{
+		Web: &enterpriseApi.SplunkRoutedServiceSpec{Route: &enterpriseApi.GatewayRouteSpec{}},
+	}
+	if err := validateServicesSpec(&services); err == nil {
+		t.Errorf("validateServicesSpec() should have returned an error without gatewayRef.name")
+	}
+
+	services.Web.Route.GatewayRef.Name = "gateway"
+	if err := validateServicesSpec(&services); err != nil {
+		t.Errorf("validateServicesSpec() returned error: %v", err)
+	}
+	if services.Web.Route.Kind != enterpriseApi.GatewayHTTPRoute {
+		t.Errorf("validateServicesSpec() route kind = %s; want %s", services.Web.Route.Kind, enterpriseApi.GatewayHTTPRoute)
+	}
+}
+
+func TestGetSplunkTrafficService(t *testing.T) {
+	ctx := context.TODO()
+	cr := enterpriseApi.SearchHeadCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	cr.Spec.Services = enterpriseApi.SplunkServicesSpec{
+		Web: &enterpriseApi.SplunkRoutedServiceSpec{
+			SplunkServiceSpec: enterpriseApi.SplunkServiceSpec{
+				Type:        corev1.ServiceTypeLoadBalancer,
+				Annotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"},
+			},
+		},
+		Mgmt: &enterpriseApi.SplunkServiceSpec{},
+		S2S:  &enterpriseApi.SplunkServiceSpec{},
+	}
+
+	// search heads do not receive forwarder traffic
+	services := getTrafficServices(&cr.Spec.CommonSplunkSpec, SplunkSearchHead)
+	if len(services) != 2 || services[0].traffic != splunkwebPort || services[1].traffic != splunkdPort {
+		t.Fatalf("getTrafficServices() = %v; want splunkweb and splunkd", services)
+	}
+
+	web := getSplunkTrafficService(ctx, &cr, &cr.Spec.CommonSplunkSpec, SplunkSearchHead, services[0])
+	if web.GetName() != "splunk-stack1-search-head-splunkweb" || web.Spec.Type != corev1.ServiceTypeLoadBalancer {
+		t.Errorf("getSplunkTrafficService() = %s %s; want splunk-stack1-search-head-splunkweb LoadBalancer", web.GetName(), web.Spec.Type)
+	}
+	if web.GetAnnotations()["service.beta.kubernetes.io/aws-load-balancer-internal"] != "true" || web.GetLabels()[trafficServiceLabel] != splunkwebPort {
+		t.Errorf("getSplunkTrafficService() metadata = %v", web.ObjectMeta)
+	}
+	if len(web.Spec.Ports) != 1 || web.Spec.Ports[0].Port != 8000 || web.Spec.ExternalTrafficPolicy != corev1.ServiceExternalTrafficPolicyTypeCluster {
+		t.Errorf("getSplunkTrafficService() spec = %v", web.Spec)
+	}
+	if web.Spec.SessionAffinity != corev1.ServiceAffinityClientIP {
+		t.Errorf("getSplunkTrafficService() session affinity = %s; want ClientIP for search heads", web.Spec.SessionAffinity)
+	}
+
+	mgmt := getSplunkTrafficService(ctx, &cr, &cr.Spec.CommonSplunkSpec, SplunkSearchHead, services[1])
+	if mgmt.Spec.Type != corev1.ServiceTypeClusterIP || mgmt.Spec.SessionAffinity != corev1.ServiceAffinityNone || mgmt.Spec.Ports[0].Port != 8089 {
+		t.Errorf("getSplunkTrafficService() spec = %v", mgmt.Spec)
+	}
+}
+
+func TestGetSplunkTrafficRoute(t *testing.T) {
+	cr := enterpriseApi.SearchHeadCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	route := &enterpriseApi.GatewayRouteSpec{
+		Kind:       enterpriseApi.GatewayHTTPRoute,
+		GatewayRef: enterpriseApi.HecGatewayRef{Name: "gateway", Namespace: "infra"},
+		Hostnames:  []string{"search.example.com"},
+	}
+	service := trafficService{traffic: splunkwebPort, protocol: protoHTTP, spec: &enterpriseApi.SplunkRoutedServiceSpec{Route: route}}
+
+	got := getSplunkTrafficRoute(&cr, SplunkSearchHead, service)
+	if got.GetKind() != "HTTPRoute" || got.GetName() != "splunk-stack1-search-head-splunkweb-route" {
+		t.Errorf("getSplunkTrafficRoute() = %s %s; want HTTPRoute splunk-stack1-search-head-splunkweb-route", got.GetKind(), got.GetName())
+	}
+	rules, _, _ := unstructured.NestedSlice(got.Object, "spec", "rules")
+	if len(rules) != 1 || rules[0].(map[string]interface{})["sessionPersistence"] == nil {
+		t.Errorf("getSplunkTrafficRoute() rules = %v; want session persistence", rules)
+	}
+	hostnames, _, _ := unstructured.NestedSlice(got.Object, "spec", "hostnames")
+	if len(hostnames) != 1 || hostnames[0] != "search.example.com" {
+		t.Errorf("getSplunkTrafficRoute() hostnames = %v", hostnames)
+	}
+
+	// TLS connections are passed through to HEC
+	route.Kind = enterpriseApi.GatewayTLSRoute
+	service.traffic = hecPort
+	got = getSplunkTrafficRoute(&cr, SplunkIndexer, service)
+	if got.GroupVersionKind() != gatewayTLSRouteGVK {
+		t.Errorf("getSplunkTrafficRoute() kind = %v; want %v", got.GroupVersionKind(), gatewayTLSRouteGVK)
+	}
+	rules, _, _ = unstructured.NestedSlice(got.Object, "spec", "rules")
+	backend := rules[0].(map[string]interface{})["backendRefs"].([]interface{})[0].(map[string]interface{})
+	if backend["name"] != "splunk-stack1-indexer-hec" || backend["port"] != int64(8088) {
+		t.Errorf("getSplunkTrafficRoute() backend = %v; want splunk-stack1-indexer-hec:8088", backend)
+	}
+}
+
+func TestApplySplunkTrafficServices(t *testing.T) {
+	ctx := context.TODO()
+	cr := enterpriseApi.Standalone{
+		ObjectMeta: metav1.ObjectMeta{Name: "stack1", Namespace: "test"},
+	}
+	c := fake.NewClientBuilder().Build()
+
+	exists := func(obj client.Object, name string) bool {
+		err := c.Get(ctx, types.NamespacedName{Namespace: "test", Name: name}, obj)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			t.Fatalf("Get(%s) returned error: %v", name, err)
+		}
+		return err == nil
+	}
+	newRoute := func(gvk schema.GroupVersionKind) *unstructured.Unstructured {
+		route := &unstructured.Unstructured{}
+		route.SetGroupVersionKind(gvk)
+		return route
+	}
+
+	// nothing is created without services
+	if err := ApplySplunkTrafficServices(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone, false); err != nil {
+		t.Errorf("ApplySplunkTrafficServices() returned error: %v", err)
+	}
+	if exists(&corev1.Service{}, "splunk-stack1-standalone-splunkweb") {
+		t.Errorf("ApplySplunkTrafficServices() created a service without services")
+	}
+
+	cr.Spec.Services = enterpriseApi.SplunkServicesSpec{
+		Web: &enterpriseApi.SplunkRoutedServiceSpec{
+			Route: &enterpriseApi.GatewayRouteSpec{Kind: enterpriseApi.GatewayHTTPRoute, GatewayRef: enterpriseApi.HecGatewayRef{Name: "gateway"}},
+		},
+		S2S: &enterpriseApi.SplunkServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+	}
+	if err := ApplySplunkTrafficServices(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone, false); err != nil {
+		t.Errorf("ApplySplunkTrafficServices() returned error: %v", err)
+	}
+	for _, name := range []string{"splunk-stack1-standalone-splunkweb", "splunk-stack1-standalone-s2s"} {
+		if !exists(&corev1.Service{}, name) {
+			t.Errorf("ApplySplunkTrafficServices() did not create service %s", name)
+		}
+	}
+	if !exists(newRoute(HecHTTPRouteGVK), "splunk-stack1-standalone-splunkweb-route") {
+		t.Errorf("ApplySplunkTrafficServices() did not create the HTTPRoute")
+	}
+
+	// changing the kind of route replaces it, removing a traffic type removes its service
+	cr.Spec.Services.Web.Route.Kind = enterpriseApi.GatewayTLSRoute
+	cr.Spec.Services.S2S = nil
+	if err := ApplySplunkTrafficServices(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone, false); err != nil {
+		t.Errorf("ApplySplunkTrafficServices() returned error: %v", err)
+	}
+	if exists(newRoute(HecHTTPRouteGVK), "splunk-stack1-standalone-splunkweb-route") || !exists(newRoute(gatewayTLSRouteGVK), "splunk-stack1-standalone-splunkweb-route") {
+		t.Errorf("ApplySplunkTrafficServices() did not replace the HTTPRoute with a TLSRoute")
+	}
+	if exists(&corev1.Service{}, "splunk-stack1-standalone-s2s") {
+		t.Errorf("ApplySplunkTrafficServices() did not remove the s2s service")
+	}
+
+	// the HEC service is kept while HEC is enabled
+	cr.Spec.Services.Hec = &enterpriseApi.SplunkRoutedServiceSpec{}
+	if err := ApplySplunkTrafficServices(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone, true); err != nil {
+		t.Errorf("ApplySplunkTrafficServices() returned error: %v", err)
+	}
+	cr.Spec.Services.Hec = nil
+	if err := ApplySplunkTrafficServices(ctx, c, &cr, &cr.Spec.CommonSplunkSpec, SplunkStandalone, true); err != nil {
+		t.Errorf("ApplySplunkTrafficServices() returned error: %v", err)
+	}
+	if !exists(&corev1.Service{}, GetSplunkHecServiceName(SplunkStandalone, "stack1")) {
+		t.Errorf("ApplySplunkTrafficServices() removed the HEC service while HEC is enabled")
+	}
+}
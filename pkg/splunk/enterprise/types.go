@@ -72,6 +72,9 @@ const (
 	// SplunkMonitoringConsole is a single instance of Splunk monitor for mc
 	SplunkMonitoringConsole InstanceType = "monitoring-console"
 
+	// SplunkDeploymentServer distributes apps and configurations to groups of deployment clients (forwarders)
+	SplunkDeploymentServer InstanceType = "deployment-server"
+
 	// TmpAppDownloadDir is the Operator directory for app framework, when there is no explicit volume specified
 	TmpAppDownloadDir string = "/tmp/appframework/"
 )
@@ -200,6 +203,15 @@ type SHCPlaybookContext struct {
 	podExecClient        splutil.PodExecClientImpl
 }
 
+// DeploymentServerPlaybookContext is used to implement playbook to reload the deployment server with the latest deployment apps
+type DeploymentServerPlaybookContext struct {
+	client        splcommon.ControllerClient
+	cr            splcommon.MetaObject
+	afwPipeline   *AppInstallPipeline
+	targetPodName string
+	podExecClient splutil.PodExecClientImpl
+}
+
 // premiumAppScopePlaybookContext is used to implement playbook to run special commands for premium apps installation
 type premiumAppScopePlaybookContext struct {
 	localCtx    *localScopePlaybookContext
@@ -244,6 +256,8 @@ func (instanceType InstanceType) ToRole() string {
 		role = splcommon.LicenseManagerRole
 	case SplunkMonitoringConsole:
 		role = "splunk_monitor"
+	case SplunkDeploymentServer:
+		role = "splunk_deployment_server"
 	}
 	return role
 }
@@ -270,6 +284,8 @@ func (instanceType InstanceType) ToKind() string {
 		kind = "license-manager"
 	case SplunkMonitoringConsole:
 		kind = "monitoring-console"
+	case SplunkDeploymentServer:
+		kind = "deployment-server"
 	}
 	return kind
 }
@@ -46,6 +46,7 @@ import (
 	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
 	splctrl "github.com/splunk/splunk-operator/pkg/splunk/controller"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
 
 	// Used to move files between pods
@@ -206,6 +207,9 @@ func GetRemoteStorageClient(ctx context.Context, client splcommon.ControllerClie
 
 // ApplySplunkConfig reconciles the state of Kubernetes Secrets, ConfigMaps and other general settings for Splunk Enterprise instances.
 func ApplySplunkConfig(ctx context.Context, client splcommon.ControllerClient, cr splcommon.MetaObject, spec enterpriseApi.CommonSplunkSpec, instanceType InstanceType) (*corev1.Secret, error) {
+	ctx, span := tracing.Start(ctx, "ApplySplunkConfig")
+	defer span.End()
+
 	var err error
 
 	// Creates/updates the namespace scoped "splunk-secrets" K8S secret object
@@ -248,7 +252,7 @@ func getClusterManagerExtraEnv(cr splcommon.MetaObject, spec *enterpriseApi.Comm
 	return []corev1.EnvVar{
 		{
 			Name:  splcommon.ClusterManagerURL,
-			Value: GetSplunkServiceName(SplunkClusterManager, cr.GetName(), false),
+			Value: GetSplunkServiceName(getClusterManagerInstanceType(cr), cr.GetName(), false),
 		},
 	}
 }
@@ -556,6 +560,8 @@ func getAppPackageLocalPath(ctx context.Context, worker *PipelineWorker) string
 // ApplySmartstoreConfigMap creates the configMap with Smartstore config in INI format
 func ApplySmartstoreConfigMap(ctx context.Context, client splcommon.ControllerClient, cr splcommon.MetaObject,
 	smartstore *enterpriseApi.SmartStoreSpec) (*corev1.ConfigMap, bool, error) {
+	ctx, span := tracing.Start(ctx, "ApplySmartstoreConfigMap")
+	defer span.End()
 
 	var crKind string
 	var configMapDataChanged bool
@@ -1943,6 +1949,18 @@ func handleAppFrameworkActivity(ctx context.Context, client splcommon.Controller
 	}
 
 	if appDeployContext.AppsSrcDeployStatus != nil {
+		// app installs may restart the Splunk instances, so they wait for a maintenance window
+		allowed, err := isInMaintenanceWindow(ctx, client, cr, getCommonSplunkSpec(cr))
+		if err != nil {
+			scopedLog.Error(err, "unable to evaluate maintenance windows")
+		}
+		if !allowed {
+			if appDeployContext.IsDeploymentInProgress {
+				updateReconcileRequeueTime(ctx, finalResult, maintenanceWindowRetryDelay, true)
+			}
+			return finalResult
+		}
+
 		requeue, err := afwSchedulerEntry(ctx, client, cr, appDeployContext, appFrameworkConfig)
 		if err != nil {
 			scopedLog.Error(err, "app framework returned error")
@@ -2186,6 +2204,15 @@ func fetchCurrentCRWithStatusUpdate(ctx context.Context, client splcommon.Contro
 		}
 		origCR.(*enterpriseApi.MonitoringConsole).Status.DeepCopyInto(&latestMcCR.Status)
 		return latestMcCR, nil
+
+	case "DeploymentServer":
+		latestDsCR := &enterpriseApi.DeploymentServer{}
+		err = client.Get(ctx, namespacedName, latestDsCR)
+		if err != nil {
+			return nil, err
+		}
+		origCR.(*enterpriseApi.DeploymentServer).Status.DeepCopyInto(&latestDsCR.Status)
+		return latestDsCR, nil
 	}
 
 	return nil, fmt.Errorf("invalid CR Kind")
@@ -2206,12 +2233,17 @@ func ReadFile(ctx context.Context, fileLocation string) (string, error) {
 }
 
 // setProbeLevelOnSplunkPod  set K8_OPERATOR_LIVENESS_LEVEL in k8_liveness_driver.sh script on splunk pod. Set probeLevel to 0 to unset K8_OPERATOR_LIVENESS_LEVEL.
-func setProbeLevelOnSplunkPod(ctx context.Context, podExecClient splutil.PodExecClientImpl, probeLevel int) error {
+// The probe level of the pods running the health agent is set through its API instead
+func setProbeLevelOnSplunkPod(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, podExecClient splutil.PodExecClientImpl, probeLevel int) error {
 	var err error
 	var stdOut string
 	var command string
 	reqLogger := log.FromContext(ctx)
 	scopedLog := reqLogger.WithName("setProbeLevelOnSplunkPod").WithValues("podName", podExecClient.GetTargetPodName(), "probeLevel", probeLevel)
+
+	if found, err := setProbeLevelOnHealthAgent(ctx, c, cr.GetNamespace(), podExecClient.GetTargetPodName(), probeLevel); found {
+		return err
+	}
 	switch probeLevel {
 	case livenessProbeLevelDefault:
 		command = fmt.Sprintf("[[ -f %s ]] && > %s", GetLivenessDriverFilePath(), GetLivenessDriverFilePath())
@@ -2237,13 +2269,13 @@ func setProbeLevelOnSplunkPod(ctx context.Context, podExecClient splutil.PodExec
 }
 
 // setProbeLevelOnCRPods set K8_OPERATOR_LIVENESS_LEVEL in k8_liveness_driver.sh script on all pods of CR,  Set probeLevel to 0 to unset K8_OPERATOR_LIVENESS_LEVEL.
-func setProbeLevelOnCRPods(ctx context.Context, cr splcommon.MetaObject, replicas int32, podExecClient splutil.PodExecClientImpl, probeLevel int) error {
+func setProbeLevelOnCRPods(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, replicas int32, podExecClient splutil.PodExecClientImpl, probeLevel int) error {
 	var err error
 	// Run the command on each replica pod
 	for replicaIndex := 0; replicaIndex < int(replicas); replicaIndex++ {
 		podName := getApplicablePodNameForK8Probes(cr, int32(replicaIndex))
 		podExecClient.SetTargetPodName(ctx, podName)
-		err = setProbeLevelOnSplunkPod(ctx, podExecClient, probeLevel)
+		err = setProbeLevelOnSplunkPod(ctx, c, cr, podExecClient, probeLevel)
 		if err != nil {
 			return err
 		}
@@ -2266,9 +2298,11 @@ func getApplicablePodNameForK8Probes(cr splcommon.MetaObject, ordinalIdx int32)
 	case "ClusterMaster":
 		podType = "cluster-master"
 	case "ClusterManager":
-		podType = "cluster-manager"
+		podType = string(getClusterManagerInstanceType(cr))
 	case "MonitoringConsole":
 		podType = "monitoring-console"
+	case "DeploymentServer":
+		podType = "deployment-server"
 	}
 	return fmt.Sprintf("splunk-%s-%s-%d", cr.GetName(), podType, ordinalIdx)
 }
@@ -0,0 +1,353 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health implements the health agent of the Splunk pods, which serves their liveness, readiness and
+// startup probes over HTTP, and the client used by the operator to change its probe level
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	splclient "github.com/splunk/splunk-operator/pkg/splunk/client"
+)
+
+const (
+	// DefaultPort is the port of the health agent
+	DefaultPort = 8095
+
+	// LivenessPath is the path of the liveness probe
+	LivenessPath = "/livez"
+
+	// ReadinessPath is the path of the readiness probe
+	ReadinessPath = "/readyz"
+
+	// StartupPath is the path of the startup probe
+	StartupPath = "/startupz"
+
+	// ProbeLevelPath is the path of the probe level API
+	ProbeLevelPath = "/level"
+
+	// ProbeLevelDefault checks that the splunkd management port is reachable
+	ProbeLevelDefault = 0
+
+	// ProbeLevelOne only checks that the splunkd process is running, while it restarts to install apps or decommission
+	ProbeLevelOne = 1
+
+	// CheckKVStore makes the pod ready when its KV store is ready or disabled
+	CheckKVStore = "kvstore"
+
+	// CheckSHCMember makes the pod ready when it is registered with its search head cluster captain
+	CheckSHCMember = "shc-member"
+
+	// CheckIndexerPeer makes the pod ready when it is a registered indexer cluster peer that is up
+	CheckIndexerPeer = "indexer-peer"
+)
+
+// Config is the configuration of the health agent
+type Config struct {
+	// SplunkdURL is the URL of the local splunkd management port
+	SplunkdURL string
+
+	// Username of the splunkd admin user
+	Username string
+
+	// PasswordFile is the file with the password of the splunkd admin user
+	PasswordFile string
+
+	// Checks are the role specific checks of the readiness probe
+	Checks []string
+
+	// ProcDir is the proc file system used to find the splunkd process
+	ProcDir string
+
+	// Timeout of the requests to splunkd
+	Timeout time.Duration
+
+	// Disabled makes all probes succeed, like NO_HEALTHCHECK does for the probe scripts
+	Disabled bool
+}
+
+// Agent serves the liveness, readiness and startup probes of a Splunk pod
+type Agent struct {
+	config Config
+	client *http.Client
+
+	// mutex to serialize the access to level
+	mutex sync.Mutex
+	level int
+}
+
+// NewAgent returns a health agent with the given configuration
+func NewAgent(config Config) *Agent {
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.ProcDir == "" {
+		config.ProcDir = "/proc"
+	}
+	return &Agent{
+		config: config,
+		client: &http.Client{
+			Timeout: config.Timeout,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // splunkd uses a self signed certificate
+			},
+		},
+	}
+}
+
+// GetProbeLevel returns the probe level of the agent
+func (a *Agent) GetProbeLevel() int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.level
+}
+
+// SetProbeLevel sets the probe level of the agent
+func (a *Agent) SetProbeLevel(level int) error {
+	if level != ProbeLevelDefault && level != ProbeLevelOne {
+		return fmt.Errorf("invalid probe level %d", level)
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.level = level
+	return nil
+}
+
+// Handler returns the HTTP handler of the probes and of the probe level API
+func (a *Agent) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivenessPath, a.probeHandler(a.Live))
+	mux.HandleFunc(ReadinessPath, a.probeHandler(a.Ready))
+	mux.HandleFunc(StartupPath, a.probeHandler(a.Started))
+	mux.HandleFunc(ProbeLevelPath, a.handleProbeLevel)
+	return mux
+}
+
+// probeHandler returns the HTTP handler of a probe, which fails with 503 Service Unavailable
+func (a *Agent) probeHandler(check func(context.Context) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if a.config.Disabled {
+			fmt.Fprintln(w, "ok")
+			return
+		}
+		if err := check(r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	}
+}
+
+// handleProbeLevel returns the probe level on GET and sets it on PUT, which requires the splunkd admin credentials
+func (a *Agent) handleProbeLevel(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		fmt.Fprintln(w, a.GetProbeLevel())
+	case http.MethodPut:
+		username, password, ok := r.BasicAuth()
+		if !ok || username != a.config.Username || !a.checkPassword(password) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(r.Body, 16))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		level, err := strconv.Atoi(strings.TrimSpace(string(body)))
+		if err == nil {
+			err = a.SetProbeLevel(level)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, level)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// checkPassword returns true if the password is the one of the splunkd admin user
+func (a *Agent) checkPassword(password string) bool {
+	expected, err := a.getPassword()
+	return err == nil && expected != "" && password == expected
+}
+
+// getPassword returns the password of the splunkd admin user
+func (a *Agent) getPassword() (string, error) {
+	data, err := os.ReadFile(a.config.PasswordFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Live returns an error if splunkd is not alive. With the default probe level the management port must be reachable,
+// with ProbeLevelOne the splunkd process must be running
+func (a *Agent) Live(ctx context.Context) error {
+	if a.GetProbeLevel() == ProbeLevelOne {
+		return a.checkProcess()
+	}
+	return a.checkReachable(ctx)
+}
+
+// Ready returns an error if splunkd is not ready. With the default probe level the management port must be reachable
+// and the role specific checks must pass, with ProbeLevelOne the splunkd process must be running
+func (a *Agent) Ready(ctx context.Context) error {
+	if a.GetProbeLevel() == ProbeLevelOne {
+		return a.checkProcess()
+	}
+	if err := a.checkReachable(ctx); err != nil {
+		return err
+	}
+	for _, check := range a.config.Checks {
+		var err error
+		switch check {
+		case CheckKVStore:
+			err = a.checkKVStore(ctx)
+		case CheckSHCMember:
+			err = a.checkSHCMember(ctx)
+		case CheckIndexerPeer:
+			err = a.checkIndexerPeer(ctx)
+		default:
+			err = fmt.Errorf("unknown check %s", check)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Started returns an error if splunkd did not start yet
+func (a *Agent) Started(ctx context.Context) error {
+	return a.checkReachable(ctx)
+}
+
+// checkReachable returns an error if the splunkd management port is not reachable
+func (a *Agent) checkReachable(ctx context.Context) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, a.config.SplunkdURL+"/", nil)
+	if err != nil {
+		return err
+	}
+	response, err := a.client.Do(request)
+	if err != nil {
+		return fmt.Errorf("mgmt. port is not reachable: %v", err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("mgmt. port returned %s", response.Status)
+	}
+	return nil
+}
+
+// checkProcess returns an error if no splunkd process is running. The agent sees the processes of the splunk
+// container because the pod shares its process namespace
+func (a *Agent) checkProcess() error {
+	entries, err := os.ReadDir(a.config.ProcDir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if _, err := strconv.Atoi(entry.Name()); err != nil || !entry.IsDir() {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join(a.config.ProcDir, entry.Name(), "cmdline"))
+		if err != nil {
+			continue
+		}
+		args := strings.Split(string(cmdline), "\x00")
+		if strings.HasSuffix(args[0], "splunkd") && len(args) > 1 && strings.Contains(strings.Join(args[1:], " "), "start") {
+			return nil
+		}
+	}
+	return fmt.Errorf("splunkd not running")
+}
+
+// getSplunkClient returns a client of the local splunkd with the admin credentials
+func (a *Agent) getSplunkClient() (*splclient.SplunkClient, error) {
+	password, err := a.getPassword()
+	if err != nil {
+		return nil, err
+	}
+	c := splclient.NewSplunkClient(a.config.SplunkdURL, a.config.Username, password)
+	c.Client = a.client
+	// the kubelet retries the probes itself
+	c.RetryPolicy = splclient.RetryPolicy{}
+	return c, nil
+}
+
+// checkKVStore returns an error if the KV store is neither ready nor disabled
+func (a *Agent) checkKVStore(ctx context.Context) error {
+	c, err := a.getSplunkClient()
+	if err != nil {
+		return err
+	}
+	status, err := c.GetKVStoreStatus(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get the KV store status: %v", err)
+	}
+	if status.Status != "ready" && status.Status != "disabled" {
+		return fmt.Errorf("KV store is %s", status.Status)
+	}
+	return nil
+}
+
+// checkSHCMember returns an error if the search head is not registered with its captain
+func (a *Agent) checkSHCMember(ctx context.Context) error {
+	c, err := a.getSplunkClient()
+	if err != nil {
+		return err
+	}
+	info, err := c.GetSearchHeadClusterMemberInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get the search head cluster member info: %v", err)
+	}
+	if !info.Registered {
+		return fmt.Errorf("search head cluster member is not registered")
+	}
+	return nil
+}
+
+// checkIndexerPeer returns an error if the indexer is not a registered cluster peer that is up, and so searchable
+func (a *Agent) checkIndexerPeer(ctx context.Context) error {
+	c, err := a.getSplunkClient()
+	if err != nil {
+		return err
+	}
+	info, err := c.GetIndexerClusterPeerInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get the indexer cluster peer info: %v", err)
+	}
+	if !info.Registered {
+		return fmt.Errorf("indexer cluster peer is not registered")
+	}
+	if info.Status != "Up" {
+		return fmt.Errorf("indexer cluster peer is %s", info.Status)
+	}
+	return nil
+}
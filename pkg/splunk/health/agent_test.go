@@ -0,0 +1,196 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestSplunkd returns a fake splunkd serving the given JSON bodies, by path
+func newTestSplunkd(t *testing.T, bodies map[string]string) *httptest.Server {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			return
+		}
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "admin" || password != "changeme" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestAgent returns an agent of a fake splunkd, with a fake proc file system
+func newTestAgent(t *testing.T, splunkdURL string, checks ...string) (*Agent, string) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	if err := os.WriteFile(passwordFile, []byte("changeme\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	procDir := filepath.Join(dir, "proc")
+	if err := os.MkdirAll(filepath.Join(procDir, "1"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(procDir, "1", "cmdline"), []byte("/bin/sh\x00-c\x00start.sh\x00"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return NewAgent(Config{
+		SplunkdURL:   splunkdURL,
+		Username:     "admin",
+		PasswordFile: passwordFile,
+		Checks:       checks,
+		ProcDir:      procDir,
+	}), procDir
+}
+
+func TestAgentProbes(t *testing.T) {
+	ctx := context.TODO()
+	splunkd := newTestSplunkd(t, map[string]string{
+		"/services/kvstore/status":        `{"entry":[{"content":{"current":{"status":"starting"}}}]}`,
+		"/services/shcluster/member/info": `{"entry":[{"content":{"is_registered":true}}]}`,
+	})
+	agent, procDir := newTestAgent(t, splunkd.URL, CheckSHCMember)
+
+	if err := agent.Started(ctx); err != nil {
+		t.Errorf("Started() returned error: %v", err)
+	}
+	if err := agent.Live(ctx); err != nil {
+		t.Errorf("Live() returned error: %v", err)
+	}
+	if err := agent.Ready(ctx); err != nil {
+		t.Errorf("Ready() returned error: %v", err)
+	}
+
+	// role specific checks make the pod unready
+	agent.config.Checks = append(agent.config.Checks, CheckKVStore)
+	if err := agent.Ready(ctx); err == nil {
+		t.Errorf("Ready() should have returned an error while the KV store starts")
+	}
+	agent.config.Checks = []string{CheckIndexerPeer}
+	if err := agent.Ready(ctx); err == nil {
+		t.Errorf("Ready() should have returned an error without indexer cluster peer info")
+	}
+
+	// probe level one only checks the splunkd process
+	if err := agent.SetProbeLevel(ProbeLevelOne); err != nil {
+		t.Errorf("SetProbeLevel() returned error: %v", err)
+	}
+	if err := agent.Live(ctx); err == nil {
+		t.Errorf("Live() should have returned an error without splunkd process")
+	}
+	if err := os.MkdirAll(filepath.Join(procDir, "42"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(procDir, "42", "cmdline"), []byte("splunkd\x00-p\x008089\x00start\x00"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := agent.Live(ctx); err != nil {
+		t.Errorf("Live() returned error: %v", err)
+	}
+	if err := agent.Ready(ctx); err != nil {
+		t.Errorf("Ready() returned error: %v", err)
+	}
+	if err := agent.SetProbeLevel(2); err == nil {
+		t.Errorf("SetProbeLevel(2) should have returned an error")
+	}
+
+	// the management port must be reachable with the default probe level
+	agent.SetProbeLevel(ProbeLevelDefault)
+	splunkd.Close()
+	if err := agent.Live(ctx); err == nil {
+		t.Errorf("Live() should have returned an error without splunkd")
+	}
+	if err := agent.Started(ctx); err == nil {
+		t.Errorf("Started() should have returned an error without splunkd")
+	}
+}
+
+func TestAgentHandler(t *testing.T) {
+	splunkd := newTestSplunkd(t, map[string]string{
+		"/services/cluster/peer/info": `{"entry":[{"content":{"is_registered":true,"status":"Up"}}]}`,
+	})
+	agent, _ := newTestAgent(t, splunkd.URL, CheckIndexerPeer)
+	server := httptest.NewTLSServer(agent.Handler())
+	defer server.Close()
+	client := server.Client()
+
+	for _, path := range []string{LivenessPath, ReadinessPath, StartupPath} {
+		response, err := client.Get(server.URL + path)
+		if err != nil || response.StatusCode != http.StatusOK {
+			t.Errorf("GET %s = %v, %v; want 200", path, response, err)
+		}
+	}
+
+	// the probe level is set with the admin credentials
+	c := &Client{Client: client}
+	if err := c.SetProbeLevel(context.TODO(), server.URL, "admin", "wrong", ProbeLevelOne); err == nil {
+		t.Errorf("SetProbeLevel() should have returned an error with a wrong password")
+	}
+	if err := c.SetProbeLevel(context.TODO(), server.URL, "admin", "changeme", 3); err == nil {
+		t.Errorf("SetProbeLevel() should have returned an error with an invalid level")
+	}
+	if err := c.SetProbeLevel(context.TODO(), server.URL, "admin", "changeme", ProbeLevelOne); err != nil {
+		t.Errorf("SetProbeLevel() returned error: %v", err)
+	}
+	if agent.GetProbeLevel() != ProbeLevelOne {
+		t.Errorf("GetProbeLevel() = %d; want %d", agent.GetProbeLevel(), ProbeLevelOne)
+	}
+
+	// without splunkd process the liveness probe fails with probe level one
+	response, err := client.Get(server.URL + LivenessPath)
+	if err != nil || response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("GET %s = %v, %v; want 503", LivenessPath, response, err)
+	}
+
+	// unless the health checks are disabled
+	agent.config.Disabled = true
+	response, err = client.Get(server.URL + LivenessPath)
+	if err != nil || response.StatusCode != http.StatusOK {
+		t.Errorf("GET %s = %v, %v; want 200", LivenessPath, response, err)
+	}
+}
+
+func TestGenerateCertificate(t *testing.T) {
+	certificate, err := GenerateCertificate("localhost")
+	if err != nil {
+		t.Fatalf("GenerateCertificate() returned error: %v", err)
+	}
+	server := httptest.NewUnstartedServer(http.NotFoundHandler())
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	server.StartTLS()
+	defer server.Close()
+
+	client := NewClient()
+	response, err := client.Client.Get(server.URL)
+	if err != nil || response.StatusCode != http.StatusNotFound {
+		t.Errorf("GET = %v, %v; want 404", response, err)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client changes the probe level of the health agents
+type Client struct {
+	// HTTP client used to process requests
+	Client *http.Client
+}
+
+// NewClient returns a client of the health agents. The agents use self signed certificates
+func NewClient() *Client {
+	return &Client{
+		Client: &http.Client{
+			Timeout: 5 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // the agents use self signed certificates
+			},
+		},
+	}
+}
+
+// SetProbeLevel sets the probe level of the health agent at agentURL, ex. "https://splunk-example-indexer-0.splunk-example-indexer-headless.ns.svc.cluster.local:8095"
+func (c *Client) SetProbeLevel(ctx context.Context, agentURL, username, password string, level int) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, agentURL+ProbeLevelPath, strings.NewReader(strconv.Itoa(level)))
+	if err != nil {
+		return err
+	}
+	request.SetBasicAuth(username, password)
+	response, err := c.Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("response code=%d from %s: %s", response.StatusCode, request.URL, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// GenerateCertificate returns a self signed certificate for the given host names, used by the health agent to serve
+// the probes and the probe level API over HTTPS
+func GenerateCertificate(hosts ...string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+
+	now := time.Now()
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: "splunk-health-agent"},
+		DNSNames:     hosts,
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.AddDate(10, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
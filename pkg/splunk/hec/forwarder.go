@@ -0,0 +1,370 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hec
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DefaultBatchSize is the number of events sent per request by default
+	DefaultBatchSize = 100
+
+	// DefaultFlushInterval is the time the events wait for a batch to fill up by default
+	DefaultFlushInterval = 5 * time.Second
+
+	// DefaultMaxBufferedEvents is the number of events kept by default while the collector is unavailable
+	DefaultMaxBufferedEvents = 10000
+
+	// DefaultMetricsInterval is the time between two exports of the metrics by default
+	DefaultMetricsInterval = time.Minute
+
+	// eventPath is the path of the event endpoint of a collector
+	eventPath = "/services/collector/event"
+
+	// source is the source of the events of the operator
+	source = "splunk-operator"
+
+	// minBackoff and maxBackoff bound the time the forwarder waits after a failed request
+	minBackoff = time.Second
+	maxBackoff = 5 * time.Minute
+
+	// requestTimeout is the time a collector is given to accept a batch
+	requestTimeout = 30 * time.Second
+)
+
+// Options configures the forwarding of the operator logs and metrics to a Splunk HTTP Event Collector
+type Options struct {
+	// URL is the URL of an external collector, ex. https://splunk-hec.example.com:8088
+	URL string
+
+	// TokenFile is the file with the token of the external collector, read before each request so that it can be rotated
+	TokenFile string
+
+	// Index is the index of the logs, the default index of the token when empty
+	Index string
+
+	// MetricsIndex is the metrics index the metrics are sent to, the metrics are not exported when empty
+	MetricsIndex string
+
+	// MetricsInterval is the time between two exports of the metrics
+	MetricsInterval time.Duration
+
+	// BatchSize is the number of events sent per request
+	BatchSize int
+
+	// FlushInterval is the time the events wait for a batch to fill up
+	FlushInterval time.Duration
+
+	// MaxBufferedEvents is the number of events kept while the collector is unavailable, the oldest ones are dropped
+	MaxBufferedEvents int
+
+	// CAFile is a PEM file with the certificate authorities the certificate of the collector is verified with
+	CAFile string
+
+	// InsecureSkipVerify disables the verification of the certificate of the collector
+	InsecureSkipVerify bool
+}
+
+// Endpoint is a collector and the token of its requests
+type Endpoint struct {
+	URL   string
+	Token string
+}
+
+// Target resolves the endpoint the events are sent to. It is resolved again after a failed request, so that the
+// forwarder follows the moves of the collector and the rotations of its token
+type Target interface {
+	Endpoint(ctx context.Context) (Endpoint, error)
+}
+
+// FileTarget is an external collector, with its token in a file
+type FileTarget struct {
+	URL       string
+	TokenFile string
+}
+
+// Endpoint returns the URL of the collector and the current content of the token file
+func (t FileTarget) Endpoint(ctx context.Context) (Endpoint, error) {
+	token, err := os.ReadFile(t.TokenFile)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("unable to read the HEC token: %w", err)
+	}
+	return Endpoint{URL: t.URL, Token: strings.TrimSpace(string(token))}, nil
+}
+
+// Event is the payload of an event sent to a collector
+type Event struct {
+	Time       float64                `json:"time"`
+	Host       string                 `json:"host,omitempty"`
+	Source     string                 `json:"source"`
+	SourceType string                 `json:"sourcetype,omitempty"`
+	Index      string                 `json:"index,omitempty"`
+	Event      interface{}            `json:"event"`
+	Fields     map[string]interface{} `json:"fields,omitempty"`
+}
+
+// Forwarder sends batches of events to a collector. The events are buffered while the collector is unavailable,
+// and the requests are retried with an exponential backoff. It is run by the manager of the operator.
+type Forwarder struct {
+	target Target
+	client *http.Client
+	opts   Options
+	host   string
+
+	// mutex protects the buffered events, it must not be held while logging since the logs are sent as events
+	mutex   sync.Mutex
+	events  [][]byte
+	dropped int
+
+	// flushMutex serializes the flushes of the Start loop and of the shutdown of the operator
+	flushMutex sync.Mutex
+	endpoint   *Endpoint
+
+	// batchReady wakes up the Start loop when a batch is full
+	batchReady chan struct{}
+}
+
+// NewForwarder returns a forwarder of events to a target, with the defaults applied to the unset options
+func NewForwarder(target Target, opts Options) (*Forwarder, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = DefaultFlushInterval
+	}
+	if opts.MaxBufferedEvents <= 0 {
+		opts.MaxBufferedEvents = DefaultMaxBufferedEvents
+	}
+	if opts.MetricsInterval <= 0 {
+		opts.MetricsInterval = DefaultMetricsInterval
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the HEC certificate authorities: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", opts.CAFile)
+		}
+	}
+
+	host, _ := os.Hostname()
+	return &Forwarder{
+		target:     target,
+		client:     &http.Client{Timeout: requestTimeout, Transport: &http.Transport{TLSClientConfig: tlsConfig}},
+		opts:       opts,
+		host:       host,
+		batchReady: make(chan struct{}, 1),
+	}, nil
+}
+
+// Send buffers an event until it is sent with its batch, dropping the oldest event when the buffer is full
+func (f *Forwarder) Send(event Event) {
+	if event.Host == "" {
+		event.Host = f.host
+	}
+	if event.Source == "" {
+		event.Source = source
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+
+	f.mutex.Lock()
+	f.events = append(f.events, payload)
+	if len(f.events) > f.opts.MaxBufferedEvents {
+		f.events = f.events[len(f.events)-f.opts.MaxBufferedEvents:]
+		f.dropped++
+	}
+	full := len(f.events) >= f.opts.BatchSize
+	f.mutex.Unlock()
+
+	if full {
+		select {
+		case f.batchReady <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Start sends the buffered events every flush interval or when a batch is full, until the context is done
+func (f *Forwarder) Start(ctx context.Context) error {
+	scopedLog := log.FromContext(ctx).WithName("hec")
+	ticker := time.NewTicker(f.opts.FlushInterval)
+	defer ticker.Stop()
+
+	var backoff time.Duration
+	var retryAt time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+			defer cancel()
+			if err := f.Flush(flushCtx); err != nil {
+				scopedLog.Error(err, "unable to send the last events to the HTTP Event Collector")
+			}
+			return nil
+		case <-ticker.C:
+		case <-f.batchReady:
+		}
+		if time.Now().Before(retryAt) {
+			continue
+		}
+
+		if err := f.Flush(ctx); err != nil {
+			backoff = nextBackoff(backoff)
+			retryAt = time.Now().Add(backoff)
+			scopedLog.Error(err, "unable to send the events to the HTTP Event Collector", "retryIn", backoff)
+		} else {
+			backoff = 0
+		}
+	}
+}
+
+// NeedLeaderElection returns false, every replica of the operator forwards its own logs
+func (f *Forwarder) NeedLeaderElection() bool {
+	return false
+}
+
+// Flush sends the events buffered when it is called in batches, and stops at the first batch which cannot be sent.
+// The batches the collector rejects as invalid are dropped, the other failed batches are kept to be sent again.
+// The events logged during the flush are left for the next one, so that rejected log events cannot loop.
+func (f *Forwarder) Flush(ctx context.Context) error {
+	f.flushMutex.Lock()
+	defer f.flushMutex.Unlock()
+
+	f.mutex.Lock()
+	pending := len(f.events)
+	f.mutex.Unlock()
+
+	for pending > 0 {
+		f.mutex.Lock()
+		size := pending
+		if size > f.opts.BatchSize {
+			size = f.opts.BatchSize
+		}
+		if size > len(f.events) {
+			size = len(f.events)
+		}
+		pending -= size
+		batch := f.events[:size:size]
+		f.events = f.events[size:]
+		dropped := f.dropped
+		f.dropped = 0
+		f.mutex.Unlock()
+
+		if dropped > 0 {
+			log.FromContext(ctx).WithName("hec").Info("events dropped while the HTTP Event Collector was unavailable", "count", dropped)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		retry, err := f.post(ctx, bytes.Join(batch, nil))
+		if err != nil && retry {
+			f.requeue(batch)
+			return err
+		}
+		if err != nil {
+			log.FromContext(ctx).WithName("hec").Error(err, "dropping the events rejected by the HTTP Event Collector", "count", len(batch))
+		}
+	}
+	return nil
+}
+
+// requeue puts back a batch which could not be sent before the events buffered since
+func (f *Forwarder) requeue(batch [][]byte) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	events := append(batch, f.events...)
+	if len(events) > f.opts.MaxBufferedEvents {
+		f.dropped += len(events) - f.opts.MaxBufferedEvents
+		events = events[len(events)-f.opts.MaxBufferedEvents:]
+	}
+	f.events = events
+}
+
+// post sends a batch to the endpoint of the target, and returns whether the batch should be sent again when it
+// fails. The endpoint is resolved again after a failure which may come from a moved collector or a rotated token.
+func (f *Forwarder) post(ctx context.Context, body []byte) (bool, error) {
+	if f.endpoint == nil {
+		endpoint, err := f.target.Endpoint(ctx)
+		if err != nil {
+			return true, err
+		}
+		f.endpoint = &endpoint
+	}
+
+	url := strings.TrimSuffix(f.endpoint.URL, "/") + eventPath
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		f.endpoint = nil
+		return false, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Splunk "+f.endpoint.Token)
+
+	response, err := f.client.Do(request)
+	if err != nil {
+		f.endpoint = nil
+		return true, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 200 && response.StatusCode <= 299 {
+		return false, nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 512))
+	err = fmt.Errorf("%s returned %s: %s", url, response.Status, strings.TrimSpace(string(message)))
+	switch response.StatusCode {
+	case http.StatusBadRequest:
+		return false, err
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		f.endpoint = nil
+	}
+	return true, err
+}
+
+// nextBackoff doubles the time waited after a failed request, up to maxBackoff
+func nextBackoff(backoff time.Duration) time.Duration {
+	backoff *= 2
+	if backoff < minBackoff {
+		return minBackoff
+	}
+	if backoff > maxBackoff {
+		return maxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,240 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hec
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// collector is a fake HTTP Event Collector answering with the status of its next responses
+type collector struct {
+	server        *httptest.Server
+	authorization []string
+	batches       [][]Event
+	statuses      []int
+}
+
+func newCollector(t *testing.T) *collector {
+	c := &collector{}
+	c.server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != eventPath {
+			t.Errorf("request to %s; want %s", r.URL.Path, eventPath)
+		}
+		c.authorization = append(c.authorization, r.Header.Get("Authorization"))
+		if len(c.statuses) > 0 {
+			status := c.statuses[0]
+			c.statuses = c.statuses[1:]
+			if status != http.StatusOK {
+				w.WriteHeader(status)
+				return
+			}
+		}
+		var batch []Event
+		decoder := json.NewDecoder(r.Body)
+		for {
+			var event Event
+			if err := decoder.Decode(&event); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("invalid event: %v", err)
+			}
+			batch = append(batch, event)
+		}
+		c.batches = append(c.batches, batch)
+	}))
+	t.Cleanup(c.server.Close)
+	return c
+}
+
+// tokenTarget resolves the fake collector with the tokens of its successive resolutions
+type tokenTarget struct {
+	url    string
+	tokens []string
+}
+
+func (t *tokenTarget) Endpoint(ctx context.Context) (Endpoint, error) {
+	token := t.tokens[0]
+	if len(t.tokens) > 1 {
+		t.tokens = t.tokens[1:]
+	}
+	return Endpoint{URL: t.url, Token: token}, nil
+}
+
+func TestForwarderFlush(t *testing.T) {
+	c := newCollector(t)
+	target := &tokenTarget{url: c.server.URL, tokens: []string{"old", "new"}}
+	forwarder, err := NewForwarder(target, Options{BatchSize: 2, MaxBufferedEvents: 4, InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatalf("NewForwarder() returned error: %v", err)
+	}
+
+	for _, message := range []string{"one", "two", "three"} {
+		forwarder.Send(Event{Event: message})
+	}
+	if err = forwarder.Flush(context.TODO()); err != nil {
+		t.Fatalf("Flush() returned error: %v", err)
+	}
+	if len(c.batches) != 2 || len(c.batches[0]) != 2 || len(c.batches[1]) != 1 || c.batches[1][0].Event != "three" {
+		t.Errorf("Flush() batches = %+v", c.batches)
+	}
+	if event := c.batches[0][0]; event.Source != source || event.Host == "" {
+		t.Errorf("Flush() event = %+v; want source %s and a host", event, source)
+	}
+
+	// a rejected token is resolved again, and the batch is kept for the next flush
+	c.statuses = []int{http.StatusForbidden}
+	forwarder.Send(Event{Event: "four"})
+	if err = forwarder.Flush(context.TODO()); err == nil {
+		t.Errorf("Flush() returned no error for a rejected token")
+	}
+	if err = forwarder.Flush(context.TODO()); err != nil {
+		t.Fatalf("Flush() returned error: %v", err)
+	}
+	if got := c.authorization[len(c.authorization)-1]; got != "Splunk new" || c.batches[2][0].Event != "four" {
+		t.Errorf("Flush() sent %+v with %q; want four with the new token", c.batches[2], got)
+	}
+
+	// an invalid batch is dropped
+	c.statuses = []int{http.StatusBadRequest}
+	forwarder.Send(Event{Event: "five"})
+	if err = forwarder.Flush(context.TODO()); err != nil {
+		t.Errorf("Flush() returned error for an invalid batch: %v", err)
+	}
+	if len(forwarder.events) != 0 {
+		t.Errorf("Flush() kept %d invalid events", len(forwarder.events))
+	}
+
+	// the oldest events are dropped while the collector is unavailable
+	c.statuses = []int{http.StatusServiceUnavailable}
+	for _, message := range []string{"a", "b", "c", "d", "e", "f"} {
+		forwarder.Send(Event{Event: message})
+	}
+	if len(forwarder.events) != 4 || forwarder.dropped != 2 {
+		t.Errorf("Send() kept %d events and dropped %d; want 4 and 2", len(forwarder.events), forwarder.dropped)
+	}
+	if err = forwarder.Flush(context.TODO()); err == nil {
+		t.Errorf("Flush() returned no error for an unavailable collector")
+	}
+	if len(forwarder.events) != 4 {
+		t.Errorf("Flush() kept %d events; want 4", len(forwarder.events))
+	}
+	if err = forwarder.Flush(context.TODO()); err != nil {
+		t.Fatalf("Flush() returned error: %v", err)
+	}
+	last := c.batches[len(c.batches)-2:]
+	if last[0][0].Event != "c" || last[1][1].Event != "f" {
+		t.Errorf("Flush() batches = %+v; want c to f", last)
+	}
+}
+
+func TestForwarderStart(t *testing.T) {
+	c := newCollector(t)
+	forwarder, _ := NewForwarder(&tokenTarget{url: c.server.URL, tokens: []string{"token"}}, Options{
+		BatchSize:          1,
+		FlushInterval:      time.Hour,
+		InsecureSkipVerify: true,
+	})
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan struct{})
+	go func() {
+		_ = forwarder.Start(ctx)
+		close(done)
+	}()
+	forwarder.Send(Event{Event: "one"})
+	cancel()
+	<-done
+
+	// the events are sent when a batch is full, or when the forwarder stops
+	if len(c.batches) != 1 || c.batches[0][0].Event != "one" {
+		t.Errorf("Start() batches = %+v", c.batches)
+	}
+	if forwarder.NeedLeaderElection() {
+		t.Errorf("NeedLeaderElection() = true; want false")
+	}
+}
+
+func TestNewForwarder(t *testing.T) {
+	if _, err := NewForwarder(FileTarget{}, Options{CAFile: filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Errorf("NewForwarder() returned no error for a missing CA file")
+	}
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	_ = os.WriteFile(caFile, []byte("not a certificate"), 0600)
+	if _, err := NewForwarder(FileTarget{}, Options{CAFile: caFile}); err == nil {
+		t.Errorf("NewForwarder() returned no error for a CA file without certificates")
+	}
+
+	forwarder, err := NewForwarder(FileTarget{}, Options{})
+	if err != nil {
+		t.Fatalf("NewForwarder() returned error: %v", err)
+	}
+	if forwarder.opts.BatchSize != DefaultBatchSize || forwarder.opts.FlushInterval != DefaultFlushInterval ||
+		forwarder.opts.MaxBufferedEvents != DefaultMaxBufferedEvents || forwarder.opts.MetricsInterval != DefaultMetricsInterval {
+		t.Errorf("NewForwarder() options = %+v; want the defaults", forwarder.opts)
+	}
+}
+
+func TestFileTarget(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	target := FileTarget{URL: "https://hec:8088", TokenFile: tokenFile}
+	if _, err := target.Endpoint(context.TODO()); err == nil {
+		t.Errorf("Endpoint() returned no error for a missing token file")
+	}
+
+	_ = os.WriteFile(tokenFile, []byte("token\n"), 0600)
+	endpoint, err := target.Endpoint(context.TODO())
+	if err != nil || endpoint.URL != "https://hec:8088" || endpoint.Token != "token" {
+		t.Errorf("Endpoint() = %+v, %v", endpoint, err)
+	}
+}
+
+func TestTeeLogs(t *testing.T) {
+	forwarder, _ := NewForwarder(FileTarget{}, Options{Index: "operator"})
+	logger := zap.NewNop().WithOptions(forwarder.TeeLogs(zapcore.InfoLevel))
+
+	logger.Debug("debug")
+	logger.Info("reconcile", zap.String("name", "s1"))
+	if len(forwarder.events) != 1 {
+		t.Fatalf("TeeLogs() buffered %d events; want 1", len(forwarder.events))
+	}
+
+	var event struct {
+		Index      string            `json:"index"`
+		SourceType string            `json:"sourcetype"`
+		Event      map[string]string `json:"event"`
+	}
+	if err := json.Unmarshal(forwarder.events[0], &event); err != nil {
+		t.Fatalf("invalid event %s: %v", forwarder.events[0], err)
+	}
+	if event.Index != "operator" || event.SourceType != logSourceType || event.Event["msg"] != "reconcile" || event.Event["name"] != "s1" {
+		t.Errorf("TeeLogs() event = %s", forwarder.events[0])
+	}
+	if !strings.HasPrefix(event.Event["ts"], "20") {
+		t.Errorf("TeeLogs() timestamp = %q; want RFC3339", event.Event["ts"])
+	}
+}
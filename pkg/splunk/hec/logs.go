@@ -0,0 +1,69 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hec
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// logSourceType is the sourcetype of the log events of the operator
+const logSourceType = "splunk:operator:log"
+
+// logWriter sends each log entry written by a zap JSON encoder as an event
+type logWriter struct {
+	forwarder *Forwarder
+	index     string
+}
+
+// Write sends a log entry as an event, the entry is copied since zap reuses its buffer
+func (w *logWriter) Write(p []byte) (int, error) {
+	entry := bytes.TrimSpace(p)
+	if len(entry) == 0 {
+		return len(p), nil
+	}
+	event := make(json.RawMessage, len(entry))
+	copy(event, entry)
+
+	w.forwarder.Send(Event{
+		Time:       float64(time.Now().UnixNano()) / float64(time.Second),
+		SourceType: logSourceType,
+		Index:      w.index,
+		Event:      event,
+	})
+	return len(p), nil
+}
+
+// Sync does nothing, the events are sent by the Start loop of the forwarder
+func (w *logWriter) Sync() error {
+	return nil
+}
+
+// TeeLogs returns a zap option also writing the log entries enabled by a level as JSON events to the forwarder,
+// in addition to the core of the logger
+func (f *Forwarder) TeeLogs(level zapcore.LevelEnabler) zap.Option {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	hecCore := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), &logWriter{forwarder: f, index: f.opts.Index}, level)
+
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(core, hecCore)
+	})
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hec
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// metricSourceType is the sourcetype of the metric events of the operator
+const metricSourceType = "splunk:operator:metrics"
+
+// MetricsExporter periodically sends the Prometheus metrics of the operator as Splunk metric events, each event
+// holding the samples of a metric sharing the same labels. It is run by the manager of the operator.
+type MetricsExporter struct {
+	Forwarder *Forwarder
+	Gatherer  prometheus.Gatherer
+}
+
+// Start exports the metrics every metrics interval of the forwarder until the context is done
+func (e *MetricsExporter) Start(ctx context.Context) error {
+	scopedLog := log.FromContext(ctx).WithName("hec")
+	ticker := time.NewTicker(e.Forwarder.opts.MetricsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			if err := e.export(now); err != nil {
+				scopedLog.Error(err, "unable to gather the metrics")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false, every replica of the operator exports its own metrics
+func (e *MetricsExporter) NeedLeaderElection() bool {
+	return false
+}
+
+// export sends the metrics gathered at a time to the forwarder
+func (e *MetricsExporter) export(now time.Time) error {
+	families, err := e.Gatherer.Gather()
+	timestamp := float64(now.UnixNano()) / float64(time.Second)
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, fields := range metricFields(family, metric) {
+				e.Forwarder.Send(Event{
+					Time:       timestamp,
+					SourceType: metricSourceType,
+					Index:      e.Forwarder.opts.MetricsIndex,
+					Event:      "metric",
+					Fields:     fields,
+				})
+			}
+		}
+	}
+	// the families gathered are sent even when some failed
+	return err
+}
+
+// metricFields returns the fields of the metric events of a sample, in the multiple-metric format where each
+// measurement is a metric_name:<name> field next to the labels. Histograms and summaries have one more event per
+// bucket or quantile, and the values which cannot be represented in JSON are left out.
+func metricFields(family *dto.MetricFamily, metric *dto.Metric) []map[string]interface{} {
+	var events []map[string]interface{}
+	addEvent := func(extraLabels map[string]string, values map[string]float64) {
+		fields := make(map[string]interface{}, len(metric.GetLabel())+len(extraLabels)+len(values))
+		for _, label := range metric.GetLabel() {
+			fields[label.GetName()] = label.GetValue()
+		}
+		for label, value := range extraLabels {
+			fields[label] = value
+		}
+		measured := false
+		for name, value := range values {
+			if !math.IsNaN(value) && !math.IsInf(value, 0) {
+				fields["metric_name:"+name] = value
+				measured = true
+			}
+		}
+		if measured {
+			events = append(events, fields)
+		}
+	}
+
+	name := family.GetName()
+	switch family.GetType() {
+	case dto.MetricType_COUNTER:
+		addEvent(nil, map[string]float64{name: metric.GetCounter().GetValue()})
+	case dto.MetricType_GAUGE:
+		addEvent(nil, map[string]float64{name: metric.GetGauge().GetValue()})
+	case dto.MetricType_UNTYPED:
+		addEvent(nil, map[string]float64{name: metric.GetUntyped().GetValue()})
+	case dto.MetricType_HISTOGRAM:
+		histogram := metric.GetHistogram()
+		addEvent(nil, map[string]float64{name + "_sum": histogram.GetSampleSum(), name + "_count": float64(histogram.GetSampleCount())})
+		for _, bucket := range histogram.GetBucket() {
+			addEvent(map[string]string{"le": formatFloat(bucket.GetUpperBound())},
+				map[string]float64{name + "_bucket": float64(bucket.GetCumulativeCount())})
+		}
+	case dto.MetricType_SUMMARY:
+		summary := metric.GetSummary()
+		addEvent(nil, map[string]float64{name + "_sum": summary.GetSampleSum(), name + "_count": float64(summary.GetSampleCount())})
+		for _, quantile := range summary.GetQuantile() {
+			addEvent(map[string]string{"quantile": formatFloat(quantile.GetQuantile())}, map[string]float64{name: quantile.GetValue()})
+		}
+	}
+	return events
+}
+
+// formatFloat formats a bucket bound or a quantile as in the Prometheus exposition format
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
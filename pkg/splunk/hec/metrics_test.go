@@ -0,0 +1,80 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hec
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMetricsExporter(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	reconciles := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "reconciles_total"}, []string{"kind"})
+	duration := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "request_duration_seconds", Buckets: []float64{0.1, 1}})
+	latency := prometheus.NewSummary(prometheus.SummaryOpts{Name: "latency_seconds", Objectives: map[float64]float64{0.5: 0.05}})
+	nan := prometheus.NewGauge(prometheus.GaugeOpts{Name: "nan"})
+	registry.MustRegister(reconciles, duration, latency, nan)
+	reconciles.WithLabelValues("Standalone").Add(3)
+	duration.Observe(0.5)
+	nan.Set(math.NaN())
+
+	forwarder, _ := NewForwarder(FileTarget{}, Options{MetricsIndex: "operator_metrics"})
+	exporter := &MetricsExporter{Forwarder: forwarder, Gatherer: registry}
+	if err := exporter.export(time.Unix(1664625600, 0)); err != nil {
+		t.Fatalf("export() returned error: %v", err)
+	}
+
+	fields := make(map[string]map[string]interface{})
+	for _, payload := range forwarder.events {
+		var event Event
+		if err := json.Unmarshal(payload, &event); err != nil {
+			t.Fatalf("invalid event %s: %v", payload, err)
+		}
+		if event.Event != "metric" || event.Index != "operator_metrics" || event.SourceType != metricSourceType || event.Time != 1664625600 {
+			t.Errorf("export() event = %s", payload)
+		}
+		for name := range event.Fields {
+			fields[name] = event.Fields
+		}
+	}
+
+	if got := fields["metric_name:reconciles_total"]; got["kind"] != "Standalone" || got["metric_name:reconciles_total"] != 3.0 {
+		t.Errorf("counter fields = %v", got)
+	}
+	if got := fields["metric_name:request_duration_seconds_sum"]; got["metric_name:request_duration_seconds_count"] != 1.0 {
+		t.Errorf("histogram fields = %v", got)
+	}
+	if got := fields["le"]; got == nil {
+		t.Errorf("no bucket event for the histogram")
+	}
+	// the summary without observations has a NaN quantile, which is left out with the NaN gauge
+	if _, ok := fields["quantile"]; ok {
+		t.Errorf("export() sent a NaN quantile")
+	}
+	if _, ok := fields["metric_name:nan"]; ok {
+		t.Errorf("export() sent a NaN gauge")
+	}
+	if len(forwarder.events) != 5 {
+		t.Errorf("export() sent %d events; want 5", len(forwarder.events))
+	}
+	if exporter.NeedLeaderElection() {
+		t.Errorf("NeedLeaderElection() = true; want false")
+	}
+}
@@ -0,0 +1,366 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package support
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	splutil "github.com/splunk/splunk-operator/pkg/splunk/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// splunkPrefix is the prefix of the names of the objects created by the operator
+	splunkPrefix = "splunk-"
+
+	// diagScript runs splunk diag in a pod, whose output ends with the path of the diag file
+	diagScript = "/opt/splunk/bin/splunk diag"
+)
+
+// operatorLabels select the pods of the operator
+var operatorLabels = labels.Set{"control-plane": "controller-manager"}
+
+// diagFilePattern matches the path of the diag file in the output of splunk diag
+var diagFilePattern = regexp.MustCompile(`Splunk diagnosis file created: (\S+)`)
+
+// customResourceKinds are the kinds of the custom resources collected
+var customResourceKinds = []schema.GroupVersionKind{
+	enterpriseApi.GroupVersion.WithKind("Standalone"),
+	enterpriseApi.GroupVersion.WithKind("IndexerCluster"),
+	enterpriseApi.GroupVersion.WithKind("ClusterManager"),
+	enterpriseApi.GroupVersion.WithKind("SearchHeadCluster"),
+	enterpriseApi.GroupVersion.WithKind("LicenseManager"),
+	enterpriseApi.GroupVersion.WithKind("MonitoringConsole"),
+	enterpriseApi.GroupVersion.WithKind("DeploymentServer"),
+	enterpriseApiV3.GroupVersion.WithKind("ClusterMaster"),
+	enterpriseApiV3.GroupVersion.WithKind("LicenseMaster"),
+}
+
+// Options configures the content of a support bundle
+type Options struct {
+	// Namespaces of the custom resources
+	Namespaces []string
+
+	// OperatorNamespace is the namespace of the operator, whose logs are collected when set
+	OperatorNamespace string
+
+	// DiagPods are the names of the pods splunk diag is run on
+	DiagPods []string
+
+	// LogTailLines is the number of lines of the logs of each container, all the lines when 0
+	LogTailLines int64
+}
+
+// PodExecFunc runs a shell script in the splunk container of a pod, and returns its stdout and stderr
+type PodExecFunc func(ctx context.Context, namespace, podName, script string) (string, string, error)
+
+// Collector collects the support bundle of the Splunk Enterprise deployments of some namespaces
+type Collector struct {
+	Options
+
+	// Client reads the Kubernetes objects
+	Client client.Client
+
+	// Clientset reads the logs of the pods
+	Clientset kubernetes.Interface
+
+	// PodExec runs splunk diag in the pods
+	PodExec PodExecFunc
+
+	redactor *redactor
+	files    []bundleFile
+	errs     []string
+}
+
+// bundleFile is a file of a support bundle
+type bundleFile struct {
+	name string
+	data []byte
+}
+
+// NewCollector returns a collector of a support bundle running splunk diag with PodExecCommand
+func NewCollector(c client.Client, clientset kubernetes.Interface, opts Options) *Collector {
+	return &Collector{
+		Options:   opts,
+		Client:    c,
+		Clientset: clientset,
+		PodExec: func(ctx context.Context, namespace, podName, script string) (string, string, error) {
+			return splutil.PodExecCommand(ctx, c, podName, namespace, []string{"/bin/sh"}, splutil.NewStreamOptionsObject(script), false, false)
+		},
+	}
+}
+
+// Collect writes the support bundle as a gzipped tar archive whose files are under root. The collection goes on
+// when some objects or logs cannot be read, their errors are listed in the errors.txt file of the archive.
+func (c *Collector) Collect(ctx context.Context, w io.Writer, root string) error {
+	c.redactor = newRedactor()
+	c.files = nil
+	c.errs = nil
+
+	// the secrets are collected first, so that their values are known before any other file is redacted
+	for _, namespace := range c.Namespaces {
+		c.collectSecrets(ctx, namespace)
+	}
+	for _, namespace := range c.Namespaces {
+		c.collectCustomResources(ctx, namespace)
+		c.collectObjects(ctx, namespace)
+		c.collectEvents(ctx, namespace)
+		c.collectPodLogs(ctx, namespace, path.Join(namespace, "logs"), client.ListOptions{Namespace: namespace})
+	}
+	if c.OperatorNamespace != "" {
+		opts := client.ListOptions{Namespace: c.OperatorNamespace, LabelSelector: labels.SelectorFromSet(operatorLabels)}
+		c.collectPodLogs(ctx, c.OperatorNamespace, path.Join("operator", "logs"), opts)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, file := range c.files {
+		if err := writeFile(tw, path.Join(root, file.name), c.redactor.redact(file.data)); err != nil {
+			return err
+		}
+	}
+
+	// the diags are written as they are collected, as they can be large
+	for _, podName := range c.DiagPods {
+		found := false
+		for _, namespace := range c.Namespaces {
+			pod := corev1.Pod{}
+			if err := c.Client.Get(ctx, client.ObjectKey{Namespace: namespace, Name: podName}, &pod); err != nil {
+				continue
+			}
+			found = true
+			if err := c.collectDiag(ctx, tw, root, namespace, podName); err != nil {
+				return err
+			}
+		}
+		if !found {
+			c.errs = append(c.errs, fmt.Sprintf("unable to run splunk diag on pod %s: pod not found", podName))
+		}
+	}
+
+	if len(c.errs) > 0 {
+		if err := writeFile(tw, path.Join(root, "errors.txt"), c.redactor.redact([]byte(strings.Join(c.errs, "\n")+"\n"))); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// addError records an error of the collection
+func (c *Collector) addError(err error, format string, args ...interface{}) {
+	c.errs = append(c.errs, fmt.Sprintf(format, args...)+": "+err.Error())
+}
+
+// addObject adds a Kubernetes object of a namespace to the bundle as a YAML file, in the directory of its kind
+func (c *Collector) addObject(namespace string, obj client.Object) {
+	gvk, err := apiutil.GVKForObject(obj, c.Client.Scheme())
+	if err != nil {
+		c.addError(err, "unable to get the kind of %s/%s", namespace, obj.GetName())
+		return
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetManagedFields(nil)
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		c.addError(err, "unable to marshal %s %s/%s", gvk.Kind, namespace, obj.GetName())
+		return
+	}
+	c.files = append(c.files, bundleFile{name: path.Join(namespace, gvk.Kind, obj.GetName()+".yaml"), data: data})
+}
+
+// collectSecrets adds the secrets of the operator in a namespace without their values, which are redacted
+// from the other files
+func (c *Collector) collectSecrets(ctx context.Context, namespace string) {
+	secrets := corev1.SecretList{}
+	if err := c.Client.List(ctx, &secrets, client.InNamespace(namespace)); err != nil {
+		c.addError(err, "unable to list the secrets of namespace %s", namespace)
+		return
+	}
+	for i := range secrets.Items {
+		if strings.HasPrefix(secrets.Items[i].GetName(), splunkPrefix) {
+			c.addObject(namespace, c.redactor.addSecret(&secrets.Items[i]))
+		}
+	}
+}
+
+// collectCustomResources adds the custom resources of a namespace, and the state of their App Framework
+func (c *Collector) collectCustomResources(ctx context.Context, namespace string) {
+	for _, gvk := range customResourceKinds {
+		list := unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.Client.List(ctx, &list, client.InNamespace(namespace)); err != nil {
+			c.addError(err, "unable to list the %s resources of namespace %s", gvk.Kind, namespace)
+			continue
+		}
+		for i := range list.Items {
+			cr := &list.Items[i]
+			c.addObject(namespace, cr)
+
+			appContext, found, _ := unstructured.NestedMap(cr.Object, "status", "appContext")
+			if !found {
+				continue
+			}
+			data, err := yaml.Marshal(appContext)
+			if err != nil {
+				c.addError(err, "unable to marshal the App Framework state of %s %s/%s", gvk.Kind, namespace, cr.GetName())
+				continue
+			}
+			c.files = append(c.files, bundleFile{name: path.Join(namespace, "appframework", gvk.Kind+"-"+cr.GetName()+".yaml"), data: data})
+		}
+	}
+}
+
+// collectObjects adds the objects created by the operator in a namespace, and the probe scripts
+func (c *Collector) collectObjects(ctx context.Context, namespace string) {
+	lists := []client.ObjectList{
+		&appsv1.StatefulSetList{},
+		&corev1.PodList{},
+		&corev1.ServiceList{},
+		&corev1.ConfigMapList{},
+		&corev1.PersistentVolumeClaimList{},
+	}
+	for _, list := range lists {
+		if err := c.Client.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			c.addError(err, "unable to list the %T of namespace %s", list, namespace)
+			continue
+		}
+		items, _ := meta.ExtractList(list)
+		for _, item := range items {
+			obj, ok := item.(client.Object)
+			if !ok || !strings.HasPrefix(obj.GetName(), splunkPrefix) {
+				continue
+			}
+			c.addObject(namespace, obj)
+
+			// the probe scripts are also added as files, so that they can be read and run as is
+			if configMap, ok := obj.(*corev1.ConfigMap); ok && configMap.GetName() == fmt.Sprintf("splunk-%s-probe-configmap", namespace) {
+				for name, script := range configMap.Data {
+					c.files = append(c.files, bundleFile{name: path.Join(namespace, "probes", name), data: []byte(script)})
+				}
+			}
+		}
+	}
+}
+
+// collectEvents adds the events of a namespace
+func (c *Collector) collectEvents(ctx context.Context, namespace string) {
+	events := corev1.EventList{}
+	if err := c.Client.List(ctx, &events, client.InNamespace(namespace)); err != nil {
+		c.addError(err, "unable to list the events of namespace %s", namespace)
+		return
+	}
+	var lines []string
+	for _, event := range events.Items {
+		timestamp := event.LastTimestamp.Time
+		if timestamp.IsZero() {
+			timestamp = event.EventTime.Time
+		}
+		lines = append(lines, fmt.Sprintf("%s\t%s\t%s\t%s/%s\tx%d\t%s", timestamp.UTC().Format(time.RFC3339),
+			event.Type, event.Reason, event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Count, event.Message))
+	}
+	sort.Strings(lines)
+	c.files = append(c.files, bundleFile{name: path.Join(namespace, "events.txt"), data: []byte(strings.Join(lines, "\n") + "\n")})
+}
+
+// collectPodLogs adds the logs of the containers of the pods selected
+func (c *Collector) collectPodLogs(ctx context.Context, namespace, dir string, opts client.ListOptions) {
+	pods := corev1.PodList{}
+	if err := c.Client.List(ctx, &pods, &opts); err != nil {
+		c.addError(err, "unable to list the pods of namespace %s", namespace)
+		return
+	}
+	for _, pod := range pods.Items {
+		if opts.LabelSelector == nil && !strings.HasPrefix(pod.GetName(), splunkPrefix) {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			logOpts := corev1.PodLogOptions{Container: container.Name}
+			if c.LogTailLines > 0 {
+				logOpts.TailLines = &c.LogTailLines
+			}
+			data, err := c.Clientset.CoreV1().Pods(namespace).GetLogs(pod.GetName(), &logOpts).DoRaw(ctx)
+			if err != nil {
+				c.addError(err, "unable to read the logs of container %s of pod %s/%s", container.Name, namespace, pod.GetName())
+				continue
+			}
+			c.files = append(c.files, bundleFile{name: path.Join(dir, pod.GetName(), container.Name+".log"), data: data})
+		}
+	}
+}
+
+// collectDiag runs splunk diag in a pod, and writes the diag file to the archive. The diag file is removed
+// from the pod afterwards.
+func (c *Collector) collectDiag(ctx context.Context, tw *tar.Writer, root, namespace, podName string) error {
+	stdout, stderr, err := c.PodExec(ctx, namespace, podName, diagScript)
+	match := diagFilePattern.FindStringSubmatch(stdout)
+	if err != nil || match == nil {
+		if err == nil {
+			err = fmt.Errorf("diag file not found in output: %s", stderr)
+		}
+		c.addError(err, "unable to run splunk diag on pod %s/%s", namespace, podName)
+		return nil
+	}
+
+	diagFile := match[1]
+	data, stderr, err := c.PodExec(ctx, namespace, podName, "cat "+diagFile)
+	if err != nil {
+		c.addError(fmt.Errorf("%v: %s", err, stderr), "unable to read the diag file %s of pod %s/%s", diagFile, namespace, podName)
+	} else if err = writeFile(tw, path.Join(root, namespace, "diags", podName, path.Base(diagFile)), []byte(data)); err != nil {
+		return err
+	}
+	if _, stderr, err = c.PodExec(ctx, namespace, podName, "rm -f "+diagFile); err != nil {
+		c.addError(fmt.Errorf("%v: %s", err, stderr), "unable to remove the diag file %s of pod %s/%s", diagFile, namespace, podName)
+	}
+	return nil
+}
+
+// writeFile writes a file to a tar archive
+func writeFile(tw *tar.Writer, name string, data []byte) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package support
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	enterpriseApiV3 "github.com/splunk/splunk-operator/api/v3"
+	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// readBundle returns the content of the files of a support bundle by name
+func readBundle(t *testing.T, data []byte) map[string]string {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("invalid gzip archive: %v", err)
+	}
+	files := map[string]string{}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return files
+		}
+		if err != nil {
+			t.Fatalf("invalid tar archive: %v", err)
+		}
+		content, _ := io.ReadAll(tr)
+		files[header.Name] = string(content)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = enterpriseApi.AddToScheme(scheme)
+	_ = enterpriseApiV3.AddToScheme(scheme)
+
+	standalone := &enterpriseApi.Standalone{ObjectMeta: metav1.ObjectMeta{Name: "s1", Namespace: "test"}}
+	standalone.Status.AppContext.AppsSrcDeployStatus = map[string]enterpriseApi.AppSrcDeployInfo{
+		"apps": {AppDeploymentInfoList: []enterpriseApi.AppDeploymentInfo{{AppName: "app1.tgz", DeployStatus: enterpriseApi.DeployStatusComplete}}},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "splunk-s1-standalone-0", Namespace: "test"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "splunk"}}},
+	}
+	operatorPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "splunk-operator-controller-manager-0", Namespace: "splunk-operator", Labels: operatorLabels},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "manager"}}},
+	}
+	objects := []runtime.Object{
+		standalone,
+		pod,
+		operatorPod,
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "splunk-test-secret", Namespace: "test"},
+			Data:       map[string][]byte{"password": []byte("changeme123"), "pass4SymmKey": []byte("symmkey456")},
+		},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test"}},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "splunk-test-probe-configmap", Namespace: "test"},
+			Data:       map[string]string{"livenessProbe.sh": "#!/bin/bash\nexit 0\n"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "splunk-s1-standalone-defaults", Namespace: "test"},
+			Data:       map[string]string{"default.yml": "splunk:\n  password: changeme123\n  idxc:\n    secret: symmkey456\n"},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "s1.1", Namespace: "test"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Standalone", Name: "s1"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "PodExecCommand",
+			Message:        "Failed with changeme123",
+			Count:          2,
+		},
+	}
+	c := fakeclient.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build()
+	clientset := fake.NewSimpleClientset(pod, operatorPod)
+
+	collector := NewCollector(c, clientset, Options{
+		Namespaces:        []string{"test"},
+		OperatorNamespace: "splunk-operator",
+		DiagPods:          []string{"splunk-s1-standalone-0", "splunk-missing-0"},
+		LogTailLines:      100,
+	})
+	var scripts []string
+	collector.PodExec = func(ctx context.Context, namespace, podName, script string) (string, string, error) {
+		scripts = append(scripts, podName+": "+script)
+		switch {
+		case script == diagScript:
+			return "Collecting components...\nSplunk diagnosis file created: /opt/splunk/diag-splunk-s1-standalone-0.tar.gz\n", "", nil
+		case strings.HasPrefix(script, "cat "):
+			return "diag content", "", nil
+		}
+		return "", "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := collector.Collect(context.TODO(), &buf, "bundle"); err != nil {
+		t.Fatalf("Collect() returned error: %v", err)
+	}
+	files := readBundle(t, buf.Bytes())
+
+	for _, name := range []string{
+		"bundle/test/Standalone/s1.yaml",
+		"bundle/test/appframework/Standalone-s1.yaml",
+		"bundle/test/Pod/splunk-s1-standalone-0.yaml",
+		"bundle/test/ConfigMap/splunk-test-probe-configmap.yaml",
+		"bundle/test/probes/livenessProbe.sh",
+		"bundle/test/Secret/splunk-test-secret.yaml",
+		"bundle/test/events.txt",
+		"bundle/test/logs/splunk-s1-standalone-0/splunk.log",
+		"bundle/operator/logs/splunk-operator-controller-manager-0/manager.log",
+		"bundle/test/diags/splunk-s1-standalone-0/diag-splunk-s1-standalone-0.tar.gz",
+		"bundle/errors.txt",
+	} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("file %s missing from the bundle", name)
+		}
+	}
+	if _, ok := files["bundle/test/Secret/other.yaml"]; ok {
+		t.Errorf("secret not created by the operator collected")
+	}
+	for name, content := range files {
+		if strings.Contains(content, "changeme123") || strings.Contains(content, "symmkey456") {
+			t.Errorf("secret value not redacted in %s:\n%s", name, content)
+		}
+	}
+	if !strings.Contains(files["bundle/test/appframework/Standalone-s1.yaml"], "app1.tgz") {
+		t.Errorf("app framework state = %s", files["bundle/test/appframework/Standalone-s1.yaml"])
+	}
+	if !strings.Contains(files["bundle/test/events.txt"], "Warning\tPodExecCommand\tStandalone/s1\tx2\tFailed with <redacted>") {
+		t.Errorf("events = %s", files["bundle/test/events.txt"])
+	}
+	if files["bundle/test/diags/splunk-s1-standalone-0/diag-splunk-s1-standalone-0.tar.gz"] != "diag content" {
+		t.Errorf("diag not copied from the pod")
+	}
+	if !strings.Contains(files["bundle/errors.txt"], "splunk-missing-0: pod not found") {
+		t.Errorf("errors = %s", files["bundle/errors.txt"])
+	}
+	want := []string{
+		"splunk-s1-standalone-0: " + diagScript,
+		"splunk-s1-standalone-0: cat /opt/splunk/diag-splunk-s1-standalone-0.tar.gz",
+		"splunk-s1-standalone-0: rm -f /opt/splunk/diag-splunk-s1-standalone-0.tar.gz",
+	}
+	if strings.Join(scripts, "\n") != strings.Join(want, "\n") {
+		t.Errorf("scripts run = %v; want %v", scripts, want)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package support
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// redactedValue replaces the secret values in the files of a support bundle
+const redactedValue = "<redacted>"
+
+// minRedactedLength is the length under which the values of the secrets are not redacted where they appear,
+// as they would match too many unrelated strings
+const minRedactedLength = 6
+
+// redactor redacts the tokens of the Splunk secrets from the files of a support bundle
+type redactor struct {
+	// values of the tokens of the secrets, redacted wherever they appear
+	values [][]byte
+
+	// pattern matches the token types assigned a value, e.g. "password: value" or "pass4SymmKey = value"
+	pattern *regexp.Regexp
+}
+
+// newRedactor returns a redactor of the token types of GetSplunkSecretTokenTypes
+func newRedactor() *redactor {
+	var tokens []string
+	for _, tokenType := range splcommon.GetSplunkSecretTokenTypes() {
+		tokens = append(tokens, regexp.QuoteMeta(tokenType))
+	}
+	return &redactor{
+		pattern: regexp.MustCompile(`(?i)(\b(?:` + strings.Join(tokens, "|") + `)["']?\s*[:=]\s*["']?)([^"'\s,}]+)`),
+	}
+}
+
+// addSecret records the values of the tokens of a secret, and returns a copy of the secret without its values
+func (r *redactor) addSecret(secret *corev1.Secret) *corev1.Secret {
+	for _, tokenType := range splcommon.GetSplunkSecretTokenTypes() {
+		if value := secret.Data[tokenType]; len(value) >= minRedactedLength {
+			r.values = append(r.values, value)
+		}
+	}
+	sort.Slice(r.values, func(i, j int) bool { return len(r.values[i]) > len(r.values[j]) })
+
+	// the keys are kept, with a readable value
+	redacted := secret.DeepCopy()
+	redacted.Data = nil
+	redacted.StringData = make(map[string]string, len(secret.Data)+len(secret.StringData))
+	for key := range secret.Data {
+		redacted.StringData[key] = redactedValue
+	}
+	for key := range secret.StringData {
+		redacted.StringData[key] = redactedValue
+	}
+	return redacted
+}
+
+// redact replaces the values of the tokens in data
+func (r *redactor) redact(data []byte) []byte {
+	for _, value := range r.values {
+		data = bytes.ReplaceAll(data, value, []byte(redactedValue))
+	}
+	return r.pattern.ReplaceAll(data, []byte("${1}"+redactedValue))
+}
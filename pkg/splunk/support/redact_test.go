@@ -0,0 +1,53 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package support
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestRedactor(t *testing.T) {
+	r := newRedactor()
+	secret := &corev1.Secret{Data: map[string][]byte{
+		"password":    []byte("changeme123"),
+		"hec_token":   []byte("0000-1111"),
+		"tls.crt":     []byte("certificate"),
+		"idxc_secret": []byte("abc"),
+	}}
+	redacted := r.addSecret(secret)
+	if redacted.Data != nil || len(redacted.StringData) != 4 || redacted.StringData["password"] != redactedValue {
+		t.Errorf("addSecret() = %v; want all the values redacted", redacted.StringData)
+	}
+	if string(secret.Data["password"]) != "changeme123" {
+		t.Errorf("addSecret() changed the secret")
+	}
+
+	for _, test := range []struct{ data, want string }{
+		{"login with changeme123 failed", "login with <redacted> failed"},
+		{"token 0000-1111 and 0000-1111", "token <redacted> and <redacted>"},
+		{"password: other", "password: <redacted>"},
+		{"pass4SymmKey = $7$abc\nsite = site1", "pass4SymmKey = <redacted>\nsite = site1"},
+		{`{"shc_secret":"xyz","name":"shc"}`, `{"shc_secret":"<redacted>","name":"shc"}`},
+		{"certificate and abc are kept", "certificate and abc are kept"},
+		{"passwordFile: /mnt/splunk-secrets/password", "passwordFile: /mnt/splunk-secrets/password"},
+	} {
+		if got := string(r.redact([]byte(test.data))); got != test.want {
+			t.Errorf("redact(%q) = %q; want %q", test.data, got, test.want)
+		}
+	}
+}
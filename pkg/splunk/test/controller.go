@@ -27,9 +27,12 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
@@ -39,7 +42,7 @@ import (
 )
 
 func init() {
-	MockObjectCopiers = append(MockObjectCopiers, coreObjectCopier, appsObjectCopier, enterpriseObjCopier)
+	MockObjectCopiers = append(MockObjectCopiers, coreObjectCopier, appsObjectCopier, enterpriseObjCopier, networkingObjectCopier, storageObjectCopier, unstructuredObjectCopier)
 	MockObjectListCopiers = append(MockObjectListCopiers, coreObjectListCopier, enterpriseObjListCopier)
 }
 
@@ -71,6 +74,8 @@ func enterpriseObjCopier(dst, src *client.Object) bool {
 		*dstP.(*enterpriseApi.SearchHeadCluster) = *srcP.(*enterpriseApi.SearchHeadCluster)
 	case *enterpriseApi.MonitoringConsole:
 		*dstP.(*enterpriseApi.MonitoringConsole) = *srcP.(*enterpriseApi.MonitoringConsole)
+	case *enterpriseApi.DeploymentServer:
+		*dstP.(*enterpriseApi.DeploymentServer) = *srcP.(*enterpriseApi.DeploymentServer)
 	default:
 		return false
 	}
@@ -130,6 +135,8 @@ func enterpriseObjListCopier(dst, src *client.ObjectList) bool {
 		*dstP.(*enterpriseApi.StandaloneList) = *srcP.(*enterpriseApi.StandaloneList)
 	case *enterpriseApi.MonitoringConsoleList:
 		*dstP.(*enterpriseApi.MonitoringConsoleList) = *srcP.(*enterpriseApi.MonitoringConsoleList)
+	case *enterpriseApi.DeploymentServerList:
+		*dstP.(*enterpriseApi.DeploymentServerList) = *srcP.(*enterpriseApi.DeploymentServerList)
 	default:
 		return false
 	}
@@ -168,6 +175,45 @@ func appsObjectCopier(dst, src *client.Object) bool {
 	return true
 }
 
+// networkingObjectCopier is used to copy networkingv1 client.Objects
+func networkingObjectCopier(dst, src *client.Object) bool {
+	srcP := *src
+	dstP := *dst
+	switch srcP.(type) {
+	case *networkingv1.Ingress:
+		*dstP.(*networkingv1.Ingress) = *srcP.(*networkingv1.Ingress)
+	default:
+		return false
+	}
+	return true
+}
+
+// storageObjectCopier is used to copy storagev1 client.Objects
+func storageObjectCopier(dst, src *client.Object) bool {
+	srcP := *src
+	dstP := *dst
+	switch srcP.(type) {
+	case *storagev1.StorageClass:
+		*dstP.(*storagev1.StorageClass) = *srcP.(*storagev1.StorageClass)
+	default:
+		return false
+	}
+	return true
+}
+
+// unstructuredObjectCopier is used to copy unstructured client.Objects
+func unstructuredObjectCopier(dst, src *client.Object) bool {
+	srcP := *src
+	dstP := *dst
+	switch srcP.(type) {
+	case *unstructured.Unstructured:
+		*dstP.(*unstructured.Unstructured) = *srcP.(*unstructured.Unstructured).DeepCopy()
+	default:
+		return false
+	}
+	return true
+}
+
 // copyMockObject uses the global MockObjectCopiers to perform the typed copy of a client.Object from src to dst
 func copyMockObject(dst, src *client.Object) {
 	for n := range MockObjectCopiers {
@@ -445,8 +491,8 @@ func NewMockClient() *MockClient {
 // getStateKeyFromObject returns a lookup key for the MockClient's state map
 func getStateKey(obj client.Object) string {
 	key := client.ObjectKey{
-		Name:      obj.(splcommon.MetaObject).GetName(),
-		Namespace: obj.(splcommon.MetaObject).GetNamespace(),
+		Name:      obj.GetName(),
+		Namespace: obj.GetNamespace(),
 	}
 	return getStateKeyWithKey(key, obj)
 }
@@ -496,6 +542,10 @@ func testReconcileForResource(t *testing.T, c *MockClient, methodPlus string, re
 	case *enterpriseApi.SearchHeadCluster:
 		cr := resource.(*enterpriseApi.SearchHeadCluster)
 		c.Create(context.Background(), cr)
+
+	case *enterpriseApi.DeploymentServer:
+		cr := resource.(*enterpriseApi.DeploymentServer)
+		c.Create(context.Background(), cr)
 	}
 
 	c.ResetCalls()
@@ -0,0 +1,109 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// tracerName is the name of the instrumentation library of the spans of the operator
+	tracerName = "github.com/splunk/splunk-operator"
+
+	// serviceName is the name of the service reported with the spans of the operator
+	serviceName = "splunk-operator"
+)
+
+// Options configures the export of the spans
+type Options struct {
+	// Endpoint is the host:port of the OTLP gRPC receiver, tracing is disabled when empty
+	Endpoint string
+
+	// Insecure disables TLS for the connection to the receiver
+	Insecure bool
+
+	// SampleRatio is the ratio of the reconciles traced, between 0 and 1
+	SampleRatio float64
+}
+
+// Setup exports the spans of the operator over OTLP, and returns a function flushing the spans left and
+// stopping the export. Tracing is left disabled, with spans that are not recorded, when no endpoint is set.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	if opts.SampleRatio < 0 || opts.SampleRatio > 1 {
+		return nil, fmt.Errorf("invalid trace sample ratio %v, must be between 0 and 1", opts.SampleRatio)
+	}
+
+	clientOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+	if opts.Insecure {
+		clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+	}
+	exporter, err := otlptracegrpc.New(ctx, clientOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the OTLP trace exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// Start starts a span of the operator, child of the span of the context if any
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartReconcile starts the root span of the reconcile of a custom resource, and adds its trace ID to the
+// logger of the context, so that the log lines of the reconcile can be matched with its trace
+func StartReconcile(ctx context.Context, kind, namespace, name string) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "Reconcile "+kind, trace.WithNewRoot(), trace.WithAttributes(
+		attribute.String("k8s.kind", kind),
+		semconv.K8SNamespaceNameKey.String(namespace),
+		attribute.String("k8s.name", name),
+	))
+	if spanContext := span.SpanContext(); spanContext.IsValid() {
+		ctx = log.IntoContext(ctx, log.FromContext(ctx).WithValues("trace_id", spanContext.TraceID().String()))
+	}
+	return ctx, span
+}
+
+// RecordError marks a span as failed with an error, if any
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2018-2022 Splunk Inc. All rights reserved.
+
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 	http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestSetup(t *testing.T) {
+	shutdown, err := Setup(context.TODO(), Options{})
+	if err != nil {
+		t.Errorf("Setup() without endpoint returned error: %v", err)
+	}
+	if err = shutdown(context.TODO()); err != nil {
+		t.Errorf("shutdown() of disabled tracing returned error: %v", err)
+	}
+
+	_, err = Setup(context.TODO(), Options{Endpoint: "localhost:4317", SampleRatio: 2})
+	if err == nil {
+		t.Errorf("Setup() with sample ratio 2 returned no error")
+	}
+}
+
+func TestStartReconcile(t *testing.T) {
+	var lines []string
+	logger := funcr.New(func(prefix, args string) { lines = append(lines, args) }, funcr.Options{})
+
+	// spans are not recorded, and log lines have no trace ID, while tracing is disabled
+	ctx, span := StartReconcile(log.IntoContext(context.TODO(), logger), "Standalone", "test", "stack1")
+	if span.IsRecording() {
+		t.Errorf("span recorded while tracing is disabled")
+	}
+	log.FromContext(ctx).Info("reconcile")
+	span.End()
+	if strings.Contains(lines[0], "trace_id") {
+		t.Errorf("log line %s has a trace ID while tracing is disabled", lines[0])
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer otel.SetTracerProvider(otel.GetTracerProvider())
+	otel.SetTracerProvider(provider)
+
+	ctx, span = StartReconcile(log.IntoContext(context.TODO(), logger), "Standalone", "test", "stack1")
+	log.FromContext(ctx).Info("reconcile")
+	_, child := Start(ctx, "ApplyService")
+	child.End()
+	RecordError(span, errors.New("unable to apply service"))
+	span.End()
+
+	traceID := span.SpanContext().TraceID().String()
+	if !strings.Contains(lines[1], `"trace_id"="`+traceID+`"`) {
+		t.Errorf("log line %s; want trace_id %s", lines[1], traceID)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("%d spans ended; want 2", len(spans))
+	}
+	if spans[0].Name() != "ApplyService" || spans[0].Parent().SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("span %s is not the child of the reconcile", spans[0].Name())
+	}
+	if spans[1].Name() != "Reconcile Standalone" {
+		t.Errorf("span name = %s; want Reconcile Standalone", spans[1].Name())
+	}
+	if spans[1].Status().Code != codes.Error || len(spans[1].Events()) != 1 {
+		t.Errorf("error not recorded in span %s", spans[1].Name())
+	}
+	attrs := map[string]string{}
+	for _, attr := range spans[1].Attributes() {
+		attrs[string(attr.Key)] = attr.Value.AsString()
+	}
+	if attrs["k8s.kind"] != "Standalone" || attrs["k8s.namespace.name"] != "test" || attrs["k8s.name"] != "stack1" {
+		t.Errorf("span attributes = %v", attrs)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -379,6 +380,9 @@ splunk:
 
 // ApplySplunkSecret creates/updates a secret using secretData(which HAS to be of ansible readable format) or namespace scoped secret data if not specified
 func ApplySplunkSecret(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, secretData map[string][]byte, secretName string, namespace string) (*corev1.Secret, error) {
+	ctx, span := tracing.Start(ctx, "ApplySplunkSecret")
+	defer span.End()
+
 	var current corev1.Secret
 	var newSecretData map[string][]byte
 	var err error
@@ -435,6 +439,9 @@ func ApplySplunkSecret(ctx context.Context, c splcommon.ControllerClient, cr spl
 
 // ApplyNamespaceScopedSecretObject creates/updates the namespace scoped K8S secret object
 func ApplyNamespaceScopedSecretObject(ctx context.Context, client splcommon.ControllerClient, namespace string) (*corev1.Secret, error) {
+	ctx, span := tracing.Start(ctx, "ApplyNamespaceScopedSecretObject")
+	defer span.End()
+
 	var current corev1.Secret
 
 	name := splcommon.GetNamespaceScopedSecretName(namespace)
@@ -530,3 +537,58 @@ func GetSecretByName(ctx context.Context, c splcommon.ControllerClient, namespac
 
 	return &namespaceScopedSecret, nil
 }
+
+// ApplyHecTokenSecret creates a secret holding a generated HEC token if it does not exist yet, existing token values are never rotated
+func ApplyHecTokenSecret(ctx context.Context, c splcommon.ControllerClient, cr splcommon.MetaObject, secretName string, labels map[string]string) (*corev1.Secret, error) {
+	ctx, span := tracing.Start(ctx, "ApplyHecTokenSecret")
+	defer span.End()
+
+	var current corev1.Secret
+
+	reqLogger := log.FromContext(ctx)
+	scopedLog := reqLogger.WithName("ApplyHecTokenSecret").WithValues(
+		"name", secretName,
+		"namespace", cr.GetNamespace())
+
+	namespacedName := types.NamespacedName{Namespace: cr.GetNamespace(), Name: secretName}
+	err := c.Get(ctx, namespacedName, &current)
+	if err == nil {
+		if _, ok := current.Data[splcommon.HecTokenSecretKey]; ok {
+			return &current, nil
+		}
+
+		// Secret exists without a token, generate one
+		scopedLog.Info("HEC token secret exists, missing value for token")
+		if current.Data == nil {
+			current.Data = make(map[string][]byte)
+		}
+		current.Data[splcommon.HecTokenSecretKey] = generateHECToken()
+		err = UpdateResource(ctx, c, &current)
+		if err != nil {
+			return nil, err
+		}
+		return &current, nil
+	} else if !k8serrors.IsNotFound(err) {
+		return nil, err
+	}
+
+	scopedLog.Info("HEC token secret does not exist, creating it")
+	current = corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: cr.GetNamespace(),
+			Labels:    labels,
+		},
+		Data: map[string][]byte{
+			splcommon.HecTokenSecretKey: generateHECToken(),
+		},
+	}
+	current.SetOwnerReferences(append(current.GetOwnerReferences(), splcommon.AsOwner(cr, false)))
+
+	err = CreateResource(ctx, c, &current)
+	if err != nil {
+		return nil, err
+	}
+
+	return &current, nil
+}
@@ -917,3 +917,54 @@ func TestGetNamespaceScopedSecretByName(t *testing.T) {
 		t.Errorf(err.Error())
 	}
 }
+
+func TestApplyHecTokenSecret(t *testing.T) {
+	ctx := context.TODO()
+	cr := TestResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "stack1",
+			Namespace: "test",
+		},
+	}
+	labels := map[string]string{"enterprise.splunk.com/hec-token": "ingest"}
+
+	c := spltest.NewMockClient()
+
+	// Secret doesn't exist, a token is generated
+	secret, err := ApplyHecTokenSecret(ctx, c, &cr, "hec-secret", labels)
+	if err != nil {
+		t.Errorf("Couldn't create HEC token secret %v", err)
+	}
+	token := string(secret.Data[splcommon.HecTokenSecretKey])
+	if len(token) != 36 || token[8] != '-' {
+		t.Errorf("Generated HEC token %s is not formatted like a UUID", token)
+	}
+	if !reflect.DeepEqual(secret.GetLabels(), labels) || len(secret.GetOwnerReferences()) != 1 {
+		t.Errorf("HEC token secret labels or owner references not set, labels=%v", secret.GetLabels())
+	}
+
+	// Secret exists, the token is kept
+	secret, err = ApplyHecTokenSecret(ctx, c, &cr, "hec-secret", labels)
+	if err != nil {
+		t.Errorf("Couldn't apply existing HEC token secret %v", err)
+	}
+	if string(secret.Data[splcommon.HecTokenSecretKey]) != token {
+		t.Errorf("HEC token was rotated, got %s want %s", secret.Data[splcommon.HecTokenSecretKey], token)
+	}
+
+	// Secret exists without a token
+	empty := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "empty-hec-secret",
+			Namespace: "test",
+		},
+	}
+	c.AddObject(&empty)
+	secret, err = ApplyHecTokenSecret(ctx, c, &cr, "empty-hec-secret", labels)
+	if err != nil {
+		t.Errorf("Couldn't update HEC token secret %v", err)
+	}
+	if len(secret.Data[splcommon.HecTokenSecretKey]) != 36 {
+		t.Errorf("Missing HEC token was not generated")
+	}
+}
@@ -25,6 +25,8 @@ import (
 	enterpriseApi "github.com/splunk/splunk-operator/api/v4"
 
 	splcommon "github.com/splunk/splunk-operator/pkg/splunk/common"
+	"github.com/splunk/splunk-operator/pkg/splunk/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	corev1 "k8s.io/api/core/v1"
 	errors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -144,6 +146,19 @@ func generateHECToken() []byte {
 
 // PodExecCommand execute a shell command in the specified pod
 func PodExecCommand(ctx context.Context, c splcommon.ControllerClient, podName string, namespace string, cmd []string, streamOptions *remotecommand.StreamOptions, tty bool, mock bool) (string, string, error) {
+	// the command is not recorded in the span, as it may hold credentials
+	ctx, span := tracing.Start(ctx, "PodExec",
+		attribute.String("k8s.namespace.name", namespace),
+		attribute.String("k8s.pod.name", podName))
+	defer span.End()
+
+	stdout, stderr, err := podExecCommand(ctx, c, podName, namespace, cmd, streamOptions, tty, mock)
+	tracing.RecordError(span, err)
+	return stdout, stderr, err
+}
+
+// podExecCommand executes a command in a pod for PodExecCommand
+func podExecCommand(ctx context.Context, c splcommon.ControllerClient, podName string, namespace string, cmd []string, streamOptions *remotecommand.StreamOptions, tty bool, mock bool) (string, string, error) {
 	var pod corev1.Pod
 
 	// Get Pod